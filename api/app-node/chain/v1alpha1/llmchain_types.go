@@ -78,6 +78,33 @@ type Memory struct {
 	// +kubebuilder:validation:Maximum=30
 	// +kubebuilder:default=5
 	ConversionWindowSize *int `json:"conversionWindowSize,omitempty"`
+	// Summarize the older conversation rounds beyond MaxTokenLimit or ConversionWindowSize by the llm instead of forgetting them.
+	// The summary is kept with the conversation and extended progressively.
+	// +optional
+	Summarize bool `json:"summarize,omitempty"`
+	// LongTerm remembers the facts about the user across conversations, disabled if not set
+	// +optional
+	LongTerm *LongTermMemory `json:"longTerm,omitempty"`
+}
+
+// LongTermMemory extracts the facts about the user from the conversations,
+// and adds the facts relevant to the question to the memory in all conversations of the user.
+type LongTermMemory struct {
+	// Embedder is used to embed the facts and questions
+	Embedder *v1alpha1.TypedObjectReference `json:"embedder"`
+	// TopK is the max number of facts added to the memory
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=5
+	TopK int `json:"topK,omitempty"`
+	// SimilarityThreshold is the min cosine similarity of a fact to the question to be added
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1
+	// +kubebuilder:default=0.5
+	SimilarityThreshold float64 `json:"similarityThreshold,omitempty"`
+	// MaxFacts is the max number of facts remembered for a user, the oldest ones are forgotten
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=100
+	MaxFacts int `json:"maxFacts,omitempty"`
 }
 
 // LLMChainStatus defines the observed state of LLMChain
@@ -22,6 +22,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	basev1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -250,6 +251,26 @@ func (in *LLMChainStatus) DeepCopy() *LLMChainStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LongTermMemory) DeepCopyInto(out *LongTermMemory) {
+	*out = *in
+	if in.Embedder != nil {
+		in, out := &in.Embedder, &out.Embedder
+		*out = new(basev1alpha1.TypedObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LongTermMemory.
+func (in *LongTermMemory) DeepCopy() *LongTermMemory {
+	if in == nil {
+		return nil
+	}
+	out := new(LongTermMemory)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Memory) DeepCopyInto(out *Memory) {
 	*out = *in
@@ -258,6 +279,11 @@ func (in *Memory) DeepCopyInto(out *Memory) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.LongTerm != nil {
+		in, out := &in.LongTerm, &out.LongTerm
+		*out = new(LongTermMemory)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Memory.
@@ -0,0 +1,174 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	node "github.com/kubeagi/arcadia/api/app-node"
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// Position is where the guardrail is in the application
+type Position string
+
+const (
+	// PositionInput checks the question before it goes to the llms, the guardrail should be right after the input node
+	PositionInput Position = "input"
+	// PositionOutput checks the answer before it goes to the user, the guardrail should be right before the output node
+	PositionOutput Position = "output"
+)
+
+// Action is what the guardrail does with the violations
+type Action string
+
+const (
+	// ActionBlock stops the chat and answers with the block message
+	ActionBlock Action = "block"
+	// ActionRedact replaces the violating text with a mask and goes on
+	ActionRedact Action = "redact"
+	// ActionWarn goes on with the text as it is, the violations are only logged
+	ActionWarn Action = "warn"
+)
+
+// PIIType is the type of personal identifiable information
+// +kubebuilder:validation:Enum=idCard;phone;email;bankCard
+type PIIType string
+
+const (
+	// PIIIDCard is the resident identity card number of China
+	PIIIDCard PIIType = "idCard"
+	// PIIPhone is the mobile phone number of China
+	PIIPhone PIIType = "phone"
+	PIIEmail PIIType = "email"
+	// PIIBankCard is the bank card number which passes the Luhn check
+	PIIBankCard PIIType = "bankCard"
+)
+
+// GuardrailSpec defines the desired state of Guardrail
+type GuardrailSpec struct {
+	v1alpha1.CommonSpec `json:",inline"`
+
+	GuardrailConfig `json:",inline"`
+}
+
+type GuardrailConfig struct {
+	// Position of the guardrail, input checks the question and output checks the answer
+	// +kubebuilder:validation:Enum=input;output
+	// +kubebuilder:default=input
+	Position Position `json:"position,omitempty"`
+	// Action to the violations of the blocklist and the policy
+	// redact is the same as block for the policy violations, since the whole text is classified
+	// +kubebuilder:validation:Enum=block;redact;warn
+	// +kubebuilder:default=block
+	Action Action `json:"action,omitempty"`
+	// Message is the answer when the chat is blocked
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Blocklist of the text
+	// +optional
+	Blocklist *Blocklist `json:"blocklist,omitempty"`
+	// PII detects and masks the personal identifiable information
+	// +optional
+	PII *PII `json:"pii,omitempty"`
+	// Policy classifies the text by a llm
+	// +optional
+	Policy *Policy `json:"policy,omitempty"`
+}
+
+// Blocklist defines the text not allowed
+type Blocklist struct {
+	// Keywords are matched case-insensitively
+	// +optional
+	Keywords []string `json:"keywords,omitempty"`
+	// Patterns are regular expressions in RE2 syntax
+	// +optional
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// PII defines how to handle the personal identifiable information.
+// When redacted in the input position, the masking is reversible: the question and the history go to the llms with placeholders,
+// and the placeholders in the answer are restored before it goes to the user.
+type PII struct {
+	// Types to detect, all types are detected if not set
+	// +optional
+	Types []PIIType `json:"types,omitempty"`
+	// Action to the detected information
+	// +kubebuilder:validation:Enum=block;redact;warn
+	// +kubebuilder:default=redact
+	Action Action `json:"action,omitempty"`
+}
+
+// Policy classifies the text by a llm against the rules
+type Policy struct {
+	// LLM to classify the text
+	// +kubebuilder:validation:Required
+	LLM v1alpha1.TypedObjectReference `json:"llm"`
+	// Model of the llm, the first model of the llm is used if not set
+	// +optional
+	Model string `json:"model,omitempty"`
+	// Rules the text must not break, in natural language, like "no violence or self-harm"
+	// +kubebuilder:validation:MinItems=1
+	Rules []string `json:"rules"`
+}
+
+// GuardrailStatus defines the observed state of Guardrail
+type GuardrailStatus struct {
+	// ObservedGeneration is the last observed generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ConditionedStatus is the current status
+	v1alpha1.ConditionedStatus `json:",inline"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// Guardrail is the Schema for the Guardrail API
+type Guardrail struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GuardrailSpec   `json:"spec,omitempty"`
+	Status GuardrailStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GuardrailList contains a list of Guardrail
+type GuardrailList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Guardrail `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Guardrail{}, &GuardrailList{})
+}
+
+var _ node.Node = (*Guardrail)(nil)
+
+func (c *Guardrail) SetRef() {
+	annotations := node.SetRefAnnotations(c.GetAnnotations(), []node.Ref{node.CommonRef.Len(1)}, []node.Ref{node.CommonRef.Len(1)})
+	if c.GetAnnotations() == nil {
+		c.SetAnnotations(annotations)
+	}
+	for k, v := range annotations {
+		c.Annotations[k] = v
+	}
+}
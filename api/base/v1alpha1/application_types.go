@@ -54,6 +54,126 @@ type ApplicationSpec struct {
 	// +kubebuilder:validation:Minimum:=1
 	// +kubebuilder:default:=60
 	ChatTimeoutSecond float64 `json:"chatTimeoutSecond,omitempty"`
+	// ResponseCache caches the answers of questions, disabled if not set
+	// +optional
+	ResponseCache *ResponseCache `json:"responseCache,omitempty"`
+	// Quota limits the daily usage of the application, no limit if not set.
+	// The limit is soft: requests are rejected once the usage reaches it, so the
+	// last accepted request and the requests running at the same time may exceed it.
+	// +optional
+	Quota *Quota `json:"quota,omitempty"`
+	// ImageCaption describes the images in questions as text for the llms which only accept text,
+	// the images are ignored by these llms if not set
+	// +optional
+	ImageCaption *ImageCaption `json:"imageCaption,omitempty"`
+	// ConversationFile limits the files uploaded to the conversations, the default limits are used if not set
+	// +optional
+	ConversationFile *ConversationFileConfig `json:"conversationFile,omitempty"`
+	// Handoff hands the conversations over to human operators by the rules, disabled if not set
+	// +optional
+	Handoff *Handoff `json:"handoff,omitempty"`
+}
+
+// Handoff hands a conversation over to human operators when any of the rules matches.
+// The application stops answering the conversation until an operator resolves it,
+// and the replies of the operators are added to the same conversation.
+type Handoff struct {
+	// Keywords hand off when the question contains any of them, like "human agent"
+	// +optional
+	Keywords []string `json:"keywords,omitempty"`
+	// OnDocNullReturn hands off when the retrievers find nothing for the question
+	// +optional
+	OnDocNullReturn bool `json:"onDocNullReturn,omitempty"`
+	// MinScore hands off when the best score of the references is lower than it, which means the answer is of low confidence
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1
+	// +optional
+	MinScore *float32 `json:"minScore,omitempty"`
+	// Message is told to the user when the conversation is handed off
+	// +optional
+	Message string `json:"message,omitempty"`
+	// WebhookURL receives a POST request with the handoff in json when a conversation is handed off
+	// +optional
+	WebhookURL string `json:"webhookURL,omitempty"`
+}
+
+// ConversationFileConfig limits the files uploaded to a conversation, which are embedded into the conversation knowledgebase
+type ConversationFileConfig struct {
+	// MaxFileSizeMB is the max size of a file in MiB
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=20
+	MaxFileSizeMB int `json:"maxFileSizeMB,omitempty"`
+	// MaxFiles is the max number of files in a conversation
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
+	MaxFiles int `json:"maxFiles,omitempty"`
+	// AllowedTypes are the allowed file extensions like .pdf, all the types supported by the knowledgebase are allowed if not set
+	// +optional
+	AllowedTypes []string `json:"allowedTypes,omitempty"`
+}
+
+// ImageCaption describes the images by a vision llm, including the text in them
+type ImageCaption struct {
+	// LLM is the vision llm to describe the images
+	// +kubebuilder:validation:Required
+	LLM TypedObjectReference `json:"llm"`
+	// Model of the llm to describe the images, the first model of the llm is used if not set
+	// +optional
+	Model string `json:"model,omitempty"`
+}
+
+// Quota limits the daily usage of the application, the usage is reset at midnight
+type Quota struct {
+	// PerUser limits the daily usage of each user
+	// +optional
+	PerUser *QuotaLimit `json:"perUser,omitempty"`
+	// PerApp limits the daily usage of all users of the application
+	// +optional
+	PerApp *QuotaLimit `json:"perApp,omitempty"`
+}
+
+// QuotaLimit is the daily limit of tokens and requests, 0 means no limit
+type QuotaLimit struct {
+	// DailyTokens is the max tokens used by llms and embedders in a day
+	// +kubebuilder:validation:Minimum=0
+	DailyTokens int64 `json:"dailyTokens,omitempty"`
+	// DailyRequests is the max chat requests in a day
+	// +kubebuilder:validation:Minimum=0
+	DailyRequests int64 `json:"dailyRequests,omitempty"`
+}
+
+type ResponseCacheMode string
+
+const (
+	// ResponseCacheModeExact reuses the answer of the same question
+	ResponseCacheModeExact ResponseCacheMode = "exact"
+	// ResponseCacheModeSemantic reuses the answer of a similar question
+	ResponseCacheModeSemantic ResponseCacheMode = "semantic"
+)
+
+// ResponseCache caches the answers of the application and the responses of its llms.
+// Cached answers are invalidated once the application or its knowledgebases change.
+type ResponseCache struct {
+	// Mode of matching questions, exact or semantic
+	// +kubebuilder:validation:Enum=exact;semantic
+	// +kubebuilder:default=exact
+	Mode ResponseCacheMode `json:"mode,omitempty"`
+	// TTLSeconds is how long an answer is cached
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3600
+	TTLSeconds int `json:"ttlSeconds,omitempty"`
+	// MaxEntries is the max number of cached answers of this application
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1000
+	MaxEntries int `json:"maxEntries,omitempty"`
+	// Embedder is used to embed questions in semantic mode
+	// +optional
+	Embedder *TypedObjectReference `json:"embedder,omitempty"`
+	// SimilarityThreshold is the min cosine similarity of two questions to reuse the answer in semantic mode
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1
+	// +kubebuilder:default=0.95
+	SimilarityThreshold float64 `json:"similarityThreshold,omitempty"`
 }
 
 // WebConfig is the configuration for web interface
@@ -37,6 +37,9 @@ const (
 	ObjectTypeTag  = "object_type"
 	ObjectCountTag = "object_count"
 	ObjectTypeQA   = "QA"
+	ObjectTypeWeb  = "Web"
+	// ObjectTypeRecord is a row of a database table
+	ObjectTypeRecord = "Record"
 )
 
 type ProviderType string
@@ -59,6 +62,75 @@ type Provider struct {
 	Worker *TypedObjectReference `json:"worker,omitempty"`
 }
 
+// OpenAICompatible defines how to request a service which speaks the OpenAI protocol,
+// like DeepSeek, Moonshot, vLLM or Ollama. The base url of the apis is endpoint.url.
+type OpenAICompatible struct {
+	// Headers are extra http headers sent in every request
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// APIVersion is sent as the `api-version` query parameter if set
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// ChatPath overrides the path of the chat completions api, `/chat/completions` by default
+	// +optional
+	ChatPath string `json:"chatPath,omitempty"`
+
+	// EmbeddingsPath overrides the path of the embeddings api, `/embeddings` by default
+	// +optional
+	EmbeddingsPath string `json:"embeddingsPath,omitempty"`
+
+	// ExtraBody are extra parameters added to the json body of every request, like `enable_search: "true"`.
+	// Values are parsed as json, and sent as strings if they are not valid json.
+	// Parameters set by the request itself are not overridden.
+	// +optional
+	ExtraBody map[string]string `json:"extraBody,omitempty"`
+}
+
+// ModelCapability declares what a model can do, the runtime uses it to validate the configs which use this model
+type ModelCapability struct {
+	// Name of the model
+	Name string `json:"name"`
+
+	// ContextLength is the max number of tokens of the prompt and the completion. 0 means unknown.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ContextLength int `json:"contextLength,omitempty"`
+
+	// FunctionCalling means the model supports native function(tool) calling
+	// +optional
+	FunctionCalling bool `json:"functionCalling,omitempty"`
+
+	// Vision means the model accepts images as input
+	// +optional
+	Vision bool `json:"vision,omitempty"`
+
+	// EmbeddingDimension is the dimension of the embeddings of an embedding model. 0 means unknown.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	EmbeddingDimension int `json:"embeddingDimension,omitempty"`
+}
+
+// GetModelCapability returns the capability of the model from the declared capabilities
+func GetModelCapability(capabilities []ModelCapability, model string) (ModelCapability, bool) {
+	for _, c := range capabilities {
+		if c.Name == model {
+			return c, true
+		}
+	}
+	return ModelCapability{}, false
+}
+
+// modelNames returns the names of models which declare their capabilities
+func modelNames(capabilities []ModelCapability) []string {
+	names := make([]string, 0, len(capabilities))
+	for _, c := range capabilities {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
 // GetType returns the type of this provider
 func (p Provider) GetType() ProviderType {
 	// if endpoint provided, then 3rd_party
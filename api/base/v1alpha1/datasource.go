@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -41,9 +43,21 @@ const (
 	DatasourceTypeRDMA       DatasourceType = "RDMA"
 	DatasourceTypePostgreSQL DatasourceType = "postgresql"
 	DatasourceTypeWeb        DatasourceType = "web"
+	DatasourceTypeGit        DatasourceType = "git"
 	DatasourceTypeUnknown    DatasourceType = "unknown"
 )
 
+const (
+	DefaultWebCrawlerMaxDepth = 2
+	DefaultWebCrawlerMaxPages = 100
+	DefaultWebCrawlerDelay    = time.Second
+)
+
+// WebCrawlerPath returns the path in the namespace bucket where the crawled pages are stored
+func (datasource Datasource) WebCrawlerPath() string {
+	return "web/" + datasource.Name
+}
+
 func (ds DatasourceSpec) Type() DatasourceType {
 	switch {
 	case ds.OSS != nil:
@@ -54,6 +68,8 @@ func (ds DatasourceSpec) Type() DatasourceType {
 		return DatasourceTypePostgreSQL
 	case ds.Web != nil:
 		return DatasourceTypeWeb
+	case ds.Git != nil:
+		return DatasourceTypeGit
 	default:
 		return DatasourceTypeUnknown
 	}
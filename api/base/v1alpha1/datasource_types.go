@@ -38,6 +38,9 @@ type DatasourceSpec struct {
 
 	// Web defines info for web resources
 	Web *Web `json:"web,omitempty"`
+
+	// Git defines info for git repository, the url of the repository is endpoint.url
+	Git *Git `json:"git,omitempty"`
 }
 
 type RDMA struct {
@@ -83,6 +86,41 @@ type PostgreSQL struct {
 	TargetSessionAttrs string `json:"PGTARGETSESSIONATTRS,omitempty"`
 	Service            string `json:"PGSERVICE,omitempty"`
 	ServiceFile        string `json:"PGSERVICEFILE,omitempty"`
+
+	// Tables are the tables, views or queries whose rows are loaded as documents,
+	// so knowledgebases can use this datasource as a source of files.
+	// +optional
+	Tables []PostgreSQLTable `json:"tables,omitempty"`
+}
+
+// PostgreSQLTable defines how rows of a table, view or query are mapped to documents.
+// Each row is a file named <name>/<id>.json in the datasource.
+type PostgreSQLTable struct {
+	// Name of this table in the datasource, used as the directory of its rows
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+$`
+	Name string `json:"name"`
+	// Table is the table or view to read, can be qualified with a schema like `public.docs`
+	// +optional
+	Table string `json:"table,omitempty"`
+	// Query is the SQL query to read rows, used if Table is empty
+	// +optional
+	Query string `json:"query,omitempty"`
+
+	// IDColumn is the column which identifies a row
+	IDColumn string `json:"idColumn"`
+	// ContentColumns are the columns joined as the content of the document
+	// +kubebuilder:validation:MinItems=1
+	ContentColumns []string `json:"contentColumns"`
+	// TitleColumn is the column used as the title of the document
+	// +optional
+	TitleColumn string `json:"titleColumn,omitempty"`
+	// MetadataColumns are the columns kept in the metadata of the document
+	// +optional
+	MetadataColumns []string `json:"metadataColumns,omitempty"`
+	// UpdatedAtColumn is the column which records when the row was updated last time.
+	// If set, a row is embedded again only when it changes, otherwise the content is compared.
+	// +optional
+	UpdatedAtColumn string `json:"updatedAtColumn,omitempty"`
 }
 
 const (
@@ -92,18 +130,125 @@ const (
 	PGSSLPASSWORD = "PGSSLPASSWORD"
 )
 
+// Git defines info for git repository as datasource.
+// The auth secret can be a basic auth secret with `username` and `password`(or a token),
+// or a ssh auth secret with `ssh-privatekey` and `known_hosts` to verify the host key of the server.
+type Git struct {
+	// Reference is the branch or tag to read, the default branch is used if empty
+	// +optional
+	Reference string `json:"reference,omitempty"`
+
+	// Paths are glob patterns of the files to read, like `docs/**/*.md` or `README.md`.
+	// `**` matches any number of directories. All files are read if empty.
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+
+	// InsecureIgnoreHostKey skips verifying the host key of the server when `known_hosts` is not in the ssh auth secret.
+	// It's insecure as the connection can be intercepted, only use it for testing.
+	// +optional
+	InsecureIgnoreHostKey bool `json:"insecureIgnoreHostKey,omitempty"`
+}
+
+const (
+	GitUsername      = "username"
+	GitPassword      = "password"
+	GitSSHPrivateKey = "ssh-privatekey"
+	GitKnownHosts    = "known_hosts"
+)
+
 // Web defines info for web resources
 type Web struct {
 	// RecommendIntervalTime is the recommended interval time for this crawler
 	RecommendIntervalTime int `json:"recommendIntervalTime,omitempty"`
+
+	// Crawler crawls pages from the web into oss, so they can be used by knowledgebases.
+	// Only endpoint.url is checked if not set.
+	// +optional
+	Crawler *WebCrawler `json:"crawler,omitempty"`
+}
+
+// WebCrawler defines how to crawl pages from a website
+type WebCrawler struct {
+	// SeedURLs are the urls to start crawling from. Default to endpoint.url
+	// +optional
+	SeedURLs []string `json:"seedURLs,omitempty"`
+
+	// MaxDepth is the max number of links followed from the seed urls
+	// +kubebuilder:default=2
+	// +kubebuilder:validation:Minimum=0
+	MaxDepth int `json:"maxDepth,omitempty"`
+
+	// MaxPages is the max number of pages to crawl
+	// +kubebuilder:default=100
+	// +kubebuilder:validation:Minimum=1
+	MaxPages int `json:"maxPages,omitempty"`
+
+	// IncludePatterns are regular expressions, only urls matching one of them are crawled if set
+	// +optional
+	IncludePatterns []string `json:"includePatterns,omitempty"`
+
+	// ExcludePatterns are regular expressions, urls matching any of them are not crawled
+	// +optional
+	ExcludePatterns []string `json:"excludePatterns,omitempty"`
+
+	// Sitemap enables discovering urls from /sitemap.xml of the seed hosts
+	// +optional
+	Sitemap bool `json:"sitemap,omitempty"`
+
+	// IgnoreRobotsTxt disables the robots.txt compliance
+	// +optional
+	IgnoreRobotsTxt bool `json:"ignoreRobotsTxt,omitempty"`
+
+	// Delay between two requests to the same host. Default to 1s
+	// +optional
+	Delay *metav1.Duration `json:"delay,omitempty"`
+
+	// UserAgent used in requests and to match robots.txt rules
+	// +optional
+	UserAgent string `json:"userAgent,omitempty"`
+
+	// Interval to crawl the website again. The website is only crawled when the spec changes if not set
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
 }
 
 // DatasourceStatus defines the observed state of Datasource
 type DatasourceStatus struct {
+	// Crawl is the status of the last crawl for web datasources with a crawler
+	// +optional
+	Crawl *CrawlStatus `json:"crawl,omitempty"`
+
 	// ConditionedStatus is the current status
 	ConditionedStatus `json:",inline"`
 }
 
+// CrawlStatus defines the result of a crawl
+type CrawlStatus struct {
+	// ObservedGeneration is the generation of the datasource which was crawled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// StartTime is the time when the crawl started
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the crawl completed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Pages is the number of pages stored in oss
+	Pages int `json:"pages,omitempty"`
+
+	// Failed is the number of pages which failed to be fetched
+	Failed int `json:"failed,omitempty"`
+
+	// Message is the error message if the crawl failed
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Failures is the number of consecutive failed crawls, failed crawls are retried with backoff
+	// +optional
+	Failures int `json:"failures,omitempty"`
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:scope=Namespaced
@@ -80,6 +80,9 @@ func (e Embedder) Get3rdPartyModels() []string {
 	if e.Spec.Models != nil && len(e.Spec.Models) != 0 {
 		return e.Spec.Models
 	}
+	if len(e.Spec.ModelCapabilities) != 0 {
+		return modelNames(e.Spec.ModelCapabilities)
+	}
 
 	switch e.Spec.Type {
 	case embeddings.ZhiPuAI:
@@ -88,11 +91,25 @@ func (e Embedder) Get3rdPartyModels() []string {
 		return embeddings.OpenAIModels
 	case embeddings.Gemini:
 		return embeddings.GeminiModels
+	case embeddings.DashScope:
+		return embeddings.DashScopeModels
 	}
 
 	return []string{}
 }
 
+// GetModelCapability returns the declared capability of the model, the first model is used if model is empty
+func (e Embedder) GetModelCapability(model string) (ModelCapability, bool) {
+	if model == "" {
+		models := e.GetModelList()
+		if len(models) == 0 {
+			return ModelCapability{}, false
+		}
+		model = models[0]
+	}
+	return GetModelCapability(e.Spec.ModelCapabilities, model)
+}
+
 func (e Embedder) ReadyCondition(msg string) Condition {
 	currCon := e.Status.GetCondition(TypeReady)
 	// return current condition if condition not changed
@@ -129,3 +146,28 @@ func (e Embedder) ErrorCondition(msg string) Condition {
 		LastTransitionTime: metav1.Now(),
 	}
 }
+
+// EmbeddingIdentity returns the identity of vectors generated by this embedder with the model, the first model is used if model is empty.
+// The dimension is the declared one in ModelCapabilities, 0 if not declared.
+func (e Embedder) EmbeddingIdentity(model string) EmbeddingIdentity {
+	if model == "" {
+		if models := e.GetModelList(); len(models) > 0 {
+			model = models[0]
+		}
+	}
+	if e.Spec.Provider.GetType() == ProviderTypeWorker {
+		// the model of a worker is its uid, which changes when the worker is re-created with the same model
+		for _, owner := range e.GetOwnerReferences() {
+			if owner.Kind == "Worker" {
+				model = "worker/" + owner.Name
+			}
+		}
+	}
+	capability, _ := e.GetModelCapability(model)
+	return EmbeddingIdentity{
+		Embedder:  e.Namespace + "/" + e.Name,
+		Type:      string(e.Spec.Type),
+		Model:     model,
+		Dimension: capability.EmbeddingDimension,
+	}
+}
@@ -38,6 +38,47 @@ type EmbedderSpec struct {
 	// Models provided by this LLM
 	// If not set,we will use default model list based on LLMType
 	Models []string `json:"models,omitempty"`
+
+	// ModelCapabilities declare the capabilities of models, like the embedding dimension.
+	// If Models is not set, these models are provided by this Embedder.
+	// +optional
+	ModelCapabilities []ModelCapability `json:"modelCapabilities,omitempty"`
+
+	// OpenAICompatible configures how to request the service when type is `openai-compatible`
+	// +optional
+	OpenAICompatible *OpenAICompatible `json:"openaiCompatible,omitempty"`
+
+	// DashScope configures the async embedding when type is `dashscope`
+	// +optional
+	DashScope *DashScopeEmbedding `json:"dashscope,omitempty"`
+
+	// RateLimit defines the limits of requests sent to this embedder.
+	// The limits are shared by all knowledgebases which use this embedder.
+	// +optional
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+}
+
+// RateLimit defines the limits of requests sent to an embedding service
+type RateLimit struct {
+	// MaxConcurrency is the max number of in-flight requests. 0 means no limit.
+	// +kubebuilder:validation:Minimum=0
+	MaxConcurrency int `json:"maxConcurrency,omitempty"`
+
+	// RequestsPerMinute is the max number of requests per minute. 0 means no limit.
+	// +kubebuilder:validation:Minimum=0
+	RequestsPerMinute int `json:"requestsPerMinute,omitempty"`
+}
+
+// DashScopeEmbedding configures how to embed large jobs with DashScope
+type DashScopeEmbedding struct {
+	// AsyncThreshold is the min number of texts, like the chunks of a file, to be embedded by an async task
+	// instead of batches of 25 texts. Async embedding is disabled if not set.
+	// The texts are uploaded to the system datasource and downloaded by DashScope from a presigned url,
+	// so the endpoint of the system datasource must be a public address which DashScope can reach.
+	// Texts are embedded in batches if the endpoint is an in-cluster or private address, or the task fails.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	AsyncThreshold int `json:"asyncThreshold,omitempty"`
 }
 
 // EmbeddingsStatus defines the observed state of Embedder
@@ -1,6 +1,12 @@
 package v1alpha1
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
@@ -24,9 +30,12 @@ func (kb *KnowledgeBase) IsTypeConversation() bool {
 const (
 	// UpdateSourceFileAnnotationKey is the key of the update source file annotation
 	UpdateSourceFileAnnotationKey = Group + "/update-source-file-time"
-	DefaultChunkSize              = 300
-	DefaultChunkOverlap           = 10
-	DefaultBatchSize              = 10
+	// UpdateSourceFileRetryFailed is the value of the update source file annotation to only retry the failed files
+	UpdateSourceFileRetryFailed = "for-failed"
+	DefaultChunkSize            = 300
+	DefaultChunkOverlap         = 10
+	DefaultBatchSize            = 10
+	DefaultMaxConcurrentFiles   = 3
 )
 
 func (kb *KnowledgeBase) EmbeddingOptions() EmbeddingOptions {
@@ -40,13 +49,33 @@ func (kb *KnowledgeBase) EmbeddingOptions() EmbeddingOptions {
 	if kb.Spec.EmbeddingOptions.BatchSize == 0 {
 		options.BatchSize = DefaultBatchSize
 	}
+	if kb.Spec.EmbeddingOptions.MaxConcurrentFiles == 0 {
+		options.MaxConcurrentFiles = DefaultMaxConcurrentFiles
+	}
 	return options
 }
 
 func (kb *KnowledgeBase) VectorStoreCollectionName() string {
+	if kb.Spec.CollectionName != "" {
+		return kb.Spec.CollectionName
+	}
 	return kb.Namespace + "_" + kb.Name
 }
 
+// IncompatibleReason returns why vectors of the embedder can't be stored in the same collection as this identity,
+// empty if they are compatible. The embedder itself may change, as long as the type, model and dimension are the same.
+func (id EmbeddingIdentity) IncompatibleReason(embedder EmbeddingIdentity) string {
+	switch {
+	case id.Type != embedder.Type:
+		return fmt.Sprintf("embedder type is changed from %s to %s", id.Type, embedder.Type)
+	case id.Model != embedder.Model:
+		return fmt.Sprintf("embedding model is changed from %s to %s", id.Model, embedder.Model)
+	case id.Dimension != 0 && embedder.Dimension != 0 && id.Dimension != embedder.Dimension:
+		return fmt.Sprintf("embedding dimension is changed from %d to %d", id.Dimension, embedder.Dimension)
+	}
+	return ""
+}
+
 func (kb *KnowledgeBase) InitCondition() Condition {
 	return Condition{
 		Type:               TypeReady,
@@ -110,3 +139,154 @@ func (f *FileGroupDetail) Init(group FileGroup) {
 		f.FileDetails[i].LastUpdateTime = metav1.Now()
 	}
 }
+
+// UpdateProgress recalculates the embedding progress from the file details.
+// Files finished after startTime are used to estimate the completion time of the remaining files.
+func (kb *KnowledgeBase) UpdateProgress(startTime metav1.Time) {
+	progress := &EmbeddingProgress{StartTime: startTime}
+	finishedInRound := 0
+	for _, fg := range kb.Status.FileGroupDetail {
+		for _, f := range fg.FileDetails {
+			progress.TotalFiles++
+			switch f.Phase {
+			case FileProcessPhaseSucceeded, FileProcessPhaseSkipped:
+				progress.CompletedFiles++
+				progress.EmbeddedChunks += f.Chunks
+			case FileProcessPhaseFailed:
+				progress.FailedFiles++
+			default:
+				continue
+			}
+			if !f.LastUpdateTime.Before(&startTime) {
+				finishedInRound++
+			}
+		}
+	}
+	remaining := progress.TotalFiles - progress.CompletedFiles - progress.FailedFiles
+	if remaining > 0 && finishedInRound > 0 {
+		now := time.Now()
+		perFile := now.Sub(startTime.Time) / time.Duration(finishedInRound)
+		eta := metav1.NewTime(now.Add(perFile * time.Duration(remaining)))
+		progress.EstimatedCompletionTime = &eta
+	}
+	kb.Status.Progress = progress
+}
+
+const (
+	DefaultSyncInterval     = 5 * time.Minute
+	DefaultSyncHistoryLimit = 10
+)
+
+// SyncInterval returns the interval to re-list sources in watch mode
+func (p *SyncPolicy) SyncInterval() time.Duration {
+	if p.Interval == nil || p.Interval.Duration <= 0 {
+		return DefaultSyncInterval
+	}
+	return p.Interval.Duration
+}
+
+// AddSyncRecord records a sync result in status, only the latest HistoryLimit records are kept
+func (kb *KnowledgeBase) AddSyncRecord(record SyncRecord) {
+	limit := DefaultSyncHistoryLimit
+	if kb.Spec.SyncPolicy != nil && kb.Spec.SyncPolicy.HistoryLimit > 0 {
+		limit = kb.Spec.SyncPolicy.HistoryLimit
+	}
+	if kb.Status.Sync == nil {
+		kb.Status.Sync = &SyncStatus{}
+	}
+	history := append([]SyncRecord{record}, kb.Status.Sync.History...)
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	kb.Status.Sync.History = history
+	kb.Status.Sync.LastSyncTime = record.StartTime.DeepCopy()
+}
+
+// FileGroups returns the file groups to be embedded, which are the ones in spec with the differences found by the last sync applied.
+// Files added by the sync are appended to the first file group of their source.
+func (kb *KnowledgeBase) FileGroups() []FileGroup {
+	if kb.Status.Sync == nil || len(kb.Status.Sync.FileGroups) == 0 {
+		return kb.Spec.FileGroups
+	}
+	synced := make(map[string]SyncedFileGroup, len(kb.Status.Sync.FileGroups))
+	for _, sg := range kb.Status.Sync.FileGroups {
+		if sg.Source != nil {
+			synced[kb.sourceKey(sg.Source)] = sg
+		}
+	}
+	inSpec := make(map[string]map[string]bool)
+	for _, fg := range kb.Spec.FileGroups {
+		if fg.Source == nil {
+			continue
+		}
+		key := kb.sourceKey(fg.Source)
+		if inSpec[key] == nil {
+			inSpec[key] = make(map[string]bool)
+		}
+		for _, f := range fg.Files {
+			inSpec[key][f.Path] = true
+		}
+	}
+
+	groups := make([]FileGroup, 0, len(kb.Spec.FileGroups))
+	appended := make(map[string]bool)
+	for _, fg := range kb.Spec.FileGroups {
+		fg := *fg.DeepCopy()
+		if fg.Source == nil {
+			groups = append(groups, fg)
+			continue
+		}
+		key := kb.sourceKey(fg.Source)
+		sg, ok := synced[key]
+		if !ok {
+			groups = append(groups, fg)
+			continue
+		}
+		removed := make(map[string]bool, len(sg.Removed))
+		for _, p := range sg.Removed {
+			removed[p] = true
+		}
+		files := make([]FileWithVersion, 0, len(fg.Files)+len(sg.Added))
+		for _, f := range fg.Files {
+			// files of a specific version are not synced
+			if removed[f.Path] && f.Version == "" {
+				continue
+			}
+			files = append(files, f)
+		}
+		if !appended[key] {
+			appended[key] = true
+			for _, p := range sg.Added {
+				if !inSpec[key][p] {
+					files = append(files, FileWithVersion{Path: p})
+				}
+			}
+		}
+		fg.Files = files
+		groups = append(groups, fg)
+	}
+	return groups
+}
+
+func (kb *KnowledgeBase) sourceKey(source *TypedObjectReference) string {
+	kind := strings.ToLower(source.Kind)
+	if kind == "" {
+		kind = "datasource"
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, source.GetNamespace(kb.Namespace), source.Name)
+}
+
+// ContentVersion returns a version which changes when the spec or the embedded files of the knowledgebase change
+func (kb *KnowledgeBase) ContentVersion() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d", kb.Generation)
+	for _, group := range kb.Status.FileGroupDetail {
+		for _, f := range group.FileDetails {
+			if f.Phase != FileProcessPhaseSucceeded && f.Phase != FileProcessPhaseSkipped {
+				continue
+			}
+			fmt.Fprintf(h, "|%s|%s|%s", f.Path, f.Version, f.Checksum)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+)
+
+func TestAddSyncRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   *SyncPolicy
+		existing int
+		expected int
+	}{
+		{name: "first record", policy: &SyncPolicy{HistoryLimit: 3}, existing: 0, expected: 1},
+		{name: "under limit", policy: &SyncPolicy{HistoryLimit: 3}, existing: 1, expected: 2},
+		{name: "trimmed to limit", policy: &SyncPolicy{HistoryLimit: 3}, existing: 3, expected: 3},
+		{name: "default limit", policy: nil, existing: DefaultSyncHistoryLimit + 2, expected: DefaultSyncHistoryLimit},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kb := &KnowledgeBase{}
+			kb.Spec.SyncPolicy = test.policy
+			if test.existing > 0 {
+				kb.Status.Sync = &SyncStatus{}
+				for i := test.existing; i > 0; i-- {
+					kb.Status.Sync.History = append(kb.Status.Sync.History, SyncRecord{Added: i})
+				}
+			}
+			kb.AddSyncRecord(SyncRecord{Added: test.existing + 1})
+			history := kb.Status.Sync.History
+			if len(history) != test.expected {
+				t.Fatalf("expected %d records, got %d", test.expected, len(history))
+			}
+			// the latest record is first, followed by the previous ones from new to old
+			for i, r := range history {
+				if r.Added != test.existing+1-i {
+					t.Errorf("expected record %d to be added %d, got %d", i, test.existing+1-i, r.Added)
+				}
+			}
+		})
+	}
+}
@@ -34,11 +34,54 @@ type KnowledgeBaseSpec struct {
 	// VectorStore defines the vectorstore to store results
 	VectorStore *TypedObjectReference `json:"vectorStore,omitempty"`
 
+	// CollectionName defines the collection in the vectorstore. If empty, use `<namespace>_<name>`.
+	// It is set by KnowledgeBaseMigration when the knowledgebase is switched to a new collection.
+	// +optional
+	CollectionName string `json:"collectionName,omitempty"`
+
 	// FileGroups included files Grouped by VersionedDataset
 	FileGroups []FileGroup `json:"fileGroups,omitempty"`
 
 	// Embedding Options
 	EmbeddingOptions `json:",inline"`
+
+	// SyncPolicy defines how to keep files in sync with the sources in FileGroups.
+	// If not set, files are only embedded once and updated by the annotation `update-source-file-time`.
+	// +optional
+	SyncPolicy *SyncPolicy `json:"syncPolicy,omitempty"`
+}
+
+type SyncMode string
+
+const (
+	// SyncModeCron syncs files from sources at the time of Schedule
+	SyncModeCron SyncMode = "cron"
+	// SyncModeWatch syncs files once the Datasource or VersionedDataset of sources is updated or a web crawl finishes,
+	// and re-lists sources every Interval. Changes of objects in oss buckets and new commits of git repositories
+	// can't be watched, they are only found by the re-listing.
+	SyncModeWatch SyncMode = "watch"
+)
+
+// SyncPolicy defines how to re-list the sources and reconcile the vectorstore to match
+type SyncPolicy struct {
+	// Mode defines when to sync files from sources
+	// +kubebuilder:validation:Enum=cron;watch
+	Mode SyncMode `json:"mode"`
+
+	// Schedule in cron format, required in cron mode. For example, "0 */6 * * *"
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Interval to re-list sources in watch mode, changes of objects in oss and commits of git repositories can not be watched.
+	// Default to 5m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// HistoryLimit defines the number of sync records kept in status
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	HistoryLimit int `json:"historyLimit,omitempty"`
 }
 
 type EmbeddingOptions struct {
@@ -51,6 +94,10 @@ type EmbeddingOptions struct {
 	// BatchSize for text splitter
 	// +kubebuilder:default=10
 	BatchSize int `json:"batchSize,omitempty"`
+	// MaxConcurrentFiles defines how many files are embedded in parallel
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentFiles int `json:"maxConcurrentFiles,omitempty"`
 }
 
 type FileGroupDetail struct {
@@ -80,6 +127,9 @@ type FileDetails struct {
 	// TimeCost defines the time cost of the file processing in milliseconds
 	TimeCost int64 `json:"timeCost,omitempty"`
 
+	// Chunks defines the number of chunks embedded from this file
+	Chunks int `json:"chunks,omitempty"`
+
 	// The last time this condition was updated.
 	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
 
@@ -112,10 +162,140 @@ type KnowledgeBaseStatus struct {
 	// FileGroupDetail is the detail of these files
 	FileGroupDetail []FileGroupDetail `json:"fileGroupDetail,omitempty"`
 
+	// Progress is the embedding progress of files in this knowledgebase
+	// +optional
+	Progress *EmbeddingProgress `json:"progress,omitempty"`
+
+	// Sync is the status of syncing files from sources
+	// +optional
+	Sync *SyncStatus `json:"sync,omitempty"`
+
+	// Embedding identifies the embedder which generated the vectors in the collection.
+	// It's recorded on the first embedding, an incompatible embedder is rejected until all files are re-embedded.
+	// +optional
+	Embedding *EmbeddingIdentity `json:"embedding,omitempty"`
+
 	// ConditionedStatus is the current status
 	ConditionedStatus `json:",inline"`
 }
 
+// EmbeddingIdentity identifies the vector space of a collection, vectors from different spaces can't be compared
+type EmbeddingIdentity struct {
+	// Embedder is the embedder in `namespace/name`
+	Embedder string `json:"embedder"`
+
+	// Type of the embedder, like openai or zhipuai
+	Type string `json:"type,omitempty"`
+
+	// Model is the embedding model
+	Model string `json:"model,omitempty"`
+
+	// Dimension of the vectors
+	Dimension int `json:"dimension,omitempty"`
+
+	// CollectionName is the collection in the vectorstore which stores the vectors
+	CollectionName string `json:"collectionName,omitempty"`
+
+	// FileMetadataKey is the metadata key which records the source file of documents, used to remove documents of a file.
+	// Collections whose documents don't record it are re-embedded.
+	FileMetadataKey string `json:"fileMetadataKey,omitempty"`
+}
+
+// EmbeddingProgress defines the progress of embedding files
+type EmbeddingProgress struct {
+	// TotalFiles is the number of files in this knowledgebase
+	TotalFiles int `json:"totalFiles"`
+
+	// CompletedFiles is the number of files which are succeeded or skipped
+	CompletedFiles int `json:"completedFiles"`
+
+	// FailedFiles is the number of files which are failed
+	FailedFiles int `json:"failedFiles"`
+
+	// EmbeddedChunks is the number of chunks which have been embedded
+	EmbeddedChunks int `json:"embeddedChunks"`
+
+	// StartTime is the time when the current round of embedding started
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// EstimatedCompletionTime is the estimated time when all files will be processed
+	// +optional
+	EstimatedCompletionTime *metav1.Time `json:"estimatedCompletionTime,omitempty"`
+}
+
+// SyncStatus defines the status of syncing files from sources
+type SyncStatus struct {
+	// LastSyncTime is the last time the sources were listed
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// NextSyncTime is the next scheduled time in cron mode
+	// +optional
+	NextSyncTime *metav1.Time `json:"nextSyncTime,omitempty"`
+
+	// ObservedSourceVersion is the generations of sources at the last sync, used to detect source changes in watch mode
+	// +optional
+	ObservedSourceVersion string `json:"observedSourceVersion,omitempty"`
+
+	// History of the recent syncs, the latest one first
+	// +optional
+	History []SyncRecord `json:"history,omitempty"`
+
+	// FileGroups are the differences between the files listed from each source by the last sync and the files in spec.
+	// Files in spec are left to the user, the embedded files are the ones in spec with these differences applied.
+	// +optional
+	FileGroups []SyncedFileGroup `json:"fileGroups,omitempty"`
+}
+
+// SyncedFileGroup is the difference between the files listed from a source and the files of the source in spec
+type SyncedFileGroup struct {
+	// Source of the files
+	Source *TypedObjectReference `json:"source"`
+
+	// Added are the paths of files in the source but not in spec
+	// +optional
+	Added []string `json:"added,omitempty"`
+
+	// Removed are the paths of files in spec but no longer in the source
+	// +optional
+	Removed []string `json:"removed,omitempty"`
+}
+
+type SyncPhase string
+
+const (
+	SyncPhaseSucceeded SyncPhase = "Succeeded"
+	SyncPhaseFailed    SyncPhase = "Failed"
+)
+
+// SyncRecord is the result of a single sync
+type SyncRecord struct {
+	// StartTime is the time when the sync started
+	StartTime metav1.Time `json:"startTime"`
+
+	// CompletionTime is the time when the sync completed
+	CompletionTime metav1.Time `json:"completionTime"`
+
+	// Trigger is the sync mode which triggered this sync
+	Trigger SyncMode `json:"trigger,omitempty"`
+
+	// Phase is the result of this sync
+	Phase SyncPhase `json:"phase"`
+
+	// Added is the number of new objects found in sources
+	Added int `json:"added"`
+
+	// Changed is the number of objects whose checksum changed
+	Changed int `json:"changed"`
+
+	// Removed is the number of objects removed from sources
+	Removed int `json:"removed"`
+
+	// Message is the error message if the sync failed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="display-name",type=string,JSONPath=`.spec.displayName`
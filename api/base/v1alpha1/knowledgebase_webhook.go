@@ -0,0 +1,84 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var knowledgebaselog = logf.Log.WithName("knowledgebase-resource")
+
+func (kb *KnowledgeBase) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(kb).
+		WithValidator(&knowledgeBaseValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-arcadia-kubeagi-k8s-com-cn-v1alpha1-knowledgebase,mutating=false,failurePolicy=fail,sideEffects=None,groups=arcadia.kubeagi.k8s.com.cn,resources=knowledgebases,verbs=update,versions=v1alpha1,name=vknowledgebase.kb.io,admissionReviewVersions=v1
+
+// knowledgeBaseValidator rejects changing the embedder of an embedded knowledgebase to an incompatible one
+type knowledgeBaseValidator struct {
+	client.Client
+}
+
+var _ webhook.CustomValidator = &knowledgeBaseValidator{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (v *knowledgeBaseValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (v *knowledgeBaseValidator) ValidateUpdate(ctx context.Context, oldObj runtime.Object, newObj runtime.Object) error {
+	oldKB, newKB := oldObj.(*KnowledgeBase), newObj.(*KnowledgeBase)
+	knowledgebaselog.Info("validate update", "name", newKB.Name)
+
+	recorded := oldKB.Status.Embedding
+	if recorded == nil || newKB.Spec.Embedder == nil || recorded.CollectionName != newKB.VectorStoreCollectionName() {
+		return nil
+	}
+	// re-embed all files into an empty collection in the same update
+	if v := newKB.Annotations[UpdateSourceFileAnnotationKey]; v != "" && v != oldKB.Annotations[UpdateSourceFileAnnotationKey] {
+		return nil
+	}
+	embedder := &Embedder{}
+	if err := v.Get(ctx, types.NamespacedName{Namespace: newKB.Spec.Embedder.GetNamespace(newKB.Namespace), Name: newKB.Spec.Embedder.Name}, embedder); err != nil {
+		// the controller checks it again when the embedder is available
+		knowledgebaselog.Error(err, "get embedder", "name", newKB.Name)
+		return nil
+	}
+	if reason := recorded.IncompatibleReason(embedder.EmbeddingIdentity("")); reason != "" {
+		return fmt.Errorf("%s, vectors in collection %s are generated by %s. set annotation %s to re-embed all files, or migrate with a KnowledgeBaseMigration",
+			reason, recorded.CollectionName, recorded.Embedder, UpdateSourceFileAnnotationKey)
+	}
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (v *knowledgeBaseValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
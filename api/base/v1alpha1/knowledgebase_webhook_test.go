@@ -0,0 +1,117 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeagi/arcadia/pkg/embeddings"
+)
+
+func TestIncompatibleReason(t *testing.T) {
+	recorded := EmbeddingIdentity{Embedder: "arcadia/zhipuai", Type: "zhipuai", Model: "embedding-2", Dimension: 1024}
+	tests := []struct {
+		name     string
+		embedder EmbeddingIdentity
+		reason   string
+	}{
+		{"same", recorded, ""},
+		{"another embedder", EmbeddingIdentity{Embedder: "arcadia/zhipuai-2", Type: "zhipuai", Model: "embedding-2", Dimension: 1024}, ""},
+		{"unknown dimension", EmbeddingIdentity{Embedder: "arcadia/zhipuai", Type: "zhipuai", Model: "embedding-2"}, ""},
+		{"type", EmbeddingIdentity{Embedder: "arcadia/openai", Type: "openai", Model: "embedding-2", Dimension: 1024}, "embedder type is changed from zhipuai to openai"},
+		{"model", EmbeddingIdentity{Embedder: "arcadia/zhipuai", Type: "zhipuai", Model: "embedding-3", Dimension: 1024}, "embedding model is changed from embedding-2 to embedding-3"},
+		{"dimension", EmbeddingIdentity{Embedder: "arcadia/zhipuai", Type: "zhipuai", Model: "embedding-2", Dimension: 2048}, "embedding dimension is changed from 1024 to 2048"},
+	}
+	for _, test := range tests {
+		if reason := recorded.IncompatibleReason(test.embedder); reason != test.reason {
+			t.Errorf("%s: expect %q, got %q", test.name, test.reason, reason)
+		}
+	}
+}
+
+func TestKnowledgeBaseValidateUpdate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	embedder := func(name, model string, dimension int) *Embedder {
+		return &Embedder{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: name},
+			Spec: EmbedderSpec{
+				Type:              embeddings.OpenAICompatible,
+				Provider:          Provider{Endpoint: &Endpoint{URL: "http://embedder"}},
+				ModelCapabilities: []ModelCapability{{Name: model, EmbeddingDimension: dimension}},
+			},
+		}
+	}
+	validator := &knowledgeBaseValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		embedder("bge", "bge-large-zh", 1024),
+		embedder("bge-copy", "bge-large-zh", 1024),
+		embedder("m3e", "m3e-base", 768),
+		embedder("bge-small", "bge-large-zh", 512),
+	).Build()}
+
+	oldKB := &KnowledgeBase{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: "kb"},
+		Spec:       KnowledgeBaseSpec{Embedder: &TypedObjectReference{Kind: "Embedder", Name: "bge"}},
+		Status: KnowledgeBaseStatus{Embedding: &EmbeddingIdentity{
+			Embedder:       "arcadia/bge",
+			Type:           string(embeddings.OpenAICompatible),
+			Model:          "bge-large-zh",
+			Dimension:      1024,
+			CollectionName: "arcadia_kb",
+		}},
+	}
+	tests := []struct {
+		name   string
+		update func(kb *KnowledgeBase)
+		err    string
+	}{
+		{name: "unchanged", update: func(kb *KnowledgeBase) {}},
+		{name: "compatible embedder", update: func(kb *KnowledgeBase) { kb.Spec.Embedder.Name = "bge-copy" }},
+		{name: "incompatible model", update: func(kb *KnowledgeBase) { kb.Spec.Embedder.Name = "m3e" }, err: "embedding model is changed from bge-large-zh to m3e-base"},
+		{name: "incompatible dimension", update: func(kb *KnowledgeBase) { kb.Spec.Embedder.Name = "bge-small" }, err: "embedding dimension is changed from 1024 to 512"},
+		{name: "embedder not found", update: func(kb *KnowledgeBase) { kb.Spec.Embedder.Name = "not-found" }},
+		{name: "another collection", update: func(kb *KnowledgeBase) {
+			kb.Spec.Embedder.Name = "m3e"
+			kb.Spec.CollectionName = "arcadia_kb_m3e"
+		}},
+		{name: "re-embed all files", update: func(kb *KnowledgeBase) {
+			kb.Spec.Embedder.Name = "m3e"
+			kb.Annotations = map[string]string{UpdateSourceFileAnnotationKey: "2024-01-01T00:00:00Z"}
+		}},
+	}
+	for _, test := range tests {
+		newKB := oldKB.DeepCopy()
+		test.update(newKB)
+		err := validator.ValidateUpdate(context.Background(), oldKB, newKB)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: expect allowed, got %s", test.name, err)
+		case test.err != "" && err == nil:
+			t.Errorf("%s: expect rejected", test.name)
+		case test.err != "" && !strings.Contains(err.Error(), test.err):
+			t.Errorf("%s: expect error with %q, got %s", test.name, test.err, err)
+		}
+	}
+}
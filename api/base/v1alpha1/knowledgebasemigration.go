@@ -0,0 +1,82 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+)
+
+// IsFinished returns true if the migration will not change the knowledgebase any more
+func (m *KnowledgeBaseMigration) IsFinished() bool {
+	switch m.Status.Phase {
+	case MigrationPhaseConfirmed, MigrationPhaseRolledBack, MigrationPhaseFailed:
+		return true
+	}
+	return false
+}
+
+// TargetCollectionName returns the collection to migrate into, like `<namespace>_<knowledgebase>_<migration>`
+func (m *KnowledgeBaseMigration) TargetCollectionName() string {
+	return m.Namespace + "_" + m.Spec.KnowledgeBase + "_" + m.Name
+}
+
+// SourceOf returns where the knowledgebase is stored now
+func SourceOf(kb *KnowledgeBase) *MigrationTarget {
+	return &MigrationTarget{
+		VectorStore:    kb.Spec.VectorStore.DeepCopy(),
+		Embedder:       kb.Spec.Embedder.DeepCopy(),
+		CollectionName: kb.VectorStoreCollectionName(),
+	}
+}
+
+// TargetOf returns where the knowledgebase will be migrated to, the vectorstore and embedder not set in spec are kept
+func (m *KnowledgeBaseMigration) TargetOf(kb *KnowledgeBase) (*MigrationTarget, error) {
+	target := SourceOf(kb)
+	changed := false
+	if m.Spec.VectorStore != nil && !sameReference(m.Spec.VectorStore, kb.Spec.VectorStore, kb.Namespace) {
+		target.VectorStore, changed = m.Spec.VectorStore.DeepCopy(), true
+	}
+	if m.Spec.Embedder != nil && !sameReference(m.Spec.Embedder, kb.Spec.Embedder, kb.Namespace) {
+		target.Embedder, changed = m.Spec.Embedder.DeepCopy(), true
+	}
+	if !changed {
+		return nil, fmt.Errorf("neither vectorstore nor embedder is changed")
+	}
+	target.CollectionName = m.TargetCollectionName()
+	return target, nil
+}
+
+// ValidateCounts checks the migrated files and chunks are the same as the knowledgebase
+func (status KnowledgeBaseMigrationStatus) ValidateCounts() error {
+	if status.FailedFiles > 0 {
+		return fmt.Errorf("%d files failed to migrate", status.FailedFiles)
+	}
+	if status.MigratedFiles != status.TotalFiles {
+		return fmt.Errorf("expect %d files migrated, got %d", status.TotalFiles, status.MigratedFiles)
+	}
+	if status.MigratedChunks != status.ExpectedChunks {
+		return fmt.Errorf("expect %d chunks migrated, got %d", status.ExpectedChunks, status.MigratedChunks)
+	}
+	return nil
+}
+
+func sameReference(a, b *TypedObjectReference, defaultNamespace string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Kind == b.Kind && a.Name == b.Name && a.GetNamespace(defaultNamespace) == b.GetNamespace(defaultNamespace)
+}
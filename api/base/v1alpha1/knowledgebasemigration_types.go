@@ -0,0 +1,151 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type MigrationDecision string
+
+const (
+	// MigrationDecisionConfirm removes the old collection, the migration can't be rolled back any more
+	MigrationDecisionConfirm MigrationDecision = "Confirm"
+	// MigrationDecisionRollback switches the knowledgebase back, and removes the new collection
+	MigrationDecisionRollback MigrationDecision = "Rollback"
+)
+
+// KnowledgeBaseMigrationSpec defines the desired state of KnowledgeBaseMigration
+type KnowledgeBaseMigrationSpec struct {
+	CommonSpec `json:",inline"`
+
+	// KnowledgeBase is the name of the knowledgebase to migrate, in the same namespace
+	KnowledgeBase string `json:"knowledgeBase"`
+
+	// VectorStore defines the new vectorstore. if empty, use the current one of the knowledgebase
+	// +optional
+	VectorStore *TypedObjectReference `json:"vectorStore,omitempty"`
+
+	// Embedder defines the new embedder. if empty, use the current one of the knowledgebase
+	// +optional
+	Embedder *TypedObjectReference `json:"embedder,omitempty"`
+
+	// Decision confirms or rolls back the migration after the knowledgebase is switched.
+	// The old collection is kept for rollback until the migration is confirmed.
+	// +kubebuilder:validation:Enum=Confirm;Rollback
+	// +optional
+	Decision MigrationDecision `json:"decision,omitempty"`
+}
+
+type MigrationPhase string
+
+const (
+	MigrationPhasePending MigrationPhase = "Pending"
+	// MigrationPhaseMigrating re-embeds the files into the new collection in the background
+	MigrationPhaseMigrating MigrationPhase = "Migrating"
+	// MigrationPhaseSwitched means the knowledgebase uses the new collection, and waits for the decision
+	MigrationPhaseSwitched   MigrationPhase = "Switched"
+	MigrationPhaseConfirmed  MigrationPhase = "Confirmed"
+	MigrationPhaseRolledBack MigrationPhase = "RolledBack"
+	MigrationPhaseFailed     MigrationPhase = "Failed"
+)
+
+// MigrationTarget is where the documents of a knowledgebase are stored
+type MigrationTarget struct {
+	VectorStore *TypedObjectReference `json:"vectorStore,omitempty"`
+
+	Embedder *TypedObjectReference `json:"embedder,omitempty"`
+
+	// CollectionName is the collection in the vectorstore
+	CollectionName string `json:"collectionName,omitempty"`
+}
+
+// KnowledgeBaseMigrationStatus defines the observed state of KnowledgeBaseMigration
+type KnowledgeBaseMigrationStatus struct {
+	// Phase of the migration
+	Phase MigrationPhase `json:"phase,omitempty"`
+
+	// Message is the reason of the current phase
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Source is where the knowledgebase is stored before the migration, used to roll back
+	// +optional
+	Source *MigrationTarget `json:"source,omitempty"`
+
+	// Target is where the knowledgebase is migrated to
+	// +optional
+	Target *MigrationTarget `json:"target,omitempty"`
+
+	// TotalFiles is the number of embedded files of the knowledgebase to migrate
+	TotalFiles int `json:"totalFiles,omitempty"`
+
+	// MigratedFiles is the number of files which have been embedded into the new collection
+	MigratedFiles int `json:"migratedFiles,omitempty"`
+
+	// FailedFiles is the number of files which failed to be embedded into the new collection
+	FailedFiles int `json:"failedFiles,omitempty"`
+
+	// ExpectedChunks is the number of chunks of the knowledgebase before the migration
+	ExpectedChunks int `json:"expectedChunks,omitempty"`
+
+	// MigratedChunks is the number of chunks embedded into the new collection
+	MigratedChunks int `json:"migratedChunks,omitempty"`
+
+	// StartTime is the time when the files started to be migrated
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// SwitchTime is the time when the knowledgebase was switched to the new collection
+	// +optional
+	SwitchTime *metav1.Time `json:"switchTime,omitempty"`
+
+	// CompletionTime is the time when the migration was confirmed, rolled back or failed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="knowledgebase",type=string,JSONPath=`.spec.knowledgeBase`
+//+kubebuilder:printcolumn:name="phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="migrated",type=integer,JSONPath=`.status.migratedFiles`
+//+kubebuilder:printcolumn:name="total",type=integer,JSONPath=`.status.totalFiles`
+
+// KnowledgeBaseMigration is the Schema for the knowledgebasemigrations API.
+// It re-embeds the files of a knowledgebase into a new vectorstore and/or with a new embedder in the background,
+// and switches the knowledgebase to the new collection once all files are migrated.
+type KnowledgeBaseMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KnowledgeBaseMigrationSpec   `json:"spec,omitempty"`
+	Status KnowledgeBaseMigrationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KnowledgeBaseMigrationList contains a list of KnowledgeBaseMigration
+type KnowledgeBaseMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KnowledgeBaseMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KnowledgeBaseMigration{}, &KnowledgeBaseMigrationList{})
+}
@@ -81,6 +81,9 @@ func (llm LLM) Get3rdPartyModels() []string {
 	if llm.Spec.Models != nil && len(llm.Spec.Models) != 0 {
 		return llm.Spec.Models
 	}
+	if len(llm.Spec.ModelCapabilities) != 0 {
+		return modelNames(llm.Spec.ModelCapabilities)
+	}
 
 	switch llm.Spec.Type {
 	case llms.ZhiPuAI:
@@ -89,10 +92,62 @@ func (llm LLM) Get3rdPartyModels() []string {
 		return llms.OpenAIModels
 	case llms.Gemini:
 		return llms.GeminiModels
+	case llms.DashScope:
+		return llms.DashScopeModels
 	}
 	return []string{}
 }
 
+// GetModelCapability returns the declared capability of the model, the first model is used if model is empty
+func (llm LLM) GetModelCapability(model string) (ModelCapability, bool) {
+	if model == "" {
+		models := llm.GetModelList()
+		if len(models) == 0 {
+			return ModelCapability{}, false
+		}
+		model = models[0]
+	}
+	return GetModelCapability(llm.Spec.ModelCapabilities, model)
+}
+
+// SupportsFunctionCalling returns whether the model supports native function calling, the first model is used if model is empty.
+// The declared capability is used if any, otherwise only the well-known models of 3rd party providers are supported.
+func (llm LLM) SupportsFunctionCalling(model string) bool {
+	if model == "" {
+		models := llm.GetModelList()
+		if len(models) == 0 {
+			return false
+		}
+		model = models[0]
+	}
+	if capability, ok := GetModelCapability(llm.Spec.ModelCapabilities, model); ok {
+		return capability.FunctionCalling
+	}
+	if llm.Spec.Provider.GetType() != ProviderType3rdParty {
+		return false
+	}
+	return llms.SupportsFunctionCalling(llm.Spec.Type, model)
+}
+
+// SupportsVision returns whether the model accepts images as input, the first model is used if model is empty.
+// The declared capability is used if any, otherwise only the well-known models of 3rd party providers are supported.
+func (llm LLM) SupportsVision(model string) bool {
+	if model == "" {
+		models := llm.GetModelList()
+		if len(models) == 0 {
+			return false
+		}
+		model = models[0]
+	}
+	if capability, ok := GetModelCapability(llm.Spec.ModelCapabilities, model); ok {
+		return capability.Vision
+	}
+	if llm.Spec.Provider.GetType() != ProviderType3rdParty {
+		return false
+	}
+	return llms.SupportsVision(llm.Spec.Type, model)
+}
+
 func (llm LLM) ReadyCondition(msg string) Condition {
 	currCon := llm.Status.GetCondition(TypeReady)
 	// return current condition if condition not changed
@@ -35,6 +35,15 @@ type LLMSpec struct {
 	// Models provided by this LLM
 	// If not set,we will use default model list based on LLMType
 	Models []string `json:"models,omitempty"`
+
+	// ModelCapabilities declare the capabilities of models, like context length and function calling.
+	// If Models is not set, these models are provided by this LLM.
+	// +optional
+	ModelCapabilities []ModelCapability `json:"modelCapabilities,omitempty"`
+
+	// OpenAICompatible configures how to request the service when type is `openai-compatible`
+	// +optional
+	OpenAICompatible *OpenAICompatible `json:"openaiCompatible,omitempty"`
 }
 
 // LLMStatus defines the observed state of LLM
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	DefaultLLMGroupFailureThreshold = 5
+	DefaultLLMGroupOpenSeconds      = 60
+)
+
+// GetStrategy returns the strategy of the group, priority by default
+func (group LLMGroup) GetStrategy() LLMGroupStrategy {
+	if group.Spec.Strategy == "" {
+		return LLMGroupStrategyPriority
+	}
+	return group.Spec.Strategy
+}
+
+// MemberName returns a readable name of the member, like namespace/llm:model
+func (group LLMGroup) MemberName(member LLMGroupMember) string {
+	name := fmt.Sprintf("%s/%s", member.LLM.GetNamespace(group.Namespace), member.LLM.Name)
+	if member.Model != "" {
+		name = fmt.Sprintf("%s:%s", name, member.Model)
+	}
+	return name
+}
+
+func (group LLMGroup) ReadyCondition(msg string) Condition {
+	currCon := group.Status.GetCondition(TypeReady)
+	// return current condition if condition not changed
+	if currCon.Status == corev1.ConditionTrue && currCon.Reason == ReasonAvailable && currCon.Message == msg {
+		return currCon
+	}
+	return Condition{
+		Type:               TypeReady,
+		Status:             corev1.ConditionTrue,
+		Reason:             ReasonAvailable,
+		Message:            msg,
+		LastTransitionTime: metav1.Now(),
+		LastSuccessfulTime: metav1.Now(),
+	}
+}
+
+func (group LLMGroup) ErrorCondition(msg string) Condition {
+	currCon := group.Status.GetCondition(TypeReady)
+	// return current condition if condition not changed
+	if currCon.Status == corev1.ConditionFalse && currCon.Reason == ReasonUnavailable && currCon.Message == msg {
+		return currCon
+	}
+	return Condition{
+		Type:               TypeReady,
+		Status:             corev1.ConditionFalse,
+		Reason:             ReasonUnavailable,
+		Message:            msg,
+		LastSuccessfulTime: currCon.LastSuccessfulTime,
+		LastTransitionTime: metav1.Now(),
+	}
+}
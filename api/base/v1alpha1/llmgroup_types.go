@@ -0,0 +1,134 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LLMGroupStrategy defines how to choose the member llm for a request
+type LLMGroupStrategy string
+
+const (
+	// LLMGroupStrategyPriority always uses the member with the smallest priority, and fails over to the next one
+	LLMGroupStrategyPriority LLMGroupStrategy = "priority"
+	// LLMGroupStrategyWeightedRoundRobin spreads the requests across members by their weights
+	LLMGroupStrategyWeightedRoundRobin LLMGroupStrategy = "weightedRoundRobin"
+	// LLMGroupStrategyLeastLatency uses the member with the least recent latency
+	LLMGroupStrategyLeastLatency LLMGroupStrategy = "leastLatency"
+)
+
+// LLMGroupSpec defines the desired state of LLMGroup
+type LLMGroupSpec struct {
+	CommonSpec `json:",inline"`
+
+	// Strategy to choose the member for a request
+	// +kubebuilder:validation:Enum=priority;weightedRoundRobin;leastLatency
+	// +kubebuilder:default=priority
+	Strategy LLMGroupStrategy `json:"strategy,omitempty"`
+
+	// Members of this group
+	// +kubebuilder:validation:MinItems=1
+	Members []LLMGroupMember `json:"members"`
+
+	// Retry defines when and how many times to fail over to other members
+	// +optional
+	Retry *LLMGroupRetry `json:"retry,omitempty"`
+
+	// CircuitBreaker stops sending requests to a failing member for a while
+	// +optional
+	CircuitBreaker *LLMGroupCircuitBreaker `json:"circuitBreaker,omitempty"`
+}
+
+// LLMGroupMember is a LLM and model pair in the group
+type LLMGroupMember struct {
+	// LLM is the reference to the LLM
+	LLM TypedObjectReference `json:"llm"`
+
+	// Model to use, the first model of the LLM is used if not set
+	// +optional
+	Model string `json:"model,omitempty"`
+
+	// Priority of the member for the priority strategy, the smaller the earlier
+	// +optional
+	Priority int `json:"priority,omitempty"`
+
+	// Weight of the member for the weightedRoundRobin strategy
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	Weight int `json:"weight,omitempty"`
+}
+
+// LLMGroupRetry defines when and how many times to fail over to other members
+type LLMGroupRetry struct {
+	// MaxAttempts is the max number of members to try for a request, all members are tried if not set
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+
+	// ErrorCodes only fails over on errors which contain one of these codes, like 429, 503 or ZhiPuAI's 1302.
+	// All errors are failed over if not set.
+	// +optional
+	ErrorCodes []string `json:"errorCodes,omitempty"`
+}
+
+// LLMGroupCircuitBreaker stops sending requests to a failing member for a while
+type LLMGroupCircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures to open the circuit of a member
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=5
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+
+	// OpenSeconds is how long a member is skipped after its circuit is opened
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=60
+	OpenSeconds int `json:"openSeconds,omitempty"`
+}
+
+// LLMGroupStatus defines the observed state of LLMGroup
+type LLMGroupStatus struct {
+	// ConditionedStatus is the current status
+	ConditionedStatus `json:",inline"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="display-name",type=string,JSONPath=`.spec.displayName`
+//+kubebuilder:printcolumn:name="strategy",type=string,JSONPath=`.spec.strategy`
+
+// LLMGroup is the Schema for the llmgroups API
+type LLMGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LLMGroupSpec   `json:"spec,omitempty"`
+	Status LLMGroupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// LLMGroupList contains a list of LLMGroup
+type LLMGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LLMGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LLMGroup{}, &LLMGroupList{})
+}
@@ -37,9 +37,13 @@ const (
 type VectorStoreType string
 
 const (
-	VectorStoreTypeChroma   VectorStoreType = "chroma"
-	VectorStoreTypePGVector VectorStoreType = "pgvector"
-	VectorStoreTypeUnknown  VectorStoreType = "unknown"
+	VectorStoreTypeChroma        VectorStoreType = "chroma"
+	VectorStoreTypePGVector      VectorStoreType = "pgvector"
+	VectorStoreTypeMilvus        VectorStoreType = "milvus"
+	VectorStoreTypeQdrant        VectorStoreType = "qdrant"
+	VectorStoreTypeElasticsearch VectorStoreType = "elasticsearch"
+	VectorStoreTypeEmbedded      VectorStoreType = "embedded"
+	VectorStoreTypeUnknown       VectorStoreType = "unknown"
 )
 
 func (vs VectorStoreSpec) Type() VectorStoreType {
@@ -48,6 +52,14 @@ func (vs VectorStoreSpec) Type() VectorStoreType {
 		return VectorStoreTypeChroma
 	case vs.PGVector != nil:
 		return VectorStoreTypePGVector
+	case vs.Milvus != nil:
+		return VectorStoreTypeMilvus
+	case vs.Qdrant != nil:
+		return VectorStoreTypeQdrant
+	case vs.Elasticsearch != nil:
+		return VectorStoreTypeElasticsearch
+	case vs.Embedded != nil:
+		return VectorStoreTypeEmbedded
 	default:
 		return VectorStoreTypeUnknown
 	}
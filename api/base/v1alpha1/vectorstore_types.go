@@ -31,6 +31,14 @@ type VectorStoreSpec struct {
 	Chroma *Chroma `json:"chroma,omitempty"`
 
 	PGVector *PGVector `json:"pgvector,omitempty"`
+
+	Milvus *Milvus `json:"milvus,omitempty"`
+
+	Qdrant *Qdrant `json:"qdrant,omitempty"`
+
+	Elasticsearch *Elasticsearch `json:"elasticsearch,omitempty"`
+
+	Embedded *Embedded `json:"embedded,omitempty"`
 }
 
 // Chroma defines the configuration of Chroma
@@ -51,6 +59,67 @@ type PGVector struct {
 	DataSourceRef *TypedObjectReference `json:"dataSourceRef,omitempty"`
 }
 
+// Milvus defines the configuration of Milvus, the endpoint is the address of its RESTful api(v2).
+// The token of the api is the apiKey in the auth secret, or user:password if no apiKey.
+type Milvus struct {
+	// DatabaseName defines the database of the collections. if empty, use `default`
+	DatabaseName string `json:"databaseName,omitempty"`
+	// MetricType defines how to measure the similarity of vectors.
+	// L2 distances are converted to similarities by 1 / (1 + distance) as the scores of documents.
+	// +kubebuilder:validation:Enum=COSINE;IP;L2
+	// +kubebuilder:default=COSINE
+	MetricType string `json:"metricType,omitempty"`
+}
+
+// Qdrant defines the configuration of Qdrant, the endpoint is the address of its RESTful api.
+// The apiKey in the auth secret is used as the api key if exists.
+type Qdrant struct {
+	// Distance defines how to measure the similarity of vectors.
+	// Euclid distances are converted to similarities by 1 / (1 + distance) as the scores of documents.
+	// +kubebuilder:validation:Enum=Cosine;Dot;Euclid
+	// +kubebuilder:default=Cosine
+	Distance string `json:"distance,omitempty"`
+}
+
+// Elasticsearch defines the configuration of Elasticsearch, the endpoint is the address of its RESTful api.
+// The apiKey in the auth secret is used as the api key, or user and password for basic authentication.
+type Elasticsearch struct {
+	// Similarity defines how to measure the similarity of vectors
+	// +kubebuilder:validation:Enum=cosine;dot_product;l2_norm
+	// +kubebuilder:default=cosine
+	Similarity string `json:"similarity,omitempty"`
+	// NumCandidates defines the number of candidates each shard considers in knn search. if 0, use 10 times of the requested documents
+	NumCandidates int `json:"numCandidates,omitempty"`
+}
+
+type EmbeddedStorage string
+
+const (
+	// EmbeddedStorageLocal persists the collections to a local directory, usually a mounted pvc
+	EmbeddedStorageLocal EmbeddedStorage = "local"
+	// EmbeddedStorageOSS persists the collections to the system datasource
+	EmbeddedStorageOSS EmbeddedStorage = "oss"
+)
+
+// Embedded defines the configuration of the embedded vectorstore, which runs inside arcadia with a brute-force index.
+// It needs no external services, but is only for single-node and test setups.
+type Embedded struct {
+	// Storage defines where the collections are persisted.
+	// `oss` stores them in the bucket of the vectorstore namespace of the system datasource, with Path as the object prefix;
+	// `local` stores them in Path, which must be a persistent volume shared by the controller and the apiserver.
+	// +kubebuilder:validation:Enum=local;oss
+	// +kubebuilder:default=oss
+	Storage EmbeddedStorage `json:"storage,omitempty"`
+	// Path defines the directory or the object prefix of the collections.
+	// It is required for `local`, and `vectorstore/<name>` is used for `oss` if empty.
+	Path string `json:"path,omitempty"`
+	// DistanceFunction defines how to measure the similarity of vectors.
+	// l2 distances are converted to similarities by 1 / (1 + distance) as the scores of documents.
+	// +kubebuilder:validation:Enum=cosine;l2;ip
+	// +kubebuilder:default=cosine
+	DistanceFunction string `json:"distanceFunction,omitempty"`
+}
+
 // VectorStoreStatus defines the observed state of VectorStore
 type VectorStoreStatus struct {
 	// ConditionedStatus is the current status
@@ -23,7 +23,8 @@ package v1alpha1
 
 import (
 	"github.com/kubeagi/arcadia/pkg/llms/zhipuai"
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -98,6 +99,31 @@ func (in *ApplicationSpec) DeepCopyInto(out *ApplicationSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ResponseCache != nil {
+		in, out := &in.ResponseCache, &out.ResponseCache
+		*out = new(ResponseCache)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(Quota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageCaption != nil {
+		in, out := &in.ImageCaption, &out.ImageCaption
+		*out = new(ImageCaption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConversationFile != nil {
+		in, out := &in.ConversationFile, &out.ConversationFile
+		*out = new(ConversationFileConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Handoff != nil {
+		in, out := &in.Handoff, &out.Handoff
+		*out = new(Handoff)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationSpec.
@@ -195,6 +221,61 @@ func (in *ConditionedStatus) DeepCopy() *ConditionedStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConversationFileConfig) DeepCopyInto(out *ConversationFileConfig) {
+	*out = *in
+	if in.AllowedTypes != nil {
+		in, out := &in.AllowedTypes, &out.AllowedTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConversationFileConfig.
+func (in *ConversationFileConfig) DeepCopy() *ConversationFileConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ConversationFileConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrawlStatus) DeepCopyInto(out *CrawlStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrawlStatus.
+func (in *CrawlStatus) DeepCopy() *CrawlStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrawlStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashScopeEmbedding) DeepCopyInto(out *DashScopeEmbedding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashScopeEmbedding.
+func (in *DashScopeEmbedding) DeepCopy() *DashScopeEmbedding {
+	if in == nil {
+		return nil
+	}
+	out := new(DashScopeEmbedding)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Dataset) DeepCopyInto(out *Dataset) {
 	*out = *in
@@ -363,12 +444,17 @@ func (in *DatasourceSpec) DeepCopyInto(out *DatasourceSpec) {
 	if in.PostgreSQL != nil {
 		in, out := &in.PostgreSQL, &out.PostgreSQL
 		*out = new(PostgreSQL)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Web != nil {
 		in, out := &in.Web, &out.Web
 		*out = new(Web)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(Git)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -385,6 +471,11 @@ func (in *DatasourceSpec) DeepCopy() *DatasourceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatasourceStatus) DeepCopyInto(out *DatasourceStatus) {
 	*out = *in
+	if in.Crawl != nil {
+		in, out := &in.Crawl, &out.Crawl
+		*out = new(CrawlStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
 }
 
@@ -398,6 +489,36 @@ func (in *DatasourceStatus) DeepCopy() *DatasourceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Elasticsearch) DeepCopyInto(out *Elasticsearch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Elasticsearch.
+func (in *Elasticsearch) DeepCopy() *Elasticsearch {
+	if in == nil {
+		return nil
+	}
+	out := new(Elasticsearch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Embedded) DeepCopyInto(out *Embedded) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Embedded.
+func (in *Embedded) DeepCopy() *Embedded {
+	if in == nil {
+		return nil
+	}
+	out := new(Embedded)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Embedder) DeepCopyInto(out *Embedder) {
 	*out = *in
@@ -467,6 +588,26 @@ func (in *EmbedderSpec) DeepCopyInto(out *EmbedderSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ModelCapabilities != nil {
+		in, out := &in.ModelCapabilities, &out.ModelCapabilities
+		*out = make([]ModelCapability, len(*in))
+		copy(*out, *in)
+	}
+	if in.OpenAICompatible != nil {
+		in, out := &in.OpenAICompatible, &out.OpenAICompatible
+		*out = new(OpenAICompatible)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DashScope != nil {
+		in, out := &in.DashScope, &out.DashScope
+		*out = new(DashScopeEmbedding)
+		**out = **in
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimit)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmbedderSpec.
@@ -495,6 +636,21 @@ func (in *EmbedderStatus) DeepCopy() *EmbedderStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmbeddingIdentity) DeepCopyInto(out *EmbeddingIdentity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmbeddingIdentity.
+func (in *EmbeddingIdentity) DeepCopy() *EmbeddingIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(EmbeddingIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EmbeddingOptions) DeepCopyInto(out *EmbeddingOptions) {
 	*out = *in
@@ -515,6 +671,26 @@ func (in *EmbeddingOptions) DeepCopy() *EmbeddingOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmbeddingProgress) DeepCopyInto(out *EmbeddingProgress) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.EstimatedCompletionTime != nil {
+		in, out := &in.EstimatedCompletionTime, &out.EstimatedCompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmbeddingProgress.
+func (in *EmbeddingProgress) DeepCopy() *EmbeddingProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(EmbeddingProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Endpoint) DeepCopyInto(out *Endpoint) {
 	*out = *in
@@ -646,6 +822,51 @@ func (in *FileWithVersion) DeepCopy() *FileWithVersion {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Git) DeepCopyInto(out *Git) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Git.
+func (in *Git) DeepCopy() *Git {
+	if in == nil {
+		return nil
+	}
+	out := new(Git)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Handoff) DeepCopyInto(out *Handoff) {
+	*out = *in
+	if in.Keywords != nil {
+		in, out := &in.Keywords, &out.Keywords
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MinScore != nil {
+		in, out := &in.MinScore, &out.MinScore
+		*out = new(float32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Handoff.
+func (in *Handoff) DeepCopy() *Handoff {
+	if in == nil {
+		return nil
+	}
+	out := new(Handoff)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Image) DeepCopyInto(out *Image) {
 	*out = *in
@@ -661,6 +882,22 @@ func (in *Image) DeepCopy() *Image {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCaption) DeepCopyInto(out *ImageCaption) {
+	*out = *in
+	in.LLM.DeepCopyInto(&out.LLM)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCaption.
+func (in *ImageCaption) DeepCopy() *ImageCaption {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCaption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KnowledgeBase) DeepCopyInto(out *KnowledgeBase) {
 	*out = *in
@@ -720,6 +957,128 @@ func (in *KnowledgeBaseList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KnowledgeBaseMigration) DeepCopyInto(out *KnowledgeBaseMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KnowledgeBaseMigration.
+func (in *KnowledgeBaseMigration) DeepCopy() *KnowledgeBaseMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(KnowledgeBaseMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KnowledgeBaseMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KnowledgeBaseMigrationList) DeepCopyInto(out *KnowledgeBaseMigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KnowledgeBaseMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KnowledgeBaseMigrationList.
+func (in *KnowledgeBaseMigrationList) DeepCopy() *KnowledgeBaseMigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(KnowledgeBaseMigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KnowledgeBaseMigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KnowledgeBaseMigrationSpec) DeepCopyInto(out *KnowledgeBaseMigrationSpec) {
+	*out = *in
+	out.CommonSpec = in.CommonSpec
+	if in.VectorStore != nil {
+		in, out := &in.VectorStore, &out.VectorStore
+		*out = new(TypedObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Embedder != nil {
+		in, out := &in.Embedder, &out.Embedder
+		*out = new(TypedObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KnowledgeBaseMigrationSpec.
+func (in *KnowledgeBaseMigrationSpec) DeepCopy() *KnowledgeBaseMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KnowledgeBaseMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KnowledgeBaseMigrationStatus) DeepCopyInto(out *KnowledgeBaseMigrationStatus) {
+	*out = *in
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(MigrationTarget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(MigrationTarget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SwitchTime != nil {
+		in, out := &in.SwitchTime, &out.SwitchTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KnowledgeBaseMigrationStatus.
+func (in *KnowledgeBaseMigrationStatus) DeepCopy() *KnowledgeBaseMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KnowledgeBaseMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KnowledgeBaseSpec) DeepCopyInto(out *KnowledgeBaseSpec) {
 	*out = *in
@@ -742,6 +1101,11 @@ func (in *KnowledgeBaseSpec) DeepCopyInto(out *KnowledgeBaseSpec) {
 		}
 	}
 	in.EmbeddingOptions.DeepCopyInto(&out.EmbeddingOptions)
+	if in.SyncPolicy != nil {
+		in, out := &in.SyncPolicy, &out.SyncPolicy
+		*out = new(SyncPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KnowledgeBaseSpec.
@@ -764,6 +1128,21 @@ func (in *KnowledgeBaseStatus) DeepCopyInto(out *KnowledgeBaseStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(EmbeddingProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sync != nil {
+		in, out := &in.Sync, &out.Sync
+		*out = new(SyncStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Embedding != nil {
+		in, out := &in.Embedding, &out.Embedding
+		*out = new(EmbeddingIdentity)
+		**out = **in
+	}
 	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
 }
 
@@ -805,15 +1184,174 @@ func (in *LLM) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LLMList) DeepCopyInto(out *LLMList) {
+func (in *LLMGroup) DeepCopyInto(out *LLMGroup) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]LLM, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMGroup.
+func (in *LLMGroup) DeepCopy() *LLMGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(LLMGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LLMGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLMGroupCircuitBreaker) DeepCopyInto(out *LLMGroupCircuitBreaker) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMGroupCircuitBreaker.
+func (in *LLMGroupCircuitBreaker) DeepCopy() *LLMGroupCircuitBreaker {
+	if in == nil {
+		return nil
+	}
+	out := new(LLMGroupCircuitBreaker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLMGroupList) DeepCopyInto(out *LLMGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LLMGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMGroupList.
+func (in *LLMGroupList) DeepCopy() *LLMGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(LLMGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LLMGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLMGroupMember) DeepCopyInto(out *LLMGroupMember) {
+	*out = *in
+	in.LLM.DeepCopyInto(&out.LLM)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMGroupMember.
+func (in *LLMGroupMember) DeepCopy() *LLMGroupMember {
+	if in == nil {
+		return nil
+	}
+	out := new(LLMGroupMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLMGroupRetry) DeepCopyInto(out *LLMGroupRetry) {
+	*out = *in
+	if in.ErrorCodes != nil {
+		in, out := &in.ErrorCodes, &out.ErrorCodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMGroupRetry.
+func (in *LLMGroupRetry) DeepCopy() *LLMGroupRetry {
+	if in == nil {
+		return nil
+	}
+	out := new(LLMGroupRetry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLMGroupSpec) DeepCopyInto(out *LLMGroupSpec) {
+	*out = *in
+	out.CommonSpec = in.CommonSpec
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]LLMGroupMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(LLMGroupRetry)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(LLMGroupCircuitBreaker)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMGroupSpec.
+func (in *LLMGroupSpec) DeepCopy() *LLMGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LLMGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLMGroupStatus) DeepCopyInto(out *LLMGroupStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMGroupStatus.
+func (in *LLMGroupStatus) DeepCopy() *LLMGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LLMGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLMList) DeepCopyInto(out *LLMList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LLM, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
@@ -846,6 +1384,16 @@ func (in *LLMSpec) DeepCopyInto(out *LLMSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ModelCapabilities != nil {
+		in, out := &in.ModelCapabilities, &out.ModelCapabilities
+		*out = make([]ModelCapability, len(*in))
+		copy(*out, *in)
+	}
+	if in.OpenAICompatible != nil {
+		in, out := &in.OpenAICompatible, &out.OpenAICompatible
+		*out = new(OpenAICompatible)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMSpec.
@@ -874,6 +1422,46 @@ func (in *LLMStatus) DeepCopy() *LLMStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationTarget) DeepCopyInto(out *MigrationTarget) {
+	*out = *in
+	if in.VectorStore != nil {
+		in, out := &in.VectorStore, &out.VectorStore
+		*out = new(TypedObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Embedder != nil {
+		in, out := &in.Embedder, &out.Embedder
+		*out = new(TypedObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationTarget.
+func (in *MigrationTarget) DeepCopy() *MigrationTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Milvus) DeepCopyInto(out *Milvus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Milvus.
+func (in *Milvus) DeepCopy() *Milvus {
+	if in == nil {
+		return nil
+	}
+	out := new(Milvus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Model) DeepCopyInto(out *Model) {
 	*out = *in
@@ -901,6 +1489,21 @@ func (in *Model) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelCapability) DeepCopyInto(out *ModelCapability) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelCapability.
+func (in *ModelCapability) DeepCopy() *ModelCapability {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelCapability)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModelList) DeepCopyInto(out *ModelList) {
 	*out = *in
@@ -1026,6 +1629,35 @@ func (in *OSS) DeepCopy() *OSS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenAICompatible) DeepCopyInto(out *OpenAICompatible) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraBody != nil {
+		in, out := &in.ExtraBody, &out.ExtraBody
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenAICompatible.
+func (in *OpenAICompatible) DeepCopy() *OpenAICompatible {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenAICompatible)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PGVector) DeepCopyInto(out *PGVector) {
 	*out = *in
@@ -1049,6 +1681,13 @@ func (in *PGVector) DeepCopy() *PGVector {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgreSQL) DeepCopyInto(out *PostgreSQL) {
 	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]PostgreSQLTable, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgreSQL.
@@ -1061,6 +1700,31 @@ func (in *PostgreSQL) DeepCopy() *PostgreSQL {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgreSQLTable) DeepCopyInto(out *PostgreSQLTable) {
+	*out = *in
+	if in.ContentColumns != nil {
+		in, out := &in.ContentColumns, &out.ContentColumns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MetadataColumns != nil {
+		in, out := &in.MetadataColumns, &out.MetadataColumns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgreSQLTable.
+func (in *PostgreSQLTable) DeepCopy() *PostgreSQLTable {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgreSQLTable)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Prompt) DeepCopyInto(out *Prompt) {
 	*out = *in
@@ -1191,6 +1855,61 @@ func (in *Provider) DeepCopy() *Provider {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Qdrant) DeepCopyInto(out *Qdrant) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Qdrant.
+func (in *Qdrant) DeepCopy() *Qdrant {
+	if in == nil {
+		return nil
+	}
+	out := new(Qdrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Quota) DeepCopyInto(out *Quota) {
+	*out = *in
+	if in.PerUser != nil {
+		in, out := &in.PerUser, &out.PerUser
+		*out = new(QuotaLimit)
+		**out = **in
+	}
+	if in.PerApp != nil {
+		in, out := &in.PerApp, &out.PerApp
+		*out = new(QuotaLimit)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Quota.
+func (in *Quota) DeepCopy() *Quota {
+	if in == nil {
+		return nil
+	}
+	out := new(Quota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaLimit) DeepCopyInto(out *QuotaLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaLimit.
+func (in *QuotaLimit) DeepCopy() *QuotaLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RDMA) DeepCopyInto(out *RDMA) {
 	*out = *in
@@ -1213,6 +1932,145 @@ func (in *RDMA) DeepCopy() *RDMA {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimit) DeepCopyInto(out *RateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimit.
+func (in *RateLimit) DeepCopy() *RateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResponseCache) DeepCopyInto(out *ResponseCache) {
+	*out = *in
+	if in.Embedder != nil {
+		in, out := &in.Embedder, &out.Embedder
+		*out = new(TypedObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResponseCache.
+func (in *ResponseCache) DeepCopy() *ResponseCache {
+	if in == nil {
+		return nil
+	}
+	out := new(ResponseCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncPolicy) DeepCopyInto(out *SyncPolicy) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncPolicy.
+func (in *SyncPolicy) DeepCopy() *SyncPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncRecord) DeepCopyInto(out *SyncRecord) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRecord.
+func (in *SyncRecord) DeepCopy() *SyncRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncStatus) DeepCopyInto(out *SyncStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextSyncTime != nil {
+		in, out := &in.NextSyncTime, &out.NextSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]SyncRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FileGroups != nil {
+		in, out := &in.FileGroups, &out.FileGroups
+		*out = make([]SyncedFileGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncStatus.
+func (in *SyncStatus) DeepCopy() *SyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncedFileGroup) DeepCopyInto(out *SyncedFileGroup) {
+	*out = *in
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(TypedObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Added != nil {
+		in, out := &in.Added, &out.Added
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Removed != nil {
+		in, out := &in.Removed, &out.Removed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncedFileGroup.
+func (in *SyncedFileGroup) DeepCopy() *SyncedFileGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncedFileGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TypedObjectReference) DeepCopyInto(out *TypedObjectReference) {
 	*out = *in
@@ -1316,6 +2174,26 @@ func (in *VectorStoreSpec) DeepCopyInto(out *VectorStoreSpec) {
 		*out = new(PGVector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Milvus != nil {
+		in, out := &in.Milvus, &out.Milvus
+		*out = new(Milvus)
+		**out = **in
+	}
+	if in.Qdrant != nil {
+		in, out := &in.Qdrant, &out.Qdrant
+		*out = new(Qdrant)
+		**out = **in
+	}
+	if in.Elasticsearch != nil {
+		in, out := &in.Elasticsearch, &out.Elasticsearch
+		*out = new(Elasticsearch)
+		**out = **in
+	}
+	if in.Embedded != nil {
+		in, out := &in.Embedded, &out.Embedded
+		*out = new(Embedded)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VectorStoreSpec.
@@ -1457,6 +2335,11 @@ func (in *VersionedDatasetStatus) DeepCopy() *VersionedDatasetStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Web) DeepCopyInto(out *Web) {
 	*out = *in
+	if in.Crawler != nil {
+		in, out := &in.Crawler, &out.Crawler
+		*out = new(WebCrawler)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Web.
@@ -1489,6 +2372,46 @@ func (in *WebConfig) DeepCopy() *WebConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebCrawler) DeepCopyInto(out *WebCrawler) {
+	*out = *in
+	if in.SeedURLs != nil {
+		in, out := &in.SeedURLs, &out.SeedURLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IncludePatterns != nil {
+		in, out := &in.IncludePatterns, &out.IncludePatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludePatterns != nil {
+		in, out := &in.ExcludePatterns, &out.ExcludePatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Delay != nil {
+		in, out := &in.Delay, &out.Delay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebCrawler.
+func (in *WebCrawler) DeepCopy() *WebCrawler {
+	if in == nil {
+		return nil
+	}
+	out := new(WebCrawler)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Worker) DeepCopyInto(out *Worker) {
 	*out = *in
@@ -1565,21 +2488,21 @@ func (in *WorkerSpec) DeepCopyInto(out *WorkerSpec) {
 	in.Resources.DeepCopyInto(&out.Resources)
 	if in.MatchExpressions != nil {
 		in, out := &in.MatchExpressions, &out.MatchExpressions
-		*out = make([]v1.NodeSelectorRequirement, len(*in))
+		*out = make([]corev1.NodeSelectorRequirement, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.AdditionalEnvs != nil {
 		in, out := &in.AdditionalEnvs, &out.AdditionalEnvs
-		*out = make([]v1.EnvVar, len(*in))
+		*out = make([]corev1.EnvVar, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.Storage != nil {
 		in, out := &in.Storage, &out.Storage
-		*out = new(v1.PersistentVolumeClaimSpec)
+		*out = new(corev1.PersistentVolumeClaimSpec)
 		(*in).DeepCopyInto(*out)
 	}
 	out.Loader = in.Loader
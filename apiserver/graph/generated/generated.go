@@ -49,6 +49,8 @@ type ResolverRoot interface {
 	DatasourceQuery() DatasourceQueryResolver
 	EmbedderMutation() EmbedderMutationResolver
 	EmbedderQuery() EmbedderQueryResolver
+	FeedbackMutation() FeedbackMutationResolver
+	FeedbackQuery() FeedbackQueryResolver
 	GPTQuery() GPTQueryResolver
 	KnowledgeBaseMutation() KnowledgeBaseMutationResolver
 	KnowledgeBaseQuery() KnowledgeBaseQueryResolver
@@ -65,6 +67,7 @@ type ResolverRoot interface {
 	RAGMutation() RAGMutationResolver
 	RAGQuery() RAGQueryResolver
 	RayClusterQuery() RayClusterQueryResolver
+	TokenUsageQuery() TokenUsageQueryResolver
 	VersionedDataset() VersionedDatasetResolver
 	VersionedDatasetMutation() VersionedDatasetMutationResolver
 	VersionedDatasetQuery() VersionedDatasetQueryResolver
@@ -372,6 +375,46 @@ type ComplexityRoot struct {
 		Versions          func(childComplexity int) int
 	}
 
+	Feedback struct {
+		Answer            func(childComplexity int) int
+		AppName           func(childComplexity int) int
+		Category          func(childComplexity int) int
+		Comment           func(childComplexity int) int
+		ConversationID    func(childComplexity int) int
+		CreationTimestamp func(childComplexity int) int
+		ExpectedAnswer    func(childComplexity int) int
+		MessageID         func(childComplexity int) int
+		Namespace         func(childComplexity int) int
+		Query             func(childComplexity int) int
+		Rating            func(childComplexity int) int
+		UpdateTimestamp   func(childComplexity int) int
+		User              func(childComplexity int) int
+		Version           func(childComplexity int) int
+	}
+
+	FeedbackCategoryCount struct {
+		Category func(childComplexity int) int
+		Dislikes func(childComplexity int) int
+	}
+
+	FeedbackMutation struct {
+		ExportFeedbacks func(childComplexity int, input ExportFeedbackInput) int
+	}
+
+	FeedbackQuery struct {
+		ListFeedbacks      func(childComplexity int, input ListFeedbackInput) int
+		SummarizeFeedbacks func(childComplexity int, input ListFeedbackInput) int
+	}
+
+	FeedbackSummary struct {
+		AppName    func(childComplexity int) int
+		Categories func(childComplexity int) int
+		Dislikes   func(childComplexity int) int
+		Likes      func(childComplexity int) int
+		Namespace  func(childComplexity int) int
+		Total      func(childComplexity int) int
+	}
+
 	FileDetails struct {
 		EndTime   func(childComplexity int) int
 		FileName  func(childComplexity int) int
@@ -567,6 +610,7 @@ type ComplexityRoot struct {
 		Dataset          func(childComplexity int) int
 		Datasource       func(childComplexity int) int
 		Embedder         func(childComplexity int) int
+		Feedback         func(childComplexity int) int
 		Hello            func(childComplexity int, name string) int
 		KnowledgeBase    func(childComplexity int) int
 		Model            func(childComplexity int) int
@@ -636,6 +680,7 @@ type ComplexityRoot struct {
 		Dataset          func(childComplexity int) int
 		Datasource       func(childComplexity int) int
 		Embedder         func(childComplexity int) int
+		Feedback         func(childComplexity int) int
 		Gpt              func(childComplexity int) int
 		Hello            func(childComplexity int, name string) int
 		KnowledgeBase    func(childComplexity int) int
@@ -645,6 +690,7 @@ type ComplexityRoot struct {
 		Node             func(childComplexity int) int
 		Rag              func(childComplexity int) int
 		RayCluster       func(childComplexity int) int
+		TokenUsage       func(childComplexity int) int
 		VersionedDataset func(childComplexity int) int
 		Worker           func(childComplexity int) int
 	}
@@ -730,6 +776,21 @@ type ComplexityRoot struct {
 		MatchLabels      func(childComplexity int) int
 	}
 
+	TokenUsage struct {
+		AppName          func(childComplexity int) int
+		CompletionTokens func(childComplexity int) int
+		Date             func(childComplexity int) int
+		Namespace        func(childComplexity int) int
+		PromptTokens     func(childComplexity int) int
+		Requests         func(childComplexity int) int
+		TotalTokens      func(childComplexity int) int
+		User             func(childComplexity int) int
+	}
+
+	TokenUsageQuery struct {
+		ListTokenUsages func(childComplexity int, input ListTokenUsageInput) int
+	}
+
 	Tool struct {
 		Name   func(childComplexity int) int
 		Params func(childComplexity int) int
@@ -892,6 +953,13 @@ type EmbedderQueryResolver interface {
 	GetEmbedder(ctx context.Context, obj *EmbedderQuery, name string, namespace string) (*Embedder, error)
 	ListEmbedders(ctx context.Context, obj *EmbedderQuery, input ListCommonInput) (*PaginatedResult, error)
 }
+type FeedbackMutationResolver interface {
+	ExportFeedbacks(ctx context.Context, obj *FeedbackMutation, input ExportFeedbackInput) (string, error)
+}
+type FeedbackQueryResolver interface {
+	SummarizeFeedbacks(ctx context.Context, obj *FeedbackQuery, input ListFeedbackInput) ([]*FeedbackSummary, error)
+	ListFeedbacks(ctx context.Context, obj *FeedbackQuery, input ListFeedbackInput) ([]*Feedback, error)
+}
 type GPTQueryResolver interface {
 	GetGpt(ctx context.Context, obj *GPTQuery, name string) (*Gpt, error)
 	ListGpt(ctx context.Context, obj *GPTQuery, input ListGPTInput) (*PaginatedResult, error)
@@ -940,6 +1008,7 @@ type MutationResolver interface {
 	Dataset(ctx context.Context) (*DatasetMutation, error)
 	Datasource(ctx context.Context) (*DatasourceMutation, error)
 	Embedder(ctx context.Context) (*EmbedderMutation, error)
+	Feedback(ctx context.Context) (*FeedbackMutation, error)
 	KnowledgeBase(ctx context.Context) (*KnowledgeBaseMutation, error)
 	Model(ctx context.Context) (*ModelMutation, error)
 	ModelService(ctx context.Context) (*ModelServiceMutation, error)
@@ -957,6 +1026,7 @@ type QueryResolver interface {
 	Dataset(ctx context.Context) (*DatasetQuery, error)
 	Datasource(ctx context.Context) (*DatasourceQuery, error)
 	Embedder(ctx context.Context) (*EmbedderQuery, error)
+	Feedback(ctx context.Context) (*FeedbackQuery, error)
 	Gpt(ctx context.Context) (*GPTQuery, error)
 	KnowledgeBase(ctx context.Context) (*KnowledgeBaseQuery, error)
 	Llm(ctx context.Context) (*LLMQuery, error)
@@ -965,6 +1035,7 @@ type QueryResolver interface {
 	Node(ctx context.Context) (*NodeQuery, error)
 	Rag(ctx context.Context) (*RAGQuery, error)
 	RayCluster(ctx context.Context) (*RayClusterQuery, error)
+	TokenUsage(ctx context.Context) (*TokenUsageQuery, error)
 	VersionedDataset(ctx context.Context) (*VersionedDatasetQuery, error)
 	Worker(ctx context.Context) (*WorkerQuery, error)
 }
@@ -987,6 +1058,9 @@ type RAGQueryResolver interface {
 type RayClusterQueryResolver interface {
 	ListRayClusters(ctx context.Context, obj *RayClusterQuery, input ListCommonInput) (*PaginatedResult, error)
 }
+type TokenUsageQueryResolver interface {
+	ListTokenUsages(ctx context.Context, obj *TokenUsageQuery, input ListTokenUsageInput) ([]*TokenUsage, error)
+}
 type VersionedDatasetResolver interface {
 	Files(ctx context.Context, obj *VersionedDataset, input *FileFilter) (*PaginatedResult, error)
 }
@@ -2609,6 +2683,196 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.F.Versions(childComplexity), true
 
+	case "Feedback.answer":
+		if e.complexity.Feedback.Answer == nil {
+			break
+		}
+
+		return e.complexity.Feedback.Answer(childComplexity), true
+
+	case "Feedback.appName":
+		if e.complexity.Feedback.AppName == nil {
+			break
+		}
+
+		return e.complexity.Feedback.AppName(childComplexity), true
+
+	case "Feedback.category":
+		if e.complexity.Feedback.Category == nil {
+			break
+		}
+
+		return e.complexity.Feedback.Category(childComplexity), true
+
+	case "Feedback.comment":
+		if e.complexity.Feedback.Comment == nil {
+			break
+		}
+
+		return e.complexity.Feedback.Comment(childComplexity), true
+
+	case "Feedback.conversationId":
+		if e.complexity.Feedback.ConversationID == nil {
+			break
+		}
+
+		return e.complexity.Feedback.ConversationID(childComplexity), true
+
+	case "Feedback.creationTimestamp":
+		if e.complexity.Feedback.CreationTimestamp == nil {
+			break
+		}
+
+		return e.complexity.Feedback.CreationTimestamp(childComplexity), true
+
+	case "Feedback.expectedAnswer":
+		if e.complexity.Feedback.ExpectedAnswer == nil {
+			break
+		}
+
+		return e.complexity.Feedback.ExpectedAnswer(childComplexity), true
+
+	case "Feedback.messageId":
+		if e.complexity.Feedback.MessageID == nil {
+			break
+		}
+
+		return e.complexity.Feedback.MessageID(childComplexity), true
+
+	case "Feedback.namespace":
+		if e.complexity.Feedback.Namespace == nil {
+			break
+		}
+
+		return e.complexity.Feedback.Namespace(childComplexity), true
+
+	case "Feedback.query":
+		if e.complexity.Feedback.Query == nil {
+			break
+		}
+
+		return e.complexity.Feedback.Query(childComplexity), true
+
+	case "Feedback.rating":
+		if e.complexity.Feedback.Rating == nil {
+			break
+		}
+
+		return e.complexity.Feedback.Rating(childComplexity), true
+
+	case "Feedback.updateTimestamp":
+		if e.complexity.Feedback.UpdateTimestamp == nil {
+			break
+		}
+
+		return e.complexity.Feedback.UpdateTimestamp(childComplexity), true
+
+	case "Feedback.user":
+		if e.complexity.Feedback.User == nil {
+			break
+		}
+
+		return e.complexity.Feedback.User(childComplexity), true
+
+	case "Feedback.version":
+		if e.complexity.Feedback.Version == nil {
+			break
+		}
+
+		return e.complexity.Feedback.Version(childComplexity), true
+
+	case "FeedbackCategoryCount.category":
+		if e.complexity.FeedbackCategoryCount.Category == nil {
+			break
+		}
+
+		return e.complexity.FeedbackCategoryCount.Category(childComplexity), true
+
+	case "FeedbackCategoryCount.dislikes":
+		if e.complexity.FeedbackCategoryCount.Dislikes == nil {
+			break
+		}
+
+		return e.complexity.FeedbackCategoryCount.Dislikes(childComplexity), true
+
+	case "FeedbackMutation.exportFeedbacks":
+		if e.complexity.FeedbackMutation.ExportFeedbacks == nil {
+			break
+		}
+
+		args, err := ec.field_FeedbackMutation_exportFeedbacks_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.FeedbackMutation.ExportFeedbacks(childComplexity, args["input"].(ExportFeedbackInput)), true
+
+	case "FeedbackQuery.listFeedbacks":
+		if e.complexity.FeedbackQuery.ListFeedbacks == nil {
+			break
+		}
+
+		args, err := ec.field_FeedbackQuery_listFeedbacks_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.FeedbackQuery.ListFeedbacks(childComplexity, args["input"].(ListFeedbackInput)), true
+
+	case "FeedbackQuery.summarizeFeedbacks":
+		if e.complexity.FeedbackQuery.SummarizeFeedbacks == nil {
+			break
+		}
+
+		args, err := ec.field_FeedbackQuery_summarizeFeedbacks_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.FeedbackQuery.SummarizeFeedbacks(childComplexity, args["input"].(ListFeedbackInput)), true
+
+	case "FeedbackSummary.appName":
+		if e.complexity.FeedbackSummary.AppName == nil {
+			break
+		}
+
+		return e.complexity.FeedbackSummary.AppName(childComplexity), true
+
+	case "FeedbackSummary.categories":
+		if e.complexity.FeedbackSummary.Categories == nil {
+			break
+		}
+
+		return e.complexity.FeedbackSummary.Categories(childComplexity), true
+
+	case "FeedbackSummary.dislikes":
+		if e.complexity.FeedbackSummary.Dislikes == nil {
+			break
+		}
+
+		return e.complexity.FeedbackSummary.Dislikes(childComplexity), true
+
+	case "FeedbackSummary.likes":
+		if e.complexity.FeedbackSummary.Likes == nil {
+			break
+		}
+
+		return e.complexity.FeedbackSummary.Likes(childComplexity), true
+
+	case "FeedbackSummary.namespace":
+		if e.complexity.FeedbackSummary.Namespace == nil {
+			break
+		}
+
+		return e.complexity.FeedbackSummary.Namespace(childComplexity), true
+
+	case "FeedbackSummary.total":
+		if e.complexity.FeedbackSummary.Total == nil {
+			break
+		}
+
+		return e.complexity.FeedbackSummary.Total(childComplexity), true
+
 	case "FileDetails.end_time":
 		if e.complexity.FileDetails.EndTime == nil {
 			break
@@ -3673,6 +3937,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Mutation.Embedder(childComplexity), true
 
+	case "Mutation.Feedback":
+		if e.complexity.Mutation.Feedback == nil {
+			break
+		}
+
+		return e.complexity.Mutation.Feedback(childComplexity), true
+
 	case "Mutation.hello":
 		if e.complexity.Mutation.Hello == nil {
 			break
@@ -3956,6 +4227,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Query.Embedder(childComplexity), true
 
+	case "Query.Feedback":
+		if e.complexity.Query.Feedback == nil {
+			break
+		}
+
+		return e.complexity.Query.Feedback(childComplexity), true
+
 	case "Query.GPT":
 		if e.complexity.Query.Gpt == nil {
 			break
@@ -4024,6 +4302,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Query.RayCluster(childComplexity), true
 
+	case "Query.TokenUsage":
+		if e.complexity.Query.TokenUsage == nil {
+			break
+		}
+
+		return e.complexity.Query.TokenUsage(childComplexity), true
+
 	case "Query.VersionedDataset":
 		if e.complexity.Query.VersionedDataset == nil {
 			break
@@ -4409,6 +4694,74 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Selector.MatchLabels(childComplexity), true
 
+	case "TokenUsage.appName":
+		if e.complexity.TokenUsage.AppName == nil {
+			break
+		}
+
+		return e.complexity.TokenUsage.AppName(childComplexity), true
+
+	case "TokenUsage.completionTokens":
+		if e.complexity.TokenUsage.CompletionTokens == nil {
+			break
+		}
+
+		return e.complexity.TokenUsage.CompletionTokens(childComplexity), true
+
+	case "TokenUsage.date":
+		if e.complexity.TokenUsage.Date == nil {
+			break
+		}
+
+		return e.complexity.TokenUsage.Date(childComplexity), true
+
+	case "TokenUsage.namespace":
+		if e.complexity.TokenUsage.Namespace == nil {
+			break
+		}
+
+		return e.complexity.TokenUsage.Namespace(childComplexity), true
+
+	case "TokenUsage.promptTokens":
+		if e.complexity.TokenUsage.PromptTokens == nil {
+			break
+		}
+
+		return e.complexity.TokenUsage.PromptTokens(childComplexity), true
+
+	case "TokenUsage.requests":
+		if e.complexity.TokenUsage.Requests == nil {
+			break
+		}
+
+		return e.complexity.TokenUsage.Requests(childComplexity), true
+
+	case "TokenUsage.totalTokens":
+		if e.complexity.TokenUsage.TotalTokens == nil {
+			break
+		}
+
+		return e.complexity.TokenUsage.TotalTokens(childComplexity), true
+
+	case "TokenUsage.user":
+		if e.complexity.TokenUsage.User == nil {
+			break
+		}
+
+		return e.complexity.TokenUsage.User(childComplexity), true
+
+	case "TokenUsageQuery.listTokenUsages":
+		if e.complexity.TokenUsageQuery.ListTokenUsages == nil {
+			break
+		}
+
+		args, err := ec.field_TokenUsageQuery_listTokenUsages_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.TokenUsageQuery.ListTokenUsages(childComplexity, args["input"].(ListTokenUsageInput)), true
+
 	case "Tool.name":
 		if e.complexity.Tool.Name == nil {
 			break
@@ -4987,6 +5340,7 @@ func (e *executableSchema) Exec(ctx context.Context) graphql.ResponseHandler {
 		ec.unmarshalInputDeleteVersionedDatasetInput,
 		ec.unmarshalInputDuplicateRAGInput,
 		ec.unmarshalInputEndpointInput,
+		ec.unmarshalInputExportFeedbackInput,
 		ec.unmarshalInputFileFilter,
 		ec.unmarshalInputFileGroup,
 		ec.unmarshalInputFileItem,
@@ -4995,12 +5349,14 @@ func (e *executableSchema) Exec(ctx context.Context) graphql.ResponseHandler {
 		ec.unmarshalInputLabelSelectorRequirementInput,
 		ec.unmarshalInputListCommonInput,
 		ec.unmarshalInputListDatasetInput,
+		ec.unmarshalInputListFeedbackInput,
 		ec.unmarshalInputListGPTInput,
 		ec.unmarshalInputListKnowledgeBaseInput,
 		ec.unmarshalInputListModelInput,
 		ec.unmarshalInputListModelServiceInput,
 		ec.unmarshalInputListNodeInput,
 		ec.unmarshalInputListRAGInput,
+		ec.unmarshalInputListTokenUsageInput,
 		ec.unmarshalInputListVersionedDatasetInput,
 		ec.unmarshalInputListWorkerInput,
 		ec.unmarshalInputNodeSelectorRequirementInput,
@@ -6562,6 +6918,141 @@ type Tool {
 }
 
 union PageNode = Datasource | Model | Embedder | KnowledgeBase | Dataset | VersionedDataset | F | Worker | ApplicationMetadata | LLM | ModelService | RayCluster | RAG | GPT | Node
+`, BuiltIn: false},
+	{Name: "../schema/feedback.graphqls", Input: `"""
+Feedback
+用户对应用回答的评价
+"""
+type Feedback {
+    """消息ID"""
+    messageId: String!
+    """回答的版本，每次重新生成回答后加1"""
+    version: Int!
+    """对话ID"""
+    conversationId: String!
+    """应用所在命名空间"""
+    namespace: String!
+    """应用名称"""
+    appName: String!
+    """用户"""
+    user: String!
+    """评价，like(赞) 或 dislike(踩)"""
+    rating: String!
+    """评价分类，如 inaccurate, irrelevant, incomplete, harmful 等"""
+    category: String
+    """评价内容"""
+    comment: String
+    """用户期望的回答，导出到评估数据集时作为标准答案"""
+    expectedAnswer: String
+    """用户的问题"""
+    query: String!
+    """被评价的回答"""
+    answer: String!
+    """创建时间"""
+    creationTimestamp: Time!
+    """更新时间"""
+    updateTimestamp: Time!
+}
+
+"""
+FeedbackCategoryCount
+某个评价分类的数量
+"""
+type FeedbackCategoryCount {
+    """评价分类，空表示未分类"""
+    category: String!
+    """点踩数量"""
+    dislikes: Int64!
+}
+
+"""
+FeedbackSummary
+应用的评价汇总
+"""
+type FeedbackSummary {
+    """应用所在命名空间"""
+    namespace: String!
+    """应用名称"""
+    appName: String!
+    """评价总数"""
+    total: Int64!
+    """点赞数量"""
+    likes: Int64!
+    """点踩数量"""
+    dislikes: Int64!
+    """点踩的分类统计，数量多的在前"""
+    categories: [FeedbackCategoryCount!]!
+}
+
+input ListFeedbackInput {
+    """命名空间(必填)"""
+    namespace: String!
+    """应用名称，不填时返回命名空间下所有应用的评价"""
+    appName: String
+    """评价，like 或 dislike，不填时不限制"""
+    rating: String
+    """评价分类，不填时不限制"""
+    category: String
+    """
+    开始日期，格式为 2006-01-02
+    规则: 包含当天，不填时不限制
+    """
+    startDate: String
+    """
+    结束日期，格式为 2006-01-02
+    规则: 包含当天，不填时不限制
+    """
+    endDate: String
+}
+
+input ExportFeedbackInput {
+    """命名空间(必填)"""
+    namespace: String!
+    """应用名称(必填)"""
+    appName: String!
+    """评价分类，不填时导出所有点踩的回答"""
+    category: String
+    """
+    开始日期，格式为 2006-01-02
+    规则: 包含当天，不填时不限制
+    """
+    startDate: String
+    """
+    结束日期，格式为 2006-01-02
+    规则: 包含当天，不填时不限制
+    """
+    endDate: String
+    """导出到的数据集版本，需要与应用在同一命名空间"""
+    versionedDataset: String!
+    """
+    导出的文件名
+    规则: 以 .csv 结尾，默认为 feedback-<应用名称>-<当前时间>.csv
+    """
+    fileName: String
+}
+
+type FeedbackQuery {
+    summarizeFeedbacks(input: ListFeedbackInput!): [FeedbackSummary!]!
+    listFeedbacks(input: ListFeedbackInput!): [Feedback!]!
+}
+
+type FeedbackMutation {
+    """
+    把点踩的回答导出为 QA 格式的 csv 文件到数据集版本中，用于 RAG 评估
+    问题列为 q，标准答案列为 a，标准答案使用用户期望的回答，没有期望回答的不会导出
+    文件保存在系统数据源中，并添加到数据集版本的文件列表，由数据集版本同步
+    返回文件在数据集版本中的路径，为 feedback/<命名空间>/<文件名>
+    """
+    exportFeedbacks(input: ExportFeedbackInput!): String!
+}
+
+extend type Query {
+    Feedback: FeedbackQuery
+}
+
+extend type Mutation {
+    Feedback: FeedbackMutation
+}
 `, BuiltIn: false},
 	{Name: "../schema/gpt.graphqls", Input: `input ListGPTInput {
 
@@ -7882,6 +8373,56 @@ type RayClusterQuery {
 extend type Query {
     RayCluster: RayClusterQuery
 }`, BuiltIn: false},
+	{Name: "../schema/tokenusage.graphqls", Input: `"""
+TokenUsage
+用户在应用中每天的token用量
+"""
+type TokenUsage {
+    """日期，格式为 2006-01-02"""
+    date: String!
+    """应用所在命名空间"""
+    namespace: String!
+    """应用名称"""
+    appName: String!
+    """用户"""
+    user: String!
+    """输入token数"""
+    promptTokens: Int64!
+    """输出token数"""
+    completionTokens: Int64!
+    """总token数"""
+    totalTokens: Int64!
+    """对话请求数"""
+    requests: Int64!
+}
+
+input ListTokenUsageInput {
+    """命名空间(必填)"""
+    namespace: String!
+    """应用名称，不填时返回命名空间下所有应用的用量"""
+    appName: String
+    """用户，不填时返回所有用户的用量"""
+    user: String
+    """
+    开始日期，格式为 2006-01-02
+    规则: 包含当天，不填时不限制
+    """
+    startDate: String
+    """
+    结束日期，格式为 2006-01-02
+    规则: 包含当天，不填时不限制
+    """
+    endDate: String
+}
+
+type TokenUsageQuery {
+    listTokenUsages(input: ListTokenUsageInput!): [TokenUsage!]!
+}
+
+extend type Query {
+    TokenUsage: TokenUsageQuery
+}
+`, BuiltIn: false},
 	{Name: "../schema/versioned_dataset.graphqls", Input: `scalar Int64
 """
 VersionedDataset
@@ -8915,6 +9456,51 @@ func (ec *executionContext) field_EmbedderQuery_listEmbedders_args(ctx context.C
 	return args, nil
 }
 
+func (ec *executionContext) field_FeedbackMutation_exportFeedbacks_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 ExportFeedbackInput
+	if tmp, ok := rawArgs["input"]; ok {
+		ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("input"))
+		arg0, err = ec.unmarshalNExportFeedbackInput2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐExportFeedbackInput(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["input"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_FeedbackQuery_listFeedbacks_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 ListFeedbackInput
+	if tmp, ok := rawArgs["input"]; ok {
+		ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("input"))
+		arg0, err = ec.unmarshalNListFeedbackInput2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐListFeedbackInput(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["input"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_FeedbackQuery_summarizeFeedbacks_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 ListFeedbackInput
+	if tmp, ok := rawArgs["input"]; ok {
+		ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("input"))
+		arg0, err = ec.unmarshalNListFeedbackInput2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐListFeedbackInput(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["input"] = arg0
+	return args, nil
+}
+
 func (ec *executionContext) field_GPTQuery_getGPT_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
 	var err error
 	args := map[string]interface{}{}
@@ -9440,6 +10026,21 @@ func (ec *executionContext) field_RayClusterQuery_listRayClusters_args(ctx conte
 	return args, nil
 }
 
+func (ec *executionContext) field_TokenUsageQuery_listTokenUsages_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 ListTokenUsageInput
+	if tmp, ok := rawArgs["input"]; ok {
+		ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("input"))
+		arg0, err = ec.unmarshalNListTokenUsageInput2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐListTokenUsageInput(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["input"] = arg0
+	return args, nil
+}
+
 func (ec *executionContext) field_VersionedDatasetMutation_createVersionedDataset_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
 	var err error
 	args := map[string]interface{}{}
@@ -19457,8 +20058,8 @@ func (ec *executionContext) fieldContext_F_latestVersion(ctx context.Context, fi
 	return fc, nil
 }
 
-func (ec *executionContext) _FileDetails_file_name(ctx context.Context, field graphql.CollectedField, obj *FileDetails) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_FileDetails_file_name(ctx, field)
+func (ec *executionContext) _Feedback_messageId(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_messageId(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19471,7 +20072,7 @@ func (ec *executionContext) _FileDetails_file_name(ctx context.Context, field gr
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.FileName, nil
+		return obj.MessageID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -19488,9 +20089,9 @@ func (ec *executionContext) _FileDetails_file_name(ctx context.Context, field gr
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_FileDetails_file_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_messageId(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "FileDetails",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -19501,8 +20102,8 @@ func (ec *executionContext) fieldContext_FileDetails_file_name(ctx context.Conte
 	return fc, nil
 }
 
-func (ec *executionContext) _FileDetails_status(ctx context.Context, field graphql.CollectedField, obj *FileDetails) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_FileDetails_status(ctx, field)
+func (ec *executionContext) _Feedback_version(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_version(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19515,7 +20116,7 @@ func (ec *executionContext) _FileDetails_status(ctx context.Context, field graph
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Status, nil
+		return obj.Version, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -19527,26 +20128,26 @@ func (ec *executionContext) _FileDetails_status(ctx context.Context, field graph
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(int)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNInt2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_FileDetails_status(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_version(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "FileDetails",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Int does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _FileDetails_start_time(ctx context.Context, field graphql.CollectedField, obj *FileDetails) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_FileDetails_start_time(ctx, field)
+func (ec *executionContext) _Feedback_conversationId(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_conversationId(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19559,7 +20160,7 @@ func (ec *executionContext) _FileDetails_start_time(ctx context.Context, field g
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.StartTime, nil
+		return obj.ConversationID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -19576,9 +20177,9 @@ func (ec *executionContext) _FileDetails_start_time(ctx context.Context, field g
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_FileDetails_start_time(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_conversationId(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "FileDetails",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -19589,8 +20190,8 @@ func (ec *executionContext) fieldContext_FileDetails_start_time(ctx context.Cont
 	return fc, nil
 }
 
-func (ec *executionContext) _FileDetails_end_time(ctx context.Context, field graphql.CollectedField, obj *FileDetails) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_FileDetails_end_time(ctx, field)
+func (ec *executionContext) _Feedback_namespace(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_namespace(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19603,7 +20204,7 @@ func (ec *executionContext) _FileDetails_end_time(ctx context.Context, field gra
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.EndTime, nil
+		return obj.Namespace, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -19620,9 +20221,9 @@ func (ec *executionContext) _FileDetails_end_time(ctx context.Context, field gra
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_FileDetails_end_time(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_namespace(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "FileDetails",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -19633,8 +20234,8 @@ func (ec *executionContext) fieldContext_FileDetails_end_time(ctx context.Contex
 	return fc, nil
 }
 
-func (ec *executionContext) _FileDetails_file_size(ctx context.Context, field graphql.CollectedField, obj *FileDetails) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_FileDetails_file_size(ctx, field)
+func (ec *executionContext) _Feedback_appName(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_appName(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19647,7 +20248,7 @@ func (ec *executionContext) _FileDetails_file_size(ctx context.Context, field gr
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.FileSize, nil
+		return obj.AppName, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -19664,9 +20265,9 @@ func (ec *executionContext) _FileDetails_file_size(ctx context.Context, field gr
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_FileDetails_file_size(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_appName(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "FileDetails",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -19677,8 +20278,8 @@ func (ec *executionContext) fieldContext_FileDetails_file_size(ctx context.Conte
 	return fc, nil
 }
 
-func (ec *executionContext) _FileWithVersion_path(ctx context.Context, field graphql.CollectedField, obj *FileWithVersion) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_FileWithVersion_path(ctx, field)
+func (ec *executionContext) _Feedback_user(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_user(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19691,7 +20292,7 @@ func (ec *executionContext) _FileWithVersion_path(ctx context.Context, field gra
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Path, nil
+		return obj.User, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -19708,9 +20309,9 @@ func (ec *executionContext) _FileWithVersion_path(ctx context.Context, field gra
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_FileWithVersion_path(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_user(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "FileWithVersion",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -19721,8 +20322,8 @@ func (ec *executionContext) fieldContext_FileWithVersion_path(ctx context.Contex
 	return fc, nil
 }
 
-func (ec *executionContext) _FileWithVersion_version(ctx context.Context, field graphql.CollectedField, obj *FileWithVersion) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_FileWithVersion_version(ctx, field)
+func (ec *executionContext) _Feedback_rating(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_rating(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19735,23 +20336,26 @@ func (ec *executionContext) _FileWithVersion_version(ctx context.Context, field
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Version, nil
+		return obj.Rating, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_FileWithVersion_version(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_rating(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "FileWithVersion",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -19762,8 +20366,8 @@ func (ec *executionContext) fieldContext_FileWithVersion_version(ctx context.Con
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_name(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_name(ctx, field)
+func (ec *executionContext) _Feedback_category(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_category(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19776,7 +20380,7 @@ func (ec *executionContext) _GPT_name(ctx context.Context, field graphql.Collect
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Name, nil
+		return obj.Category, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -19790,9 +20394,9 @@ func (ec *executionContext) _GPT_name(ctx context.Context, field graphql.Collect
 	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_category(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -19803,8 +20407,8 @@ func (ec *executionContext) fieldContext_GPT_name(ctx context.Context, field gra
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_displayName(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_displayName(ctx, field)
+func (ec *executionContext) _Feedback_comment(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_comment(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19817,7 +20421,7 @@ func (ec *executionContext) _GPT_displayName(ctx context.Context, field graphql.
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.DisplayName, nil
+		return obj.Comment, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -19831,9 +20435,9 @@ func (ec *executionContext) _GPT_displayName(ctx context.Context, field graphql.
 	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_displayName(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_comment(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -19844,8 +20448,8 @@ func (ec *executionContext) fieldContext_GPT_displayName(ctx context.Context, fi
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_description(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_description(ctx, field)
+func (ec *executionContext) _Feedback_expectedAnswer(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_expectedAnswer(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19858,7 +20462,7 @@ func (ec *executionContext) _GPT_description(ctx context.Context, field graphql.
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Description, nil
+		return obj.ExpectedAnswer, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -19872,9 +20476,9 @@ func (ec *executionContext) _GPT_description(ctx context.Context, field graphql.
 	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_description(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_expectedAnswer(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -19885,8 +20489,8 @@ func (ec *executionContext) fieldContext_GPT_description(ctx context.Context, fi
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_hot(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_hot(ctx, field)
+func (ec *executionContext) _Feedback_query(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_query(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19899,35 +20503,38 @@ func (ec *executionContext) _GPT_hot(ctx context.Context, field graphql.Collecte
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Hot, nil
+		return obj.Query, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*int64)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOInt642ᚖint64(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_hot(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_query(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Int64 does not have child fields")
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_creator(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_creator(ctx, field)
+func (ec *executionContext) _Feedback_answer(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_answer(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19940,23 +20547,26 @@ func (ec *executionContext) _GPT_creator(ctx context.Context, field graphql.Coll
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Creator, nil
+		return obj.Answer, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_creator(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_answer(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -19967,8 +20577,8 @@ func (ec *executionContext) fieldContext_GPT_creator(ctx context.Context, field
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_isRecommended(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_isRecommended(ctx, field)
+func (ec *executionContext) _Feedback_creationTimestamp(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_creationTimestamp(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -19981,35 +20591,38 @@ func (ec *executionContext) _GPT_isRecommended(ctx context.Context, field graphq
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.IsRecommended, nil
+		return obj.CreationTimestamp, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*bool)
+	res := resTmp.(time.Time)
 	fc.Result = res
-	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_isRecommended(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_creationTimestamp(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Boolean does not have child fields")
+			return nil, errors.New("field of type Time does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_category(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_category(ctx, field)
+func (ec *executionContext) _Feedback_updateTimestamp(ctx context.Context, field graphql.CollectedField, obj *Feedback) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Feedback_updateTimestamp(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20022,35 +20635,38 @@ func (ec *executionContext) _GPT_category(ctx context.Context, field graphql.Col
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Category, nil
+		return obj.UpdateTimestamp, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.([]*string)
+	res := resTmp.(time.Time)
 	fc.Result = res
-	return ec.marshalOString2ᚕᚖstring(ctx, field.Selections, res)
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_category(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Feedback_updateTimestamp(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "Feedback",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Time does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_icon(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_icon(ctx, field)
+func (ec *executionContext) _FeedbackCategoryCount_category(ctx context.Context, field graphql.CollectedField, obj *FeedbackCategoryCount) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FeedbackCategoryCount_category(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20063,23 +20679,26 @@ func (ec *executionContext) _GPT_icon(ctx context.Context, field graphql.Collect
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Icon, nil
+		return obj.Category, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_icon(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FeedbackCategoryCount_category(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "FeedbackCategoryCount",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -20090,8 +20709,8 @@ func (ec *executionContext) fieldContext_GPT_icon(ctx context.Context, field gra
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_prologue(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_prologue(ctx, field)
+func (ec *executionContext) _FeedbackCategoryCount_dislikes(ctx context.Context, field graphql.CollectedField, obj *FeedbackCategoryCount) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FeedbackCategoryCount_dislikes(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20104,35 +20723,38 @@ func (ec *executionContext) _GPT_prologue(ctx context.Context, field graphql.Col
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Prologue, nil
+		return obj.Dislikes, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(int64)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_prologue(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FeedbackCategoryCount_dislikes(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "FeedbackCategoryCount",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Int64 does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_showRespInfo(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_showRespInfo(ctx, field)
+func (ec *executionContext) _FeedbackMutation_exportFeedbacks(ctx context.Context, field graphql.CollectedField, obj *FeedbackMutation) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FeedbackMutation_exportFeedbacks(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20145,35 +20767,49 @@ func (ec *executionContext) _GPT_showRespInfo(ctx context.Context, field graphql
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ShowRespInfo, nil
+		return ec.resolvers.FeedbackMutation().ExportFeedbacks(rctx, obj, fc.Args["input"].(ExportFeedbackInput))
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*bool)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_showRespInfo(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FeedbackMutation_exportFeedbacks(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "FeedbackMutation",
 		Field:      field,
-		IsMethod:   false,
-		IsResolver: false,
+		IsMethod:   true,
+		IsResolver: true,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Boolean does not have child fields")
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ec.Recover(ctx, r)
+			ec.Error(ctx, err)
+		}
+	}()
+	ctx = graphql.WithFieldContext(ctx, fc)
+	if fc.Args, err = ec.field_FeedbackMutation_exportFeedbacks_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+		ec.Error(ctx, err)
+		return fc, err
+	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_showRetrievalInfo(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_showRetrievalInfo(ctx, field)
+func (ec *executionContext) _FeedbackQuery_summarizeFeedbacks(ctx context.Context, field graphql.CollectedField, obj *FeedbackQuery) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FeedbackQuery_summarizeFeedbacks(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20186,35 +20822,63 @@ func (ec *executionContext) _GPT_showRetrievalInfo(ctx context.Context, field gr
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ShowRetrievalInfo, nil
+		return ec.resolvers.FeedbackQuery().SummarizeFeedbacks(rctx, obj, fc.Args["input"].(ListFeedbackInput))
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*bool)
+	res := resTmp.([]*FeedbackSummary)
 	fc.Result = res
-	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
+	return ec.marshalNFeedbackSummary2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackSummaryᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_showRetrievalInfo(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FeedbackQuery_summarizeFeedbacks(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "FeedbackQuery",
 		Field:      field,
-		IsMethod:   false,
-		IsResolver: false,
+		IsMethod:   true,
+		IsResolver: true,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Boolean does not have child fields")
+			switch field.Name {
+			case "namespace":
+				return ec.fieldContext_FeedbackSummary_namespace(ctx, field)
+			case "appName":
+				return ec.fieldContext_FeedbackSummary_appName(ctx, field)
+			case "total":
+				return ec.fieldContext_FeedbackSummary_total(ctx, field)
+			case "likes":
+				return ec.fieldContext_FeedbackSummary_likes(ctx, field)
+			case "dislikes":
+				return ec.fieldContext_FeedbackSummary_dislikes(ctx, field)
+			case "categories":
+				return ec.fieldContext_FeedbackSummary_categories(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type FeedbackSummary", field.Name)
 		},
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ec.Recover(ctx, r)
+			ec.Error(ctx, err)
+		}
+	}()
+	ctx = graphql.WithFieldContext(ctx, fc)
+	if fc.Args, err = ec.field_FeedbackQuery_summarizeFeedbacks_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+		ec.Error(ctx, err)
+		return fc, err
+	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_showNextGuide(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_showNextGuide(ctx, field)
+func (ec *executionContext) _FeedbackQuery_listFeedbacks(ctx context.Context, field graphql.CollectedField, obj *FeedbackQuery) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FeedbackQuery_listFeedbacks(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20227,35 +20891,79 @@ func (ec *executionContext) _GPT_showNextGuide(ctx context.Context, field graphq
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ShowNextGuide, nil
+		return ec.resolvers.FeedbackQuery().ListFeedbacks(rctx, obj, fc.Args["input"].(ListFeedbackInput))
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*bool)
+	res := resTmp.([]*Feedback)
 	fc.Result = res
-	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
+	return ec.marshalNFeedback2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_showNextGuide(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FeedbackQuery_listFeedbacks(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "FeedbackQuery",
 		Field:      field,
-		IsMethod:   false,
-		IsResolver: false,
+		IsMethod:   true,
+		IsResolver: true,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Boolean does not have child fields")
+			switch field.Name {
+			case "messageId":
+				return ec.fieldContext_Feedback_messageId(ctx, field)
+			case "version":
+				return ec.fieldContext_Feedback_version(ctx, field)
+			case "conversationId":
+				return ec.fieldContext_Feedback_conversationId(ctx, field)
+			case "namespace":
+				return ec.fieldContext_Feedback_namespace(ctx, field)
+			case "appName":
+				return ec.fieldContext_Feedback_appName(ctx, field)
+			case "user":
+				return ec.fieldContext_Feedback_user(ctx, field)
+			case "rating":
+				return ec.fieldContext_Feedback_rating(ctx, field)
+			case "category":
+				return ec.fieldContext_Feedback_category(ctx, field)
+			case "comment":
+				return ec.fieldContext_Feedback_comment(ctx, field)
+			case "expectedAnswer":
+				return ec.fieldContext_Feedback_expectedAnswer(ctx, field)
+			case "query":
+				return ec.fieldContext_Feedback_query(ctx, field)
+			case "answer":
+				return ec.fieldContext_Feedback_answer(ctx, field)
+			case "creationTimestamp":
+				return ec.fieldContext_Feedback_creationTimestamp(ctx, field)
+			case "updateTimestamp":
+				return ec.fieldContext_Feedback_updateTimestamp(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type Feedback", field.Name)
 		},
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ec.Recover(ctx, r)
+			ec.Error(ctx, err)
+		}
+	}()
+	ctx = graphql.WithFieldContext(ctx, fc)
+	if fc.Args, err = ec.field_FeedbackQuery_listFeedbacks_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+		ec.Error(ctx, err)
+		return fc, err
+	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_enableUploadFile(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_enableUploadFile(ctx, field)
+func (ec *executionContext) _FeedbackSummary_namespace(ctx context.Context, field graphql.CollectedField, obj *FeedbackSummary) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FeedbackSummary_namespace(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20268,35 +20976,38 @@ func (ec *executionContext) _GPT_enableUploadFile(ctx context.Context, field gra
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.EnableUploadFile, nil
+		return obj.Namespace, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*bool)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_enableUploadFile(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FeedbackSummary_namespace(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "FeedbackSummary",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Boolean does not have child fields")
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPT_notReadyReasonCode(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPT_notReadyReasonCode(ctx, field)
+func (ec *executionContext) _FeedbackSummary_appName(ctx context.Context, field graphql.CollectedField, obj *FeedbackSummary) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FeedbackSummary_appName(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20309,23 +21020,26 @@ func (ec *executionContext) _GPT_notReadyReasonCode(ctx context.Context, field g
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.NotReadyReasonCode, nil
+		return obj.AppName, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPT_notReadyReasonCode(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FeedbackSummary_appName(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPT",
+		Object:     "FeedbackSummary",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -20336,8 +21050,8 @@ func (ec *executionContext) fieldContext_GPT_notReadyReasonCode(ctx context.Cont
 	return fc, nil
 }
 
-func (ec *executionContext) _GPTCategory_id(ctx context.Context, field graphql.CollectedField, obj *GPTCategory) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPTCategory_id(ctx, field)
+func (ec *executionContext) _FeedbackSummary_total(ctx context.Context, field graphql.CollectedField, obj *FeedbackSummary) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FeedbackSummary_total(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20350,7 +21064,7 @@ func (ec *executionContext) _GPTCategory_id(ctx context.Context, field graphql.C
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ID, nil
+		return obj.Total, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -20362,26 +21076,26 @@ func (ec *executionContext) _GPTCategory_id(ctx context.Context, field graphql.C
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(int64)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPTCategory_id(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FeedbackSummary_total(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPTCategory",
+		Object:     "FeedbackSummary",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Int64 does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPTCategory_name(ctx context.Context, field graphql.CollectedField, obj *GPTCategory) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPTCategory_name(ctx, field)
+func (ec *executionContext) _FeedbackSummary_likes(ctx context.Context, field graphql.CollectedField, obj *FeedbackSummary) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FeedbackSummary_likes(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20394,7 +21108,7 @@ func (ec *executionContext) _GPTCategory_name(ctx context.Context, field graphql
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Name, nil
+		return obj.Likes, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -20406,26 +21120,26 @@ func (ec *executionContext) _GPTCategory_name(ctx context.Context, field graphql
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(int64)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPTCategory_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FeedbackSummary_likes(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPTCategory",
+		Object:     "FeedbackSummary",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Int64 does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPTCategory_nameEn(ctx context.Context, field graphql.CollectedField, obj *GPTCategory) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPTCategory_nameEn(ctx, field)
+func (ec *executionContext) _FeedbackSummary_dislikes(ctx context.Context, field graphql.CollectedField, obj *FeedbackSummary) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FeedbackSummary_dislikes(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20438,7 +21152,7 @@ func (ec *executionContext) _GPTCategory_nameEn(ctx context.Context, field graph
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.NameEn, nil
+		return obj.Dislikes, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -20450,26 +21164,26 @@ func (ec *executionContext) _GPTCategory_nameEn(ctx context.Context, field graph
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(int64)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPTCategory_nameEn(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FeedbackSummary_dislikes(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPTCategory",
+		Object:     "FeedbackSummary",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Int64 does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPTQuery_getGPT(ctx context.Context, field graphql.CollectedField, obj *GPTQuery) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPTQuery_getGPT(ctx, field)
+func (ec *executionContext) _FeedbackSummary_categories(ctx context.Context, field graphql.CollectedField, obj *FeedbackSummary) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FeedbackSummary_categories(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20482,7 +21196,7 @@ func (ec *executionContext) _GPTQuery_getGPT(ctx context.Context, field graphql.
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.GPTQuery().GetGpt(rctx, obj, fc.Args["name"].(string))
+		return obj.Categories, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -20494,67 +21208,32 @@ func (ec *executionContext) _GPTQuery_getGPT(ctx context.Context, field graphql.
 		}
 		return graphql.Null
 	}
-	res := resTmp.(*Gpt)
+	res := resTmp.([]*FeedbackCategoryCount)
 	fc.Result = res
-	return ec.marshalNGPT2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐGpt(ctx, field.Selections, res)
+	return ec.marshalNFeedbackCategoryCount2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackCategoryCountᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPTQuery_getGPT(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FeedbackSummary_categories(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPTQuery",
+		Object:     "FeedbackSummary",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
 			switch field.Name {
-			case "name":
-				return ec.fieldContext_GPT_name(ctx, field)
-			case "displayName":
-				return ec.fieldContext_GPT_displayName(ctx, field)
-			case "description":
-				return ec.fieldContext_GPT_description(ctx, field)
-			case "hot":
-				return ec.fieldContext_GPT_hot(ctx, field)
-			case "creator":
-				return ec.fieldContext_GPT_creator(ctx, field)
-			case "isRecommended":
-				return ec.fieldContext_GPT_isRecommended(ctx, field)
 			case "category":
-				return ec.fieldContext_GPT_category(ctx, field)
-			case "icon":
-				return ec.fieldContext_GPT_icon(ctx, field)
-			case "prologue":
-				return ec.fieldContext_GPT_prologue(ctx, field)
-			case "showRespInfo":
-				return ec.fieldContext_GPT_showRespInfo(ctx, field)
-			case "showRetrievalInfo":
-				return ec.fieldContext_GPT_showRetrievalInfo(ctx, field)
-			case "showNextGuide":
-				return ec.fieldContext_GPT_showNextGuide(ctx, field)
-			case "enableUploadFile":
-				return ec.fieldContext_GPT_enableUploadFile(ctx, field)
-			case "notReadyReasonCode":
-				return ec.fieldContext_GPT_notReadyReasonCode(ctx, field)
+				return ec.fieldContext_FeedbackCategoryCount_category(ctx, field)
+			case "dislikes":
+				return ec.fieldContext_FeedbackCategoryCount_dislikes(ctx, field)
 			}
-			return nil, fmt.Errorf("no field named %q was found under type GPT", field.Name)
+			return nil, fmt.Errorf("no field named %q was found under type FeedbackCategoryCount", field.Name)
 		},
 	}
-	defer func() {
-		if r := recover(); r != nil {
-			err = ec.Recover(ctx, r)
-			ec.Error(ctx, err)
-		}
-	}()
-	ctx = graphql.WithFieldContext(ctx, fc)
-	if fc.Args, err = ec.field_GPTQuery_getGPT_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
-		ec.Error(ctx, err)
-		return fc, err
-	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPTQuery_listGPT(ctx context.Context, field graphql.CollectedField, obj *GPTQuery) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPTQuery_listGPT(ctx, field)
+func (ec *executionContext) _FileDetails_file_name(ctx context.Context, field graphql.CollectedField, obj *FileDetails) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FileDetails_file_name(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20567,7 +21246,7 @@ func (ec *executionContext) _GPTQuery_listGPT(ctx context.Context, field graphql
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.GPTQuery().ListGpt(rctx, obj, fc.Args["input"].(ListGPTInput))
+		return obj.FileName, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -20579,49 +21258,26 @@ func (ec *executionContext) _GPTQuery_listGPT(ctx context.Context, field graphql
 		}
 		return graphql.Null
 	}
-	res := resTmp.(*PaginatedResult)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNPaginatedResult2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐPaginatedResult(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPTQuery_listGPT(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FileDetails_file_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPTQuery",
+		Object:     "FileDetails",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "hasNextPage":
-				return ec.fieldContext_PaginatedResult_hasNextPage(ctx, field)
-			case "nodes":
-				return ec.fieldContext_PaginatedResult_nodes(ctx, field)
-			case "page":
-				return ec.fieldContext_PaginatedResult_page(ctx, field)
-			case "pageSize":
-				return ec.fieldContext_PaginatedResult_pageSize(ctx, field)
-			case "totalCount":
-				return ec.fieldContext_PaginatedResult_totalCount(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type PaginatedResult", field.Name)
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
-	defer func() {
-		if r := recover(); r != nil {
-			err = ec.Recover(ctx, r)
-			ec.Error(ctx, err)
-		}
-	}()
-	ctx = graphql.WithFieldContext(ctx, fc)
-	if fc.Args, err = ec.field_GPTQuery_listGPT_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
-		ec.Error(ctx, err)
-		return fc, err
-	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPTQuery_listGPTCategory(ctx context.Context, field graphql.CollectedField, obj *GPTQuery) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPTQuery_listGPTCategory(ctx, field)
+func (ec *executionContext) _FileDetails_status(ctx context.Context, field graphql.CollectedField, obj *FileDetails) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FileDetails_status(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20634,7 +21290,7 @@ func (ec *executionContext) _GPTQuery_listGPTCategory(ctx context.Context, field
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.GPTQuery().ListGPTCategory(rctx, obj)
+		return obj.Status, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -20646,34 +21302,26 @@ func (ec *executionContext) _GPTQuery_listGPTCategory(ctx context.Context, field
 		}
 		return graphql.Null
 	}
-	res := resTmp.([]*GPTCategory)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNGPTCategory2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐGPTCategory(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPTQuery_listGPTCategory(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FileDetails_status(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPTQuery",
+		Object:     "FileDetails",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "id":
-				return ec.fieldContext_GPTCategory_id(ctx, field)
-			case "name":
-				return ec.fieldContext_GPTCategory_name(ctx, field)
-			case "nameEn":
-				return ec.fieldContext_GPTCategory_nameEn(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type GPTCategory", field.Name)
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPTQuery_getGPTStore(ctx context.Context, field graphql.CollectedField, obj *GPTQuery) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPTQuery_getGPTStore(ctx, field)
+func (ec *executionContext) _FileDetails_start_time(ctx context.Context, field graphql.CollectedField, obj *FileDetails) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FileDetails_start_time(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20686,7 +21334,7 @@ func (ec *executionContext) _GPTQuery_getGPTStore(ctx context.Context, field gra
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.GPTQuery().GetGPTStore(rctx, obj)
+		return obj.StartTime, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -20698,32 +21346,26 @@ func (ec *executionContext) _GPTQuery_getGPTStore(ctx context.Context, field gra
 		}
 		return graphql.Null
 	}
-	res := resTmp.(*GPTStore)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNGPTStore2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐGPTStore(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPTQuery_getGPTStore(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FileDetails_start_time(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPTQuery",
+		Object:     "FileDetails",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "url":
-				return ec.fieldContext_GPTStore_url(ctx, field)
-			case "public_namespace":
-				return ec.fieldContext_GPTStore_public_namespace(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type GPTStore", field.Name)
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _GPTStore_url(ctx context.Context, field graphql.CollectedField, obj *GPTStore) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPTStore_url(ctx, field)
+func (ec *executionContext) _FileDetails_end_time(ctx context.Context, field graphql.CollectedField, obj *FileDetails) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FileDetails_end_time(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20736,7 +21378,7 @@ func (ec *executionContext) _GPTStore_url(ctx context.Context, field graphql.Col
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.URL, nil
+		return obj.EndTime, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -20753,9 +21395,9 @@ func (ec *executionContext) _GPTStore_url(ctx context.Context, field graphql.Col
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPTStore_url(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FileDetails_end_time(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPTStore",
+		Object:     "FileDetails",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -20766,8 +21408,8 @@ func (ec *executionContext) fieldContext_GPTStore_url(ctx context.Context, field
 	return fc, nil
 }
 
-func (ec *executionContext) _GPTStore_public_namespace(ctx context.Context, field graphql.CollectedField, obj *GPTStore) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_GPTStore_public_namespace(ctx, field)
+func (ec *executionContext) _FileDetails_file_size(ctx context.Context, field graphql.CollectedField, obj *FileDetails) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FileDetails_file_size(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20780,7 +21422,7 @@ func (ec *executionContext) _GPTStore_public_namespace(ctx context.Context, fiel
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.PublicNamespace, nil
+		return obj.FileSize, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -20797,9 +21439,9 @@ func (ec *executionContext) _GPTStore_public_namespace(ctx context.Context, fiel
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_GPTStore_public_namespace(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FileDetails_file_size(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "GPTStore",
+		Object:     "FileDetails",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -20810,8 +21452,8 @@ func (ec *executionContext) fieldContext_GPTStore_public_namespace(ctx context.C
 	return fc, nil
 }
 
-func (ec *executionContext) _KnowledgeBase_id(ctx context.Context, field graphql.CollectedField, obj *KnowledgeBase) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_KnowledgeBase_id(ctx, field)
+func (ec *executionContext) _FileWithVersion_path(ctx context.Context, field graphql.CollectedField, obj *FileWithVersion) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FileWithVersion_path(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20824,23 +21466,26 @@ func (ec *executionContext) _KnowledgeBase_id(ctx context.Context, field graphql
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ID, nil
+		return obj.Path, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_KnowledgeBase_id(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FileWithVersion_path(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "KnowledgeBase",
+		Object:     "FileWithVersion",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -20851,8 +21496,8 @@ func (ec *executionContext) fieldContext_KnowledgeBase_id(ctx context.Context, f
 	return fc, nil
 }
 
-func (ec *executionContext) _KnowledgeBase_name(ctx context.Context, field graphql.CollectedField, obj *KnowledgeBase) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_KnowledgeBase_name(ctx, field)
+func (ec *executionContext) _FileWithVersion_version(ctx context.Context, field graphql.CollectedField, obj *FileWithVersion) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FileWithVersion_version(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20865,26 +21510,23 @@ func (ec *executionContext) _KnowledgeBase_name(ctx context.Context, field graph
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Name, nil
+		return obj.Version, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_KnowledgeBase_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_FileWithVersion_version(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "KnowledgeBase",
+		Object:     "FileWithVersion",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -20895,8 +21537,8 @@ func (ec *executionContext) fieldContext_KnowledgeBase_name(ctx context.Context,
 	return fc, nil
 }
 
-func (ec *executionContext) _KnowledgeBase_namespace(ctx context.Context, field graphql.CollectedField, obj *KnowledgeBase) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_KnowledgeBase_namespace(ctx, field)
+func (ec *executionContext) _GPT_name(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_name(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -20909,19 +21551,1152 @@ func (ec *executionContext) _KnowledgeBase_namespace(ctx context.Context, field
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Namespace, nil
+		return obj.Name, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_displayName(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_displayName(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.DisplayName, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_displayName(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_description(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_description(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Description, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_description(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_hot(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_hot(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Hot, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*int64)
+	fc.Result = res
+	return ec.marshalOInt642ᚖint64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_hot(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_creator(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_creator(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Creator, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_creator(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_isRecommended(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_isRecommended(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.IsRecommended, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*bool)
+	fc.Result = res
+	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_isRecommended(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Boolean does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_category(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_category(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Category, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.([]*string)
+	fc.Result = res
+	return ec.marshalOString2ᚕᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_category(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_icon(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_icon(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Icon, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_icon(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_prologue(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_prologue(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Prologue, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_prologue(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_showRespInfo(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_showRespInfo(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ShowRespInfo, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*bool)
+	fc.Result = res
+	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_showRespInfo(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Boolean does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_showRetrievalInfo(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_showRetrievalInfo(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ShowRetrievalInfo, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*bool)
+	fc.Result = res
+	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_showRetrievalInfo(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Boolean does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_showNextGuide(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_showNextGuide(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ShowNextGuide, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*bool)
+	fc.Result = res
+	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_showNextGuide(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Boolean does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_enableUploadFile(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_enableUploadFile(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.EnableUploadFile, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*bool)
+	fc.Result = res
+	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_enableUploadFile(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Boolean does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPT_notReadyReasonCode(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_notReadyReasonCode(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.NotReadyReasonCode, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_notReadyReasonCode(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPTCategory_id(ctx context.Context, field graphql.CollectedField, obj *GPTCategory) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPTCategory_id(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPTCategory_id(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPTCategory",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPTCategory_name(ctx context.Context, field graphql.CollectedField, obj *GPTCategory) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPTCategory_name(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Name, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPTCategory_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPTCategory",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPTCategory_nameEn(ctx context.Context, field graphql.CollectedField, obj *GPTCategory) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPTCategory_nameEn(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.NameEn, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPTCategory_nameEn(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPTCategory",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPTQuery_getGPT(ctx context.Context, field graphql.CollectedField, obj *GPTQuery) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPTQuery_getGPT(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.GPTQuery().GetGpt(rctx, obj, fc.Args["name"].(string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*Gpt)
+	fc.Result = res
+	return ec.marshalNGPT2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐGpt(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPTQuery_getGPT(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPTQuery",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "name":
+				return ec.fieldContext_GPT_name(ctx, field)
+			case "displayName":
+				return ec.fieldContext_GPT_displayName(ctx, field)
+			case "description":
+				return ec.fieldContext_GPT_description(ctx, field)
+			case "hot":
+				return ec.fieldContext_GPT_hot(ctx, field)
+			case "creator":
+				return ec.fieldContext_GPT_creator(ctx, field)
+			case "isRecommended":
+				return ec.fieldContext_GPT_isRecommended(ctx, field)
+			case "category":
+				return ec.fieldContext_GPT_category(ctx, field)
+			case "icon":
+				return ec.fieldContext_GPT_icon(ctx, field)
+			case "prologue":
+				return ec.fieldContext_GPT_prologue(ctx, field)
+			case "showRespInfo":
+				return ec.fieldContext_GPT_showRespInfo(ctx, field)
+			case "showRetrievalInfo":
+				return ec.fieldContext_GPT_showRetrievalInfo(ctx, field)
+			case "showNextGuide":
+				return ec.fieldContext_GPT_showNextGuide(ctx, field)
+			case "enableUploadFile":
+				return ec.fieldContext_GPT_enableUploadFile(ctx, field)
+			case "notReadyReasonCode":
+				return ec.fieldContext_GPT_notReadyReasonCode(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type GPT", field.Name)
+		},
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ec.Recover(ctx, r)
+			ec.Error(ctx, err)
+		}
+	}()
+	ctx = graphql.WithFieldContext(ctx, fc)
+	if fc.Args, err = ec.field_GPTQuery_getGPT_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+		ec.Error(ctx, err)
+		return fc, err
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPTQuery_listGPT(ctx context.Context, field graphql.CollectedField, obj *GPTQuery) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPTQuery_listGPT(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.GPTQuery().ListGpt(rctx, obj, fc.Args["input"].(ListGPTInput))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*PaginatedResult)
+	fc.Result = res
+	return ec.marshalNPaginatedResult2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐPaginatedResult(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPTQuery_listGPT(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPTQuery",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "hasNextPage":
+				return ec.fieldContext_PaginatedResult_hasNextPage(ctx, field)
+			case "nodes":
+				return ec.fieldContext_PaginatedResult_nodes(ctx, field)
+			case "page":
+				return ec.fieldContext_PaginatedResult_page(ctx, field)
+			case "pageSize":
+				return ec.fieldContext_PaginatedResult_pageSize(ctx, field)
+			case "totalCount":
+				return ec.fieldContext_PaginatedResult_totalCount(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type PaginatedResult", field.Name)
+		},
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ec.Recover(ctx, r)
+			ec.Error(ctx, err)
+		}
+	}()
+	ctx = graphql.WithFieldContext(ctx, fc)
+	if fc.Args, err = ec.field_GPTQuery_listGPT_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+		ec.Error(ctx, err)
+		return fc, err
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPTQuery_listGPTCategory(ctx context.Context, field graphql.CollectedField, obj *GPTQuery) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPTQuery_listGPTCategory(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.GPTQuery().ListGPTCategory(rctx, obj)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]*GPTCategory)
+	fc.Result = res
+	return ec.marshalNGPTCategory2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐGPTCategory(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPTQuery_listGPTCategory(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPTQuery",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "id":
+				return ec.fieldContext_GPTCategory_id(ctx, field)
+			case "name":
+				return ec.fieldContext_GPTCategory_name(ctx, field)
+			case "nameEn":
+				return ec.fieldContext_GPTCategory_nameEn(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type GPTCategory", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPTQuery_getGPTStore(ctx context.Context, field graphql.CollectedField, obj *GPTQuery) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPTQuery_getGPTStore(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.GPTQuery().GetGPTStore(rctx, obj)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*GPTStore)
+	fc.Result = res
+	return ec.marshalNGPTStore2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐGPTStore(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPTQuery_getGPTStore(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPTQuery",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "url":
+				return ec.fieldContext_GPTStore_url(ctx, field)
+			case "public_namespace":
+				return ec.fieldContext_GPTStore_public_namespace(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type GPTStore", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPTStore_url(ctx context.Context, field graphql.CollectedField, obj *GPTStore) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPTStore_url(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.URL, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPTStore_url(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPTStore",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _GPTStore_public_namespace(ctx context.Context, field graphql.CollectedField, obj *GPTStore) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPTStore_public_namespace(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.PublicNamespace, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPTStore_public_namespace(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPTStore",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _KnowledgeBase_id(ctx context.Context, field graphql.CollectedField, obj *KnowledgeBase) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_KnowledgeBase_id(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_KnowledgeBase_id(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "KnowledgeBase",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _KnowledgeBase_name(ctx context.Context, field graphql.CollectedField, obj *KnowledgeBase) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_KnowledgeBase_name(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Name, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_KnowledgeBase_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "KnowledgeBase",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _KnowledgeBase_namespace(ctx context.Context, field graphql.CollectedField, obj *KnowledgeBase) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_KnowledgeBase_namespace(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Namespace, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
 	fc.Result = res
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
@@ -26104,6 +27879,51 @@ func (ec *executionContext) fieldContext_Mutation_Embedder(ctx context.Context,
 	return fc, nil
 }
 
+func (ec *executionContext) _Mutation_Feedback(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Mutation_Feedback(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().Feedback(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*FeedbackMutation)
+	fc.Result = res
+	return ec.marshalOFeedbackMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackMutation(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Mutation_Feedback(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Mutation",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "exportFeedbacks":
+				return ec.fieldContext_FeedbackMutation_exportFeedbacks(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type FeedbackMutation", field.Name)
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _Mutation_KnowledgeBase(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_Mutation_KnowledgeBase(ctx, field)
 	if err != nil {
@@ -27922,6 +29742,53 @@ func (ec *executionContext) fieldContext_Query_Embedder(ctx context.Context, fie
 	return fc, nil
 }
 
+func (ec *executionContext) _Query_Feedback(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Query_Feedback(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Query().Feedback(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*FeedbackQuery)
+	fc.Result = res
+	return ec.marshalOFeedbackQuery2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackQuery(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Query_Feedback(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Query",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "summarizeFeedbacks":
+				return ec.fieldContext_FeedbackQuery_summarizeFeedbacks(ctx, field)
+			case "listFeedbacks":
+				return ec.fieldContext_FeedbackQuery_listFeedbacks(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type FeedbackQuery", field.Name)
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _Query_GPT(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_Query_GPT(ctx, field)
 	if err != nil {
@@ -28300,6 +30167,51 @@ func (ec *executionContext) fieldContext_Query_RayCluster(ctx context.Context, f
 	return fc, nil
 }
 
+func (ec *executionContext) _Query_TokenUsage(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Query_TokenUsage(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Query().TokenUsage(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*TokenUsageQuery)
+	fc.Result = res
+	return ec.marshalOTokenUsageQuery2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐTokenUsageQuery(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Query_TokenUsage(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Query",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "listTokenUsages":
+				return ec.fieldContext_TokenUsageQuery_listTokenUsages(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type TokenUsageQuery", field.Name)
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _Query_VersionedDataset(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_Query_VersionedDataset(ctx, field)
 	if err != nil {
@@ -30987,6 +32899,431 @@ func (ec *executionContext) fieldContext_Selector_matchExpressions(ctx context.C
 	return fc, nil
 }
 
+func (ec *executionContext) _TokenUsage_date(ctx context.Context, field graphql.CollectedField, obj *TokenUsage) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_TokenUsage_date(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Date, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_TokenUsage_date(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "TokenUsage",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _TokenUsage_namespace(ctx context.Context, field graphql.CollectedField, obj *TokenUsage) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_TokenUsage_namespace(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Namespace, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_TokenUsage_namespace(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "TokenUsage",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _TokenUsage_appName(ctx context.Context, field graphql.CollectedField, obj *TokenUsage) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_TokenUsage_appName(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.AppName, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_TokenUsage_appName(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "TokenUsage",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _TokenUsage_user(ctx context.Context, field graphql.CollectedField, obj *TokenUsage) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_TokenUsage_user(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.User, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_TokenUsage_user(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "TokenUsage",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _TokenUsage_promptTokens(ctx context.Context, field graphql.CollectedField, obj *TokenUsage) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_TokenUsage_promptTokens(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.PromptTokens, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int64)
+	fc.Result = res
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_TokenUsage_promptTokens(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "TokenUsage",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _TokenUsage_completionTokens(ctx context.Context, field graphql.CollectedField, obj *TokenUsage) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_TokenUsage_completionTokens(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CompletionTokens, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int64)
+	fc.Result = res
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_TokenUsage_completionTokens(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "TokenUsage",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _TokenUsage_totalTokens(ctx context.Context, field graphql.CollectedField, obj *TokenUsage) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_TokenUsage_totalTokens(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.TotalTokens, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int64)
+	fc.Result = res
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_TokenUsage_totalTokens(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "TokenUsage",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _TokenUsage_requests(ctx context.Context, field graphql.CollectedField, obj *TokenUsage) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_TokenUsage_requests(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Requests, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int64)
+	fc.Result = res
+	return ec.marshalNInt642int64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_TokenUsage_requests(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "TokenUsage",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int64 does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _TokenUsageQuery_listTokenUsages(ctx context.Context, field graphql.CollectedField, obj *TokenUsageQuery) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_TokenUsageQuery_listTokenUsages(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.TokenUsageQuery().ListTokenUsages(rctx, obj, fc.Args["input"].(ListTokenUsageInput))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]*TokenUsage)
+	fc.Result = res
+	return ec.marshalNTokenUsage2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐTokenUsageᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_TokenUsageQuery_listTokenUsages(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "TokenUsageQuery",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "date":
+				return ec.fieldContext_TokenUsage_date(ctx, field)
+			case "namespace":
+				return ec.fieldContext_TokenUsage_namespace(ctx, field)
+			case "appName":
+				return ec.fieldContext_TokenUsage_appName(ctx, field)
+			case "user":
+				return ec.fieldContext_TokenUsage_user(ctx, field)
+			case "promptTokens":
+				return ec.fieldContext_TokenUsage_promptTokens(ctx, field)
+			case "completionTokens":
+				return ec.fieldContext_TokenUsage_completionTokens(ctx, field)
+			case "totalTokens":
+				return ec.fieldContext_TokenUsage_totalTokens(ctx, field)
+			case "requests":
+				return ec.fieldContext_TokenUsage_requests(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type TokenUsage", field.Name)
+		},
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ec.Recover(ctx, r)
+			ec.Error(ctx, err)
+		}
+	}()
+	ctx = graphql.WithFieldContext(ctx, fc)
+	if fc.Args, err = ec.field_TokenUsageQuery_listTokenUsages_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+		ec.Error(ctx, err)
+		return fc, err
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _Tool_name(ctx context.Context, field graphql.CollectedField, obj *Tool) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_Tool_name(ctx, field)
 	if err != nil {
@@ -37506,83 +39843,199 @@ func (ec *executionContext) unmarshalInputDataProcessDetailsInput(ctx context.Co
 	return it, nil
 }
 
-func (ec *executionContext) unmarshalInputDataProcessFileLogInput(ctx context.Context, obj interface{}) (DataProcessFileLogInput, error) {
-	var it DataProcessFileLogInput
+func (ec *executionContext) unmarshalInputDataProcessFileLogInput(ctx context.Context, obj interface{}) (DataProcessFileLogInput, error) {
+	var it DataProcessFileLogInput
+	asMap := map[string]interface{}{}
+	for k, v := range obj.(map[string]interface{}) {
+		asMap[k] = v
+	}
+
+	fieldsInOrder := [...]string{"id", "file_name", "type"}
+	for _, k := range fieldsInOrder {
+		v, ok := asMap[k]
+		if !ok {
+			continue
+		}
+		switch k {
+		case "id":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("id"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.ID = data
+		case "file_name":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("file_name"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.FileName = data
+		case "type":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("type"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Type = data
+		}
+	}
+
+	return it, nil
+}
+
+func (ec *executionContext) unmarshalInputDataProcessRetryInput(ctx context.Context, obj interface{}) (DataProcessRetryInput, error) {
+	var it DataProcessRetryInput
+	asMap := map[string]interface{}{}
+	for k, v := range obj.(map[string]interface{}) {
+		asMap[k] = v
+	}
+
+	fieldsInOrder := [...]string{"id", "creator"}
+	for _, k := range fieldsInOrder {
+		v, ok := asMap[k]
+		if !ok {
+			continue
+		}
+		switch k {
+		case "id":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("id"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.ID = data
+		case "creator":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("creator"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Creator = data
+		}
+	}
+
+	return it, nil
+}
+
+func (ec *executionContext) unmarshalInputDeleteCommonInput(ctx context.Context, obj interface{}) (DeleteCommonInput, error) {
+	var it DeleteCommonInput
+	asMap := map[string]interface{}{}
+	for k, v := range obj.(map[string]interface{}) {
+		asMap[k] = v
+	}
+
+	fieldsInOrder := [...]string{"name", "namespace", "labelSelector", "fieldSelector"}
+	for _, k := range fieldsInOrder {
+		v, ok := asMap[k]
+		if !ok {
+			continue
+		}
+		switch k {
+		case "name":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("name"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Name = data
+		case "namespace":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("namespace"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Namespace = data
+		case "labelSelector":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("labelSelector"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.LabelSelector = data
+		case "fieldSelector":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("fieldSelector"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.FieldSelector = data
+		}
+	}
+
+	return it, nil
+}
+
+func (ec *executionContext) unmarshalInputDeleteDataProcessInput(ctx context.Context, obj interface{}) (DeleteDataProcessInput, error) {
+	var it DeleteDataProcessInput
+	asMap := map[string]interface{}{}
+	for k, v := range obj.(map[string]interface{}) {
+		asMap[k] = v
+	}
+
+	fieldsInOrder := [...]string{"id"}
+	for _, k := range fieldsInOrder {
+		v, ok := asMap[k]
+		if !ok {
+			continue
+		}
+		switch k {
+		case "id":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("id"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.ID = data
+		}
+	}
+
+	return it, nil
+}
+
+func (ec *executionContext) unmarshalInputDeleteRAGInput(ctx context.Context, obj interface{}) (DeleteRAGInput, error) {
+	var it DeleteRAGInput
 	asMap := map[string]interface{}{}
 	for k, v := range obj.(map[string]interface{}) {
 		asMap[k] = v
 	}
 
-	fieldsInOrder := [...]string{"id", "file_name", "type"}
+	fieldsInOrder := [...]string{"name", "namespace", "labelSelector"}
 	for _, k := range fieldsInOrder {
 		v, ok := asMap[k]
 		if !ok {
 			continue
 		}
 		switch k {
-		case "id":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("id"))
-			data, err := ec.unmarshalNString2string(ctx, v)
-			if err != nil {
-				return it, err
-			}
-			it.ID = data
-		case "file_name":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("file_name"))
-			data, err := ec.unmarshalNString2string(ctx, v)
-			if err != nil {
-				return it, err
-			}
-			it.FileName = data
-		case "type":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("type"))
+		case "name":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("name"))
 			data, err := ec.unmarshalNString2string(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.Type = data
-		}
-	}
-
-	return it, nil
-}
-
-func (ec *executionContext) unmarshalInputDataProcessRetryInput(ctx context.Context, obj interface{}) (DataProcessRetryInput, error) {
-	var it DataProcessRetryInput
-	asMap := map[string]interface{}{}
-	for k, v := range obj.(map[string]interface{}) {
-		asMap[k] = v
-	}
-
-	fieldsInOrder := [...]string{"id", "creator"}
-	for _, k := range fieldsInOrder {
-		v, ok := asMap[k]
-		if !ok {
-			continue
-		}
-		switch k {
-		case "id":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("id"))
+			it.Name = data
+		case "namespace":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("namespace"))
 			data, err := ec.unmarshalNString2string(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.ID = data
-		case "creator":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("creator"))
-			data, err := ec.unmarshalNString2string(ctx, v)
+			it.Namespace = data
+		case "labelSelector":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("labelSelector"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.Creator = data
+			it.LabelSelector = data
 		}
 	}
 
 	return it, nil
 }
 
-func (ec *executionContext) unmarshalInputDeleteCommonInput(ctx context.Context, obj interface{}) (DeleteCommonInput, error) {
-	var it DeleteCommonInput
+func (ec *executionContext) unmarshalInputDeleteVersionedDatasetInput(ctx context.Context, obj interface{}) (DeleteVersionedDatasetInput, error) {
+	var it DeleteVersionedDatasetInput
 	asMap := map[string]interface{}{}
 	for k, v := range obj.(map[string]interface{}) {
 		asMap[k] = v
@@ -37629,41 +40082,14 @@ func (ec *executionContext) unmarshalInputDeleteCommonInput(ctx context.Context,
 	return it, nil
 }
 
-func (ec *executionContext) unmarshalInputDeleteDataProcessInput(ctx context.Context, obj interface{}) (DeleteDataProcessInput, error) {
-	var it DeleteDataProcessInput
-	asMap := map[string]interface{}{}
-	for k, v := range obj.(map[string]interface{}) {
-		asMap[k] = v
-	}
-
-	fieldsInOrder := [...]string{"id"}
-	for _, k := range fieldsInOrder {
-		v, ok := asMap[k]
-		if !ok {
-			continue
-		}
-		switch k {
-		case "id":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("id"))
-			data, err := ec.unmarshalNString2string(ctx, v)
-			if err != nil {
-				return it, err
-			}
-			it.ID = data
-		}
-	}
-
-	return it, nil
-}
-
-func (ec *executionContext) unmarshalInputDeleteRAGInput(ctx context.Context, obj interface{}) (DeleteRAGInput, error) {
-	var it DeleteRAGInput
+func (ec *executionContext) unmarshalInputDuplicateRAGInput(ctx context.Context, obj interface{}) (DuplicateRAGInput, error) {
+	var it DuplicateRAGInput
 	asMap := map[string]interface{}{}
 	for k, v := range obj.(map[string]interface{}) {
 		asMap[k] = v
 	}
 
-	fieldsInOrder := [...]string{"name", "namespace", "labelSelector"}
+	fieldsInOrder := [...]string{"name", "namespace", "displayName"}
 	for _, k := range fieldsInOrder {
 		v, ok := asMap[k]
 		if !ok {
@@ -37684,143 +40110,123 @@ func (ec *executionContext) unmarshalInputDeleteRAGInput(ctx context.Context, ob
 				return it, err
 			}
 			it.Namespace = data
-		case "labelSelector":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("labelSelector"))
+		case "displayName":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("displayName"))
 			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.LabelSelector = data
+			it.DisplayName = data
 		}
 	}
 
 	return it, nil
 }
 
-func (ec *executionContext) unmarshalInputDeleteVersionedDatasetInput(ctx context.Context, obj interface{}) (DeleteVersionedDatasetInput, error) {
-	var it DeleteVersionedDatasetInput
+func (ec *executionContext) unmarshalInputEndpointInput(ctx context.Context, obj interface{}) (EndpointInput, error) {
+	var it EndpointInput
 	asMap := map[string]interface{}{}
 	for k, v := range obj.(map[string]interface{}) {
 		asMap[k] = v
 	}
 
-	fieldsInOrder := [...]string{"name", "namespace", "labelSelector", "fieldSelector"}
+	fieldsInOrder := [...]string{"url", "auth", "insecure"}
 	for _, k := range fieldsInOrder {
 		v, ok := asMap[k]
 		if !ok {
 			continue
 		}
 		switch k {
-		case "name":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("name"))
-			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
-			if err != nil {
-				return it, err
-			}
-			it.Name = data
-		case "namespace":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("namespace"))
+		case "url":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("url"))
 			data, err := ec.unmarshalNString2string(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.Namespace = data
-		case "labelSelector":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("labelSelector"))
-			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			it.URL = data
+		case "auth":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("auth"))
+			data, err := ec.unmarshalOMap2map(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.LabelSelector = data
-		case "fieldSelector":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("fieldSelector"))
-			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			it.Auth = data
+		case "insecure":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("insecure"))
+			data, err := ec.unmarshalOBoolean2ᚖbool(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.FieldSelector = data
+			it.Insecure = data
 		}
 	}
 
 	return it, nil
 }
 
-func (ec *executionContext) unmarshalInputDuplicateRAGInput(ctx context.Context, obj interface{}) (DuplicateRAGInput, error) {
-	var it DuplicateRAGInput
+func (ec *executionContext) unmarshalInputExportFeedbackInput(ctx context.Context, obj interface{}) (ExportFeedbackInput, error) {
+	var it ExportFeedbackInput
 	asMap := map[string]interface{}{}
 	for k, v := range obj.(map[string]interface{}) {
 		asMap[k] = v
 	}
 
-	fieldsInOrder := [...]string{"name", "namespace", "displayName"}
+	fieldsInOrder := [...]string{"namespace", "appName", "category", "startDate", "endDate", "versionedDataset", "fileName"}
 	for _, k := range fieldsInOrder {
 		v, ok := asMap[k]
 		if !ok {
 			continue
 		}
 		switch k {
-		case "name":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("name"))
+		case "namespace":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("namespace"))
 			data, err := ec.unmarshalNString2string(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.Name = data
-		case "namespace":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("namespace"))
+			it.Namespace = data
+		case "appName":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("appName"))
 			data, err := ec.unmarshalNString2string(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.Namespace = data
-		case "displayName":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("displayName"))
+			it.AppName = data
+		case "category":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("category"))
 			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.DisplayName = data
-		}
-	}
-
-	return it, nil
-}
-
-func (ec *executionContext) unmarshalInputEndpointInput(ctx context.Context, obj interface{}) (EndpointInput, error) {
-	var it EndpointInput
-	asMap := map[string]interface{}{}
-	for k, v := range obj.(map[string]interface{}) {
-		asMap[k] = v
-	}
-
-	fieldsInOrder := [...]string{"url", "auth", "insecure"}
-	for _, k := range fieldsInOrder {
-		v, ok := asMap[k]
-		if !ok {
-			continue
-		}
-		switch k {
-		case "url":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("url"))
-			data, err := ec.unmarshalNString2string(ctx, v)
+			it.Category = data
+		case "startDate":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("startDate"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.URL = data
-		case "auth":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("auth"))
-			data, err := ec.unmarshalOMap2map(ctx, v)
+			it.StartDate = data
+		case "endDate":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("endDate"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.Auth = data
-		case "insecure":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("insecure"))
-			data, err := ec.unmarshalOBoolean2ᚖbool(ctx, v)
+			it.EndDate = data
+		case "versionedDataset":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("versionedDataset"))
+			data, err := ec.unmarshalNString2string(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.Insecure = data
+			it.VersionedDataset = data
+		case "fileName":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("fileName"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.FileName = data
 		}
 	}
 
@@ -38239,6 +40645,68 @@ func (ec *executionContext) unmarshalInputListDatasetInput(ctx context.Context,
 	return it, nil
 }
 
+func (ec *executionContext) unmarshalInputListFeedbackInput(ctx context.Context, obj interface{}) (ListFeedbackInput, error) {
+	var it ListFeedbackInput
+	asMap := map[string]interface{}{}
+	for k, v := range obj.(map[string]interface{}) {
+		asMap[k] = v
+	}
+
+	fieldsInOrder := [...]string{"namespace", "appName", "rating", "category", "startDate", "endDate"}
+	for _, k := range fieldsInOrder {
+		v, ok := asMap[k]
+		if !ok {
+			continue
+		}
+		switch k {
+		case "namespace":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("namespace"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Namespace = data
+		case "appName":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("appName"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.AppName = data
+		case "rating":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("rating"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Rating = data
+		case "category":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("category"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Category = data
+		case "startDate":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("startDate"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.StartDate = data
+		case "endDate":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("endDate"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.EndDate = data
+		}
+	}
+
+	return it, nil
+}
+
 func (ec *executionContext) unmarshalInputListGPTInput(ctx context.Context, obj interface{}) (ListGPTInput, error) {
 	var it ListGPTInput
 	asMap := map[string]interface{}{}
@@ -38604,6 +41072,61 @@ func (ec *executionContext) unmarshalInputListRAGInput(ctx context.Context, obj
 	return it, nil
 }
 
+func (ec *executionContext) unmarshalInputListTokenUsageInput(ctx context.Context, obj interface{}) (ListTokenUsageInput, error) {
+	var it ListTokenUsageInput
+	asMap := map[string]interface{}{}
+	for k, v := range obj.(map[string]interface{}) {
+		asMap[k] = v
+	}
+
+	fieldsInOrder := [...]string{"namespace", "appName", "user", "startDate", "endDate"}
+	for _, k := range fieldsInOrder {
+		v, ok := asMap[k]
+		if !ok {
+			continue
+		}
+		switch k {
+		case "namespace":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("namespace"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Namespace = data
+		case "appName":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("appName"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.AppName = data
+		case "user":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("user"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.User = data
+		case "startDate":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("startDate"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.StartDate = data
+		case "endDate":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("endDate"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.EndDate = data
+		}
+	}
+
+	return it, nil
+}
+
 func (ec *executionContext) unmarshalInputListVersionedDatasetInput(ctx context.Context, obj interface{}) (ListVersionedDatasetInput, error) {
 	var it ListVersionedDatasetInput
 	asMap := map[string]interface{}{}
@@ -43217,7 +45740,382 @@ func (ec *executionContext) _EmbedderQuery(ctx context.Context, sel ast.Selectio
 			}
 
 			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
-		case "listEmbedders":
+		case "listEmbedders":
+			field := field
+
+			innerFunc := func(ctx context.Context, fs *graphql.FieldSet) (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._EmbedderQuery_listEmbedders(ctx, field, obj)
+				if res == graphql.Null {
+					atomic.AddUint32(&fs.Invalids, 1)
+				}
+				return res
+			}
+
+			if field.Deferrable != nil {
+				dfs, ok := deferred[field.Deferrable.Label]
+				di := 0
+				if ok {
+					dfs.AddField(field)
+					di = len(dfs.Values) - 1
+				} else {
+					dfs = graphql.NewFieldSet([]graphql.CollectedField{field})
+					deferred[field.Deferrable.Label] = dfs
+				}
+				dfs.Concurrently(di, func(ctx context.Context) graphql.Marshaler {
+					return innerFunc(ctx, dfs)
+				})
+
+				// don't run the out.Concurrently() call below
+				out.Values[i] = graphql.Null
+				continue
+			}
+
+			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var endpointImplementors = []string{"Endpoint"}
+
+func (ec *executionContext) _Endpoint(ctx context.Context, sel ast.SelectionSet, obj *Endpoint) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, endpointImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Endpoint")
+		case "url":
+			out.Values[i] = ec._Endpoint_url(ctx, field, obj)
+		case "authSecret":
+			out.Values[i] = ec._Endpoint_authSecret(ctx, field, obj)
+		case "insecure":
+			out.Values[i] = ec._Endpoint_insecure(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var fImplementors = []string{"F", "PageNode"}
+
+func (ec *executionContext) _F(ctx context.Context, sel ast.SelectionSet, obj *F) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, fImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("F")
+		case "path":
+			out.Values[i] = ec._F_path(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "fileType":
+			out.Values[i] = ec._F_fileType(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "count":
+			out.Values[i] = ec._F_count(ctx, field, obj)
+		case "time":
+			out.Values[i] = ec._F_time(ctx, field, obj)
+		case "size":
+			out.Values[i] = ec._F_size(ctx, field, obj)
+		case "creationTimestamp":
+			out.Values[i] = ec._F_creationTimestamp(ctx, field, obj)
+		case "versions":
+			out.Values[i] = ec._F_versions(ctx, field, obj)
+		case "latestVersion":
+			out.Values[i] = ec._F_latestVersion(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var feedbackImplementors = []string{"Feedback"}
+
+func (ec *executionContext) _Feedback(ctx context.Context, sel ast.SelectionSet, obj *Feedback) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, feedbackImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Feedback")
+		case "messageId":
+			out.Values[i] = ec._Feedback_messageId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "version":
+			out.Values[i] = ec._Feedback_version(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "conversationId":
+			out.Values[i] = ec._Feedback_conversationId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "namespace":
+			out.Values[i] = ec._Feedback_namespace(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "appName":
+			out.Values[i] = ec._Feedback_appName(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "user":
+			out.Values[i] = ec._Feedback_user(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "rating":
+			out.Values[i] = ec._Feedback_rating(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "category":
+			out.Values[i] = ec._Feedback_category(ctx, field, obj)
+		case "comment":
+			out.Values[i] = ec._Feedback_comment(ctx, field, obj)
+		case "expectedAnswer":
+			out.Values[i] = ec._Feedback_expectedAnswer(ctx, field, obj)
+		case "query":
+			out.Values[i] = ec._Feedback_query(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "answer":
+			out.Values[i] = ec._Feedback_answer(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "creationTimestamp":
+			out.Values[i] = ec._Feedback_creationTimestamp(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "updateTimestamp":
+			out.Values[i] = ec._Feedback_updateTimestamp(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var feedbackCategoryCountImplementors = []string{"FeedbackCategoryCount"}
+
+func (ec *executionContext) _FeedbackCategoryCount(ctx context.Context, sel ast.SelectionSet, obj *FeedbackCategoryCount) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, feedbackCategoryCountImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("FeedbackCategoryCount")
+		case "category":
+			out.Values[i] = ec._FeedbackCategoryCount_category(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "dislikes":
+			out.Values[i] = ec._FeedbackCategoryCount_dislikes(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var feedbackMutationImplementors = []string{"FeedbackMutation"}
+
+func (ec *executionContext) _FeedbackMutation(ctx context.Context, sel ast.SelectionSet, obj *FeedbackMutation) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, feedbackMutationImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("FeedbackMutation")
+		case "exportFeedbacks":
+			field := field
+
+			innerFunc := func(ctx context.Context, fs *graphql.FieldSet) (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._FeedbackMutation_exportFeedbacks(ctx, field, obj)
+				if res == graphql.Null {
+					atomic.AddUint32(&fs.Invalids, 1)
+				}
+				return res
+			}
+
+			if field.Deferrable != nil {
+				dfs, ok := deferred[field.Deferrable.Label]
+				di := 0
+				if ok {
+					dfs.AddField(field)
+					di = len(dfs.Values) - 1
+				} else {
+					dfs = graphql.NewFieldSet([]graphql.CollectedField{field})
+					deferred[field.Deferrable.Label] = dfs
+				}
+				dfs.Concurrently(di, func(ctx context.Context) graphql.Marshaler {
+					return innerFunc(ctx, dfs)
+				})
+
+				// don't run the out.Concurrently() call below
+				out.Values[i] = graphql.Null
+				continue
+			}
+
+			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var feedbackQueryImplementors = []string{"FeedbackQuery"}
+
+func (ec *executionContext) _FeedbackQuery(ctx context.Context, sel ast.SelectionSet, obj *FeedbackQuery) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, feedbackQueryImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("FeedbackQuery")
+		case "summarizeFeedbacks":
 			field := field
 
 			innerFunc := func(ctx context.Context, fs *graphql.FieldSet) (res graphql.Marshaler) {
@@ -43226,7 +46124,7 @@ func (ec *executionContext) _EmbedderQuery(ctx context.Context, sel ast.Selectio
 						ec.Error(ctx, ec.Recover(ctx, r))
 					}
 				}()
-				res = ec._EmbedderQuery_listEmbedders(ctx, field, obj)
+				res = ec._FeedbackQuery_summarizeFeedbacks(ctx, field, obj)
 				if res == graphql.Null {
 					atomic.AddUint32(&fs.Invalids, 1)
 				}
@@ -43253,46 +46151,42 @@ func (ec *executionContext) _EmbedderQuery(ctx context.Context, sel ast.Selectio
 			}
 
 			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
-		default:
-			panic("unknown field " + strconv.Quote(field.Name))
-		}
-	}
-	out.Dispatch(ctx)
-	if out.Invalids > 0 {
-		return graphql.Null
-	}
-
-	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
-
-	for label, dfs := range deferred {
-		ec.processDeferredGroup(graphql.DeferredGroup{
-			Label:    label,
-			Path:     graphql.GetPath(ctx),
-			FieldSet: dfs,
-			Context:  ctx,
-		})
-	}
+		case "listFeedbacks":
+			field := field
 
-	return out
-}
+			innerFunc := func(ctx context.Context, fs *graphql.FieldSet) (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._FeedbackQuery_listFeedbacks(ctx, field, obj)
+				if res == graphql.Null {
+					atomic.AddUint32(&fs.Invalids, 1)
+				}
+				return res
+			}
 
-var endpointImplementors = []string{"Endpoint"}
+			if field.Deferrable != nil {
+				dfs, ok := deferred[field.Deferrable.Label]
+				di := 0
+				if ok {
+					dfs.AddField(field)
+					di = len(dfs.Values) - 1
+				} else {
+					dfs = graphql.NewFieldSet([]graphql.CollectedField{field})
+					deferred[field.Deferrable.Label] = dfs
+				}
+				dfs.Concurrently(di, func(ctx context.Context) graphql.Marshaler {
+					return innerFunc(ctx, dfs)
+				})
 
-func (ec *executionContext) _Endpoint(ctx context.Context, sel ast.SelectionSet, obj *Endpoint) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, endpointImplementors)
+				// don't run the out.Concurrently() call below
+				out.Values[i] = graphql.Null
+				continue
+			}
 
-	out := graphql.NewFieldSet(fields)
-	deferred := make(map[string]*graphql.FieldSet)
-	for i, field := range fields {
-		switch field.Name {
-		case "__typename":
-			out.Values[i] = graphql.MarshalString("Endpoint")
-		case "url":
-			out.Values[i] = ec._Endpoint_url(ctx, field, obj)
-		case "authSecret":
-			out.Values[i] = ec._Endpoint_authSecret(ctx, field, obj)
-		case "insecure":
-			out.Values[i] = ec._Endpoint_insecure(ctx, field, obj)
+			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -43316,39 +46210,47 @@ func (ec *executionContext) _Endpoint(ctx context.Context, sel ast.SelectionSet,
 	return out
 }
 
-var fImplementors = []string{"F", "PageNode"}
+var feedbackSummaryImplementors = []string{"FeedbackSummary"}
 
-func (ec *executionContext) _F(ctx context.Context, sel ast.SelectionSet, obj *F) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, fImplementors)
+func (ec *executionContext) _FeedbackSummary(ctx context.Context, sel ast.SelectionSet, obj *FeedbackSummary) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, feedbackSummaryImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	deferred := make(map[string]*graphql.FieldSet)
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("F")
-		case "path":
-			out.Values[i] = ec._F_path(ctx, field, obj)
+			out.Values[i] = graphql.MarshalString("FeedbackSummary")
+		case "namespace":
+			out.Values[i] = ec._FeedbackSummary_namespace(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
-		case "fileType":
-			out.Values[i] = ec._F_fileType(ctx, field, obj)
+		case "appName":
+			out.Values[i] = ec._FeedbackSummary_appName(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "total":
+			out.Values[i] = ec._FeedbackSummary_total(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "likes":
+			out.Values[i] = ec._FeedbackSummary_likes(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "dislikes":
+			out.Values[i] = ec._FeedbackSummary_dislikes(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "categories":
+			out.Values[i] = ec._FeedbackSummary_categories(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
-		case "count":
-			out.Values[i] = ec._F_count(ctx, field, obj)
-		case "time":
-			out.Values[i] = ec._F_time(ctx, field, obj)
-		case "size":
-			out.Values[i] = ec._F_size(ctx, field, obj)
-		case "creationTimestamp":
-			out.Values[i] = ec._F_creationTimestamp(ctx, field, obj)
-		case "versions":
-			out.Values[i] = ec._F_versions(ctx, field, obj)
-		case "latestVersion":
-			out.Values[i] = ec._F_latestVersion(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -45167,6 +48069,10 @@ func (ec *executionContext) _Mutation(ctx context.Context, sel ast.SelectionSet)
 			out.Values[i] = ec.OperationContext.RootResolverMiddleware(innerCtx, func(ctx context.Context) (res graphql.Marshaler) {
 				return ec._Mutation_Embedder(ctx, field)
 			})
+		case "Feedback":
+			out.Values[i] = ec.OperationContext.RootResolverMiddleware(innerCtx, func(ctx context.Context) (res graphql.Marshaler) {
+				return ec._Mutation_Feedback(ctx, field)
+			})
 		case "KnowledgeBase":
 			out.Values[i] = ec.OperationContext.RootResolverMiddleware(innerCtx, func(ctx context.Context) (res graphql.Marshaler) {
 				return ec._Mutation_KnowledgeBase(ctx, field)
@@ -45770,6 +48676,25 @@ func (ec *executionContext) _Query(ctx context.Context, sel ast.SelectionSet) gr
 					func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
 			}
 
+			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return rrm(innerCtx) })
+		case "Feedback":
+			field := field
+
+			innerFunc := func(ctx context.Context, fs *graphql.FieldSet) (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_Feedback(ctx, field)
+				return res
+			}
+
+			rrm := func(ctx context.Context) graphql.Marshaler {
+				return ec.OperationContext.RootResolverMiddleware(ctx,
+					func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
+			}
+
 			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return rrm(innerCtx) })
 		case "GPT":
 			field := field
@@ -45922,6 +48847,25 @@ func (ec *executionContext) _Query(ctx context.Context, sel ast.SelectionSet) gr
 					func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
 			}
 
+			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return rrm(innerCtx) })
+		case "TokenUsage":
+			field := field
+
+			innerFunc := func(ctx context.Context, fs *graphql.FieldSet) (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_TokenUsage(ctx, field)
+				return res
+			}
+
+			rrm := func(ctx context.Context) graphql.Marshaler {
+				return ec.OperationContext.RootResolverMiddleware(ctx,
+					func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
+			}
+
 			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return rrm(innerCtx) })
 		case "VersionedDataset":
 			field := field
@@ -46834,21 +49778,165 @@ func (ec *executionContext) _Resources(ctx context.Context, sel ast.SelectionSet
 	return out
 }
 
-var selectorImplementors = []string{"Selector"}
+var selectorImplementors = []string{"Selector"}
+
+func (ec *executionContext) _Selector(ctx context.Context, sel ast.SelectionSet, obj *Selector) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, selectorImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Selector")
+		case "matchLabels":
+			out.Values[i] = ec._Selector_matchLabels(ctx, field, obj)
+		case "matchExpressions":
+			out.Values[i] = ec._Selector_matchExpressions(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var tokenUsageImplementors = []string{"TokenUsage"}
+
+func (ec *executionContext) _TokenUsage(ctx context.Context, sel ast.SelectionSet, obj *TokenUsage) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, tokenUsageImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("TokenUsage")
+		case "date":
+			out.Values[i] = ec._TokenUsage_date(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "namespace":
+			out.Values[i] = ec._TokenUsage_namespace(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "appName":
+			out.Values[i] = ec._TokenUsage_appName(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "user":
+			out.Values[i] = ec._TokenUsage_user(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "promptTokens":
+			out.Values[i] = ec._TokenUsage_promptTokens(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "completionTokens":
+			out.Values[i] = ec._TokenUsage_completionTokens(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "totalTokens":
+			out.Values[i] = ec._TokenUsage_totalTokens(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "requests":
+			out.Values[i] = ec._TokenUsage_requests(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var tokenUsageQueryImplementors = []string{"TokenUsageQuery"}
 
-func (ec *executionContext) _Selector(ctx context.Context, sel ast.SelectionSet, obj *Selector) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, selectorImplementors)
+func (ec *executionContext) _TokenUsageQuery(ctx context.Context, sel ast.SelectionSet, obj *TokenUsageQuery) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, tokenUsageQueryImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	deferred := make(map[string]*graphql.FieldSet)
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("Selector")
-		case "matchLabels":
-			out.Values[i] = ec._Selector_matchLabels(ctx, field, obj)
-		case "matchExpressions":
-			out.Values[i] = ec._Selector_matchExpressions(ctx, field, obj)
+			out.Values[i] = graphql.MarshalString("TokenUsageQuery")
+		case "listTokenUsages":
+			field := field
+
+			innerFunc := func(ctx context.Context, fs *graphql.FieldSet) (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._TokenUsageQuery_listTokenUsages(ctx, field, obj)
+				if res == graphql.Null {
+					atomic.AddUint32(&fs.Invalids, 1)
+				}
+				return res
+			}
+
+			if field.Deferrable != nil {
+				dfs, ok := deferred[field.Deferrable.Label]
+				di := 0
+				if ok {
+					dfs.AddField(field)
+					di = len(dfs.Values) - 1
+				} else {
+					dfs = graphql.NewFieldSet([]graphql.CollectedField{field})
+					deferred[field.Deferrable.Label] = dfs
+				}
+				dfs.Concurrently(di, func(ctx context.Context) graphql.Marshaler {
+					return innerFunc(ctx, dfs)
+				})
+
+				// don't run the out.Concurrently() call below
+				out.Values[i] = graphql.Null
+				continue
+			}
+
+			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -48391,6 +51479,11 @@ func (ec *executionContext) unmarshalNEndpointInput2githubᚗcomᚋkubeagiᚋarc
 	return res, graphql.ErrorOnPath(ctx, err)
 }
 
+func (ec *executionContext) unmarshalNExportFeedbackInput2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐExportFeedbackInput(ctx context.Context, v interface{}) (ExportFeedbackInput, error) {
+	res, err := ec.unmarshalInputExportFeedbackInput(ctx, v)
+	return res, graphql.ErrorOnPath(ctx, err)
+}
+
 func (ec *executionContext) marshalNF2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐF(ctx context.Context, sel ast.SelectionSet, v *F) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
@@ -48401,6 +51494,168 @@ func (ec *executionContext) marshalNF2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapis
 	return ec._F(ctx, sel, v)
 }
 
+func (ec *executionContext) marshalNFeedback2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackᚄ(ctx context.Context, sel ast.SelectionSet, v []*Feedback) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNFeedback2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedback(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+
+	for _, e := range ret {
+		if e == graphql.Null {
+			return graphql.Null
+		}
+	}
+
+	return ret
+}
+
+func (ec *executionContext) marshalNFeedback2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedback(ctx context.Context, sel ast.SelectionSet, v *Feedback) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+		return graphql.Null
+	}
+	return ec._Feedback(ctx, sel, v)
+}
+
+func (ec *executionContext) marshalNFeedbackCategoryCount2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackCategoryCountᚄ(ctx context.Context, sel ast.SelectionSet, v []*FeedbackCategoryCount) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNFeedbackCategoryCount2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackCategoryCount(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+
+	for _, e := range ret {
+		if e == graphql.Null {
+			return graphql.Null
+		}
+	}
+
+	return ret
+}
+
+func (ec *executionContext) marshalNFeedbackCategoryCount2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackCategoryCount(ctx context.Context, sel ast.SelectionSet, v *FeedbackCategoryCount) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+		return graphql.Null
+	}
+	return ec._FeedbackCategoryCount(ctx, sel, v)
+}
+
+func (ec *executionContext) marshalNFeedbackSummary2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackSummaryᚄ(ctx context.Context, sel ast.SelectionSet, v []*FeedbackSummary) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNFeedbackSummary2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackSummary(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+
+	for _, e := range ret {
+		if e == graphql.Null {
+			return graphql.Null
+		}
+	}
+
+	return ret
+}
+
+func (ec *executionContext) marshalNFeedbackSummary2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackSummary(ctx context.Context, sel ast.SelectionSet, v *FeedbackSummary) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+		return graphql.Null
+	}
+	return ec._FeedbackSummary(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalNFileDetails2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFileDetails(ctx context.Context, sel ast.SelectionSet, v *FileDetails) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
@@ -48517,6 +51772,21 @@ func (ec *executionContext) marshalNInt2int(ctx context.Context, sel ast.Selecti
 	return res
 }
 
+func (ec *executionContext) unmarshalNInt642int64(ctx context.Context, v interface{}) (int64, error) {
+	res, err := graphql.UnmarshalInt64(v)
+	return res, graphql.ErrorOnPath(ctx, err)
+}
+
+func (ec *executionContext) marshalNInt642int64(ctx context.Context, sel ast.SelectionSet, v int64) graphql.Marshaler {
+	res := graphql.MarshalInt64(v)
+	if res == graphql.Null {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+	}
+	return res
+}
+
 func (ec *executionContext) marshalNKnowledgeBase2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐKnowledgeBase(ctx context.Context, sel ast.SelectionSet, v KnowledgeBase) graphql.Marshaler {
 	return ec._KnowledgeBase(ctx, sel, &v)
 }
@@ -48550,6 +51820,11 @@ func (ec *executionContext) unmarshalNListCommonInput2githubᚗcomᚋkubeagiᚋa
 	return res, graphql.ErrorOnPath(ctx, err)
 }
 
+func (ec *executionContext) unmarshalNListFeedbackInput2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐListFeedbackInput(ctx context.Context, v interface{}) (ListFeedbackInput, error) {
+	res, err := ec.unmarshalInputListFeedbackInput(ctx, v)
+	return res, graphql.ErrorOnPath(ctx, err)
+}
+
 func (ec *executionContext) unmarshalNListGPTInput2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐListGPTInput(ctx context.Context, v interface{}) (ListGPTInput, error) {
 	res, err := ec.unmarshalInputListGPTInput(ctx, v)
 	return res, graphql.ErrorOnPath(ctx, err)
@@ -48570,6 +51845,11 @@ func (ec *executionContext) unmarshalNListRAGInput2githubᚗcomᚋkubeagiᚋarca
 	return res, graphql.ErrorOnPath(ctx, err)
 }
 
+func (ec *executionContext) unmarshalNListTokenUsageInput2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐListTokenUsageInput(ctx context.Context, v interface{}) (ListTokenUsageInput, error) {
+	res, err := ec.unmarshalInputListTokenUsageInput(ctx, v)
+	return res, graphql.ErrorOnPath(ctx, err)
+}
+
 func (ec *executionContext) unmarshalNListVersionedDatasetInput2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐListVersionedDatasetInput(ctx context.Context, v interface{}) (ListVersionedDatasetInput, error) {
 	res, err := ec.unmarshalInputListVersionedDatasetInput(ctx, v)
 	return res, graphql.ErrorOnPath(ctx, err)
@@ -48888,6 +52168,60 @@ func (ec *executionContext) marshalNTime2timeᚐTime(ctx context.Context, sel as
 	return res
 }
 
+func (ec *executionContext) marshalNTokenUsage2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐTokenUsageᚄ(ctx context.Context, sel ast.SelectionSet, v []*TokenUsage) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNTokenUsage2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐTokenUsage(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+
+	for _, e := range ret {
+		if e == graphql.Null {
+			return graphql.Null
+		}
+	}
+
+	return ret
+}
+
+func (ec *executionContext) marshalNTokenUsage2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐTokenUsage(ctx context.Context, sel ast.SelectionSet, v *TokenUsage) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+		return graphql.Null
+	}
+	return ec._TokenUsage(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalNTypedObjectReference2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐTypedObjectReference(ctx context.Context, sel ast.SelectionSet, v TypedObjectReference) graphql.Marshaler {
 	return ec._TypedObjectReference(ctx, sel, &v)
 }
@@ -49923,6 +53257,20 @@ func (ec *executionContext) marshalOF2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋa
 	return ret
 }
 
+func (ec *executionContext) marshalOFeedbackMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackMutation(ctx context.Context, sel ast.SelectionSet, v *FeedbackMutation) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	return ec._FeedbackMutation(ctx, sel, v)
+}
+
+func (ec *executionContext) marshalOFeedbackQuery2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFeedbackQuery(ctx context.Context, sel ast.SelectionSet, v *FeedbackQuery) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	return ec._FeedbackQuery(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalOFileDetails2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐFileDetailsᚄ(ctx context.Context, sel ast.SelectionSet, v []*FileDetails) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
@@ -50813,6 +54161,13 @@ func (ec *executionContext) marshalOTime2ᚖtimeᚐTime(ctx context.Context, sel
 	return res
 }
 
+func (ec *executionContext) marshalOTokenUsageQuery2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐTokenUsageQuery(ctx context.Context, sel ast.SelectionSet, v *TokenUsageQuery) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	return ec._TokenUsageQuery(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalOTool2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐTool(ctx context.Context, sel ast.SelectionSet, v []*Tool) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
@@ -805,6 +805,26 @@ type EndpointInput struct {
 	Insecure *bool `json:"insecure,omitempty"`
 }
 
+type ExportFeedbackInput struct {
+	// 命名空间(必填)
+	Namespace string `json:"namespace"`
+	// 应用名称(必填)
+	AppName string `json:"appName"`
+	// 评价分类，不填时导出所有点踩的回答
+	Category *string `json:"category,omitempty"`
+	// 开始日期，格式为 2006-01-02
+	// 规则: 包含当天，不填时不限制
+	StartDate *string `json:"startDate,omitempty"`
+	// 结束日期，格式为 2006-01-02
+	// 规则: 包含当天，不填时不限制
+	EndDate *string `json:"endDate,omitempty"`
+	// 导出到的数据集版本，需要与应用在同一命名空间
+	VersionedDataset string `json:"versionedDataset"`
+	// 导出的文件名
+	// 规则: 以 .csv 结尾，默认为 feedback-<应用名称>-<当前时间>.csv
+	FileName *string `json:"fileName,omitempty"`
+}
+
 // File
 // 展示某个版本的所有文件。
 type F struct {
@@ -828,6 +848,78 @@ type F struct {
 
 func (F) IsPageNode() {}
 
+// Feedback
+// 用户对应用回答的评价
+type Feedback struct {
+	// 消息ID
+	MessageID string `json:"messageId"`
+	// 回答的版本，每次重新生成回答后加1
+	Version int `json:"version"`
+	// 对话ID
+	ConversationID string `json:"conversationId"`
+	// 应用所在命名空间
+	Namespace string `json:"namespace"`
+	// 应用名称
+	AppName string `json:"appName"`
+	// 用户
+	User string `json:"user"`
+	// 评价，like(赞) 或 dislike(踩)
+	Rating string `json:"rating"`
+	// 评价分类，如 inaccurate, irrelevant, incomplete, harmful 等
+	Category *string `json:"category,omitempty"`
+	// 评价内容
+	Comment *string `json:"comment,omitempty"`
+	// 用户期望的回答，导出到评估数据集时作为标准答案
+	ExpectedAnswer *string `json:"expectedAnswer,omitempty"`
+	// 用户的问题
+	Query string `json:"query"`
+	// 被评价的回答
+	Answer string `json:"answer"`
+	// 创建时间
+	CreationTimestamp time.Time `json:"creationTimestamp"`
+	// 更新时间
+	UpdateTimestamp time.Time `json:"updateTimestamp"`
+}
+
+// FeedbackCategoryCount
+// 某个评价分类的数量
+type FeedbackCategoryCount struct {
+	// 评价分类，空表示未分类
+	Category string `json:"category"`
+	// 点踩数量
+	Dislikes int64 `json:"dislikes"`
+}
+
+type FeedbackMutation struct {
+	// 把点踩的回答导出为 QA 格式的 csv 文件到数据集版本中，用于 RAG 评估
+	// 问题列为 q，标准答案列为 a，标准答案使用用户期望的回答，没有期望回答的不会导出
+	// 文件保存在系统数据源中，并添加到数据集版本的文件列表，由数据集版本同步
+	// 返回文件在数据集版本中的路径，为 feedback/<命名空间>/<文件名>
+	ExportFeedbacks string `json:"exportFeedbacks"`
+}
+
+type FeedbackQuery struct {
+	SummarizeFeedbacks []*FeedbackSummary `json:"summarizeFeedbacks"`
+	ListFeedbacks      []*Feedback        `json:"listFeedbacks"`
+}
+
+// FeedbackSummary
+// 应用的评价汇总
+type FeedbackSummary struct {
+	// 应用所在命名空间
+	Namespace string `json:"namespace"`
+	// 应用名称
+	AppName string `json:"appName"`
+	// 评价总数
+	Total int64 `json:"total"`
+	// 点赞数量
+	Likes int64 `json:"likes"`
+	// 点踩数量
+	Dislikes int64 `json:"dislikes"`
+	// 点踩的分类统计，数量多的在前
+	Categories []*FeedbackCategoryCount `json:"categories"`
+}
+
 type FileDetails struct {
 	FileName  string `json:"file_name"`
 	Status    string `json:"status"`
@@ -1099,6 +1191,23 @@ type ListDatasetInput struct {
 	Keyword *string `json:"keyword,omitempty"`
 }
 
+type ListFeedbackInput struct {
+	// 命名空间(必填)
+	Namespace string `json:"namespace"`
+	// 应用名称，不填时返回命名空间下所有应用的评价
+	AppName *string `json:"appName,omitempty"`
+	// 评价，like 或 dislike，不填时不限制
+	Rating *string `json:"rating,omitempty"`
+	// 评价分类，不填时不限制
+	Category *string `json:"category,omitempty"`
+	// 开始日期，格式为 2006-01-02
+	// 规则: 包含当天，不填时不限制
+	StartDate *string `json:"startDate,omitempty"`
+	// 结束日期，格式为 2006-01-02
+	// 规则: 包含当天，不填时不限制
+	EndDate *string `json:"endDate,omitempty"`
+}
+
 type ListGPTInput struct {
 	// category: gpt所属分类
 	// 规则：分类和关键词同时使用时是and的关系；不填时会默认返回推荐分类的结果
@@ -1206,6 +1315,21 @@ type ListRAGInput struct {
 	PageSize *int `json:"pageSize,omitempty"`
 }
 
+type ListTokenUsageInput struct {
+	// 命名空间(必填)
+	Namespace string `json:"namespace"`
+	// 应用名称，不填时返回命名空间下所有应用的用量
+	AppName *string `json:"appName,omitempty"`
+	// 用户，不填时返回所有用户的用量
+	User *string `json:"user,omitempty"`
+	// 开始日期，格式为 2006-01-02
+	// 规则: 包含当天，不填时不限制
+	StartDate *string `json:"startDate,omitempty"`
+	// 结束日期，格式为 2006-01-02
+	// 规则: 包含当天，不填时不限制
+	EndDate *string `json:"endDate,omitempty"`
+}
+
 type ListVersionedDatasetInput struct {
 	Name          *string `json:"name,omitempty"`
 	Namespace     *string `json:"namespace,omitempty"`
@@ -1625,6 +1749,31 @@ type SelectorInput struct {
 	MatchExpressions []*LabelSelectorRequirementInput `json:"matchExpressions,omitempty"`
 }
 
+// TokenUsage
+// 用户在应用中每天的token用量
+type TokenUsage struct {
+	// 日期，格式为 2006-01-02
+	Date string `json:"date"`
+	// 应用所在命名空间
+	Namespace string `json:"namespace"`
+	// 应用名称
+	AppName string `json:"appName"`
+	// 用户
+	User string `json:"user"`
+	// 输入token数
+	PromptTokens int64 `json:"promptTokens"`
+	// 输出token数
+	CompletionTokens int64 `json:"completionTokens"`
+	// 总token数
+	TotalTokens int64 `json:"totalTokens"`
+	// 对话请求数
+	Requests int64 `json:"requests"`
+}
+
+type TokenUsageQuery struct {
+	ListTokenUsages []*TokenUsage `json:"listTokenUsages"`
+}
+
 // Tool 应用和Agent中用到的工具
 type Tool struct {
 	// 名称，需要严格大小写一致，可选项为："Bing Search API","calculator","Weather Query API","Web Scraper"
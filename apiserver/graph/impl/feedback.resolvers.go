@@ -0,0 +1,60 @@
+package impl
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.44
+
+import (
+	"context"
+
+	"github.com/kubeagi/arcadia/apiserver/graph/generated"
+	"github.com/kubeagi/arcadia/apiserver/pkg/feedback"
+)
+
+// Feedback is the resolver for the Feedback field.
+func (r *mutationResolver) Feedback(ctx context.Context) (*generated.FeedbackMutation, error) {
+	return &generated.FeedbackMutation{}, nil
+}
+
+// Feedback is the resolver for the Feedback field.
+func (r *queryResolver) Feedback(ctx context.Context) (*generated.FeedbackQuery, error) {
+	return &generated.FeedbackQuery{}, nil
+}
+
+// ExportFeedbacks is the resolver for the exportFeedbacks field.
+func (r *feedbackMutationResolver) ExportFeedbacks(ctx context.Context, obj *generated.FeedbackMutation, input generated.ExportFeedbackInput) (string, error) {
+	c, err := getClientFromCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	return feedback.ExportFeedbacks(ctx, c, input)
+}
+
+// SummarizeFeedbacks is the resolver for the summarizeFeedbacks field.
+func (r *feedbackQueryResolver) SummarizeFeedbacks(ctx context.Context, obj *generated.FeedbackQuery, input generated.ListFeedbackInput) ([]*generated.FeedbackSummary, error) {
+	c, err := getClientFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return feedback.SummarizeFeedbacks(ctx, c, input)
+}
+
+// ListFeedbacks is the resolver for the listFeedbacks field.
+func (r *feedbackQueryResolver) ListFeedbacks(ctx context.Context, obj *generated.FeedbackQuery, input generated.ListFeedbackInput) ([]*generated.Feedback, error) {
+	c, err := getClientFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return feedback.ListFeedbacks(ctx, c, input)
+}
+
+// FeedbackMutation returns generated.FeedbackMutationResolver implementation.
+func (r *Resolver) FeedbackMutation() generated.FeedbackMutationResolver {
+	return &feedbackMutationResolver{r}
+}
+
+// FeedbackQuery returns generated.FeedbackQueryResolver implementation.
+func (r *Resolver) FeedbackQuery() generated.FeedbackQueryResolver { return &feedbackQueryResolver{r} }
+
+type feedbackMutationResolver struct{ *Resolver }
+type feedbackQueryResolver struct{ *Resolver }
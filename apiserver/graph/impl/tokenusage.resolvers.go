@@ -0,0 +1,33 @@
+package impl
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.44
+
+import (
+	"context"
+
+	"github.com/kubeagi/arcadia/apiserver/graph/generated"
+	"github.com/kubeagi/arcadia/apiserver/pkg/tokenusage"
+)
+
+// TokenUsage is the resolver for the TokenUsage field.
+func (r *queryResolver) TokenUsage(ctx context.Context) (*generated.TokenUsageQuery, error) {
+	return &generated.TokenUsageQuery{}, nil
+}
+
+// ListTokenUsages is the resolver for the listTokenUsages field.
+func (r *tokenUsageQueryResolver) ListTokenUsages(ctx context.Context, obj *generated.TokenUsageQuery, input generated.ListTokenUsageInput) ([]*generated.TokenUsage, error) {
+	c, err := getClientFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tokenusage.ListTokenUsages(ctx, c, input)
+}
+
+// TokenUsageQuery returns generated.TokenUsageQueryResolver implementation.
+func (r *Resolver) TokenUsageQuery() generated.TokenUsageQueryResolver {
+	return &tokenUsageQueryResolver{r}
+}
+
+type tokenUsageQueryResolver struct{ *Resolver }
@@ -0,0 +1,189 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+const (
+	// APIKeyLabel marks a secret as an api key, the key is stored in the `apiKey` field of the secret.
+	// An api key can only access the applications in the namespace of the secret.
+	APIKeyLabel = v1alpha1.Group + "/api-key"
+	// APIKeyUserAnnotation is the user of the api key, used for conversations and quotas. Default to the secret name.
+	APIKeyUserAnnotation = v1alpha1.Group + "/api-key-user"
+	// APIKeyApplicationsAnnotation limits the api key to the comma separated applications. Empty means all applications in the namespace.
+	APIKeyApplicationsAnnotation = v1alpha1.Group + "/api-key-applications"
+
+	apiKeyDataKey = "apiKey"
+	// apiKeyHashIndex indexes the api key secrets by the sha256 of their keys
+	apiKeyHashIndex = "apiKeyHash"
+	apiKeyResync    = 10 * time.Minute
+
+	APIKeyScopeContextKey contextKey = "apiKeyScope"
+)
+
+var (
+	ErrInvalidAPIKey    = errors.New("invalid api key")
+	errAPIKeysNotSynced = errors.New("api keys are not loaded yet")
+)
+
+// APIKeyScope is the applications which an api key can access
+type APIKeyScope struct {
+	Namespace    string
+	Applications []string
+}
+
+// CanAccess returns true if the application is in the scope
+func (s *APIKeyScope) CanAccess(namespace, name string) bool {
+	if s == nil {
+		return true
+	}
+	if namespace != s.Namespace {
+		return false
+	}
+	if len(s.Applications) == 0 {
+		return true
+	}
+	for _, app := range s.Applications {
+		if app == name {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyScopeFromContext returns the scope of the api key in the request, nil means no limit
+func APIKeyScopeFromContext(ctx context.Context) *APIKeyScope {
+	scope, _ := ctx.Value(APIKeyScopeContextKey).(*APIKeyScope)
+	return scope
+}
+
+// APIKeyStore finds api keys in the secrets with APIKeyLabel. The secrets are cached by an informer
+// and indexed by the hash of their keys, so a request doesn't list secrets from the kube-apiserver.
+type APIKeyStore struct {
+	indexer   cache.Indexer
+	hasSynced cache.InformerSynced
+}
+
+// NewAPIKeyStore starts an informer of the api key secrets, which stops when ctx is done.
+// Lookups fail until the secrets are loaded.
+func NewAPIKeyStore(ctx context.Context, clientset kubernetes.Interface) (*APIKeyStore, error) {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, apiKeyResync, informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+		options.LabelSelector = APIKeyLabel
+	}))
+	informer := factory.Core().V1().Secrets().Informer()
+	if err := informer.AddIndexers(cache.Indexers{apiKeyHashIndex: apiKeyHashIndexFunc}); err != nil {
+		return nil, err
+	}
+	factory.Start(ctx.Done())
+	return &APIKeyStore{indexer: informer.GetIndexer(), hasSynced: informer.HasSynced}, nil
+}
+
+func apiKeyHash(apiKey []byte) string {
+	sum := sha256.Sum256(apiKey)
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyHashIndexFunc(obj interface{}) ([]string, error) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok || len(secret.Data[apiKeyDataKey]) == 0 {
+		return nil, nil
+	}
+	return []string{apiKeyHash(secret.Data[apiKeyDataKey])}, nil
+}
+
+// Lookup finds the secret of the api key and returns its user and scope
+func (s *APIKeyStore) Lookup(apiKey string) (string, *APIKeyScope, error) {
+	if !s.hasSynced() {
+		return "", nil, errAPIKeysNotSynced
+	}
+	objs, err := s.indexer.ByIndex(apiKeyHashIndex, apiKeyHash([]byte(apiKey)))
+	if err != nil {
+		return "", nil, err
+	}
+	for _, obj := range objs {
+		secret := obj.(*corev1.Secret)
+		if subtle.ConstantTimeCompare(secret.Data[apiKeyDataKey], []byte(apiKey)) != 1 {
+			continue
+		}
+		user := secret.Annotations[APIKeyUserAnnotation]
+		if user == "" {
+			user = secret.Name
+		}
+		scope := &APIKeyScope{Namespace: secret.Namespace}
+		for _, app := range strings.Split(secret.Annotations[APIKeyApplicationsAnnotation], ",") {
+			if app = strings.TrimSpace(app); app != "" {
+				scope.Applications = append(scope.Applications, app)
+			}
+		}
+		return user, scope, nil
+	}
+	return "", nil, ErrInvalidAPIKey
+}
+
+// APIKeyInterceptor authenticates the request by the api key in the bearer token, like the OpenAI apis.
+// The user and the scope of the api key are added to the context. If needAuth is false, requests without api key are allowed.
+func APIKeyInterceptor(needAuth bool, keys *APIKeyStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		apiKey, ok := isBearerToken(ctx.GetHeader("Authorization"))
+		if !ok {
+			if !needAuth {
+				ctx.Next()
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, openAIError("unauthorized, api key is required", "invalid_request_error"))
+			return
+		}
+		user, scope, err := keys.Lookup(apiKey)
+		if err != nil {
+			if errors.Is(err, ErrInvalidAPIKey) {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, openAIError("incorrect api key provided", "invalid_request_error"))
+				return
+			}
+			klog.Errorf("auth error: failed to find api key, error %s", err)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, openAIError(err.Error(), "server_error"))
+			return
+		}
+		reqCtx := context.WithValue(ctx.Request.Context(), UserNameContextKey, user)
+		reqCtx = context.WithValue(reqCtx, APIKeyScopeContextKey, scope)
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+		ctx.Next()
+	}
+}
+
+// openAIError is the error response in OpenAI format, so OpenAI SDKs can show the message
+func openAIError(message, errType string) gin.H {
+	return gin.H{"error": gin.H{"message": message, "type": errType}}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func apiKeySecret(namespace, name, key string, annotations map[string]string, labeled bool) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: annotations},
+		Data:       map[string][]byte{apiKeyDataKey: []byte(key)},
+	}
+	if labeled {
+		secret.Labels = map[string]string{APIKeyLabel: "true"}
+	}
+	return secret
+}
+
+func newTestAPIKeyStore(t *testing.T, objects ...*corev1.Secret) (*APIKeyStore, *fake.Clientset) {
+	clientset := fake.NewSimpleClientset()
+	for _, obj := range objects {
+		if _, err := clientset.CoreV1().Secrets(obj.Namespace).Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	store, err := NewAPIKeyStore(ctx, clientset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cache.WaitForCacheSync(ctx.Done(), store.hasSynced) {
+		t.Fatal("api keys are not synced")
+	}
+	return store, clientset
+}
+
+func TestAPIKeyStoreLookup(t *testing.T) {
+	store, _ := newTestAPIKeyStore(t,
+		apiKeySecret("arcadia", "key-a", "sk-a", nil, true),
+		apiKeySecret("team", "key-b", "sk-b", map[string]string{
+			APIKeyUserAnnotation:         "bob",
+			APIKeyApplicationsAnnotation: "chat, search,",
+		}, true),
+		apiKeySecret("arcadia", "not-api-key", "sk-c", nil, false),
+	)
+	tests := []struct {
+		name  string
+		key   string
+		user  string
+		scope *APIKeyScope
+		err   error
+	}{
+		{name: "user defaults to the secret name", key: "sk-a", user: "key-a", scope: &APIKeyScope{Namespace: "arcadia"}},
+		{name: "user and applications from annotations", key: "sk-b", user: "bob", scope: &APIKeyScope{Namespace: "team", Applications: []string{"chat", "search"}}},
+		{name: "secret without label", key: "sk-c", err: ErrInvalidAPIKey},
+		{name: "unknown key", key: "sk-d", err: ErrInvalidAPIKey},
+		{name: "empty key", key: "", err: ErrInvalidAPIKey},
+	}
+	for _, test := range tests {
+		user, scope, err := store.Lookup(test.key)
+		if !errors.Is(err, test.err) || user != test.user || !reflect.DeepEqual(scope, test.scope) {
+			t.Errorf("%s: expect %q %+v %v, got %q %+v %v", test.name, test.user, test.scope, test.err, user, scope, err)
+		}
+	}
+}
+
+func TestAPIKeyStoreWatch(t *testing.T) {
+	store, clientset := newTestAPIKeyStore(t, apiKeySecret("arcadia", "key-a", "sk-a", nil, true))
+	ctx := context.Background()
+	waitFor := func(key string, found bool) {
+		t.Helper()
+		err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			_, _, err := store.Lookup(key)
+			return (err == nil) == found, nil
+		})
+		if err != nil {
+			t.Fatalf("expect key %s found %v", key, found)
+		}
+	}
+
+	// a rotated key replaces the old one
+	if _, err := clientset.CoreV1().Secrets("arcadia").Update(ctx, apiKeySecret("arcadia", "key-a", "sk-a2", nil, true), metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("sk-a2", true)
+	waitFor("sk-a", false)
+
+	// a deleted key is revoked
+	if err := clientset.CoreV1().Secrets("arcadia").Delete(ctx, "key-a", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("sk-a2", false)
+}
+
+func TestAPIKeyStoreNotSynced(t *testing.T) {
+	store := &APIKeyStore{indexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{apiKeyHashIndex: apiKeyHashIndexFunc}), hasSynced: func() bool { return false }}
+	if _, _, err := store.Lookup("sk-a"); !errors.Is(err, errAPIKeysNotSynced) {
+		t.Errorf("expect not synced, got %v", err)
+	}
+}
+
+func TestAPIKeyInterceptor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store, _ := newTestAPIKeyStore(t, apiKeySecret("arcadia", "key-a", "sk-a", nil, true))
+	tests := []struct {
+		name          string
+		needAuth      bool
+		authorization string
+		status        int
+		user          string
+	}{
+		{name: "valid key", needAuth: true, authorization: "Bearer sk-a", status: http.StatusOK, user: "key-a"},
+		{name: "invalid key", needAuth: true, authorization: "Bearer sk-b", status: http.StatusUnauthorized},
+		{name: "no key", needAuth: true, status: http.StatusUnauthorized},
+		{name: "no key without auth", needAuth: false, status: http.StatusOK},
+	}
+	for _, test := range tests {
+		var user string
+		r := gin.New()
+		r.GET("/models", APIKeyInterceptor(test.needAuth, store), func(c *gin.Context) {
+			user, _ = c.Request.Context().Value(UserNameContextKey).(string)
+			c.Status(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/models", nil)
+		if test.authorization != "" {
+			req.Header.Set("Authorization", test.authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != test.status || user != test.user {
+			t.Errorf("%s: expect %d %q, got %d %q", test.name, test.status, test.user, w.Code, user)
+		}
+	}
+}
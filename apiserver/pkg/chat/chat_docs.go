@@ -39,16 +39,21 @@ import (
 	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
 	pkgclient "github.com/kubeagi/arcadia/apiserver/pkg/client"
 	pkgconfig "github.com/kubeagi/arcadia/pkg/config"
+	"github.com/kubeagi/arcadia/pkg/datasource"
 )
 
-// ReceiveConversationDocs receive and process docs for a conversation
-func (cs *ChatServer) ReceiveConversationFile(ctx context.Context, messageID string, req ConversationFilesReqBody, file *multipart.FileHeader) (*ChatRespBody, error) {
+// ReceiveConversationFile receives and processes the files for a conversation
+func (cs *ChatServer) ReceiveConversationFile(ctx context.Context, messageID string, req ConversationFilesReqBody, files []*multipart.FileHeader) (*ChatRespBody, error) {
 	if messageID == "" {
 		messageID = string(uuid.NewUUID())
 	}
 
+	app, err := cs.GetApp(ctx, req.APPName, req.AppNamespace)
+	if err != nil {
+		return nil, err
+	}
 	var conversation *storage.Conversation
-	var err error
+	existing := 0
 	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
 	if !req.NewChat {
 		search := []storage.SearchOption{
@@ -62,7 +67,17 @@ func (cs *ChatServer) ReceiveConversationFile(ctx context.Context, messageID str
 		if err != nil {
 			return nil, err
 		}
-	} else {
+		documents, err := cs.Storage().ListDocuments(conversation.ID)
+		if err != nil {
+			return nil, err
+		}
+		existing = len(documents)
+	}
+	// check all the files before uploading any of them
+	if err := fileLimits(app).check(existing, files); err != nil {
+		return nil, err
+	}
+	if req.NewChat {
 		conversation = &storage.Conversation{
 			ID:           req.ConversationID,
 			AppName:      req.APPName,
@@ -85,47 +100,26 @@ func (cs *ChatServer) ReceiveConversationFile(ctx context.Context, messageID str
 		return nil, fmt.Errorf("no storage service found with err %s", err.Error())
 	}
 
-	src, err := file.Open()
-	if err != nil {
-		return nil, err
-	}
-	defer src.Close()
-	data, err := io.ReadAll(src)
-	if err != nil {
-		return nil, err
-	}
-	// use sha256 as the object name so we can avoid overwrite files with same name but different content
-	hash := sha256.Sum256(data)
-	objectName := hex.EncodeToString(hash[:])
-	objectPath := arcadiav1alpha1.ConversationFilePath(req.APPName, req.ConversationID, fmt.Sprintf("%s%s", objectName, filepath.Ext(file.Filename)))
-	_, err = ds.Client.PutObject(
-		ctx, req.AppNamespace,
-		objectPath,
-		bytes.NewReader(data),
-		int64(len(data)),
-		minio.PutObjectOptions{
-			// UserTags: map[string]string{
-			// 	"FILE_NAME": file.Filename,
-			// },
+	documents := make([]storage.Document, 0, len(files))
+	for _, file := range files {
+		objectPath, err := uploadConversationFile(ctx, ds, req, file)
+		if err != nil {
+			klog.Errorf("failed to store file %s with error %s", file.Filename, err.Error())
+			return nil, fmt.Errorf("failed to store file %s with error %s", file.Filename, err.Error())
+		}
+		documents = append(documents, storage.Document{
+			ID:             string(uuid.NewUUID()),
+			MessageID:      messageID,
+			ConversationID: req.ConversationID,
+			Name:           file.Filename,
+			Object:         objectPath,
 		})
-	if err != nil {
-		klog.Errorf("failed to store file %s with error %s", file.Filename, err.Error())
-		return nil, fmt.Errorf("failed to store file %s with error %s", file.Filename, err.Error())
-	}
-
-	document := storage.Document{
-		ID:             string(uuid.NewUUID()),
-		MessageID:      messageID,
-		ConversationID: req.ConversationID,
-		Name:           file.Filename,
-		Object:         objectPath,
 	}
 
 	// build/update conversation knowledgebase
-	err = cs.BuildConversationKnowledgeBase(ctx, req, document)
-	if err != nil {
-		// only log error
+	if err = cs.BuildConversationKnowledgeBase(ctx, req, documents...); err != nil {
 		klog.Errorf("failed to build conversation knowledgebase %s with error %s", req.ConversationID, err.Error())
+		return nil, fmt.Errorf("failed to build conversation knowledgebase with error %s", err.Error())
 	}
 
 	// process document with map-reduce
@@ -135,7 +129,7 @@ func (cs *ChatServer) ReceiveConversationFile(ctx context.Context, messageID str
 		Query:     "UPLOAD",
 		Answer:    "DONE",
 		Latency:   int64(time.Since(req.StartTime).Milliseconds()),
-		Documents: []storage.Document{document},
+		Documents: documents,
 	}
 
 	// update conversat ion
@@ -153,25 +147,61 @@ func (cs *ChatServer) ReceiveConversationFile(ctx context.Context, messageID str
 		}
 	}
 
-	return &ChatRespBody{
+	resp := &ChatRespBody{
 		ConversationID: req.ConversationID,
 		CreatedAt:      time.Now(),
 		MessageID:      messageID,
 		Action:         "UPLOAD",
 		Message:        "Done",
 		Latency:        message.Latency,
-		Document: DocumentRespBody{
+		Documents:      make([]DocumentRespBody, 0, len(documents)),
+	}
+	for _, document := range documents {
+		resp.Documents = append(resp.Documents, DocumentRespBody{
 			ID:     document.ID,
 			Name:   document.Name,
 			Object: document.Object,
-		},
-	}, nil
+		})
+	}
+	resp.Document = resp.Documents[0]
+	return resp, nil
+}
+
+// uploadConversationFile stores the file to the system datasource and returns its object path
+func uploadConversationFile(ctx context.Context, ds *datasource.OSS, req ConversationFilesReqBody, file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return "", err
+	}
+	// use sha256 as the object name so we can avoid overwrite files with same name but different content
+	hash := sha256.Sum256(data)
+	objectName := hex.EncodeToString(hash[:])
+	objectPath := arcadiav1alpha1.ConversationFilePath(req.APPName, req.ConversationID, fmt.Sprintf("%s%s", objectName, filepath.Ext(file.Filename)))
+	_, err = ds.Client.PutObject(
+		ctx, req.AppNamespace,
+		objectPath,
+		bytes.NewReader(data),
+		int64(len(data)),
+		minio.PutObjectOptions{
+			// UserTags: map[string]string{
+			// 	"FILE_NAME": file.Filename,
+			// },
+		})
+	if err != nil {
+		return "", err
+	}
+	return objectPath, nil
 }
 
 // BuildConversationKnowledgeBase create/updates knowledgebase for this conversation.
-// Conversation ID will be the knowledgebase name and document will be placed unde filegroup
+// Conversation ID will be the knowledgebase name and documents will be placed unde filegroup, the documents already in it are skipped
 // Knoweledgebase will embed the document into vectorstore which can be used in this conversation as references(similarity search)
-func (cs *ChatServer) BuildConversationKnowledgeBase(ctx context.Context, req ConversationFilesReqBody, document storage.Document) error {
+func (cs *ChatServer) BuildConversationKnowledgeBase(ctx context.Context, req ConversationFilesReqBody, documents ...storage.Document) error {
 	// get system embedding suite
 	embedder, vs, err := pkgconfig.GetSystemEmbeddingSuite(ctx)
 	if err != nil {
@@ -214,7 +244,24 @@ func (cs *ChatServer) BuildConversationKnowledgeBase(ctx context.Context, req Co
 		if err := controllerutil.SetControllerReference(app, kb, pkgclient.Scheme); err != nil {
 			return err
 		}
-		// append document path
+		// append the document paths not in the knowledgebase yet
+		paths := make(map[string]bool)
+		for _, group := range kb.Spec.FileGroups {
+			for _, file := range group.Files {
+				paths[file.Path] = true
+			}
+		}
+		files := make([]arcadiav1alpha1.FileWithVersion, 0, len(documents))
+		for _, document := range documents {
+			if paths[document.Object] {
+				continue
+			}
+			paths[document.Object] = true
+			files = append(files, arcadiav1alpha1.FileWithVersion{Path: document.Object})
+		}
+		if len(files) == 0 {
+			return nil
+		}
 		kb.Spec.FileGroups = append(kb.Spec.FileGroups, arcadiav1alpha1.FileGroup{
 			Source: &arcadiav1alpha1.TypedObjectReference{
 				APIGroup:  &arcadiav1alpha1.GroupVersion.Group,
@@ -222,7 +269,7 @@ func (cs *ChatServer) BuildConversationKnowledgeBase(ctx context.Context, req Co
 				Name:      systemDatasource.Name,
 				Namespace: &systemDatasource.Namespace,
 			},
-			Files: []arcadiav1alpha1.FileWithVersion{{Path: document.Object}},
+			Files: files,
 		})
 		return nil
 	})
@@ -29,6 +29,7 @@ import (
 	langchainllms "github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/memory"
 	"github.com/tmc/langchaingo/prompts"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
 	"golang.org/x/sync/errgroup"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
@@ -47,8 +48,8 @@ import (
 	"github.com/kubeagi/arcadia/pkg/appruntime/llm"
 	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
 	pkgconfig "github.com/kubeagi/arcadia/pkg/config"
-	"github.com/kubeagi/arcadia/pkg/datasource"
 	"github.com/kubeagi/arcadia/pkg/documentloaders"
+	"github.com/kubeagi/arcadia/pkg/tokenusage"
 )
 
 type ChatServer struct {
@@ -65,40 +66,17 @@ func NewChatServer(cli runtimeclient.Client, isGpts bool) *ChatServer {
 	}
 }
 
+// NewChatServerWithStorage creates a chat server which keeps the conversations in the given storage
+func NewChatServerWithStorage(cli runtimeclient.Client, isGpts bool, s storage.Storage) *ChatServer {
+	cs := NewChatServer(cli, isGpts)
+	cs.storage = s
+	return cs
+}
+
 func (cs *ChatServer) Storage() storage.Storage {
 	if cs.storage == nil {
 		cs.once.Do(func() {
-			ctx := context.TODO()
-			ds, err := pkgconfig.GetRelationalDatasource(ctx)
-			if err != nil || ds == nil {
-				if err != nil {
-					klog.Infof("get relational datasource failed: %s, use memory storage for chat", err.Error())
-				} else if ds == nil {
-					klog.Infoln("no relational datasource found, use memory storage for chat")
-				}
-				cs.storage = storage.NewMemoryStorage()
-				return
-			}
-			pg, err := datasource.GetPostgreSQLPool(ctx, cs.systemCli, ds)
-			if err != nil {
-				klog.Errorf("get postgresql pool failed : %s", err.Error())
-				cs.storage = storage.NewMemoryStorage()
-				return
-			}
-			conn, err := pg.Pool.Acquire(ctx)
-			if err != nil {
-				klog.Errorf("postgresql pool acquire failed : %s", err.Error())
-				cs.storage = storage.NewMemoryStorage()
-				return
-			}
-			db, err := storage.NewPostgreSQLStorage(conn.Conn())
-			if err != nil {
-				klog.Errorf("storage.NewPostgreSQLStorage failed : %s", err.Error())
-				cs.storage = storage.NewMemoryStorage()
-				return
-			}
-			klog.Infoln("use pg as chat storage.")
-			cs.storage = db
+			cs.storage = storage.Default(context.TODO(), cs.systemCli)
 		})
 	}
 	return cs.storage
@@ -111,8 +89,19 @@ func (cs *ChatServer) AppRun(ctx context.Context, req ChatReqBody, respStream ch
 	}
 	*timeout = app.Spec.ChatTimeoutSecond
 	var conversation *storage.Conversation
-	history := memory.NewChatMessageHistory()
 	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	if err := cs.CheckQuota(app, currentUser); err != nil {
+		return nil, err
+	}
+	images, err := parseImages(req.Images)
+	if err != nil {
+		return nil, err
+	}
+	// index of the message to answer in the conversation
+	current := -1
+	// number of the messages in the summary used in the history, -1 if the summary can't be kept
+	summarized := -1
+	rounds := make([]langchaingoschema.ChatMessage, 0)
 	if !req.NewChat {
 		search := []storage.SearchOption{
 			storage.WithAppName(req.APPName),
@@ -126,10 +115,15 @@ func (cs *ChatServer) AppRun(ctx context.Context, req ChatReqBody, respStream ch
 		if err != nil {
 			return nil, err
 		}
-		for _, v := range conversation.Messages {
-			_ = history.AddUserMessage(ctx, v.Query)
-			_ = history.AddAIMessage(ctx, v.Answer)
+		end := len(conversation.Messages)
+		if req.Regenerate {
+			if current = messageIndex(conversation, messageID); current < 0 {
+				return nil, storage.ErrMessageNotFound
+			}
+			// only the messages before the regenerated one are the history
+			end = current
 		}
+		rounds, summarized = historyRounds(conversation, end)
 	} else {
 		conversation = &storage.Conversation{
 			ID:           req.ConversationID,
@@ -144,59 +138,203 @@ func (cs *ChatServer) AppRun(ctx context.Context, req ChatReqBody, respStream ch
 		if err := cs.Storage().UpdateConversation(conversation); err != nil {
 			return nil, err
 		}
+		rounds = append(rounds, req.History...)
+	}
+	if req.Regenerate {
+		// ask the same question with the same files again
+		req.Query = conversation.Messages[current].Query
+		req.Files = nil
+		if raw := conversation.Messages[current].RawFiles; raw != "" {
+			req.Files = strings.Split(raw, ",")
+		}
+		if saved := conversation.Messages[current].Images; len(saved) > 0 {
+			if images, err = cs.loadImages(ctx, conversation, saved); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		var saved storage.Images
+		if len(images) > 0 {
+			if saved, err = cs.saveImages(ctx, conversation, images); err != nil {
+				return nil, err
+			}
+		}
+		conversation.Messages = append(conversation.Messages, storage.Message{
+			ID:     messageID,
+			Action: "CHAT",
+			Query:  req.Query,
+			Answer: "",
+			Images: saved,
+		})
+		current = len(conversation.Messages) - 1
+	}
+	// debug chats are never handed off
+	handoff := app.Spec.Handoff
+	if !req.Debug && (conversation.PendingHuman || (handoff != nil && !req.Regenerate && matchHandoffKeyword(handoff, req.Query))) {
+		return cs.waitForHuman(ctx, app, conversation, &conversation.Messages[current], req)
+	}
+	recorder := tokenusage.NewRecorder()
+	summaryRecorder := appruntimechain.NewSummaryRecorder()
+	runCtx := appruntimechain.NewSummaryContext(tokenusage.NewContext(ctx, recorder), summaryRecorder)
+	history := memory.NewChatMessageHistory()
+	var ltm *longTermMemory
+	if memoryConfig := cs.appMemory(ctx, app); memoryConfig != nil && memoryConfig.LongTerm != nil && currentUser != "" {
+		// the long-term memory is try our best, the app runs without it if failed
+		if ltm, err = cs.newLongTermMemory(ctx, app, memoryConfig.LongTerm); err != nil {
+			klog.FromContext(ctx).Error(err, "failed to init the long-term memory")
+		} else if facts, err := cs.recallUserFacts(runCtx, ltm, currentUser, req.Query); err != nil {
+			klog.FromContext(ctx).Error(err, "failed to recall the facts about the user")
+		} else if len(facts) > 0 {
+			_ = history.AddMessage(ctx, langchaingoschema.SystemChatMessage{Content: factsContent(facts)})
+		}
+	}
+	for _, m := range rounds {
+		_ = history.AddMessage(ctx, m)
 	}
-	conversation.Messages = append(conversation.Messages, storage.Message{
-		ID:     messageID,
-		Action: "CHAT",
-		Query:  req.Query,
-		Answer: "",
-	})
 	// since authenticattion already passed by http handler,we should use chatserver's client which is also the system client to new/ini appruntime
 	appRun, err := appruntime.NewAppOrGetFromCache(ctx, cs.systemCli, app)
 	if err != nil {
 		return nil, err
 	}
-	klog.FromContext(ctx).Info("begin to run application", "appName", req.APPName, "appNamespace", req.AppNamespace)
-	out, err := appRun.Run(ctx, cs.systemCli, respStream, appruntime.Input{Question: req.Query, Files: req.Files, NeedStream: req.ResponseMode.IsStreaming(), History: history, ConversationID: req.ConversationID})
+	klog.FromContext(ctx).Info("begin to run application", "appName", req.APPName, "appNamespace", req.AppNamespace, "regenerate", req.Regenerate)
+	out, err := appRun.Run(runCtx, cs.systemCli, respStream, appruntime.Input{Question: req.Query, Files: req.Files, Images: images, NeedStream: req.ResponseMode.IsStreaming(), History: history, ConversationID: req.ConversationID, NoCache: req.Regenerate})
 	if err != nil {
+		// the tokens are used even if the run failed
+		cs.recordTokenUsage(ctx, req.APPMetadata, currentUser, recorder.Total(), 1)
 		return nil, err
 	}
+	cs.recordViolations(ctx, req, currentUser, messageID, out.Violations)
+	// the question or the answer found by the guardrails is not sent to the llms for the suggestions, title and memory
+	guarded := len(out.Violations) > 0
 
 	conversation.UpdatedAt = req.StartTime
-	conversation.Messages[len(conversation.Messages)-1].Answer = out.Answer
-	conversation.Messages[len(conversation.Messages)-1].References = out.References
-	conversation.Messages[len(conversation.Messages)-1].Latency = time.Since(req.StartTime).Milliseconds()
-	if req.Files != nil && len(req.Files) > 0 {
-		conversation.Messages[len(conversation.Messages)-1].RawFiles = strings.Join(req.Files, ",")
+	message := &conversation.Messages[current]
+	if req.Regenerate {
+		message.AddAlternate()
+	}
+	message.Answer = out.Answer
+	message.References = out.References
+	message.Latency = time.Since(req.StartTime).Milliseconds()
+	if !req.Regenerate && req.Files != nil && len(req.Files) > 0 {
+		message.RawFiles = strings.Join(req.Files, ",")
+	}
+	notice := ""
+	if handoff != nil && !req.Debug {
+		if reason := handoffReason(handoff, out); reason != "" {
+			if _, err := cs.handOff(ctx, app, conversation, messageID, req.Query, reason); err != nil {
+				// the answer is kept even if the conversation fails to be handed off
+				klog.FromContext(ctx).Error(err, "failed to hand off the conversation", "reason", reason)
+			} else {
+				notice = handoffMessage(handoff)
+			}
+		}
+	}
+	message.SuggestedQuestions = nil
+	if app.Spec.ShowNextGuide && !req.NoSuggestions && !conversation.PendingHuman && !guarded {
+		message.SuggestedQuestions = cs.suggestQuestions(runCtx, app, rounds, req.Query, out.Answer, out.References)
 	}
+	// the usage includes the tokens to suggest the questions
+	total := recorder.Total()
+	message.Usage = recorder.Usages()
+	cs.recordTokenUsage(ctx, req.APPMetadata, currentUser, total, 1)
 
+	if req.Regenerate {
+		message.ConversationID = conversation.ID
+		if err := cs.Storage().UpdateMessage(message); err != nil {
+			return nil, err
+		}
+	}
+	if summary, messages, ok := summaryRecorder.Summary(); ok && summarized >= 0 {
+		conversation.Summary = summary
+		conversation.SummarizedMessages = summarized + messages/2
+	}
+	// name the conversation after the first turn
+	generateTitle := conversation.Title == "" && !req.Debug
+	if generateTitle {
+		conversation.Title = defaultTitle(req.Query)
+	}
 	if err := cs.Storage().UpdateConversation(conversation); err != nil {
 		return nil, err
 	}
+	if generateTitle && !guarded {
+		go cs.generateTitle(app, currentUser, conversation.ID, req.Query, out.Answer)
+	}
+	if ltm != nil && !req.Debug && !guarded {
+		go cs.rememberUserFacts(app, ltm, currentUser, conversation.ID, req.Query, out.Answer)
+	}
 	return &ChatRespBody{
-		ConversationID: conversation.ID,
-		MessageID:      messageID,
-		Action:         "CHAT",
-		Message:        out.Answer,
-		CreatedAt:      time.Now(),
-		References:     out.References,
+		ConversationID:     conversation.ID,
+		MessageID:          messageID,
+		Action:             "CHAT",
+		Message:            out.Answer,
+		CreatedAt:          time.Now(),
+		References:         out.References,
+		Usage:              &total,
+		Version:            message.Version(),
+		SuggestedQuestions: message.SuggestedQuestions,
+		PendingHuman:       conversation.PendingHuman,
+		Handoff:            notice,
 	}, nil
 }
 
-func (cs *ChatServer) ListConversations(ctx context.Context, req APPMetadata) ([]storage.Conversation, error) {
+// messageIndex returns the index of the message in the conversation, -1 if not found
+func messageIndex(conversation *storage.Conversation, messageID string) int {
+	for i, v := range conversation.Messages {
+		if v.ID == messageID {
+			return i
+		}
+	}
+	return -1
+}
+
+// historyRounds returns the chat history of the messages before end, the older messages are replaced by their summary.
+// summarized is the number of messages in the summary used in the history,
+// -1 if the summary can't be kept because it includes messages after end.
+func historyRounds(conversation *storage.Conversation, end int) (rounds []langchaingoschema.ChatMessage, summarized int) {
+	rounds = make([]langchaingoschema.ChatMessage, 0)
+	summarized = -1
+	start := 0
+	if conversation.SummarizedMessages <= end {
+		if conversation.Summary != "" {
+			rounds = append(rounds, appruntimechain.SummaryChatMessage{Content: conversation.Summary})
+			start = conversation.SummarizedMessages
+		}
+		summarized = start
+	}
+	for _, v := range conversation.Messages[start:end] {
+		if v.Action == ActionHuman {
+			// the replies of human operators have no query
+			rounds = append(rounds, langchaingoschema.AIChatMessage{Content: v.Answer})
+			continue
+		}
+		rounds = append(rounds, langchaingoschema.HumanChatMessage{Content: v.Query}, langchaingoschema.AIChatMessage{Content: v.Answer})
+	}
+	return rounds, summarized
+}
+
+func (cs *ChatServer) ListConversations(ctx context.Context, req ListConversationReqBody) ([]storage.Conversation, error) {
 	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
-	return cs.Storage().ListConversations(storage.WithAppNamespace(req.AppNamespace), storage.WithAppName(req.APPName), storage.WithUser(currentUser))
+	return cs.Storage().ListConversations(storage.WithAppNamespace(req.AppNamespace), storage.WithAppName(req.APPName), storage.WithUser(currentUser),
+		storage.WithKeyword(req.Keyword), storage.WithPagination(req.Page, req.PageSize))
 }
 
 func (cs *ChatServer) DeleteConversation(ctx context.Context, conversationID string) error {
 	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
-	// Note: in pg table, this data is marked as deleted, deleted_at column is not null. the pdf in minio is not deleted. we only delete the conversation knowledgebase.
+	// find the conversation first, its files in minio are removed after it is deleted
+	conversation, findErr := cs.Storage().FindExistingConversation(conversationID, storage.WithUser(currentUser))
+	// Note: in pg table, this data is marked as deleted, deleted_at column is not null. the files and images in minio are removed along with the conversation knowledgebase.
 	// delete conversation knowledgebase if it exists
 	// when delete is successful, it means currentuser is the creator of this conversation
 	err := cs.Storage().Delete(storage.WithConversationID(conversationID), storage.WithUser(currentUser))
 	if err != nil {
 		return err
 	}
+	if findErr == nil {
+		if err = cs.removeConversationObjects(ctx, conversation); err != nil {
+			// note: remove the files is try our best, they are not accessible after the conversation is deleted
+			klog.FromContext(ctx).Error(err, "conversation deleted but its files failed to remove", "conversationID", conversationID)
+		}
+	}
 	kbList := &v1alpha1.KnowledgeBaseList{}
 	if err = runtimeclient.IgnoreNotFound(cs.systemCli.List(ctx, kbList, runtimeclient.MatchingFields(map[string]string{"metadata.name": conversationID}))); err != nil {
 		return err
@@ -219,11 +357,75 @@ func (cs *ChatServer) ListMessages(ctx context.Context, req ConversationReqBody)
 		return storage.Conversation{}, err
 	}
 	if c != nil {
+		if err := cs.fillFeedbacks(c); err != nil {
+			// note: fill feedbacks is try our best, don't need to return error
+			klog.FromContext(ctx).Error(err, "error fill feedbacks to messages")
+		}
 		return *c, nil
 	}
 	return storage.Conversation{}, errors.New("conversation is not found")
 }
 
+// fillFeedbacks adds the feedbacks to the answers of the messages and their alternates
+func (cs *ChatServer) fillFeedbacks(c *storage.Conversation) error {
+	feedbacks, err := cs.Storage().ListFeedbacks(storage.WithConversationID(c.ID))
+	if err != nil {
+		return err
+	}
+	if len(feedbacks) == 0 {
+		return nil
+	}
+	type key struct {
+		messageID string
+		version   int
+	}
+	byVersion := make(map[key]*storage.Feedback, len(feedbacks))
+	for i := range feedbacks {
+		byVersion[key{feedbacks[i].MessageID, feedbacks[i].Version}] = &feedbacks[i]
+	}
+	for i := range c.Messages {
+		m := &c.Messages[i]
+		m.Feedback = byVersion[key{m.ID, m.Version()}]
+		for version := range m.Alternates {
+			m.Alternates[version].Feedback = byVersion[key{m.ID, version}]
+		}
+	}
+	return nil
+}
+
+// SetFeedback rates the current answer of the message, an empty rating removes the feedback
+func (cs *ChatServer) SetFeedback(ctx context.Context, req FeedbackReqBody) (*storage.Feedback, error) {
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	m, err := cs.Storage().FindExistingMessage(req.ConversationID, req.MessageID, storage.WithAppNamespace(req.AppNamespace), storage.WithAppName(req.APPName), storage.WithUser(currentUser))
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, storage.ErrMessageNotFound
+	}
+	if req.Rating == "" {
+		return nil, cs.Storage().DeleteFeedback(m.ID, m.Version())
+	}
+	feedback := &storage.Feedback{
+		MessageID:      m.ID,
+		Version:        m.Version(),
+		ConversationID: req.ConversationID,
+		AppName:        req.APPName,
+		AppNamespace:   req.AppNamespace,
+		User:           currentUser,
+		Rating:         req.Rating,
+		Category:       req.Category,
+		Comment:        req.Comment,
+		ExpectedAnswer: req.ExpectedAnswer,
+		Query:          m.Query,
+		Answer:         m.Answer,
+	}
+	if err := cs.Storage().UpdateFeedback(feedback); err != nil {
+		return nil, err
+	}
+	return feedback, nil
+}
+
 func (cs *ChatServer) GetMessageReferences(ctx context.Context, req MessageReqBody) ([]retriever.Reference, error) {
 	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
 	m, err := cs.Storage().FindExistingMessage(req.ConversationID, req.MessageID, storage.WithAppNamespace(req.AppNamespace), storage.WithAppName(req.APPName), storage.WithAppNamespace(req.AppNamespace), storage.WithUser(currentUser))
@@ -242,53 +444,9 @@ func (cs *ChatServer) ListPromptStarters(ctx context.Context, req APPMetadata, l
 	if err != nil {
 		return nil, err
 	}
-	var kb *v1alpha1.KnowledgeBase
-	var chainOptions []chains.ChainCallOption
-	var model langchainllms.Model
-	for _, n := range app.Spec.Nodes {
-		baseNode := base.NewBaseNode(app.Namespace, n.Name, *n.Ref)
-		switch baseNode.Group() {
-		case "chain":
-			switch baseNode.Kind() {
-			case "llmchain":
-				ch := appruntimechain.NewLLMChain(baseNode)
-				if err := ch.Init(ctx, cs.systemCli, nil); err != nil {
-					klog.Infof("init llmchain err:%s, will use empty chain config", err)
-				}
-				chainOptions = appruntimechain.GetChainOptions(ch.Instance.Spec.CommonChainConfig)
-			case "retrievalqachain":
-				ch := appruntimechain.NewRetrievalQAChain(baseNode)
-				if err := ch.Init(ctx, cs.systemCli, nil); err != nil {
-					klog.Infof("init retrievalqachain err:%s, will use empty chain config", err)
-				}
-				chainOptions = appruntimechain.GetChainOptions(ch.Instance.Spec.CommonChainConfig)
-			case "apichain":
-				ch := appruntimechain.NewAPIChain(baseNode)
-				if err := ch.Init(ctx, cs.systemCli, nil); err != nil {
-					klog.Infof("init apichain err:%s, will use empty chain config", err)
-				}
-				chainOptions = appruntimechain.GetChainOptions(ch.Instance.Spec.CommonChainConfig)
-			default:
-				klog.Infoln("can't find chain config in app, use empty chain config")
-			}
-		case "":
-			switch baseNode.Kind() {
-			case "llm":
-				l := llm.NewLLM(baseNode)
-				if err := l.Init(ctx, cs.systemCli, nil); err != nil {
-					klog.Infof("init llm err:%s, abort", err)
-					return nil, err
-				}
-				model = l.Model
-			case "knowledgebase":
-				k := knowledgebase.NewKnowledgebase(baseNode)
-				if err := k.Init(ctx, cs.systemCli, nil); err != nil {
-					klog.Infof("init knowledgebase err:%s, abort", err)
-					return nil, err
-				}
-				kb = k.Instance
-			}
-		}
+	model, chainOptions, kb, err := cs.appModel(ctx, app)
+	if err != nil {
+		return nil, err
 	}
 	promptStarters = make([]string, 0, limit)
 	content := bytes.Buffer{}
@@ -369,6 +527,56 @@ func (cs *ChatServer) ListPromptStarters(ctx context.Context, req APPMetadata, l
 	return promptStarters, nil
 }
 
+// appModel returns the llm, the chain options and the knowledgebase in the nodes of the application
+func (cs *ChatServer) appModel(ctx context.Context, app *v1alpha1.Application) (model langchainllms.Model, chainOptions []chains.ChainCallOption, kb *v1alpha1.KnowledgeBase, err error) {
+	for _, n := range app.Spec.Nodes {
+		baseNode := base.NewBaseNode(app.Namespace, n.Name, *n.Ref)
+		switch baseNode.Group() {
+		case "chain":
+			switch baseNode.Kind() {
+			case "llmchain":
+				ch := appruntimechain.NewLLMChain(baseNode)
+				if err := ch.Init(ctx, cs.systemCli, nil); err != nil {
+					klog.Infof("init llmchain err:%s, will use empty chain config", err)
+				}
+				chainOptions = appruntimechain.GetChainOptions(ch.Instance.Spec.CommonChainConfig)
+			case "retrievalqachain":
+				ch := appruntimechain.NewRetrievalQAChain(baseNode)
+				if err := ch.Init(ctx, cs.systemCli, nil); err != nil {
+					klog.Infof("init retrievalqachain err:%s, will use empty chain config", err)
+				}
+				chainOptions = appruntimechain.GetChainOptions(ch.Instance.Spec.CommonChainConfig)
+			case "apichain":
+				ch := appruntimechain.NewAPIChain(baseNode)
+				if err := ch.Init(ctx, cs.systemCli, nil); err != nil {
+					klog.Infof("init apichain err:%s, will use empty chain config", err)
+				}
+				chainOptions = appruntimechain.GetChainOptions(ch.Instance.Spec.CommonChainConfig)
+			default:
+				klog.Infoln("can't find chain config in app, use empty chain config")
+			}
+		case "":
+			switch baseNode.Kind() {
+			case "llm", "llmgroup":
+				l := llm.NewLLM(baseNode)
+				if err := l.Init(ctx, cs.systemCli, nil); err != nil {
+					klog.Infof("init llm err:%s, abort", err)
+					return nil, nil, nil, err
+				}
+				model = l.Model
+			case "knowledgebase":
+				k := knowledgebase.NewKnowledgebase(baseNode)
+				if err := k.Init(ctx, cs.systemCli, nil); err != nil {
+					klog.Infof("init knowledgebase err:%s, abort", err)
+					return nil, nil, nil, err
+				}
+				kb = k.Instance
+			}
+		}
+	}
+	return model, chainOptions, kb, nil
+}
+
 const PromptForGeneratePromptStartersByAppInfo = `You are the friendly and curious questioner, please ask {{.limit}} questions based on the name and description of this app below.
 
 Requires language consistent with the name and description of the application, no restating of my words, questions only, one question per line, no subheadings.
@@ -401,6 +609,21 @@ func (cs *ChatServer) GetApp(ctx context.Context, appName, appNamespace string)
 	return app, nil
 }
 
+// ListReadyApps returns the ready applications in the namespace which the user can chat with, empty namespace means all namespaces
+func (cs *ChatServer) ListReadyApps(ctx context.Context, namespace string) ([]v1alpha1.Application, error) {
+	list := &v1alpha1.ApplicationList{}
+	if err := cs.systemCli.List(ctx, list, runtimeclient.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+	apps := make([]v1alpha1.Application, 0, len(list.Items))
+	for _, app := range list.Items {
+		if app.Status.IsReady() && cs.IsGPTUserHasPermissionForApp(ctx, &app) {
+			apps = append(apps, app)
+		}
+	}
+	return apps, nil
+}
+
 // todo Reuse the flow without having to rebuild req same, not finish, Flow doesn't start with/contain nodes that depend on incomingInput.question
 
 func (cs *ChatServer) FillAppIconToConversations(ctx context.Context, conversations *[]storage.Conversation) error {
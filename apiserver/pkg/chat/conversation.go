@@ -0,0 +1,125 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tmc/langchaingo/chains"
+	"github.com/tmc/langchaingo/prompts"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/pkg/auth"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+)
+
+const (
+	// maxTitleLength is the max runes of a conversation title
+	maxTitleLength = 50
+	// defaultTitleLength is the runes of the query used as the title before it is generated
+	defaultTitleLength = 20
+	// timeout to generate the title by llm
+	generateTitleTimeout = 30 * time.Second
+)
+
+const PromptForGenerateConversationTitle = `Summarize the conversation below into a short title, no more than 10 words.
+
+Requires language consistent with the conversation, the title only, no quotes, no punctuation at the end.
+---
+Q: {{.query}}
+A: {{.answer}}
+---
+The title is:`
+
+// defaultTitle returns the beginning of the query as the title
+func defaultTitle(query string) string {
+	return truncate(strings.Join(strings.Fields(query), " "), defaultTitleLength)
+}
+
+// truncate returns the first n runes of s
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// generateTitle lets the llm of the application summarize the first turn of the conversation into the title.
+// It runs in the background after the answer is returned, the title is not changed if it fails.
+func (cs *ChatServer) generateTitle(app *v1alpha1.Application, user, conversationID, query, answer string) {
+	ctx, cancel := context.WithTimeout(context.Background(), generateTitleTimeout)
+	defer cancel()
+	ctx, record := cs.recordBackgroundUsage(ctx, app, user)
+	defer record()
+	logger := klog.FromContext(ctx).WithValues("conversationID", conversationID)
+	model, chainOptions, _, err := cs.appModel(ctx, app)
+	if err != nil || model == nil {
+		logger.V(3).Info("no llm in app to generate the conversation title", "error", err)
+		return
+	}
+	p := prompts.NewPromptTemplate(PromptForGenerateConversationTitle, []string{"query", "answer"})
+	title, err := chains.Predict(ctx, chains.NewLLMChain(model, p, chainOptions...), map[string]any{
+		"query":  truncate(query, 500),
+		"answer": truncate(answer, 500),
+	})
+	if err != nil {
+		logger.Error(err, "failed to generate the conversation title")
+		return
+	}
+	title = strings.TrimSpace(strings.SplitN(strings.TrimSpace(title), "\n", 2)[0])
+	title = strings.Trim(title, "\"'“”《》#*")
+	if title == "" {
+		return
+	}
+	if err := cs.Storage().RenameConversation(conversationID, truncate(title, maxTitleLength)); err != nil {
+		logger.Error(err, "failed to save the conversation title")
+	}
+}
+
+// UpdateConversation renames or pins the conversation of current user
+func (cs *ChatServer) UpdateConversation(ctx context.Context, conversationID string, req UpdateConversationReqBody) error {
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	if req.Title != nil {
+		if err := cs.Storage().RenameConversation(conversationID, truncate(strings.TrimSpace(*req.Title), maxTitleLength), storage.WithUser(currentUser)); err != nil {
+			return err
+		}
+	}
+	if req.Pinned != nil {
+		if err := cs.Storage().PinConversation(conversationID, *req.Pinned, storage.WithUser(currentUser)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportConversation returns the conversation of current user in the format, with its file name and content type
+func (cs *ChatServer) ExportConversation(ctx context.Context, conversationID string, format ExportFormat) (content []byte, fileName, contentType string, err error) {
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	c, err := cs.Storage().FindExistingConversation(conversationID, storage.WithUser(currentUser))
+	if err != nil {
+		return nil, "", "", err
+	}
+	content, contentType, err = ExportConversation(c, format)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return content, conversationID + format.Ext(), contentType, nil
+}
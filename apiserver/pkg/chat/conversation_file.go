@@ -0,0 +1,298 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/pkg/auth"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	pkgconfig "github.com/kubeagi/arcadia/pkg/config"
+	"github.com/kubeagi/arcadia/pkg/vectorstore"
+)
+
+const (
+	// defaultMaxConversationFileSizeMB is the max size of a file uploaded to a conversation in MiB if the application doesn't limit it
+	defaultMaxConversationFileSizeMB = 20
+	// defaultMaxConversationFiles is the max number of files in a conversation if the application doesn't limit it
+	defaultMaxConversationFiles = 10
+)
+
+// defaultConversationFileTypes are the file types which can be embedded into the conversation knowledgebase
+var defaultConversationFileTypes = []string{".pdf", ".txt", ".md", ".csv", ".json", ".html", ".htm"}
+
+var (
+	ErrInvalidFile     = errors.New("invalid file")
+	ErrFileNotFailed   = errors.New("only the files failed to be processed can be re-processed")
+	ErrFileNotUploaded = errors.New("no file is uploaded")
+)
+
+// conversationFileLimits are the limits of the files uploaded to the conversations of an application
+type conversationFileLimits struct {
+	maxFileSize  int64
+	maxFiles     int
+	allowedTypes []string
+}
+
+func fileLimits(app *arcadiav1alpha1.Application) conversationFileLimits {
+	limits := conversationFileLimits{
+		maxFileSize:  defaultMaxConversationFileSizeMB << 20,
+		maxFiles:     defaultMaxConversationFiles,
+		allowedTypes: defaultConversationFileTypes,
+	}
+	config := app.Spec.ConversationFile
+	if config == nil {
+		return limits
+	}
+	if config.MaxFileSizeMB > 0 {
+		limits.maxFileSize = int64(config.MaxFileSizeMB) << 20
+	}
+	if config.MaxFiles > 0 {
+		limits.maxFiles = config.MaxFiles
+	}
+	if len(config.AllowedTypes) > 0 {
+		limits.allowedTypes = config.AllowedTypes
+	}
+	return limits
+}
+
+// check returns ErrInvalidFile if the files can't be uploaded to a conversation which already has existing files
+func (l conversationFileLimits) check(existing int, files []*multipart.FileHeader) error {
+	if len(files) == 0 {
+		return ErrFileNotUploaded
+	}
+	if existing+len(files) > l.maxFiles {
+		return fmt.Errorf("%w: at most %d files in a conversation, %d uploaded already", ErrInvalidFile, l.maxFiles, existing)
+	}
+	for _, file := range files {
+		if !l.allowed(file.Filename) {
+			return fmt.Errorf("%w: type of %s is not one of %s", ErrInvalidFile, file.Filename, strings.Join(l.allowedTypes, ", "))
+		}
+		if file.Size > l.maxFileSize {
+			return fmt.Errorf("%w: %s is larger than %d MiB", ErrInvalidFile, file.Filename, l.maxFileSize>>20)
+		}
+	}
+	return nil
+}
+
+func (l conversationFileLimits) allowed(fileName string) bool {
+	ext := filepath.Ext(fileName)
+	for _, t := range l.allowedTypes {
+		if strings.EqualFold(ext, "."+strings.TrimPrefix(t, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// conversationFiles finds the conversation of current user and the files uploaded to it
+func (cs *ChatServer) conversationFiles(ctx context.Context, conversationID string) (*storage.Conversation, []storage.Document, error) {
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	conversation, err := cs.Storage().FindExistingConversation(conversationID, storage.WithUser(currentUser))
+	if err != nil {
+		return nil, nil, err
+	}
+	documents, err := cs.Storage().ListDocuments(conversation.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conversation, documents, nil
+}
+
+// conversationKnowledgeBase returns the knowledgebase of the conversation, or nil if no file is embedded
+func (cs *ChatServer) conversationKnowledgeBase(ctx context.Context, conversation *storage.Conversation) (*arcadiav1alpha1.KnowledgeBase, error) {
+	kb := &arcadiav1alpha1.KnowledgeBase{}
+	if err := cs.systemCli.Get(ctx, types.NamespacedName{Namespace: conversation.AppNamespace, Name: conversation.ID}, kb); err != nil {
+		return nil, runtimeclient.IgnoreNotFound(err)
+	}
+	return kb, nil
+}
+
+// fileDetails returns the process details of the files in the knowledgebase by path
+func fileDetails(kb *arcadiav1alpha1.KnowledgeBase) map[string]arcadiav1alpha1.FileDetails {
+	details := make(map[string]arcadiav1alpha1.FileDetails)
+	if kb == nil {
+		return details
+	}
+	for _, group := range kb.Status.FileGroupDetail {
+		for _, detail := range group.FileDetails {
+			details[detail.Path] = detail
+		}
+	}
+	return details
+}
+
+// ListConversationFiles returns the files uploaded to the conversation with their process phase in the conversation knowledgebase
+func (cs *ChatServer) ListConversationFiles(ctx context.Context, conversationID string) ([]ConversationFile, error) {
+	conversation, documents, err := cs.conversationFiles(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	kb, err := cs.conversationKnowledgeBase(ctx, conversation)
+	if err != nil {
+		return nil, err
+	}
+	details := fileDetails(kb)
+	files := make([]ConversationFile, 0, len(documents))
+	for _, document := range documents {
+		file := ConversationFile{
+			DocumentRespBody: DocumentRespBody{ID: document.ID, Name: document.Name, Object: document.Object},
+			MessageID:        document.MessageID,
+			Phase:            arcadiav1alpha1.FileProcessPhasePending,
+		}
+		if detail, ok := details[document.Object]; ok && detail.Phase != "" {
+			file.Phase = detail.Phase
+			file.ErrMessage = detail.ErrMessage
+			file.Chunks = detail.Chunks
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// DeleteConversationFile removes the file from the conversation, its documents in the conversation knowledgebase
+// and its object in the system datasource if no other file of the conversation has the same content.
+func (cs *ChatServer) DeleteConversationFile(ctx context.Context, conversationID, fileID string) error {
+	conversation, documents, err := cs.conversationFiles(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	var document *storage.Document
+	shared := false
+	for i := range documents {
+		if documents[i].ID == fileID {
+			document = &documents[i]
+		}
+	}
+	if document == nil {
+		return storage.ErrDocumentNotFound
+	}
+	for _, d := range documents {
+		if d.ID != document.ID && d.Object == document.Object {
+			shared = true
+		}
+	}
+	if !shared {
+		if err := cs.removeKnowledgeBaseFile(ctx, conversation, document.Object); err != nil {
+			return err
+		}
+		ds, err := pkgconfig.GetSystemDatasourceOSS(ctx)
+		if err != nil {
+			return fmt.Errorf("no storage service found with err %s", err.Error())
+		}
+		if err := ds.Remove(ctx, &arcadiav1alpha1.OSS{Bucket: conversation.AppNamespace, Object: document.Object}); err != nil {
+			return fmt.Errorf("failed to remove file %s with error %s", document.Name, err.Error())
+		}
+	}
+	return cs.Storage().DeleteDocument(conversation.ID, document.ID)
+}
+
+// removeKnowledgeBaseFile removes the file and its documents from the conversation knowledgebase,
+// and deletes the knowledgebase if no file is left.
+func (cs *ChatServer) removeKnowledgeBaseFile(ctx context.Context, conversation *storage.Conversation, object string) error {
+	kb, err := cs.conversationKnowledgeBase(ctx, conversation)
+	if err != nil || kb == nil {
+		return err
+	}
+	found, left := false, 0
+	fileGroups := make([]arcadiav1alpha1.FileGroup, 0, len(kb.Spec.FileGroups))
+	for _, group := range kb.Spec.FileGroups {
+		files := make([]arcadiav1alpha1.FileWithVersion, 0, len(group.Files))
+		for _, file := range group.Files {
+			if file.Path == object {
+				found = true
+				continue
+			}
+			files = append(files, file)
+		}
+		if len(files) == 0 {
+			continue
+		}
+		group.Files = files
+		fileGroups = append(fileGroups, group)
+		left += len(files)
+	}
+	if !found {
+		return nil
+	}
+	if left == 0 {
+		// the collection is removed along with the knowledgebase
+		return runtimeclient.IgnoreNotFound(cs.systemCli.Delete(ctx, kb))
+	}
+	kb.Spec.FileGroups = fileGroups
+	if err := cs.systemCli.Update(ctx, kb); err != nil {
+		return err
+	}
+	if _, ok := fileDetails(kb)[object]; !ok {
+		// not embedded yet
+		return nil
+	}
+	vs := &arcadiav1alpha1.VectorStore{}
+	if err := cs.systemCli.Get(ctx, types.NamespacedName{Namespace: kb.Spec.VectorStore.GetNamespace(kb.Namespace), Name: kb.Spec.VectorStore.Name}, vs); err != nil {
+		return err
+	}
+	return vectorstore.RemoveFileDocuments(ctx, klog.FromContext(ctx), vs, kb.VectorStoreCollectionName(), cs.systemCli, object)
+}
+
+// ReprocessConversationFile embeds the file into the conversation knowledgebase again if it failed to be processed.
+// Note that all the failed files of the conversation are re-processed together.
+func (cs *ChatServer) ReprocessConversationFile(ctx context.Context, conversationID, fileID string) error {
+	conversation, documents, err := cs.conversationFiles(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	var document *storage.Document
+	for i := range documents {
+		if documents[i].ID == fileID {
+			document = &documents[i]
+		}
+	}
+	if document == nil {
+		return storage.ErrDocumentNotFound
+	}
+	kb, err := cs.conversationKnowledgeBase(ctx, conversation)
+	if err != nil {
+		return err
+	}
+	if detail, ok := fileDetails(kb)[document.Object]; !ok || detail.Phase != arcadiav1alpha1.FileProcessPhaseFailed {
+		return ErrFileNotFailed
+	}
+	if kb.Annotations == nil {
+		kb.Annotations = make(map[string]string)
+	}
+	kb.Annotations[arcadiav1alpha1.UpdateSourceFileAnnotationKey] = arcadiav1alpha1.UpdateSourceFileRetryFailed
+	return cs.systemCli.Update(ctx, kb)
+}
+
+// removeConversationObjects removes all the files and images of the conversation in the system datasource
+func (cs *ChatServer) removeConversationObjects(ctx context.Context, conversation *storage.Conversation) error {
+	ds, err := pkgconfig.GetSystemDatasourceOSS(ctx)
+	if err != nil {
+		return fmt.Errorf("no storage service found with err %s", err.Error())
+	}
+	return ds.Remove(ctx, &arcadiav1alpha1.OSS{Bucket: conversation.AppNamespace, Object: arcadiav1alpha1.ConversationFilePath(conversation.AppName, conversation.ID, "")})
+}
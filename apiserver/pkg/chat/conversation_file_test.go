@@ -0,0 +1,63 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"errors"
+	"mime/multipart"
+	"testing"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+func TestConversationFileLimits(t *testing.T) {
+	pdf := &multipart.FileHeader{Filename: "kaoqin.PDF", Size: 1 << 20}
+	large := &multipart.FileHeader{Filename: "large.txt", Size: 30 << 20}
+	exe := &multipart.FileHeader{Filename: "app.exe", Size: 1 << 10}
+
+	defaults := fileLimits(&arcadiav1alpha1.Application{})
+	if err := defaults.check(0, []*multipart.FileHeader{pdf, pdf}); err != nil {
+		t.Errorf("got error %v for valid files", err)
+	}
+	if err := defaults.check(0, nil); !errors.Is(err, ErrFileNotUploaded) {
+		t.Errorf("got error %v for no file, want ErrFileNotUploaded", err)
+	}
+	for _, invalid := range []*multipart.FileHeader{large, exe} {
+		if err := defaults.check(0, []*multipart.FileHeader{pdf, invalid}); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("got error %v for %s, want ErrInvalidFile", err, invalid.Filename)
+		}
+	}
+	if err := defaults.check(defaultMaxConversationFiles-1, []*multipart.FileHeader{pdf, pdf}); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("got error %v for too many files, want ErrInvalidFile", err)
+	}
+
+	app := &arcadiav1alpha1.Application{}
+	app.Spec.ConversationFile = &arcadiav1alpha1.ConversationFileConfig{MaxFileSizeMB: 50, MaxFiles: 1, AllowedTypes: []string{"exe", ".txt"}}
+	limits := fileLimits(app)
+	if err := limits.check(0, []*multipart.FileHeader{large}); err != nil {
+		t.Errorf("got error %v for the file smaller than the limit of app", err)
+	}
+	if err := limits.check(0, []*multipart.FileHeader{exe}); err != nil {
+		t.Errorf("got error %v for the type allowed by app", err)
+	}
+	if err := limits.check(0, []*multipart.FileHeader{pdf}); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("got error %v for the type not allowed by app, want ErrInvalidFile", err)
+	}
+	if err := limits.check(1, []*multipart.FileHeader{exe}); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("got error %v for too many files, want ErrInvalidFile", err)
+	}
+}
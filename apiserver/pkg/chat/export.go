@@ -0,0 +1,274 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+)
+
+type ExportFormat string
+
+const (
+	ExportMarkdown ExportFormat = "markdown"
+	ExportJSON     ExportFormat = "json"
+	ExportPDF      ExportFormat = "pdf"
+)
+
+// Ext returns the file extension of the format
+func (f ExportFormat) Ext() string {
+	switch f {
+	case ExportJSON:
+		return ".json"
+	case ExportPDF:
+		return ".pdf"
+	default:
+		return ".md"
+	}
+}
+
+// ExportConversation renders the conversation with its references, returns the content and its content type
+func ExportConversation(c *storage.Conversation, format ExportFormat) ([]byte, string, error) {
+	switch format {
+	case ExportJSON:
+		content, err := json.MarshalIndent(c, "", "  ")
+		return content, "application/json", err
+	case ExportMarkdown, "":
+		return []byte(conversationMarkdown(c)), "text/markdown; charset=utf-8", nil
+	case ExportPDF:
+		return newPDF(conversationLines(c)).bytes(), "application/pdf", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported export format %s, should be one of markdown, json and pdf", format)
+	}
+}
+
+func conversationTitle(c *storage.Conversation) string {
+	if c.Title != "" {
+		return c.Title
+	}
+	return "Conversation " + c.ID
+}
+
+func conversationMarkdown(c *storage.Conversation) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# %s\n\n", conversationTitle(c))
+	fmt.Fprintf(b, "- Application: %s/%s\n", c.AppNamespace, c.AppName)
+	fmt.Fprintf(b, "- Started at: %s\n", c.StartedAt.Format(time.RFC3339))
+	fmt.Fprintf(b, "- Updated at: %s\n", c.UpdatedAt.Format(time.RFC3339))
+	for _, m := range c.Messages {
+		b.WriteString("\n---\n\n")
+		if len(m.Documents) > 0 {
+			fmt.Fprintf(b, "**Files:** %s\n\n", documentNames(m.Documents))
+		}
+		if m.Query != "" {
+			fmt.Fprintf(b, "**Q:** %s\n\n", m.Query)
+		}
+		if m.Answer != "" {
+			fmt.Fprintf(b, "**A:** %s\n\n", m.Answer)
+		}
+		if len(m.References) > 0 {
+			b.WriteString("**References:**\n\n")
+			for i, r := range m.References {
+				fmt.Fprintf(b, "%d. %s\n", i+1, referenceText(r))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// conversationLines returns the conversation in plain text lines
+func conversationLines(c *storage.Conversation) []string {
+	lines := []string{
+		conversationTitle(c),
+		"",
+		fmt.Sprintf("Application: %s/%s", c.AppNamespace, c.AppName),
+		fmt.Sprintf("Started at: %s", c.StartedAt.Format(time.RFC3339)),
+		fmt.Sprintf("Updated at: %s", c.UpdatedAt.Format(time.RFC3339)),
+	}
+	add := func(prefix, text string) {
+		for i, l := range strings.Split(text, "\n") {
+			if i == 0 {
+				l = prefix + l
+			}
+			lines = append(lines, l)
+		}
+	}
+	for _, m := range c.Messages {
+		lines = append(lines, "", strings.Repeat("-", 40), "")
+		if len(m.Documents) > 0 {
+			add("Files: ", documentNames(m.Documents))
+		}
+		if m.Query != "" {
+			add("Q: ", m.Query)
+			lines = append(lines, "")
+		}
+		if m.Answer != "" {
+			add("A: ", m.Answer)
+		}
+		if len(m.References) > 0 {
+			lines = append(lines, "", "References:")
+			for i, r := range m.References {
+				add(fmt.Sprintf("%d. ", i+1), referenceText(r))
+			}
+		}
+	}
+	return lines
+}
+
+func documentNames(docs []storage.Document) string {
+	names := make([]string, 0, len(docs))
+	for _, d := range docs {
+		names = append(names, d.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// referenceText returns the source and the content of the reference in one line
+func referenceText(r retriever.Reference) string {
+	source := r.FileName
+	if r.PageNumber > 0 {
+		source = fmt.Sprintf("%s p.%d", source, r.PageNumber)
+	}
+	if r.URL != "" {
+		source = strings.TrimSpace(r.Title + " " + r.URL)
+	}
+	content := r.Content
+	if content == "" {
+		content = strings.TrimSpace(r.Question + " " + r.Answer)
+	}
+	content = strings.Join(strings.Fields(content), " ")
+	if source == "" {
+		return content
+	}
+	return fmt.Sprintf("[%s] %s", source, content)
+}
+
+// pdf is a minimal pdf writer for text, it uses the standard Chinese font STSong-Light,
+// which is not embedded, so both Chinese and English text can be shown by pdf readers.
+type pdf struct {
+	pages [][]string
+}
+
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+	pdfFontSize   = 11
+	pdfLeading    = 16
+)
+
+// newPDF wraps the lines to fit the page width and splits them into pages
+func newPDF(lines []string) *pdf {
+	maxWidth := float64(pdfPageWidth-2*pdfMargin) / pdfFontSize
+	linesPerPage := (pdfPageHeight - 2*pdfMargin) / pdfLeading
+	var wrapped []string
+	for _, l := range lines {
+		wrapped = append(wrapped, wrapLine(l, maxWidth)...)
+	}
+	p := &pdf{}
+	for len(wrapped) > linesPerPage {
+		p.pages = append(p.pages, wrapped[:linesPerPage])
+		wrapped = wrapped[linesPerPage:]
+	}
+	p.pages = append(p.pages, wrapped)
+	return p
+}
+
+// runeWidth is the width of the rune in em, ascii characters are half-width
+func runeWidth(r rune) float64 {
+	if r < 0x80 {
+		return 0.5
+	}
+	return 1
+}
+
+// wrapLine splits the line into lines which are not wider than maxWidth em
+func wrapLine(line string, maxWidth float64) []string {
+	line = strings.ReplaceAll(line, "\t", "    ")
+	var res []string
+	var current []rune
+	width := 0.0
+	for _, r := range line {
+		w := runeWidth(r)
+		if width+w > maxWidth {
+			res = append(res, string(current))
+			current, width = nil, 0
+		}
+		current = append(current, r)
+		width += w
+	}
+	return append(res, string(current))
+}
+
+// hexText encodes the text in UCS-2, characters out of the basic multilingual plane are replaced by '?'
+func hexText(s string) string {
+	b := &strings.Builder{}
+	for _, r := range s {
+		if r > 0xFFFF || r < 0x20 {
+			r = '?'
+		}
+		fmt.Fprintf(b, "%04X", r)
+	}
+	return b.String()
+}
+
+func (p *pdf) bytes() []byte {
+	buf := &bytes.Buffer{}
+	var offsets []int
+	// objects are numbered from 1 in the order they are written
+	write := func(obj string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", len(offsets), obj)
+	}
+	buf.WriteString("%PDF-1.4\n")
+	// 1: catalog, 2: pages, 3-5: font, then a page and its content for each page
+	const firstPage = 6
+	kids := make([]string, 0, len(p.pages))
+	for i := range p.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPage+2*i))
+	}
+	write("<< /Type /Catalog /Pages 2 0 R >>")
+	write(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	write("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>")
+	write("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light /CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> /FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>")
+	write("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	for i, lines := range p.pages {
+		write(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, firstPage+2*i+1))
+		content := &strings.Builder{}
+		fmt.Fprintf(content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, l := range lines {
+			fmt.Fprintf(content, "<%s> Tj T*\n", hexText(l))
+		}
+		content.WriteString("ET")
+		write(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
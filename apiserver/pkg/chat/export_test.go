@@ -0,0 +1,158 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	pdfreader "github.com/ledongthuc/pdf"
+
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+)
+
+func testConversation() *storage.Conversation {
+	return &storage.Conversation{
+		ID:           "5a41f3ca-763b-41ec-91c3-4bbbb00736d0",
+		AppName:      "chat-with-kb",
+		AppNamespace: "arcadia",
+		Title:        "旷工计算单位",
+		Messages: []storage.Message{{
+			ID:     "4f3546dd-5404-4bf8-a3bc-4fa3f9a7ba24",
+			Query:  "旷工最小计算单位为多少天？",
+			Answer: "旷工最小计算单位为0.5天。",
+			References: storage.References{{
+				FileName:   "kaoqin.pdf",
+				PageNumber: 3,
+				Content:    "旷工最小计算单位为0.5天，\n不足0.5天以0.5天计算。",
+			}},
+		}},
+	}
+}
+
+func TestExportConversation(t *testing.T) {
+	c := testConversation()
+
+	md, contentType, err := ExportConversation(c, ExportMarkdown)
+	if err != nil || !strings.HasPrefix(contentType, "text/markdown") {
+		t.Fatalf("unexpected content type %s, error %v", contentType, err)
+	}
+	for _, expected := range []string{"# 旷工计算单位", "**Q:** 旷工最小计算单位为多少天？", "1. [kaoqin.pdf p.3] 旷工最小计算单位为0.5天， 不足0.5天以0.5天计算。"} {
+		if !strings.Contains(string(md), expected) {
+			t.Fatalf("expected %q in markdown:\n%s", expected, md)
+		}
+	}
+
+	js, _, err := ExportConversation(c, ExportJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := storage.Conversation{}
+	if err := json.Unmarshal(js, &got); err != nil || got.Messages[0].References[0].FileName != "kaoqin.pdf" {
+		t.Fatalf("unexpected json %s, error %v", js, err)
+	}
+
+	doc, _, err := ExportConversation(c, ExportPDF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(doc, []byte("%PDF-")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatalf("invalid pdf:\n%s", doc)
+	}
+	pages, text := pdfText(t, doc)
+	if pages != 1 || text != strings.Join(conversationLines(c), "\n") {
+		t.Fatalf("expected the conversation in 1 page, got %d pages:\n%s", pages, text)
+	}
+
+	if _, _, err := ExportConversation(c, "docx"); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
+
+func TestExportConversationPages(t *testing.T) {
+	c := testConversation()
+	for i := 0; i < 30; i++ {
+		c.Messages = append(c.Messages, storage.Message{
+			Query:  fmt.Sprintf("问题%d", i),
+			Answer: strings.Repeat("旷工最小计算单位为0.5天。", 10),
+		})
+	}
+	doc, _, err := ExportConversation(c, ExportPDF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages, text := pdfText(t, doc)
+	// long lines are wrapped into several lines
+	if pages < 2 || strings.ReplaceAll(text, "\n", "") != strings.Join(conversationLines(c), "") {
+		t.Fatalf("expected the conversation in pages, got %d pages:\n%s", pages, text)
+	}
+}
+
+// pdfText parses the pdf and returns the number of pages and the text lines, which are encoded in UCS-2 by the font.
+func pdfText(t *testing.T, doc []byte) (int, string) {
+	t.Helper()
+	r, err := pdfreader.NewReader(bytes.NewReader(doc), int64(len(doc)))
+	if err != nil {
+		t.Fatalf("failed to parse pdf: %s", err)
+	}
+	var lines []string
+	for i := 1; i <= r.NumPage(); i++ {
+		page := r.Page(i)
+		if encoding := page.Font("F1").V.Key("Encoding").Name(); encoding != "UniGB-UCS2-H" {
+			t.Fatalf("unexpected encoding %s of page %d", encoding, i)
+		}
+		pdfreader.Interpret(page.V.Key("Contents"), func(stk *pdfreader.Stack, op string) {
+			args := make([]pdfreader.Value, stk.Len())
+			for j := len(args) - 1; j >= 0; j-- {
+				args[j] = stk.Pop()
+			}
+			if op != "Tj" {
+				return
+			}
+			raw := args[0].RawString()
+			units := make([]uint16, 0, len(raw)/2)
+			for j := 0; j+1 < len(raw); j += 2 {
+				units = append(units, uint16(raw[j])<<8|uint16(raw[j+1]))
+			}
+			lines = append(lines, string(utf16.Decode(units)))
+		})
+	}
+	return r.NumPage(), strings.Join(lines, "\n")
+}
+
+func TestWrapLine(t *testing.T) {
+	testCases := []struct {
+		line     string
+		maxWidth float64
+		expected []string
+	}{
+		{line: "", maxWidth: 2, expected: []string{""}},
+		{line: "abcd", maxWidth: 2, expected: []string{"abcd"}},
+		{line: "abcde", maxWidth: 2, expected: []string{"abcd", "e"}},
+		{line: "旷工a最小", maxWidth: 2, expected: []string{"旷工", "a最", "小"}},
+	}
+	for _, tc := range testCases {
+		got := wrapLine(tc.line, tc.maxWidth)
+		if strings.Join(got, "|") != strings.Join(tc.expected, "|") {
+			t.Fatalf("wrap %q: expected %q, got %q", tc.line, tc.expected, got)
+		}
+	}
+}
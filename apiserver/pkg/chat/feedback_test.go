@@ -0,0 +1,190 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/apiserver/pkg/auth"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	appruntimechain "github.com/kubeagi/arcadia/pkg/appruntime/chain"
+)
+
+func feedbackConversation() *storage.Conversation {
+	return &storage.Conversation{
+		ID:           "conversation",
+		AppName:      "app",
+		AppNamespace: "arcadia",
+		User:         "alice",
+		Messages: []storage.Message{
+			{ID: "m1", Action: "CHAT", Query: "q1", Answer: "a1"},
+			{ID: "m2", Action: "CHAT", Query: "q2", Answer: "a2"},
+		},
+	}
+}
+
+func feedbackRequest(messageID string, rating storage.FeedbackRating) FeedbackReqBody {
+	return FeedbackReqBody{
+		MessageReqBody: MessageReqBody{
+			ConversationReqBody: ConversationReqBody{APPMetadata: APPMetadata{APPName: "app", AppNamespace: "arcadia"}, ConversationID: "conversation"},
+			MessageID:           messageID,
+		},
+		Rating:         rating,
+		Category:       "inaccurate",
+		ExpectedAnswer: "expected",
+	}
+}
+
+func TestSetFeedback(t *testing.T) {
+	cs := &ChatServer{storage: storage.NewMemoryStorage()}
+	if err := cs.Storage().UpdateConversation(feedbackConversation()); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), auth.UserNameContextKey, "alice")
+
+	feedback, err := cs.SetFeedback(ctx, feedbackRequest("m2", storage.FeedbackDislike))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feedback.Version != 0 || feedback.Query != "q2" || feedback.Answer != "a2" || feedback.User != "alice" || feedback.ExpectedAnswer != "expected" {
+		t.Errorf("unexpected feedback %+v", feedback)
+	}
+	// rating again updates the feedback
+	if _, err = cs.SetFeedback(ctx, feedbackRequest("m2", storage.FeedbackLike)); err != nil {
+		t.Fatal(err)
+	}
+	feedbacks, _ := cs.Storage().ListFeedbacks(storage.WithConversationID("conversation"))
+	if len(feedbacks) != 1 || feedbacks[0].Rating != storage.FeedbackLike {
+		t.Errorf("expect one like, got %+v", feedbacks)
+	}
+	// an empty rating removes the feedback
+	if _, err = cs.SetFeedback(ctx, feedbackRequest("m2", "")); err != nil {
+		t.Fatal(err)
+	}
+	if feedbacks, _ = cs.Storage().ListFeedbacks(storage.WithConversationID("conversation")); len(feedbacks) != 0 {
+		t.Errorf("expect no feedbacks, got %+v", feedbacks)
+	}
+
+	if _, err = cs.SetFeedback(ctx, feedbackRequest("m3", storage.FeedbackLike)); !errors.Is(err, storage.ErrMessageNotFound) {
+		t.Errorf("expect message not found, got %v", err)
+	}
+	// only the user of the conversation can rate its answers
+	other := context.WithValue(context.Background(), auth.UserNameContextKey, "bob")
+	if _, err = cs.SetFeedback(other, feedbackRequest("m2", storage.FeedbackLike)); !errors.Is(err, storage.ErrConversationNotFound) {
+		t.Errorf("expect conversation not found, got %v", err)
+	}
+}
+
+func TestRegenerateFeedbacks(t *testing.T) {
+	cs := &ChatServer{storage: storage.NewMemoryStorage()}
+	conversation := feedbackConversation()
+	if err := cs.Storage().UpdateConversation(conversation); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), auth.UserNameContextKey, "alice")
+	if _, err := cs.SetFeedback(ctx, feedbackRequest("m2", storage.FeedbackDislike)); err != nil {
+		t.Fatal(err)
+	}
+
+	// regenerate the answer of m2 as AppRun does
+	message := &conversation.Messages[messageIndex(conversation, "m2")]
+	message.AddAlternate()
+	message.Answer = "a2 regenerated"
+	message.ConversationID = conversation.ID
+	if err := cs.Storage().UpdateMessage(message); err != nil {
+		t.Fatal(err)
+	}
+	if message.Version() != 1 {
+		t.Errorf("expect version 1, got %d", message.Version())
+	}
+	if err := cs.Storage().UpdateMessage(&storage.Message{ID: "m3", ConversationID: conversation.ID}); !errors.Is(err, storage.ErrMessageNotFound) {
+		t.Errorf("expect message not found, got %v", err)
+	}
+
+	// the new answer is rated separately
+	feedback, err := cs.SetFeedback(ctx, feedbackRequest("m2", storage.FeedbackLike))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feedback.Version != 1 || feedback.Answer != "a2 regenerated" {
+		t.Errorf("expect the feedback of the new answer, got %+v", feedback)
+	}
+
+	c, err := cs.Storage().FindExistingConversation(conversation.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cs.fillFeedbacks(c); err != nil {
+		t.Fatal(err)
+	}
+	m := c.Messages[1]
+	if m.Answer != "a2 regenerated" || len(m.Alternates) != 1 || m.Alternates[0].Answer != "a2" {
+		t.Fatalf("expect the previous answer kept as an alternate, got %+v", m)
+	}
+	if m.Feedback == nil || m.Feedback.Rating != storage.FeedbackLike {
+		t.Errorf("expect the like of the current answer, got %+v", m.Feedback)
+	}
+	if m.Alternates[0].Feedback == nil || m.Alternates[0].Feedback.Rating != storage.FeedbackDislike || m.Alternates[0].Feedback.Answer != "a2" {
+		t.Errorf("expect the dislike of the previous answer, got %+v", m.Alternates[0].Feedback)
+	}
+	if c.Messages[0].Feedback != nil {
+		t.Errorf("expect no feedback of m1, got %+v", c.Messages[0].Feedback)
+	}
+}
+
+func TestHistoryRounds(t *testing.T) {
+	conversation := &storage.Conversation{Messages: []storage.Message{
+		{ID: "m1", Query: "q1", Answer: "a1"},
+		{ID: "m2", Action: ActionHuman, Answer: "h2"},
+		{ID: "m3", Query: "q3", Answer: "a3"},
+	}}
+	human := func(s string) langchaingoschema.ChatMessage { return langchaingoschema.HumanChatMessage{Content: s} }
+	ai := func(s string) langchaingoschema.ChatMessage { return langchaingoschema.AIChatMessage{Content: s} }
+	summary := appruntimechain.SummaryChatMessage{Content: "summary"}
+	tests := []struct {
+		name       string
+		summarized int
+		end        int
+		rounds     []langchaingoschema.ChatMessage
+		kept       int
+	}{
+		{name: "all messages", end: 3, rounds: []langchaingoschema.ChatMessage{human("q1"), ai("a1"), ai("h2"), human("q3"), ai("a3")}, kept: 0},
+		{name: "regenerate the last message", end: 2, rounds: []langchaingoschema.ChatMessage{human("q1"), ai("a1"), ai("h2")}, kept: 0},
+		{name: "regenerate the first message", end: 0, rounds: []langchaingoschema.ChatMessage{}, kept: 0},
+		{name: "with summary", summarized: 2, end: 3, rounds: []langchaingoschema.ChatMessage{summary, human("q3"), ai("a3")}, kept: 2},
+		{name: "regenerate a summarized message", summarized: 2, end: 1, rounds: []langchaingoschema.ChatMessage{human("q1"), ai("a1")}, kept: -1},
+	}
+	for _, test := range tests {
+		conversation.SummarizedMessages = test.summarized
+		conversation.Summary = ""
+		if test.summarized > 0 {
+			conversation.Summary = "summary"
+		}
+		rounds, kept := historyRounds(conversation, test.end)
+		if !reflect.DeepEqual(rounds, test.rounds) || kept != test.kept {
+			t.Errorf("%s: expect %v %d, got %v %d", test.name, test.rounds, test.kept, rounds, kept)
+		}
+	}
+	if messageIndex(conversation, "m3") != 2 || messageIndex(conversation, "m4") != -1 {
+		t.Error("unexpected message index")
+	}
+}
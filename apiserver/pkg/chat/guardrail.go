@@ -0,0 +1,58 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	"github.com/kubeagi/arcadia/pkg/appruntime/guardrail"
+)
+
+// recordViolations saves the violations found by the guardrails in the chat, it is try our best
+func (cs *ChatServer) recordViolations(ctx context.Context, req ChatReqBody, user, messageID string, violations []guardrail.Violation) {
+	if len(violations) == 0 {
+		return
+	}
+	records := make([]storage.GuardrailViolation, 0, len(violations))
+	for _, v := range violations {
+		records = append(records, storage.GuardrailViolation{
+			ID:             string(uuid.NewUUID()),
+			ConversationID: req.ConversationID,
+			MessageID:      messageID,
+			AppName:        req.APPName,
+			AppNamespace:   req.AppNamespace,
+			User:           user,
+			Node:           v.Node,
+			Position:       string(v.Position),
+			Rule:           string(v.Rule),
+			Detail:         v.Detail,
+			Action:         string(v.Action),
+		})
+	}
+	if err := cs.Storage().AddGuardrailViolations(records); err != nil {
+		klog.FromContext(ctx).Error(err, "failed to record guardrail violations", "appName", req.APPName, "appNamespace", req.AppNamespace, "violations", len(records))
+	}
+}
+
+func (cs *ChatServer) ListGuardrailViolations(ctx context.Context, req ListGuardrailViolationsReqBody) ([]storage.GuardrailViolation, error) {
+	return cs.Storage().ListGuardrailViolations(storage.WithAppNamespace(req.AppNamespace), storage.WithAppName(req.APPName),
+		storage.WithConversationID(req.ConversationID), storage.WithPagination(req.Page, req.PageSize))
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/pkg/auth"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	"github.com/kubeagi/arcadia/pkg/appruntime"
+)
+
+const (
+	// ActionHuman is the action of the messages replied by human operators
+	ActionHuman = "HUMAN"
+	// DefaultHandoffMessage is told to the user when the conversation is handed off if the application doesn't set one
+	DefaultHandoffMessage = "Your conversation has been transferred to a human agent, please wait for the reply."
+	// timeout to notify the webhook of a handoff
+	handoffWebhookTimeout = 10 * time.Second
+)
+
+var (
+	ErrHandoffDisabled = errors.New("handoff is not enabled in the application")
+	ErrPendingHuman    = errors.New("conversation is waiting for human operators")
+	ErrHandoffResolved = errors.New("handoff is resolved already")
+	ErrEmptyHumanReply = errors.New("reply content is empty")
+)
+
+// matchHandoffKeyword returns whether the user asks for a human operator by any keyword in the question
+func matchHandoffKeyword(handoff *v1alpha1.Handoff, query string) bool {
+	query = strings.ToLower(query)
+	for _, keyword := range handoff.Keywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" && strings.Contains(query, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// handoffReason returns why the conversation should be handed off after the application answers, or empty if it shouldn't
+func handoffReason(handoff *v1alpha1.Handoff, out appruntime.Output) storage.HandoffReason {
+	if out.DocNull {
+		if handoff.OnDocNullReturn {
+			return storage.HandoffReasonDocNull
+		}
+		return ""
+	}
+	if handoff.MinScore == nil || len(out.References) == 0 {
+		return ""
+	}
+	var best float32
+	for _, reference := range out.References {
+		score := reference.Score
+		if reference.RerankScore > 0 {
+			score = reference.RerankScore
+		}
+		if score > best {
+			best = score
+		}
+	}
+	if best < *handoff.MinScore {
+		return storage.HandoffReasonLowConfidence
+	}
+	return ""
+}
+
+func handoffMessage(handoff *v1alpha1.Handoff) string {
+	if handoff != nil && handoff.Message != "" {
+		return handoff.Message
+	}
+	return DefaultHandoffMessage
+}
+
+// handOff queues the conversation for the human operators and notifies the webhook of the application.
+// The caller saves the conversation, which is pending human after this.
+func (cs *ChatServer) handOff(ctx context.Context, app *v1alpha1.Application, conversation *storage.Conversation, messageID, query string, reason storage.HandoffReason) (*storage.Handoff, error) {
+	handoff := &storage.Handoff{
+		ID:             string(uuid.NewUUID()),
+		ConversationID: conversation.ID,
+		AppName:        conversation.AppName,
+		AppNamespace:   conversation.AppNamespace,
+		User:           conversation.User,
+		MessageID:      messageID,
+		Query:          query,
+		Reason:         reason,
+		Status:         storage.HandoffPending,
+	}
+	if err := cs.Storage().CreateHandoff(handoff); err != nil {
+		return nil, err
+	}
+	conversation.PendingHuman = true
+	klog.FromContext(ctx).Info("conversation is handed off", "conversationID", conversation.ID, "reason", reason)
+	if url := app.Spec.Handoff.WebhookURL; url != "" {
+		go notifyHandoffWebhook(url, *handoff)
+	}
+	return handoff, nil
+}
+
+// notifyHandoffWebhook posts the handoff in json to the webhook, the handoff is in the queue even if it fails
+func notifyHandoffWebhook(url string, handoff storage.Handoff) {
+	ctx, cancel := context.WithTimeout(context.Background(), handoffWebhookTimeout)
+	defer cancel()
+	logger := klog.FromContext(ctx).WithValues("handoffID", handoff.ID, "webhook", url)
+	body, err := json.Marshal(handoff)
+	if err != nil {
+		logger.Error(err, "failed to marshal the handoff")
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		logger.Error(err, "failed to create the webhook request")
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		logger.Error(err, "failed to notify the webhook of the handoff")
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		logger.Error(fmt.Errorf("status code %d", resp.StatusCode), "failed to notify the webhook of the handoff")
+	}
+}
+
+// waitForHuman saves the question for the human operators instead of answering it by the application,
+// the conversation is handed off first if it is not pending human yet.
+func (cs *ChatServer) waitForHuman(ctx context.Context, app *v1alpha1.Application, conversation *storage.Conversation, message *storage.Message, req ChatReqBody) (*ChatRespBody, error) {
+	if req.Regenerate {
+		return nil, ErrPendingHuman
+	}
+	notice := ""
+	if !conversation.PendingHuman {
+		if _, err := cs.handOff(ctx, app, conversation, message.ID, req.Query, storage.HandoffReasonUserRequest); err != nil {
+			return nil, err
+		}
+		notice = handoffMessage(app.Spec.Handoff)
+		message.Answer = notice
+	}
+	message.Latency = time.Since(req.StartTime).Milliseconds()
+	conversation.UpdatedAt = req.StartTime
+	if conversation.Title == "" && !req.Debug {
+		conversation.Title = defaultTitle(req.Query)
+	}
+	if err := cs.Storage().UpdateConversation(conversation); err != nil {
+		return nil, err
+	}
+	return &ChatRespBody{
+		ConversationID: conversation.ID,
+		MessageID:      message.ID,
+		Action:         "CHAT",
+		Message:        notice,
+		CreatedAt:      time.Now(),
+		Latency:        message.Latency,
+		PendingHuman:   true,
+		Handoff:        notice,
+	}, nil
+}
+
+// RequestHandoff hands the conversation of current user over to human operators at the request of the user
+func (cs *ChatServer) RequestHandoff(ctx context.Context, conversationID string) (*storage.Handoff, error) {
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	conversation, err := cs.Storage().FindExistingConversation(conversationID, storage.WithUser(currentUser))
+	if err != nil {
+		return nil, err
+	}
+	app, err := cs.GetApp(ctx, conversation.AppName, conversation.AppNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if app.Spec.Handoff == nil {
+		return nil, ErrHandoffDisabled
+	}
+	if conversation.PendingHuman {
+		return nil, ErrPendingHuman
+	}
+	handoff, err := cs.handOff(ctx, app, conversation, "", "", storage.HandoffReasonUserRequest)
+	if err != nil {
+		return nil, err
+	}
+	if err := cs.Storage().SetPendingHuman(conversation.ID, true); err != nil {
+		return nil, err
+	}
+	return handoff, nil
+}
+
+// ListHandoffs returns the handoffs of the applications in the namespace for the human operators, the earliest first
+func (cs *ChatServer) ListHandoffs(ctx context.Context, req ListHandoffsReqBody) ([]storage.Handoff, error) {
+	return cs.Storage().ListHandoffs(storage.WithAppNamespace(req.AppNamespace), storage.WithAppName(req.APPName), storage.WithHandoffStatus(req.Status))
+}
+
+// ReplyHandoff adds the reply of current user as the human operator to the conversation of the pending handoff
+func (cs *ChatServer) ReplyHandoff(ctx context.Context, namespace, handoffID, content string) (*storage.Message, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, ErrEmptyHumanReply
+	}
+	handoff, err := cs.Storage().FindHandoff(handoffID, storage.WithAppNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	if handoff.Status != storage.HandoffPending {
+		return nil, ErrHandoffResolved
+	}
+	conversation, err := cs.Storage().FindExistingConversation(handoff.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	operator, _ := ctx.Value(auth.UserNameContextKey).(string)
+	conversation.Messages = append(conversation.Messages, storage.Message{
+		ID:       string(uuid.NewUUID()),
+		Action:   ActionHuman,
+		Answer:   content,
+		Operator: operator,
+	})
+	conversation.UpdatedAt = time.Now()
+	if err := cs.Storage().UpdateConversation(conversation); err != nil {
+		return nil, err
+	}
+	reply := &conversation.Messages[len(conversation.Messages)-1]
+	humanReplies.publish(conversation.ID, *reply)
+	return reply, nil
+}
+
+// ResolveHandoff marks the handoff as resolved by current user, the application answers the conversation again
+func (cs *ChatServer) ResolveHandoff(ctx context.Context, namespace, handoffID string) error {
+	handoff, err := cs.Storage().FindHandoff(handoffID, storage.WithAppNamespace(namespace))
+	if err != nil {
+		return err
+	}
+	if handoff.Status != storage.HandoffPending {
+		return ErrHandoffResolved
+	}
+	now := time.Now()
+	handoff.Status = storage.HandoffResolved
+	handoff.Operator, _ = ctx.Value(auth.UserNameContextKey).(string)
+	handoff.ResolvedAt = &now
+	if err := cs.Storage().UpdateHandoff(handoff); err != nil {
+		return err
+	}
+	return cs.Storage().SetPendingHuman(handoff.ConversationID, false)
+}
+
+// SubscribeHumanReplies returns the replies of the human operators to the conversation from now on,
+// the returned function must be called to stop the subscription.
+func (cs *ChatServer) SubscribeHumanReplies(conversationID string) (<-chan storage.Message, func()) {
+	return humanReplies.subscribe(conversationID)
+}
+
+// humanReplies delivers the replies of the operators to the streaming chats in this apiserver,
+// the chats served by other replicas get the replies from the messages history.
+var humanReplies = &replyBroker{subscribers: make(map[string]map[chan storage.Message]struct{})}
+
+type replyBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan storage.Message]struct{}
+}
+
+func (b *replyBroker) subscribe(conversationID string) (<-chan storage.Message, func()) {
+	ch := make(chan storage.Message, 8)
+	b.mu.Lock()
+	if b.subscribers[conversationID] == nil {
+		b.subscribers[conversationID] = make(map[chan storage.Message]struct{})
+	}
+	b.subscribers[conversationID][ch] = struct{}{}
+	b.mu.Unlock()
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[conversationID], ch)
+		if len(b.subscribers[conversationID]) == 0 {
+			delete(b.subscribers, conversationID)
+		}
+	}
+}
+
+func (b *replyBroker) publish(conversationID string, reply storage.Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers[conversationID] {
+		select {
+		case ch <- reply:
+		default:
+			// the subscriber is too slow, it gets the reply from the messages history
+		}
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"testing"
+
+	"k8s.io/utils/pointer"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	"github.com/kubeagi/arcadia/pkg/appruntime"
+	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+)
+
+func TestMatchHandoffKeyword(t *testing.T) {
+	handoff := &v1alpha1.Handoff{Keywords: []string{"Human Agent", " 人工 ", ""}}
+	for query, want := range map[string]bool{
+		"let me talk to a human agent": true,
+		"转人工":                          true,
+		"旷工最小计算单位为多少天？":                false,
+	} {
+		if got := matchHandoffKeyword(handoff, query); got != want {
+			t.Errorf("matchHandoffKeyword(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestHandoffReason(t *testing.T) {
+	handoff := &v1alpha1.Handoff{OnDocNullReturn: true, MinScore: pointer.Float32(0.5)}
+	for _, tc := range []struct {
+		name string
+		out  appruntime.Output
+		want storage.HandoffReason
+	}{
+		{"doc null", appruntime.Output{Answer: "未找到您询问的内容", DocNull: true}, storage.HandoffReasonDocNull},
+		{"no references", appruntime.Output{Answer: "hello"}, ""},
+		{"low score", appruntime.Output{References: []retriever.Reference{{Score: 0.3}, {Score: 0.4}}}, storage.HandoffReasonLowConfidence},
+		{"high score", appruntime.Output{References: []retriever.Reference{{Score: 0.3}, {Score: 0.8}}}, ""},
+		{"high rerank score", appruntime.Output{References: []retriever.Reference{{Score: 0.3, RerankScore: 0.9}}}, ""},
+	} {
+		if got := handoffReason(handoff, tc.out); got != tc.want {
+			t.Errorf("%s: got reason %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	if got := handoffReason(&v1alpha1.Handoff{}, appruntime.Output{DocNull: true}); got != "" {
+		t.Errorf("got reason %q without rules, want empty", got)
+	}
+}
+
+func TestReplyBroker(t *testing.T) {
+	b := &replyBroker{subscribers: make(map[string]map[chan storage.Message]struct{})}
+	replies, unsubscribe := b.subscribe("c1")
+	b.publish("c2", storage.Message{ID: "m0"})
+	b.publish("c1", storage.Message{ID: "m1"})
+	select {
+	case reply := <-replies:
+		if reply.ID != "m1" {
+			t.Errorf("got reply %s, want m1", reply.ID)
+		}
+	default:
+		t.Fatal("no reply received")
+	}
+	unsubscribe()
+	if len(b.subscribers) != 0 {
+		t.Errorf("got %d subscribed conversations after unsubscribe, want 0", len(b.subscribers))
+	}
+	b.publish("c1", storage.Message{ID: "m2"})
+	if len(replies) != 0 {
+		t.Error("got reply after unsubscribe")
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/pkg/auth"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	pkgconfig "github.com/kubeagi/arcadia/pkg/config"
+)
+
+const (
+	// maxImages is the max number of images in a query
+	maxImages = 4
+	// maxImageSize is the max bytes of an image
+	maxImageSize = 10 << 20
+)
+
+var (
+	ErrInvalidImage  = errors.New("invalid image")
+	ErrImageNotFound = errors.New("image not found")
+)
+
+// imageExtensions are the supported image types and their extensions
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// parseImages decodes the images in data urls like data:image/png;base64,iVBORw0KGgo...
+func parseImages(dataURLs []string) ([]base.Image, error) {
+	if len(dataURLs) > maxImages {
+		return nil, fmt.Errorf("%w: at most %d images in a query", ErrInvalidImage, maxImages)
+	}
+	images := make([]base.Image, 0, len(dataURLs))
+	for i, u := range dataURLs {
+		header, encoded, ok := strings.Cut(strings.TrimPrefix(u, "data:"), ",")
+		if !ok || !strings.HasPrefix(u, "data:") {
+			return nil, fmt.Errorf("%w: image %d is not a data url", ErrInvalidImage, i+1)
+		}
+		mimeType, ok := strings.CutSuffix(header, ";base64")
+		if !ok {
+			return nil, fmt.Errorf("%w: image %d is not base64 encoded", ErrInvalidImage, i+1)
+		}
+		if _, ok := imageExtensions[mimeType]; !ok {
+			return nil, fmt.Errorf("%w: type %s of image %d is not supported", ErrInvalidImage, mimeType, i+1)
+		}
+		if base64.StdEncoding.DecodedLen(len(encoded)) > maxImageSize {
+			return nil, fmt.Errorf("%w: image %d is larger than %d bytes", ErrInvalidImage, i+1, maxImageSize)
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: image %d: %s", ErrInvalidImage, i+1, err)
+		}
+		images = append(images, base.Image{MIMEType: mimeType, Data: data})
+	}
+	return images, nil
+}
+
+// imageObjectPath is the object name of an image of the conversation in the oss
+func imageObjectPath(appName, conversationID, name string) string {
+	return arcadiav1alpha1.ConversationFilePath(appName, conversationID, path.Join("images", name))
+}
+
+// saveImages stores the images to the system datasource with the files uploaded to the conversation
+func (cs *ChatServer) saveImages(ctx context.Context, conversation *storage.Conversation, images []base.Image) (storage.Images, error) {
+	ds, err := pkgconfig.GetSystemDatasourceOSS(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("no storage service found with err %s", err.Error())
+	}
+	res := make(storage.Images, 0, len(images))
+	for _, image := range images {
+		// use sha256 as the object name so the same image is stored once in a conversation
+		hash := sha256.Sum256(image.Data)
+		name := hex.EncodeToString(hash[:]) + imageExtensions[image.MIMEType]
+		_, err = ds.Client.PutObject(ctx, conversation.AppNamespace, imageObjectPath(conversation.AppName, conversation.ID, name),
+			bytes.NewReader(image.Data), int64(len(image.Data)), minio.PutObjectOptions{ContentType: image.MIMEType})
+		if err != nil {
+			return nil, fmt.Errorf("failed to store image with error %s", err.Error())
+		}
+		res = append(res, storage.Image{Name: name, MIMEType: image.MIMEType})
+	}
+	return res, nil
+}
+
+// loadImages reads the images of a message from the system datasource
+func (cs *ChatServer) loadImages(ctx context.Context, conversation *storage.Conversation, images storage.Images) ([]base.Image, error) {
+	res := make([]base.Image, 0, len(images))
+	for _, image := range images {
+		data, err := cs.readImage(ctx, conversation, image.Name)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, base.Image{MIMEType: image.MIMEType, Data: data})
+	}
+	return res, nil
+}
+
+func (cs *ChatServer) readImage(ctx context.Context, conversation *storage.Conversation, name string) ([]byte, error) {
+	ds, err := pkgconfig.GetSystemDatasourceOSS(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("no storage service found with err %s", err.Error())
+	}
+	object, err := ds.Client.GetObject(ctx, conversation.AppNamespace, imageObjectPath(conversation.AppName, conversation.ID, name), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrImageNotFound
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// GetConversationImage returns an image in the queries of the conversation of current user, to render the messages history
+func (cs *ChatServer) GetConversationImage(ctx context.Context, conversationID, name string) (mimeType string, data []byte, err error) {
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	conversation, err := cs.Storage().FindExistingConversation(conversationID, storage.WithUser(currentUser))
+	if err != nil {
+		return "", nil, err
+	}
+	for _, m := range conversation.Messages {
+		for _, image := range m.Images {
+			if image.Name != name {
+				continue
+			}
+			data, err := cs.readImage(ctx, conversation, name)
+			if err != nil {
+				return "", nil, err
+			}
+			return image.MIMEType, data, nil
+		}
+	}
+	return "", nil, ErrImageNotFound
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseImages(t *testing.T) {
+	images, err := parseImages([]string{"data:image/png;base64,aGVsbG8=", "data:image/jpeg;base64,d29ybGQ="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 2 || images[0].MIMEType != "image/png" || string(images[0].Data) != "hello" || string(images[1].Data) != "world" {
+		t.Errorf("got unexpected images %+v", images)
+	}
+	for _, invalid := range []string{
+		"aGVsbG8=",
+		"data:image/png,aGVsbG8=",
+		"data:image/svg+xml;base64,aGVsbG8=",
+		"data:image/png;base64,not base64",
+		"data:image/png;base64," + strings.Repeat("A", maxImageSize*2),
+	} {
+		if _, err := parseImages([]string{invalid}); !errors.Is(err, ErrInvalidImage) {
+			t.Errorf("parseImages(%.40q) got error %v, want ErrInvalidImage", invalid, err)
+		}
+	}
+	if _, err := parseImages(make([]string, maxImages+1)); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("got error %v for too many images, want ErrInvalidImage", err)
+	}
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/chains"
+	langchaingoembeddings "github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/prompts"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/klog/v2"
+
+	apiagent "github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
+	apichain "github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/pkg/auth"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	"github.com/kubeagi/arcadia/pkg/langchainwrap"
+)
+
+const (
+	// defaultTopKFacts is the max number of facts added to the memory if not set
+	defaultTopKFacts = 5
+	// defaultMaxFacts is the max number of facts remembered for a user if not set
+	defaultMaxFacts = 100
+	// maxFactLength is the max runes of a fact
+	maxFactLength = 200
+	// duplicateFactSimilarity is the min similarity of a new fact to a remembered one to be the same fact
+	duplicateFactSimilarity = 0.9
+	// timeout to extract the facts by llm
+	extractFactsTimeout = 30 * time.Second
+	// the answer of the llm if there is no fact in the conversation
+	noFacts = "NONE"
+)
+
+const PromptForExtractUserFacts = `Extract the facts about the user worth remembering in future conversations from the conversation below, such as the identity, preferences and plans of the user.
+
+Requires language consistent with the conversation, one fact per line, no numbering, output ` + noFacts + ` if there is no such fact.
+---
+Q: {{.query}}
+A: {{.answer}}
+---
+The facts are:`
+
+// appMemory returns the memory config of the chain or agent in the application, nil if not found
+func (cs *ChatServer) appMemory(ctx context.Context, app *v1alpha1.Application) *apichain.Memory {
+	for _, n := range app.Spec.Nodes {
+		baseNode := base.NewBaseNode(app.Namespace, n.Name, *n.Ref)
+		key := types.NamespacedName{Namespace: baseNode.RefNamespace(), Name: baseNode.RefName()}
+		var err error
+		var config *apichain.Memory
+		switch {
+		case baseNode.Group() == "chain" && baseNode.Kind() == "llmchain":
+			ch := &apichain.LLMChain{}
+			err = cs.systemCli.Get(ctx, key, ch)
+			config = &ch.Spec.Memory
+		case baseNode.Group() == "chain" && baseNode.Kind() == "retrievalqachain":
+			ch := &apichain.RetrievalQAChain{}
+			err = cs.systemCli.Get(ctx, key, ch)
+			config = &ch.Spec.Memory
+		case baseNode.Group() == "chain" && baseNode.Kind() == "apichain":
+			ch := &apichain.APIChain{}
+			err = cs.systemCli.Get(ctx, key, ch)
+			config = &ch.Spec.Memory
+		case baseNode.Group() == "" && baseNode.Kind() == "agent":
+			agent := &apiagent.Agent{}
+			err = cs.systemCli.Get(ctx, key, agent)
+			config = &agent.Spec.AgentConfig.Options.Memory
+		default:
+			continue
+		}
+		if err != nil {
+			klog.FromContext(ctx).Error(err, "failed to get the memory config", "node", n.Name)
+			continue
+		}
+		return config
+	}
+	return nil
+}
+
+// longTermMemory remembers the facts about the users with the embedder of an application
+type longTermMemory struct {
+	config   apichain.LongTermMemory
+	embedder langchaingoembeddings.Embedder
+	// embedderName is the embedder in namespace/name, the facts embedded by other embedders are ignored
+	embedderName string
+}
+
+func (cs *ChatServer) newLongTermMemory(ctx context.Context, app *v1alpha1.Application, config *apichain.LongTermMemory) (*longTermMemory, error) {
+	if config.Embedder == nil {
+		return nil, fmt.Errorf("embedder is required by the long-term memory")
+	}
+	embedder := &v1alpha1.Embedder{}
+	if err := cs.systemCli.Get(ctx, types.NamespacedName{Namespace: config.Embedder.GetNamespace(app.Namespace), Name: config.Embedder.Name}, embedder); err != nil {
+		return nil, fmt.Errorf("can't find the embedder of long-term memory: %w", err)
+	}
+	em, err := langchainwrap.GetLangchainEmbedder(ctx, embedder, cs.systemCli, "")
+	if err != nil {
+		return nil, fmt.Errorf("can't convert to langchain embedder: %w", err)
+	}
+	ltm := &longTermMemory{
+		config:       *config,
+		embedder:     em,
+		embedderName: embedder.Namespace + "/" + embedder.Name,
+	}
+	if ltm.config.TopK <= 0 {
+		ltm.config.TopK = defaultTopKFacts
+	}
+	if ltm.config.MaxFacts <= 0 {
+		ltm.config.MaxFacts = defaultMaxFacts
+	}
+	return ltm, nil
+}
+
+// facts returns the remembered facts of the user embedded by the embedder of the memory, the latest first
+func (cs *ChatServer) facts(ltm *longTermMemory, user string) ([]storage.UserMemory, error) {
+	memories, err := cs.Storage().ListUserMemories(storage.WithUser(user))
+	if err != nil {
+		return nil, err
+	}
+	res := memories[:0]
+	for _, m := range memories {
+		if m.Embedder == ltm.embedderName {
+			res = append(res, m)
+		}
+	}
+	return res, nil
+}
+
+// recallUserFacts returns the facts about the user relevant to the query
+func (cs *ChatServer) recallUserFacts(ctx context.Context, ltm *longTermMemory, user, query string) ([]string, error) {
+	memories, err := cs.facts(ltm, user)
+	if err != nil || len(memories) == 0 {
+		return nil, err
+	}
+	vector, err := ltm.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	type scored struct {
+		content string
+		score   float64
+	}
+	relevant := make([]scored, 0, len(memories))
+	for _, m := range memories {
+		if score := cosineSimilarity(vector, m.Vector); score >= ltm.config.SimilarityThreshold {
+			relevant = append(relevant, scored{content: m.Content, score: score})
+		}
+	}
+	sort.SliceStable(relevant, func(i, j int) bool { return relevant[i].score > relevant[j].score })
+	if len(relevant) > ltm.config.TopK {
+		relevant = relevant[:ltm.config.TopK]
+	}
+	facts := make([]string, len(relevant))
+	for i := range relevant {
+		facts[i] = relevant[i].content
+	}
+	return facts, nil
+}
+
+// factsContent is the content of the system message with the facts in the history
+func factsContent(facts []string) string {
+	return "Facts about the user:\n- " + strings.Join(facts, "\n- ")
+}
+
+// rememberUserFacts lets the llm of the application extract the facts about the user from a round of the conversation,
+// and saves the new facts. It runs in the background after the answer is returned.
+func (cs *ChatServer) rememberUserFacts(app *v1alpha1.Application, ltm *longTermMemory, user, conversationID, query, answer string) {
+	ctx, cancel := context.WithTimeout(context.Background(), extractFactsTimeout)
+	defer cancel()
+	ctx, record := cs.recordBackgroundUsage(ctx, app, user)
+	defer record()
+	logger := klog.FromContext(ctx).WithValues("conversationID", conversationID)
+	model, chainOptions, _, err := cs.appModel(ctx, app)
+	if err != nil || model == nil {
+		logger.V(3).Info("no llm in app to extract the facts about the user", "error", err)
+		return
+	}
+	p := prompts.NewPromptTemplate(PromptForExtractUserFacts, []string{"query", "answer"})
+	out, err := chains.Predict(ctx, chains.NewLLMChain(model, p, chainOptions...), map[string]any{
+		"query":  truncate(query, 1000),
+		"answer": truncate(answer, 1000),
+	})
+	if err != nil {
+		logger.Error(err, "failed to extract the facts about the user")
+		return
+	}
+	facts := parseFacts(out)
+	if len(facts) == 0 {
+		return
+	}
+	vectors, err := ltm.embedder.EmbedDocuments(ctx, facts)
+	if err != nil || len(vectors) != len(facts) {
+		logger.Error(err, "failed to embed the facts about the user")
+		return
+	}
+	memories, err := cs.facts(ltm, user)
+	if err != nil {
+		logger.Error(err, "failed to list the facts about the user")
+		return
+	}
+	added := 0
+	for i, fact := range facts {
+		duplicated := false
+		for _, m := range memories {
+			if cosineSimilarity(vectors[i], m.Vector) >= duplicateFactSimilarity {
+				duplicated = true
+				break
+			}
+		}
+		if duplicated {
+			continue
+		}
+		m := storage.UserMemory{
+			ID:             string(uuid.NewUUID()),
+			User:           user,
+			Content:        fact,
+			Embedder:       ltm.embedderName,
+			Vector:         vectors[i],
+			AppName:        app.Name,
+			AppNamespace:   app.Namespace,
+			ConversationID: conversationID,
+		}
+		if err := cs.Storage().AddUserMemory(&m); err != nil {
+			logger.Error(err, "failed to save the fact about the user")
+			return
+		}
+		memories = append(memories, m)
+		added++
+	}
+	logger.V(3).Info("remember the facts about the user", "facts", added)
+	// forget the oldest facts
+	all, err := cs.Storage().ListUserMemories(storage.WithUser(user))
+	if err != nil {
+		logger.Error(err, "failed to list the facts about the user")
+		return
+	}
+	for i := ltm.config.MaxFacts; i < len(all); i++ {
+		if err := cs.Storage().DeleteUserMemory(all[i].ID); err != nil {
+			logger.Error(err, "failed to forget the fact about the user")
+		}
+	}
+}
+
+// parseFacts returns the facts in the answer of the llm, one fact per line
+func parseFacts(out string) []string {
+	facts := make([]string, 0)
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*•"))
+		if line == "" || strings.EqualFold(line, noFacts) {
+			continue
+		}
+		facts = append(facts, truncate(line, maxFactLength))
+	}
+	return facts
+}
+
+// ListUserMemories returns the facts remembered about current user
+func (cs *ChatServer) ListUserMemories(ctx context.Context) ([]storage.UserMemory, error) {
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	memories, err := cs.Storage().ListUserMemories(storage.WithUser(currentUser))
+	if err != nil {
+		return nil, err
+	}
+	if memories == nil {
+		memories = make([]storage.UserMemory, 0)
+	}
+	return memories, nil
+}
+
+// DeleteUserMemory forgets a fact about current user
+func (cs *ChatServer) DeleteUserMemory(ctx context.Context, id string) error {
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	return cs.Storage().DeleteUserMemory(id, storage.WithUser(currentUser))
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
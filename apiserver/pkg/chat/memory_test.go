@@ -0,0 +1,56 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseFacts(t *testing.T) {
+	tests := []struct {
+		out  string
+		want []string
+	}{
+		{out: "NONE", want: []string{}},
+		{out: " none \n", want: []string{}},
+		{out: "- The user is a Go developer\n\n* The user lives in Beijing\n", want: []string{"The user is a Go developer", "The user lives in Beijing"}},
+	}
+	for _, tt := range tests {
+		if got := parseFacts(tt.out); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseFacts(%q) = %q, want %q", tt.out, got, tt.want)
+		}
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b []float32
+		want float64
+	}{
+		{a: []float32{1, 0}, b: []float32{2, 0}, want: 1},
+		{a: []float32{1, 0}, b: []float32{0, 1}, want: 0},
+		{a: []float32{1, 0}, b: []float32{1}, want: 0},
+		{a: []float32{0, 0}, b: []float32{1, 1}, want: 0},
+	}
+	for _, tt := range tests {
+		if got := cosineSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("cosineSimilarity(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+)
+
+const (
+	OpenAIRoleSystem    = "system"
+	OpenAIRoleUser      = "user"
+	OpenAIRoleAssistant = "assistant"
+
+	OpenAIFinishReasonStop = "stop"
+)
+
+// OpenAIChatCompletionReq is the request of OpenAI `/v1/chat/completions`, the model is an application in `namespace/name`
+type OpenAIChatCompletionReq struct {
+	// Model is the application in `namespace/name`, the namespace can be omitted if the api key is in the same namespace
+	Model string `json:"model" binding:"required" example:"arcadia/chat-with-llm"`
+	// Messages are the history and the question, the last one must be from user.
+	// System messages are ignored, as the application has its own prompt.
+	Messages []OpenAIMessage `json:"messages" binding:"required"`
+	// Stream sends the answer in server-sent events
+	Stream bool `json:"stream"`
+	// ConversationID is an extension to continue a conversation stored in arcadia, only the last message is used if set
+	ConversationID string `json:"conversation_id,omitempty" example:"5a41f3ca-763b-41ec-91c3-4bbbb00736d0"`
+}
+
+// OpenAIMessage is a message in the chat
+type OpenAIMessage struct {
+	Role    string        `json:"role" example:"user"`
+	Content OpenAIContent `json:"content" swaggertype:"string" example:"旷工最小计算单位为多少天？"`
+}
+
+// OpenAIContent is the text of a message, which is a string or an array of content parts
+type OpenAIContent string
+
+func (c *OpenAIContent) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = OpenAIContent(text)
+		return nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("content should be a string or an array of content parts: %w", err)
+	}
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p.Type == "text" {
+			texts = append(texts, p.Text)
+		}
+	}
+	*c = OpenAIContent(strings.Join(texts, "\n"))
+	return nil
+}
+
+// ParseModel returns the namespace and name of the application, defaultNamespace is used if the model has no namespace
+func (req OpenAIChatCompletionReq) ParseModel(defaultNamespace string) (namespace, name string, err error) {
+	return ParseOpenAIModel(req.Model, defaultNamespace)
+}
+
+// ParseOpenAIModel returns the namespace and name of the application from the model `namespace/name`
+func ParseOpenAIModel(model, defaultNamespace string) (namespace, name string, err error) {
+	namespace, name, ok := strings.Cut(model, "/")
+	if !ok {
+		namespace, name = defaultNamespace, model
+	}
+	if namespace == "" || name == "" {
+		return "", "", fmt.Errorf("model should be an application in namespace/name, got %q", model)
+	}
+	return namespace, name, nil
+}
+
+// ChatReqBody converts the request to a chat request, the last message is the query and the others are the history
+func (req OpenAIChatCompletionReq) ChatReqBody(defaultNamespace string) (ChatReqBody, error) {
+	namespace, name, err := req.ParseModel(defaultNamespace)
+	if err != nil {
+		return ChatReqBody{}, err
+	}
+	if len(req.Messages) == 0 || req.Messages[len(req.Messages)-1].Role != OpenAIRoleUser {
+		return ChatReqBody{}, errors.New("the last message should be from user")
+	}
+	chatReq := ChatReqBody{
+		Query:        string(req.Messages[len(req.Messages)-1].Content),
+		ResponseMode: Blocking,
+		ConversationReqBody: ConversationReqBody{
+			APPMetadata:    APPMetadata{APPName: name, AppNamespace: namespace},
+			ConversationID: req.ConversationID,
+		},
+		NewChat:       req.ConversationID == "",
+		StartTime:     time.Now(),
+		NoSuggestions: true,
+	}
+	if req.Stream {
+		chatReq.ResponseMode = Streaming
+	}
+	if chatReq.NewChat {
+		chatReq.History = make([]schema.ChatMessage, 0, len(req.Messages)-1)
+		for _, m := range req.Messages[:len(req.Messages)-1] {
+			switch m.Role {
+			case OpenAIRoleUser:
+				chatReq.History = append(chatReq.History, schema.HumanChatMessage{Content: string(m.Content)})
+			case OpenAIRoleAssistant:
+				chatReq.History = append(chatReq.History, schema.AIChatMessage{Content: string(m.Content)})
+			}
+		}
+	}
+	return chatReq, nil
+}
+
+// OpenAIChatCompletion is the response of `/v1/chat/completions`, and the chunk in streaming mode
+type OpenAIChatCompletion struct {
+	ID      string         `json:"id" example:"chatcmpl-4f3546dd-5404-4bf8-a3bc-4fa3f9a7ba24"`
+	Object  string         `json:"object" example:"chat.completion"`
+	Created int64          `json:"created" example:"1703125266"`
+	Model   string         `json:"model" example:"arcadia/chat-with-llm"`
+	Choices []OpenAIChoice `json:"choices"`
+	Usage   *OpenAIUsage   `json:"usage,omitempty"`
+	// ConversationID is an extension to continue the conversation
+	ConversationID string `json:"conversation_id,omitempty" example:"5a41f3ca-763b-41ec-91c3-4bbbb00736d0"`
+	// References is an extension of the references of the answer
+	References []retriever.Reference `json:"references,omitempty"`
+}
+
+type OpenAIChoice struct {
+	Index int `json:"index"`
+	// Message is the answer in blocking mode
+	Message *OpenAIMessage `json:"message,omitempty"`
+	// Delta is a piece of the answer in streaming mode
+	Delta        *OpenAIDelta `json:"delta,omitempty"`
+	FinishReason *string      `json:"finish_reason"`
+}
+
+type OpenAIDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+type OpenAIUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+// OpenAIModel is an application which can be used as the model
+type OpenAIModel struct {
+	ID      string `json:"id" example:"arcadia/chat-with-llm"`
+	Object  string `json:"object" example:"model"`
+	Created int64  `json:"created" example:"1703125266"`
+	OwnedBy string `json:"owned_by" example:"arcadia"`
+}
+
+type OpenAIModelList struct {
+	Object string        `json:"object" example:"list"`
+	Data   []OpenAIModel `json:"data"`
+}
+
+// OpenAIErrorResp is the error in OpenAI format
+type OpenAIErrorResp struct {
+	Error OpenAIError `json:"error"`
+}
+
+type OpenAIError struct {
+	Message string `json:"message" example:"application not ready"`
+	Type    string `json:"type" example:"invalid_request_error"`
+}
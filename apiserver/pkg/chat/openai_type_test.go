@@ -0,0 +1,104 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/tmc/langchaingo/schema"
+)
+
+func TestOpenAIContentUnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		data     string
+		expected OpenAIContent
+		wantErr  bool
+	}{
+		{data: `"hello"`, expected: "hello"},
+		{data: `[{"type":"text","text":"hello"},{"type":"image_url","image_url":{"url":"x"}},{"type":"text","text":"world"}]`, expected: "hello\nworld"},
+		{data: `123`, wantErr: true},
+	}
+	for _, tc := range testCases {
+		var content OpenAIContent
+		err := json.Unmarshal([]byte(tc.data), &content)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("unmarshal %s: unexpected error %v", tc.data, err)
+		}
+		if !tc.wantErr && content != tc.expected {
+			t.Fatalf("unmarshal %s: expected %q, got %q", tc.data, tc.expected, content)
+		}
+	}
+}
+
+func TestParseOpenAIModel(t *testing.T) {
+	testCases := []struct {
+		model, defaultNamespace string
+		namespace, name         string
+		wantErr                 bool
+	}{
+		{model: "arcadia/app", defaultNamespace: "default", namespace: "arcadia", name: "app"},
+		{model: "app", defaultNamespace: "default", namespace: "default", name: "app"},
+		{model: "app", wantErr: true},
+		{model: "arcadia/", defaultNamespace: "default", wantErr: true},
+	}
+	for _, tc := range testCases {
+		namespace, name, err := ParseOpenAIModel(tc.model, tc.defaultNamespace)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("parse %s: unexpected error %v", tc.model, err)
+		}
+		if namespace != tc.namespace || name != tc.name {
+			t.Fatalf("parse %s: expected %s/%s, got %s/%s", tc.model, tc.namespace, tc.name, namespace, name)
+		}
+	}
+}
+
+func TestOpenAIChatCompletionReqChatReqBody(t *testing.T) {
+	req := OpenAIChatCompletionReq{
+		Model: "arcadia/app",
+		Messages: []OpenAIMessage{
+			{Role: OpenAIRoleSystem, Content: "be nice"},
+			{Role: OpenAIRoleUser, Content: "hi"},
+			{Role: OpenAIRoleAssistant, Content: "hello"},
+			{Role: OpenAIRoleUser, Content: "how are you"},
+		},
+		Stream: true,
+	}
+	body, err := req.ChatReqBody("")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if body.Query != "how are you" || body.APPName != "app" || body.AppNamespace != "arcadia" || !body.NewChat || body.ResponseMode != Streaming {
+		t.Fatalf("unexpected body %+v", body)
+	}
+	expected := []schema.ChatMessage{schema.HumanChatMessage{Content: "hi"}, schema.AIChatMessage{Content: "hello"}}
+	if !reflect.DeepEqual(body.History, expected) {
+		t.Fatalf("expected history %v, got %v", expected, body.History)
+	}
+
+	// only the query is used when continuing a stored conversation
+	req.ConversationID = "c1"
+	if body, err = req.ChatReqBody(""); err != nil || body.NewChat || body.History != nil {
+		t.Fatalf("unexpected body %+v, error %v", body, err)
+	}
+
+	req.Messages = req.Messages[:3]
+	if _, err = req.ChatReqBody(""); err == nil {
+		t.Fatal("expected error when the last message is not from user")
+	}
+}
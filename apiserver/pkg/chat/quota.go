@@ -0,0 +1,129 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	"github.com/kubeagi/arcadia/pkg/tokenusage"
+)
+
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// QuotaExceededError is returned when the daily usage of the user or the app reaches the quota
+type QuotaExceededError struct {
+	// Scope is "user" or "app"
+	Scope string
+	// Resource is "tokens" or "requests"
+	Resource string
+	Used     int64
+	Limit    int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota exceeded: the %s has used %d of %d daily %s, please try again tomorrow", e.Scope, e.Used, e.Limit, e.Resource)
+}
+
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// now is replaced in tests
+var now = time.Now
+
+// today returns the date of token usages
+func today() string {
+	return now().Format(time.DateOnly)
+}
+
+// CheckQuota returns QuotaExceededError if the daily usage of the user or the app reaches the quota of the app.
+// The quota is soft, the usage is only recorded after a request is done,
+// so concurrent requests which pass the check together may exceed the quota.
+func (cs *ChatServer) CheckQuota(app *v1alpha1.Application, user string) error {
+	quota := app.Spec.Quota
+	if quota == nil || (quota.PerUser == nil && quota.PerApp == nil) {
+		return nil
+	}
+	date := today()
+	usages, err := cs.Storage().ListTokenUsages(storage.WithAppNamespace(app.Namespace), storage.WithAppName(app.Name), storage.WithDateRange(date, date))
+	if err != nil {
+		return fmt.Errorf("failed to get token usages: %w", err)
+	}
+	var appUsage, userUsage storage.TokenUsage
+	for _, u := range usages {
+		appUsage.TotalTokens += u.TotalTokens
+		appUsage.Requests += u.Requests
+		if u.User == user {
+			userUsage.TotalTokens += u.TotalTokens
+			userUsage.Requests += u.Requests
+		}
+	}
+	if err := checkQuotaLimit("user", quota.PerUser, userUsage); err != nil {
+		return err
+	}
+	return checkQuotaLimit("app", quota.PerApp, appUsage)
+}
+
+func checkQuotaLimit(scope string, limit *v1alpha1.QuotaLimit, usage storage.TokenUsage) error {
+	if limit == nil {
+		return nil
+	}
+	if limit.DailyRequests > 0 && usage.Requests >= limit.DailyRequests {
+		return &QuotaExceededError{Scope: scope, Resource: "requests", Used: usage.Requests, Limit: limit.DailyRequests}
+	}
+	if limit.DailyTokens > 0 && usage.TotalTokens >= limit.DailyTokens {
+		return &QuotaExceededError{Scope: scope, Resource: "tokens", Used: usage.TotalTokens, Limit: limit.DailyTokens}
+	}
+	return nil
+}
+
+// recordTokenUsage adds the usage to the daily usage of the user in the app,
+// requests is 0 for the llm calls in background which are not chat requests, such as generating the title
+func (cs *ChatServer) recordTokenUsage(ctx context.Context, app APPMetadata, user string, total tokenusage.Usage, requests int64) {
+	if requests == 0 && total.TotalTokens == 0 {
+		return
+	}
+	err := cs.Storage().AddTokenUsage(storage.TokenUsage{
+		Date:             today(),
+		AppNamespace:     app.AppNamespace,
+		AppName:          app.APPName,
+		User:             user,
+		PromptTokens:     total.PromptTokens,
+		CompletionTokens: total.CompletionTokens,
+		TotalTokens:      total.TotalTokens,
+		Requests:         requests,
+	})
+	if err != nil {
+		klog.FromContext(ctx).Error(err, "failed to record token usage", "appName", app.APPName, "appNamespace", app.AppNamespace)
+	}
+}
+
+// recordBackgroundUsage returns a context recording the tokens used by the llm calls in background,
+// and a function to add them to the daily usage of the user in the app when the calls are done
+func (cs *ChatServer) recordBackgroundUsage(ctx context.Context, app *v1alpha1.Application, user string) (context.Context, func()) {
+	recorder := tokenusage.NewRecorder()
+	return tokenusage.NewContext(ctx, recorder), func() {
+		cs.recordTokenUsage(ctx, APPMetadata{APPName: app.Name, AppNamespace: app.Namespace}, user, recorder.Total(), 0)
+	}
+}
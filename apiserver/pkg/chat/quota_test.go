@@ -0,0 +1,159 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	"github.com/kubeagi/arcadia/pkg/tokenusage"
+)
+
+func TestCheckQuotaLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    *v1alpha1.QuotaLimit
+		usage    storage.TokenUsage
+		resource string
+	}{
+		{"no limit", nil, storage.TokenUsage{Requests: 100, TotalTokens: 100}, ""},
+		{"zero means no limit", &v1alpha1.QuotaLimit{}, storage.TokenUsage{Requests: 100, TotalTokens: 100}, ""},
+		{"under the limits", &v1alpha1.QuotaLimit{DailyRequests: 10, DailyTokens: 100}, storage.TokenUsage{Requests: 9, TotalTokens: 99}, ""},
+		{"requests reach the limit", &v1alpha1.QuotaLimit{DailyRequests: 10, DailyTokens: 100}, storage.TokenUsage{Requests: 10, TotalTokens: 0}, "requests"},
+		{"tokens reach the limit", &v1alpha1.QuotaLimit{DailyRequests: 10, DailyTokens: 100}, storage.TokenUsage{Requests: 1, TotalTokens: 100}, "tokens"},
+		{"tokens exceed the limit", &v1alpha1.QuotaLimit{DailyTokens: 100}, storage.TokenUsage{Requests: 1, TotalTokens: 150}, "tokens"},
+	}
+	for _, test := range tests {
+		err := checkQuotaLimit("user", test.limit, test.usage)
+		if test.resource == "" {
+			if err != nil {
+				t.Errorf("%s: expect no error, got %v", test.name, err)
+			}
+			continue
+		}
+		quotaErr := &QuotaExceededError{}
+		if !errors.As(err, &quotaErr) || quotaErr.Resource != test.resource || quotaErr.Scope != "user" || !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("%s: expect %s exceeded, got %v", test.name, test.resource, err)
+		}
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	defer func() { now = time.Now }()
+	day := time.Date(2024, 5, 1, 23, 59, 0, 0, time.Local)
+	now = func() time.Time { return day }
+
+	cs := &ChatServer{storage: storage.NewMemoryStorage()}
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: "app"},
+		Spec: v1alpha1.ApplicationSpec{Quota: &v1alpha1.Quota{
+			PerUser: &v1alpha1.QuotaLimit{DailyRequests: 2},
+			PerApp:  &v1alpha1.QuotaLimit{DailyTokens: 100},
+		}},
+	}
+	record := func(namespace, name, user string, tokens int64) {
+		cs.recordTokenUsage(context.Background(), APPMetadata{AppNamespace: namespace, APPName: name}, user, tokenusage.Usage{TotalTokens: tokens}, 1)
+	}
+	scope := func(err error) string {
+		quotaErr := &QuotaExceededError{}
+		if errors.As(err, &quotaErr) {
+			return quotaErr.Scope
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ""
+	}
+
+	record("arcadia", "app", "alice", 10)
+	record("arcadia", "app", "alice", 10)
+	if got := scope(cs.CheckQuota(app, "alice")); got != "user" {
+		t.Errorf("alice should exceed the per user quota, got %q", got)
+	}
+	if got := scope(cs.CheckQuota(app, "bob")); got != "" {
+		t.Errorf("bob should not be limited by the usage of alice, got %q", got)
+	}
+	// the usage of other apps doesn't count
+	record("arcadia", "other", "bob", 1000)
+	record("default", "app", "bob", 1000)
+	if got := scope(cs.CheckQuota(app, "bob")); got != "" {
+		t.Errorf("bob should not be limited by the usage of other apps, got %q", got)
+	}
+	record("arcadia", "app", "bob", 80)
+	if got := scope(cs.CheckQuota(app, "bob")); got != "app" {
+		t.Errorf("bob should exceed the per app quota, got %q", got)
+	}
+
+	// the usage is reset in the next day
+	day = day.Add(2 * time.Minute)
+	for _, user := range []string{"alice", "bob"} {
+		if got := scope(cs.CheckQuota(app, user)); got != "" {
+			t.Errorf("%s should not be limited in the next day, got %q", user, got)
+		}
+	}
+	record("arcadia", "app", "alice", 10)
+	if got := scope(cs.CheckQuota(app, "alice")); got != "" {
+		t.Errorf("alice should not be limited after 1 request in the next day, got %q", got)
+	}
+
+	app.Spec.Quota = nil
+	record("arcadia", "app", "alice", 1000)
+	if got := scope(cs.CheckQuota(app, "alice")); got != "" {
+		t.Errorf("no quota should not limit alice, got %q", got)
+	}
+}
+
+func TestRecordBackgroundUsage(t *testing.T) {
+	cs := &ChatServer{storage: storage.NewMemoryStorage()}
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: "app"},
+		Spec: v1alpha1.ApplicationSpec{Quota: &v1alpha1.Quota{
+			PerUser: &v1alpha1.QuotaLimit{DailyRequests: 1, DailyTokens: 100},
+		}},
+	}
+	// no tokens used, nothing recorded
+	_, record := cs.recordBackgroundUsage(context.Background(), app, "alice")
+	record()
+	usages, err := cs.Storage().ListTokenUsages(storage.WithAppNamespace("arcadia"), storage.WithAppName("app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usages) != 0 {
+		t.Errorf("expect no usage recorded, got %+v", usages)
+	}
+
+	ctx, record := cs.recordBackgroundUsage(context.Background(), app, "alice")
+	tokenusage.Record(ctx, tokenusage.Usage{PromptTokens: 60, CompletionTokens: 10, TotalTokens: 70})
+	record()
+	// the tokens count toward the quota, but not as a request
+	if err := cs.CheckQuota(app, "alice"); err != nil {
+		t.Errorf("expect the background llm calls not counted as requests, got %v", err)
+	}
+	ctx, record = cs.recordBackgroundUsage(context.Background(), app, "alice")
+	tokenusage.Record(ctx, tokenusage.Usage{PromptTokens: 30, CompletionTokens: 10, TotalTokens: 40})
+	record()
+	quotaErr := &QuotaExceededError{}
+	if err := cs.CheckQuota(app, "alice"); !errors.As(err, &quotaErr) || quotaErr.Resource != "tokens" || quotaErr.Used != 110 {
+		t.Errorf("expect the tokens of the background llm calls exceed the quota, got %v", err)
+	}
+}
@@ -19,7 +19,12 @@ package chat
 import (
 	"time"
 
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
 	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+	"github.com/kubeagi/arcadia/pkg/tokenusage"
 )
 
 type ResponseMode string
@@ -42,6 +47,28 @@ type APPMetadata struct {
 	AppNamespace string `json:"-" form:"-"`
 }
 
+type ListConversationReqBody struct {
+	APPMetadata `json:",inline" form:",inline"`
+	// Keyword searches the title, queries and answers of conversations
+	Keyword string `json:"keyword" form:"keyword" example:"旷工"`
+	// Page starts from 1, works with PageSize
+	Page int `json:"page" form:"page" example:"1"`
+	// PageSize is the number of conversations in a page, 0 means all conversations
+	PageSize int `json:"page_size" form:"page_size" example:"20"`
+}
+
+type UpdateConversationReqBody struct {
+	// Title renames the conversation if set
+	Title *string `json:"title,omitempty" example:"旷工计算单位"`
+	// Pinned pins or unpins the conversation if set
+	Pinned *bool `json:"pinned,omitempty" example:"true"`
+}
+
+type CreateShareReqBody struct {
+	// ExpireDays is the days the share link is valid for, 0 means never expire
+	ExpireDays int `json:"expire_days,omitempty" binding:"min=0" example:"7"`
+}
+
 type ConversationReqBody struct {
 	APPMetadata `json:",inline" form:",inline"`
 	// ConversationID, if it is empty, a new conversation will be created
@@ -66,6 +93,8 @@ type ChatReqBody struct {
 	Query string `json:"query" form:"query" binding:"required" example:"旷工最小计算单位为多少天？"`
 	// Files this conversation will use in the context
 	Files []string `json:"files" form:"files" example:"test.pdf,song.mp3"`
+	// Images in the query, data urls of base64 encoded png, jpeg, gif or webp images
+	Images []string `json:"images" form:"images" example:"data:image/png;base64,iVBORw0KGgo..."`
 	// ResponseMode:
 	// * Blocking - means the response is returned in a blocking manner
 	// * Streaming - means the response will use Server-Sent Events
@@ -74,6 +103,33 @@ type ChatReqBody struct {
 	Debug               bool      `json:"-"`
 	NewChat             bool      `json:"-"`
 	StartTime           time.Time `json:"-"`
+	// History of a new chat, used by the OpenAI compatible api which sends the whole history in each request
+	History []schema.ChatMessage `json:"-"`
+	// Regenerate answers the query of an existing message again, the message id is the one to regenerate
+	Regenerate bool `json:"-"`
+	// NoSuggestions skips the suggested follow-up questions even if the application shows them,
+	// used by the OpenAI compatible api which can't return them
+	NoSuggestions bool `json:"-"`
+}
+
+type RegenerateReqBody struct {
+	MessageReqBody `json:",inline"`
+	// ResponseMode:
+	// * Blocking - means the response is returned in a blocking manner
+	// * Streaming - means the response will use Server-Sent Events
+	ResponseMode ResponseMode `json:"response_mode" binding:"required" example:"blocking"`
+}
+
+type FeedbackReqBody struct {
+	MessageReqBody `json:",inline"`
+	// Rating of the answer, like or dislike, empty means to remove the feedback
+	Rating storage.FeedbackRating `json:"rating" binding:"omitempty,oneof=like dislike" example:"dislike"`
+	// Category of the feedback, such as inaccurate, irrelevant, incomplete, harmful and so on
+	Category string `json:"category" example:"inaccurate"`
+	// Comment is free-text
+	Comment string `json:"comment" example:"should be 0.5 day"`
+	// ExpectedAnswer is the answer the user expected, used as the ground truth when exported to evaluation datasets
+	ExpectedAnswer string `json:"expected_answer" example:"旷工最小计算单位为0.5天。"`
 }
 
 type ChatRespBody struct {
@@ -89,8 +145,21 @@ type ChatRespBody struct {
 	References []retriever.Reference `json:"references,omitempty"`
 	// Latency(ms) is how much time the server cost to process a certain request.
 	Latency int64 `json:"latency,omitempty" example:"1000"`
-	// Documents in this chat
+	// Usage is the tokens used by all models in this chat
+	Usage *tokenusage.Usage `json:"usage,omitempty"`
+	// Version of the answer, increases by one after each regeneration
+	Version int `json:"version,omitempty" example:"1"`
+	// SuggestedQuestions are the follow-up questions suggested after the answer, only if the application shows the next guide
+	SuggestedQuestions []string `json:"suggested_questions,omitempty" example:"病假的最小计算单位是多少天？"`
+	// PendingHuman is true if the conversation is waiting for human operators, whose replies are sent in human_reply events
+	// in streaming mode and added to the messages history
+	PendingHuman bool `json:"pending_human,omitempty" example:"false"`
+	// Handoff is told to the user when the conversation is handed off to human operators in this chat
+	Handoff string `json:"handoff,omitempty" example:"Your conversation has been transferred to a human agent, please wait for the reply."`
+	// Document is the first document uploaded in this chat
 	Document DocumentRespBody `json:"document,omitempty"`
+	// Documents are all the documents uploaded in this chat
+	Documents []DocumentRespBody `json:"documents,omitempty"`
 }
 
 type DocumentRespBody struct {
@@ -99,6 +168,40 @@ type DocumentRespBody struct {
 	Object string `json:"object,omitempty" example:"application/base-chat-document-assistant/conversation/f54f5122-28fb-474e-8593-39f5b3760eaa/90fe100fb9ee6e6cb9ccb091fd91b6264f5f5444dea6d93b3c8ed7418e20c37d.pdf"`
 }
 
+// ConversationFile is a file uploaded to the conversation and its process state in the conversation knowledgebase
+type ConversationFile struct {
+	DocumentRespBody `json:",inline"`
+	// MessageID is the upload message of the file
+	MessageID string `json:"message_id" example:"4f3546dd-5404-4bf8-a3bc-4fa3f9a7ba24"`
+	// Phase is the process phase of the file, the file can be used in the chat after it succeeded
+	Phase v1alpha1.FileProcessPhase `json:"phase" example:"Succeeded"`
+	// ErrMessage is why the file failed to be processed
+	ErrMessage string `json:"err_message,omitempty"`
+	// Chunks is the number of the chunks embedded from the file
+	Chunks int `json:"chunks,omitempty" example:"10"`
+}
+
+type ListHandoffsReqBody struct {
+	APPName      string                `json:"app_name" form:"app_name" example:"chat-with-llm"`
+	AppNamespace string                `json:"-"`
+	Status       storage.HandoffStatus `json:"status" form:"status" example:"pending"`
+}
+
+type HandoffReplyReqBody struct {
+	// Content is the reply of the human operator
+	Content string `json:"content" binding:"required" example:"旷工最小计算单位为0.5天。"`
+}
+
+type ListGuardrailViolationsReqBody struct {
+	APPName        string `json:"app_name" form:"app_name" example:"chat-with-llm"`
+	AppNamespace   string `json:"-"`
+	ConversationID string `json:"conversation_id" form:"conversation_id" example:"5a41f3ca-763b-41ec-91c3-4bbbb00736d0"`
+	// Page starts from 1, works with PageSize
+	Page int `json:"page" form:"page" example:"1"`
+	// PageSize is the number of violations in a page, 0 means all violations
+	PageSize int `json:"page_size" form:"page_size" example:"20"`
+}
+
 type ErrorResp struct {
 	Err string `json:"error" example:"conversation is not found"`
 }
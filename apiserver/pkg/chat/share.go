@@ -0,0 +1,154 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/config"
+	"github.com/kubeagi/arcadia/apiserver/pkg/auth"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	"github.com/kubeagi/arcadia/apiserver/pkg/common"
+)
+
+// shareTokenBytes is the random bytes of a share token
+const shareTokenBytes = 32
+
+var (
+	ErrAppNotPublic = errors.New("only conversations of public applications can be shared")
+	ErrShareExpired = errors.New("share is expired")
+)
+
+// newShareToken returns an unguessable url-safe token
+func newShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// isAppPublic returns true if the application exists and is public
+func (cs *ChatServer) isAppPublic(ctx context.Context, appName, appNamespace string) (bool, error) {
+	app := &v1alpha1.Application{}
+	if err := cs.systemCli.Get(ctx, types.NamespacedName{Namespace: appNamespace, Name: appName}, app); err != nil {
+		return false, fmt.Errorf("failed to get application: %w", err)
+	}
+	return app.Spec.IsPublic, nil
+}
+
+// CreateShare snapshots the conversation of current user with its messages and references into a share
+func (cs *ChatServer) CreateShare(ctx context.Context, conversationID string, req CreateShareReqBody) (*storage.Share, error) {
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	c, err := cs.Storage().FindExistingConversation(conversationID, storage.WithUser(currentUser))
+	if err != nil {
+		return nil, err
+	}
+	public, err := cs.isAppPublic(ctx, c.AppName, c.AppNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if !public {
+		return nil, ErrAppNotPublic
+	}
+	// deep copy the conversation, so the snapshot won't be changed by the later chats
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := storage.SharedConversation{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, err
+	}
+	for i := range snapshot.Messages {
+		snapshot.Messages[i].Feedback = nil
+		snapshot.Messages[i].Alternates = nil
+	}
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	share := &storage.Share{
+		Token:          token,
+		ConversationID: c.ID,
+		AppName:        c.AppName,
+		AppNamespace:   c.AppNamespace,
+		User:           currentUser,
+		Conversation:   snapshot,
+	}
+	if req.ExpireDays > 0 {
+		expiresAt := now().AddDate(0, 0, req.ExpireDays)
+		share.ExpiresAt = &expiresAt
+	}
+	if err := cs.Storage().CreateShare(share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// ListShares returns the shares of current user, only the shares of the conversation if conversationID is not empty
+func (cs *ChatServer) ListShares(ctx context.Context, conversationID string) ([]storage.Share, error) {
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	opts := []storage.SearchOption{storage.WithUser(currentUser)}
+	if conversationID != "" {
+		opts = append(opts, storage.WithConversationID(conversationID))
+	}
+	shares, err := cs.Storage().ListShares(opts...)
+	if err != nil {
+		return nil, err
+	}
+	// the snapshots are not needed in the list
+	for i := range shares {
+		shares[i].Conversation = storage.SharedConversation{ID: shares[i].ConversationID, Title: shares[i].Conversation.Title}
+	}
+	return shares, nil
+}
+
+// RevokeShare deletes the share of current user
+func (cs *ChatServer) RevokeShare(ctx context.Context, token string) error {
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	return cs.Storage().DeleteShare(token, storage.WithUser(currentUser))
+}
+
+// GetShare returns the shared snapshot to anyone with the token.
+// It fails if the share is expired or the application is no longer public.
+func (cs *ChatServer) GetShare(ctx context.Context, token string) (*storage.Share, error) {
+	share, err := cs.Storage().FindShare(token)
+	if err != nil {
+		return nil, err
+	}
+	if share.Expired(now()) {
+		return nil, ErrShareExpired
+	}
+	public, err := cs.isAppPublic(ctx, share.AppName, share.AppNamespace)
+	if err != nil || !public {
+		// don't tell anonymous users the details of the application
+		return nil, storage.ErrShareNotFound
+	}
+	app := &v1alpha1.Application{}
+	app.Name, app.Namespace = share.AppName, share.AppNamespace
+	share.Conversation.Icon = common.AppIconLink(app, config.GetConfig().PlaygroundEndpointPrefix)
+	return share, nil
+}
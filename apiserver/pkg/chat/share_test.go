@@ -0,0 +1,164 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/pkg/auth"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	pkgclient "github.com/kubeagi/arcadia/apiserver/pkg/client"
+)
+
+func newShareServer(t *testing.T, public bool) (*ChatServer, *v1alpha1.Application) {
+	t.Helper()
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: "app"},
+		Spec:       v1alpha1.ApplicationSpec{IsPublic: public},
+	}
+	cs := &ChatServer{
+		systemCli: fake.NewClientBuilder().WithScheme(pkgclient.Scheme).WithObjects(app).Build(),
+		storage:   storage.NewMemoryStorage(),
+	}
+	conversation := feedbackConversation()
+	conversation.Messages[1].Alternates = storage.Alternates{{Answer: "a2 old"}}
+	if err := cs.Storage().UpdateConversation(conversation); err != nil {
+		t.Fatal(err)
+	}
+	return cs, app
+}
+
+func TestCreateShare(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.UserNameContextKey, "alice")
+	cs, _ := newShareServer(t, false)
+	if _, err := cs.CreateShare(ctx, "conversation", CreateShareReqBody{}); !errors.Is(err, ErrAppNotPublic) {
+		t.Errorf("expect the app not public, got %v", err)
+	}
+
+	cs, _ = newShareServer(t, true)
+	other := context.WithValue(context.Background(), auth.UserNameContextKey, "bob")
+	if _, err := cs.CreateShare(other, "conversation", CreateShareReqBody{}); !errors.Is(err, storage.ErrConversationNotFound) {
+		t.Errorf("expect only the user of the conversation can share it, got %v", err)
+	}
+	share, err := cs.CreateShare(ctx, "conversation", CreateShareReqBody{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(share.Token) < 40 || share.ExpiresAt != nil || share.User != "alice" {
+		t.Errorf("unexpected share %+v", share)
+	}
+	if len(share.Conversation.Messages) != 2 || share.Conversation.Messages[1].Alternates != nil {
+		t.Errorf("expect the messages without alternates, got %+v", share.Conversation.Messages)
+	}
+	another, err := cs.CreateShare(ctx, "conversation", CreateShareReqBody{ExpireDays: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if another.Token == share.Token || another.ExpiresAt == nil {
+		t.Errorf("expect another share which expires, got %+v", another)
+	}
+	shares, err := cs.ListShares(ctx, "conversation")
+	if err != nil || len(shares) != 2 {
+		t.Errorf("expect 2 shares, got %v %v", shares, err)
+	}
+	if shares, _ = cs.ListShares(other, ""); len(shares) != 0 {
+		t.Errorf("expect no shares of bob, got %v", shares)
+	}
+}
+
+func TestGetShare(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.UserNameContextKey, "alice")
+	anonymous := context.Background()
+	cs, app := newShareServer(t, true)
+	share, err := cs.CreateShare(ctx, "conversation", CreateShareReqBody{ExpireDays: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := cs.GetShare(anonymous, share.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Conversation.Messages[1].Answer != "a2" || got.Conversation.Icon == "" {
+		t.Errorf("unexpected shared conversation %+v", got.Conversation)
+	}
+	if _, err = cs.GetShare(anonymous, "unknown"); !errors.Is(err, storage.ErrShareNotFound) {
+		t.Errorf("expect not found, got %v", err)
+	}
+
+	// the snapshot survives the deletion of the conversation
+	if err = cs.Storage().Delete(storage.WithConversationID("conversation"), storage.WithUser("alice")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cs.Storage().FindExistingConversation("conversation"); !errors.Is(err, storage.ErrConversationNotFound) {
+		t.Fatalf("expect the conversation deleted, got %v", err)
+	}
+	if got, err = cs.GetShare(anonymous, share.Token); err != nil || len(got.Conversation.Messages) != 2 {
+		t.Errorf("expect the snapshot after the conversation deleted, got %+v %v", got, err)
+	}
+
+	// expired the next day
+	origin := now
+	t.Cleanup(func() { now = origin })
+	now = func() time.Time { return origin().AddDate(0, 0, 1) }
+	if _, err = cs.GetShare(anonymous, share.Token); !errors.Is(err, ErrShareExpired) {
+		t.Errorf("expect expired, got %v", err)
+	}
+	now = origin
+
+	// not found if the app is not public any more
+	app.Spec.IsPublic = false
+	if err = cs.systemCli.Update(ctx, app); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cs.GetShare(anonymous, share.Token); !errors.Is(err, storage.ErrShareNotFound) {
+		t.Errorf("expect not found, got %v", err)
+	}
+}
+
+func TestRevokeShare(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.UserNameContextKey, "alice")
+	cs, _ := newShareServer(t, true)
+	share, err := cs.CreateShare(ctx, "conversation", CreateShareReqBody{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the creator can revoke the share
+	other := context.WithValue(context.Background(), auth.UserNameContextKey, "bob")
+	if err = cs.RevokeShare(other, share.Token); !errors.Is(err, storage.ErrShareNotFound) {
+		t.Errorf("expect not found, got %v", err)
+	}
+	if _, err = cs.GetShare(context.Background(), share.Token); err != nil {
+		t.Errorf("expect the share still valid, got %v", err)
+	}
+	if err = cs.RevokeShare(ctx, share.Token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cs.GetShare(context.Background(), share.Token); !errors.Is(err, storage.ErrShareNotFound) {
+		t.Errorf("expect not found after revoked, got %v", err)
+	}
+	if err = cs.RevokeShare(ctx, share.Token); !errors.Is(err, storage.ErrShareNotFound) {
+		t.Errorf("expect not found after revoked, got %v", err)
+	}
+}
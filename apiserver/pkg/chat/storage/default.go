@@ -0,0 +1,71 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/klog/v2"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	pkgconfig "github.com/kubeagi/arcadia/pkg/config"
+	"github.com/kubeagi/arcadia/pkg/datasource"
+)
+
+var (
+	defaultStorage Storage
+	defaultOnce    sync.Once
+)
+
+// Default returns the storage shared in the process, it uses the relational datasource in the system config,
+// and falls back to memory storage if there is none.
+func Default(ctx context.Context, cli runtimeclient.Client) Storage {
+	defaultOnce.Do(func() {
+		defaultStorage = newStorage(ctx, cli)
+	})
+	return defaultStorage
+}
+
+func newStorage(ctx context.Context, cli runtimeclient.Client) Storage {
+	ds, err := pkgconfig.GetRelationalDatasource(ctx)
+	if err != nil || ds == nil {
+		if err != nil {
+			klog.Infof("get relational datasource failed: %s, use memory storage for chat", err.Error())
+		} else if ds == nil {
+			klog.Infoln("no relational datasource found, use memory storage for chat")
+		}
+		return NewMemoryStorage()
+	}
+	pg, err := datasource.GetPostgreSQLPool(ctx, cli, ds)
+	if err != nil {
+		klog.Errorf("get postgresql pool failed : %s", err.Error())
+		return NewMemoryStorage()
+	}
+	conn, err := pg.Pool.Acquire(ctx)
+	if err != nil {
+		klog.Errorf("postgresql pool acquire failed : %s", err.Error())
+		return NewMemoryStorage()
+	}
+	db, err := NewPostgreSQLStorage(conn.Conn())
+	if err != nil {
+		klog.Errorf("storage.NewPostgreSQLStorage failed : %s", err.Error())
+		return NewMemoryStorage()
+	}
+	klog.Infoln("use pg as chat storage.")
+	return db
+}
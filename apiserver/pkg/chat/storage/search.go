@@ -16,6 +16,8 @@ limitations under the License.
 
 package storage
 
+import "strings"
+
 type Search struct {
 	ConversationID *string
 	MessageID      *string
@@ -23,6 +25,27 @@ type Search struct {
 	AppNamespace   *string
 	User           *string
 	Debug          *bool
+	// StartDate and EndDate are the date range of token usages, in format 2006-01-02
+	StartDate *string
+	EndDate   *string
+	// Rating and Category filter feedbacks
+	Rating   *FeedbackRating
+	Category *string
+	// Keyword searches the title, queries and answers of conversations
+	Keyword *string
+	// HandoffStatus filters handoffs
+	HandoffStatus *HandoffStatus
+	// Page starts from 1, and PageSize 0 means no pagination
+	Page     int
+	PageSize int
+}
+
+// offset returns the number of items to skip for the page
+func (s *Search) offset() int {
+	if s.Page < 1 {
+		return 0
+	}
+	return (s.Page - 1) * s.PageSize
 }
 
 type SearchOption func(options *Search)
@@ -95,3 +118,64 @@ func WithDebug(debug bool) SearchOption {
 		o.Debug = &debug
 	}
 }
+
+// WithDateRange returns a Search for setting the StartDate and EndDate, both are included.
+func WithDateRange(start, end string) SearchOption {
+	return func(o *Search) {
+		if start != "" {
+			o.StartDate = &start
+		}
+		if end != "" {
+			o.EndDate = &end
+		}
+	}
+}
+
+// WithRating returns a Search for setting the Rating.
+func WithRating(rating FeedbackRating) SearchOption {
+	if rating == "" {
+		return func(o *Search) {}
+	}
+	return func(o *Search) {
+		o.Rating = &rating
+	}
+}
+
+// WithCategory returns a Search for setting the Category.
+func WithCategory(category string) SearchOption {
+	if category == "" {
+		return func(o *Search) {}
+	}
+	return func(o *Search) {
+		o.Category = &category
+	}
+}
+
+// WithHandoffStatus returns a Search for setting the HandoffStatus.
+func WithHandoffStatus(status HandoffStatus) SearchOption {
+	if status == "" {
+		return func(o *Search) {}
+	}
+	return func(o *Search) {
+		o.HandoffStatus = &status
+	}
+}
+
+// WithKeyword returns a Search for setting the Keyword.
+func WithKeyword(keyword string) SearchOption {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return func(o *Search) {}
+	}
+	return func(o *Search) {
+		o.Keyword = &keyword
+	}
+}
+
+// WithPagination returns a Search for setting the Page and PageSize.
+func WithPagination(page, pageSize int) SearchOption {
+	return func(o *Search) {
+		o.Page = page
+		o.PageSize = pageSize
+	}
+}
@@ -24,10 +24,23 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+	"github.com/kubeagi/arcadia/pkg/tokenusage"
 )
 
 var (
 	ErrConversationNotFound = errors.New("conversation is not found")
+	ErrMessageNotFound      = errors.New("message is not found")
+	ErrShareNotFound        = errors.New("share is not found")
+	ErrUserMemoryNotFound   = errors.New("user memory is not found")
+	ErrDocumentNotFound     = errors.New("document is not found")
+	ErrHandoffNotFound      = errors.New("handoff is not found")
+)
+
+type FeedbackRating string
+
+const (
+	FeedbackLike    FeedbackRating = "like"
+	FeedbackDislike FeedbackRating = "dislike"
 )
 
 // Conversation represent a conversation in storage
@@ -35,12 +48,20 @@ type Conversation struct {
 	ID           string         `gorm:"column:id;primaryKey;type:uuid;comment:conversation id" json:"id" example:"5a41f3ca-763b-41ec-91c3-4bbbb00736d0"`
 	AppName      string         `gorm:"column:app_name;type:string;comment:app name" json:"app_name" example:"chat-with-llm"`
 	AppNamespace string         `gorm:"column:app_namespace;type:string;comment:app namespace" json:"app_namespace" example:"arcadia"`
+	Title        string         `gorm:"column:title;type:string;comment:conversation title" json:"title" example:"旷工计算单位"`
+	Pinned       bool           `gorm:"column:pinned;type:bool;comment:pinned conversations are listed first" json:"pinned" example:"false"`
 	StartedAt    time.Time      `gorm:"column:started_at;type:time;autoCreateTime;comment:the time the conversation started at" json:"started_at" example:"2023-12-21T10:21:06.389359092+08:00"`
 	UpdatedAt    time.Time      `gorm:"column:updated_at;type:time;autoUpdateTime;comment:the time the conversation updated at" json:"updated_at" example:"2023-12-22T10:21:06.389359092+08:00"`
 	Messages     []Message      `gorm:"foreignKey:ConversationID" json:"messages"`
 	User         string         `gorm:"column:user;type:string;comment:the conversation chat user" json:"-"`
 	Debug        bool           `gorm:"column:debug;type:bool;comment:debug mode" json:"-"`
 	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at;type:time;comment:the time the conversation deleted at" json:"-"`
+	// Summary is the summary of the older messages made by the summary buffer memory
+	Summary string `gorm:"column:summary;type:string;comment:summary of the older messages" json:"-"`
+	// SummarizedMessages is the number of the leading messages in the summary
+	SummarizedMessages int `gorm:"column:summarized_messages;type:int;comment:number of the messages in the summary" json:"-"`
+	// PendingHuman is true if the conversation is handed off and waiting for human operators, the application doesn't answer it until resolved
+	PendingHuman bool `gorm:"column:pending_human;type:bool;comment:waiting for human operators" json:"pending_human" example:"false"`
 	// icon only valid in conversation list api
 	Icon string `gorm:"-" json:"icon"`
 }
@@ -62,6 +83,20 @@ type Message struct {
 	RawFiles   string     `gorm:"column:files;type:string;comment:input files" json:"-"`
 	Answer     string     `gorm:"column:answer;type:string;comment:ai response" json:"answer" example:"旷工最小计算单位为0.5天。"`
 	References References `gorm:"column:references;type:json;comment:references" json:"references,omitempty"`
+	// Usage is the tokens used by each model to answer the query
+	Usage TokenUsages `gorm:"column:usage;type:json;comment:token usage of each model" json:"usage,omitempty"`
+	// Alternates are the previous answers replaced by regeneration, the oldest first.
+	// The version of the current answer is len(Alternates).
+	Alternates Alternates `gorm:"column:alternates;type:json;comment:previous answers before regeneration" json:"alternates,omitempty"`
+	// Feedback of the current answer, only valid in messages history api
+	Feedback *Feedback `gorm:"-" json:"feedback,omitempty"`
+	// Images in the query
+	Images Images `gorm:"column:images;type:json;comment:images in the query" json:"images,omitempty"`
+	// SuggestedQuestions are the follow-up questions suggested after the current answer
+	SuggestedQuestions Questions `gorm:"column:suggested_questions;type:json;comment:suggested follow-up questions" json:"suggested_questions,omitempty"`
+
+	// For Action HUMAN, the answer is a reply of the human operator
+	Operator string `gorm:"column:operator;type:string;comment:the human operator who replies" json:"operator,omitempty" example:"admin"`
 
 	// For Action Upload
 	Documents []Document `gorm:"foreignKey:MessageID" json:"documents"`
@@ -72,6 +107,64 @@ func (m *Message) AfterFind(tx *gorm.DB) error {
 	return nil
 }
 
+// Version returns the version of the current answer, which increases by one after each regeneration
+func (m *Message) Version() int {
+	return len(m.Alternates)
+}
+
+// AddAlternate keeps the current answer as an alternate before it's replaced by a regenerated one,
+// so the version of the message increases by one.
+func (m *Message) AddAlternate() {
+	m.Alternates = append(m.Alternates, Alternate{
+		Answer:     m.Answer,
+		References: m.References,
+		Usage:      m.Usage,
+		Latency:    m.Latency,
+	})
+}
+
+// Alternate is a previous answer of a message
+type Alternate struct {
+	Answer     string      `json:"answer" example:"旷工最小计算单位为1天。"`
+	References References  `json:"references,omitempty"`
+	Usage      TokenUsages `json:"usage,omitempty"`
+	Latency    int64       `json:"latency" example:"1000"`
+	CreatedAt  time.Time   `json:"created_at" example:"2023-12-21T10:21:06.389359092+08:00"`
+	Feedback   *Feedback   `json:"feedback,omitempty"`
+}
+
+type Alternates []Alternate
+
+// Image is an image in the query, saved in the oss with the files uploaded to the conversation
+type Image struct {
+	// Name is sha256(content) with the extension, unique in the conversation
+	Name     string `json:"name" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08.png"`
+	MIMEType string `json:"mime_type" example:"image/png"`
+}
+
+type Images []Image
+
+type Questions []string
+
+// Feedback is the rating of an answer given by the chat user.
+// The query and answer are kept, so feedbacks are still valid after the answer is regenerated.
+type Feedback struct {
+	MessageID      string         `gorm:"column:message_id;primaryKey;type:uuid;comment:message id" json:"message_id" example:"4f3546dd-5404-4bf8-a3bc-4fa3f9a7ba24"`
+	Version        int            `gorm:"column:version;primaryKey;type:int;comment:version of the answer" json:"version" example:"0"`
+	ConversationID string         `gorm:"column:conversation_id;type:uuid;comment:conversation id" json:"conversation_id" example:"5a41f3ca-763b-41ec-91c3-4bbbb00736d0"`
+	AppName        string         `gorm:"column:app_name;type:string;comment:app name" json:"app_name" example:"chat-with-llm"`
+	AppNamespace   string         `gorm:"column:app_namespace;type:string;comment:app namespace" json:"app_namespace" example:"arcadia"`
+	User           string         `gorm:"column:user;type:string;comment:the chat user" json:"user" example:"admin"`
+	Rating         FeedbackRating `gorm:"column:rating;type:string;comment:like or dislike" json:"rating" example:"dislike"`
+	Category       string         `gorm:"column:category;type:string;comment:category of the feedback" json:"category,omitempty" example:"inaccurate"`
+	Comment        string         `gorm:"column:comment;type:string;comment:free-text comment" json:"comment,omitempty" example:"should be 0.5 day"`
+	ExpectedAnswer string         `gorm:"column:expected_answer;type:string;comment:the answer the user expected" json:"expected_answer,omitempty" example:"旷工最小计算单位为0.5天。"`
+	Query          string         `gorm:"column:query;type:string;comment:user input" json:"query" example:"旷工最小计算单位为多少天？"`
+	Answer         string         `gorm:"column:answer;type:string;comment:the rated answer" json:"answer" example:"旷工最小计算单位为1天。"`
+	CreatedAt      time.Time      `gorm:"column:created_at;type:time;autoCreateTime;comment:the time the feedback created at" json:"created_at" example:"2023-12-21T10:21:06.389359092+08:00"`
+	UpdatedAt      time.Time      `gorm:"column:updated_at;type:time;autoUpdateTime;comment:the time the feedback updated at" json:"updated_at" example:"2023-12-21T10:21:06.389359092+08:00"`
+}
+
 type Document struct {
 	ID             string `gorm:"column:id;primaryKey;type:uuid;comment:document id" json:"id" example:"4f3546dd-5404-4bf8-a3bc-4fa3f9a7ba24"`
 	Name           string `gorm:"column:name;type:string;comment:document name" json:"name" example:"kaoqin.pdf"`
@@ -83,6 +176,112 @@ type Document struct {
 
 type References []retriever.Reference
 
+type TokenUsages []tokenusage.Usage
+
+// TokenUsage is the aggregated usage of a user in an app in a day
+type TokenUsage struct {
+	Date             string `gorm:"column:date;primaryKey;type:string;comment:the day in format 2006-01-02" json:"date" example:"2024-04-01"`
+	AppNamespace     string `gorm:"column:app_namespace;primaryKey;type:string;comment:app namespace" json:"app_namespace" example:"arcadia"`
+	AppName          string `gorm:"column:app_name;primaryKey;type:string;comment:app name" json:"app_name" example:"chat-with-llm"`
+	User             string `gorm:"column:user;primaryKey;type:string;comment:the chat user" json:"user" example:"admin"`
+	PromptTokens     int64  `gorm:"column:prompt_tokens;type:bigint;comment:prompt tokens" json:"prompt_tokens" example:"100"`
+	CompletionTokens int64  `gorm:"column:completion_tokens;type:bigint;comment:completion tokens" json:"completion_tokens" example:"20"`
+	TotalTokens      int64  `gorm:"column:total_tokens;type:bigint;comment:total tokens" json:"total_tokens" example:"120"`
+	Requests         int64  `gorm:"column:requests;type:bigint;comment:chat requests" json:"requests" example:"1"`
+}
+
+// Share is a read-only snapshot of a conversation which can be accessed by anyone with the token
+type Share struct {
+	Token          string `gorm:"column:token;primaryKey;type:string;comment:unguessable token in the share link" json:"token" example:"3q2-7wEAAAB8ZXhhbXBsZV90b2tlbl9mb3Jfc2hhcmU"`
+	ConversationID string `gorm:"column:conversation_id;type:uuid;comment:the shared conversation id" json:"conversation_id" example:"5a41f3ca-763b-41ec-91c3-4bbbb00736d0"`
+	AppName        string `gorm:"column:app_name;type:string;comment:app name" json:"app_name" example:"chat-with-llm"`
+	AppNamespace   string `gorm:"column:app_namespace;type:string;comment:app namespace" json:"app_namespace" example:"arcadia"`
+	User           string `gorm:"column:user;type:string;comment:the user who shares the conversation" json:"-"`
+	// Conversation is the snapshot with messages and references when it is shared, it is kept after the conversation is deleted
+	Conversation SharedConversation `gorm:"column:conversation;type:json;comment:snapshot of the conversation" json:"conversation"`
+	CreatedAt    time.Time          `gorm:"column:created_at;type:time;autoCreateTime;comment:the time the share created at" json:"created_at" example:"2023-12-21T10:21:06.389359092+08:00"`
+	// ExpiresAt is the time the share link expires, nil means never
+	ExpiresAt *time.Time `gorm:"column:expires_at;type:time;comment:the time the share expires at" json:"expires_at,omitempty" example:"2023-12-28T10:21:06.389359092+08:00"`
+}
+
+// Expired returns true if the share is expired at the time
+func (s *Share) Expired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
+
+type SharedConversation Conversation
+
+// UserMemory is a fact about the user remembered across conversations
+type UserMemory struct {
+	ID      string `gorm:"column:id;primaryKey;type:uuid;comment:memory id" json:"id" example:"8c2a6f0e-3f5e-4d8a-9d5e-2f0f3b7c9a11"`
+	User    string `gorm:"column:user;type:string;comment:the user the fact is about" json:"-"`
+	Content string `gorm:"column:content;type:string;comment:the fact about the user" json:"content" example:"用户是一名Go开发者"`
+	// Embedder is the embedder of the vector, in namespace/name
+	Embedder string `gorm:"column:embedder;type:string;comment:the embedder of the vector" json:"-"`
+	Vector   Vector `gorm:"column:vector;type:json;comment:embedding of the content" json:"-"`
+	// where the fact is extracted from
+	AppName        string    `gorm:"column:app_name;type:string;comment:app name" json:"app_name" example:"chat-with-llm"`
+	AppNamespace   string    `gorm:"column:app_namespace;type:string;comment:app namespace" json:"app_namespace" example:"arcadia"`
+	ConversationID string    `gorm:"column:conversation_id;type:uuid;comment:conversation id" json:"conversation_id" example:"5a41f3ca-763b-41ec-91c3-4bbbb00736d0"`
+	CreatedAt      time.Time `gorm:"column:created_at;type:time;autoCreateTime;comment:the time the memory created at" json:"created_at" example:"2023-12-21T10:21:06.389359092+08:00"`
+}
+
+type Vector []float32
+
+type HandoffStatus string
+
+const (
+	HandoffPending  HandoffStatus = "pending"
+	HandoffResolved HandoffStatus = "resolved"
+)
+
+type HandoffReason string
+
+const (
+	// HandoffReasonUserRequest means the user asks for a human operator
+	HandoffReasonUserRequest HandoffReason = "user_request"
+	// HandoffReasonDocNull means the retrievers find nothing for the question
+	HandoffReasonDocNull HandoffReason = "doc_null"
+	// HandoffReasonLowConfidence means the references of the answer are not similar enough to the question
+	HandoffReasonLowConfidence HandoffReason = "low_confidence"
+)
+
+// Handoff is a conversation handed over to human operators, the pending ones are the queue of the operators
+type Handoff struct {
+	ID             string `gorm:"column:id;primaryKey;type:uuid;comment:handoff id" json:"id" example:"0b8d5a6e-1f0c-4c1a-9a55-6d4f0f0b1c2d"`
+	ConversationID string `gorm:"column:conversation_id;type:uuid;comment:conversation id" json:"conversation_id" example:"5a41f3ca-763b-41ec-91c3-4bbbb00736d0"`
+	AppName        string `gorm:"column:app_name;type:string;comment:app name" json:"app_name" example:"chat-with-llm"`
+	AppNamespace   string `gorm:"column:app_namespace;type:string;comment:app namespace" json:"app_namespace" example:"arcadia"`
+	User           string `gorm:"column:user;type:string;comment:the chat user" json:"user" example:"admin"`
+	// MessageID and Query are of the message which triggers the handoff
+	MessageID string        `gorm:"column:message_id;type:uuid;comment:message id" json:"message_id" example:"4f3546dd-5404-4bf8-a3bc-4fa3f9a7ba24"`
+	Query     string        `gorm:"column:query;type:string;comment:user input" json:"query" example:"旷工最小计算单位为多少天？"`
+	Reason    HandoffReason `gorm:"column:reason;type:string;comment:why the conversation is handed off" json:"reason" example:"doc_null"`
+	Status    HandoffStatus `gorm:"column:status;type:string;comment:pending or resolved" json:"status" example:"pending"`
+	// Operator is the human operator who resolves the handoff
+	Operator   string     `gorm:"column:operator;type:string;comment:the operator who resolves it" json:"operator,omitempty" example:"admin"`
+	CreatedAt  time.Time  `gorm:"column:created_at;type:time;autoCreateTime;comment:the time the handoff created at" json:"created_at" example:"2023-12-21T10:21:06.389359092+08:00"`
+	ResolvedAt *time.Time `gorm:"column:resolved_at;type:time;comment:the time the handoff resolved at" json:"resolved_at,omitempty" example:"2023-12-21T10:31:06.389359092+08:00"`
+}
+
+// GuardrailViolation is a rule of the guardrail nodes broken by the question or the answer of a message
+type GuardrailViolation struct {
+	ID             string `gorm:"column:id;primaryKey;type:uuid;comment:violation id" json:"id" example:"9d1f6c2a-5b7e-4c3d-8a2f-1e0b9c8d7a6f"`
+	ConversationID string `gorm:"column:conversation_id;type:uuid;comment:conversation id" json:"conversation_id" example:"5a41f3ca-763b-41ec-91c3-4bbbb00736d0"`
+	MessageID      string `gorm:"column:message_id;type:uuid;comment:message id" json:"message_id" example:"4f3546dd-5404-4bf8-a3bc-4fa3f9a7ba24"`
+	AppName        string `gorm:"column:app_name;type:string;comment:app name" json:"app_name" example:"chat-with-llm"`
+	AppNamespace   string `gorm:"column:app_namespace;type:string;comment:app namespace" json:"app_namespace" example:"arcadia"`
+	User           string `gorm:"column:user;type:string;comment:the chat user" json:"user" example:"admin"`
+	// Node is the name of the guardrail node in the app
+	Node     string `gorm:"column:node;type:string;comment:the guardrail node" json:"node" example:"input-guardrail"`
+	Position string `gorm:"column:position;type:string;comment:input or output" json:"position" example:"input"`
+	Rule     string `gorm:"column:rule;type:string;comment:blocklist, pii or policy" json:"rule" example:"pii"`
+	// Detail is the keyword or pattern in the blocklist, the type of the personal information, or the reason from the policy llm
+	Detail    string    `gorm:"column:detail;type:string;comment:what breaks the rule" json:"detail" example:"phone"`
+	Action    string    `gorm:"column:action;type:string;comment:block, redact or warn" json:"action" example:"redact"`
+	CreatedAt time.Time `gorm:"column:created_at;type:time;autoCreateTime;comment:the time the violation found at" json:"created_at" example:"2023-12-21T10:21:06.389359092+08:00"`
+}
+
 func (Conversation) TableName() string {
 	return "app_chat_conversation"
 }
@@ -95,10 +294,40 @@ func (Document) TableName() string {
 	return "app_chat_document"
 }
 
+func (TokenUsage) TableName() string {
+	return "app_token_usage"
+}
+
+func (Feedback) TableName() string {
+	return "app_chat_message_feedback"
+}
+
+func (Share) TableName() string {
+	return "app_chat_share"
+}
+
+func (UserMemory) TableName() string {
+	return "app_chat_user_memory"
+}
+
+func (Handoff) TableName() string {
+	return "app_chat_handoff"
+}
+
+func (GuardrailViolation) TableName() string {
+	return "app_chat_guardrail_violation"
+}
+
 type Storage interface {
 	ConversationStorage
 	MessageStorage
 	DocumentStorage
+	UsageStorage
+	FeedbackStorage
+	ShareStorage
+	UserMemoryStorage
+	HandoffStorage
+	GuardrailStorage
 }
 
 // ConversationStorage interface
@@ -118,9 +347,22 @@ type ConversationStorage interface {
 	// It takes a pointer to a Conversation and returns an error.
 	UpdateConversation(*Conversation) error
 	// ListConversations returns a list of conversations based on the provided options.
+	// Pinned conversations come first, then the latest updated.
 	//
 	// It accepts SearchOption(s) and returns a slice of Conversation and an error.
 	ListConversations(opts ...SearchOption) ([]Conversation, error)
+	// RenameConversation sets the title of the conversation.
+	//
+	// It returns ErrConversationNotFound if no conversation matches the ID and options.
+	RenameConversation(ID string, title string, opts ...SearchOption) error
+	// PinConversation pins or unpins the conversation.
+	//
+	// It returns ErrConversationNotFound if no conversation matches the ID and options.
+	PinConversation(ID string, pinned bool, opts ...SearchOption) error
+	// SetPendingHuman sets whether the conversation is waiting for human operators.
+	//
+	// It returns ErrConversationNotFound if no conversation matches the ID and options.
+	SetPendingHuman(ID string, pending bool, opts ...SearchOption) error
 }
 
 type MessageStorage interface {
@@ -130,8 +372,76 @@ type MessageStorage interface {
 	FindExistingMessage(conversationID, messageID string, opts ...SearchOption) (*Message, error)
 	// CountMessages count how many messages is about this app
 	CountMessages(appName, appNamespace string) (int64, error)
+	// UpdateMessage updates the answer, references, usage and alternates of an existing message.
+	UpdateMessage(*Message) error
 }
 
 type DocumentStorage interface {
-	// TO BE DEFINED
+	// ListDocuments returns the documents uploaded to the conversation.
+	ListDocuments(conversationID string) ([]Document, error)
+	// DeleteDocument deletes the document of the conversation.
+	//
+	// It returns ErrDocumentNotFound if no document matches the id in the conversation.
+	DeleteDocument(conversationID, documentID string) error
+}
+
+type UsageStorage interface {
+	// AddTokenUsage adds the tokens and requests to the usage with the same date, app and user.
+	AddTokenUsage(usage TokenUsage) error
+	// ListTokenUsages returns the daily usages filtered by the app, user and date range, the latest day first.
+	ListTokenUsages(opts ...SearchOption) ([]TokenUsage, error)
+}
+
+type FeedbackStorage interface {
+	// UpdateFeedback creates or updates the feedback of the message with the same version.
+	UpdateFeedback(*Feedback) error
+	// DeleteFeedback deletes the feedback of the message with the version.
+	// **not** return error if the feedback is not found
+	DeleteFeedback(messageID string, version int) error
+	// ListFeedbacks returns the feedbacks filtered by the conversation, app, user, rating, category and date range, the latest first.
+	ListFeedbacks(opts ...SearchOption) ([]Feedback, error)
+}
+
+type ShareStorage interface {
+	// CreateShare saves a new share.
+	CreateShare(*Share) error
+	// FindShare returns the share with the token, or ErrShareNotFound.
+	FindShare(token string) (*Share, error)
+	// ListShares returns the shares filtered by the conversation and user, the latest first.
+	ListShares(opts ...SearchOption) ([]Share, error)
+	// DeleteShare revokes the share with the token.
+	//
+	// It returns ErrShareNotFound if no share matches the token and options.
+	DeleteShare(token string, opts ...SearchOption) error
+}
+
+type UserMemoryStorage interface {
+	// AddUserMemory saves a new fact about the user.
+	AddUserMemory(*UserMemory) error
+	// ListUserMemories returns the facts filtered by the user, the latest first.
+	ListUserMemories(opts ...SearchOption) ([]UserMemory, error)
+	// DeleteUserMemory forgets the fact.
+	//
+	// It returns ErrUserMemoryNotFound if no fact matches the id and options.
+	DeleteUserMemory(id string, opts ...SearchOption) error
+}
+
+type HandoffStorage interface {
+	// CreateHandoff saves a new handoff.
+	CreateHandoff(*Handoff) error
+	// FindHandoff returns the handoff with the id.
+	//
+	// It returns ErrHandoffNotFound if no handoff matches the id and options.
+	FindHandoff(id string, opts ...SearchOption) (*Handoff, error)
+	// ListHandoffs returns the handoffs filtered by the app, conversation and status, the earliest first.
+	ListHandoffs(opts ...SearchOption) ([]Handoff, error)
+	// UpdateHandoff updates the status, operator and resolved time of the handoff.
+	UpdateHandoff(*Handoff) error
+}
+
+type GuardrailStorage interface {
+	// AddGuardrailViolations saves the violations found in a chat.
+	AddGuardrailViolations([]GuardrailViolation) error
+	// ListGuardrailViolations returns the violations filtered by the app, conversation, user and date range, the latest first.
+	ListGuardrailViolations(opts ...SearchOption) ([]GuardrailViolation, error)
 }
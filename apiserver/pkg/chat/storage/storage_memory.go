@@ -17,8 +17,11 @@ limitations under the License.
 package storage
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 var _ Storage = (*MemoryStorage)(nil)
@@ -26,6 +29,17 @@ var _ Storage = (*MemoryStorage)(nil)
 type MemoryStorage struct {
 	mu            sync.Mutex
 	conversations map[string]Conversation
+	usages        map[TokenUsage]TokenUsage
+	feedbacks     map[feedbackKey]Feedback
+	shares        map[string]Share
+	userMemories  map[string]UserMemory
+	handoffs      map[string]Handoff
+	violations    []GuardrailViolation
+}
+
+type feedbackKey struct {
+	messageID string
+	version   int
 }
 
 func (m *MemoryStorage) CountMessages(appName, appNamespace string) (res int64, err error) {
@@ -44,6 +58,11 @@ func (m *MemoryStorage) CountMessages(appName, appNamespace string) (res int64,
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
 		conversations: make(map[string]Conversation),
+		usages:        make(map[TokenUsage]TokenUsage),
+		feedbacks:     make(map[feedbackKey]Feedback),
+		shares:        make(map[string]Share),
+		userMemories:  make(map[string]UserMemory),
+		handoffs:      make(map[string]Handoff),
 	}
 }
 
@@ -68,15 +87,75 @@ func (m *MemoryStorage) ListConversations(opts ...SearchOption) (conversations [
 		if searchOpt.Debug != nil && c.Debug != *searchOpt.Debug {
 			continue
 		}
+		if searchOpt.Keyword != nil && !c.contains(*searchOpt.Keyword) {
+			continue
+		}
 		conversations = append(conversations, c)
 	}
 	m.mu.Unlock()
 	sort.Slice(conversations, func(i, j int) bool {
+		if conversations[i].Pinned != conversations[j].Pinned {
+			return conversations[i].Pinned
+		}
 		return conversations[i].UpdatedAt.After(conversations[j].UpdatedAt)
 	})
+	if searchOpt.PageSize > 0 {
+		start := searchOpt.offset()
+		if start >= len(conversations) {
+			return nil, nil
+		}
+		end := start + searchOpt.PageSize
+		if end > len(conversations) {
+			end = len(conversations)
+		}
+		conversations = conversations[start:end]
+	}
 	return conversations, nil
 }
 
+// contains returns true if the title, any query or answer of the conversation contains the keyword, case-insensitively
+func (c *Conversation) contains(keyword string) bool {
+	keyword = strings.ToLower(keyword)
+	if strings.Contains(strings.ToLower(c.Title), keyword) {
+		return true
+	}
+	for _, m := range c.Messages {
+		if strings.Contains(strings.ToLower(m.Query), keyword) || strings.Contains(strings.ToLower(m.Answer), keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryStorage) RenameConversation(conversationID string, title string, opts ...SearchOption) error {
+	return m.patchConversation(conversationID, func(c *Conversation) { c.Title = title }, opts...)
+}
+
+func (m *MemoryStorage) PinConversation(conversationID string, pinned bool, opts ...SearchOption) error {
+	return m.patchConversation(conversationID, func(c *Conversation) { c.Pinned = pinned }, opts...)
+}
+
+func (m *MemoryStorage) SetPendingHuman(conversationID string, pending bool, opts ...SearchOption) error {
+	return m.patchConversation(conversationID, func(c *Conversation) { c.PendingHuman = pending }, opts...)
+}
+
+func (m *MemoryStorage) patchConversation(conversationID string, patch func(c *Conversation), opts ...SearchOption) error {
+	c, err := m.FindExistingConversation(conversationID, opts...)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// get again in case it is updated after found
+	current, ok := m.conversations[c.ID]
+	if !ok {
+		return ErrConversationNotFound
+	}
+	patch(&current)
+	m.conversations[c.ID] = current
+	return nil
+}
+
 // UpdateConversation updates a conversation in the MemoryStorage.
 //
 // It takes a pointer to a Conversation as a parameter and returns an error.
@@ -101,6 +180,24 @@ func (m *MemoryStorage) FindExistingMessage(conversationID string, messageID str
 	return nil, nil
 }
 
+func (m *MemoryStorage) UpdateMessage(message *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, c := range m.conversations {
+		if message.ConversationID != "" && id != message.ConversationID {
+			continue
+		}
+		for i := range c.Messages {
+			if c.Messages[i].ID == message.ID {
+				c.Messages[i] = *message
+				m.conversations[id] = c
+				return nil
+			}
+		}
+	}
+	return ErrMessageNotFound
+}
+
 // Delete deletes a conversation from MemoryStorage based on the provided options.
 //
 // Parameter(s): opts ...SearchOption
@@ -180,3 +277,354 @@ func (m *MemoryStorage) FindExistingDocument(conversationID string, messageID st
 	}
 	return nil, nil
 }
+
+func (m *MemoryStorage) ListDocuments(conversationID string) ([]Document, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	documents := make([]Document, 0)
+	for _, message := range m.conversations[conversationID].Messages {
+		documents = append(documents, message.Documents...)
+	}
+	return documents, nil
+}
+
+func (m *MemoryStorage) DeleteDocument(conversationID, documentID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.conversations[conversationID]
+	if !ok {
+		return ErrDocumentNotFound
+	}
+	messages := make([]Message, len(c.Messages))
+	copy(messages, c.Messages)
+	for i, message := range messages {
+		for j, document := range message.Documents {
+			if document.ID != documentID {
+				continue
+			}
+			documents := make([]Document, 0, len(message.Documents)-1)
+			documents = append(documents, message.Documents[:j]...)
+			messages[i].Documents = append(documents, message.Documents[j+1:]...)
+			c.Messages = messages
+			m.conversations[conversationID] = c
+			return nil
+		}
+	}
+	return ErrDocumentNotFound
+}
+
+func (m *MemoryStorage) AddTokenUsage(usage TokenUsage) error {
+	key := TokenUsage{Date: usage.Date, AppNamespace: usage.AppNamespace, AppName: usage.AppName, User: usage.User}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.usages[key]
+	if !ok {
+		current = key
+	}
+	current.PromptTokens += usage.PromptTokens
+	current.CompletionTokens += usage.CompletionTokens
+	current.TotalTokens += usage.TotalTokens
+	current.Requests += usage.Requests
+	m.usages[key] = current
+	return nil
+}
+
+func (m *MemoryStorage) ListTokenUsages(opts ...SearchOption) (usages []TokenUsage, err error) {
+	searchOpt := applyOptions(nil, opts...)
+	m.mu.Lock()
+	for _, u := range m.usages {
+		if searchOpt.AppName != nil && u.AppName != *searchOpt.AppName {
+			continue
+		}
+		if searchOpt.AppNamespace != nil && u.AppNamespace != *searchOpt.AppNamespace {
+			continue
+		}
+		if searchOpt.User != nil && u.User != *searchOpt.User {
+			continue
+		}
+		if searchOpt.StartDate != nil && u.Date < *searchOpt.StartDate {
+			continue
+		}
+		if searchOpt.EndDate != nil && u.Date > *searchOpt.EndDate {
+			continue
+		}
+		usages = append(usages, u)
+	}
+	m.mu.Unlock()
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Date > usages[j].Date
+	})
+	return usages, nil
+}
+
+func (m *MemoryStorage) UpdateFeedback(feedback *Feedback) error {
+	key := feedbackKey{messageID: feedback.MessageID, version: feedback.Version}
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if current, ok := m.feedbacks[key]; ok {
+		feedback.CreatedAt = current.CreatedAt
+	} else {
+		feedback.CreatedAt = now
+	}
+	feedback.UpdatedAt = now
+	m.feedbacks[key] = *feedback
+	return nil
+}
+
+func (m *MemoryStorage) DeleteFeedback(messageID string, version int) error {
+	m.mu.Lock()
+	delete(m.feedbacks, feedbackKey{messageID: messageID, version: version})
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MemoryStorage) ListFeedbacks(opts ...SearchOption) (feedbacks []Feedback, err error) {
+	searchOpt := applyOptions(nil, opts...)
+	m.mu.Lock()
+	for _, f := range m.feedbacks {
+		if searchOpt.ConversationID != nil && f.ConversationID != *searchOpt.ConversationID {
+			continue
+		}
+		if searchOpt.MessageID != nil && f.MessageID != *searchOpt.MessageID {
+			continue
+		}
+		if searchOpt.AppName != nil && f.AppName != *searchOpt.AppName {
+			continue
+		}
+		if searchOpt.AppNamespace != nil && f.AppNamespace != *searchOpt.AppNamespace {
+			continue
+		}
+		if searchOpt.User != nil && f.User != *searchOpt.User {
+			continue
+		}
+		if searchOpt.Rating != nil && f.Rating != *searchOpt.Rating {
+			continue
+		}
+		if searchOpt.Category != nil && f.Category != *searchOpt.Category {
+			continue
+		}
+		date := f.CreatedAt.Format(time.DateOnly)
+		if searchOpt.StartDate != nil && date < *searchOpt.StartDate {
+			continue
+		}
+		if searchOpt.EndDate != nil && date > *searchOpt.EndDate {
+			continue
+		}
+		feedbacks = append(feedbacks, f)
+	}
+	m.mu.Unlock()
+	sort.Slice(feedbacks, func(i, j int) bool {
+		return feedbacks[i].CreatedAt.After(feedbacks[j].CreatedAt)
+	})
+	return feedbacks, nil
+}
+
+func (m *MemoryStorage) CreateShare(share *Share) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.shares[share.Token]; ok {
+		return fmt.Errorf("share %s already exists", share.Token)
+	}
+	share.CreatedAt = time.Now()
+	m.shares[share.Token] = *share
+	return nil
+}
+
+func (m *MemoryStorage) FindShare(token string) (*Share, error) {
+	m.mu.Lock()
+	share, ok := m.shares[token]
+	m.mu.Unlock()
+	if !ok {
+		return nil, ErrShareNotFound
+	}
+	return &share, nil
+}
+
+func (m *MemoryStorage) ListShares(opts ...SearchOption) (shares []Share, err error) {
+	searchOpt := applyOptions(nil, opts...)
+	m.mu.Lock()
+	for _, s := range m.shares {
+		if searchOpt.ConversationID != nil && s.ConversationID != *searchOpt.ConversationID {
+			continue
+		}
+		if searchOpt.User != nil && s.User != *searchOpt.User {
+			continue
+		}
+		shares = append(shares, s)
+	}
+	m.mu.Unlock()
+	sort.Slice(shares, func(i, j int) bool {
+		return shares[i].CreatedAt.After(shares[j].CreatedAt)
+	})
+	return shares, nil
+}
+
+func (m *MemoryStorage) DeleteShare(token string, opts ...SearchOption) error {
+	searchOpt := applyOptions(nil, opts...)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	share, ok := m.shares[token]
+	if !ok || (searchOpt.User != nil && share.User != *searchOpt.User) {
+		return ErrShareNotFound
+	}
+	delete(m.shares, token)
+	return nil
+}
+
+func (m *MemoryStorage) AddUserMemory(memory *UserMemory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	memory.CreatedAt = time.Now()
+	m.userMemories[memory.ID] = *memory
+	return nil
+}
+
+func (m *MemoryStorage) ListUserMemories(opts ...SearchOption) (memories []UserMemory, err error) {
+	searchOpt := applyOptions(nil, opts...)
+	m.mu.Lock()
+	for _, v := range m.userMemories {
+		if searchOpt.User != nil && v.User != *searchOpt.User {
+			continue
+		}
+		memories = append(memories, v)
+	}
+	m.mu.Unlock()
+	sort.Slice(memories, func(i, j int) bool {
+		return memories[i].CreatedAt.After(memories[j].CreatedAt)
+	})
+	return memories, nil
+}
+
+func (m *MemoryStorage) DeleteUserMemory(id string, opts ...SearchOption) error {
+	searchOpt := applyOptions(nil, opts...)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.userMemories[id]
+	if !ok || (searchOpt.User != nil && v.User != *searchOpt.User) {
+		return ErrUserMemoryNotFound
+	}
+	delete(m.userMemories, id)
+	return nil
+}
+
+func (m *MemoryStorage) CreateHandoff(handoff *Handoff) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.handoffs[handoff.ID]; ok {
+		return fmt.Errorf("handoff %s already exists", handoff.ID)
+	}
+	handoff.CreatedAt = time.Now()
+	m.handoffs[handoff.ID] = *handoff
+	return nil
+}
+
+func (m *MemoryStorage) FindHandoff(id string, opts ...SearchOption) (*Handoff, error) {
+	searchOpt := applyOptions(nil, opts...)
+	m.mu.Lock()
+	handoff, ok := m.handoffs[id]
+	m.mu.Unlock()
+	if !ok || !handoffMatches(handoff, searchOpt) {
+		return nil, ErrHandoffNotFound
+	}
+	return &handoff, nil
+}
+
+func (m *MemoryStorage) ListHandoffs(opts ...SearchOption) (handoffs []Handoff, err error) {
+	searchOpt := applyOptions(nil, opts...)
+	m.mu.Lock()
+	for _, h := range m.handoffs {
+		if handoffMatches(h, searchOpt) {
+			handoffs = append(handoffs, h)
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(handoffs, func(i, j int) bool {
+		return handoffs[i].CreatedAt.Before(handoffs[j].CreatedAt)
+	})
+	return handoffs, nil
+}
+
+func handoffMatches(h Handoff, searchOpt *Search) bool {
+	if searchOpt.ConversationID != nil && h.ConversationID != *searchOpt.ConversationID {
+		return false
+	}
+	if searchOpt.AppName != nil && h.AppName != *searchOpt.AppName {
+		return false
+	}
+	if searchOpt.AppNamespace != nil && h.AppNamespace != *searchOpt.AppNamespace {
+		return false
+	}
+	if searchOpt.HandoffStatus != nil && h.Status != *searchOpt.HandoffStatus {
+		return false
+	}
+	return true
+}
+
+func (m *MemoryStorage) UpdateHandoff(handoff *Handoff) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.handoffs[handoff.ID]
+	if !ok {
+		return ErrHandoffNotFound
+	}
+	v.Status = handoff.Status
+	v.Operator = handoff.Operator
+	v.ResolvedAt = handoff.ResolvedAt
+	m.handoffs[handoff.ID] = v
+	return nil
+}
+
+func (m *MemoryStorage) AddGuardrailViolations(violations []GuardrailViolation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for _, v := range violations {
+		v.CreatedAt = now
+		m.violations = append(m.violations, v)
+	}
+	return nil
+}
+
+func (m *MemoryStorage) ListGuardrailViolations(opts ...SearchOption) (violations []GuardrailViolation, err error) {
+	searchOpt := applyOptions(nil, opts...)
+	m.mu.Lock()
+	for _, v := range m.violations {
+		if searchOpt.ConversationID != nil && v.ConversationID != *searchOpt.ConversationID {
+			continue
+		}
+		if searchOpt.AppName != nil && v.AppName != *searchOpt.AppName {
+			continue
+		}
+		if searchOpt.AppNamespace != nil && v.AppNamespace != *searchOpt.AppNamespace {
+			continue
+		}
+		if searchOpt.User != nil && v.User != *searchOpt.User {
+			continue
+		}
+		date := v.CreatedAt.Format(time.DateOnly)
+		if searchOpt.StartDate != nil && date < *searchOpt.StartDate {
+			continue
+		}
+		if searchOpt.EndDate != nil && date > *searchOpt.EndDate {
+			continue
+		}
+		violations = append(violations, v)
+	}
+	m.mu.Unlock()
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].CreatedAt.After(violations[j].CreatedAt)
+	})
+	if searchOpt.PageSize > 0 {
+		start := searchOpt.offset()
+		if start >= len(violations) {
+			return nil, nil
+		}
+		end := start + searchOpt.PageSize
+		if end > len(violations) {
+			end = len(violations)
+		}
+		violations = violations[start:end]
+	}
+	return violations, nil
+}
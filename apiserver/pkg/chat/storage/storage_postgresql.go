@@ -19,9 +19,11 @@ package storage
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -30,8 +32,10 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
+	"k8s.io/klog/v2"
 
 	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+	"github.com/kubeagi/arcadia/pkg/tokenusage"
 )
 
 func (r *References) Scan(value interface{}) error {
@@ -57,6 +61,118 @@ func (r References) Value() (driver.Value, error) {
 	return json.Marshal(r)
 }
 
+func (u *TokenUsages) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal JSONB value:%#v", value)
+	}
+
+	result := make([]tokenusage.Usage, 0)
+	err := json.Unmarshal(bytes, &result)
+	if err != nil {
+		return err
+	}
+	*u = result
+	return nil
+}
+
+func (u TokenUsages) Value() (driver.Value, error) {
+	if len(u) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(u)
+}
+
+func (a *Alternates) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal JSONB value:%#v", value)
+	}
+
+	result := make([]Alternate, 0)
+	err := json.Unmarshal(bytes, &result)
+	if err != nil {
+		return err
+	}
+	*a = result
+	return nil
+}
+
+func (a Alternates) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+func (i *Images) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal JSONB value:%#v", value)
+	}
+
+	result := make([]Image, 0)
+	err := json.Unmarshal(bytes, &result)
+	if err != nil {
+		return err
+	}
+	*i = result
+	return nil
+}
+
+func (i Images) Value() (driver.Value, error) {
+	if len(i) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(i)
+}
+
+func (q *Questions) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal JSONB value:%#v", value)
+	}
+
+	result := make([]string, 0)
+	err := json.Unmarshal(bytes, &result)
+	if err != nil {
+		return err
+	}
+	*q = result
+	return nil
+}
+
+func (q Questions) Value() (driver.Value, error) {
+	if len(q) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(q)
+}
+
+func (c *SharedConversation) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal JSONB value:%#v", value)
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+func (c SharedConversation) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (v *Vector) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal JSONB value:%#v", value)
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+func (v Vector) Value() (driver.Value, error) {
+	return json.Marshal(v)
+}
+
 var _ Storage = (*PostgreSQLStorage)(nil)
 
 type PostgreSQLStorage struct {
@@ -103,13 +219,79 @@ func (p *PostgreSQLStorage) ListConversations(opts ...SearchOption) ([]Conversat
 	conversationQuery.Debug = false
 	conversationQuery.DeletedAt.Valid = false
 	res := make([]Conversation, 0)
-	tx := p.db.Preload("Messages.Documents").Order("updated_at DESC").Find(&res, conversationQuery)
+	tx := p.db.Preload("Messages.Documents").Where(conversationQuery)
+	if searchOpt.Keyword != nil {
+		tx = tx.Where("title ILIKE ? OR id IN (?)", "%"+escapeLike(*searchOpt.Keyword)+"%", p.matchedConversations(conversationQuery, *searchOpt.Keyword))
+	}
+	if searchOpt.PageSize > 0 {
+		tx = tx.Offset(searchOpt.offset()).Limit(searchOpt.PageSize)
+	}
+	tx = tx.Order("pinned DESC").Order("updated_at DESC").Find(&res)
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
 	return res, nil
 }
 
+// matchedConversations selects the ids of the conversations in the scope which have messages matching the keyword.
+// The full-text search uses the tsvector index and the substring match for languages without spaces between words
+// like Chinese uses the trigram indexes, they are separate branches of a UNION so that each one can use its index.
+func (p *PostgreSQLStorage) matchedConversations(scope Conversation, keyword string) *gorm.DB {
+	like := "%" + escapeLike(keyword) + "%"
+	branch := func(query string, args ...any) *gorm.DB {
+		conversations := p.db.Model(&Conversation{}).Select("id").Where(scope)
+		return p.db.Model(&Message{}).Select("conversation_id").Where("conversation_id IN (?)", conversations).Where(query, args...)
+	}
+	return p.db.Raw("(?) UNION (?) UNION (?)",
+		branch(messageTSVector+" @@ plainto_tsquery('simple', ?)", keyword),
+		branch("query ILIKE ?", like),
+		branch("answer ILIKE ?", like))
+}
+
+// messageTSVector is the text search vector of the query and answer of a message
+const messageTSVector = "to_tsvector('simple', coalesce(query, '') || ' ' || coalesce(answer, ''))"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+func (p *PostgreSQLStorage) RenameConversation(conversationID string, title string, opts ...SearchOption) error {
+	return p.patchConversation(conversationID, map[string]any{"title": title}, opts...)
+}
+
+func (p *PostgreSQLStorage) PinConversation(conversationID string, pinned bool, opts ...SearchOption) error {
+	return p.patchConversation(conversationID, map[string]any{"pinned": pinned}, opts...)
+}
+
+func (p *PostgreSQLStorage) SetPendingHuman(conversationID string, pending bool, opts ...SearchOption) error {
+	return p.patchConversation(conversationID, map[string]any{"pending_human": pending}, opts...)
+}
+
+func (p *PostgreSQLStorage) patchConversation(conversationID string, values map[string]any, opts ...SearchOption) error {
+	searchOpt := applyOptions(&conversationID, opts...)
+	conversationQuery := Conversation{ID: conversationID}
+	if searchOpt.User != nil {
+		conversationQuery.User = *searchOpt.User
+	}
+	if searchOpt.AppName != nil {
+		conversationQuery.AppName = *searchOpt.AppName
+	}
+	if searchOpt.AppNamespace != nil {
+		conversationQuery.AppNamespace = *searchOpt.AppNamespace
+	}
+	// keep updated_at, which is the time of the last message
+	tx := p.db.Model(&Conversation{}).Where(conversationQuery).UpdateColumns(values)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrConversationNotFound
+	}
+	return nil
+}
+
 func (p *PostgreSQLStorage) UpdateConversation(conversation *Conversation) error {
 	tx := p.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(conversation)
 	if tx.Error != nil {
@@ -124,9 +306,22 @@ func NewPostgreSQLStorage(conn *pgx.Conn) (*PostgreSQLStorage, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := db.AutoMigrate(&Conversation{}, &Message{}, &Document{}); err != nil {
+	if err := db.AutoMigrate(&Conversation{}, &Message{}, &Document{}, &TokenUsage{}, &Feedback{}, &Share{}, &UserMemory{}, &Handoff{}, &GuardrailViolation{}); err != nil {
+		return nil, err
+	}
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_app_chat_message_fts ON " + Message{}.TableName() + " USING GIN (" + messageTSVector + ")").Error; err != nil {
 		return nil, err
 	}
+	// the trigram indexes are only an optimization of the substring match, skip them if pg_trgm can't be installed
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		klog.Warningf("failed to create extension pg_trgm, searching messages by substring won't use indexes: %s", err)
+	} else {
+		for _, column := range []string{"query", "answer"} {
+			if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_app_chat_message_" + column + "_trgm ON " + Message{}.TableName() + " USING GIN (" + column + " gin_trgm_ops)").Error; err != nil {
+				return nil, err
+			}
+		}
+	}
 	customLogger := logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
 		SlowThreshold:             100 * time.Millisecond,
 		LogLevel:                  logger.Info,
@@ -247,6 +442,17 @@ func (p *PostgreSQLStorage) FindExistingMessage(conversationID string, messageID
 	return message, nil
 }
 
+func (p *PostgreSQLStorage) UpdateMessage(message *Message) error {
+	tx := p.db.Model(message).Select("answer", "references", "usage", "latency", "alternates").Updates(message)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrMessageNotFound
+	}
+	return nil
+}
+
 func (p *PostgreSQLStorage) FindExistingDocument(conversationID, messageID string, documentID string, opts ...SearchOption) (*Document, error) {
 	messageQuery := Message{ID: messageID}
 	message := &Message{}
@@ -264,3 +470,297 @@ func (p *PostgreSQLStorage) FindExistingDocument(conversationID, messageID strin
 	}
 	return document, nil
 }
+
+func (p *PostgreSQLStorage) ListDocuments(conversationID string) ([]Document, error) {
+	documents := make([]Document, 0)
+	tx := p.db.Where(Document{ConversationID: conversationID}).Find(&documents)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return documents, nil
+}
+
+func (p *PostgreSQLStorage) DeleteDocument(conversationID, documentID string) error {
+	tx := p.db.Where(Document{ConversationID: conversationID, ID: documentID}).Delete(&Document{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrDocumentNotFound
+	}
+	return nil
+}
+
+func (p *PostgreSQLStorage) AddTokenUsage(usage TokenUsage) error {
+	table := usage.TableName()
+	increase := func(column string) clause.Expr {
+		return gorm.Expr(fmt.Sprintf("%q.%q + excluded.%q", table, column, column))
+	}
+	tx := p.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "date"}, {Name: "app_namespace"}, {Name: "app_name"}, {Name: "user"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"prompt_tokens":     increase("prompt_tokens"),
+			"completion_tokens": increase("completion_tokens"),
+			"total_tokens":      increase("total_tokens"),
+			"requests":          increase("requests"),
+		}),
+	}).Create(&usage)
+	return tx.Error
+}
+
+func (p *PostgreSQLStorage) ListTokenUsages(opts ...SearchOption) ([]TokenUsage, error) {
+	searchOpt := applyOptions(nil, opts...)
+	query := TokenUsage{}
+	if searchOpt.User != nil {
+		query.User = *searchOpt.User
+	}
+	if searchOpt.AppName != nil {
+		query.AppName = *searchOpt.AppName
+	}
+	if searchOpt.AppNamespace != nil {
+		query.AppNamespace = *searchOpt.AppNamespace
+	}
+	tx := p.db.Where(query)
+	if searchOpt.StartDate != nil {
+		tx = tx.Where("date >= ?", *searchOpt.StartDate)
+	}
+	if searchOpt.EndDate != nil {
+		tx = tx.Where("date <= ?", *searchOpt.EndDate)
+	}
+	res := make([]TokenUsage, 0)
+	if err := tx.Order("date DESC").Find(&res).Error; err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *PostgreSQLStorage) UpdateFeedback(feedback *Feedback) error {
+	tx := p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_id"}, {Name: "version"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rating", "category", "comment", "expected_answer", "updated_at"}),
+	}).Create(feedback)
+	return tx.Error
+}
+
+func (p *PostgreSQLStorage) DeleteFeedback(messageID string, version int) error {
+	return p.db.Delete(&Feedback{}, Feedback{MessageID: messageID, Version: version}).Error
+}
+
+func (p *PostgreSQLStorage) ListFeedbacks(opts ...SearchOption) ([]Feedback, error) {
+	searchOpt := applyOptions(nil, opts...)
+	query := Feedback{}
+	if searchOpt.ConversationID != nil {
+		query.ConversationID = *searchOpt.ConversationID
+	}
+	if searchOpt.MessageID != nil {
+		query.MessageID = *searchOpt.MessageID
+	}
+	if searchOpt.User != nil {
+		query.User = *searchOpt.User
+	}
+	if searchOpt.AppName != nil {
+		query.AppName = *searchOpt.AppName
+	}
+	if searchOpt.AppNamespace != nil {
+		query.AppNamespace = *searchOpt.AppNamespace
+	}
+	if searchOpt.Rating != nil {
+		query.Rating = *searchOpt.Rating
+	}
+	if searchOpt.Category != nil {
+		query.Category = *searchOpt.Category
+	}
+	tx := p.db.Where(query)
+	if searchOpt.StartDate != nil {
+		tx = tx.Where("created_at >= ?", *searchOpt.StartDate)
+	}
+	if searchOpt.EndDate != nil {
+		// the end date is included
+		end, err := time.Parse(time.DateOnly, *searchOpt.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Where("created_at < ?", end.AddDate(0, 0, 1))
+	}
+	res := make([]Feedback, 0)
+	if err := tx.Order("created_at DESC").Find(&res).Error; err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *PostgreSQLStorage) CreateShare(share *Share) error {
+	return p.db.Create(share).Error
+}
+
+func (p *PostgreSQLStorage) FindShare(token string) (*Share, error) {
+	res := &Share{}
+	tx := p.db.First(res, Share{Token: token})
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrShareNotFound
+		}
+		return nil, tx.Error
+	}
+	return res, nil
+}
+
+func (p *PostgreSQLStorage) ListShares(opts ...SearchOption) ([]Share, error) {
+	searchOpt := applyOptions(nil, opts...)
+	query := Share{}
+	if searchOpt.ConversationID != nil {
+		query.ConversationID = *searchOpt.ConversationID
+	}
+	if searchOpt.User != nil {
+		query.User = *searchOpt.User
+	}
+	res := make([]Share, 0)
+	if err := p.db.Where(query).Order("created_at DESC").Find(&res).Error; err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *PostgreSQLStorage) DeleteShare(token string, opts ...SearchOption) error {
+	searchOpt := applyOptions(nil, opts...)
+	query := Share{Token: token}
+	if searchOpt.User != nil {
+		query.User = *searchOpt.User
+	}
+	tx := p.db.Where(query).Delete(&Share{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+func (p *PostgreSQLStorage) AddUserMemory(memory *UserMemory) error {
+	return p.db.Create(memory).Error
+}
+
+func (p *PostgreSQLStorage) ListUserMemories(opts ...SearchOption) ([]UserMemory, error) {
+	searchOpt := applyOptions(nil, opts...)
+	query := UserMemory{}
+	if searchOpt.User != nil {
+		query.User = *searchOpt.User
+	}
+	res := make([]UserMemory, 0)
+	if err := p.db.Where(query).Order("created_at DESC").Find(&res).Error; err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *PostgreSQLStorage) DeleteUserMemory(id string, opts ...SearchOption) error {
+	searchOpt := applyOptions(nil, opts...)
+	query := UserMemory{ID: id}
+	if searchOpt.User != nil {
+		query.User = *searchOpt.User
+	}
+	tx := p.db.Where(query).Delete(&UserMemory{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrUserMemoryNotFound
+	}
+	return nil
+}
+
+func (p *PostgreSQLStorage) CreateHandoff(handoff *Handoff) error {
+	return p.db.Create(handoff).Error
+}
+
+func (p *PostgreSQLStorage) FindHandoff(id string, opts ...SearchOption) (*Handoff, error) {
+	res := &Handoff{}
+	tx := p.db.First(res, handoffQuery(Handoff{ID: id}, applyOptions(nil, opts...)))
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrHandoffNotFound
+		}
+		return nil, tx.Error
+	}
+	return res, nil
+}
+
+func (p *PostgreSQLStorage) ListHandoffs(opts ...SearchOption) ([]Handoff, error) {
+	res := make([]Handoff, 0)
+	if err := p.db.Where(handoffQuery(Handoff{}, applyOptions(nil, opts...))).Order("created_at ASC").Find(&res).Error; err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func handoffQuery(query Handoff, searchOpt *Search) Handoff {
+	if searchOpt.ConversationID != nil {
+		query.ConversationID = *searchOpt.ConversationID
+	}
+	if searchOpt.AppName != nil {
+		query.AppName = *searchOpt.AppName
+	}
+	if searchOpt.AppNamespace != nil {
+		query.AppNamespace = *searchOpt.AppNamespace
+	}
+	if searchOpt.HandoffStatus != nil {
+		query.Status = *searchOpt.HandoffStatus
+	}
+	return query
+}
+
+func (p *PostgreSQLStorage) UpdateHandoff(handoff *Handoff) error {
+	tx := p.db.Model(&Handoff{ID: handoff.ID}).Select("status", "operator", "resolved_at").Updates(handoff)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrHandoffNotFound
+	}
+	return nil
+}
+
+func (p *PostgreSQLStorage) AddGuardrailViolations(violations []GuardrailViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return p.db.Create(&violations).Error
+}
+
+func (p *PostgreSQLStorage) ListGuardrailViolations(opts ...SearchOption) ([]GuardrailViolation, error) {
+	searchOpt := applyOptions(nil, opts...)
+	query := GuardrailViolation{}
+	if searchOpt.ConversationID != nil {
+		query.ConversationID = *searchOpt.ConversationID
+	}
+	if searchOpt.AppName != nil {
+		query.AppName = *searchOpt.AppName
+	}
+	if searchOpt.AppNamespace != nil {
+		query.AppNamespace = *searchOpt.AppNamespace
+	}
+	if searchOpt.User != nil {
+		query.User = *searchOpt.User
+	}
+	tx := p.db.Where(query)
+	if searchOpt.StartDate != nil {
+		tx = tx.Where("created_at >= ?", *searchOpt.StartDate)
+	}
+	if searchOpt.EndDate != nil {
+		// the end date is included
+		end, err := time.Parse(time.DateOnly, *searchOpt.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Where("created_at < ?", end.AddDate(0, 0, 1))
+	}
+	if searchOpt.PageSize > 0 {
+		tx = tx.Offset(searchOpt.offset()).Limit(searchOpt.PageSize)
+	}
+	res := make([]GuardrailViolation, 0)
+	if err := tx.Order("created_at DESC").Find(&res).Error; err != nil {
+		return nil, err
+	}
+	return res, nil
+}
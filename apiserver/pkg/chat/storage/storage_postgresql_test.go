@@ -0,0 +1,52 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestMatchedConversations(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &PostgreSQLStorage{db: db}
+	scope := Conversation{User: "alice", AppName: "app", AppNamespace: "arcadia"}
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&Conversation{}).Where(scope).Where("title ILIKE ? OR id IN (?)", "%"+escapeLike("50%")+"%", p.matchedConversations(scope, "50%")).Find(&[]Conversation{})
+	})
+	if n := strings.Count(sql, " UNION "); n != 2 {
+		t.Errorf("expect the message matches in 3 branches of a union, got %d unions", n)
+	}
+	if strings.Contains(sql, " OR query ILIKE") || strings.Contains(sql, " OR answer ILIKE") {
+		t.Error("expect the substring matches not in an OR")
+	}
+	// each branch is limited to the conversations of the user and app, and the outer query too
+	for _, condition := range []string{`"user" = 'alice'`, `"app_name" = 'app'`, `"app_namespace" = 'arcadia'`} {
+		if n := strings.Count(sql, condition); n != 4 {
+			t.Errorf("expect %s in every branch and the outer query, got %d", condition, n)
+		}
+	}
+	if !strings.Contains(sql, `query ILIKE '%50\%%'`) || !strings.Contains(sql, `answer ILIKE '%50\%%'`) {
+		t.Error("expect the keyword escaped in the substring matches")
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/chains"
+	"github.com/tmc/langchaingo/prompts"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+)
+
+const (
+	// suggestedQuestionsLimit is the number of follow-up questions suggested after each answer
+	suggestedQuestionsLimit = 3
+	// suggestedQuestionsHistory is the max number of the recent history messages used to suggest questions
+	suggestedQuestionsHistory = 4
+	// suggestedQuestionsReferences is the max number of the references used to suggest questions
+	suggestedQuestionsReferences = 3
+	// timeout to suggest the questions by llm
+	suggestQuestionsTimeout = 20 * time.Second
+)
+
+const PromptForSuggestQuestions = `You are the user chatting with an AI assistant, please ask {{.limit}} follow-up questions you are most likely to ask next, based on the conversation and the reference information below.
+
+Requires language consistent with the conversation, questions answerable by the reference information if any, no restating of the asked questions, questions only, one question per line, no numbering.
+---
+Conversation:
+{{.history}}
+Human: {{.query}}
+AI: {{.answer}}
+---
+Reference information:
+{{.references}}
+---
+The questions are:`
+
+// suggestQuestions lets the llm of the application suggest the follow-up questions after the answer,
+// grounded in the recent history and the references of the answer. It returns nil if it fails.
+func (cs *ChatServer) suggestQuestions(ctx context.Context, app *v1alpha1.Application, history []langchaingoschema.ChatMessage, query, answer string, references []retriever.Reference) []string {
+	ctx, cancel := context.WithTimeout(ctx, suggestQuestionsTimeout)
+	defer cancel()
+	logger := klog.FromContext(ctx)
+	model, chainOptions, _, err := cs.appModel(ctx, app)
+	if err != nil || model == nil {
+		logger.V(3).Info("no llm in app to suggest the questions", "error", err)
+		return nil
+	}
+	if len(history) > suggestedQuestionsHistory {
+		history = history[len(history)-suggestedQuestionsHistory:]
+	}
+	historyString, err := langchaingoschema.GetBufferString(history, "Human", "AI")
+	if err != nil {
+		logger.Error(err, "failed to format the history to suggest the questions")
+		return nil
+	}
+	referenceContents := make([]string, 0, suggestedQuestionsReferences)
+	for i := 0; i < len(references) && i < suggestedQuestionsReferences; i++ {
+		content := references[i].Content
+		if references[i].Answer != "" {
+			content = references[i].Question + "\n" + references[i].Answer
+		}
+		referenceContents = append(referenceContents, truncate(content, 500))
+	}
+	p := prompts.NewPromptTemplate(PromptForSuggestQuestions, []string{"limit", "history", "query", "answer", "references"})
+	out, err := chains.Predict(ctx, chains.NewLLMChain(model, p, chainOptions...), map[string]any{
+		"limit":      suggestedQuestionsLimit,
+		"history":    truncate(historyString, 2000),
+		"query":      truncate(query, 1000),
+		"answer":     truncate(answer, 1000),
+		"references": strings.Join(referenceContents, "\n\n"),
+	})
+	if err != nil {
+		logger.Error(err, "failed to suggest the questions")
+		return nil
+	}
+	return parseQuestions(out, query, suggestedQuestionsLimit)
+}
+
+// questionPrefix is the bullet or numbering before a question
+var questionPrefix = regexp.MustCompile(`^([-*•]|\d+[.、)）])\s*`)
+
+// parseQuestions returns at most limit distinct questions in the answer of the llm, one question per line
+func parseQuestions(out, query string, limit int) []string {
+	questions := make([]string, 0, limit)
+	seen := map[string]bool{strings.TrimSpace(query): true}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(questionPrefix.ReplaceAllString(strings.TrimSpace(line), ""))
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		questions = append(questions, line)
+		if len(questions) == limit {
+			break
+		}
+	}
+	return questions
+}
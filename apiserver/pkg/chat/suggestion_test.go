@@ -0,0 +1,38 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuestions(t *testing.T) {
+	tests := []struct {
+		out  string
+		want []string
+	}{
+		{out: "", want: []string{}},
+		{out: "1. How many days?\n2、What about sick leave?\n\n- Who approves it?\n* One more?", want: []string{"How many days?", "What about sick leave?", "Who approves it?"}},
+		{out: "2024年的假期有几天？\n2024年的假期有几天？\n旷工最小计算单位为多少天？", want: []string{"2024年的假期有几天？"}},
+	}
+	for _, tt := range tests {
+		if got := parseQuestions(tt.out, "旷工最小计算单位为多少天？", 3); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseQuestions(%q) = %q, want %q", tt.out, got, tt.want)
+		}
+	}
+}
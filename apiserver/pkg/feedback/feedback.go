@@ -0,0 +1,301 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feedback
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/graph/generated"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	apiserverclient "github.com/kubeagi/arcadia/apiserver/pkg/client"
+	pkgconfig "github.com/kubeagi/arcadia/pkg/config"
+	"github.com/kubeagi/arcadia/pkg/documentloaders"
+)
+
+// columns of the exported csv besides the question and the ground truth
+const (
+	badAnswerColumn = "bad_answer"
+	categoryColumn  = "category"
+	commentColumn   = "comment"
+
+	// feedbackDir is the directory of the exported files in the system datasource and the versioned datasets
+	feedbackDir = "feedback"
+)
+
+// feedbackStorage returns the storage of the feedbacks
+var feedbackStorage = func(ctx context.Context) (storage.Storage, error) {
+	systemCli, err := apiserverclient.GetClient(nil)
+	if err != nil {
+		return nil, err
+	}
+	return storage.Default(ctx, systemCli), nil
+}
+
+// listFeedbacks returns the feedbacks of the applications in the namespace.
+// c is the client of current user, only users who can list applications in the namespace can get the feedbacks.
+func listFeedbacks(ctx context.Context, c client.Client, namespace string, opts ...storage.SearchOption) ([]storage.Feedback, error) {
+	if err := c.List(ctx, &v1alpha1.ApplicationList{}, client.InNamespace(namespace), client.Limit(1)); err != nil {
+		return nil, fmt.Errorf("no permission to get feedbacks in namespace %s: %w", namespace, err)
+	}
+	s, err := feedbackStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListFeedbacks(append(opts, storage.WithAppNamespace(namespace))...)
+}
+
+func searchOptions(input generated.ListFeedbackInput) []storage.SearchOption {
+	return []storage.SearchOption{
+		storage.WithAppName(pointer.StringDeref(input.AppName, "")),
+		storage.WithRating(storage.FeedbackRating(pointer.StringDeref(input.Rating, ""))),
+		storage.WithCategory(pointer.StringDeref(input.Category, "")),
+		storage.WithDateRange(pointer.StringDeref(input.StartDate, ""), pointer.StringDeref(input.EndDate, "")),
+	}
+}
+
+// ListFeedbacks returns the feedbacks of the applications in the namespace, the latest first.
+func ListFeedbacks(ctx context.Context, c client.Client, input generated.ListFeedbackInput) ([]*generated.Feedback, error) {
+	feedbacks, err := listFeedbacks(ctx, c, input.Namespace, searchOptions(input)...)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*generated.Feedback, 0, len(feedbacks))
+	for _, f := range feedbacks {
+		f := f
+		res = append(res, &generated.Feedback{
+			MessageID:         f.MessageID,
+			Version:           f.Version,
+			ConversationID:    f.ConversationID,
+			Namespace:         f.AppNamespace,
+			AppName:           f.AppName,
+			User:              f.User,
+			Rating:            string(f.Rating),
+			Category:          &f.Category,
+			Comment:           &f.Comment,
+			ExpectedAnswer:    &f.ExpectedAnswer,
+			Query:             f.Query,
+			Answer:            f.Answer,
+			CreationTimestamp: f.CreatedAt,
+			UpdateTimestamp:   f.UpdatedAt,
+		})
+	}
+	return res, nil
+}
+
+// SummarizeFeedbacks returns the count of likes and dislikes of each application in the namespace,
+// and the categories of dislikes so that app owners can find weak spots.
+func SummarizeFeedbacks(ctx context.Context, c client.Client, input generated.ListFeedbackInput) ([]*generated.FeedbackSummary, error) {
+	feedbacks, err := listFeedbacks(ctx, c, input.Namespace, searchOptions(input)...)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make(map[string]*generated.FeedbackSummary)
+	categories := make(map[string]map[string]int64)
+	for _, f := range feedbacks {
+		summary, ok := summaries[f.AppName]
+		if !ok {
+			summary = &generated.FeedbackSummary{Namespace: f.AppNamespace, AppName: f.AppName}
+			summaries[f.AppName] = summary
+			categories[f.AppName] = make(map[string]int64)
+		}
+		summary.Total++
+		switch f.Rating {
+		case storage.FeedbackLike:
+			summary.Likes++
+		case storage.FeedbackDislike:
+			summary.Dislikes++
+			categories[f.AppName][f.Category]++
+		}
+	}
+	res := make([]*generated.FeedbackSummary, 0, len(summaries))
+	for app, summary := range summaries {
+		summary.Categories = make([]*generated.FeedbackCategoryCount, 0, len(categories[app]))
+		for category, count := range categories[app] {
+			summary.Categories = append(summary.Categories, &generated.FeedbackCategoryCount{Category: category, Dislikes: count})
+		}
+		sort.Slice(summary.Categories, func(i, j int) bool {
+			if summary.Categories[i].Dislikes != summary.Categories[j].Dislikes {
+				return summary.Categories[i].Dislikes > summary.Categories[j].Dislikes
+			}
+			return summary.Categories[i].Category < summary.Categories[j].Category
+		})
+		res = append(res, summary)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].AppName < res[j].AppName })
+	return res, nil
+}
+
+// ExportFeedbacks writes the disliked answers of the application into a QA csv file of the versioned dataset,
+// which can be used by RAG evaluation. The expected answer given by the user is the ground truth,
+// so answers without the expected answer are not exported.
+// The file is stored in the system datasource and added to the files of the versioned dataset, which copies it.
+// It returns the path of the file in the versioned dataset.
+func ExportFeedbacks(ctx context.Context, c client.Client, input generated.ExportFeedbackInput) (string, error) {
+	fileName := pointer.StringDeref(input.FileName, "")
+	if fileName == "" {
+		fileName = fmt.Sprintf("feedback-%s-%s.csv", input.AppName, time.Now().Format("20060102150405"))
+	}
+	if !strings.HasSuffix(fileName, ".csv") || strings.Contains(fileName, "/") {
+		return "", fmt.Errorf("invalid file name %s, should be a csv file", fileName)
+	}
+	// the user must be able to update the versioned dataset
+	vds := &v1alpha1.VersionedDataset{}
+	key := types.NamespacedName{Namespace: input.Namespace, Name: input.VersionedDataset}
+	if err := c.Get(ctx, key, vds); err != nil {
+		return "", err
+	}
+	if err := c.Update(ctx, vds, client.DryRunAll); err != nil {
+		return "", fmt.Errorf("no permission to update versioned dataset %s: %w", input.VersionedDataset, err)
+	}
+	if vds.Spec.Dataset == nil {
+		return "", fmt.Errorf("versioned dataset %s has no dataset", vds.Name)
+	}
+	// files in the system datasource are shared by all namespaces
+	path := fmt.Sprintf("%s/%s/%s", feedbackDir, input.Namespace, fileName)
+	if hasFile(vds, path) {
+		return "", fmt.Errorf("file %s already exists in versioned dataset %s", path, vds.Name)
+	}
+
+	feedbacks, err := listFeedbacks(ctx, c, input.Namespace,
+		storage.WithAppName(input.AppName),
+		storage.WithRating(storage.FeedbackDislike),
+		storage.WithCategory(pointer.StringDeref(input.Category, "")),
+		storage.WithDateRange(pointer.StringDeref(input.StartDate, ""), pointer.StringDeref(input.EndDate, "")),
+	)
+	if err != nil {
+		return "", err
+	}
+	data, rows, err := feedbackCSV(feedbacks)
+	if err != nil {
+		return "", err
+	}
+	if rows == 0 {
+		return "", fmt.Errorf("no disliked answers with expected answers found in application %s", input.AppName)
+	}
+
+	systemDatasource, err := pkgconfig.GetSystemDatasource(ctx)
+	if err != nil {
+		return "", err
+	}
+	oss, err := pkgconfig.GetSystemDatasourceOSS(ctx)
+	if err != nil {
+		return "", err
+	}
+	// the same bucket where the versioned dataset copies files from
+	bucket := systemDatasource.Namespace
+	if systemDatasource.Spec.OSS != nil {
+		bucket = systemDatasource.Spec.OSS.Bucket
+	}
+	_, err = oss.Client.PutObject(ctx, bucket, path, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: "text/csv",
+		UserTags: map[string]string{
+			v1alpha1.ObjectTypeTag:  v1alpha1.ObjectTypeQA,
+			v1alpha1.ObjectCountTag: strconv.Itoa(rows),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", path, err)
+	}
+
+	source := &v1alpha1.TypedObjectReference{
+		APIGroup:  &v1alpha1.GroupVersion.Group,
+		Kind:      "Datasource",
+		Name:      systemDatasource.Name,
+		Namespace: &systemDatasource.Namespace,
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := c.Get(ctx, key, vds); err != nil {
+			return err
+		}
+		addFile(vds, source, path)
+		return c.Update(ctx, vds)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to add %s to versioned dataset %s: %w", path, vds.Name, err)
+	}
+	return path, nil
+}
+
+// feedbackCSV returns the QA csv of the disliked answers with expected answers, and the number of its rows.
+// The same query is exported only once with its latest expected answer.
+func feedbackCSV(feedbacks []storage.Feedback) ([]byte, int, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	_ = w.Write([]string{documentloaders.QuestionCol, documentloaders.AnswerCol, badAnswerColumn, categoryColumn, commentColumn})
+	rows := 0
+	exported := make(map[string]bool, len(feedbacks))
+	// the latest feedback is the first
+	for _, f := range feedbacks {
+		// no ground truth to evaluate with
+		if strings.TrimSpace(f.ExpectedAnswer) == "" || exported[f.Query] {
+			continue
+		}
+		exported[f.Query] = true
+		_ = w.Write([]string{f.Query, f.ExpectedAnswer, f.Answer, f.Category, f.Comment})
+		rows++
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), rows, nil
+}
+
+// hasFile returns whether the path is a file of the versioned dataset
+func hasFile(vds *v1alpha1.VersionedDataset, path string) bool {
+	for _, group := range vds.Spec.FileGroups {
+		for _, file := range group.Files {
+			if file.Path == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addFile adds the path of the source to the files of the versioned dataset
+func addFile(vds *v1alpha1.VersionedDataset, source *v1alpha1.TypedObjectReference, path string) {
+	if hasFile(vds, path) {
+		return
+	}
+	for i := range vds.Spec.FileGroups {
+		group := &vds.Spec.FileGroups[i]
+		if group.Source != nil && group.Source.Kind == source.Kind && group.Source.Name == source.Name &&
+			group.Source.GetNamespace(vds.Namespace) == source.GetNamespace(vds.Namespace) {
+			group.Files = append(group.Files, v1alpha1.FileWithVersion{Path: path})
+			return
+		}
+	}
+	vds.Spec.FileGroups = append(vds.Spec.FileGroups, v1alpha1.FileGroup{
+		Source: source,
+		Files:  []v1alpha1.FileWithVersion{{Path: path}},
+	})
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feedback
+
+import (
+	"context"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/graph/generated"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	apiserverclient "github.com/kubeagi/arcadia/apiserver/pkg/client"
+)
+
+func withFeedbacks(t *testing.T, feedbacks ...storage.Feedback) {
+	s := storage.NewMemoryStorage()
+	for i := range feedbacks {
+		if err := s.UpdateFeedback(&feedbacks[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	origin := feedbackStorage
+	feedbackStorage = func(ctx context.Context) (storage.Storage, error) { return s, nil }
+	t.Cleanup(func() { feedbackStorage = origin })
+}
+
+func newClient(objs ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(apiserverclient.Scheme).WithObjects(objs...).Build()
+}
+
+func TestSummarizeFeedbacks(t *testing.T) {
+	feedback := func(id, app string, rating storage.FeedbackRating, category string) storage.Feedback {
+		return storage.Feedback{MessageID: id, AppName: app, AppNamespace: "arcadia", Rating: rating, Category: category}
+	}
+	withFeedbacks(t,
+		feedback("m1", "chat", storage.FeedbackLike, ""),
+		feedback("m2", "chat", storage.FeedbackDislike, "inaccurate"),
+		feedback("m3", "chat", storage.FeedbackDislike, "incomplete"),
+		feedback("m4", "chat", storage.FeedbackDislike, "inaccurate"),
+		feedback("m5", "search", storage.FeedbackLike, ""),
+		storage.Feedback{MessageID: "m6", AppName: "chat", AppNamespace: "other", Rating: storage.FeedbackDislike},
+	)
+	summaries, err := SummarizeFeedbacks(context.Background(), newClient(), generated.ListFeedbackInput{Namespace: "arcadia"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*generated.FeedbackSummary{
+		{Namespace: "arcadia", AppName: "chat", Total: 4, Likes: 1, Dislikes: 3, Categories: []*generated.FeedbackCategoryCount{
+			{Category: "inaccurate", Dislikes: 2},
+			{Category: "incomplete", Dislikes: 1},
+		}},
+		{Namespace: "arcadia", AppName: "search", Total: 1, Likes: 1, Categories: []*generated.FeedbackCategoryCount{}},
+	}
+	if !reflect.DeepEqual(summaries, expected) {
+		t.Errorf("expect %+v, got %+v", expected, summaries)
+	}
+
+	summaries, err = SummarizeFeedbacks(context.Background(), newClient(), generated.ListFeedbackInput{Namespace: "arcadia", AppName: pointer.String("chat"), Category: pointer.String("incomplete")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 1 || summaries[0].Total != 1 || summaries[0].Dislikes != 1 {
+		t.Errorf("expect the incomplete dislike of chat, got %+v", summaries)
+	}
+}
+
+func TestFeedbackCSV(t *testing.T) {
+	// the latest feedback is the first
+	data, rows, err := feedbackCSV([]storage.Feedback{
+		{Query: "q1", Answer: "a1 new", ExpectedAnswer: "e1 new", Category: "inaccurate", Comment: "wrong"},
+		{Query: "q2", Answer: "a2", ExpectedAnswer: " "},
+		{Query: "q1", Answer: "a1", ExpectedAnswer: "e1"},
+		{Query: "q3", Answer: "a3 new"},
+		{Query: "q3", Answer: "a3", ExpectedAnswer: "e3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]string{
+		{"q", "a", "bad_answer", "category", "comment"},
+		{"q1", "e1 new", "a1 new", "inaccurate", "wrong"},
+		{"q3", "e3", "a3", "", ""},
+	}
+	if rows != 2 || !reflect.DeepEqual(records, expected) {
+		t.Errorf("expect %v, got %d rows %v", expected, rows, records)
+	}
+
+	if _, rows, _ = feedbackCSV([]storage.Feedback{{Query: "q1", Answer: "a1"}}); rows != 0 {
+		t.Errorf("expect no rows without expected answers, got %d", rows)
+	}
+}
+
+func TestAddFile(t *testing.T) {
+	system := &v1alpha1.TypedObjectReference{Kind: "Datasource", Name: "datasource-sample", Namespace: pointer.String("arcadia")}
+	vds := &v1alpha1.VersionedDataset{ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: "dataset-v1"}}
+
+	addFile(vds, system, "feedback/arcadia/a.csv")
+	if len(vds.Spec.FileGroups) != 1 || !hasFile(vds, "feedback/arcadia/a.csv") {
+		t.Fatalf("expect a new file group, got %+v", vds.Spec.FileGroups)
+	}
+	addFile(vds, &v1alpha1.TypedObjectReference{Kind: "Datasource", Name: "local"}, "b.txt")
+	addFile(vds, system, "feedback/arcadia/c.csv")
+	addFile(vds, system, "feedback/arcadia/a.csv")
+	expected := []v1alpha1.FileWithVersion{{Path: "feedback/arcadia/a.csv"}, {Path: "feedback/arcadia/c.csv"}}
+	if len(vds.Spec.FileGroups) != 2 || !reflect.DeepEqual(vds.Spec.FileGroups[0].Files, expected) {
+		t.Errorf("expect files of the system datasource in one group, got %+v", vds.Spec.FileGroups)
+	}
+	if hasFile(vds, "c.csv") {
+		t.Error("expect the full path to match")
+	}
+}
+
+func TestExportFeedbacks(t *testing.T) {
+	withFeedbacks(t,
+		storage.Feedback{MessageID: "m1", AppName: "chat", AppNamespace: "arcadia", Rating: storage.FeedbackDislike, Query: "q1", Answer: "a1"},
+		storage.Feedback{MessageID: "m2", AppName: "chat", AppNamespace: "arcadia", Rating: storage.FeedbackLike, Query: "q2", Answer: "a2", ExpectedAnswer: "e2"},
+	)
+	vds := &v1alpha1.VersionedDataset{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: "dataset-v1"},
+		Spec: v1alpha1.VersionedDatasetSpec{
+			Dataset: &v1alpha1.TypedObjectReference{Kind: "Dataset", Name: "dataset"},
+			Version: "v1",
+			FileGroups: []v1alpha1.FileGroup{{
+				Source: &v1alpha1.TypedObjectReference{Kind: "Datasource", Name: "datasource-sample"},
+				Files:  []v1alpha1.FileWithVersion{{Path: "feedback/arcadia/exists.csv"}},
+			}},
+		},
+	}
+	input := func(fileName string) generated.ExportFeedbackInput {
+		return generated.ExportFeedbackInput{Namespace: "arcadia", AppName: "chat", VersionedDataset: "dataset-v1", FileName: pointer.String(fileName)}
+	}
+	tests := []struct {
+		name  string
+		input generated.ExportFeedbackInput
+		err   string
+	}{
+		{"not a csv file", input("feedback.txt"), "invalid file name"},
+		{"file in a directory", input("a/feedback.csv"), "invalid file name"},
+		{"versioned dataset not found", generated.ExportFeedbackInput{Namespace: "arcadia", AppName: "chat", VersionedDataset: "not-found"}, "not found"},
+		{"file exists", input("exists.csv"), "already exists"},
+		{"no expected answers", input("feedback.csv"), "no disliked answers with expected answers"},
+	}
+	for _, test := range tests {
+		_, err := ExportFeedbacks(context.Background(), newClient(vds.DeepCopy()), test.input)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expect error with %q, got %v", test.name, test.err, err)
+		}
+	}
+}
@@ -71,7 +71,7 @@ func knowledgebase2model(ctx context.Context, c client.Client, knowledgebase *v1
 	// https://github.com/kubeagi/arcadia/issues/874
 	cache := make(map[string][2]int)
 	var filegroupdetails []*generated.Filegroupdetail
-	for out, fg := range knowledgebase.Spec.FileGroups {
+	for out, fg := range knowledgebase.FileGroups() {
 		groupFiles := make([]*generated.Filedetail, 0)
 		ns := knowledgebase.Namespace
 		if fg.Source.Namespace != nil {
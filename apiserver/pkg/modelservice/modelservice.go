@@ -314,7 +314,7 @@ func CheckModelService(ctx context.Context, c client.Client, input generated.Cre
 		}
 
 		switch *input.APIType {
-		case "openai":
+		case "openai", "openai-compatible":
 			info, err = checkOpenAI(ctx, input)
 		case "zhipuai":
 			info, err = checkZhipuAI(ctx, input)
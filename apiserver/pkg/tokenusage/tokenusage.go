@@ -0,0 +1,66 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenusage
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/graph/generated"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	apiserverclient "github.com/kubeagi/arcadia/apiserver/pkg/client"
+)
+
+// ListTokenUsages returns the daily token usages of the applications in the namespace.
+// c is the client of current user, only users who can list applications in the namespace can get the usages.
+func ListTokenUsages(ctx context.Context, c client.Client, input generated.ListTokenUsageInput) ([]*generated.TokenUsage, error) {
+	if err := c.List(ctx, &v1alpha1.ApplicationList{}, client.InNamespace(input.Namespace), client.Limit(1)); err != nil {
+		return nil, fmt.Errorf("no permission to get token usages in namespace %s: %w", input.Namespace, err)
+	}
+	systemCli, err := apiserverclient.GetClient(nil)
+	if err != nil {
+		return nil, err
+	}
+	opts := []storage.SearchOption{
+		storage.WithAppNamespace(input.Namespace),
+		storage.WithAppName(pointer.StringDeref(input.AppName, "")),
+		storage.WithUser(pointer.StringDeref(input.User, "")),
+		storage.WithDateRange(pointer.StringDeref(input.StartDate, ""), pointer.StringDeref(input.EndDate, "")),
+	}
+	usages, err := storage.Default(ctx, systemCli).ListTokenUsages(opts...)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*generated.TokenUsage, 0, len(usages))
+	for _, u := range usages {
+		res = append(res, &generated.TokenUsage{
+			Date:             u.Date,
+			Namespace:        u.AppNamespace,
+			AppName:          u.AppName,
+			User:             u.User,
+			PromptTokens:     u.PromptTokens,
+			CompletionTokens: u.CompletionTokens,
+			TotalTokens:      u.TotalTokens,
+			Requests:         u.Requests,
+		})
+	}
+	return res, nil
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
@@ -35,6 +36,7 @@ import (
 	"github.com/kubeagi/arcadia/apiserver/config"
 	"github.com/kubeagi/arcadia/apiserver/pkg/auth"
 	"github.com/kubeagi/arcadia/apiserver/pkg/chat"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
 	"github.com/kubeagi/arcadia/apiserver/pkg/client"
 	"github.com/kubeagi/arcadia/apiserver/pkg/oidc"
 	"github.com/kubeagi/arcadia/apiserver/pkg/requestid"
@@ -64,7 +66,7 @@ func NewChatService(cli runtimeclient.Client, isGpts bool) (*ChatService, error)
 // @Param			namespace	header		string				true	"namespace this request is in"
 // @Param			debug		query		bool				false	"Should the chat request be treated as debugging?"
 // @Param			request		body		chat.ChatReqBody	true	"query params"
-// @Success		200			{object}	chat.ChatRespBody	"blocking mode, will return all field; streaming mode, only conversation_id, message and created_at will be returned"
+// @Success		200			{object}	chat.ChatRespBody	"blocking mode, will return all field; streaming mode, only conversation_id, message and created_at will be returned, then suggested_questions in a suggested_questions event if the application shows the next guide, or a handoff event followed by human_reply events if the conversation is waiting for human operators"
 // @Failure		400			{object}	chat.ErrorResp
 // @Failure		500			{object}	chat.ErrorResp
 // @Router			/chat [post]
@@ -82,114 +84,192 @@ func (cs *ChatService) ChatHandler() gin.HandlerFunc {
 			req.ConversationID = string(uuid.NewUUID())
 		}
 		messageID := string(uuid.NewUUID())
-		var response *chat.ChatRespBody
-		var err error
-		logger := klog.FromContext(c.Request.Context())
-		chatTimeoutSecond := pointer.Float64(WaitTimeoutForChatStreaming)
-
-		if req.ResponseMode.IsStreaming() {
-			buf := strings.Builder{}
-			// handle chat streaming mode
-			respStream := make(chan string, 1)
-			manualStop := make(chan bool)
-			go func() {
-				defer func() {
-					if e := recover(); e != nil {
-						err, ok := e.(error)
-						if ok {
-							logger.Error(err, "A panic occurred when run chat.AppRun")
-						} else {
-							logger.Error(fmt.Errorf("get err:%#v", e), "A panic occurred when run chat.AppRun")
-						}
+		cs.chat(c, req, messageID)
+	}
+}
+
+// chat runs the application and writes the answer in blocking or streaming mode
+func (cs *ChatService) chat(c *gin.Context, req chat.ChatReqBody, messageID string) {
+	var response *chat.ChatRespBody
+	var err error
+	logger := klog.FromContext(c.Request.Context())
+	chatTimeoutSecond := pointer.Float64(WaitTimeoutForChatStreaming)
+
+	if req.ResponseMode.IsStreaming() {
+		buf := strings.Builder{}
+		// handle chat streaming mode
+		respStream := make(chan string, 1)
+		manualStop := make(chan bool)
+		suggestedQuestions := make(chan []string)
+		handoffs := make(chan *chat.ChatRespBody)
+		// replies of the human operators, subscribed after the conversation is handed off
+		var humanReplies <-chan storage.Message
+		unsubscribe := func() {}
+		defer func() { unsubscribe() }()
+		go func() {
+			defer func() {
+				if e := recover(); e != nil {
+					err, ok := e.(error)
+					if ok {
+						logger.Error(err, "A panic occurred when run chat.AppRun")
+					} else {
+						logger.Error(fmt.Errorf("get err:%#v", e), "A panic occurred when run chat.AppRun")
+					}
+				}
+			}()
+			response, err = cs.server.AppRun(c.Request.Context(), req, respStream, messageID, chatTimeoutSecond)
+			if err != nil {
+				c.SSEvent("error", chat.ChatRespBody{
+					MessageID:      messageID,
+					ConversationID: req.ConversationID,
+					Message:        err.Error(),
+					CreatedAt:      time.Now(),
+					Latency:        time.Since(req.StartTime).Milliseconds(),
+				})
+				// c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
+				logger.Error(err, "error resp, stop the stream")
+				manualStop <- true
+				return
+			}
+			if response != nil {
+				if response.PendingHuman {
+					// the stream is kept for the replies of the human operators until the chat times out
+					select {
+					case handoffs <- response:
+					case <-c.Request.Context().Done():
 					}
-				}()
-				response, err = cs.server.AppRun(c.Request.Context(), req, respStream, messageID, chatTimeoutSecond)
-				if err != nil {
-					c.SSEvent("error", chat.ChatRespBody{
-						MessageID:      messageID,
-						ConversationID: req.ConversationID,
-						Message:        err.Error(),
-						CreatedAt:      time.Now(),
-						Latency:        time.Since(req.StartTime).Milliseconds(),
-					})
-					// c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
-					logger.Error(err, "error resp, stop the stream")
-					manualStop <- true
 					return
 				}
-				if response != nil {
-					if str := buf.String(); response.Message == str || strings.TrimSpace(str) == strings.TrimSpace(response.Message) {
-						logger.Info("blocking resp is same with streaming resp, no new message received, stop the stream")
-						manualStop <- true
+				if len(response.SuggestedQuestions) > 0 {
+					// sent after the answer, so the answer is all received when the stream is checked below
+					select {
+					case suggestedQuestions <- response.SuggestedQuestions:
+					case <-c.Request.Context().Done():
 					}
 				}
-			}()
+				if str := buf.String(); response.Message == str || strings.TrimSpace(str) == strings.TrimSpace(response.Message) {
+					logger.Info("blocking resp is same with streaming resp, no new message received, stop the stream")
+					manualStop <- true
+				}
+			}
+		}()
 
-			LatestTimestampGetDataFromLLM := time.Now()
-			// Use ticker to check if there is no data from llm for a long time and close the entire stream
-			ticker := time.NewTicker(time.Microsecond * 500)
-			defer ticker.Stop()
-			go func() {
-				for range ticker.C {
-					timeout := time.Second * time.Duration(*chatTimeoutSecond)
-					if time.Since(LatestTimestampGetDataFromLLM) > timeout {
-						logger.Info("no data from LLM for a long time, stop the stream", "timeout", timeout)
-						manualStop <- true
+		LatestTimestampGetDataFromLLM := time.Now()
+		// Use ticker to check if there is no data from llm for a long time and close the entire stream
+		ticker := time.NewTicker(time.Microsecond * 500)
+		defer ticker.Stop()
+		go func() {
+			for range ticker.C {
+				timeout := time.Second * time.Duration(*chatTimeoutSecond)
+				if time.Since(LatestTimestampGetDataFromLLM) > timeout {
+					logger.Info("no data from LLM for a long time, stop the stream", "timeout", timeout)
+					manualStop <- true
+					return
+				}
+			}
+		}()
+		c.Writer.Header().Set("Content-Type", "text/event-stream")
+		c.Writer.Header().Set("Cache-Control", "no-cache")
+		c.Writer.Header().Set("Connection", "keep-alive")
+		c.Writer.Header().Set("Transfer-Encoding", "chunked")
+		logger.Info("start to receive messages...")
+		sendMessage := func(msg string) {
+			t := time.Now()
+			c.SSEvent("", chat.ChatRespBody{
+				MessageID:      messageID,
+				ConversationID: req.ConversationID,
+				Message:        msg,
+				CreatedAt:      t,
+				Latency:        time.Since(req.StartTime).Milliseconds(),
+			})
+			LatestTimestampGetDataFromLLM = t
+			buf.WriteString(msg)
+		}
+		// drainAnswer sends the rest of the answer, which is done
+		drainAnswer := func() {
+			for {
+				select {
+				case msg, ok := <-respStream:
+					if !ok {
 						return
 					}
+					sendMessage(msg)
+				default:
+					return
 				}
-			}()
-			c.Writer.Header().Set("Content-Type", "text/event-stream")
-			c.Writer.Header().Set("Cache-Control", "no-cache")
-			c.Writer.Header().Set("Connection", "keep-alive")
-			c.Writer.Header().Set("Transfer-Encoding", "chunked")
-			logger.Info("start to receive messages...")
-			clientDisconnected := c.Stream(func(w io.Writer) bool {
-				for {
-					select {
-					case <-manualStop:
+			}
+		}
+		clientDisconnected := c.Stream(func(w io.Writer) bool {
+			for {
+				select {
+				case <-manualStop:
+					return false
+				case msg, ok := <-respStream:
+					if !ok {
 						return false
-					case msg, ok := <-respStream:
-						if !ok {
-							return false
-						}
-						t := time.Now()
-						c.SSEvent("", chat.ChatRespBody{
-							MessageID:      messageID,
-							ConversationID: req.ConversationID,
-							Message:        msg,
-							CreatedAt:      t,
-							Latency:        time.Since(req.StartTime).Milliseconds(),
-						})
-						LatestTimestampGetDataFromLLM = t
-						buf.WriteString(msg)
-						return true
 					}
+					sendMessage(msg)
+					return true
+				case questions := <-suggestedQuestions:
+					// the answer is done, send the rest of it before the suggested questions
+					drainAnswer()
+					c.SSEvent("suggested_questions", chat.ChatRespBody{
+						MessageID:          messageID,
+						ConversationID:     req.ConversationID,
+						CreatedAt:          time.Now(),
+						Latency:            time.Since(req.StartTime).Milliseconds(),
+						SuggestedQuestions: questions,
+					})
+					return true
+				case resp := <-handoffs:
+					drainAnswer()
+					c.SSEvent("handoff", chat.ChatRespBody{
+						MessageID:      messageID,
+						ConversationID: req.ConversationID,
+						Message:        resp.Handoff,
+						CreatedAt:      time.Now(),
+						Latency:        time.Since(req.StartTime).Milliseconds(),
+						PendingHuman:   true,
+					})
+					humanReplies, unsubscribe = cs.server.SubscribeHumanReplies(req.ConversationID)
+					LatestTimestampGetDataFromLLM = time.Now()
+					return true
+				case reply := <-humanReplies:
+					c.SSEvent("human_reply", chat.ChatRespBody{
+						MessageID:      reply.ID,
+						ConversationID: req.ConversationID,
+						Action:         chat.ActionHuman,
+						Message:        reply.Answer,
+						CreatedAt:      time.Now(),
+						PendingHuman:   true,
+					})
+					LatestTimestampGetDataFromLLM = time.Now()
+					return true
 				}
-			})
-			if clientDisconnected {
-				manualStop <- true
-				logger.Info("chatHandler: the client is disconnected")
 			}
-			logger.Info("end to receive messages")
-		} else {
-			// handle chat blocking mode
-			response, err = cs.server.AppRun(c.Request.Context(), req, nil, messageID, chatTimeoutSecond)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
-				logger.Error(err, "error resp")
-				return
-			}
-			c.JSON(http.StatusOK, response)
+		})
+		if clientDisconnected {
+			manualStop <- true
+			logger.Info("chatHandler: the client is disconnected")
 		}
-		switch {
-		case logger.V(5).Enabled():
-			logger.Info("chat done", "req", req, "resp", response)
-		case logger.V(3).Enabled():
-			logger.Info("chat done", "req", req)
-		default:
-			logger.Info("chat done")
+		logger.Info("end to receive messages")
+	} else {
+		// handle chat blocking mode
+		response, err = cs.server.AppRun(c.Request.Context(), req, nil, messageID, chatTimeoutSecond)
+		if err != nil {
+			c.JSON(chatErrorCode(err), chat.ErrorResp{Err: err.Error()})
+			logger.Error(err, "error resp")
+			return
 		}
+		c.JSON(http.StatusOK, response)
+	}
+	switch {
+	case logger.V(5).Enabled():
+		logger.Info("chat done", "req", req, "resp", response)
+	case logger.V(3).Enabled():
+		logger.Info("chat done", "req", req)
+	default:
+		logger.Info("chat done")
 	}
 }
 
@@ -203,7 +283,8 @@ func (cs *ChatService) ChatHandler() gin.HandlerFunc {
 // @Param			namespace		header		string	true	"namespace this request is in"
 // @Param			app_name		formData	string	true	"The app name for this conversation"
 // @Param			conversation_id	formData	string	false	"The conversation id for this file"
-// @Param			file			formData	file	true	"This is the file for the conversation"
+// @Param			files			formData	file	false	"These are the files for the conversation, limited by the conversation file config of the app"
+// @Param			file			formData	file	false	"This is the file for the conversation, deprecated and use files instead"
 //
 // @Success		200				{object}	chat.ChatRespBody
 // @Failure		400				{object}	chat.ErrorResp
@@ -237,19 +318,25 @@ func (cs *ChatService) ChatFile() gin.HandlerFunc {
 			req.ConversationID = string(uuid.NewUUID())
 		}
 
-		file, err := c.FormFile("file")
+		form, err := c.MultipartForm()
 		if err != nil {
 			klog.FromContext(c.Request.Context()).Error(err, "error receive conversational file")
 			c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: err.Error()})
 			return
 		}
+		// file is kept for the clients uploading one file per request
+		files := append(form.File["files"], form.File["file"]...)
 
 		messageID := string(uuid.NewUUID())
-		// Upload the file to specific dst.
-		resp, err := cs.server.ReceiveConversationFile(c.Request.Context(), messageID, req, file)
+		// Upload the files to specific dst.
+		resp, err := cs.server.ReceiveConversationFile(c.Request.Context(), messageID, req, files)
 		if err != nil {
 			klog.FromContext(c.Request.Context()).Error(err, "error receive conversational file")
-			c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
+			code := http.StatusInternalServerError
+			if errors.Is(err, chat.ErrInvalidFile) || errors.Is(err, chat.ErrFileNotUploaded) {
+				code = http.StatusBadRequest
+			}
+			c.JSON(code, chat.ErrorResp{Err: err.Error()})
 			return
 		}
 		c.JSON(http.StatusOK, resp)
@@ -259,19 +346,19 @@ func (cs *ChatService) ChatFile() gin.HandlerFunc {
 
 // @Summary	list all conversations
 // @Schemes
-// @Description	list all conversations
+// @Description	list all conversations, pinned conversations come first, then the latest updated. Search the title, queries and answers with keyword
 // @Tags			application
 // @Accept			json
 // @Produce		json
-// @Param			namespace	header		string				true	"namespace this request is in"
-// @Param			request		body		chat.APPMetadata	false	"query params, if not set will return all current user's conversations"
+// @Param			namespace	header		string						true	"namespace this request is in"
+// @Param			request		body		chat.ListConversationReqBody	false	"query params, if not set will return all current user's conversations"
 // @Success		200			{object}	[]storage.Conversation
 // @Failure		400			{object}	chat.ErrorResp
 // @Failure		500			{object}	chat.ErrorResp
 // @Router			/chat/conversations [post]
 func (cs *ChatService) ListConversationHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		req := chat.APPMetadata{}
+		req := chat.ListConversationReqBody{}
 		_ = c.ShouldBindJSON(&req)
 		req.AppNamespace = NamespaceInHeader(c)
 		resp, err := cs.server.ListConversations(c.Request.Context(), req)
@@ -320,6 +407,523 @@ func (cs *ChatService) DeleteConversationHandler() gin.HandlerFunc {
 	}
 }
 
+// @Summary	rename or pin one conversation
+// @Schemes
+// @Description	rename or pin one conversation, only the fields set are updated
+// @Tags			application
+// @Accept			json
+// @Produce		json
+// @Param			conversationID	path		string							true	"conversationID"
+// @Param			request			body		chat.UpdateConversationReqBody	true	"query params"
+// @Success		200				{object}	chat.SimpleResp
+// @Failure		400				{object}	chat.ErrorResp
+// @Failure		500				{object}	chat.ErrorResp
+// @Router			/chat/conversations/{conversationID} [put]
+func (cs *ChatService) UpdateConversationHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conversationID := c.Param("conversationID")
+		req := chat.UpdateConversationReqBody{}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "updateConversationHandler: error binding json")
+			c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+			c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: "title can not be empty"})
+			return
+		}
+		if err := cs.server.UpdateConversation(c.Request.Context(), conversationID, req); err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error update conversation")
+			c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		klog.FromContext(c.Request.Context()).V(3).Info("update conversation done", "conversationID", conversationID)
+		c.JSON(http.StatusOK, chat.SimpleResp{Message: "ok"})
+	}
+}
+
+// @Summary	get one image in the conversation
+// @Schemes
+// @Description	get one image in the queries of the conversation, to render the messages history
+// @Tags			application
+// @Produce		image/png,image/jpeg,image/gif,image/webp
+// @Param			conversationID	path		string	true	"conversationID"
+// @Param			name			path		string	true	"image name in the message"
+// @Success		200				{file}		file
+// @Failure		404				{object}	chat.ErrorResp
+// @Failure		500				{object}	chat.ErrorResp
+// @Router			/chat/conversations/{conversationID}/images/{name} [get]
+func (cs *ChatService) ConversationImageHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conversationID, name := c.Param("conversationID"), c.Param("name")
+		mimeType, data, err := cs.server.GetConversationImage(c.Request.Context(), conversationID, name)
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error get conversation image", "conversationID", conversationID, "name", name)
+			code := http.StatusInternalServerError
+			if errors.Is(err, chat.ErrImageNotFound) || errors.Is(err, storage.ErrConversationNotFound) || errors.Is(err, gorm.ErrRecordNotFound) {
+				code = http.StatusNotFound
+			}
+			c.JSON(code, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		// images never change since the name is the hash of the content
+		c.Header("Cache-Control", "private, max-age=86400")
+		c.Data(http.StatusOK, mimeType, data)
+	}
+}
+
+// @Summary	list the files of one conversation
+// @Schemes
+// @Description	list the files uploaded to one conversation with their process phase, the files can be used in the chat after they succeeded
+// @Tags			application
+// @Produce		json
+// @Param			conversationID	path		string	true	"conversationID"
+// @Success		200				{object}	[]chat.ConversationFile
+// @Failure		404				{object}	chat.ErrorResp
+// @Failure		500				{object}	chat.ErrorResp
+// @Router			/chat/conversations/{conversationID}/files [get]
+func (cs *ChatService) ListConversationFilesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conversationID := c.Param("conversationID")
+		resp, err := cs.server.ListConversationFiles(c.Request.Context(), conversationID)
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error list conversation files", "conversationID", conversationID)
+			code := http.StatusInternalServerError
+			if errors.Is(err, storage.ErrConversationNotFound) || errors.Is(err, gorm.ErrRecordNotFound) {
+				code = http.StatusNotFound
+			}
+			c.JSON(code, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// @Summary	delete one file of one conversation
+// @Schemes
+// @Description	delete one file uploaded to one conversation, it is removed from the conversation knowledgebase too
+// @Tags			application
+// @Produce		json
+// @Param			conversationID	path		string	true	"conversationID"
+// @Param			fileID			path		string	true	"file id"
+// @Success		200				{object}	chat.SimpleResp
+// @Failure		404				{object}	chat.ErrorResp
+// @Failure		500				{object}	chat.ErrorResp
+// @Router			/chat/conversations/{conversationID}/files/{fileID} [delete]
+func (cs *ChatService) DeleteConversationFileHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conversationID, fileID := c.Param("conversationID"), c.Param("fileID")
+		if err := cs.server.DeleteConversationFile(c.Request.Context(), conversationID, fileID); err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error delete conversation file", "conversationID", conversationID, "fileID", fileID)
+			code := http.StatusInternalServerError
+			if errors.Is(err, storage.ErrDocumentNotFound) || errors.Is(err, storage.ErrConversationNotFound) || errors.Is(err, gorm.ErrRecordNotFound) {
+				code = http.StatusNotFound
+			}
+			c.JSON(code, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		klog.FromContext(c.Request.Context()).V(3).Info("delete conversation file done", "conversationID", conversationID, "fileID", fileID)
+		c.JSON(http.StatusOK, chat.SimpleResp{Message: "ok"})
+	}
+}
+
+// @Summary	re-process one file of one conversation
+// @Schemes
+// @Description	embed one file failed to be processed into the conversation knowledgebase again, all the failed files of the conversation are re-processed together
+// @Tags			application
+// @Produce		json
+// @Param			conversationID	path		string	true	"conversationID"
+// @Param			fileID			path		string	true	"file id"
+// @Success		200				{object}	chat.SimpleResp
+// @Failure		400				{object}	chat.ErrorResp
+// @Failure		404				{object}	chat.ErrorResp
+// @Failure		500				{object}	chat.ErrorResp
+// @Router			/chat/conversations/{conversationID}/files/{fileID}/reprocess [post]
+func (cs *ChatService) ReprocessConversationFileHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conversationID, fileID := c.Param("conversationID"), c.Param("fileID")
+		if err := cs.server.ReprocessConversationFile(c.Request.Context(), conversationID, fileID); err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error reprocess conversation file", "conversationID", conversationID, "fileID", fileID)
+			code := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, chat.ErrFileNotFailed):
+				code = http.StatusBadRequest
+			case errors.Is(err, storage.ErrDocumentNotFound) || errors.Is(err, storage.ErrConversationNotFound) || errors.Is(err, gorm.ErrRecordNotFound):
+				code = http.StatusNotFound
+			}
+			c.JSON(code, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		klog.FromContext(c.Request.Context()).V(3).Info("reprocess conversation file done", "conversationID", conversationID, "fileID", fileID)
+		c.JSON(http.StatusOK, chat.SimpleResp{Message: "ok"})
+	}
+}
+
+// @Summary	export one conversation
+// @Schemes
+// @Description	download one conversation with references in markdown, json or pdf
+// @Tags			application
+// @Produce		application/octet-stream
+// @Param			conversationID	path		string	true	"conversationID"
+// @Param			format			query		string	false	"markdown(default), json or pdf"
+// @Success		200				{file}		file
+// @Failure		400				{object}	chat.ErrorResp
+// @Failure		500				{object}	chat.ErrorResp
+// @Router			/chat/conversations/{conversationID}/export [get]
+func (cs *ChatService) ExportConversationHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conversationID := c.Param("conversationID")
+		format := chat.ExportFormat(c.DefaultQuery("format", string(chat.ExportMarkdown)))
+		if format != chat.ExportMarkdown && format != chat.ExportJSON && format != chat.ExportPDF {
+			c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: fmt.Sprintf("unsupported format %s", format)})
+			return
+		}
+		content, fileName, contentType, err := cs.server.ExportConversation(c.Request.Context(), conversationID, format)
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error export conversation")
+			c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		klog.FromContext(c.Request.Context()).V(3).Info("export conversation done", "conversationID", conversationID, "format", format)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+		c.Data(http.StatusOK, contentType, content)
+	}
+}
+
+// @Summary	share one conversation
+// @Schemes
+// @Description	snapshot one conversation with messages and references into a public read-only share link, only conversations of public applications can be shared
+// @Tags			application
+// @Accept			json
+// @Produce		json
+// @Param			conversationID	path		string					true	"conversationID"
+// @Param			request			body		chat.CreateShareReqBody	false	"share params"
+// @Success		200				{object}	storage.Share
+// @Failure		400				{object}	chat.ErrorResp
+// @Failure		403				{object}	chat.ErrorResp
+// @Failure		404				{object}	chat.ErrorResp
+// @Failure		500				{object}	chat.ErrorResp
+// @Router			/chat/conversations/{conversationID}/shares [post]
+func (cs *ChatService) CreateShareHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conversationID := c.Param("conversationID")
+		req := chat.CreateShareReqBody{}
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				klog.FromContext(c.Request.Context()).Error(err, "createShareHandler: error binding json")
+				c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: err.Error()})
+				return
+			}
+		}
+		share, err := cs.server.CreateShare(c.Request.Context(), conversationID, req)
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error create share")
+			c.JSON(shareErrorCode(err), chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		klog.FromContext(c.Request.Context()).V(3).Info("create share done", "conversationID", conversationID)
+		c.JSON(http.StatusOK, share)
+	}
+}
+
+// @Summary	list shares of one conversation
+// @Schemes
+// @Description	list the share links of one conversation created by current user, without snapshots
+// @Tags			application
+// @Produce		json
+// @Param			conversationID	path		string	true	"conversationID"
+// @Success		200				{object}	[]storage.Share
+// @Failure		500				{object}	chat.ErrorResp
+// @Router			/chat/conversations/{conversationID}/shares [get]
+func (cs *ChatService) ListSharesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shares, err := cs.server.ListShares(c.Request.Context(), c.Param("conversationID"))
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error list shares")
+			c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, shares)
+	}
+}
+
+// @Summary	revoke one share
+// @Schemes
+// @Description	revoke one share link created by current user, the link can't be accessed any more
+// @Tags			application
+// @Produce		json
+// @Param			token	path		string	true	"share token"
+// @Success		200		{object}	chat.SimpleResp
+// @Failure		404		{object}	chat.ErrorResp
+// @Failure		500		{object}	chat.ErrorResp
+// @Router			/chat/shares/{token} [delete]
+func (cs *ChatService) RevokeShareHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := cs.server.RevokeShare(c.Request.Context(), c.Param("token")); err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error revoke share")
+			c.JSON(shareErrorCode(err), chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		klog.FromContext(c.Request.Context()).V(3).Info("revoke share done")
+		c.JSON(http.StatusOK, chat.SimpleResp{Message: "ok"})
+	}
+}
+
+// @Summary	get one shared conversation
+// @Schemes
+// @Description	get the snapshot of one shared conversation by the token, no authentication is required
+// @Tags			application
+// @Produce		json
+// @Param			token	path		string	true	"share token"
+// @Success		200		{object}	storage.Share
+// @Failure		404		{object}	chat.ErrorResp
+// @Failure		500		{object}	chat.ErrorResp
+// @Router			/chat/shares/{token} [get]
+func (cs *ChatService) GetShareHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		share, err := cs.server.GetShare(c.Request.Context(), c.Param("token"))
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error get share")
+			c.JSON(shareErrorCode(err), chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, share)
+	}
+}
+
+func chatErrorCode(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrConversationNotFound), errors.Is(err, storage.ErrMessageNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, chat.ErrQuotaExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, chat.ErrInvalidImage):
+		return http.StatusBadRequest
+	case errors.Is(err, chat.ErrPendingHuman):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func shareErrorCode(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrShareNotFound), errors.Is(err, storage.ErrConversationNotFound), errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, chat.ErrShareExpired):
+		return http.StatusNotFound
+	case errors.Is(err, chat.ErrAppNotPublic):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary	list the long-term memories of current user
+// @Schemes
+// @Description	list the facts about current user remembered across conversations, the latest first
+// @Tags			application
+// @Produce		json
+// @Success		200	{object}	[]storage.UserMemory
+// @Failure		500	{object}	chat.ErrorResp
+// @Router			/chat/memories [get]
+func (cs *ChatService) ListUserMemoriesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		memories, err := cs.server.ListUserMemories(c.Request.Context())
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error list user memories")
+			c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, memories)
+	}
+}
+
+// @Summary	delete one long-term memory of current user
+// @Schemes
+// @Description	forget one fact about current user
+// @Tags			application
+// @Produce		json
+// @Param			memoryID	path		string	true	"memoryID"
+// @Success		200			{object}	chat.SimpleResp
+// @Failure		404			{object}	chat.ErrorResp
+// @Failure		500			{object}	chat.ErrorResp
+// @Router			/chat/memories/{memoryID} [delete]
+func (cs *ChatService) DeleteUserMemoryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		memoryID := c.Param("memoryID")
+		if err := cs.server.DeleteUserMemory(c.Request.Context(), memoryID); err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error delete user memory")
+			code := http.StatusInternalServerError
+			if errors.Is(err, storage.ErrUserMemoryNotFound) {
+				code = http.StatusNotFound
+			}
+			c.JSON(code, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		klog.FromContext(c.Request.Context()).V(3).Info("delete user memory done", "memoryID", memoryID)
+		c.JSON(http.StatusOK, chat.SimpleResp{Message: "ok"})
+	}
+}
+
+// @Summary	hand one conversation off to human operators
+// @Schemes
+// @Description	hand one conversation of current user off to human operators at the request of the user, the application stops answering it until an operator resolves it
+// @Tags			application
+// @Produce		json
+// @Param			conversationID	path		string	true	"conversationID"
+// @Success		200				{object}	storage.Handoff
+// @Failure		400				{object}	chat.ErrorResp
+// @Failure		404				{object}	chat.ErrorResp
+// @Failure		409				{object}	chat.ErrorResp
+// @Failure		500				{object}	chat.ErrorResp
+// @Router			/chat/conversations/{conversationID}/handoff [post]
+func (cs *ChatService) RequestHandoffHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conversationID := c.Param("conversationID")
+		handoff, err := cs.server.RequestHandoff(c.Request.Context(), conversationID)
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error request handoff", "conversationID", conversationID)
+			c.JSON(handoffErrorCode(err), chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		klog.FromContext(c.Request.Context()).V(3).Info("request handoff done", "conversationID", conversationID)
+		c.JSON(http.StatusOK, handoff)
+	}
+}
+
+// @Summary	list handoffs for human operators
+// @Schemes
+// @Description	list the conversations handed off to human operators in the namespace, the earliest first
+// @Tags			application
+// @Produce		json
+// @Param			namespace	header		string	true	"namespace this request is in"
+// @Param			app_name	query		string	false	"only the handoffs of the app"
+// @Param			status		query		string	false	"pending or resolved, all if not set"
+// @Success		200			{object}	[]storage.Handoff
+// @Failure		400			{object}	chat.ErrorResp
+// @Failure		500			{object}	chat.ErrorResp
+// @Router			/chat/handoffs [get]
+func (cs *ChatService) ListHandoffsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := chat.ListHandoffsReqBody{}
+		if err := c.ShouldBindQuery(&req); err != nil {
+			c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		req.AppNamespace = NamespaceInHeader(c)
+		resp, err := cs.server.ListHandoffs(c.Request.Context(), req)
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error list handoffs")
+			c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// @Summary	reply to one handoff
+// @Schemes
+// @Description	add the reply of current user as the human operator to the conversation of one pending handoff, the user gets it in the messages history and the streaming chat
+// @Tags			application
+// @Accept			json
+// @Produce		json
+// @Param			namespace	header		string						true	"namespace this request is in"
+// @Param			handoffID	path		string						true	"handoff id"
+// @Param			request		body		chat.HandoffReplyReqBody	true	"reply"
+// @Success		200			{object}	storage.Message
+// @Failure		400			{object}	chat.ErrorResp
+// @Failure		404			{object}	chat.ErrorResp
+// @Failure		409			{object}	chat.ErrorResp
+// @Failure		500			{object}	chat.ErrorResp
+// @Router			/chat/handoffs/{handoffID}/replies [post]
+func (cs *ChatService) ReplyHandoffHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handoffID := c.Param("handoffID")
+		req := chat.HandoffReplyReqBody{}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		reply, err := cs.server.ReplyHandoff(c.Request.Context(), NamespaceInHeader(c), handoffID, req.Content)
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error reply handoff", "handoffID", handoffID)
+			c.JSON(handoffErrorCode(err), chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		klog.FromContext(c.Request.Context()).V(3).Info("reply handoff done", "handoffID", handoffID)
+		c.JSON(http.StatusOK, reply)
+	}
+}
+
+// @Summary	resolve one handoff
+// @Schemes
+// @Description	mark one pending handoff as resolved by current user, the application answers the conversation again
+// @Tags			application
+// @Produce		json
+// @Param			namespace	header		string	true	"namespace this request is in"
+// @Param			handoffID	path		string	true	"handoff id"
+// @Success		200			{object}	chat.SimpleResp
+// @Failure		404			{object}	chat.ErrorResp
+// @Failure		409			{object}	chat.ErrorResp
+// @Failure		500			{object}	chat.ErrorResp
+// @Router			/chat/handoffs/{handoffID}/resolve [post]
+func (cs *ChatService) ResolveHandoffHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handoffID := c.Param("handoffID")
+		if err := cs.server.ResolveHandoff(c.Request.Context(), NamespaceInHeader(c), handoffID); err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error resolve handoff", "handoffID", handoffID)
+			c.JSON(handoffErrorCode(err), chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		klog.FromContext(c.Request.Context()).V(3).Info("resolve handoff done", "handoffID", handoffID)
+		c.JSON(http.StatusOK, chat.SimpleResp{Message: "ok"})
+	}
+}
+
+// @Summary	list guardrail violations
+// @Schemes
+// @Description	list the rules of the guardrail nodes broken by the questions and answers in the namespace, the latest first
+// @Tags			application
+// @Produce		json
+// @Param			namespace		header		string	true	"namespace this request is in"
+// @Param			app_name		query		string	false	"only the violations of the app"
+// @Param			conversation_id	query		string	false	"only the violations of the conversation"
+// @Param			page			query		int		false	"page, starts from 1"
+// @Param			page_size		query		int		false	"page size, 0 means all"
+// @Success		200				{object}	[]storage.GuardrailViolation
+// @Failure		400				{object}	chat.ErrorResp
+// @Failure		500				{object}	chat.ErrorResp
+// @Router			/chat/guardrail-violations [get]
+func (cs *ChatService) ListGuardrailViolationsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := chat.ListGuardrailViolationsReqBody{}
+		if err := c.ShouldBindQuery(&req); err != nil {
+			c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		req.AppNamespace = NamespaceInHeader(c)
+		resp, err := cs.server.ListGuardrailViolations(c.Request.Context(), req)
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error list guardrail violations")
+			c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+func handoffErrorCode(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrHandoffNotFound), errors.Is(err, storage.ErrConversationNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, chat.ErrHandoffDisabled), errors.Is(err, chat.ErrEmptyHumanReply):
+		return http.StatusBadRequest
+	case errors.Is(err, chat.ErrPendingHuman), errors.Is(err, chat.ErrHandoffResolved):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // @Summary	get all messages history for one conversation
 // @Schemes
 // @Description	get all messages history for one conversation
@@ -394,6 +998,83 @@ func (cs *ChatService) ReferenceHandler() gin.HandlerFunc {
 	}
 }
 
+// @Summary	rate the answer of one message
+// @Schemes
+// @Description	like or dislike the current answer of one message with category and comment, an empty rating removes the feedback
+// @Tags			application
+// @Accept			json
+// @Produce		json
+// @Param			namespace	header		string					true	"namespace this request is in"
+// @Param			messageID	path		string					true	"messageID"
+// @Param			request		body		chat.FeedbackReqBody	true	"query params"
+// @Success		200			{object}	storage.Feedback
+// @Failure		400			{object}	chat.ErrorResp
+// @Failure		404			{object}	chat.ErrorResp
+// @Failure		500			{object}	chat.ErrorResp
+// @Router			/chat/messages/{messageID}/feedback [post]
+func (cs *ChatService) FeedbackHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := chat.FeedbackReqBody{}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "feedbackHandler: error binding json")
+			c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		req.MessageID = c.Param("messageID")
+		req.AppNamespace = NamespaceInHeader(c)
+		resp, err := cs.server.SetFeedback(c.Request.Context(), req)
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error set message feedback")
+			c.JSON(chatErrorCode(err), chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		klog.FromContext(c.Request.Context()).V(3).Info("set message feedback done", "req", req)
+		if resp == nil {
+			c.JSON(http.StatusOK, chat.SimpleResp{Message: "ok"})
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// @Summary	regenerate the answer of one message
+// @Schemes
+// @Description	answer the query of one message again, the previous answer is kept in the alternates of the message
+// @Tags			application
+// @Accept			json
+// @Produce		json
+// @Param			namespace	header		string					true	"namespace this request is in"
+// @Param			messageID	path		string					true	"messageID"
+// @Param			request		body		chat.RegenerateReqBody	true	"query params"
+// @Success		200			{object}	chat.ChatRespBody		"blocking mode, will return all field; streaming mode, only conversation_id, message and created_at will be returned, then suggested_questions in a suggested_questions event if the application shows the next guide"
+// @Failure		400			{object}	chat.ErrorResp
+// @Failure		404			{object}	chat.ErrorResp
+// @Failure		500			{object}	chat.ErrorResp
+// @Router			/chat/messages/{messageID}/regenerate [post]
+func (cs *ChatService) RegenerateHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body := chat.RegenerateReqBody{}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "regenerateHandler: error binding json")
+			c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		if body.ConversationID == "" {
+			c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: "conversation_id is required"})
+			return
+		}
+		req := chat.ChatReqBody{
+			ResponseMode:        body.ResponseMode,
+			ConversationReqBody: body.ConversationReqBody,
+			Debug:               c.Query("debug") == "true",
+			StartTime:           time.Now(),
+			Regenerate:          true,
+		}
+		req.AppNamespace = NamespaceInHeader(c)
+		cs.chat(c, req, c.Param("messageID"))
+	}
+}
+
 // @Summary	get app's prompt starters
 // @Schemes
 // @Description	get app's prompt starters
@@ -457,12 +1138,33 @@ func registerChat(g *gin.RouterGroup, conf config.ServerConfig) {
 
 	g.POST("", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ChatHandler()) // chat with bot
 
-	g.POST("/conversations/file", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ChatFile())                               // upload fles for conversation
-	g.POST("/conversations", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ListConversationHandler())                     // list conversations
-	g.DELETE("/conversations/:conversationID", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.DeleteConversationHandler()) // delete conversation
+	g.POST("/conversations/file", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ChatFile())                                                            // upload fles for conversation
+	g.POST("/conversations", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ListConversationHandler())                                                  // list conversations
+	g.DELETE("/conversations/:conversationID", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.DeleteConversationHandler())                              // delete conversation
+	g.PUT("/conversations/:conversationID", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.UpdateConversationHandler())                                 // rename or pin conversation
+	g.GET("/conversations/:conversationID/export", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ExportConversationHandler())                          // export conversation
+	g.GET("/conversations/:conversationID/images/:name", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ConversationImageHandler())                     // image in the queries
+	g.GET("/conversations/:conversationID/files", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ListConversationFilesHandler())                        // files of conversation
+	g.DELETE("/conversations/:conversationID/files/:fileID", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.DeleteConversationFileHandler())            // delete file of conversation
+	g.POST("/conversations/:conversationID/files/:fileID/reprocess", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ReprocessConversationFileHandler()) // re-process failed file of conversation
+	g.POST("/conversations/:conversationID/handoff", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.RequestHandoffHandler())                            // hand off to human operators
+	g.POST("/conversations/:conversationID/shares", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.CreateShareHandler())                                // share conversation
+	g.GET("/conversations/:conversationID/shares", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ListSharesHandler())                                  // list shares of conversation
+	g.DELETE("/shares/:token", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.RevokeShareHandler())                                                     // revoke share
+	g.GET("/shares/:token", requestid.RequestIDInterceptor(), chatService.GetShareHandler())                                                                                                                                                               // shared conversation, no authentication
+
+	g.POST("/messages", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.HistoryHandler())                          // messages history
+	g.POST("/messages/:messageID/references", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ReferenceHandler())  // messages reference
+	g.POST("/messages/:messageID/feedback", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.FeedbackHandler())     // rate the answer
+	g.POST("/messages/:messageID/regenerate", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.RegenerateHandler()) // regenerate the answer
+
+	g.GET("/memories", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ListUserMemoriesHandler())              // long-term memories of current user
+	g.DELETE("/memories/:memoryID", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.DeleteUserMemoryHandler()) // forget one memory
 
-	g.POST("/messages", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.HistoryHandler())                         // messages history
-	g.POST("/messages/:messageID/references", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ReferenceHandler()) // messages reference
+	g.GET("/handoffs", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "update", "applications"), requestid.RequestIDInterceptor(), chatService.ListHandoffsHandler())                        // handoffs queue of operators
+	g.POST("/handoffs/:handoffID/replies", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "update", "applications"), requestid.RequestIDInterceptor(), chatService.ReplyHandoffHandler())    // operator replies
+	g.POST("/handoffs/:handoffID/resolve", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "update", "applications"), requestid.RequestIDInterceptor(), chatService.ResolveHandoffHandler())  // operator resolves
+	g.GET("/guardrail-violations", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "update", "applications"), requestid.RequestIDInterceptor(), chatService.ListGuardrailViolationsHandler()) // violations log of guardrails
 
 	g.POST("/prompt-starter", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.PromptStartersHandler())
 }
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+	pkgclient "github.com/kubeagi/arcadia/apiserver/pkg/client"
+)
+
+func newChatTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: "app"},
+		Status: v1alpha1.ApplicationStatus{
+			ConditionedStatus: v1alpha1.ConditionedStatus{
+				Conditions: []v1alpha1.Condition{{Type: v1alpha1.TypeReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(pkgclient.Scheme).WithObjects(app).Build()
+	s := storage.NewMemoryStorage()
+	if err := s.UpdateConversation(&storage.Conversation{
+		ID:           "conversation",
+		AppName:      "app",
+		AppNamespace: "arcadia",
+		Messages:     []storage.Message{{ID: "m1", Action: "CHAT", Query: "q1", Answer: "a1"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cs := &ChatService{chat.NewChatServerWithStorage(cli, false, s)}
+	r := gin.New()
+	r.POST("/chat/messages/:messageID/feedback", cs.FeedbackHandler())
+	r.POST("/chat/messages/:messageID/regenerate", cs.RegenerateHandler())
+	return r
+}
+
+func TestMessageNotFound(t *testing.T) {
+	r := newChatTestRouter(t)
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		expected int
+	}{
+		{
+			name:     "feedback",
+			path:     "/chat/messages/m1/feedback",
+			body:     `{"app_name":"app","conversation_id":"conversation","rating":"like"}`,
+			expected: http.StatusOK,
+		},
+		{
+			name:     "feedback on a missing message",
+			path:     "/chat/messages/missing/feedback",
+			body:     `{"app_name":"app","conversation_id":"conversation","rating":"like"}`,
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "feedback in a missing conversation",
+			path:     "/chat/messages/m1/feedback",
+			body:     `{"app_name":"app","conversation_id":"missing","rating":"like"}`,
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "regenerate a missing message",
+			path:     "/chat/messages/missing/regenerate",
+			body:     `{"app_name":"app","conversation_id":"conversation","response_mode":"blocking"}`,
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "regenerate in a missing conversation",
+			path:     "/chat/messages/m1/regenerate",
+			body:     `{"app_name":"app","conversation_id":"missing","response_mode":"blocking"}`,
+			expected: http.StatusNotFound,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(namespaceHeader, "arcadia")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.expected {
+				t.Errorf("expect status %d, got %d: %s", tc.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -39,12 +39,28 @@ func registerGptsChat(g *gin.RouterGroup, conf config.ServerConfig) {
 
 	g.POST("", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ChatHandler()) // chat with bot
 
-	g.POST("/conversations/file", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ChatFile())                               // upload fles for conversation
-	g.POST("/conversations", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ListConversationHandler())                     // list conversations
-	g.DELETE("/conversations/:conversationID", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.DeleteConversationHandler()) // delete conversation
+	g.POST("/conversations/file", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ChatFile())                                                            // upload fles for conversation
+	g.POST("/conversations", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ListConversationHandler())                                                  // list conversations
+	g.DELETE("/conversations/:conversationID", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.DeleteConversationHandler())                              // delete conversation
+	g.PUT("/conversations/:conversationID", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.UpdateConversationHandler())                                 // rename or pin conversation
+	g.GET("/conversations/:conversationID/export", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ExportConversationHandler())                          // export conversation
+	g.GET("/conversations/:conversationID/images/:name", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ConversationImageHandler())                     // image in the queries
+	g.GET("/conversations/:conversationID/files", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ListConversationFilesHandler())                        // files of conversation
+	g.DELETE("/conversations/:conversationID/files/:fileID", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.DeleteConversationFileHandler())            // delete file of conversation
+	g.POST("/conversations/:conversationID/files/:fileID/reprocess", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ReprocessConversationFileHandler()) // re-process failed file of conversation
+	g.POST("/conversations/:conversationID/handoff", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.RequestHandoffHandler())                            // hand off to human operators
+	g.POST("/conversations/:conversationID/shares", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.CreateShareHandler())                                // share conversation
+	g.GET("/conversations/:conversationID/shares", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ListSharesHandler())                                  // list shares of conversation
+	g.DELETE("/shares/:token", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.RevokeShareHandler())                                                     // revoke share
+	g.GET("/shares/:token", requestid.RequestIDInterceptor(), chatService.GetShareHandler())                                                                                                                  // shared conversation, no authentication
 
-	g.POST("/messages", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.HistoryHandler())                         // messages history
-	g.POST("/messages/:messageID/references", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ReferenceHandler()) // messages reference
+	g.POST("/messages", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.HistoryHandler())                          // messages history
+	g.POST("/messages/:messageID/references", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ReferenceHandler())  // messages reference
+	g.POST("/messages/:messageID/feedback", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.FeedbackHandler())     // rate the answer
+	g.POST("/messages/:messageID/regenerate", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.RegenerateHandler()) // regenerate the answer
+
+	g.GET("/memories", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.ListUserMemoriesHandler())              // long-term memories of current user
+	g.DELETE("/memories/:memoryID", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.DeleteUserMemoryHandler()) // forget one memory
 
 	g.POST("/prompt-starter", auth.AuthTokenIsValid(conf.EnableOIDC, oidc.Verifier), requestid.RequestIDInterceptor(), chatService.PromptStartersHandler())
 }
@@ -0,0 +1,279 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/kubeagi/arcadia/apiserver/config"
+	"github.com/kubeagi/arcadia/apiserver/pkg/auth"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat"
+	"github.com/kubeagi/arcadia/apiserver/pkg/client"
+	"github.com/kubeagi/arcadia/apiserver/pkg/requestid"
+)
+
+// @Summary	chat with application in OpenAI format
+// @Schemes
+// @Description	chat with application like OpenAI `/v1/chat/completions`, the model is the application in `namespace/name`
+// @Tags			openai
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string							false	"Bearer api key"
+// @Param			request			body		chat.OpenAIChatCompletionReq	true	"query params"
+// @Success		200				{object}	chat.OpenAIChatCompletion		"blocking mode returns the completion; streaming mode returns chunks in server-sent events, ends with `data: [DONE]`"
+// @Failure		400				{object}	chat.OpenAIErrorResp
+// @Failure		404				{object}	chat.OpenAIErrorResp
+// @Failure		429				{object}	chat.OpenAIErrorResp
+// @Failure		500				{object}	chat.OpenAIErrorResp
+// @Router			/v1/chat/completions [post]
+func (cs *ChatService) ChatCompletionsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body := chat.OpenAIChatCompletionReq{}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			openAIErrorResp(c, http.StatusBadRequest, err.Error(), "invalid_request_error")
+			return
+		}
+		scope := auth.APIKeyScopeFromContext(c.Request.Context())
+		defaultNamespace := NamespaceInHeader(c)
+		if scope != nil {
+			defaultNamespace = scope.Namespace
+		}
+		req, err := body.ChatReqBody(defaultNamespace)
+		if err != nil {
+			openAIErrorResp(c, http.StatusBadRequest, err.Error(), "invalid_request_error")
+			return
+		}
+		model := req.AppNamespace + "/" + req.APPName
+		if !scope.CanAccess(req.AppNamespace, req.APPName) {
+			openAIErrorResp(c, http.StatusNotFound, fmt.Sprintf("the model %s does not exist or you do not have access to it", model), "invalid_request_error")
+			return
+		}
+		if req.NewChat {
+			req.ConversationID = string(uuid.NewUUID())
+		}
+		messageID := string(uuid.NewUUID())
+		completion := chat.OpenAIChatCompletion{
+			ID:             "chatcmpl-" + messageID,
+			Object:         "chat.completion",
+			Created:        req.StartTime.Unix(),
+			Model:          model,
+			ConversationID: req.ConversationID,
+		}
+		logger := klog.FromContext(c.Request.Context())
+		chatTimeoutSecond := pointer.Float64(WaitTimeoutForChatStreaming)
+
+		if !req.ResponseMode.IsStreaming() {
+			response, err := cs.server.AppRun(c.Request.Context(), req, nil, messageID, chatTimeoutSecond)
+			if err != nil {
+				logger.Error(err, "error resp")
+				openAIRunErrorResp(c, err)
+				return
+			}
+			completion.Choices = []chat.OpenAIChoice{{
+				Message:      &chat.OpenAIMessage{Role: chat.OpenAIRoleAssistant, Content: chat.OpenAIContent(response.Message)},
+				FinishReason: pointer.String(chat.OpenAIFinishReasonStop),
+			}}
+			completion.Usage = openAIUsage(response)
+			completion.References = response.References
+			c.JSON(http.StatusOK, completion)
+			logger.Info("chat completion done")
+			return
+		}
+
+		respStream := make(chan string, 1)
+		done := make(chan struct{})
+		var response *chat.ChatRespBody
+		var runErr error
+		go func() {
+			defer close(done)
+			defer func() {
+				if e := recover(); e != nil {
+					runErr = fmt.Errorf("panic in chat: %v", e)
+				}
+			}()
+			response, runErr = cs.server.AppRun(c.Request.Context(), req, respStream, messageID, chatTimeoutSecond)
+		}()
+
+		c.Writer.Header().Set("Content-Type", "text/event-stream")
+		c.Writer.Header().Set("Cache-Control", "no-cache")
+		c.Writer.Header().Set("Connection", "keep-alive")
+		completion.Object = "chat.completion.chunk"
+		chunk := func(delta chat.OpenAIDelta, finishReason *string) chat.OpenAIChatCompletion {
+			res := completion
+			res.Choices = []chat.OpenAIChoice{{Delta: &delta, FinishReason: finishReason}}
+			return res
+		}
+		writeOpenAIEvent(c, chunk(chat.OpenAIDelta{Role: chat.OpenAIRoleAssistant}, nil))
+
+		streamed := false
+		idleTimeout := time.Second * time.Duration(WaitTimeoutForChatStreaming)
+		idle := time.NewTimer(idleTimeout)
+		defer idle.Stop()
+	loop:
+		for {
+			select {
+			case msg := <-respStream:
+				writeOpenAIEvent(c, chunk(chat.OpenAIDelta{Content: msg}, nil))
+				streamed = true
+				idle.Reset(idleTimeout)
+			case <-done:
+				// the answer may be left in the buffer when the run returns
+				for {
+					select {
+					case msg := <-respStream:
+						writeOpenAIEvent(c, chunk(chat.OpenAIDelta{Content: msg}, nil))
+						streamed = true
+					default:
+						break loop
+					}
+				}
+			case <-idle.C:
+				logger.Info("no data from LLM for a long time, stop the stream", "timeout", idleTimeout)
+				writeOpenAIEvent(c, chat.OpenAIErrorResp{Error: chat.OpenAIError{Message: "timeout waiting for the answer", Type: "server_error"}})
+				return
+			case <-c.Request.Context().Done():
+				logger.Info("chatCompletionsHandler: the client is disconnected")
+				return
+			}
+		}
+		if runErr != nil {
+			logger.Error(runErr, "error resp, stop the stream")
+			writeOpenAIEvent(c, chat.OpenAIErrorResp{Error: chat.OpenAIError{Message: runErr.Error(), Type: "server_error"}})
+			return
+		}
+		if !streamed && response.Message != "" {
+			// the application doesn't support streaming, send the whole answer at once
+			writeOpenAIEvent(c, chunk(chat.OpenAIDelta{Content: response.Message}, nil))
+		}
+		last := chunk(chat.OpenAIDelta{}, pointer.String(chat.OpenAIFinishReasonStop))
+		last.Usage = openAIUsage(response)
+		last.References = response.References
+		writeOpenAIEvent(c, last)
+		_, _ = c.Writer.WriteString("data: [DONE]\n\n")
+		c.Writer.Flush()
+		logger.Info("chat completion done")
+	}
+}
+
+// @Summary	list applications as models in OpenAI format
+// @Schemes
+// @Description	list the ready applications which the api key can access, like OpenAI `/v1/models`
+// @Tags			openai
+// @Produce		json
+// @Param			Authorization	header		string	false	"Bearer api key"
+// @Param			namespace		header		string	false	"namespace of the applications if no api key is used, default to all namespaces"
+// @Success		200				{object}	chat.OpenAIModelList
+// @Failure		500				{object}	chat.OpenAIErrorResp
+// @Router			/v1/models [get]
+func (cs *ChatService) ListModelsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := auth.APIKeyScopeFromContext(c.Request.Context())
+		namespace := NamespaceInHeader(c)
+		if scope != nil {
+			namespace = scope.Namespace
+		}
+		apps, err := cs.server.ListReadyApps(c.Request.Context(), namespace)
+		if err != nil {
+			openAIErrorResp(c, http.StatusInternalServerError, err.Error(), "server_error")
+			return
+		}
+		models := chat.OpenAIModelList{Object: "list", Data: make([]chat.OpenAIModel, 0, len(apps))}
+		for _, app := range apps {
+			if !scope.CanAccess(app.Namespace, app.Name) {
+				continue
+			}
+			models.Data = append(models.Data, chat.OpenAIModel{
+				ID:      app.Namespace + "/" + app.Name,
+				Object:  "model",
+				Created: app.CreationTimestamp.Unix(),
+				OwnedBy: app.Namespace,
+			})
+		}
+		sort.Slice(models.Data, func(i, j int) bool { return models.Data[i].ID < models.Data[j].ID })
+		c.JSON(http.StatusOK, models)
+	}
+}
+
+func openAIUsage(resp *chat.ChatRespBody) *chat.OpenAIUsage {
+	if resp == nil || resp.Usage == nil {
+		return nil
+	}
+	return &chat.OpenAIUsage{
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+	}
+}
+
+func openAIErrorResp(c *gin.Context, code int, message, errType string) {
+	c.JSON(code, chat.OpenAIErrorResp{Error: chat.OpenAIError{Message: message, Type: errType}})
+}
+
+func openAIRunErrorResp(c *gin.Context, err error) {
+	if errors.Is(err, chat.ErrQuotaExceeded) {
+		openAIErrorResp(c, http.StatusTooManyRequests, err.Error(), "insufficient_quota")
+		return
+	}
+	openAIErrorResp(c, http.StatusInternalServerError, err.Error(), "server_error")
+}
+
+// writeOpenAIEvent writes a server-sent event with only data, as OpenAI SDKs expect
+func writeOpenAIEvent(c *gin.Context, data any) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", b)
+	c.Writer.Flush()
+}
+
+func registerOpenAI(g *gin.RouterGroup, conf config.ServerConfig) {
+	c, err := client.GetClient(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	chatService, err := NewChatService(c, false)
+	if err != nil {
+		panic(err)
+	}
+	clientset, err := kubernetes.NewForConfig(ctrl.GetConfigOrDie())
+	if err != nil {
+		panic(err)
+	}
+	keys, err := auth.NewAPIKeyStore(context.Background(), clientset)
+	if err != nil {
+		panic(err)
+	}
+
+	// api keys are required when authentication is enabled
+	g.POST("/chat/completions", auth.APIKeyInterceptor(conf.EnableOIDC, keys), requestid.RequestIDInterceptor(), chatService.ChatCompletionsHandler())
+	g.GET("/models", auth.APIKeyInterceptor(conf.EnableOIDC, keys), requestid.RequestIDInterceptor(), chatService.ListModelsHandler())
+}
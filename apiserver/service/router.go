@@ -20,6 +20,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
@@ -37,7 +38,7 @@ func Cors() gin.HandlerFunc {
 		c.Header("Access-Control-Allow-Origin", origin)
 		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, UPDATE")
 		c.Header("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization, namespace, Referer, User-Agent")
-		c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Cache-Control, Content-Language, Content-Type")
+		c.Header("Access-Control-Expose-Headers", "Content-Length, Content-Disposition, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Cache-Control, Content-Language, Content-Type")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		if method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -52,6 +53,8 @@ func NewServerAndRun(conf config.ServerConfig) {
 	r.GET("/healthz", func(c *gin.Context) {
 		c.String(http.StatusOK, "ok")
 	})
+	// for metrics like the hits of response cache
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// enable oidc authentication
 	if conf.EnableOIDC {
@@ -82,6 +85,10 @@ func NewServerAndRun(conf config.ServerConfig) {
 
 		fg := r.Group("/forward")
 		registerForward(fg, conf)
+
+		// for OpenAI compatible apis in front of applications
+		openaiGroup := r.Group("/v1")
+		registerOpenAI(openaiGroup, conf)
 	}
 
 	//  for swagger
@@ -38,6 +38,7 @@ import (
 	agentv1alpha1 "github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
 	chainv1alpha1 "github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
 	documentloaderv1alpha1 "github.com/kubeagi/arcadia/api/app-node/documentloader/v1alpha1"
+	guardrailv1alpha1 "github.com/kubeagi/arcadia/api/app-node/guardrail/v1alpha1"
 	promptv1alpha1 "github.com/kubeagi/arcadia/api/app-node/prompt/v1alpha1"
 	retrieveralpha1 "github.com/kubeagi/arcadia/api/app-node/retriever/v1alpha1"
 	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
@@ -51,6 +52,7 @@ const (
 	RetrievalQAChainIndexKey       = "metadata.retrievalqachain"
 	KnowledgebaseIndexKey          = "metadata.knowledgebase"
 	LLMIndexKey                    = "metadata.llm"
+	LLMGroupIndexKey               = "metadata.llmgroup"
 	PromptIndexKey                 = "metadata.prompt"
 	KnowledgebaseRetrieverIndexKey = "metadata.knowledgebaseretriever"
 	RerankRetrieverIndexKey        = "metadata.rerankretriever"
@@ -58,6 +60,7 @@ const (
 	MergerRetrieverIndexKey        = "metadata.mergerretriever"
 	AgentIndexKey                  = "metadata.agent"
 	DocumentLoaderIndexKey         = "metadata.documentloader"
+	GuardrailIndexKey              = "metadata.guardrail"
 )
 
 // ApplicationReconciler reconciles an Application object
@@ -84,6 +87,7 @@ type ApplicationReconciler struct {
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=llms,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=llms/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=llms/finalizers,verbs=update
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=llmgroups,verbs=get;list;watch
 //+kubebuilder:rbac:groups=prompt.arcadia.kubeagi.k8s.com.cn,resources=prompts,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=prompt.arcadia.kubeagi.k8s.com.cn,resources=prompts/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=prompt.arcadia.kubeagi.k8s.com.cn,resources=prompts/finalizers,verbs=update
@@ -96,6 +100,9 @@ type ApplicationReconciler struct {
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=documentloaders,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=documentloaders/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=documentloaders/finalizers,verbs=update
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=guardrails,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=guardrails/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=guardrails/finalizers,verbs=update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -182,6 +189,7 @@ func MigrateAppCategory(app *arcadiav1alpha1.Application) bool {
 // 6. when this node points to output, it can only point to output
 // 7. should not have cycle TODO
 // 8. nodeName should be unique
+// 9. chain configs should fit the capabilities of the models they use
 func (r *ApplicationReconciler) validateNodes(ctx context.Context, log logr.Logger, app *arcadiav1alpha1.Application) (*arcadiav1alpha1.Application, ctrl.Result, error) {
 	log.V(5).Info("Start validate nodes...")
 	defer log.V(5).Info("Validate nodes Done")
@@ -254,6 +262,10 @@ func (r *ApplicationReconciler) validateNodes(ctx context.Context, log logr.Logg
 		r.setCondition(app, app.Status.ErrorCondition(err.Error())...)
 		return app, ctrl.Result{RequeueAfter: waitMedium}, nil
 	}
+	if err := runtimeApp.ValidateModels(); err != nil {
+		r.setCondition(app, app.Status.ErrorCondition(err.Error())...)
+		return app, ctrl.Result{RequeueAfter: waitMedium}, nil
+	}
 
 	visited := make(map[string]bool)
 	waitRunningNodes := list.New()
@@ -365,6 +377,7 @@ func (r *ApplicationReconciler) SetupWithManager(ctx context.Context, mgr ctrl.M
 		{RetrievalQAChainIndexKey, "chain", "retrievalqachain"},
 		{KnowledgebaseIndexKey, "", "knowledgebase"},
 		{LLMIndexKey, "", "llm"},
+		{LLMGroupIndexKey, "", "llmgroup"},
 		{PromptIndexKey, "prompt", "prompt"},
 		{KnowledgebaseRetrieverIndexKey, "retriever", "knowledgebaseretriever"},
 		{RerankRetrieverIndexKey, "retriever", "rerankretriever"},
@@ -372,6 +385,7 @@ func (r *ApplicationReconciler) SetupWithManager(ctx context.Context, mgr ctrl.M
 		{MergerRetrieverIndexKey, "retriever", "mergerretriever"},
 		{AgentIndexKey, "", "agent"},
 		{DocumentLoaderIndexKey, "", "documentloader"},
+		{GuardrailIndexKey, "", "guardrail"},
 	}
 	for _, d := range dependencies {
 		d := d
@@ -422,6 +436,7 @@ func (r *ApplicationReconciler) SetupWithManager(ctx context.Context, mgr ctrl.M
 		Watches(&source.Kind{Type: &chainv1alpha1.RetrievalQAChain{}}, getEventHandler(RetrievalQAChainIndexKey)).
 		Watches(&source.Kind{Type: &arcadiav1alpha1.KnowledgeBase{}}, getEventHandler(KnowledgebaseIndexKey)).
 		Watches(&source.Kind{Type: &arcadiav1alpha1.LLM{}}, getEventHandler(LLMIndexKey)).
+		Watches(&source.Kind{Type: &arcadiav1alpha1.LLMGroup{}}, getEventHandler(LLMGroupIndexKey)).
 		Watches(&source.Kind{Type: &promptv1alpha1.Prompt{}}, getEventHandler(PromptIndexKey)).
 		Watches(&source.Kind{Type: &retrieveralpha1.KnowledgeBaseRetriever{}}, getEventHandler(KnowledgebaseRetrieverIndexKey)).
 		Watches(&source.Kind{Type: &retrieveralpha1.RerankRetriever{}}, getEventHandler(RerankRetrieverIndexKey)).
@@ -429,6 +444,7 @@ func (r *ApplicationReconciler) SetupWithManager(ctx context.Context, mgr ctrl.M
 		Watches(&source.Kind{Type: &retrieveralpha1.MergerRetriever{}}, getEventHandler(MergerRetrieverIndexKey)).
 		Watches(&source.Kind{Type: &agentv1alpha1.Agent{}}, getEventHandler(AgentIndexKey)).
 		Watches(&source.Kind{Type: &documentloaderv1alpha1.DocumentLoader{}}, getEventHandler(DocumentLoaderIndexKey)).
+		Watches(&source.Kind{Type: &guardrailv1alpha1.Guardrail{}}, getEventHandler(GuardrailIndexKey)).
 		Complete(r)
 }
 
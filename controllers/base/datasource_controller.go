@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -41,6 +42,9 @@ import (
 type DatasourceReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// crawls holds the cancel functions of running crawls, keyed by datasource uid
+	crawls sync.Map
 }
 
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=datasources,verbs=get;list;watch;create;update;patch;delete
@@ -112,6 +116,9 @@ func (r *DatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		// Update conditioned status
 		return reconcile.Result{RequeueAfter: waitMedium}, err
 	}
+	if wait := r.reconcileCrawl(ctx, logger, instance); wait > 0 && wait < waitLonger {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
 	return ctrl.Result{RequeueAfter: waitLonger}, nil
 }
 
@@ -175,6 +182,11 @@ func (r *DatasourceReconciler) Checkdatasource(ctx context.Context, logger logr.
 		if err != nil {
 			return r.UpdateStatus(ctx, instance, err)
 		}
+	case arcadiav1alpha1.DatasourceTypeGit:
+		ds, err = datasource.GetGit(ctx, r.Client, instance)
+		if err != nil {
+			return r.UpdateStatus(ctx, instance, err)
+		}
 	case arcadiav1alpha1.DatasourceTypeWeb:
 		info = instance.Spec.Web.DeepCopy()
 		ds, err = datasource.NewWeb(ctx, endpoint.URL)
@@ -219,6 +231,14 @@ func (r *DatasourceReconciler) RemoveDatasource(logger logr.Logger, instance *ar
 	case arcadiav1alpha1.DatasourceTypeRDMA:
 	case arcadiav1alpha1.DatasourceTypePostgreSQL:
 		datasource.RemovePostgreSQLPool(*instance)
+	case arcadiav1alpha1.DatasourceTypeGit:
+		datasource.RemoveGit(*instance)
+	case arcadiav1alpha1.DatasourceTypeWeb:
+		r.stopCrawl(logger, instance)
+		if instance.Spec.Web.Crawler != nil {
+			// the deletion is a best effort and we don't want it to block the current goroutine
+			go r.removeCrawledPages(context.Background(), logger, instance.DeepCopy())
+		}
 	default:
 	}
 }
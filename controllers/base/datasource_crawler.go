@@ -0,0 +1,225 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/minio-go/v7"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/config"
+	"github.com/kubeagi/arcadia/pkg/datasource"
+	"github.com/kubeagi/arcadia/pkg/documentloaders"
+)
+
+const (
+	crawlRetryBaseDelay = time.Minute
+	crawlRetryMaxDelay  = time.Hour
+)
+
+// reconcileCrawl starts to crawl pages for web datasources with a crawler if a crawl is due.
+// It returns how long to wait for the next crawl, zero means no crawl is scheduled.
+func (r *DatasourceReconciler) reconcileCrawl(ctx context.Context, logger logr.Logger, instance *arcadiav1alpha1.Datasource) time.Duration {
+	if instance.Spec.Type() != arcadiav1alpha1.DatasourceTypeWeb || instance.Spec.Web.Crawler == nil {
+		return 0
+	}
+	key := string(instance.GetUID())
+	if _, ok := r.crawls.Load(key); ok {
+		logger.V(5).Info("crawler is running, wait for it")
+		return 0
+	}
+	crawler := instance.Spec.Web.Crawler
+	status := instance.Status.Crawl
+	// the last crawl may be interrupted by a restart if it has not completed
+	due := status == nil || status.ObservedGeneration != instance.Generation || status.CompletionTime == nil
+	if !due {
+		var interval time.Duration
+		if crawler.Interval != nil && crawler.Interval.Duration > 0 {
+			interval = crawler.Interval.Duration
+		}
+		// failed crawls are retried with backoff, or at the interval if it's shorter
+		if status.Failures > 0 {
+			if retry := crawlRetryDelay(status.Failures); interval == 0 || retry < interval {
+				interval = retry
+			}
+		}
+		if interval == 0 {
+			return 0
+		}
+		if wait := time.Until(status.CompletionTime.Add(interval)); wait > 0 {
+			return wait
+		}
+	}
+
+	crawlCtx, cancel := context.WithCancel(context.Background())
+	r.crawls.Store(key, cancel)
+	instance = instance.DeepCopy()
+	go func() {
+		defer func() {
+			cancel()
+			r.crawls.Delete(key)
+		}()
+		r.crawl(crawlCtx, logger.WithName("webcrawler"), instance)
+	}()
+	return 0
+}
+
+// crawlRetryDelay returns the delay before retrying after the given number of consecutive failed crawls
+func crawlRetryDelay(failures int) time.Duration {
+	delay := crawlRetryBaseDelay
+	for i := 1; i < failures && delay < crawlRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > crawlRetryMaxDelay {
+		delay = crawlRetryMaxDelay
+	}
+	return delay
+}
+
+// stopCrawl stops the running crawl of the datasource
+func (r *DatasourceReconciler) stopCrawl(logger logr.Logger, instance *arcadiav1alpha1.Datasource) {
+	if v, ok := r.crawls.LoadAndDelete(string(instance.GetUID())); ok {
+		logger.Info("stop crawler")
+		v.(context.CancelFunc)()
+	}
+}
+
+func (r *DatasourceReconciler) crawl(ctx context.Context, logger logr.Logger, instance *arcadiav1alpha1.Datasource) {
+	logger.Info("start to crawl")
+	status := &arcadiav1alpha1.CrawlStatus{ObservedGeneration: instance.Generation, StartTime: metav1.Now()}
+	if err := r.patchCrawlStatus(ctx, instance, status); err != nil {
+		logger.Error(err, "failed to update crawl status")
+		return
+	}
+	result, err := r.crawlToOSS(ctx, logger, instance)
+	status.Pages, status.Failed = result.Pages, result.Failed
+	if err == nil && result.Pages == 0 && result.Failed > 0 {
+		err = fmt.Errorf("no pages crawled, %d pages failed to be fetched", result.Failed)
+	}
+	if ctx.Err() != nil {
+		// stopped, the crawl will be started again if the datasource still exists
+		return
+	}
+	if err != nil {
+		logger.Error(err, "failed to crawl")
+		status.Message = err.Error()
+		status.Failures = 1
+		if last := instance.Status.Crawl; last != nil && last.ObservedGeneration == instance.Generation {
+			status.Failures = last.Failures + 1
+		}
+	}
+	now := metav1.Now()
+	status.CompletionTime = &now
+	logger.Info("crawl done", "pages", result.Pages, "failed", result.Failed)
+	if err = r.patchCrawlStatus(ctx, instance, status); err != nil {
+		logger.Error(err, "failed to update crawl status")
+	}
+}
+
+// crawlToOSS stores the crawled pages under the crawler path in the namespace bucket of system datasource,
+// pages which are not found any more are removed.
+func (r *DatasourceReconciler) crawlToOSS(ctx context.Context, logger logr.Logger, instance *arcadiav1alpha1.Datasource) (datasource.CrawlResult, error) {
+	result := datasource.CrawlResult{}
+	crawler, err := datasource.NewWebCrawler(instance.Spec.Endpoint.URL, instance.Spec.Web.Crawler)
+	if err != nil {
+		return result, err
+	}
+	oss, err := config.GetSystemDatasourceOSS(ctx)
+	if err != nil {
+		return result, err
+	}
+	bucket := instance.Namespace
+	exists, err := oss.Client.BucketExists(ctx, bucket)
+	if err != nil {
+		return result, err
+	}
+	if !exists {
+		if err = oss.Client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
+			return result, err
+		}
+	}
+
+	prefix := instance.WebCrawlerPath() + "/"
+	written := make(map[string]bool)
+	result, err = crawler.Crawl(ctx, func(ctx context.Context, page *documentloaders.WebPage) error {
+		object := prefix + datasource.WebPageObjectName(page.URL)
+		content := page.String()
+		logger.V(5).Info("store page", "url", page.URL, "object", object)
+		_, err := oss.Client.PutObject(ctx, bucket, object, strings.NewReader(content), int64(len(content)), minio.PutObjectOptions{
+			ContentType: "text/markdown",
+			UserTags:    map[string]string{arcadiav1alpha1.ObjectTypeTag: arcadiav1alpha1.ObjectTypeWeb},
+		})
+		if err != nil {
+			return err
+		}
+		written[object] = true
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	if result.Pages == 0 {
+		// keep the pages crawled before, the website may be unavailable now
+		return result, nil
+	}
+	for object := range oss.Client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return result, object.Err
+		}
+		if written[object.Key] {
+			continue
+		}
+		logger.V(3).Info("remove page which is not found any more", "object", object.Key)
+		if err = oss.Client.RemoveObject(ctx, bucket, object.Key, minio.RemoveObjectOptions{}); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// removeCrawledPages removes all pages stored by the crawler
+func (r *DatasourceReconciler) removeCrawledPages(ctx context.Context, logger logr.Logger, instance *arcadiav1alpha1.Datasource) {
+	oss, err := config.GetSystemDatasourceOSS(ctx)
+	if err != nil {
+		logger.Error(err, "failed to get system datasource, may leave crawled pages")
+		return
+	}
+	objects := oss.Client.ListObjects(ctx, instance.Namespace, minio.ListObjectsOptions{Prefix: instance.WebCrawlerPath() + "/", Recursive: true})
+	for err := range oss.Client.RemoveObjects(ctx, instance.Namespace, objects, minio.RemoveObjectsOptions{}) {
+		logger.Error(err.Err, "failed to remove crawled page", "object", err.ObjectName)
+	}
+}
+
+func (r *DatasourceReconciler) patchCrawlStatus(ctx context.Context, instance *arcadiav1alpha1.Datasource, status *arcadiav1alpha1.CrawlStatus) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &arcadiav1alpha1.Datasource{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(instance), latest); err != nil {
+			return err
+		}
+		orig := latest.DeepCopy()
+		latest.Status.Crawl = status.DeepCopy()
+		return r.Status().Patch(ctx, latest, client.MergeFromWithOptions(orig, client.MergeFromWithOptimisticLock{}))
+	})
+}
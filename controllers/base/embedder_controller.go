@@ -41,7 +41,10 @@ import (
 
 	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
 	"github.com/kubeagi/arcadia/pkg/embeddings"
+	embeddingsdashscope "github.com/kubeagi/arcadia/pkg/embeddings/dashscope"
 	embeddingszhipuai "github.com/kubeagi/arcadia/pkg/embeddings/zhipuai"
+	"github.com/kubeagi/arcadia/pkg/langchainwrap"
+	"github.com/kubeagi/arcadia/pkg/llms/dashscope"
 	"github.com/kubeagi/arcadia/pkg/llms/zhipuai"
 )
 
@@ -249,6 +252,36 @@ func (r *EmbedderReconciler) check3rdPartyEmbedder(ctx context.Context, logger l
 			}
 			msg = "Success"
 		}
+	case embeddings.DashScope:
+		// validate all embedding models
+		for _, model := range models {
+			opts := []embeddingsdashscope.Option{embeddingsdashscope.WithModel(model)}
+			if instance.Spec.Endpoint.URL != "" {
+				opts = append(opts, embeddingsdashscope.WithClientOptions(dashscope.WithBaseURL(instance.Spec.Endpoint.URL)))
+			}
+			embedClient := embeddingsdashscope.NewDashScopeEmbedder(apiKey, opts...)
+			_, err = embedClient.EmbedQuery(ctx, embedingText)
+			if err != nil {
+				return r.UpdateStatus(ctx, instance, nil, err)
+			}
+			msg = "Success"
+		}
+	case embeddings.OpenAICompatible:
+		// validate all embedding models, and their dimensions if declared
+		for _, model := range models {
+			embedClient, err := langchainwrap.GetLangchainEmbedder(ctx, instance, r.Client, model)
+			if err != nil {
+				return r.UpdateStatus(ctx, instance, nil, err)
+			}
+			embedding, err := embedClient.EmbedQuery(ctx, embedingText)
+			if err != nil {
+				return r.UpdateStatus(ctx, instance, nil, err)
+			}
+			if capability, ok := instance.GetModelCapability(model); ok && capability.EmbeddingDimension != 0 && capability.EmbeddingDimension != len(embedding) {
+				return r.UpdateStatus(ctx, instance, nil, fmt.Errorf("model %s declares embedding dimension %d, but got %d", model, capability.EmbeddingDimension, len(embedding)))
+			}
+			msg = "Success"
+		}
 	default:
 		return r.UpdateStatus(ctx, instance, nil, fmt.Errorf("unsupported service type: %s", instance.Spec.Type))
 	}
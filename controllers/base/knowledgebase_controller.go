@@ -24,7 +24,6 @@ import (
 	"io"
 	"path/filepath"
 	"reflect"
-	"strings"
 	"sync"
 	"time"
 
@@ -48,8 +47,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
-	"github.com/kubeagi/arcadia/pkg/config"
-	"github.com/kubeagi/arcadia/pkg/datasource"
 	pkgdocumentloaders "github.com/kubeagi/arcadia/pkg/documentloaders"
 	"github.com/kubeagi/arcadia/pkg/langchainwrap"
 	"github.com/kubeagi/arcadia/pkg/utils"
@@ -64,15 +61,16 @@ const (
 	waitSmaller = time.Second * 3
 	waitMedium  = time.Minute
 
-	retryForFailed = "for-failed"
+	retryForFailed = arcadiav1alpha1.UpdateSourceFileRetryFailed
 )
 
 var (
-	errNoSource            = fmt.Errorf("no source")
-	errDataSourceNotReady  = fmt.Errorf("datasource is not ready")
-	errEmbedderNotReady    = fmt.Errorf("embedder is not ready")
-	errVectorStoreNotReady = fmt.Errorf("vectorstore is not ready")
-	errFileSkipped         = fmt.Errorf("file is skipped")
+	errNoSource             = fmt.Errorf("no source")
+	errDataSourceNotReady   = fmt.Errorf("datasource is not ready")
+	errEmbedderNotReady     = fmt.Errorf("embedder is not ready")
+	errVectorStoreNotReady  = fmt.Errorf("vectorstore is not ready")
+	errFileSkipped          = fmt.Errorf("file is skipped")
+	errEmbedderIncompatible = fmt.Errorf("embedder is incompatible with the embedded vectors")
 )
 
 // KnowledgeBaseReconciler reconciles a KnowledgeBase object
@@ -82,6 +80,9 @@ type KnowledgeBaseReconciler struct {
 	HasHandledSuccessPath map[string]bool
 	readyMu               sync.Mutex
 	ReadyMap              map[string]bool
+
+	// embeddingPools holds the running worker pools which embed files, keyed by knowledgebase uid
+	embeddingPools sync.Map
 }
 
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=knowledgebases,verbs=get;list;watch;create;update;patch;delete
@@ -93,6 +94,8 @@ type KnowledgeBaseReconciler struct {
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=versioneddataset/status,verbs=get
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=vectorstores,verbs=get;list;watch
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=vectorstores/status,verbs=get
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=datasources,verbs=get;list;watch
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=versioneddatasets,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -116,6 +119,7 @@ func (r *KnowledgeBaseReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	// indicated by the deletion timestamp being set.
 	if kb.GetDeletionTimestamp() != nil && controllerutil.ContainsFinalizer(kb, arcadiav1alpha1.Finalizer) {
 		log.Info("Performing Finalizer Operations for KnowledgeBase before delete CR")
+		r.stopEmbedding(log, kb)
 		r.reconcileDelete(ctx, log, kb)
 		log.Info("Removing Finalizer for KnowledgeBase after successfully performing the operations")
 		controllerutil.RemoveFinalizer(kb, arcadiav1alpha1.Finalizer)
@@ -154,6 +158,7 @@ func (r *KnowledgeBaseReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	if kb.Status.ObservedGeneration != kb.Generation {
+		r.stopEmbedding(log, kb)
 		kb.Status.ObservedGeneration = kb.Generation
 		log.Info("start to set InitCondition")
 		kb = r.setCondition(log, kb, kb.InitCondition())
@@ -162,9 +167,14 @@ func (r *KnowledgeBaseReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	if v := kb.Annotations[arcadiav1alpha1.UpdateSourceFileAnnotationKey]; v != "" {
 		log.Info("Manual update")
+		r.stopEmbedding(log, kb)
 		kbNew := kb.DeepCopy()
 		if v != retryForFailed && len(kb.Status.FileGroupDetail) != 0 {
 			log.Info("set FileGroupDetail to nil to redo embedder...")
+			if err = r.resetEmbedding(ctx, log, kbNew); err != nil {
+				log.Error(err, "Failed to remove the collection of the incompatible embedder")
+				return reconcile.Result{}, err
+			}
 			kbNew.Status.FileGroupDetail = nil
 			kbNew = r.setCondition(log, kbNew, kbNew.InitCondition())
 			return reconcile.Result{}, r.patchStatus(ctx, log, kbNew)
@@ -256,6 +266,29 @@ func (r *KnowledgeBaseReconciler) SetupWithManager(ctx context.Context, mgr ctrl
 		return err
 	}
 
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &arcadiav1alpha1.KnowledgeBase{}, SourceIndexKey,
+		func(o client.Object) []string {
+			kb, ok := o.(*arcadiav1alpha1.KnowledgeBase)
+			if !ok {
+				return nil
+			}
+			// only knowledgebases in watch mode need to be notified of source changes
+			if kb.Spec.SyncPolicy == nil || kb.Spec.SyncPolicy.Mode != arcadiav1alpha1.SyncModeWatch {
+				return nil
+			}
+			sources := make([]string, 0, len(kb.Spec.FileGroups))
+			for _, fg := range kb.Spec.FileGroups {
+				if fg.Source == nil {
+					continue
+				}
+				sources = append(sources, sourceKey(fg.Source, kb.Namespace))
+			}
+			return sources
+		},
+	); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&arcadiav1alpha1.KnowledgeBase{}).
 		Watches(&source.Kind{Type: &arcadiav1alpha1.Embedder{}},
@@ -284,6 +317,10 @@ func (r *KnowledgeBaseReconciler) SetupWithManager(ctx context.Context, mgr ctrl
 				}
 				return reqs
 			})).
+		Watches(&source.Kind{Type: &arcadiav1alpha1.Datasource{}},
+			handler.EnqueueRequestsFromMapFunc(r.mapSourceToKnowledgeBases(ctx, "Datasource"))).
+		Watches(&source.Kind{Type: &arcadiav1alpha1.VersionedDataset{}},
+			handler.EnqueueRequestsFromMapFunc(r.mapSourceToKnowledgeBases(ctx, "VersionedDataset"))).
 		Complete(r)
 }
 
@@ -291,7 +328,7 @@ func (r *KnowledgeBaseReconciler) reconcile(ctx context.Context, log logr.Logger
 	// Observe generation change or manual update
 	embedderReq := kb.Spec.Embedder
 	vectorStoreReq := kb.Spec.VectorStore
-	fileGroupsReq := kb.Spec.FileGroups
+	fileGroupsReq := kb.FileGroups()
 	if embedderReq == nil || vectorStoreReq == nil || len(fileGroupsReq) == 0 {
 		kb = r.setCondition(log, kb, kb.PendingCondition("embedder or vectorstore or filegroups is not setting"))
 		return ctrl.Result{}, r.patchStatus(ctx, log, kb)
@@ -309,6 +346,7 @@ func (r *KnowledgeBaseReconciler) reconcile(ctx context.Context, log logr.Logger
 	}
 	if !embedder.Status.IsReady() {
 		log.Info(fmt.Sprintf("embedder %s is not ready", embedder.Name))
+		r.stopEmbedding(log, kb)
 		kb = r.setCondition(log, kb, kb.ErrorCondition(errEmbedderNotReady.Error()))
 		return ctrl.Result{}, r.patchStatus(ctx, log, kb)
 	}
@@ -325,51 +363,74 @@ func (r *KnowledgeBaseReconciler) reconcile(ctx context.Context, log logr.Logger
 	}
 	if !vectorStore.Status.IsReady() {
 		log.Info(fmt.Sprintf("vectorstore %s is not ready", vectorStore.Name))
+		r.stopEmbedding(log, kb)
 		kb = r.setCondition(log, kb, kb.ErrorCondition(errVectorStoreNotReady.Error()))
 		return ctrl.Result{}, r.patchStatus(ctx, log, kb)
 	}
 
+	if recorded, err := r.checkEmbedding(ctx, kb, embedder); err != nil {
+		log.Info("check embedding error " + err.Error())
+		r.stopEmbedding(log, kb)
+		kb = r.setCondition(log, kb, kb.ErrorCondition(err.Error()))
+		return ctrl.Result{}, r.patchStatus(ctx, log, kb)
+	} else if recorded {
+		log.Info("record the embedding identity", "embedding", kb.Status.Embedding)
+		return ctrl.Result{Requeue: true}, r.patchStatus(ctx, log, kb)
+	}
+	if reset, err := r.checkFileMetadata(ctx, log, kb, vectorStore); err != nil {
+		log.Info("reset the collection error " + err.Error())
+		kb = r.setCondition(log, kb, kb.ErrorCondition(err.Error()))
+		return ctrl.Result{}, r.patchStatus(ctx, log, kb)
+	} else if reset {
+		return ctrl.Result{Requeue: true}, r.patchStatus(ctx, log, kb)
+	}
+
+	synced, syncAfter, err := r.reconcileSync(ctx, log, kb, vectorStore)
+	if err != nil || synced {
+		return ctrl.Result{}, err
+	}
+
 	if kb.Status.IsReady() || r.isReady(kb) {
 		log.Info("KnowledgeBase is ready, skip reconcile")
-		return ctrl.Result{}, nil
+		return ctrl.Result{RequeueAfter: syncAfter}, nil
 	}
 
-	haveFailed := false
+	haveFailed, havePending, haveProcessing := false, false, false
 	for out, fg := range kb.Status.FileGroupDetail {
 		if fg.Source == nil {
 			log.Info(fmt.Sprintf("kb.Status.FileGroupDetail[%d] source is nil, skip", out))
 			continue
 		}
 		for in, f := range fg.FileDetails {
-			if f.Phase == arcadiav1alpha1.FileProcessPhaseSkipped {
-				log.Info(fmt.Sprintf("source %s/%s, file %s, the current phase is skip and will not be processed.", fg.Source.Kind, fg.Source.Name, f.Path))
-				continue
-			}
-			if f.Phase == arcadiav1alpha1.FileProcessPhasePending {
+			switch f.Phase {
+			case arcadiav1alpha1.FileProcessPhaseSkipped:
+				log.V(5).Info(fmt.Sprintf("source %s/%s, file %s, the current phase is skip and will not be processed.", fg.Source.Kind, fg.Source.Name, f.Path))
+			case arcadiav1alpha1.FileProcessPhasePending:
 				log.V(5).Info(fmt.Sprintf("source: %s/%s file: %s, cur is Pending,change it to Processing", fg.Source.Kind, fg.Source.Name, f.Path))
 				kb.Status.FileGroupDetail[out].FileDetails[in].Phase = arcadiav1alpha1.FileProcessPhaseProcessing
 				kb.Status.FileGroupDetail[out].FileDetails[in].LastUpdateTime = metav1.Now()
-				return ctrl.Result{}, r.patchStatus(ctx, log, kb)
-			}
-			if f.Phase == arcadiav1alpha1.FileProcessPhaseFailed {
-				log.Info(fmt.Sprintf("source: %s/%s, file: %s, is failed skip.", fg.Source.Kind, fg.Source.Name, f.Path))
+				havePending = true
+			case arcadiav1alpha1.FileProcessPhaseFailed:
+				log.V(5).Info(fmt.Sprintf("source: %s/%s, file: %s, is failed skip.", fg.Source.Kind, fg.Source.Name, f.Path))
 				haveFailed = true
-				continue
-			}
-			if f.Phase == arcadiav1alpha1.FileProcessPhaseSucceeded {
-				log.Info(fmt.Sprintf("source %s/%s, file %s, processing completed", fg.Source.Kind, fg.Source.Name, f.Path))
-				continue
-			}
-			if f.Phase == arcadiav1alpha1.FileProcessPhaseProcessing {
-				log.Info(fmt.Sprintf("source: %s/%s, file: %s, is Processing", fg.Source.Kind, fg.Source.Name, f.Path))
-				err := r.reconcileFileGroup(ctx, log, kb, vectorStore, embedder, out, in)
-				if err != nil {
-					log.Error(err, "failed to handle single file", "FileName", f.Path)
-				}
-				return ctrl.Result{Requeue: true}, r.patchStatus(ctx, log, kb)
+			case arcadiav1alpha1.FileProcessPhaseSucceeded:
+				log.V(5).Info(fmt.Sprintf("source %s/%s, file %s, processing completed", fg.Source.Kind, fg.Source.Name, f.Path))
+			case arcadiav1alpha1.FileProcessPhaseProcessing:
+				log.V(5).Info(fmt.Sprintf("source: %s/%s, file: %s, is Processing", fg.Source.Kind, fg.Source.Name, f.Path))
+				haveProcessing = true
 			}
 		}
 	}
+	if havePending {
+		kb.UpdateProgress(metav1.Now())
+		return ctrl.Result{}, r.patchStatus(ctx, log, kb)
+	}
+	if haveProcessing {
+		// files are embedded by a background worker pool, which updates the file status when each file is done.
+		// requeue to restart the pool in case it exits with some files left.
+		r.startEmbedding(ctx, log, kb, vectorStore, embedder)
+		return ctrl.Result{RequeueAfter: waitMedium}, nil
+	}
 	if haveFailed {
 		r.setCondition(log, kb, kb.ErrorCondition("some files failed to process."))
 		return ctrl.Result{RequeueAfter: waitMedium}, r.patchStatus(ctx, log, kb)
@@ -377,7 +438,97 @@ func (r *KnowledgeBaseReconciler) reconcile(ctx context.Context, log logr.Logger
 	if kb.Status.Conditions[0].Status != corev1.ConditionTrue {
 		kb = r.setCondition(log, kb, kb.ReadyCondition())
 	}
-	return ctrl.Result{}, r.patchStatus(ctx, log, kb)
+	return ctrl.Result{RequeueAfter: syncAfter}, r.patchStatus(ctx, log, kb)
+}
+
+// checkEmbedding records the embedding identity of the collection on the first embedding, returns true if it's recorded.
+// Vectors from different embedding models can't be searched together, so an incompatible embedder is rejected.
+func (r *KnowledgeBaseReconciler) checkEmbedding(ctx context.Context, kb *arcadiav1alpha1.KnowledgeBase, embedder *arcadiav1alpha1.Embedder) (bool, error) {
+	current := embedder.EmbeddingIdentity("")
+	current.CollectionName = kb.VectorStoreCollectionName()
+	recorded := kb.Status.Embedding
+	if recorded != nil && recorded.CollectionName == current.CollectionName {
+		if reason := recorded.IncompatibleReason(current); reason != "" {
+			return false, fmt.Errorf("%w: %s. set annotation %s to re-embed all files, or migrate with a KnowledgeBaseMigration",
+				errEmbedderIncompatible, reason, arcadiav1alpha1.UpdateSourceFileAnnotationKey)
+		}
+		return false, nil
+	}
+	if current.Dimension == 0 {
+		// the dimension is not declared in the model capabilities, get it from the embedder
+		em, err := langchainwrap.GetLangchainEmbedder(ctx, embedder, r.Client, "")
+		if err != nil {
+			return false, err
+		}
+		vector, err := em.EmbedQuery(ctx, "dimension")
+		if err != nil {
+			return false, fmt.Errorf("failed to get the embedding dimension: %w", err)
+		}
+		current.Dimension = len(vector)
+	}
+	if !hasEmbeddedFiles(kb) || (recorded != nil && recorded.FileMetadataKey == vectorstore.FileMetadataKey) {
+		// documents of a new collection, or of a collection migrated from a tracked one, record their files
+		current.FileMetadataKey = vectorstore.FileMetadataKey
+	}
+	kb.Status.Embedding = &current
+	return true, nil
+}
+
+// checkFileMetadata re-embeds all files into an empty collection if the documents don't record their files with
+// vectorstore.FileMetadataKey, as they can't be removed when the files are changed or removed. Returns true if it's reset.
+func (r *KnowledgeBaseReconciler) checkFileMetadata(ctx context.Context, log logr.Logger, kb *arcadiav1alpha1.KnowledgeBase, vectorStore *arcadiav1alpha1.VectorStore) (bool, error) {
+	recorded := kb.Status.Embedding
+	if recorded == nil || recorded.FileMetadataKey == vectorstore.FileMetadataKey {
+		return false, nil
+	}
+	log.Info("remove the collection before re-embedding, documents are embedded without the file metadata", "key", vectorstore.FileMetadataKey)
+	r.stopEmbedding(log, kb)
+	if err := vectorstore.RemoveCollection(ctx, log, vectorStore, recorded.CollectionName, r.Client); err != nil {
+		return false, err
+	}
+	kb.Status.FileGroupDetail = nil
+	recorded.FileMetadataKey = vectorstore.FileMetadataKey
+	r.setCondition(log, kb, kb.InitCondition())
+	return true, nil
+}
+
+// hasEmbeddedFiles returns true if any file has been embedded into the collection
+func hasEmbeddedFiles(kb *arcadiav1alpha1.KnowledgeBase) bool {
+	for _, fg := range kb.Status.FileGroupDetail {
+		for _, f := range fg.FileDetails {
+			if f.Checksum != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resetEmbedding removes the collection if its vectors are generated by an incompatible embedder,
+// so all files are re-embedded into an empty collection
+func (r *KnowledgeBaseReconciler) resetEmbedding(ctx context.Context, log logr.Logger, kb *arcadiav1alpha1.KnowledgeBase) error {
+	recorded := kb.Status.Embedding
+	if recorded == nil || recorded.CollectionName != kb.VectorStoreCollectionName() || kb.Spec.Embedder == nil || kb.Spec.VectorStore == nil {
+		return nil
+	}
+	embedder := &arcadiav1alpha1.Embedder{}
+	if err := r.Get(ctx, types.NamespacedName{Name: kb.Spec.Embedder.Name, Namespace: kb.Spec.Embedder.GetNamespace(kb.GetNamespace())}, embedder); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	reason := recorded.IncompatibleReason(embedder.EmbeddingIdentity(""))
+	if reason == "" {
+		return nil
+	}
+	vectorStore := &arcadiav1alpha1.VectorStore{}
+	if err := r.Get(ctx, types.NamespacedName{Name: kb.Spec.VectorStore.Name, Namespace: kb.Spec.VectorStore.GetNamespace(kb.GetNamespace())}, vectorStore); err != nil {
+		return err
+	}
+	log.Info("remove the collection before re-embedding", "reason", reason)
+	if err := vectorstore.RemoveCollection(ctx, log, vectorStore, kb.VectorStoreCollectionName(), r.Client); err != nil {
+		return err
+	}
+	kb.Status.Embedding = nil
+	return nil
 }
 
 func (r *KnowledgeBaseReconciler) setCondition(log logr.Logger, kb *arcadiav1alpha1.KnowledgeBase, condition ...arcadiav1alpha1.Condition) *arcadiav1alpha1.KnowledgeBase {
@@ -397,100 +548,37 @@ func (r *KnowledgeBaseReconciler) setCondition(log logr.Logger, kb *arcadiav1alp
 	return kb
 }
 
+// reconcileFileGroup embeds a single file of the group, and records the result in fileDetail.
+// kb is read only here, so it's safe to process files of the same knowledgebase concurrently.
 func (r *KnowledgeBaseReconciler) reconcileFileGroup(
 	ctx context.Context,
 	log logr.Logger,
 	kb *arcadiav1alpha1.KnowledgeBase,
 	vectorStore *arcadiav1alpha1.VectorStore,
 	embedder *arcadiav1alpha1.Embedder,
-	groupIndex, fileIndex int,
+	group *arcadiav1alpha1.FileGroupDetail,
+	fileDetail *arcadiav1alpha1.FileDetails,
 ) (err error) {
 	defer func() {
 		if err != nil {
 			err = fmt.Errorf("failed to reconcile FileGroup: %w", err)
 		}
 	}()
-	group := kb.Status.FileGroupDetail[groupIndex]
-	fileDetail := kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex]
-
-	ns := kb.Namespace
-	if group.Source.Namespace != nil {
-		ns = *group.Source.Namespace
-	}
-
-	lowerKind := strings.ToLower(group.Source.Kind)
-	var ds datasource.Datasource
-	info := &arcadiav1alpha1.OSS{Bucket: ns}
-	var vsBasePath string
-	switch lowerKind {
-	case "versioneddataset":
-		versionedDataset := &arcadiav1alpha1.VersionedDataset{}
-		if err = r.Get(ctx, types.NamespacedName{Name: group.Source.Name, Namespace: ns}, versionedDataset); err != nil {
-			if apierrors.IsNotFound(err) {
-				return errNoSource
-			}
-			return err
-		}
-		if versionedDataset.Spec.Dataset == nil {
-			return fmt.Errorf("versionedDataset.Spec.Dataset is nil")
-		}
-		if !versionedDataset.Status.IsReady() {
-			return errDataSourceNotReady
-		}
-		system, err := config.GetSystemDatasource(ctx)
-		if err != nil {
-			return err
-		}
-		endpoint := system.Spec.Endpoint.DeepCopy()
-		if endpoint != nil && endpoint.AuthSecret != nil {
-			endpoint.AuthSecret.WithNameSpace(system.Namespace)
-		}
-		ds, err = datasource.NewLocal(ctx, r.Client, endpoint)
-		if err != nil {
-			return err
-		}
-		// basepath for this versioneddataset
-		vsBasePath = filepath.Join("dataset", versionedDataset.Spec.Dataset.Name, versionedDataset.Spec.Version)
-		info.Object = filepath.Join(vsBasePath, fileDetail.Path)
 
-	case "datasource", "":
-		dsObj := &arcadiav1alpha1.Datasource{}
-		if err = r.Get(ctx, types.NamespacedName{Name: group.Source.Name, Namespace: ns}, dsObj); err != nil {
-			if apierrors.IsNotFound(err) {
-				return errNoSource
-			}
-			return err
-		}
-		if !dsObj.Status.IsReady() {
-			return errDataSourceNotReady
-		}
-		// set endpoint's auth secret namespace to current datasource if not set
-		endpoint := dsObj.Spec.Endpoint.DeepCopy()
-		if endpoint != nil && endpoint.AuthSecret != nil {
-			endpoint.AuthSecret.WithNameSpace(dsObj.Namespace)
-		}
-		ds, err = datasource.NewOSS(ctx, r.Client, endpoint)
-		if err != nil {
-			return err
-		}
-		// for none-conversation knowledgebase, bucket is the same as datasource.
-		if kb.Spec.Type != arcadiav1alpha1.KnowledgeBaseTypeConversation {
-			info.Bucket = dsObj.Spec.OSS.Bucket
-		}
-
-		info.Object = fileDetail.Path
-	default:
-		return fmt.Errorf("source type %s not supported yet", group.Source.Kind)
+	loc, err := r.sourceLocation(ctx, kb, group.Source)
+	if err != nil {
+		return err
 	}
-
+	ds := loc.ds
+	info := &arcadiav1alpha1.OSS{Bucket: loc.bucket, Object: loc.objectName(fileDetail.Path)}
 	info.VersionID = fileDetail.Version
 
 	stat, err := ds.StatFile(ctx, info)
 	log.V(5).Info(fmt.Sprintf("raw StatFile:%#v", stat), "path", fileDetail.Path)
 	if err != nil {
 		log.Error(err, fmt.Sprintf("stat file failed. source: %s/%s/%s, file: %s, error: %s",
-			group.Source.Kind, ns, group.Source.Name, fileDetail.Path, err))
-		kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].UpdateErr(err, arcadiav1alpha1.FileProcessPhaseFailed)
+			group.Source.Kind, group.Source.GetNamespace(kb.Namespace), group.Source.Name, fileDetail.Path, err))
+		fileDetail.UpdateErr(err, arcadiav1alpha1.FileProcessPhaseFailed)
 		return err
 	}
 
@@ -498,76 +586,99 @@ func (r *KnowledgeBaseReconciler) reconcileFileGroup(
 	log.V(5).Info(fmt.Sprintf("minio StatFile:%#v", objectStat), "path", fileDetail.Path)
 	if !ok {
 		err = fmt.Errorf("failed to convert stat to minio.ObjectInfo:%s", fileDetail.Path)
-		kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].UpdateErr(err, arcadiav1alpha1.FileProcessPhaseFailed)
+		fileDetail.UpdateErr(err, arcadiav1alpha1.FileProcessPhaseFailed)
 		return err
 	}
-	kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].Version = fileDetail.Version
 	if objectStat.ETag == fileDetail.Checksum {
-		kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].Phase = arcadiav1alpha1.FileProcessPhaseSucceeded
+		// the file has been embedded before, no need to do it again
+		fileDetail.UpdateErr(nil, arcadiav1alpha1.FileProcessPhaseSucceeded)
 		return nil
 	}
-	kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].Checksum = objectStat.ETag
+	if fileDetail.Checksum != "" {
+		// the file is changed, remove documents of the previous version
+		if err = vectorstore.RemoveFileDocuments(ctx, log, vectorStore, kb.VectorStoreCollectionName(), r.Client, info.Object); err != nil {
+			fileDetail.UpdateErr(err, arcadiav1alpha1.FileProcessPhaseFailed)
+			return err
+		}
+	}
+	fileDetail.Checksum = objectStat.ETag
 
 	tags, err := ds.GetTags(ctx, info)
 	if err != nil {
 		fileDetail.UpdateErr(err, arcadiav1alpha1.FileProcessPhaseFailed)
-		kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].UpdateErr(err, arcadiav1alpha1.FileProcessPhaseFailed)
 		return err
 	}
 
-	kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].Size = utils.BytesToSizedStr(objectStat.Size)
+	fileDetail.Size = utils.BytesToSizedStr(objectStat.Size)
 	// File Type in string
-	kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].Type = tags[arcadiav1alpha1.ObjectCountTag]
+	fileDetail.Type = tags[arcadiav1alpha1.ObjectCountTag]
 	// File data count in string
-	kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].Count = tags[arcadiav1alpha1.ObjectCountTag]
+	fileDetail.Count = tags[arcadiav1alpha1.ObjectCountTag]
 
 	file, err := ds.ReadFile(ctx, info)
 	if err != nil {
-		kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].UpdateErr(err, arcadiav1alpha1.FileProcessPhaseFailed)
+		fileDetail.UpdateErr(err, arcadiav1alpha1.FileProcessPhaseFailed)
 		return err
 	}
 	defer file.Close()
 	startTime := time.Now()
-	if err = r.handleFile(ctx, log, file, info.Object, tags, kb, vectorStore, embedder); err != nil {
+	chunks, err := r.handleFile(ctx, log, file, info.Object, tags, kb, vectorStore, embedder)
+	if err != nil {
 		if errors.Is(err, errFileSkipped) {
-			kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].UpdateErr(err, arcadiav1alpha1.FileProcessPhaseSkipped)
+			fileDetail.UpdateErr(err, arcadiav1alpha1.FileProcessPhaseSkipped)
 		} else {
-			kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].UpdateErr(err, arcadiav1alpha1.FileProcessPhaseFailed)
+			fileDetail.UpdateErr(err, arcadiav1alpha1.FileProcessPhaseFailed)
 		}
 		return err
 	}
 	cost := int64(time.Since(startTime).Milliseconds())
 
-	kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].TimeCost = cost
+	fileDetail.TimeCost = cost
+	fileDetail.Chunks = chunks
 	log.Info("handle FileGroup succeeded", "timecost(milliseconds)", cost)
-	kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].UpdateErr(err, arcadiav1alpha1.FileProcessPhaseSucceeded)
+	fileDetail.UpdateErr(err, arcadiav1alpha1.FileProcessPhaseSucceeded)
 	return nil
 }
 
-func (r *KnowledgeBaseReconciler) handleFile(ctx context.Context, log logr.Logger, file io.ReadCloser, fileName string, tags map[string]string, kb *arcadiav1alpha1.KnowledgeBase, store *arcadiav1alpha1.VectorStore, embedder *arcadiav1alpha1.Embedder) (err error) {
+// handleFile splits the file into chunks and adds them to the vectorstore, returns the number of chunks
+func (r *KnowledgeBaseReconciler) handleFile(ctx context.Context, log logr.Logger, file io.ReadCloser, fileName string, tags map[string]string, kb *arcadiav1alpha1.KnowledgeBase, store *arcadiav1alpha1.VectorStore, embedder *arcadiav1alpha1.Embedder) (chunks int, err error) {
 	log = log.WithValues("fileName", fileName, "tags", tags)
 	if !embedder.Status.IsReady() {
-		return errEmbedderNotReady
+		return 0, errEmbedderNotReady
 	}
 	if !store.Status.IsReady() {
-		return errVectorStoreNotReady
+		return 0, errVectorStoreNotReady
 	}
 	embeddingOptions := kb.EmbeddingOptions()
-	em, err := langchainwrap.GetLangchainEmbedder(ctx, embedder, r.Client, "", embeddings.WithBatchSize(embeddingOptions.BatchSize))
+	em, err := langchainwrap.GetIndexingEmbedder(ctx, embedder, r.Client, "", embeddings.WithBatchSize(embeddingOptions.BatchSize))
 	if err != nil {
-		return err
+		return 0, err
 	}
 	data, err := io.ReadAll(file) // TODO Load large files in pieces to save memory
 	// TODO Line or single line byte exceeds embedder limit
 	if err != nil {
-		return err
+		return 0, err
 	}
 	dataReader := bytes.NewReader(data)
 	var documents []schema.Document
 	var loader documentloaders.Loader
 	switch filepath.Ext(fileName) {
+	case ".md":
+		if tags[arcadiav1alpha1.ObjectTypeTag] == arcadiav1alpha1.ObjectTypeWeb {
+			// pages crawled from web, keep the url in metadata
+			loader = pkgdocumentloaders.NewWeb(dataReader)
+		} else {
+			loader = documentloaders.NewText(dataReader)
+		}
 	case ".txt":
 		loader = documentloaders.NewText(dataReader)
+	case ".json":
+		if tags[arcadiav1alpha1.ObjectTypeTag] == arcadiav1alpha1.ObjectTypeRecord {
+			// rows of database tables, keep the id, title and metadata columns in metadata
+			loader = pkgdocumentloaders.NewRecord(dataReader)
+		} else {
+			loader = documentloaders.NewText(dataReader)
+		}
 	case ".csv":
 		v, ok := tags[arcadiav1alpha1.ObjectTypeTag]
 		if ok && v == arcadiav1alpha1.ObjectTypeQA {
@@ -611,10 +722,17 @@ func (r *KnowledgeBaseReconciler) handleFile(ctx context.Context, log logr.Logge
 
 	documents, err = loader.LoadAndSplit(ctx, split)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	// record the source file, so documents can be removed when the file is changed or removed
+	for i := range documents {
+		if documents[i].Metadata == nil {
+			documents[i].Metadata = make(map[string]any)
+		}
+		documents[i].Metadata[vectorstore.FileMetadataKey] = fileName
 	}
 
-	return vectorstore.AddDocuments(ctx, log, store, em, kb.VectorStoreCollectionName(), r.Client, documents)
+	return len(documents), vectorstore.AddDocuments(ctx, log, store, em, kb.VectorStoreCollectionName(), r.Client, documents)
 }
 
 func (r *KnowledgeBaseReconciler) reconcileDelete(ctx context.Context, log logr.Logger, kb *arcadiav1alpha1.KnowledgeBase) {
@@ -688,6 +806,7 @@ func isFileDetailDiff(a, b arcadiav1alpha1.FileDetails) bool {
 		a.Size != b.Size ||
 		a.Checksum != b.Checksum ||
 		a.TimeCost != b.TimeCost ||
+		a.Chunks != b.Chunks ||
 		a.Phase != b.Phase ||
 		a.ErrMessage != b.ErrMessage ||
 		a.Version != b.Version
@@ -698,7 +817,7 @@ func (r *KnowledgeBaseReconciler) syncStatus(ctx context.Context, kb *arcadiav1a
 	newStatus := make([]arcadiav1alpha1.FileGroupDetail, 0)
 	specSource := make(map[string]map[string][2]int)
 	now := metav1.Now()
-	for _, fg := range kb.Spec.FileGroups {
+	for _, fg := range kb.FileGroups() {
 		if fg.Source == nil {
 			continue
 		}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/embeddings"
+	"github.com/kubeagi/arcadia/pkg/vectorstore"
+)
+
+func TestCheckEmbedding(t *testing.T) {
+	probes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"object": "list",
+			"data":   []map[string]any{{"object": "embedding", "index": 0, "embedding": []float32{0.1, 0.2, 0.3}}},
+			"usage":  map[string]int{"prompt_tokens": 1, "total_tokens": 1},
+		})
+	}))
+	defer server.Close()
+	embedder := func(name, model string, dimension int) *arcadiav1alpha1.Embedder {
+		return &arcadiav1alpha1.Embedder{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: name},
+			Spec: arcadiav1alpha1.EmbedderSpec{
+				Type:              embeddings.OpenAICompatible,
+				Provider:          arcadiav1alpha1.Provider{Endpoint: &arcadiav1alpha1.Endpoint{URL: server.URL}},
+				ModelCapabilities: []arcadiav1alpha1.ModelCapability{{Name: model, EmbeddingDimension: dimension}},
+			},
+		}
+	}
+	newKB := func() *arcadiav1alpha1.KnowledgeBase {
+		return &arcadiav1alpha1.KnowledgeBase{ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: "kb"}}
+	}
+	r := &KnowledgeBaseReconciler{}
+	ctx := context.Background()
+
+	// the first embedding records the identity with the declared dimension
+	kb := newKB()
+	recorded, err := r.checkEmbedding(ctx, kb, embedder("bge", "bge-large-zh", 1024))
+	if err != nil || !recorded {
+		t.Fatalf("expect recorded, got %v %v", recorded, err)
+	}
+	expected := arcadiav1alpha1.EmbeddingIdentity{Embedder: "arcadia/bge", Type: string(embeddings.OpenAICompatible), Model: "bge-large-zh", Dimension: 1024, CollectionName: "arcadia_kb", FileMetadataKey: vectorstore.FileMetadataKey}
+	if *kb.Status.Embedding != expected || probes != 0 {
+		t.Errorf("expect %+v without probing, got %+v and %d probes", expected, *kb.Status.Embedding, probes)
+	}
+
+	// a compatible embedder keeps the recorded identity
+	if recorded, err = r.checkEmbedding(ctx, kb, embedder("bge-copy", "bge-large-zh", 1024)); err != nil || recorded {
+		t.Errorf("expect compatible, got %v %v", recorded, err)
+	}
+	if kb.Status.Embedding.Embedder != "arcadia/bge" {
+		t.Errorf("expect the recorded embedder kept, got %s", kb.Status.Embedding.Embedder)
+	}
+
+	// an incompatible model or dimension is rejected
+	for _, e := range []*arcadiav1alpha1.Embedder{embedder("m3e", "m3e-base", 768), embedder("bge-small", "bge-large-zh", 512)} {
+		recorded, err = r.checkEmbedding(ctx, kb, e)
+		if recorded || !errors.Is(err, errEmbedderIncompatible) || !strings.Contains(err.Error(), arcadiav1alpha1.UpdateSourceFileAnnotationKey) {
+			t.Errorf("%s: expect incompatible, got %v %v", e.Name, recorded, err)
+		}
+	}
+
+	// another collection records a new identity
+	kb.Spec.CollectionName = "arcadia_kb_m3e"
+	if recorded, err = r.checkEmbedding(ctx, kb, embedder("m3e", "m3e-base", 768)); err != nil || !recorded {
+		t.Errorf("expect recorded for the new collection, got %v %v", recorded, err)
+	}
+	if kb.Status.Embedding.Model != "m3e-base" || kb.Status.Embedding.CollectionName != "arcadia_kb_m3e" {
+		t.Errorf("expect the identity of the new collection, got %+v", *kb.Status.Embedding)
+	}
+
+	// files embedded before the identity is recorded don't record their files
+	kb = newKB()
+	kb.Status.FileGroupDetail = []arcadiav1alpha1.FileGroupDetail{{FileDetails: []arcadiav1alpha1.FileDetails{{Path: "a.txt", Checksum: "1"}}}}
+	if recorded, err = r.checkEmbedding(ctx, kb, embedder("bge", "bge-large-zh", 1024)); err != nil || !recorded {
+		t.Fatalf("expect recorded, got %v %v", recorded, err)
+	}
+	if kb.Status.Embedding.FileMetadataKey != "" {
+		t.Errorf("expect no file metadata key for the embedded files, got %s", kb.Status.Embedding.FileMetadataKey)
+	}
+	// but the ones migrated from a collection which records them do
+	kb.Spec.CollectionName = "arcadia_kb_migrated"
+	kb.Status.Embedding.FileMetadataKey = vectorstore.FileMetadataKey
+	if recorded, err = r.checkEmbedding(ctx, kb, embedder("bge", "bge-large-zh", 1024)); err != nil || !recorded {
+		t.Fatalf("expect recorded, got %v %v", recorded, err)
+	}
+	if kb.Status.Embedding.FileMetadataKey != vectorstore.FileMetadataKey {
+		t.Errorf("expect the file metadata key kept after migration, got %+v", *kb.Status.Embedding)
+	}
+
+	// the dimension is got from the embedder if it's not declared
+	kb = newKB()
+	if recorded, err = r.checkEmbedding(ctx, kb, embedder("bge", "bge-large-zh", 0)); err != nil || !recorded {
+		t.Fatalf("expect recorded, got %v %v", recorded, err)
+	}
+	if kb.Status.Embedding.Dimension != 3 || probes != 1 {
+		t.Errorf("expect dimension 3 from 1 probe, got %d from %d probes", kb.Status.Embedding.Dimension, probes)
+	}
+}
+
+func TestCheckFileMetadata(t *testing.T) {
+	dir := t.TempDir()
+	vs := &arcadiav1alpha1.VectorStore{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: "embedded"},
+		Spec:       arcadiav1alpha1.VectorStoreSpec{Embedded: &arcadiav1alpha1.Embedded{Storage: arcadiav1alpha1.EmbeddedStorageLocal, Path: dir}},
+	}
+	collection := filepath.Join(dir, "arcadia_kb.json")
+	if err := os.WriteFile(collection, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r := &KnowledgeBaseReconciler{ReadyMap: make(map[string]bool)}
+	ctx := context.Background()
+	kb := &arcadiav1alpha1.KnowledgeBase{ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: "kb"}}
+	kb.Status.FileGroupDetail = []arcadiav1alpha1.FileGroupDetail{{FileDetails: []arcadiav1alpha1.FileDetails{{Path: "a.txt", Checksum: "1"}}}}
+
+	// nothing to do before the identity is recorded, or the files are recorded
+	for _, embedding := range []*arcadiav1alpha1.EmbeddingIdentity{nil, {CollectionName: "arcadia_kb", FileMetadataKey: vectorstore.FileMetadataKey}} {
+		kb.Status.Embedding = embedding
+		if reset, err := r.checkFileMetadata(ctx, logr.Discard(), kb, vs); err != nil || reset {
+			t.Errorf("expect no reset, got %v %v", reset, err)
+		}
+	}
+	if _, err := os.Stat(collection); err != nil {
+		t.Fatalf("expect the collection kept, got %v", err)
+	}
+
+	// documents without the file metadata are re-embedded into an empty collection
+	kb.Status.Embedding = &arcadiav1alpha1.EmbeddingIdentity{CollectionName: "arcadia_kb"}
+	reset, err := r.checkFileMetadata(ctx, logr.Discard(), kb, vs)
+	if err != nil || !reset {
+		t.Fatalf("expect reset, got %v %v", reset, err)
+	}
+	if _, err = os.Stat(collection); !os.IsNotExist(err) {
+		t.Errorf("expect the collection removed, got %v", err)
+	}
+	if kb.Status.FileGroupDetail != nil || kb.Status.Embedding.FileMetadataKey != vectorstore.FileMetadataKey {
+		t.Errorf("expect all files to be embedded again with the file metadata key, got %+v", kb.Status)
+	}
+	if c := kb.Status.GetCondition(arcadiav1alpha1.TypeReady); c.Reason != "Init" {
+		t.Errorf("expect the init condition, got %+v", c)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/KawashiroNitori/butcher/v2"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// embeddingPool is a running worker pool which embeds the Processing files of a knowledgebase
+type embeddingPool struct {
+	cancel context.CancelFunc
+}
+
+// fileJob is a file to be embedded by the worker pool
+type fileJob struct {
+	group *arcadiav1alpha1.FileGroupDetail
+	file  arcadiav1alpha1.FileDetails
+}
+
+// embeddingExecutor implements butcher.Executor to embed files of a knowledgebase concurrently.
+// The file status is patched once a file is done, so completed files are not embedded again after the controller restarts.
+type embeddingExecutor struct {
+	r           *KnowledgeBaseReconciler
+	log         logr.Logger
+	kb          *arcadiav1alpha1.KnowledgeBase
+	vectorStore *arcadiav1alpha1.VectorStore
+	embedder    *arcadiav1alpha1.Embedder
+	startTime   metav1.Time
+
+	// mu serializes the status updates from different workers
+	mu sync.Mutex
+}
+
+// startEmbedding starts a worker pool to embed the Processing files of this knowledgebase if no pool is running
+func (r *KnowledgeBaseReconciler) startEmbedding(ctx context.Context, log logr.Logger, kb *arcadiav1alpha1.KnowledgeBase, vectorStore *arcadiav1alpha1.VectorStore, embedder *arcadiav1alpha1.Embedder) {
+	key := string(kb.GetUID())
+	if _, ok := r.embeddingPools.Load(key); ok {
+		log.V(5).Info("embedding pool is running, wait for it")
+		return
+	}
+	options := kb.EmbeddingOptions()
+	e := &embeddingExecutor{
+		r:           r,
+		log:         log.WithName("embedding-pool"),
+		kb:          kb.DeepCopy(),
+		vectorStore: vectorStore.DeepCopy(),
+		embedder:    embedder.DeepCopy(),
+		startTime:   metav1.Now(),
+	}
+	runner, err := butcher.NewButcher[fileJob](e, butcher.MaxWorker(options.MaxConcurrentFiles), butcher.BufferSize(options.MaxConcurrentFiles))
+	if err != nil {
+		log.Error(err, "failed to create embedding pool")
+		return
+	}
+	poolCtx, cancel := context.WithCancel(ctx)
+	pool := &embeddingPool{cancel: cancel}
+	r.embeddingPools.Store(key, pool)
+	log.Info("start embedding pool", "maxConcurrentFiles", options.MaxConcurrentFiles)
+	go func() {
+		defer func() {
+			cancel()
+			r.embeddingPools.CompareAndDelete(key, pool)
+		}()
+		if err := runner.Run(poolCtx); err != nil {
+			log.Error(err, "embedding pool exits with error")
+			return
+		}
+		log.Info("embedding pool done")
+	}()
+}
+
+// stopEmbedding stops the running worker pool of this knowledgebase
+func (r *KnowledgeBaseReconciler) stopEmbedding(log logr.Logger, kb *arcadiav1alpha1.KnowledgeBase) {
+	if v, ok := r.embeddingPools.LoadAndDelete(string(kb.GetUID())); ok {
+		log.Info("stop embedding pool")
+		v.(*embeddingPool).cancel()
+	}
+}
+
+func (e *embeddingExecutor) GenerateJob(ctx context.Context, jobCh chan<- fileJob) error {
+	for i := range e.kb.Status.FileGroupDetail {
+		group := &e.kb.Status.FileGroupDetail[i]
+		if group.Source == nil {
+			continue
+		}
+		for _, f := range group.FileDetails {
+			if f.Phase != arcadiav1alpha1.FileProcessPhaseProcessing {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case jobCh <- fileJob{group: group, file: f}:
+			}
+		}
+	}
+	return nil
+}
+
+func (e *embeddingExecutor) Task(ctx context.Context, job fileJob) error {
+	log := e.log.WithValues("source", fmt.Sprintf("%s/%s", job.group.Source.Kind, job.group.Source.Name), "file", job.file.Path)
+	log.Info("start to embed file")
+	if err := e.r.reconcileFileGroup(ctx, log, e.kb, e.vectorStore, e.embedder, job.group, &job.file); err != nil {
+		log.Error(err, "failed to handle single file")
+	}
+	// the result is recorded in the file status, the task itself never fails
+	return e.updateFileDetail(ctx, job)
+}
+
+// updateFileDetail patches the result of a single file into the latest knowledgebase status
+func (e *embeddingExecutor) updateFileDetail(ctx context.Context, job fileJob) error {
+	if ctx.Err() != nil {
+		// the pool is stopped, the file is left as Processing and will be embedded again
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &arcadiav1alpha1.KnowledgeBase{}
+		if err := e.r.Get(ctx, client.ObjectKeyFromObject(e.kb), latest); err != nil {
+			return err
+		}
+		if latest.Status.ObservedGeneration != e.kb.Status.ObservedGeneration {
+			return nil
+		}
+		orig := latest.DeepCopy()
+		found := false
+		for i, fg := range latest.Status.FileGroupDetail {
+			if fg.Source == nil || !sameSource(fg.Source, job.group.Source, latest.Namespace) {
+				continue
+			}
+			for j, f := range fg.FileDetails {
+				if f.Path != job.file.Path || f.Version != job.file.Version || f.Phase != arcadiav1alpha1.FileProcessPhaseProcessing {
+					continue
+				}
+				latest.Status.FileGroupDetail[i].FileDetails[j] = job.file
+				found = true
+			}
+		}
+		if !found {
+			return nil
+		}
+		latest.UpdateProgress(e.startTime)
+		return e.r.Status().Patch(ctx, latest, client.MergeFromWithOptions(orig, client.MergeFromWithOptimisticLock{}), client.FieldOwner("knowledgebase-controller"))
+	})
+	if err != nil {
+		e.log.Error(err, "failed to update file status", "file", job.file.Path)
+	}
+	return nil
+}
+
+func sameSource(a, b *arcadiav1alpha1.TypedObjectReference, defaultNamespace string) bool {
+	return a.Kind == b.Kind && a.Name == b.Name && a.GetNamespace(defaultNamespace) == b.GetNamespace(defaultNamespace)
+}
@@ -0,0 +1,506 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/minio-go/v7"
+	"github.com/robfig/cron/v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/config"
+	"github.com/kubeagi/arcadia/pkg/datasource"
+	"github.com/kubeagi/arcadia/pkg/vectorstore"
+)
+
+const (
+	SourceIndexKey = "metadata.sources"
+)
+
+// sourceLocation is where the files of a source are stored
+type sourceLocation struct {
+	ds     datasource.Datasource
+	bucket string
+	// basePath is the path of files in the bucket, paths in FileGroups are relative to it
+	basePath string
+	// prefix is used to list all objects of the source
+	prefix string
+}
+
+// objectName returns the object name of the file in the bucket
+func (l *sourceLocation) objectName(path string) string {
+	if l.basePath == "" {
+		return path
+	}
+	return filepath.Join(l.basePath, path)
+}
+
+// sourceLocation returns the location of files of the source
+func (r *KnowledgeBaseReconciler) sourceLocation(ctx context.Context, kb *arcadiav1alpha1.KnowledgeBase, source *arcadiav1alpha1.TypedObjectReference) (*sourceLocation, error) {
+	ns := source.GetNamespace(kb.Namespace)
+	loc := &sourceLocation{bucket: ns}
+	var err error
+	switch strings.ToLower(source.Kind) {
+	case "versioneddataset":
+		versionedDataset := &arcadiav1alpha1.VersionedDataset{}
+		if err = r.Get(ctx, types.NamespacedName{Name: source.Name, Namespace: ns}, versionedDataset); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, errNoSource
+			}
+			return nil, err
+		}
+		if versionedDataset.Spec.Dataset == nil {
+			return nil, fmt.Errorf("versionedDataset.Spec.Dataset is nil")
+		}
+		if !versionedDataset.Status.IsReady() {
+			return nil, errDataSourceNotReady
+		}
+		system, err := config.GetSystemDatasource(ctx)
+		if err != nil {
+			return nil, err
+		}
+		endpoint := system.Spec.Endpoint.DeepCopy()
+		if endpoint != nil && endpoint.AuthSecret != nil {
+			endpoint.AuthSecret.WithNameSpace(system.Namespace)
+		}
+		loc.ds, err = datasource.NewLocal(ctx, r.Client, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		// basepath for this versioneddataset
+		loc.basePath = filepath.Join("dataset", versionedDataset.Spec.Dataset.Name, versionedDataset.Spec.Version)
+		loc.prefix = loc.basePath + "/"
+	case "datasource", "":
+		dsObj := &arcadiav1alpha1.Datasource{}
+		if err = r.Get(ctx, types.NamespacedName{Name: source.Name, Namespace: ns}, dsObj); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, errNoSource
+			}
+			return nil, err
+		}
+		if !dsObj.Status.IsReady() {
+			return nil, errDataSourceNotReady
+		}
+		if dsObj.Spec.Type() == arcadiav1alpha1.DatasourceTypeWeb {
+			// pages crawled from web are stored in the namespace bucket of system datasource
+			system, err := config.GetSystemDatasource(ctx)
+			if err != nil {
+				return nil, err
+			}
+			endpoint := system.Spec.Endpoint.DeepCopy()
+			if endpoint != nil && endpoint.AuthSecret != nil {
+				endpoint.AuthSecret.WithNameSpace(system.Namespace)
+			}
+			loc.ds, err = datasource.NewLocal(ctx, r.Client, endpoint)
+			if err != nil {
+				return nil, err
+			}
+			loc.basePath = dsObj.WebCrawlerPath()
+			loc.prefix = loc.basePath + "/"
+			return loc, nil
+		}
+		if dsObj.Spec.Type() == arcadiav1alpha1.DatasourceTypePostgreSQL {
+			// rows of tables are read as files named <table>/<id>.json
+			dsObj = dsObj.DeepCopy()
+			if dsObj.Spec.Endpoint.AuthSecret != nil {
+				dsObj.Spec.Endpoint.AuthSecret.WithNameSpace(dsObj.Namespace)
+			}
+			loc.ds, err = datasource.GetPostgreSQLPool(ctx, r.Client, dsObj)
+			if err != nil {
+				return nil, err
+			}
+			return loc, nil
+		}
+		if dsObj.Spec.Type() == arcadiav1alpha1.DatasourceTypeGit {
+			// files of the git reference, paths in FileGroups are relative to the root of the repository
+			loc.ds, err = datasource.GetGit(ctx, r.Client, dsObj)
+			if err != nil {
+				return nil, err
+			}
+			return loc, nil
+		}
+		// set endpoint's auth secret namespace to current datasource if not set
+		endpoint := dsObj.Spec.Endpoint.DeepCopy()
+		if endpoint != nil && endpoint.AuthSecret != nil {
+			endpoint.AuthSecret.WithNameSpace(dsObj.Namespace)
+		}
+		loc.ds, err = datasource.NewOSS(ctx, r.Client, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		// for none-conversation knowledgebase, bucket is the same as datasource.
+		if kb.Spec.Type != arcadiav1alpha1.KnowledgeBaseTypeConversation && dsObj.Spec.OSS != nil {
+			loc.bucket = dsObj.Spec.OSS.Bucket
+			loc.prefix = dsObj.Spec.OSS.Object
+		}
+	default:
+		return nil, fmt.Errorf("source type %s not supported yet", source.Kind)
+	}
+	return loc, nil
+}
+
+// listSourceFiles lists the objects of the source, returns their etags keyed by the file path used in FileGroups
+func (r *KnowledgeBaseReconciler) listSourceFiles(ctx context.Context, kb *arcadiav1alpha1.KnowledgeBase, source *arcadiav1alpha1.TypedObjectReference) (map[string]string, error) {
+	loc, err := r.sourceLocation(ctx, kb, source)
+	if err != nil {
+		return nil, err
+	}
+	res, err := loc.ds.ListObjects(ctx, loc.bucket, minio.ListObjectsOptions{Prefix: loc.prefix, Recursive: true})
+	if err != nil {
+		return nil, err
+	}
+	objects, ok := res.([]minio.ObjectInfo)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert objects of source %s/%s to []minio.ObjectInfo", source.Kind, source.Name)
+	}
+	trimPrefix := ""
+	if loc.basePath != "" {
+		trimPrefix = loc.basePath + "/"
+	}
+	files := make(map[string]string, len(objects))
+	for _, o := range objects {
+		if o.Err != nil {
+			return nil, o.Err
+		}
+		if strings.HasSuffix(o.Key, "/") {
+			continue
+		}
+		files[strings.TrimPrefix(o.Key, trimPrefix)] = o.ETag
+	}
+	return files, nil
+}
+
+// sourceVersion returns the generations of all sources, which changes once any source is updated.
+// For web datasources the completion time of the last crawl is included, so a finished crawl triggers a sync.
+// Changes of objects in oss buckets and new commits of git repositories don't change it,
+// they are only found when sources are re-listed every Interval.
+func (r *KnowledgeBaseReconciler) sourceVersion(ctx context.Context, kb *arcadiav1alpha1.KnowledgeBase) string {
+	versions := make([]string, 0, len(kb.Spec.FileGroups))
+	for _, fg := range kb.Spec.FileGroups {
+		if fg.Source == nil {
+			continue
+		}
+		key := types.NamespacedName{Name: fg.Source.Name, Namespace: fg.Source.GetNamespace(kb.Namespace)}
+		var obj client.Object = &arcadiav1alpha1.Datasource{}
+		if strings.EqualFold(fg.Source.Kind, "versioneddataset") {
+			obj = &arcadiav1alpha1.VersionedDataset{}
+		}
+		version := "0"
+		if err := r.Get(ctx, key, obj); err == nil {
+			version = strconv.FormatInt(obj.GetGeneration(), 10)
+			if ds, ok := obj.(*arcadiav1alpha1.Datasource); ok && ds.Status.Crawl != nil && ds.Status.Crawl.CompletionTime != nil {
+				version += "@" + ds.Status.Crawl.CompletionTime.UTC().Format(time.RFC3339)
+			}
+		}
+		versions = append(versions, fmt.Sprintf("%s/%s:%s", fg.Source.Kind, key, version))
+	}
+	sort.Strings(versions)
+	return strings.Join(versions, ",")
+}
+
+// reconcileSync syncs files from the sources if a sync is due according to the SyncPolicy.
+// It returns synced=true if the knowledgebase is updated, and how long to wait for the next sync.
+func (r *KnowledgeBaseReconciler) reconcileSync(ctx context.Context, log logr.Logger, kb *arcadiav1alpha1.KnowledgeBase, vectorStore *arcadiav1alpha1.VectorStore) (synced bool, next time.Duration, err error) {
+	policy := kb.Spec.SyncPolicy
+	if policy == nil || kb.IsTypeConversation() {
+		return false, 0, nil
+	}
+	for _, fg := range kb.Status.FileGroupDetail {
+		for _, f := range fg.FileDetails {
+			if f.Phase == arcadiav1alpha1.FileProcessPhasePending || f.Phase == arcadiav1alpha1.FileProcessPhaseProcessing {
+				// wait for the current files to be embedded
+				return false, 0, nil
+			}
+		}
+	}
+
+	now := time.Now()
+	status := kb.Status.Sync
+	if status == nil {
+		status = &arcadiav1alpha1.SyncStatus{}
+	}
+	sourceVersion := r.sourceVersion(ctx, kb)
+	var nextSyncTime *metav1.Time
+	switch policy.Mode {
+	case arcadiav1alpha1.SyncModeCron:
+		schedule, err := cron.ParseStandard(policy.Schedule)
+		if err != nil {
+			log.Error(err, "invalid sync schedule", "schedule", policy.Schedule)
+			return false, 0, nil
+		}
+		last := kb.CreationTimestamp.Time
+		if status.LastSyncTime != nil {
+			last = status.LastSyncTime.Time
+		}
+		if t := schedule.Next(last); now.Before(t) {
+			return false, t.Sub(now), nil
+		}
+		t := metav1.NewTime(schedule.Next(now))
+		nextSyncTime = &t
+	case arcadiav1alpha1.SyncModeWatch:
+		interval := policy.SyncInterval()
+		if status.LastSyncTime != nil && status.ObservedSourceVersion == sourceVersion {
+			if t := status.LastSyncTime.Add(interval); now.Before(t) {
+				return false, t.Sub(now), nil
+			}
+		}
+	default:
+		return false, 0, nil
+	}
+
+	log.Info("start to sync files from sources", "mode", policy.Mode)
+	record := arcadiav1alpha1.SyncRecord{StartTime: metav1.NewTime(now), Trigger: policy.Mode}
+	kbNew := kb.DeepCopy()
+	if err := r.syncFiles(ctx, log, kbNew, vectorStore, &record); err != nil {
+		log.Error(err, "failed to sync files from sources")
+		record.Phase = arcadiav1alpha1.SyncPhaseFailed
+		record.Message = err.Error()
+	} else {
+		record.Phase = arcadiav1alpha1.SyncPhaseSucceeded
+	}
+	record.CompletionTime = metav1.Now()
+	log.Info("sync files from sources done", "phase", record.Phase, "added", record.Added, "changed", record.Changed, "removed", record.Removed)
+
+	// files added or removed by the sync are kept in status, spec is left to the user
+	kbNew.AddSyncRecord(record)
+	kbNew.Status.Sync.NextSyncTime = nextSyncTime
+	kbNew.Status.Sync.ObservedSourceVersion = sourceVersion
+	if record.Added > 0 || record.Changed > 0 || record.Removed > 0 {
+		kbNew = r.setCondition(log, kbNew, kbNew.InitCondition())
+	}
+	if err = r.Status().Patch(ctx, kbNew, client.MergeFrom(kb), client.FieldOwner("knowledgebase-controller")); err != nil {
+		return false, 0, err
+	}
+	return true, 0, nil
+}
+
+// syncFiles re-lists the sources and records the files added to or removed from them in the sync status.
+func (r *KnowledgeBaseReconciler) syncFiles(ctx context.Context, log logr.Logger, kb *arcadiav1alpha1.KnowledgeBase, vectorStore *arcadiav1alpha1.VectorStore, record *arcadiav1alpha1.SyncRecord) error {
+	listed := make(map[string]map[string]string)
+	for _, fg := range kb.Spec.FileGroups {
+		if fg.Source == nil {
+			continue
+		}
+		key := sourceKey(fg.Source, kb.Namespace)
+		if _, ok := listed[key]; ok {
+			continue
+		}
+		files, err := r.listSourceFiles(ctx, kb, fg.Source)
+		if err != nil {
+			return fmt.Errorf("failed to list source %s: %w", key, err)
+		}
+		listed[key] = files
+	}
+	return applySourceFiles(log, kb, listed, record, func(source *arcadiav1alpha1.TypedObjectReference, path string) error {
+		return r.removeFileDocuments(ctx, log, kb, vectorStore, source, path)
+	})
+}
+
+// applySourceFiles updates the files of the knowledgebase to match the listed etags of each source keyed by sourceKey:
+// new objects are added, changed objects are set to Pending to be embedded again,
+// removed objects are removed along with their documents in the vectorstore by removeDocuments.
+// The differences between the listed objects and the files in spec are recorded in Status.Sync.FileGroups, spec is not changed.
+func applySourceFiles(log logr.Logger, kb *arcadiav1alpha1.KnowledgeBase, listed map[string]map[string]string, record *arcadiav1alpha1.SyncRecord, removeDocuments func(source *arcadiav1alpha1.TypedObjectReference, path string) error) error {
+	// changed files are embedded again, the previous documents are removed before that
+	for i, fgd := range kb.Status.FileGroupDetail {
+		if fgd.Source == nil {
+			continue
+		}
+		files := listed[sourceKey(fgd.Source, kb.Namespace)]
+		for j, f := range fgd.FileDetails {
+			etag, ok := files[f.Path]
+			if !ok || f.Version != "" || f.Checksum == "" || f.Checksum == etag {
+				continue
+			}
+			log.V(3).Info("file changed", "source", sourceKey(fgd.Source, kb.Namespace), "file", f.Path)
+			kb.Status.FileGroupDetail[i].FileDetails[j].UpdateErr(nil, arcadiav1alpha1.FileProcessPhasePending)
+			record.Changed++
+		}
+	}
+
+	// the files embedded now and the files in spec of each source
+	sources := make([]*arcadiav1alpha1.TypedObjectReference, 0)
+	current := make(map[string][]arcadiav1alpha1.FileWithVersion)
+	inSpec := make(map[string]map[string]bool)
+	specFiles := make(map[string][]arcadiav1alpha1.FileWithVersion)
+	for _, fg := range kb.FileGroups() {
+		if fg.Source != nil {
+			key := sourceKey(fg.Source, kb.Namespace)
+			current[key] = append(current[key], fg.Files...)
+		}
+	}
+	for _, fg := range kb.Spec.FileGroups {
+		if fg.Source == nil {
+			continue
+		}
+		key := sourceKey(fg.Source, kb.Namespace)
+		if inSpec[key] == nil {
+			inSpec[key] = make(map[string]bool)
+			sources = append(sources, fg.Source)
+		}
+		for _, f := range fg.Files {
+			inSpec[key][f.Path] = true
+		}
+		specFiles[key] = append(specFiles[key], fg.Files...)
+	}
+	previous := make(map[string]arcadiav1alpha1.SyncedFileGroup)
+	if kb.Status.Sync != nil {
+		for _, sg := range kb.Status.Sync.FileGroups {
+			if sg.Source != nil {
+				previous[sourceKey(sg.Source, kb.Namespace)] = sg
+			}
+		}
+	}
+
+	var errs []string
+	var synced []arcadiav1alpha1.SyncedFileGroup
+	for _, source := range sources {
+		key := sourceKey(source, kb.Namespace)
+		files, ok := listed[key]
+		if !ok {
+			// the source is not listed, keep the previous differences
+			if sg, ok := previous[key]; ok {
+				synced = append(synced, sg)
+			}
+			continue
+		}
+		embedded := make(map[string]bool, len(current[key]))
+		kept := make(map[string]bool)
+		added := make(map[string]bool)
+		removed := make(map[string]bool)
+		for _, f := range current[key] {
+			embedded[f.Path] = true
+			if _, ok := files[f.Path]; ok || f.Version != "" {
+				continue
+			}
+			if detail := fileDetail(kb, source, f.Path); detail != nil && detail.Checksum != "" {
+				if err := removeDocuments(source, f.Path); err != nil {
+					// keep the file to remove its documents in the next sync
+					errs = append(errs, fmt.Sprintf("failed to remove documents of %s: %s", f.Path, err))
+					kept[f.Path] = true
+					if !inSpec[key][f.Path] {
+						added[f.Path] = true
+					}
+					continue
+				}
+			}
+			log.V(3).Info("file removed", "source", key, "file", f.Path)
+			record.Removed++
+		}
+		for _, f := range specFiles[key] {
+			if _, ok := files[f.Path]; !ok && f.Version == "" && !kept[f.Path] {
+				removed[f.Path] = true
+			}
+		}
+		for path := range files {
+			if !embedded[path] {
+				log.V(3).Info("file added", "source", key, "file", path)
+				record.Added++
+			}
+			if !inSpec[key][path] {
+				added[path] = true
+			}
+		}
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		synced = append(synced, arcadiav1alpha1.SyncedFileGroup{Source: source.DeepCopy(), Added: sortedPaths(added), Removed: sortedPaths(removed)})
+	}
+	if kb.Status.Sync == nil {
+		kb.Status.Sync = &arcadiav1alpha1.SyncStatus{}
+	}
+	kb.Status.Sync.FileGroups = synced
+	if len(errs) != 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func sortedPaths(paths map[string]bool) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(paths))
+	for p := range paths {
+		res = append(res, p)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func (r *KnowledgeBaseReconciler) removeFileDocuments(ctx context.Context, log logr.Logger, kb *arcadiav1alpha1.KnowledgeBase, vectorStore *arcadiav1alpha1.VectorStore, source *arcadiav1alpha1.TypedObjectReference, path string) error {
+	loc, err := r.sourceLocation(ctx, kb, source)
+	if err != nil {
+		return err
+	}
+	return vectorstore.RemoveFileDocuments(ctx, log, vectorStore, kb.VectorStoreCollectionName(), r.Client, loc.objectName(path))
+}
+
+// mapSourceToKnowledgeBases enqueues the knowledgebases which watch the changed source
+func (r *KnowledgeBaseReconciler) mapSourceToKnowledgeBases(ctx context.Context, kind string) handler.MapFunc {
+	return func(o client.Object) (reqs []reconcile.Request) {
+		var list arcadiav1alpha1.KnowledgeBaseList
+		key := fmt.Sprintf("%s/%s/%s", strings.ToLower(kind), o.GetNamespace(), o.GetName())
+		if err := r.List(ctx, &list, client.MatchingFields{SourceIndexKey: key}); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "failed to list Knowlegebase for source changes")
+			return nil
+		}
+		for _, i := range list.Items {
+			i := i
+			reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&i)})
+		}
+		return reqs
+	}
+}
+
+func sourceKey(source *arcadiav1alpha1.TypedObjectReference, defaultNamespace string) string {
+	kind := strings.ToLower(source.Kind)
+	if kind == "" {
+		kind = "datasource"
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, source.GetNamespace(defaultNamespace), source.Name)
+}
+
+// fileDetail returns the status of the file in the source
+func fileDetail(kb *arcadiav1alpha1.KnowledgeBase, source *arcadiav1alpha1.TypedObjectReference, path string) *arcadiav1alpha1.FileDetails {
+	for i, fgd := range kb.Status.FileGroupDetail {
+		if fgd.Source == nil || !sameSource(fgd.Source, source, kb.Namespace) {
+			continue
+		}
+		for j := range fgd.FileDetails {
+			if fgd.FileDetails[j].Path == path {
+				return &kb.Status.FileGroupDetail[i].FileDetails[j]
+			}
+		}
+	}
+	return nil
+}
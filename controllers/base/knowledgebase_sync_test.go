@@ -0,0 +1,228 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+func TestApplySourceFiles(t *testing.T) {
+	source := &arcadiav1alpha1.TypedObjectReference{Kind: "Datasource", Name: "docs"}
+	key := sourceKey(source, "default")
+	embedded := func(path, checksum string) arcadiav1alpha1.FileDetails {
+		return arcadiav1alpha1.FileDetails{Path: path, Checksum: checksum, Phase: arcadiav1alpha1.FileProcessPhaseSucceeded}
+	}
+
+	tests := []struct {
+		name      string
+		files     []string
+		synced    *arcadiav1alpha1.SyncedFileGroup
+		details   []arcadiav1alpha1.FileDetails
+		listed    map[string]string
+		removeErr error
+
+		expectedFiles   []string
+		expectedPending []string
+		expectedRemoved []string
+		expectedRecord  arcadiav1alpha1.SyncRecord
+		expectedSynced  *arcadiav1alpha1.SyncedFileGroup
+		expectedErr     bool
+	}{
+		{
+			name:           "unchanged",
+			files:          []string{"a.txt", "b.txt"},
+			details:        []arcadiav1alpha1.FileDetails{embedded("a.txt", "1"), embedded("b.txt", "2")},
+			listed:         map[string]string{"a.txt": "1", "b.txt": "2"},
+			expectedFiles:  []string{"a.txt", "b.txt"},
+			expectedRecord: arcadiav1alpha1.SyncRecord{},
+		},
+		{
+			name:           "added",
+			files:          []string{"a.txt"},
+			details:        []arcadiav1alpha1.FileDetails{embedded("a.txt", "1")},
+			listed:         map[string]string{"a.txt": "1", "c.txt": "3", "b.txt": "2"},
+			expectedFiles:  []string{"a.txt", "b.txt", "c.txt"},
+			expectedRecord: arcadiav1alpha1.SyncRecord{Added: 2},
+			expectedSynced: &arcadiav1alpha1.SyncedFileGroup{Added: []string{"b.txt", "c.txt"}},
+		},
+		{
+			name:            "changed",
+			files:           []string{"a.txt", "b.txt"},
+			details:         []arcadiav1alpha1.FileDetails{embedded("a.txt", "1"), embedded("b.txt", "2")},
+			listed:          map[string]string{"a.txt": "1", "b.txt": "22"},
+			expectedFiles:   []string{"a.txt", "b.txt"},
+			expectedPending: []string{"b.txt"},
+			expectedRecord:  arcadiav1alpha1.SyncRecord{Changed: 1},
+		},
+		{
+			name:  "removed",
+			files: []string{"a.txt", "b.txt", "c.txt"},
+			// c.txt is not embedded yet, so there are no documents to remove
+			details:         []arcadiav1alpha1.FileDetails{embedded("a.txt", "1"), embedded("b.txt", "2"), {Path: "c.txt", Phase: arcadiav1alpha1.FileProcessPhaseFailed}},
+			listed:          map[string]string{"a.txt": "1"},
+			expectedFiles:   []string{"a.txt"},
+			expectedRemoved: []string{"b.txt"},
+			expectedRecord:  arcadiav1alpha1.SyncRecord{Removed: 2},
+			expectedSynced:  &arcadiav1alpha1.SyncedFileGroup{Removed: []string{"b.txt", "c.txt"}},
+		},
+		{
+			name:            "failed to remove documents",
+			files:           []string{"a.txt", "b.txt"},
+			details:         []arcadiav1alpha1.FileDetails{embedded("a.txt", "1"), embedded("b.txt", "2")},
+			listed:          map[string]string{"a.txt": "1"},
+			removeErr:       errors.New("vectorstore unavailable"),
+			expectedFiles:   []string{"a.txt", "b.txt"},
+			expectedRemoved: []string{"b.txt"},
+			expectedRecord:  arcadiav1alpha1.SyncRecord{},
+			expectedErr:     true,
+		},
+		{
+			name:           "files with version are kept",
+			files:          []string{"a.txt", "b.txt"},
+			details:        []arcadiav1alpha1.FileDetails{embedded("a.txt", "1"), {Path: "b.txt", Version: "v1", Checksum: "2", Phase: arcadiav1alpha1.FileProcessPhaseSucceeded}},
+			listed:         map[string]string{"a.txt": "1", "b.txt": "22"},
+			expectedFiles:  []string{"a.txt", "b.txt"},
+			expectedRecord: arcadiav1alpha1.SyncRecord{},
+		},
+		{
+			name:            "added, changed, removed and unchanged",
+			files:           []string{"a.txt", "b.txt", "c.txt"},
+			details:         []arcadiav1alpha1.FileDetails{embedded("a.txt", "1"), embedded("b.txt", "2"), embedded("c.txt", "3")},
+			listed:          map[string]string{"a.txt": "1", "b.txt": "22", "d.txt": "4"},
+			expectedFiles:   []string{"a.txt", "b.txt", "d.txt"},
+			expectedPending: []string{"b.txt"},
+			expectedRemoved: []string{"c.txt"},
+			expectedRecord:  arcadiav1alpha1.SyncRecord{Added: 1, Changed: 1, Removed: 1},
+			expectedSynced:  &arcadiav1alpha1.SyncedFileGroup{Added: []string{"d.txt"}, Removed: []string{"c.txt"}},
+		},
+		{
+			name:           "previously synced",
+			files:          []string{"a.txt", "b.txt"},
+			synced:         &arcadiav1alpha1.SyncedFileGroup{Added: []string{"c.txt"}, Removed: []string{"b.txt"}},
+			details:        []arcadiav1alpha1.FileDetails{embedded("a.txt", "1"), embedded("c.txt", "3")},
+			listed:         map[string]string{"a.txt": "1", "c.txt": "3"},
+			expectedFiles:  []string{"a.txt", "c.txt"},
+			expectedRecord: arcadiav1alpha1.SyncRecord{},
+			expectedSynced: &arcadiav1alpha1.SyncedFileGroup{Added: []string{"c.txt"}, Removed: []string{"b.txt"}},
+		},
+		{
+			name:  "files added to spec after the previous sync",
+			files: []string{"a.txt", "c.txt", "d.txt"},
+			// c.txt is added by the user after it was added by the previous sync
+			synced:         &arcadiav1alpha1.SyncedFileGroup{Added: []string{"c.txt"}},
+			details:        []arcadiav1alpha1.FileDetails{embedded("a.txt", "1"), embedded("c.txt", "3")},
+			listed:         map[string]string{"a.txt": "1", "c.txt": "3", "d.txt": "4"},
+			expectedFiles:  []string{"a.txt", "c.txt", "d.txt"},
+			expectedRecord: arcadiav1alpha1.SyncRecord{},
+		},
+		{
+			name:            "previously added files removed from the source",
+			files:           []string{"a.txt"},
+			synced:          &arcadiav1alpha1.SyncedFileGroup{Added: []string{"b.txt", "c.txt"}},
+			details:         []arcadiav1alpha1.FileDetails{embedded("a.txt", "1"), embedded("b.txt", "2"), embedded("c.txt", "3")},
+			listed:          map[string]string{"a.txt": "1", "c.txt": "3"},
+			expectedFiles:   []string{"a.txt", "c.txt"},
+			expectedRemoved: []string{"b.txt"},
+			expectedRecord:  arcadiav1alpha1.SyncRecord{Removed: 1},
+			expectedSynced:  &arcadiav1alpha1.SyncedFileGroup{Added: []string{"c.txt"}},
+		},
+		{
+			name:            "failed to remove documents of previously added files",
+			files:           []string{"a.txt"},
+			synced:          &arcadiav1alpha1.SyncedFileGroup{Added: []string{"b.txt"}},
+			details:         []arcadiav1alpha1.FileDetails{embedded("a.txt", "1"), embedded("b.txt", "2")},
+			listed:          map[string]string{"a.txt": "1"},
+			removeErr:       errors.New("vectorstore unavailable"),
+			expectedFiles:   []string{"a.txt", "b.txt"},
+			expectedRemoved: []string{"b.txt"},
+			expectedRecord:  arcadiav1alpha1.SyncRecord{},
+			expectedSynced:  &arcadiav1alpha1.SyncedFileGroup{Added: []string{"b.txt"}},
+			expectedErr:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kb := &arcadiav1alpha1.KnowledgeBase{ObjectMeta: metav1.ObjectMeta{Name: "kb", Namespace: "default"}}
+			fg := arcadiav1alpha1.FileGroup{Source: source.DeepCopy()}
+			for _, f := range test.files {
+				fg.Files = append(fg.Files, arcadiav1alpha1.FileWithVersion{Path: f})
+			}
+			kb.Spec.FileGroups = []arcadiav1alpha1.FileGroup{fg}
+			kb.Status.FileGroupDetail = []arcadiav1alpha1.FileGroupDetail{{Source: source.DeepCopy(), FileDetails: test.details}}
+			if test.synced != nil {
+				synced := test.synced.DeepCopy()
+				synced.Source = source.DeepCopy()
+				kb.Status.Sync = &arcadiav1alpha1.SyncStatus{FileGroups: []arcadiav1alpha1.SyncedFileGroup{*synced}}
+			}
+			spec := kb.Spec.DeepCopy()
+
+			var removed []string
+			record := arcadiav1alpha1.SyncRecord{}
+			err := applySourceFiles(logr.Discard(), kb, map[string]map[string]string{key: test.listed}, &record, func(s *arcadiav1alpha1.TypedObjectReference, path string) error {
+				if s.Name != source.Name {
+					t.Errorf("unexpected source %s", s.Name)
+				}
+				removed = append(removed, path)
+				return test.removeErr
+			})
+			if (err != nil) != test.expectedErr {
+				t.Fatalf("expected error %t, got %v", test.expectedErr, err)
+			}
+
+			if !reflect.DeepEqual(&kb.Spec, spec) {
+				t.Errorf("spec should not be changed, got %+v", kb.Spec)
+			}
+			var files []string
+			for _, f := range kb.FileGroups()[0].Files {
+				files = append(files, f.Path)
+			}
+			if !reflect.DeepEqual(files, test.expectedFiles) {
+				t.Errorf("expected files %v, got %v", test.expectedFiles, files)
+			}
+			var pending []string
+			for _, f := range kb.Status.FileGroupDetail[0].FileDetails {
+				if f.Phase == arcadiav1alpha1.FileProcessPhasePending {
+					pending = append(pending, f.Path)
+				}
+			}
+			if !reflect.DeepEqual(pending, test.expectedPending) {
+				t.Errorf("expected pending files %v, got %v", test.expectedPending, pending)
+			}
+			if !reflect.DeepEqual(removed, test.expectedRemoved) {
+				t.Errorf("expected documents of %v to be removed, got %v", test.expectedRemoved, removed)
+			}
+			if record != test.expectedRecord {
+				t.Errorf("expected record %+v, got %+v", test.expectedRecord, record)
+			}
+			var expectedSynced []arcadiav1alpha1.SyncedFileGroup
+			if test.expectedSynced != nil {
+				expectedSynced = []arcadiav1alpha1.SyncedFileGroup{*test.expectedSynced}
+				expectedSynced[0].Source = source
+			}
+			if !reflect.DeepEqual(kb.Status.Sync.FileGroups, expectedSynced) {
+				t.Errorf("expected synced file groups %+v, got %+v", expectedSynced, kb.Status.Sync.FileGroups)
+			}
+		})
+	}
+}
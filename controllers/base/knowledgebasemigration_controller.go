@@ -0,0 +1,510 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/KawashiroNitori/butcher/v2"
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/vectorstore"
+)
+
+// KnowledgeBaseMigrationReconciler reconciles a KnowledgeBaseMigration object
+type KnowledgeBaseMigrationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// migrationPools holds the running worker pools which re-embed files, keyed by migration uid
+	migrationPools sync.Map
+}
+
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=knowledgebasemigrations,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=knowledgebasemigrations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=knowledgebasemigrations/finalizers,verbs=update
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=knowledgebases,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=embedders,verbs=get;list;watch
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=vectorstores,verbs=get;list;watch
+
+// Reconcile moves the migration through Pending -> Migrating -> Switched -> Confirmed/RolledBack.
+// Files are re-embedded into a new collection in the background, and the knowledgebase is switched to it
+// only if all files are migrated. The old collection is kept until the migration is confirmed.
+func (r *KnowledgeBaseMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(5).Info("Start KnowledgeBaseMigration Reconcile")
+	m := &arcadiav1alpha1.KnowledgeBaseMigration{}
+	if err := r.Get(ctx, req.NamespacedName, m); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.migrationPools.Range(func(key, value any) bool {
+				if value.(*migrationPool).key == req.NamespacedName {
+					r.stopMigration(log, key.(string))
+				}
+				return true
+			})
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	log = log.WithValues("knowledgebase", m.Spec.KnowledgeBase, "phase", m.Status.Phase)
+
+	if m.GetDeletionTimestamp() != nil && controllerutil.ContainsFinalizer(m, arcadiav1alpha1.Finalizer) {
+		log.Info("Performing Finalizer Operations for KnowledgeBaseMigration before delete CR")
+		r.stopMigration(log, string(m.GetUID()))
+		if m.Status.Phase == arcadiav1alpha1.MigrationPhaseMigrating {
+			// the knowledgebase is not switched yet, the new collection is useless.
+			// a switched migration keeps both collections, as nobody decided which one to remove.
+			r.removeCollection(ctx, log, m.Namespace, m.Status.Target)
+		}
+		controllerutil.RemoveFinalizer(m, arcadiav1alpha1.Finalizer)
+		if err := r.Update(ctx, m); err != nil {
+			log.Error(err, "Failed to remove finalizer for KnowledgeBaseMigration")
+			return ctrl.Result{}, err
+		}
+		log.Info("Remove KnowledgeBaseMigration done")
+		return ctrl.Result{}, nil
+	}
+
+	if newAdded := controllerutil.AddFinalizer(m, arcadiav1alpha1.Finalizer); newAdded {
+		log.Info("Try to add Finalizer for KnowledgeBaseMigration")
+		if err := r.Update(ctx, m); err != nil {
+			log.Error(err, "Failed to update KnowledgeBaseMigration to add finalizer, will try again later")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	switch m.Status.Phase {
+	case "", arcadiav1alpha1.MigrationPhasePending:
+		return r.reconcilePending(ctx, log, m)
+	case arcadiav1alpha1.MigrationPhaseMigrating:
+		return r.reconcileMigrating(ctx, log, m)
+	case arcadiav1alpha1.MigrationPhaseSwitched:
+		return r.reconcileSwitched(ctx, log, m)
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcilePending checks the knowledgebase and the new vectorstore and embedder, then starts to migrate
+func (r *KnowledgeBaseMigrationReconciler) reconcilePending(ctx context.Context, log logr.Logger, m *arcadiav1alpha1.KnowledgeBaseMigration) (ctrl.Result, error) {
+	kb := &arcadiav1alpha1.KnowledgeBase{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: m.Namespace, Name: m.Spec.KnowledgeBase}, kb); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.fail(ctx, m, "knowledgebase is not found")
+		}
+		return ctrl.Result{}, err
+	}
+	if m.Spec.Decision == arcadiav1alpha1.MigrationDecisionRollback {
+		return ctrl.Result{}, r.finish(ctx, m, arcadiav1alpha1.MigrationPhaseRolledBack, "rolled back before migrating")
+	}
+	others := &arcadiav1alpha1.KnowledgeBaseMigrationList{}
+	if err := r.List(ctx, others, client.InNamespace(m.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	for _, other := range others.Items {
+		if other.Name != m.Name && other.Spec.KnowledgeBase == m.Spec.KnowledgeBase &&
+			(other.Status.Phase == arcadiav1alpha1.MigrationPhaseMigrating || other.Status.Phase == arcadiav1alpha1.MigrationPhaseSwitched) {
+			return ctrl.Result{}, r.fail(ctx, m, fmt.Sprintf("migration %s of the knowledgebase is not finished", other.Name))
+		}
+	}
+	target, err := m.TargetOf(kb)
+	if err != nil {
+		return ctrl.Result{}, r.fail(ctx, m, err.Error())
+	}
+	if !kb.Status.IsReady() {
+		return r.pending(ctx, m, "knowledgebase is not ready")
+	}
+	if _, _, err = r.getTarget(ctx, m.Namespace, target); err != nil {
+		return r.pending(ctx, m, err.Error())
+	}
+
+	files, chunks := succeededFiles(kb)
+	mNew := m.DeepCopy()
+	mNew.Status.Source = arcadiav1alpha1.SourceOf(kb)
+	mNew.Status.Target = target
+	mNew.Status.TotalFiles = len(files)
+	mNew.Status.ExpectedChunks = chunks
+	mNew.Status.MigratedFiles, mNew.Status.FailedFiles, mNew.Status.MigratedChunks = 0, 0, 0
+	mNew.Status.Phase = arcadiav1alpha1.MigrationPhaseMigrating
+	mNew.Status.Message = ""
+	mNew.Status.StartTime = &metav1.Time{Time: time.Now()}
+	log.Info("start to migrate", "source", mNew.Status.Source.CollectionName, "target", target.CollectionName, "files", len(files))
+	return ctrl.Result{}, r.Status().Patch(ctx, mNew, client.MergeFrom(m))
+}
+
+// reconcileMigrating keeps the worker pool running until all files are migrated, then switches the knowledgebase
+func (r *KnowledgeBaseMigrationReconciler) reconcileMigrating(ctx context.Context, log logr.Logger, m *arcadiav1alpha1.KnowledgeBaseMigration) (ctrl.Result, error) {
+	if m.Spec.Decision == arcadiav1alpha1.MigrationDecisionRollback {
+		r.stopMigration(log, string(m.GetUID()))
+		r.removeCollection(ctx, log, m.Namespace, m.Status.Target)
+		return ctrl.Result{}, r.finish(ctx, m, arcadiav1alpha1.MigrationPhaseRolledBack, "rolled back before switching")
+	}
+	kb := &arcadiav1alpha1.KnowledgeBase{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: m.Namespace, Name: m.Spec.KnowledgeBase}, kb); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.stopMigration(log, string(m.GetUID()))
+			r.removeCollection(ctx, log, m.Namespace, m.Status.Target)
+			return ctrl.Result{}, r.fail(ctx, m, "knowledgebase is not found")
+		}
+		return ctrl.Result{}, err
+	}
+	if kb.VectorStoreCollectionName() == m.Status.Target.CollectionName {
+		// the knowledgebase has been switched, but the status was not updated
+		return ctrl.Result{}, r.switched(ctx, m)
+	}
+
+	if m.Status.MigratedFiles+m.Status.FailedFiles < m.Status.TotalFiles {
+		if _, ok := r.migrationPools.Load(string(m.GetUID())); ok {
+			log.V(5).Info("migration pool is running, wait for it")
+			return ctrl.Result{RequeueAfter: waitMedium}, nil
+		}
+		// the pool exits with some files left, e.g. the controller restarts, migrate all files again.
+		// documents which already exist in the new collection are skipped by the vectorstore.
+		if m.Status.MigratedFiles+m.Status.FailedFiles > 0 {
+			mNew := m.DeepCopy()
+			mNew.Status.MigratedFiles, mNew.Status.FailedFiles, mNew.Status.MigratedChunks = 0, 0, 0
+			mNew.Status.StartTime = &metav1.Time{Time: time.Now()}
+			return ctrl.Result{}, r.Status().Patch(ctx, mNew, client.MergeFrom(m))
+		}
+		vectorStore, embedder, err := r.getTarget(ctx, m.Namespace, m.Status.Target)
+		if err != nil {
+			log.Info("target is not available, wait for it", "reason", err.Error())
+			return ctrl.Result{RequeueAfter: waitMedium}, nil
+		}
+		r.startMigration(ctx, log, m, kb, vectorStore, embedder)
+		return ctrl.Result{RequeueAfter: waitMedium}, nil
+	}
+
+	if err := m.Status.ValidateCounts(); err != nil {
+		r.removeCollection(ctx, log, m.Namespace, m.Status.Target)
+		return ctrl.Result{}, r.fail(ctx, m, err.Error())
+	}
+	files, chunks := succeededFiles(kb)
+	if len(files) != m.Status.TotalFiles || chunks != m.Status.ExpectedChunks {
+		r.removeCollection(ctx, log, m.Namespace, m.Status.Target)
+		return ctrl.Result{}, r.fail(ctx, m, "files of the knowledgebase are changed during the migration, please retry")
+	}
+	if !kb.Status.IsReady() {
+		log.Info("knowledgebase is not ready, wait to switch")
+		return ctrl.Result{RequeueAfter: waitMedium}, nil
+	}
+
+	log.Info("all files are migrated, switch the knowledgebase", "collection", m.Status.Target.CollectionName)
+	if err := r.updateKnowledgeBase(ctx, m, func(kb *arcadiav1alpha1.KnowledgeBase) {
+		setCollection(kb, m.Status.Target)
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, r.switched(ctx, m)
+}
+
+// reconcileSwitched waits for the decision to remove the old or the new collection
+func (r *KnowledgeBaseMigrationReconciler) reconcileSwitched(ctx context.Context, log logr.Logger, m *arcadiav1alpha1.KnowledgeBaseMigration) (ctrl.Result, error) {
+	switch m.Spec.Decision {
+	case arcadiav1alpha1.MigrationDecisionConfirm:
+		r.removeCollection(ctx, log, m.Namespace, m.Status.Source)
+		return ctrl.Result{}, r.finish(ctx, m, arcadiav1alpha1.MigrationPhaseConfirmed, "the old collection is removed")
+	case arcadiav1alpha1.MigrationDecisionRollback:
+		err := r.updateKnowledgeBase(ctx, m, func(kb *arcadiav1alpha1.KnowledgeBase) {
+			setCollection(kb, m.Status.Source)
+			if changedAfter(kb, m.Status.SwitchTime) {
+				// files changed after switching are only embedded into the new collection, redo embedding
+				if kb.Annotations == nil {
+					kb.Annotations = make(map[string]string)
+				}
+				kb.Annotations[arcadiav1alpha1.UpdateSourceFileAnnotationKey] = time.Now().Format(time.RFC3339)
+			}
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		r.removeCollection(ctx, log, m.Namespace, m.Status.Target)
+		return ctrl.Result{}, r.finish(ctx, m, arcadiav1alpha1.MigrationPhaseRolledBack, "the new collection is removed")
+	}
+	return ctrl.Result{}, nil
+}
+
+// updateKnowledgeBase updates the spec of the knowledgebase in one request, so the vectorstore, embedder and
+// collection are switched atomically
+func (r *KnowledgeBaseMigrationReconciler) updateKnowledgeBase(ctx context.Context, m *arcadiav1alpha1.KnowledgeBaseMigration, mutate func(kb *arcadiav1alpha1.KnowledgeBase)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		kb := &arcadiav1alpha1.KnowledgeBase{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: m.Namespace, Name: m.Spec.KnowledgeBase}, kb); err != nil {
+			return err
+		}
+		mutate(kb)
+		return r.Update(ctx, kb)
+	})
+}
+
+// getTarget returns the vectorstore and embedder of the target if both are ready
+func (r *KnowledgeBaseMigrationReconciler) getTarget(ctx context.Context, namespace string, target *arcadiav1alpha1.MigrationTarget) (*arcadiav1alpha1.VectorStore, *arcadiav1alpha1.Embedder, error) {
+	if target == nil || target.VectorStore == nil || target.Embedder == nil {
+		return nil, nil, errors.New("vectorstore or embedder is not setting")
+	}
+	vectorStore := &arcadiav1alpha1.VectorStore{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: target.VectorStore.GetNamespace(namespace), Name: target.VectorStore.Name}, vectorStore); err != nil {
+		return nil, nil, err
+	}
+	if !vectorStore.Status.IsReady() {
+		return nil, nil, errVectorStoreNotReady
+	}
+	embedder := &arcadiav1alpha1.Embedder{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: target.Embedder.GetNamespace(namespace), Name: target.Embedder.Name}, embedder); err != nil {
+		return nil, nil, err
+	}
+	if !embedder.Status.IsReady() {
+		return nil, nil, errEmbedderNotReady
+	}
+	return vectorStore, embedder, nil
+}
+
+// removeCollection removes the collection in best effort, the migration goes on even if it fails
+func (r *KnowledgeBaseMigrationReconciler) removeCollection(ctx context.Context, log logr.Logger, namespace string, target *arcadiav1alpha1.MigrationTarget) {
+	if target == nil || target.VectorStore == nil || target.CollectionName == "" {
+		return
+	}
+	vectorStore := &arcadiav1alpha1.VectorStore{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: target.VectorStore.GetNamespace(namespace), Name: target.VectorStore.Name}, vectorStore); err != nil {
+		log.Error(err, "get vectorstore error, may leave garbage data", "collection", target.CollectionName)
+		return
+	}
+	if err := vectorstore.RemoveCollection(ctx, log, vectorStore, target.CollectionName, r.Client); err != nil {
+		log.Error(err, "failed to remove collection, may leave garbage data", "collection", target.CollectionName)
+	}
+}
+
+func (r *KnowledgeBaseMigrationReconciler) pending(ctx context.Context, m *arcadiav1alpha1.KnowledgeBaseMigration, msg string) (ctrl.Result, error) {
+	mNew := m.DeepCopy()
+	mNew.Status.Phase = arcadiav1alpha1.MigrationPhasePending
+	mNew.Status.Message = msg
+	return ctrl.Result{RequeueAfter: waitMedium}, r.Status().Patch(ctx, mNew, client.MergeFrom(m))
+}
+
+func (r *KnowledgeBaseMigrationReconciler) switched(ctx context.Context, m *arcadiav1alpha1.KnowledgeBaseMigration) error {
+	mNew := m.DeepCopy()
+	mNew.Status.Phase = arcadiav1alpha1.MigrationPhaseSwitched
+	mNew.Status.Message = "the knowledgebase is switched, set decision to Confirm or Rollback"
+	mNew.Status.SwitchTime = &metav1.Time{Time: time.Now()}
+	return r.Status().Patch(ctx, mNew, client.MergeFrom(m))
+}
+
+func (r *KnowledgeBaseMigrationReconciler) fail(ctx context.Context, m *arcadiav1alpha1.KnowledgeBaseMigration, msg string) error {
+	return r.finish(ctx, m, arcadiav1alpha1.MigrationPhaseFailed, msg)
+}
+
+func (r *KnowledgeBaseMigrationReconciler) finish(ctx context.Context, m *arcadiav1alpha1.KnowledgeBaseMigration, phase arcadiav1alpha1.MigrationPhase, msg string) error {
+	mNew := m.DeepCopy()
+	mNew.Status.Phase = phase
+	mNew.Status.Message = msg
+	mNew.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	return r.Status().Patch(ctx, mNew, client.MergeFrom(m))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KnowledgeBaseMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&arcadiav1alpha1.KnowledgeBaseMigration{}).
+		Complete(r)
+}
+
+// migrationPool is a running worker pool which re-embeds the files of a knowledgebase into the new collection
+type migrationPool struct {
+	key    types.NamespacedName
+	cancel context.CancelFunc
+}
+
+// migrationExecutor implements butcher.Executor to re-embed files concurrently.
+// The embedding is done by the knowledgebase reconciler, with a copy of the knowledgebase pointing to the new collection.
+type migrationExecutor struct {
+	r           *KnowledgeBaseMigrationReconciler
+	kbr         *KnowledgeBaseReconciler
+	log         logr.Logger
+	migration   *arcadiav1alpha1.KnowledgeBaseMigration
+	kb          *arcadiav1alpha1.KnowledgeBase
+	vectorStore *arcadiav1alpha1.VectorStore
+	embedder    *arcadiav1alpha1.Embedder
+
+	// mu protects the counters below, which are patched into the migration status once a file is done
+	mu                                 sync.Mutex
+	migratedFiles, failedFiles, chunks int
+}
+
+// startMigration starts a worker pool to re-embed the files if no pool is running
+func (r *KnowledgeBaseMigrationReconciler) startMigration(ctx context.Context, log logr.Logger, m *arcadiav1alpha1.KnowledgeBaseMigration, kb *arcadiav1alpha1.KnowledgeBase, vectorStore *arcadiav1alpha1.VectorStore, embedder *arcadiav1alpha1.Embedder) {
+	key := string(m.GetUID())
+	if _, ok := r.migrationPools.Load(key); ok {
+		return
+	}
+	kbCopy := kb.DeepCopy()
+	setCollection(kbCopy, m.Status.Target)
+	options := kbCopy.EmbeddingOptions()
+	e := &migrationExecutor{
+		r:           r,
+		kbr:         &KnowledgeBaseReconciler{Client: r.Client, Scheme: r.Scheme},
+		log:         log.WithName("migration-pool"),
+		migration:   m.DeepCopy(),
+		kb:          kbCopy,
+		vectorStore: vectorStore.DeepCopy(),
+		embedder:    embedder.DeepCopy(),
+	}
+	runner, err := butcher.NewButcher[fileJob](e, butcher.MaxWorker(options.MaxConcurrentFiles), butcher.BufferSize(options.MaxConcurrentFiles))
+	if err != nil {
+		log.Error(err, "failed to create migration pool")
+		return
+	}
+	poolCtx, cancel := context.WithCancel(ctx)
+	pool := &migrationPool{key: client.ObjectKeyFromObject(m), cancel: cancel}
+	r.migrationPools.Store(key, pool)
+	log.Info("start migration pool", "maxConcurrentFiles", options.MaxConcurrentFiles)
+	go func() {
+		defer func() {
+			cancel()
+			r.migrationPools.CompareAndDelete(key, pool)
+		}()
+		if err := runner.Run(poolCtx); err != nil {
+			log.Error(err, "migration pool exits with error")
+			return
+		}
+		log.Info("migration pool done")
+	}()
+}
+
+// stopMigration stops the running worker pool of the migration
+func (r *KnowledgeBaseMigrationReconciler) stopMigration(log logr.Logger, key string) {
+	if v, ok := r.migrationPools.LoadAndDelete(key); ok {
+		log.Info("stop migration pool")
+		v.(*migrationPool).cancel()
+	}
+}
+
+func (e *migrationExecutor) GenerateJob(ctx context.Context, jobCh chan<- fileJob) error {
+	files, _ := succeededFiles(e.kb)
+	for _, job := range files {
+		select {
+		case <-ctx.Done():
+			return nil
+		case jobCh <- job:
+		}
+	}
+	return nil
+}
+
+func (e *migrationExecutor) Task(ctx context.Context, job fileJob) error {
+	log := e.log.WithValues("source", fmt.Sprintf("%s/%s", job.group.Source.Kind, job.group.Source.Name), "file", job.file.Path)
+	// a fresh file detail without checksum, so the file is always embedded into the new collection
+	file := arcadiav1alpha1.FileDetails{Path: job.file.Path, Version: job.file.Version}
+	err := e.kbr.reconcileFileGroup(ctx, log, e.kb, e.vectorStore, e.embedder, job.group, &file)
+	if ctx.Err() != nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if migrated(&file, err) {
+		e.migratedFiles++
+		e.chunks += file.Chunks
+	} else {
+		log.Error(err, "failed to migrate file")
+		e.failedFiles++
+	}
+	// the result is recorded in the migration status, the task itself never fails
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &arcadiav1alpha1.KnowledgeBaseMigration{}
+		if err := e.r.Get(ctx, client.ObjectKeyFromObject(e.migration), latest); err != nil {
+			return err
+		}
+		if latest.Status.Phase != arcadiav1alpha1.MigrationPhaseMigrating || !latest.Status.StartTime.Equal(e.migration.Status.StartTime) {
+			return nil
+		}
+		orig := latest.DeepCopy()
+		latest.Status.MigratedFiles, latest.Status.FailedFiles, latest.Status.MigratedChunks = e.migratedFiles, e.failedFiles, e.chunks
+		return e.r.Status().Patch(ctx, latest, client.MergeFromWithOptions(orig, client.MergeFromWithOptimisticLock{}))
+	})
+	if err != nil {
+		log.Error(err, "failed to update migration status")
+	}
+	return nil
+}
+
+// migrated returns whether the file is migrated to the new collection.
+// A skipped file is done without documents, the same as in the knowledgebase, so it is not a failure,
+// and a difference of chunks is still found by the chunk count of the migration.
+func migrated(file *arcadiav1alpha1.FileDetails, err error) bool {
+	switch file.Phase {
+	case arcadiav1alpha1.FileProcessPhaseSkipped:
+		return err == nil || errors.Is(err, errFileSkipped)
+	case arcadiav1alpha1.FileProcessPhaseSucceeded:
+		return err == nil
+	}
+	return false
+}
+
+// succeededFiles returns the embedded files of the knowledgebase to migrate, and the sum of their chunks.
+// Skipped files have no documents in the collection, so they are neither migrated nor counted as failed.
+func succeededFiles(kb *arcadiav1alpha1.KnowledgeBase) (files []fileJob, chunks int) {
+	for i := range kb.Status.FileGroupDetail {
+		group := &kb.Status.FileGroupDetail[i]
+		if group.Source == nil {
+			continue
+		}
+		for _, f := range group.FileDetails {
+			if f.Phase != arcadiav1alpha1.FileProcessPhaseSucceeded {
+				continue
+			}
+			files = append(files, fileJob{group: group, file: f})
+			chunks += f.Chunks
+		}
+	}
+	return files, chunks
+}
+
+// setCollection points the knowledgebase to the vectorstore, embedder and collection of the target
+func setCollection(kb *arcadiav1alpha1.KnowledgeBase, target *arcadiav1alpha1.MigrationTarget) {
+	kb.Spec.VectorStore = target.VectorStore.DeepCopy()
+	kb.Spec.Embedder = target.Embedder.DeepCopy()
+	kb.Spec.CollectionName = ""
+	if target.CollectionName != kb.VectorStoreCollectionName() {
+		kb.Spec.CollectionName = target.CollectionName
+	}
+}
+
+// changedAfter returns true if any file of the knowledgebase is processed after t
+func changedAfter(kb *arcadiav1alpha1.KnowledgeBase, t *metav1.Time) bool {
+	if t == nil {
+		return false
+	}
+	for _, group := range kb.Status.FileGroupDetail {
+		for _, f := range group.FileDetails {
+			if f.LastUpdateTime.After(t.Time) {
+				return true
+			}
+		}
+	}
+	return false
+}
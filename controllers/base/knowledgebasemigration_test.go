@@ -0,0 +1,128 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+func TestSucceededFiles(t *testing.T) {
+	source := &arcadiav1alpha1.TypedObjectReference{Kind: "VersionedDataset", Name: "docs"}
+	file := func(path string, phase arcadiav1alpha1.FileProcessPhase, chunks int) arcadiav1alpha1.FileDetails {
+		return arcadiav1alpha1.FileDetails{Path: path, Phase: phase, Chunks: chunks}
+	}
+	kb := &arcadiav1alpha1.KnowledgeBase{Status: arcadiav1alpha1.KnowledgeBaseStatus{FileGroupDetail: []arcadiav1alpha1.FileGroupDetail{
+		{Source: source, FileDetails: []arcadiav1alpha1.FileDetails{
+			file("a.txt", arcadiav1alpha1.FileProcessPhaseSucceeded, 3),
+			file("b.txt", arcadiav1alpha1.FileProcessPhaseSkipped, 0),
+			file("c.txt", arcadiav1alpha1.FileProcessPhaseFailed, 0),
+			file("d.txt", arcadiav1alpha1.FileProcessPhaseSucceeded, 2),
+		}},
+		{Source: nil, FileDetails: []arcadiav1alpha1.FileDetails{file("e.txt", arcadiav1alpha1.FileProcessPhaseSucceeded, 5)}},
+		{Source: source, FileDetails: []arcadiav1alpha1.FileDetails{file("f.txt", arcadiav1alpha1.FileProcessPhaseSkipped, 0)}},
+	}}}
+	files, chunks := succeededFiles(kb)
+	if len(files) != 2 || files[0].file.Path != "a.txt" || files[1].file.Path != "d.txt" || chunks != 5 {
+		t.Errorf("expect a.txt and d.txt with 5 chunks, got %v %d", files, chunks)
+	}
+	if files[0].group != &kb.Status.FileGroupDetail[0] {
+		t.Error("the job should refer to the file group of the knowledgebase")
+	}
+	if files, chunks = succeededFiles(&arcadiav1alpha1.KnowledgeBase{}); len(files) != 0 || chunks != 0 {
+		t.Errorf("expect no files, got %v %d", files, chunks)
+	}
+}
+
+func TestMigrated(t *testing.T) {
+	tests := []struct {
+		name  string
+		phase arcadiav1alpha1.FileProcessPhase
+		err   error
+		want  bool
+	}{
+		{"succeeded", arcadiav1alpha1.FileProcessPhaseSucceeded, nil, true},
+		{"skipped", arcadiav1alpha1.FileProcessPhaseSkipped, errFileSkipped, true},
+		{"skipped without error", arcadiav1alpha1.FileProcessPhaseSkipped, nil, true},
+		{"skipped with another error", arcadiav1alpha1.FileProcessPhaseSkipped, errors.New("failed"), false},
+		{"failed", arcadiav1alpha1.FileProcessPhaseFailed, errors.New("failed"), false},
+		{"succeeded with error", arcadiav1alpha1.FileProcessPhaseSucceeded, errors.New("failed to update"), false},
+		{"not processed", arcadiav1alpha1.FileProcessPhaseProcessing, nil, false},
+	}
+	for _, test := range tests {
+		if got := migrated(&arcadiav1alpha1.FileDetails{Phase: test.phase}, test.err); got != test.want {
+			t.Errorf("%s: expect %v, got %v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestSetCollection(t *testing.T) {
+	kb := &arcadiav1alpha1.KnowledgeBase{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: "kb"},
+		Spec: arcadiav1alpha1.KnowledgeBaseSpec{
+			VectorStore:    &arcadiav1alpha1.TypedObjectReference{Kind: "VectorStore", Name: "old"},
+			Embedder:       &arcadiav1alpha1.TypedObjectReference{Kind: "Embedder", Name: "old"},
+			CollectionName: "arcadia_kb_old",
+		},
+	}
+	target := &arcadiav1alpha1.MigrationTarget{
+		VectorStore:    &arcadiav1alpha1.TypedObjectReference{Kind: "VectorStore", Name: "new"},
+		Embedder:       &arcadiav1alpha1.TypedObjectReference{Kind: "Embedder", Name: "new"},
+		CollectionName: "arcadia_kb_new",
+	}
+	setCollection(kb, target)
+	if kb.Spec.VectorStore.Name != "new" || kb.Spec.Embedder.Name != "new" || kb.VectorStoreCollectionName() != "arcadia_kb_new" {
+		t.Errorf("expect the knowledgebase pointing to the target, got %+v", kb.Spec)
+	}
+	target.VectorStore.Name = "changed"
+	if kb.Spec.VectorStore.Name != "new" {
+		t.Error("the references should be copied from the target")
+	}
+
+	// switching back to the default collection name clears the collection name
+	setCollection(kb, &arcadiav1alpha1.MigrationTarget{VectorStore: target.VectorStore, Embedder: target.Embedder, CollectionName: "arcadia_kb"})
+	if kb.Spec.CollectionName != "" || kb.VectorStoreCollectionName() != "arcadia_kb" {
+		t.Errorf("expect the default collection name, got %q", kb.Spec.CollectionName)
+	}
+}
+
+func TestChangedAfter(t *testing.T) {
+	now := time.Now()
+	kb := &arcadiav1alpha1.KnowledgeBase{Status: arcadiav1alpha1.KnowledgeBaseStatus{FileGroupDetail: []arcadiav1alpha1.FileGroupDetail{
+		{FileDetails: []arcadiav1alpha1.FileDetails{
+			{Path: "a.txt", LastUpdateTime: metav1.NewTime(now.Add(-time.Hour))},
+			{Path: "b.txt", Phase: arcadiav1alpha1.FileProcessPhaseSkipped, LastUpdateTime: metav1.NewTime(now.Add(-time.Minute))},
+		}},
+	}}}
+	if changedAfter(kb, nil) {
+		t.Error("expect no change without the switch time")
+	}
+	if changedAfter(kb, &metav1.Time{Time: now}) {
+		t.Error("expect no change after the switch time")
+	}
+	if !changedAfter(kb, &metav1.Time{Time: now.Add(-2 * time.Hour)}) {
+		t.Error("expect a change after the switch time")
+	}
+	if !changedAfter(kb, &metav1.Time{Time: now.Add(-10 * time.Minute)}) {
+		t.Error("a skipped file updated after the switch time is also a change")
+	}
+}
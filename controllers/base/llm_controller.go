@@ -40,7 +40,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/langchainwrap"
 	"github.com/kubeagi/arcadia/pkg/llms"
+	"github.com/kubeagi/arcadia/pkg/llms/dashscope"
 	"github.com/kubeagi/arcadia/pkg/llms/openai"
 	"github.com/kubeagi/arcadia/pkg/llms/zhipuai"
 )
@@ -229,6 +231,33 @@ func (r *LLMReconciler) check3rdPartyLLM(ctx context.Context, logger logr.Logger
 			}
 			msg = strings.Join([]string{msg, res}, "\n")
 		}
+	case llms.DashScope:
+		var opts []dashscope.Option
+		if instance.Spec.Endpoint.URL != "" {
+			opts = append(opts, dashscope.WithBaseURL(instance.Spec.Endpoint.URL))
+		}
+		llmClient := dashscope.NewDashScope(apiKey, false, opts...)
+		// validate against models
+		for _, model := range models {
+			res, err := llmClient.Validate(ctx, langchainllms.WithModel(model))
+			if err != nil {
+				return r.UpdateStatus(ctx, instance, nil, err)
+			}
+			msg = strings.Join([]string{msg, res.String()}, "\n")
+		}
+	case llms.OpenAICompatible:
+		// validate against models
+		for _, model := range models {
+			llm, err := langchainwrap.GetLangchainLLM(ctx, instance, r.Client, model)
+			if err != nil {
+				return r.UpdateStatus(ctx, instance, nil, err)
+			}
+			res, err := langchainllms.GenerateFromSinglePrompt(ctx, llm, "Hello", langchainllms.WithMaxTokens(16))
+			if err != nil {
+				return r.UpdateStatus(ctx, instance, nil, err)
+			}
+			msg = strings.Join([]string{msg, res}, "\n")
+		}
 	default:
 		return r.UpdateStatus(ctx, instance, nil, fmt.Errorf("unsupported service type: %s", instance.Spec.Type))
 	}
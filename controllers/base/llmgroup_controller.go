@@ -0,0 +1,172 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/strings/slices"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// LLMGroupReconciler reconciles a LLMGroup object
+type LLMGroupReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=llmgroups,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=llmgroups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=llmgroups/finalizers,verbs=update
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=llms,verbs=get;list;watch
+
+// Reconcile checks the members of the LLMGroup, the group is ready if any of its members is ready.
+func (r *LLMGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("Reconciling LLMGroup resource")
+
+	instance := &arcadiav1alpha1.LLMGroup{}
+	if err := r.Get(ctx, req.NamespacedName, instance); err != nil {
+		// There's no need to requeue if the resource no longer exists.
+		// Otherwise, we'll be requeued implicitly because we return an error.
+		logger.V(1).Info("Failed to get LLMGroup")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if newAdded := controllerutil.AddFinalizer(instance, arcadiav1alpha1.Finalizer); newAdded {
+		logger.Info("Try to add Finalizer for LLMGroup")
+		if err := r.Update(ctx, instance); err != nil {
+			logger.Error(err, "Failed to update LLMGroup to add finalizer, will try again later")
+			return ctrl.Result{}, err
+		}
+		logger.Info("Adding Finalizer for LLMGroup done")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	if instance.GetDeletionTimestamp() != nil && controllerutil.ContainsFinalizer(instance, arcadiav1alpha1.Finalizer) {
+		logger.Info("Removing Finalizer for LLMGroup")
+		controllerutil.RemoveFinalizer(instance, arcadiav1alpha1.Finalizer)
+		if err := r.Update(ctx, instance); err != nil {
+			logger.Error(err, "Failed to remove finalizer for LLMGroup")
+			return ctrl.Result{}, err
+		}
+		logger.Info("Remove LLMGroup done")
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.CheckMembers(ctx, instance); err != nil {
+		logger.Error(err, "Failed to check LLMGroup")
+		return ctrl.Result{RequeueAfter: waitMedium}, err
+	}
+	return ctrl.Result{RequeueAfter: waitLonger}, nil
+}
+
+// CheckMembers checks whether the members exist, are ready and provide the models, then updates the status.
+func (r *LLMGroupReconciler) CheckMembers(ctx context.Context, instance *arcadiav1alpha1.LLMGroup) error {
+	if len(instance.Spec.Members) == 0 {
+		return r.UpdateStatus(ctx, instance, "", errors.New("no members in llm group"))
+	}
+	ready := 0
+	problems := make([]string, 0)
+	for _, m := range instance.Spec.Members {
+		name := instance.MemberName(m)
+		llm := &arcadiav1alpha1.LLM{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: m.LLM.GetNamespace(instance.Namespace), Name: m.LLM.Name}, llm); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", name, err))
+			continue
+		}
+		if isReady, msg := llm.Status.IsReadyOrGetReadyMessage(); !isReady {
+			problems = append(problems, fmt.Sprintf("%s: not ready%s", name, msg))
+			continue
+		}
+		if models := llm.GetModelList(); m.Model != "" && len(models) != 0 && !slices.Contains(models, m.Model) {
+			problems = append(problems, fmt.Sprintf("%s: model not provided, available models: %v", name, models))
+			continue
+		}
+		ready++
+	}
+	if ready == 0 {
+		return r.UpdateStatus(ctx, instance, "", fmt.Errorf("no member is ready: %s", strings.Join(problems, "; ")))
+	}
+	msg := fmt.Sprintf("%d/%d members are ready", ready, len(instance.Spec.Members))
+	if len(problems) > 0 {
+		msg = fmt.Sprintf("%s, %s", msg, strings.Join(problems, "; "))
+	}
+	return r.UpdateStatus(ctx, instance, msg, nil)
+}
+
+func (r *LLMGroupReconciler) UpdateStatus(ctx context.Context, instance *arcadiav1alpha1.LLMGroup, msg string, err error) error {
+	instanceCopy := instance.DeepCopy()
+	var newCondition arcadiav1alpha1.Condition
+	if err != nil {
+		newCondition = instance.ErrorCondition(err.Error())
+	} else {
+		newCondition = instance.ReadyCondition(msg)
+	}
+	instanceCopy.Status.SetConditions(newCondition)
+	return errors.Join(err, r.Client.Status().Update(ctx, instanceCopy))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LLMGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&arcadiav1alpha1.LLMGroup{}, builder.WithPredicates(predicate.Funcs{
+			UpdateFunc: func(ue event.UpdateEvent) bool {
+				// Avoid to handle the event that it's not spec update or delete
+				oldGroup := ue.ObjectOld.(*arcadiav1alpha1.LLMGroup)
+				newGroup := ue.ObjectNew.(*arcadiav1alpha1.LLMGroup)
+				return !reflect.DeepEqual(oldGroup.Spec, newGroup.Spec) || newGroup.DeletionTimestamp != nil
+			},
+		})).
+		Watches(&source.Kind{Type: &arcadiav1alpha1.LLM{}},
+			handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+				// recheck the groups which have this llm as a member
+				groups := &arcadiav1alpha1.LLMGroupList{}
+				if err := r.List(context.TODO(), groups); err != nil {
+					return nil
+				}
+				reqs := make([]reconcile.Request, 0)
+				for i := range groups.Items {
+					group := &groups.Items[i]
+					for _, m := range group.Spec.Members {
+						if m.LLM.Name == o.GetName() && m.LLM.GetNamespace(group.Namespace) == o.GetNamespace() {
+							reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(group)})
+							break
+						}
+					}
+				}
+				return reqs
+			})).
+		Complete(r)
+}
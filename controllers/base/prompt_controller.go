@@ -140,6 +140,8 @@ func (r *PromptReconciler) CallLLM(ctx context.Context, logger logr.Logger, prom
 		if err != nil {
 			return r.UpdateStatus(ctx, prompt, nil, err)
 		}
+	case llms.DashScope:
+		return r.UpdateStatus(ctx, prompt, nil, errors.New("not implemented yet"))
 	case llms.Gemini:
 		return r.UpdateStatus(ctx, prompt, nil, errors.New("not implemented yet"))
 	default:
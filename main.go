@@ -40,6 +40,7 @@ import (
 	agentv1alpha1 "github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
 	apichain "github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
 	documentloaderv1alpha1 "github.com/kubeagi/arcadia/api/app-node/documentloader/v1alpha1"
+	guardrailv1alpha1 "github.com/kubeagi/arcadia/api/app-node/guardrail/v1alpha1"
 	apiprompt "github.com/kubeagi/arcadia/api/app-node/prompt/v1alpha1"
 	apiretriever "github.com/kubeagi/arcadia/api/app-node/retriever/v1alpha1"
 	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
@@ -75,6 +76,7 @@ func init() {
 	utilruntime.Must(agentv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(rbacv1.AddToScheme(scheme))
 	utilruntime.Must(documentloaderv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(guardrailv1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -163,6 +165,13 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "LLM")
 		os.Exit(1)
 	}
+	if err = (&basecontrollers.LLMGroupReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LLMGroup")
+		os.Exit(1)
+	}
 	// Deprecated: will remove later, use promptcontrollers.PromptReconciler and construct a application
 	if err = (&basecontrollers.PromptReconciler{
 		Client: mgr.GetClient(),
@@ -222,6 +231,13 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "KnowledgeBase")
 		os.Exit(1)
 	}
+	if err = (&basecontrollers.KnowledgeBaseMigrationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KnowledgeBaseMigration")
+		os.Exit(1)
+	}
 	if err = (&basecontrollers.VectorStoreReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -298,6 +314,10 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Prompt")
 			os.Exit(1)
 		}
+		if err = (&arcadiav1alpha1.KnowledgeBase{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KnowledgeBase")
+			os.Exit(1)
+		}
 	}
 	if err = (&evaluationcontrollers.RAGReconciler{
 		Client: mgr.GetClient(),
@@ -37,6 +37,11 @@ import (
 	"github.com/kubeagi/arcadia/pkg/appruntime/tools"
 )
 
+// functionCaller is implemented by the llm nodes which know whether the model supports native function calling
+type functionCaller interface {
+	SupportsFunctionCalling() bool
+}
+
 type Executor struct {
 	base.BaseNode
 }
@@ -60,7 +65,10 @@ func (p *Executor) Run(ctx context.Context, cli client.Client, args map[string]a
 	if err := cli.Get(ctx, types.NamespacedName{Namespace: p.RefNamespace(), Name: p.Ref.Name}, instance); err != nil {
 		return args, fmt.Errorf("can't find the agent in cluster: %w", err)
 	}
-	allowedTools := tools.InitTools(ctx, instance.Spec.AllowedTools)
+	allowedTools, err := tools.InitTools(ctx, instance.Spec.AllowedTools)
+	if err != nil {
+		return args, fmt.Errorf("failed to init tools of the agent: %w", err)
+	}
 
 	var history langchaingoschema.ChatMessageHistory
 	if v3, ok := args[base.LangchaingoChatMessageHistoryKeyInArg]; ok && v3 != nil {
@@ -82,17 +90,25 @@ func (p *Executor) Run(ctx context.Context, cli client.Client, args map[string]a
 		}
 		agents.WithMemory(chain.GetMemory(llm, instance.Spec.AgentConfig.Options.Memory, history, "input", ""))(o)
 	}
-	executor, err := agents.Initialize(llm, allowedTools, agents.ConversationalReactDescription, executorOptions)
-	if err != nil {
-		return args, fmt.Errorf("failed to initialize executor: %w", err)
-	}
-	executor.CallbacksHandler = log.KLogHandler{LogLevel: 3}
 	input := make(map[string]any)
-	if instance.Spec.Prompt != "" {
-		input["input"] = fmt.Sprintf("%s, %s", instance.Spec.Prompt, args["question"])
-	} else {
+	var executor agents.Executor
+	if fc, ok := llm.(functionCaller); ok && fc.SupportsFunctionCalling() && len(allowedTools) > 0 {
+		// use native function calling when the llm supports it, the prompt goes to the system message
+		klog.FromContext(ctx).V(3).Info("use native function calling agent")
+		executor = agents.NewExecutor(NewFunctionCallingAgent(llm, allowedTools, instance.Spec.Prompt), allowedTools, executorOptions)
 		input["input"] = args["question"]
+	} else {
+		executor, err = agents.Initialize(llm, allowedTools, agents.ConversationalReactDescription, executorOptions)
+		if err != nil {
+			return args, fmt.Errorf("failed to initialize executor: %w", err)
+		}
+		if instance.Spec.Prompt != "" {
+			input["input"] = fmt.Sprintf("%s, %s", instance.Spec.Prompt, args["question"])
+		} else {
+			input["input"] = args["question"]
+		}
 	}
+	executor.CallbacksHandler = log.KLogHandler{LogLevel: 3}
 	// chains.Call will add history to args
 	response, err := chains.Call(ctx, executor, input)
 	if err != nil {
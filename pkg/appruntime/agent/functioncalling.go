@@ -0,0 +1,142 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/agents"
+	"github.com/tmc/langchaingo/llms"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	langchaingotools "github.com/tmc/langchaingo/tools"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/tools"
+	arcadiallms "github.com/kubeagi/arcadia/pkg/llms"
+)
+
+const (
+	functionCallingSystemPrompt = "You are a helpful assistant. Call the functions when they help to answer the question, and answer the question directly when they don't."
+	functionCallingInputKey     = "input"
+	functionCallingHistoryKey   = "history"
+	functionCallingOutputKey    = "output"
+	// functionCallingLogFormat is the log of actions, which keeps the function name and arguments of the call
+	functionCallingLogFormat = "Invoking function %s with %s"
+)
+
+// FunctionCallingAgent is an agent which chooses tools by the native function calling of llms,
+// instead of parsing the text output like the ReAct agents.
+type FunctionCallingAgent struct {
+	LLM   llms.Model
+	Tools []langchaingotools.Tool
+	// Prompt is the instruction of the agent, added to the system message
+	Prompt string
+
+	functions []llms.FunctionDefinition
+	// functionToTool maps function names back to tool names
+	functionToTool map[string]string
+}
+
+var _ agents.Agent = (*FunctionCallingAgent)(nil)
+
+func NewFunctionCallingAgent(llm llms.Model, ts []langchaingotools.Tool, prompt string) *FunctionCallingAgent {
+	functionToTool := make(map[string]string, len(ts))
+	for _, t := range ts {
+		functionToTool[tools.FunctionName(t.Name())] = t.Name()
+	}
+	return &FunctionCallingAgent{
+		LLM:            llm,
+		Tools:          ts,
+		Prompt:         prompt,
+		functions:      tools.FunctionDefinitions(ts),
+		functionToTool: functionToTool,
+	}
+}
+
+// Plan calls the llm with the function definitions, and returns an action if the llm calls a function
+func (a *FunctionCallingAgent) Plan(ctx context.Context, intermediateSteps []langchaingoschema.AgentStep, inputs map[string]string) ([]langchaingoschema.AgentAction, *langchaingoschema.AgentFinish, error) {
+	resp, err := a.LLM.GenerateContent(ctx, a.messages(intermediateSteps, inputs),
+		llms.WithFunctions(a.functions), llms.WithFunctionCallBehavior(llms.FunctionCallBehaviorAuto))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, nil, errors.New("no choices in the response")
+	}
+	choice := resp.Choices[0]
+	if choice.FuncCall == nil {
+		return nil, &langchaingoschema.AgentFinish{
+			ReturnValues: map[string]any{functionCallingOutputKey: choice.Content},
+			Log:          choice.Content,
+		}, nil
+	}
+	toolName, ok := a.functionToTool[choice.FuncCall.Name]
+	if !ok {
+		// let the executor report the unknown tool to the llm
+		toolName = choice.FuncCall.Name
+	}
+	return []langchaingoschema.AgentAction{{
+		Tool:      toolName,
+		ToolInput: tools.ToolInput(toolName, choice.FuncCall.Arguments),
+		Log:       fmt.Sprintf(functionCallingLogFormat, choice.FuncCall.Name, choice.FuncCall.Arguments),
+	}}, nil, nil
+}
+
+// messages builds the messages of the conversation.
+// Function calls are sent as ai messages, and their results are sent as function messages.
+func (a *FunctionCallingAgent) messages(intermediateSteps []langchaingoschema.AgentStep, inputs map[string]string) []llms.MessageContent {
+	system := functionCallingSystemPrompt
+	if a.Prompt != "" {
+		system = fmt.Sprintf("%s\n%s", system, a.Prompt)
+	}
+	if history := strings.TrimSpace(inputs[functionCallingHistoryKey]); history != "" {
+		system = fmt.Sprintf("%s\n\nThe previous conversation:\n%s", system, history)
+	}
+	messages := []llms.MessageContent{
+		llms.TextParts(langchaingoschema.ChatMessageTypeSystem, system),
+		llms.TextParts(langchaingoschema.ChatMessageTypeHuman, inputs[functionCallingInputKey]),
+	}
+	for _, step := range intermediateSteps {
+		name, arguments := functionCall(step.Action)
+		messages = append(messages,
+			arcadiallms.FunctionCallMessage(name, arguments),
+			arcadiallms.FunctionResultMessage(name, step.Observation),
+		)
+	}
+	return messages
+}
+
+// functionCall returns the function name and arguments of the call from the log of the action
+func functionCall(action langchaingoschema.AgentAction) (name, arguments string) {
+	name = tools.FunctionName(action.Tool)
+	if args, ok := strings.CutPrefix(action.Log, fmt.Sprintf(functionCallingLogFormat, name, "")); ok {
+		return name, args
+	}
+	input, _ := json.Marshal(map[string]string{"input": action.ToolInput})
+	return name, string(input)
+}
+
+func (a *FunctionCallingAgent) GetInputKeys() []string {
+	return []string{functionCallingInputKey}
+}
+
+func (a *FunctionCallingAgent) GetOutputKeys() []string {
+	return []string{functionCallingOutputKey}
+}
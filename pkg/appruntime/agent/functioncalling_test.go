@@ -0,0 +1,80 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	langchaingotools "github.com/tmc/langchaingo/tools"
+
+	arcadiallms "github.com/kubeagi/arcadia/pkg/llms"
+)
+
+// fakeLLM calls the calculator first, then answers with the result
+type fakeLLM struct {
+	messages []llms.MessageContent
+}
+
+func (f *fakeLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+func (f *fakeLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	f.messages = messages
+	if len(messages) == 2 {
+		return &llms.ContentResponse{Choices: []*llms.ContentChoice{{
+			FuncCall: &langchaingoschema.FunctionCall{Name: "calculator", Arguments: `{"expression":"4+5"}`},
+		}}}, nil
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: "The answer is 9."}}}, nil
+}
+
+func TestFunctionCallingAgent(t *testing.T) {
+	llm := &fakeLLM{}
+	agent := NewFunctionCallingAgent(llm, []langchaingotools.Tool{langchaingotools.Calculator{}}, "")
+	inputs := map[string]string{functionCallingInputKey: "what is 4+5?"}
+	actions, finish, err := agent.Plan(context.Background(), nil, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finish != nil || len(actions) != 1 || actions[0].Tool != "calculator" || actions[0].ToolInput != "4+5" {
+		t.Fatalf("unexpected actions %+v, finish %+v", actions, finish)
+	}
+
+	steps := []langchaingoschema.AgentStep{{Action: actions[0], Observation: "9"}}
+	_, finish, err = agent.Plan(context.Background(), steps, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finish == nil || finish.ReturnValues[functionCallingOutputKey] != "The answer is 9." {
+		t.Fatalf("unexpected finish %+v", finish)
+	}
+	if len(llm.messages) != 4 {
+		t.Fatalf("expect 4 messages, got %d", len(llm.messages))
+	}
+	call, ok := llm.messages[2].Parts[0].(arcadiallms.FunctionCallPart)
+	if llm.messages[2].Role != langchaingoschema.ChatMessageTypeAI || !ok || call.Name != "calculator" || call.Arguments != `{"expression":"4+5"}` {
+		t.Errorf("unexpected function call message %+v", llm.messages[2])
+	}
+	result, ok := llm.messages[3].Parts[0].(arcadiallms.FunctionResultPart)
+	if llm.messages[3].Role != langchaingoschema.ChatMessageTypeFunction || !ok || result.Name != "calculator" || result.Text != "9" {
+		t.Errorf("unexpected function result message %+v", llm.messages[3])
+	}
+}
@@ -34,10 +34,12 @@ import (
 	"github.com/kubeagi/arcadia/pkg/appruntime/base"
 	"github.com/kubeagi/arcadia/pkg/appruntime/chain"
 	"github.com/kubeagi/arcadia/pkg/appruntime/documentloader"
+	"github.com/kubeagi/arcadia/pkg/appruntime/guardrail"
 	"github.com/kubeagi/arcadia/pkg/appruntime/knowledgebase"
 	"github.com/kubeagi/arcadia/pkg/appruntime/llm"
 	"github.com/kubeagi/arcadia/pkg/appruntime/prompt"
 	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+	"github.com/kubeagi/arcadia/pkg/responsecache"
 )
 
 type Input struct {
@@ -46,24 +48,40 @@ type Input struct {
 	// Files from user upload
 	// normally, the user will upload the files to s3 first and use the name of files here
 	Files []string
+	// Images in the question, sent to the llms which accept images or described as text for the others
+	Images []base.Image
 	// overrideConfig
 	NeedStream     bool
 	History        langchaingoschema.ChatMessageHistory
 	ConversationID string
+	// NoCache skips the response cache of answers and llm responses, e.g. to regenerate an answer
+	NoCache bool
 }
 type Output struct {
 	Answer     string
 	References []retriever.Reference
+	// DocNull is true if the retrievers find nothing and the DocNullReturn of the application is the answer
+	DocNull bool
+	// Blocked is true if the chat is blocked by the guardrails and the block message is the answer
+	Blocked bool
+	// Violations found by the guardrails
+	Violations []guardrail.Violation
 }
 
 type Application struct {
 	Namespace     string
 	Name          string
+	Generation    int64
 	Spec          arcadiav1alpha1.ApplicationSpec
 	Inited        bool
 	Nodes         map[string]base.Node
 	StartingNodes []base.Node
 	EndingNode    base.Node
+
+	// cache is the response cache, nil if it is not enabled
+	cache *responsecache.Cache
+	// captioner describes the images for the llms which only accept text, nil if image caption is not set
+	captioner *imageCaptioner
 }
 
 func NewAppOrGetFromCache(ctx context.Context, cli client.Client, app *arcadiav1alpha1.Application) (*Application, error) {
@@ -71,10 +89,11 @@ func NewAppOrGetFromCache(ctx context.Context, cli client.Client, app *arcadiav1
 		return nil, errors.New("app has no name or namespace")
 	}
 	a := &Application{
-		Namespace: app.GetNamespace(),
-		Name:      app.Name,
-		Spec:      app.Spec,
-		Inited:    false,
+		Namespace:  app.GetNamespace(),
+		Name:       app.Name,
+		Generation: app.Generation,
+		Spec:       app.Spec,
+		Inited:     false,
 	}
 	return a, a.Init(ctx, cli)
 }
@@ -135,11 +154,39 @@ func (a *Application) Init(ctx context.Context, cli client.Client) (err error) {
 			a.StartingNodes = append(a.StartingNodes, current)
 		}
 	}
+	a.StartingNodes = inputGuardrailsFirst(a.StartingNodes, inputNodeName)
+	if a.Spec.ResponseCache != nil {
+		if err := a.initCache(ctx, cli); err != nil {
+			return fmt.Errorf("init response cache failed: %w", err)
+		}
+	}
+	if a.Spec.ImageCaption != nil {
+		if err := a.initImageCaption(ctx, cli); err != nil {
+			return fmt.Errorf("init image caption failed: %w", err)
+		}
+	}
 	klog.FromContext(ctx).V(5).Info(fmt.Sprintf("init application success starting nodes: %#v\n", a.StartingNodes))
 	return nil
 }
 
 func (a *Application) Run(ctx context.Context, cli client.Client, respStream chan string, input Input) (output Output, err error) {
+	if input.NoCache {
+		ctx = responsecache.WithoutCache(ctx)
+	}
+	useCache := a.cache != nil && !input.NoCache && cacheable(ctx, input)
+	if useCache {
+		if v, ok := a.cache.GetAnswer(ctx, input.Question); ok {
+			if cached, ok := v.(Output); ok {
+				klog.FromContext(ctx).V(3).Info("hit response cache", "question", input.Question)
+				if input.NeedStream && respStream != nil {
+					go func() {
+						respStream <- cached.Answer
+					}()
+				}
+				return cached, nil
+			}
+		}
+	}
 	out := map[string]any{
 		base.InputQuestionKeyInArg:                 input.Question,
 		"files":                                    input.Files,
@@ -153,6 +200,18 @@ func (a *Application) Run(ctx context.Context, cli client.Client, respStream cha
 	if a.Spec.DocNullReturn != "" {
 		out[base.APPDocNullReturn] = a.Spec.DocNullReturn
 	}
+	if len(input.Images) > 0 {
+		if a.acceptsImages() {
+			out[base.InputImagesKeyInArg] = input.Images
+		} else {
+			out[base.InputQuestionKeyInArg] = a.withImageDescriptions(ctx, input.Question, input.Images)
+		}
+	}
+	// the chains don't stream the answer if it is held by the guardrails, the checked answer is sent at last
+	holdAnswer := a.holdsAnswer()
+	if holdAnswer {
+		out[base.InputIsNeedStreamKeyInArg] = false
+	}
 	visited := make(map[string]bool)
 	waitRunningNodes := list.New()
 	for _, v := range a.StartingNodes {
@@ -180,7 +239,15 @@ func (a *Application) Run(ctx context.Context, cli client.Client, respStream cha
 			defer e.Cleanup()
 			if out, err = e.Run(ctx, cli, out); err != nil {
 				var er *base.RetrieverGetNullDocError
-				if errors.As(err, &er) {
+				var blocked *base.GuardrailBlockedError
+				if errors.As(err, &blocked) {
+					if input.NeedStream && respStream != nil {
+						go func() {
+							respStream <- blocked.Msg
+						}()
+					}
+					return Output{Answer: blocked.Msg, Blocked: true, Violations: guardrail.GetViolationsFromArg(out)}, nil
+				} else if errors.As(err, &er) {
 					agentReturnNothing := true
 					v, ok := out[base.OutputAnswerKeyInArg]
 					if ok {
@@ -194,7 +261,7 @@ func (a *Application) Run(ctx context.Context, cli client.Client, respStream cha
 								respStream <- er.Msg
 							}()
 						}
-						return Output{Answer: er.Msg}, nil
+						return Output{Answer: er.Msg, DocNull: true}, nil
 					}
 				} else {
 					return Output{}, fmt.Errorf("run node %s: %w", e.Name(), err)
@@ -208,9 +275,10 @@ func (a *Application) Run(ctx context.Context, cli client.Client, respStream cha
 	}
 	if a, ok := out[base.OutputAnswerKeyInArg]; ok {
 		if answer, ok := a.(string); ok && len(answer) > 0 {
-			output = Output{Answer: answer}
+			output = Output{Answer: guardrail.Restore(out, answer)}
 		}
 	}
+	output.Violations = guardrail.GetViolationsFromArg(out)
 	if a, ok := out[base.RuntimeRetrieverReferencesKeyInArg]; ok {
 		if references, ok := a.([]retriever.Reference); ok && len(references) > 0 {
 			output.References = references
@@ -219,6 +287,15 @@ func (a *Application) Run(ctx context.Context, cli client.Client, respStream cha
 	if output.Answer == "" && respStream == nil {
 		return Output{}, errors.New("no answer")
 	}
+	if holdAnswer && input.NeedStream && respStream != nil && output.Answer != "" {
+		go func() {
+			respStream <- output.Answer
+		}()
+	}
+	// the questions with violations are always checked by the guardrails
+	if useCache && output.Answer != "" && len(output.Violations) == 0 {
+		a.cache.SetAnswer(ctx, input.Question, output)
+	}
 	return output, nil
 }
 
@@ -268,6 +345,9 @@ func InitNode(ctx context.Context, appNamespace, name string, ref arcadiav1alpha
 		case "llm":
 			logger.V(3).Info("initnode llm")
 			return llm.NewLLM(baseNode), nil
+		case "llmgroup":
+			logger.V(3).Info("initnode llmgroup")
+			return llm.NewLLM(baseNode), nil
 		case "input":
 			return base.NewInput(baseNode), nil
 		case "output":
@@ -281,6 +361,9 @@ func InitNode(ctx context.Context, appNamespace, name string, ref arcadiav1alpha
 		case "documentloader":
 			logger.V(3).Info("initnode agent - documentloader")
 			return documentloader.NewDocumentLoader(baseNode), nil
+		case "guardrail":
+			logger.V(3).Info("initnode guardrail")
+			return guardrail.NewGuardrail(baseNode), nil
 		default:
 			return nil, err
 		}
@@ -297,6 +380,31 @@ func InitNode(ctx context.Context, appNamespace, name string, ref arcadiav1alpha
 	}
 }
 
+// inputGuardrailsFirst moves the input guardrails after the input node right after it,
+// so they check the question before the other starting nodes like retrievers get it
+func inputGuardrailsFirst(nodes []base.Node, inputNodeName string) []base.Node {
+	if len(nodes) == 0 || nodes[0].Name() != inputNodeName {
+		return nodes
+	}
+	res := []base.Node{nodes[0]}
+	for _, next := range nodes[0].GetNextNode() {
+		if g, ok := next.(*guardrail.Guardrail); ok && g.IsInput() {
+			res = append(res, next)
+		}
+	}
+	return append(res, nodes[1:]...)
+}
+
+// holdsAnswer returns whether any guardrail of the application may change the answer
+func (a *Application) holdsAnswer() bool {
+	for _, n := range a.Nodes {
+		if g, ok := n.(*guardrail.Guardrail); ok && g.HoldsAnswer() {
+			return true
+		}
+	}
+	return false
+}
+
 // FindNodesHas group means ref.APIGroup files before `arcadia.kubeagi.k8s.com.cn`
 func FindNodesHas(app *arcadiav1alpha1.Application, group, kind string) (has bool, namespace, name string) {
 	group, kind = strings.ToLower(group), strings.ToLower(kind)
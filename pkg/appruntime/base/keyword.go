@@ -37,6 +37,9 @@ const (
 	APPDocNullReturn                      = "_app_doc_null_return"
 	ConversationKnowledgeBaseInArg        = "_conversation_knowledgebase" // the conversation Knowledgebase cr in args, status has ready
 	ConversationIDInArg                   = "_conversation_id"
+	InputImagesKeyInArg                   = "_images"               // the images in the question, only set when the llms accept images
+	GuardrailViolationsInArg              = "_guardrail_violations" // the violations found by the guardrail nodes
+	GuardrailPIIMaskInArg                 = "_guardrail_pii_mask"   // the personal information masked in the question, restored in the answer
 )
 
 var (
@@ -56,6 +59,18 @@ func GetInputQuestionFromArg(args map[string]any) (string, error) {
 	return query, nil
 }
 
+// Image is an image in the question
+type Image struct {
+	MIMEType string
+	Data     []byte
+}
+
+// GetInputImagesFromArg returns the images in the question, nil if there is no image
+func GetInputImagesFromArg(args map[string]any) []Image {
+	images, _ := args[InputImagesKeyInArg].([]Image)
+	return images
+}
+
 func GetRetrieversFromArg(args map[string]any) ([]langchainschema.Retriever, error) {
 	v, ok := args[LangchaingoRetrieversKeyInArg]
 	if !ok {
@@ -127,3 +127,10 @@ type RetrieverGetNullDocError struct {
 }
 
 func (e *RetrieverGetNullDocError) Error() string { return e.Msg }
+
+// GuardrailBlockedError is returned by the guardrail nodes when the chat is blocked, Msg is the answer
+type GuardrailBlockedError struct {
+	Msg string
+}
+
+func (e *GuardrailBlockedError) Error() string { return e.Msg }
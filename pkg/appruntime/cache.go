@@ -0,0 +1,90 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/knowledgebase"
+	"github.com/kubeagi/arcadia/pkg/appruntime/llm"
+	"github.com/kubeagi/arcadia/pkg/langchainwrap"
+	"github.com/kubeagi/arcadia/pkg/responsecache"
+)
+
+// initCache creates the response cache of the application and wraps the llms with it.
+// The cache version contains the generation of the application and the content versions of its knowledgebases,
+// so the cached answers are dropped once any of them changes.
+func (a *Application) initCache(ctx context.Context, cli client.Client) error {
+	spec := a.Spec.ResponseCache
+	versions := []string{fmt.Sprintf("%d", a.Generation)}
+	for _, n := range a.Nodes {
+		if kb, ok := n.(*knowledgebase.Knowledgebase); ok && kb.Instance != nil {
+			versions = append(versions, fmt.Sprintf("%s=%s", kb.Instance.Name, kb.Instance.ContentVersion()))
+		}
+	}
+	sort.Strings(versions[1:])
+	config := responsecache.Config{
+		Mode:                responsecache.Mode(spec.Mode),
+		TTL:                 time.Duration(spec.TTLSeconds) * time.Second,
+		MaxEntries:          spec.MaxEntries,
+		SimilarityThreshold: spec.SimilarityThreshold,
+	}
+	if spec.Mode == arcadiav1alpha1.ResponseCacheModeSemantic {
+		if spec.Embedder == nil {
+			return fmt.Errorf("embedder is required by the semantic response cache")
+		}
+		embedder := &arcadiav1alpha1.Embedder{}
+		if err := cli.Get(ctx, types.NamespacedName{Namespace: spec.Embedder.GetNamespace(a.Namespace), Name: spec.Embedder.Name}, embedder); err != nil {
+			return fmt.Errorf("can't find the embedder of response cache: %w", err)
+		}
+		em, err := langchainwrap.GetLangchainEmbedder(ctx, embedder, cli, "")
+		if err != nil {
+			return fmt.Errorf("can't convert to langchain embedder: %w", err)
+		}
+		config.Embedder = em
+	}
+	a.cache = responsecache.New(a.Namespace, a.Name, strings.Join(versions, ","), config)
+	for _, n := range a.Nodes {
+		if l, ok := n.(*llm.LLM); ok && l.Model != nil {
+			l.Model = responsecache.WrapModel(l.Model, a.cache)
+		}
+	}
+	return nil
+}
+
+// cacheable returns whether the answer of the input can be cached,
+// questions in a conversation or with files or images depend on more than the question itself.
+func cacheable(ctx context.Context, input Input) bool {
+	if len(input.Files) > 0 || len(input.Images) > 0 {
+		return false
+	}
+	if input.History != nil {
+		messages, err := input.History.Messages(ctx)
+		if err != nil || len(messages) > 0 {
+			return false
+		}
+	}
+	return true
+}
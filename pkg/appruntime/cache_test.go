@@ -0,0 +1,98 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/memory"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	"github.com/kubeagi/arcadia/pkg/responsecache"
+)
+
+// fakeModel returns the number of calls as the answer
+type fakeModel struct {
+	calls int
+}
+
+func (m *fakeModel) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func (m *fakeModel) GenerateContent(_ context.Context, _ []langchainllms.MessageContent, _ ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	m.calls++
+	return &langchainllms.ContentResponse{Choices: []*langchainllms.ContentChoice{{Content: fmt.Sprintf("answer %d", m.calls)}}}, nil
+}
+
+// fakeChain answers the question with the llm
+type fakeChain struct {
+	base.BaseNode
+	llm langchainllms.Model
+}
+
+func (c *fakeChain) Run(ctx context.Context, _ client.Client, args map[string]any) (map[string]any, error) {
+	answer, err := langchainllms.GenerateFromSinglePrompt(ctx, c.llm, args[base.InputQuestionKeyInArg].(string))
+	if err != nil {
+		return args, err
+	}
+	args[base.OutputAnswerKeyInArg] = answer
+	return args, nil
+}
+
+func TestRunWithoutCache(t *testing.T) {
+	ctx := context.Background()
+	model := &fakeModel{}
+	a := &Application{Namespace: "arcadia", Name: t.Name()}
+	a.cache = responsecache.New(a.Namespace, a.Name, "1", responsecache.Config{})
+	chain := &fakeChain{
+		BaseNode: base.NewBaseNode(a.Namespace, "chain", arcadiav1alpha1.TypedObjectReference{Kind: "LLMChain", Name: "chain"}),
+		llm:      responsecache.WrapModel(model, a.cache),
+	}
+	a.Nodes = map[string]base.Node{"chain": chain}
+	a.StartingNodes = []base.Node{chain}
+
+	history := memory.NewChatMessageHistory()
+	_ = history.AddMessage(ctx, langchaingoschema.HumanChatMessage{Content: "hi"})
+	tests := []struct {
+		name     string
+		input    Input
+		expected string
+	}{
+		{name: "first answer", input: Input{Question: "what is kubeagi?"}, expected: "answer 1"},
+		{name: "answer cache", input: Input{Question: "what is kubeagi?"}, expected: "answer 1"},
+		{name: "regenerate the first message", input: Input{Question: "what is kubeagi?", NoCache: true}, expected: "answer 2"},
+		{name: "llm cache", input: Input{Question: "what is kubeagi?", History: history}, expected: "answer 1"},
+		{name: "regenerate a later message", input: Input{Question: "what is kubeagi?", History: history, NoCache: true}, expected: "answer 3"},
+		{name: "regenerated answers are not cached", input: Input{Question: "what is kubeagi?"}, expected: "answer 1"},
+	}
+	for _, test := range tests {
+		out, err := a.Run(ctx, nil, nil, test.input)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if out.Answer != test.expected {
+			t.Errorf("%s: expect %q, got %q", test.name, test.expected, out.Answer)
+		}
+	}
+}
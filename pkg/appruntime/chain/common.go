@@ -22,9 +22,6 @@ import (
 	"strings"
 
 	"github.com/tmc/langchaingo/chains"
-	"github.com/tmc/langchaingo/llms"
-	"github.com/tmc/langchaingo/memory"
-	langchaingoschema "github.com/tmc/langchaingo/schema"
 	"k8s.io/klog/v2"
 
 	"github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
@@ -83,22 +80,6 @@ func GetChainOptions(config v1alpha1.CommonChainConfig) []chains.ChainCallOption
 	return options
 }
 
-func GetMemory(llm llms.Model, config v1alpha1.Memory, history langchaingoschema.ChatMessageHistory, inputKey, outputKey string) langchaingoschema.Memory {
-	if inputKey == "" {
-		inputKey = "question"
-	}
-	if outputKey == "" {
-		outputKey = "text"
-	}
-	if config.MaxTokenLimit > 0 {
-		return memory.NewConversationTokenBuffer(llm, config.MaxTokenLimit, memory.WithInputKey(inputKey), memory.WithOutputKey(outputKey), memory.WithChatHistory(history))
-	}
-	if config.ConversionWindowSize != nil {
-		return memory.NewConversationWindowBuffer(*config.ConversionWindowSize, memory.WithInputKey(inputKey), memory.WithOutputKey(outputKey), memory.WithChatHistory(history))
-	}
-	return memory.NewSimple()
-}
-
 /*
 When using **stream** mode and an error occurs, **fastchat** returns http code 200 and returns an error message in json,
 but this json is inconsistent with the default format of openai, so it is silently **ignored** by langchaingo,
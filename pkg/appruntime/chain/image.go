@@ -0,0 +1,74 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chain
+
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/llms"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+// visionModel is a model which accepts images, like the llm node
+type visionModel interface {
+	SupportsVision() bool
+	ImageParts(images []base.Image) []llms.ContentPart
+}
+
+// withImages returns the model which sends the images in args along with the question,
+// or the model itself if there is no image or the model only accepts text.
+func withImages(model llms.Model, args map[string]any) llms.Model {
+	images := base.GetInputImagesFromArg(args)
+	if len(images) == 0 {
+		return model
+	}
+	vm, ok := model.(visionModel)
+	if !ok || !vm.SupportsVision() {
+		return model
+	}
+	return &imageModel{Model: model, images: vm.ImageParts(images)}
+}
+
+// imageModel adds the images to the last human message of the requests
+type imageModel struct {
+	llms.Model
+	images []llms.ContentPart
+}
+
+var _ llms.Model = (*imageModel)(nil)
+
+func (m *imageModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func (m *imageModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != langchaingoschema.ChatMessageTypeHuman {
+			continue
+		}
+		// don't change the messages of the caller
+		withImages := make([]llms.MessageContent, len(messages))
+		copy(withImages, messages)
+		parts := make([]llms.ContentPart, 0, len(messages[i].Parts)+len(m.images))
+		parts = append(parts, messages[i].Parts...)
+		withImages[i].Parts = append(parts, m.images...)
+		return m.Model.GenerateContent(ctx, withImages, options...)
+	}
+	return m.Model.GenerateContent(ctx, messages, options...)
+}
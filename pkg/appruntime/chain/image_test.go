@@ -0,0 +1,83 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+type fakeVisionLLM struct {
+	fakeLLM
+	vision   bool
+	messages []llms.MessageContent
+}
+
+func (f *fakeVisionLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	f.messages = messages
+	return f.fakeLLM.GenerateContent(ctx, messages, options...)
+}
+
+func (f *fakeVisionLLM) SupportsVision() bool {
+	return f.vision
+}
+
+func (f *fakeVisionLLM) ImageParts(images []base.Image) []llms.ContentPart {
+	parts := make([]llms.ContentPart, 0, len(images))
+	for _, image := range images {
+		parts = append(parts, llms.BinaryPart(image.MIMEType, image.Data))
+	}
+	return parts
+}
+
+func TestWithImages(t *testing.T) {
+	args := map[string]any{base.InputImagesKeyInArg: []base.Image{{MIMEType: "image/png", Data: []byte("png")}}}
+	textOnly := &fakeVisionLLM{}
+	if withImages(textOnly, args) != llms.Model(textOnly) {
+		t.Error("the text-only model should not be wrapped")
+	}
+	vision := &fakeVisionLLM{vision: true}
+	if withImages(vision, map[string]any{}) != llms.Model(vision) {
+		t.Error("the model should not be wrapped without images")
+	}
+
+	messages := []llms.MessageContent{
+		llms.TextParts(langchaingoschema.ChatMessageTypeSystem, "be helpful"),
+		llms.TextParts(langchaingoschema.ChatMessageTypeHuman, "what is in the image?"),
+	}
+	if _, err := withImages(vision, args).GenerateContent(context.Background(), messages); err != nil {
+		t.Fatal(err)
+	}
+	if len(messages[1].Parts) != 1 {
+		t.Errorf("the messages of the caller are changed")
+	}
+	got := vision.messages[1].Parts
+	if len(got) != 2 {
+		t.Fatalf("got %d parts in the human message, want 2", len(got))
+	}
+	if image, ok := got[1].(llms.BinaryContent); !ok || string(image.Data) != "png" {
+		t.Errorf("got %#v, want the image", got[1])
+	}
+	if len(vision.messages[0].Parts) != 1 {
+		t.Errorf("images are added to the system message")
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chain
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/tmc/langchaingo/chains"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/memory"
+	"github.com/tmc/langchaingo/prompts"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
+)
+
+const PromptForSummarizeConversation = `Progressively summarize the lines of conversation provided, adding onto the previous summary and returning a new summary.
+
+Requires language consistent with the conversation, keep the names, numbers and facts mentioned, the summary only.
+---
+Current summary:
+{{.summary}}
+
+New lines of conversation:
+{{.new_lines}}
+---
+The new summary is:`
+
+const (
+	humanPrefix = "Human"
+	aiPrefix    = "AI"
+)
+
+// SummaryChatMessage is the summary of the older conversation rounds, it is put before the rounds in the history.
+type SummaryChatMessage struct {
+	Content string
+}
+
+func (m SummaryChatMessage) GetType() langchaingoschema.ChatMessageType {
+	return langchaingoschema.ChatMessageTypeSystem
+}
+
+func (m SummaryChatMessage) GetContent() string {
+	return "Summary of the earlier conversation: " + m.Content
+}
+
+// SummaryRecorder records the summary made by the memories in a run,
+// so that the caller can keep the summary, and the memories of all chains in the run only summarize once.
+type SummaryRecorder struct {
+	mu         sync.Mutex
+	summarized bool
+	summary    string
+	messages   int
+}
+
+func NewSummaryRecorder() *SummaryRecorder {
+	return &SummaryRecorder{}
+}
+
+// Summary returns the new summary and the number of the history messages after the leading system messages summarized into it.
+// ok is false if nothing is summarized in the run.
+func (r *SummaryRecorder) Summary() (summary string, messages int, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.summary, r.messages, r.summarized
+}
+
+func (r *SummaryRecorder) record(summary string, messages int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summary, r.messages, r.summarized = summary, messages, true
+}
+
+type summaryRecorderKey struct{}
+
+// NewSummaryContext returns a context which the memories record the summary to
+func NewSummaryContext(ctx context.Context, r *SummaryRecorder) context.Context {
+	return context.WithValue(ctx, summaryRecorderKey{}, r)
+}
+
+// SummaryRecorderFromContext returns the recorder in the context, nil if not found
+func SummaryRecorderFromContext(ctx context.Context) *SummaryRecorder {
+	r, _ := ctx.Value(summaryRecorderKey{}).(*SummaryRecorder)
+	return r
+}
+
+// conversationMemory keeps the leading system messages of the history, like the summary and the facts about the user,
+// and the recent conversation rounds within MaxTokenLimit or ConversionWindowSize.
+// The older rounds are forgotten, or summarized by the llm if Summarize is set.
+type conversationMemory struct {
+	// the buffer memory saves the context to the history
+	langchaingoschema.Memory
+	llm     llms.Model
+	config  v1alpha1.Memory
+	history langchaingoschema.ChatMessageHistory
+}
+
+var _ langchaingoschema.Memory = (*conversationMemory)(nil)
+
+func (m *conversationMemory) LoadMemoryVariables(ctx context.Context, inputs map[string]any) (map[string]any, error) {
+	messages, err := m.history.Messages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	leading, rounds := splitLeadingMessages(messages)
+	if len(leading) == 0 && !m.config.Summarize {
+		return m.Memory.LoadMemoryVariables(ctx, inputs)
+	}
+	kept, forgotten := m.cut(rounds)
+	if m.config.Summarize && len(forgotten) > 0 {
+		summary, summarized, err := m.summarize(ctx, leading, rounds, len(forgotten))
+		if err != nil {
+			// forget the older rounds like the buffer memory does
+			klog.FromContext(ctx).Error(err, "failed to summarize the conversation")
+		} else {
+			leading = withSummary(leading, summary)
+			kept, _ = m.cut(rounds[summarized:])
+		}
+	}
+	bufferString, err := langchaingoschema.GetBufferString(append(leading, kept...), humanPrefix, aiPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		m.Memory.GetMemoryKey(ctx): bufferString,
+	}, nil
+}
+
+// cut returns the recent rounds kept in the memory and the older ones, a round is a pair of messages.
+func (m *conversationMemory) cut(rounds []langchaingoschema.ChatMessage) (kept, forgotten []langchaingoschema.ChatMessage) {
+	start := 0
+	switch {
+	case m.config.MaxTokenLimit > 0:
+		for ; start < len(rounds); start += 2 {
+			bufferString, err := langchaingoschema.GetBufferString(rounds[start:], humanPrefix, aiPrefix)
+			if err != nil || llms.CountTokens("", bufferString) <= m.config.MaxTokenLimit {
+				break
+			}
+		}
+		if start > len(rounds) {
+			start = len(rounds)
+		}
+	case m.config.ConversionWindowSize != nil:
+		if size := *m.config.ConversionWindowSize * 2; len(rounds) > size {
+			start = len(rounds) - size
+		}
+	}
+	return rounds[start:], rounds[:start]
+}
+
+// summarize adds the rounds to forget onto the current summary by the llm,
+// it returns the new summary and the number of rounds messages summarized.
+func (m *conversationMemory) summarize(ctx context.Context, leading, rounds []langchaingoschema.ChatMessage, forgotten int) (summary string, summarized int, err error) {
+	recorder := SummaryRecorderFromContext(ctx)
+	if recorder != nil {
+		// another chain in this run has summarized the same history
+		if summary, summarized, ok := recorder.Summary(); ok && summarized <= len(rounds) {
+			return summary, summarized, nil
+		}
+	}
+	for _, msg := range leading {
+		if s, ok := msg.(SummaryChatMessage); ok {
+			summary = s.Content
+		}
+	}
+	newLines, err := langchaingoschema.GetBufferString(rounds[:forgotten], humanPrefix, aiPrefix)
+	if err != nil {
+		return "", 0, err
+	}
+	p := prompts.NewPromptTemplate(PromptForSummarizeConversation, []string{"summary", "new_lines"})
+	summary, err = chains.Predict(ctx, chains.NewLLMChain(m.llm, p), map[string]any{
+		"summary":   summary,
+		"new_lines": newLines,
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	summary = strings.TrimSpace(summary)
+	if recorder != nil {
+		recorder.record(summary, forgotten)
+	}
+	klog.FromContext(ctx).V(3).Info("summarize the conversation", "messages", forgotten)
+	return summary, forgotten, nil
+}
+
+// splitLeadingMessages splits the system messages before the conversation rounds in the history
+func splitLeadingMessages(messages []langchaingoschema.ChatMessage) (leading, rounds []langchaingoschema.ChatMessage) {
+	i := 0
+	for ; i < len(messages); i++ {
+		if messages[i].GetType() != langchaingoschema.ChatMessageTypeSystem {
+			break
+		}
+	}
+	return messages[:i:i], messages[i:]
+}
+
+// withSummary returns the leading messages with the summary replaced, or added at the end
+func withSummary(leading []langchaingoschema.ChatMessage, summary string) []langchaingoschema.ChatMessage {
+	res := make([]langchaingoschema.ChatMessage, 0, len(leading)+1)
+	for _, msg := range leading {
+		if _, ok := msg.(SummaryChatMessage); !ok {
+			res = append(res, msg)
+		}
+	}
+	return append(res, SummaryChatMessage{Content: summary})
+}
+
+// GetMemory returns the memory of the chain by the config, the history is shared by the chains in a run
+func GetMemory(llm llms.Model, config v1alpha1.Memory, history langchaingoschema.ChatMessageHistory, inputKey, outputKey string) langchaingoschema.Memory {
+	if inputKey == "" {
+		inputKey = "question"
+	}
+	if outputKey == "" {
+		outputKey = "text"
+	}
+	var buffer langchaingoschema.Memory
+	switch {
+	case config.MaxTokenLimit > 0:
+		buffer = memory.NewConversationTokenBuffer(llm, config.MaxTokenLimit, memory.WithInputKey(inputKey), memory.WithOutputKey(outputKey), memory.WithChatHistory(history))
+	case config.ConversionWindowSize != nil:
+		buffer = memory.NewConversationWindowBuffer(*config.ConversionWindowSize, memory.WithInputKey(inputKey), memory.WithOutputKey(outputKey), memory.WithChatHistory(history))
+	default:
+		return memory.NewSimple()
+	}
+	return &conversationMemory{Memory: buffer, llm: llm, config: config, history: history}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/memory"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
+)
+
+type fakeLLM struct {
+	answer string
+	calls  int
+}
+
+func (f *fakeLLM) GenerateContent(_ context.Context, _ []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	f.calls++
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: f.answer}}}, nil
+}
+
+func (f *fakeLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+func newHistory(messages ...langchaingoschema.ChatMessage) *memory.ChatMessageHistory {
+	return memory.NewChatMessageHistory(memory.WithPreviousMessages(messages))
+}
+
+func rounds(n int) []langchaingoschema.ChatMessage {
+	res := make([]langchaingoschema.ChatMessage, 0, n*2)
+	for i := 0; i < n; i++ {
+		res = append(res, langchaingoschema.HumanChatMessage{Content: "q" + string(rune('0'+i))}, langchaingoschema.AIChatMessage{Content: "a" + string(rune('0'+i))})
+	}
+	return res
+}
+
+func TestConversationMemory(t *testing.T) {
+	window := 2
+	facts := langchaingoschema.SystemChatMessage{Content: "the user is a gopher"}
+	tests := []struct {
+		name      string
+		config    v1alpha1.Memory
+		history   []langchaingoschema.ChatMessage
+		want      string
+		summarize bool
+	}{
+		{
+			name:    "window buffer without leading messages",
+			config:  v1alpha1.Memory{ConversionWindowSize: &window},
+			history: rounds(3),
+			want:    "Human: q1\nAI: a1\nHuman: q2\nAI: a2",
+		},
+		{
+			name:    "keep the facts when the rounds are cut",
+			config:  v1alpha1.Memory{ConversionWindowSize: &window},
+			history: append([]langchaingoschema.ChatMessage{facts}, rounds(3)...),
+			want:    "System: the user is a gopher\nHuman: q1\nAI: a1\nHuman: q2\nAI: a2",
+		},
+		{
+			name:      "summarize the forgotten rounds",
+			config:    v1alpha1.Memory{ConversionWindowSize: &window, Summarize: true},
+			history:   append([]langchaingoschema.ChatMessage{facts, SummaryChatMessage{Content: "old"}}, rounds(3)...),
+			want:      "System: the user is a gopher\nSystem: Summary of the earlier conversation: new\nHuman: q1\nAI: a1\nHuman: q2\nAI: a2",
+			summarize: true,
+		},
+		{
+			name:    "nothing to summarize",
+			config:  v1alpha1.Memory{ConversionWindowSize: &window, Summarize: true},
+			history: rounds(2),
+			want:    "Human: q0\nAI: a0\nHuman: q1\nAI: a1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &fakeLLM{answer: " new\n"}
+			recorder := NewSummaryRecorder()
+			ctx := NewSummaryContext(context.Background(), recorder)
+			m := GetMemory(llm, tt.config, newHistory(tt.history...), "", "")
+			for i := 0; i < 2; i++ {
+				vars, err := m.LoadMemoryVariables(ctx, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := vars["history"]; got != tt.want {
+					t.Errorf("got %q, want %q", got, tt.want)
+				}
+			}
+			summary, messages, ok := recorder.Summary()
+			if ok != tt.summarize {
+				t.Fatalf("summarized %v, want %v", ok, tt.summarize)
+			}
+			if !tt.summarize {
+				return
+			}
+			if summary != "new" || messages != 2 {
+				t.Errorf("got summary %q of %d messages", summary, messages)
+			}
+			if llm.calls != 1 {
+				t.Errorf("summarized %d times, want once", llm.calls)
+			}
+		})
+	}
+}
+
+func TestConversationMemoryTokenLimit(t *testing.T) {
+	m := &conversationMemory{config: v1alpha1.Memory{MaxTokenLimit: 1}}
+	kept, forgotten := m.cut(rounds(3))
+	if len(kept) != 0 || len(forgotten) != 6 {
+		t.Errorf("got %d kept and %d forgotten", len(kept), len(forgotten))
+	}
+	m.config.MaxTokenLimit = 1000
+	kept, forgotten = m.cut(rounds(3))
+	if len(kept) != 6 || len(forgotten) != 0 {
+		t.Errorf("got %d kept and %d forgotten", len(kept), len(forgotten))
+	}
+}
@@ -0,0 +1,418 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/memory"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeagi/arcadia/api/app-node/guardrail/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	"github.com/kubeagi/arcadia/pkg/appruntime/chain"
+	"github.com/kubeagi/arcadia/pkg/langchainwrap"
+)
+
+const (
+	// DefaultBlockMessage is the answer when the chat is blocked and no message is set
+	DefaultBlockMessage = "Sorry, I can't help with this, the content is not allowed."
+
+	// RedactMask replaces the text in the blocklist when redacted
+	RedactMask = "***"
+
+	PromptForPolicy = `You are a content moderator. Check whether the text below breaks any of the rules.
+
+Rules:
+%s
+
+Text:
+"""
+%s
+"""
+
+Reply with SAFE if the text breaks none of the rules. Otherwise reply with UNSAFE followed by the numbers of the broken rules and a short reason, like "UNSAFE: 1, it asks how to make weapons". Reply nothing else.`
+)
+
+// Rule is the kind of the rules of the guardrail
+type Rule string
+
+const (
+	RuleBlocklist Rule = "blocklist"
+	RulePII       Rule = "pii"
+	RulePolicy    Rule = "policy"
+)
+
+// Violation is a rule broken by the question or the answer
+type Violation struct {
+	// Node is the name of the guardrail node
+	Node     string
+	Position v1alpha1.Position
+	Rule     Rule
+	// Detail is the keyword or pattern in the blocklist, the type of the personal information, or the reason from the policy llm
+	Detail string
+	Action v1alpha1.Action
+}
+
+// GetViolationsFromArg returns the violations found by the guardrail nodes so far
+func GetViolationsFromArg(args map[string]any) []Violation {
+	violations, _ := args[base.GuardrailViolationsInArg].([]Violation)
+	return violations
+}
+
+// GetPIIMaskFromArg returns the originals of the placeholders masked by the input guardrails, nil if nothing is masked
+func GetPIIMaskFromArg(args map[string]any) map[string]string {
+	originals, _ := args[base.GuardrailPIIMaskInArg].(map[string]string)
+	return originals
+}
+
+// blockRule is a keyword or pattern in the blocklist
+type blockRule struct {
+	detail string
+	re     *regexp.Regexp
+}
+
+type Guardrail struct {
+	base.BaseNode
+	Instance  *v1alpha1.Guardrail
+	blocklist []blockRule
+	// policy is the llm to classify the text, nil if no policy is set
+	policy langchainllms.Model
+	// stream takes the answer stream over to restore the placeholders, nil if the answer stream is not taken over
+	stream     chan string
+	streamDone chan struct{}
+}
+
+func NewGuardrail(baseNode base.BaseNode) *Guardrail {
+	return &Guardrail{
+		BaseNode: baseNode,
+	}
+}
+
+func (g *Guardrail) Init(ctx context.Context, cli client.Client, _ map[string]any) error {
+	instance := &v1alpha1.Guardrail{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: g.RefNamespace(), Name: g.Ref.Name}, instance); err != nil {
+		return fmt.Errorf("can't find the guardrail in cluster: %w", err)
+	}
+	g.Instance = instance
+	blocklist, err := compileBlocklist(instance.Spec.Blocklist)
+	if err != nil {
+		return err
+	}
+	g.blocklist = blocklist
+	if policy := instance.Spec.Policy; policy != nil {
+		llm := &arcadiav1alpha1.LLM{}
+		if err := cli.Get(ctx, types.NamespacedName{Namespace: policy.LLM.GetNamespace(g.RefNamespace()), Name: policy.LLM.Name}, llm); err != nil {
+			return fmt.Errorf("can't find the llm of policy: %w", err)
+		}
+		model, err := langchainwrap.GetLangchainLLM(ctx, llm, cli, policy.Model)
+		if err != nil {
+			return fmt.Errorf("can't convert to langchain llm: %w", err)
+		}
+		g.policy = model
+	}
+	return nil
+}
+
+// compileBlocklist compiles the keywords and patterns in the blocklist, the keywords are matched case-insensitively
+func compileBlocklist(blocklist *v1alpha1.Blocklist) ([]blockRule, error) {
+	if blocklist == nil {
+		return nil, nil
+	}
+	rules := make([]blockRule, 0, len(blocklist.Keywords)+len(blocklist.Patterns))
+	for _, keyword := range blocklist.Keywords {
+		if keyword == "" {
+			continue
+		}
+		rules = append(rules, blockRule{detail: keyword, re: regexp.MustCompile("(?i)" + regexp.QuoteMeta(keyword))})
+	}
+	for _, pattern := range blocklist.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in the blocklist: %w", pattern, err)
+		}
+		rules = append(rules, blockRule{detail: pattern, re: re})
+	}
+	return rules, nil
+}
+
+// IsInput returns whether the guardrail checks the question
+func (g *Guardrail) IsInput() bool {
+	return g.Instance == nil || g.Instance.Spec.Position != v1alpha1.PositionOutput
+}
+
+// HoldsAnswer returns whether the guardrail may change the answer,
+// the answer can't be streamed to the user before it is checked by such guardrails
+func (g *Guardrail) HoldsAnswer() bool {
+	if g.IsInput() {
+		return false
+	}
+	spec := g.Instance.Spec
+	if spec.PII != nil && piiAction(spec.PII) != v1alpha1.ActionWarn {
+		return true
+	}
+	return (len(g.blocklist) > 0 || g.policy != nil) && g.action() != v1alpha1.ActionWarn
+}
+
+func (g *Guardrail) action() v1alpha1.Action {
+	if g.Instance.Spec.Action == "" {
+		return v1alpha1.ActionBlock
+	}
+	return g.Instance.Spec.Action
+}
+
+func piiAction(pii *v1alpha1.PII) v1alpha1.Action {
+	if pii.Action == "" {
+		return v1alpha1.ActionRedact
+	}
+	return pii.Action
+}
+
+func (g *Guardrail) Run(ctx context.Context, _ client.Client, args map[string]any) (map[string]any, error) {
+	if g.IsInput() {
+		return g.checkQuestion(ctx, args)
+	}
+	return g.checkAnswer(ctx, args)
+}
+
+// checkQuestion checks the question, and masks the personal information in the question and the history reversibly,
+// so the llms only get the placeholders, and the placeholders in the answer are restored for the user.
+func (g *Guardrail) checkQuestion(ctx context.Context, args map[string]any) (map[string]any, error) {
+	question, err := base.GetInputQuestionFromArg(args)
+	if err != nil {
+		return args, err
+	}
+	var m *masker
+	if pii := g.Instance.Spec.PII; pii != nil && piiAction(pii) == v1alpha1.ActionRedact {
+		m = newMasker(pii.Types, true, GetPIIMaskFromArg(args))
+	}
+	question, err = g.check(ctx, args, question, m)
+	if err != nil {
+		return args, err
+	}
+	args[base.InputQuestionKeyInArg] = question
+	if m == nil {
+		return args, nil
+	}
+	if v, ok := args[base.LangchaingoChatMessageHistoryKeyInArg]; ok && v != nil {
+		history, ok := v.(langchaingoschema.ChatMessageHistory)
+		if !ok {
+			return args, errors.New("history not memory.ChatMessageHistory")
+		}
+		if args[base.LangchaingoChatMessageHistoryKeyInArg], err = maskHistory(ctx, history, m); err != nil {
+			return args, fmt.Errorf("failed to mask the history: %w", err)
+		}
+	}
+	if len(m.originals) > 0 {
+		args[base.GuardrailPIIMaskInArg] = m.originals
+		g.takeOverStream(args, m.originals)
+	}
+	return args, nil
+}
+
+// checkAnswer checks the answer, the personal information in the answer is masked irreversibly
+func (g *Guardrail) checkAnswer(ctx context.Context, args map[string]any) (map[string]any, error) {
+	answer, _ := args[base.OutputAnswerKeyInArg].(string)
+	if answer == "" {
+		return args, nil
+	}
+	var m *masker
+	if pii := g.Instance.Spec.PII; pii != nil && piiAction(pii) == v1alpha1.ActionRedact {
+		m = newMasker(pii.Types, false, nil)
+	}
+	answer, err := g.check(ctx, args, answer, m)
+	if err != nil {
+		return args, err
+	}
+	args[base.OutputAnswerKeyInArg] = answer
+	return args, nil
+}
+
+// check returns the text to go on with, or a GuardrailBlockedError if the chat is blocked.
+// The personal information is masked by m if it is not nil.
+func (g *Guardrail) check(ctx context.Context, args map[string]any, text string, m *masker) (string, error) {
+	logger := klog.FromContext(ctx)
+	blocked := false
+	if pii := g.Instance.Spec.PII; pii != nil {
+		var found []v1alpha1.PIIType
+		if m != nil {
+			text, found = m.mask(text)
+		} else {
+			found = detectPII(text, pii.Types)
+		}
+		for _, t := range found {
+			g.violate(args, RulePII, string(t), piiAction(pii))
+		}
+		blocked = len(found) > 0 && piiAction(pii) == v1alpha1.ActionBlock
+	}
+	action := g.action()
+	for _, rule := range g.blocklist {
+		if !rule.re.MatchString(text) {
+			continue
+		}
+		g.violate(args, RuleBlocklist, rule.detail, action)
+		switch action {
+		case v1alpha1.ActionBlock:
+			blocked = true
+		case v1alpha1.ActionRedact:
+			text = rule.re.ReplaceAllString(text, RedactMask)
+		}
+	}
+	if blocked {
+		return text, g.blockedError()
+	}
+	if g.policy != nil {
+		// the policy is try our best, the text goes on if it fails to be classified
+		reason, err := g.classify(ctx, text)
+		if err != nil {
+			logger.Error(err, "failed to classify the text by the policy llm", "guardrail", g.Name())
+		} else if reason != "" {
+			g.violate(args, RulePolicy, reason, action)
+			if action != v1alpha1.ActionWarn {
+				return text, g.blockedError()
+			}
+		}
+	}
+	return text, nil
+}
+
+func (g *Guardrail) violate(args map[string]any, rule Rule, detail string, action v1alpha1.Action) {
+	position := v1alpha1.PositionInput
+	if !g.IsInput() {
+		position = v1alpha1.PositionOutput
+	}
+	args[base.GuardrailViolationsInArg] = append(GetViolationsFromArg(args), Violation{
+		Node:     g.Name(),
+		Position: position,
+		Rule:     rule,
+		Detail:   detail,
+		Action:   action,
+	})
+}
+
+func (g *Guardrail) blockedError() error {
+	msg := g.Instance.Spec.Message
+	if msg == "" {
+		msg = DefaultBlockMessage
+	}
+	return &base.GuardrailBlockedError{Msg: msg}
+}
+
+// classify returns the reason if the text breaks the rules of the policy, empty if it doesn't
+func (g *Guardrail) classify(ctx context.Context, text string) (string, error) {
+	rules := make([]string, 0, len(g.Instance.Spec.Policy.Rules))
+	for i, rule := range g.Instance.Spec.Policy.Rules {
+		rules = append(rules, fmt.Sprintf("%d. %s", i+1, rule))
+	}
+	resp, err := langchainllms.GenerateFromSinglePrompt(ctx, g.policy, fmt.Sprintf(PromptForPolicy, strings.Join(rules, "\n"), text), langchainllms.WithTemperature(0))
+	if err != nil {
+		return "", err
+	}
+	return parsePolicyResult(resp)
+}
+
+// parsePolicyResult returns the reason if the reply of the policy llm is UNSAFE, empty if it is SAFE
+func parsePolicyResult(resp string) (string, error) {
+	resp = strings.TrimSpace(resp)
+	upper := strings.ToUpper(resp)
+	switch {
+	case strings.HasPrefix(upper, "UNSAFE"):
+		if reason := strings.TrimSpace(strings.TrimLeft(resp[len("UNSAFE"):], ":： ")); reason != "" {
+			return reason, nil
+		}
+		return "UNSAFE", nil
+	case strings.HasPrefix(upper, "SAFE"):
+		return "", nil
+	default:
+		return "", fmt.Errorf("unexpected reply of the policy llm: %s", resp)
+	}
+}
+
+// maskHistory returns a copy of the history with the personal information masked
+func maskHistory(ctx context.Context, history langchaingoschema.ChatMessageHistory, m *masker) (langchaingoschema.ChatMessageHistory, error) {
+	messages, err := history.Messages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := memory.NewChatMessageHistory()
+	for _, msg := range messages {
+		switch v := msg.(type) {
+		case chain.SummaryChatMessage:
+			v.Content, _ = m.mask(v.Content)
+			msg = v
+		case langchaingoschema.HumanChatMessage:
+			v.Content, _ = m.mask(v.Content)
+			msg = v
+		case langchaingoschema.AIChatMessage:
+			v.Content, _ = m.mask(v.Content)
+			msg = v
+		case langchaingoschema.SystemChatMessage:
+			v.Content, _ = m.mask(v.Content)
+			msg = v
+		case langchaingoschema.GenericChatMessage:
+			v.Content, _ = m.mask(v.Content)
+			msg = v
+		}
+		if err := res.AddMessage(ctx, msg); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// takeOverStream takes the answer stream over, so the placeholders in the streamed answer are restored before they go to the user
+func (g *Guardrail) takeOverStream(args map[string]any, originals map[string]string) {
+	needStream, _ := args[base.InputIsNeedStreamKeyInArg].(bool)
+	out, ok := args[base.OutputAnswerStreamChanKeyInArg].(chan string)
+	if !needStream || !ok || out == nil || g.stream != nil {
+		return
+	}
+	in := make(chan string)
+	done := make(chan struct{})
+	r := newStreamRestorer(originals)
+	go func() {
+		defer close(done)
+		for chunk := range in {
+			if s := r.push(chunk); s != "" {
+				out <- s
+			}
+		}
+		if s := r.flush(); s != "" {
+			out <- s
+		}
+	}()
+	args[base.OutputAnswerStreamChanKeyInArg] = in
+	g.stream, g.streamDone = in, done
+}
+
+// Cleanup sends the rest of the streamed answer
+func (g *Guardrail) Cleanup() {
+	if g.stream != nil {
+		close(g.stream)
+		<-g.streamDone
+		g.stream, g.streamDone = nil, nil
+	}
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kubeagi/arcadia/api/app-node/guardrail/v1alpha1"
+)
+
+// piiDetector finds one type of personal information in the text
+type piiDetector struct {
+	typ v1alpha1.PIIType
+	// label is used in the placeholders of the information
+	label string
+	re    *regexp.Regexp
+	// valid checks the matched text further, nil means all matches are valid
+	valid func(string) bool
+}
+
+// piiDetectors are in the order of detection, the emails go first since they may contain the numbers
+var piiDetectors = []piiDetector{
+	{
+		typ:   v1alpha1.PIIEmail,
+		label: "EMAIL",
+		re:    regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+	},
+	{
+		typ:   v1alpha1.PIIIDCard,
+		label: "ID_CARD",
+		re:    regexp.MustCompile(`\b[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`),
+		valid: validIDCard,
+	},
+	{
+		typ:   v1alpha1.PIIBankCard,
+		label: "BANK_CARD",
+		re:    regexp.MustCompile(`\b\d{4}(?:[ -]?\d{4}){2}[ -]?\d{4,7}\b`),
+		valid: validBankCard,
+	},
+	{
+		typ:   v1alpha1.PIIPhone,
+		label: "PHONE",
+		re:    regexp.MustCompile(`(?:\+86[ -]?|\b)1[3-9]\d{9}\b`),
+	},
+}
+
+// validIDCard checks the check digit of the resident identity card number
+func validIDCard(s string) bool {
+	weights := []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i, w := range weights {
+		sum += int(s[i]-'0') * w
+	}
+	return "10X98765432"[sum%11] == strings.ToUpper(s)[17]
+}
+
+// validBankCard checks the bank card number by the Luhn algorithm
+func validBankCard(s string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+	if len(digits) < 16 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+func enabledDetectors(types []v1alpha1.PIIType) []piiDetector {
+	if len(types) == 0 {
+		return piiDetectors
+	}
+	res := make([]piiDetector, 0, len(types))
+	for _, d := range piiDetectors {
+		for _, t := range types {
+			if d.typ == t {
+				res = append(res, d)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// replacePII replaces the personal information in the text by replace, and returns the types found in order
+func replacePII(text string, detectors []piiDetector, replace func(d piiDetector, s string) string) (string, []v1alpha1.PIIType) {
+	var found []v1alpha1.PIIType
+	for _, d := range detectors {
+		d := d
+		hit := false
+		text = d.re.ReplaceAllStringFunc(text, func(s string) string {
+			if d.valid != nil && !d.valid(s) {
+				return s
+			}
+			hit = true
+			return replace(d, s)
+		})
+		if hit {
+			found = append(found, d.typ)
+		}
+	}
+	return text, found
+}
+
+// detectPII returns the types of the personal information in the text
+func detectPII(text string, types []v1alpha1.PIIType) []v1alpha1.PIIType {
+	_, found := replacePII(text, enabledDetectors(types), func(_ piiDetector, s string) string { return s })
+	return found
+}
+
+// masker masks the personal information with placeholders like [PHONE_1].
+// If it is reversible, the same information gets the same placeholder, and the originals of the placeholders are kept to restore them,
+// otherwise the placeholders are like [PHONE].
+type masker struct {
+	detectors  []piiDetector
+	reversible bool
+	// originals of the placeholders
+	originals    map[string]string
+	placeholders map[string]string
+	counts       map[string]int
+}
+
+// newMasker returns a masker, the placeholders of the reversible one go on from the originals masked before
+func newMasker(types []v1alpha1.PIIType, reversible bool, originals map[string]string) *masker {
+	m := &masker{
+		detectors:    enabledDetectors(types),
+		reversible:   reversible,
+		originals:    make(map[string]string, len(originals)),
+		placeholders: make(map[string]string, len(originals)),
+		counts:       make(map[string]int),
+	}
+	for placeholder, original := range originals {
+		m.originals[placeholder] = original
+		m.placeholders[original] = placeholder
+		label := strings.TrimSuffix(strings.TrimPrefix(placeholder, "["), "]")
+		if i := strings.LastIndex(label, "_"); i > 0 {
+			label = label[:i]
+		}
+		m.counts[label]++
+	}
+	return m
+}
+
+// mask returns the masked text and the types of the personal information found
+func (m *masker) mask(text string) (string, []v1alpha1.PIIType) {
+	return replacePII(text, m.detectors, func(d piiDetector, s string) string {
+		if !m.reversible {
+			return "[" + d.label + "]"
+		}
+		if placeholder, ok := m.placeholders[s]; ok {
+			return placeholder
+		}
+		m.counts[d.label]++
+		placeholder := fmt.Sprintf("[%s_%d]", d.label, m.counts[d.label])
+		m.placeholders[s] = placeholder
+		m.originals[placeholder] = s
+		return placeholder
+	})
+}
+
+// newRestorer returns a replacer which restores the placeholders to the originals
+func newRestorer(originals map[string]string) *strings.Replacer {
+	pairs := make([]string, 0, len(originals)*2)
+	for placeholder, original := range originals {
+		pairs = append(pairs, placeholder, original)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// Restore returns the text with the placeholders of the personal information masked by the input guardrails restored
+func Restore(args map[string]any, text string) string {
+	originals := GetPIIMaskFromArg(args)
+	if len(originals) == 0 {
+		return text
+	}
+	return newRestorer(originals).Replace(text)
+}
+
+// streamRestorer restores the placeholders in the chunks of a stream, the placeholders may be split into chunks
+type streamRestorer struct {
+	restorer *strings.Replacer
+	// maxLen is the length of the longest placeholder
+	maxLen  int
+	pending string
+}
+
+func newStreamRestorer(originals map[string]string) *streamRestorer {
+	r := &streamRestorer{restorer: newRestorer(originals)}
+	for placeholder := range originals {
+		if len(placeholder) > r.maxLen {
+			r.maxLen = len(placeholder)
+		}
+	}
+	return r
+}
+
+// push returns the text which can be sent in the chunks received so far
+func (r *streamRestorer) push(chunk string) string {
+	r.pending += chunk
+	open := strings.LastIndex(r.pending, "[")
+	if open < 0 || strings.Contains(r.pending[open:], "]") || len(r.pending)-open >= r.maxLen {
+		return r.flush()
+	}
+	// keep the text from the last [, it may be the start of a placeholder
+	res := r.restorer.Replace(r.pending[:open])
+	r.pending = r.pending[open:]
+	return res
+}
+
+// flush returns the rest of the text
+func (r *streamRestorer) flush() string {
+	res := r.restorer.Replace(r.pending)
+	r.pending = ""
+	return res
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	"github.com/kubeagi/arcadia/pkg/appruntime/llm"
+	"github.com/kubeagi/arcadia/pkg/langchainwrap"
+	"github.com/kubeagi/arcadia/pkg/llms"
+)
+
+const PromptForDescribeImage = `Describe the image in detail for someone who can't see it, and write down all the text in the image as it is.
+Requires language consistent with the text in the image, the description only.`
+
+// imageCaptioner describes the images by a vision llm for the llms which only accept text
+type imageCaptioner struct {
+	model   langchainllms.Model
+	llmType llms.LLMType
+}
+
+func (a *Application) initImageCaption(ctx context.Context, cli client.Client) error {
+	spec := a.Spec.ImageCaption
+	instance := &arcadiav1alpha1.LLM{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: spec.LLM.GetNamespace(a.Namespace), Name: spec.LLM.Name}, instance); err != nil {
+		return fmt.Errorf("can't find the llm of image caption: %w", err)
+	}
+	if !instance.SupportsVision(spec.Model) {
+		return fmt.Errorf("llm %s of image caption doesn't accept images, declare the vision capability of the model if it does", instance.Name)
+	}
+	model, err := langchainwrap.GetLangchainLLM(ctx, instance, cli, spec.Model)
+	if err != nil {
+		return fmt.Errorf("can't convert to langchain llm: %w", err)
+	}
+	a.captioner = &imageCaptioner{model: model, llmType: instance.Spec.Type}
+	return nil
+}
+
+// acceptsImages returns whether all the llms of the application accept images
+func (a *Application) acceptsImages() bool {
+	found := false
+	for _, n := range a.Nodes {
+		if l, ok := n.(*llm.LLM); ok {
+			if !l.SupportsVision() {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+// withImageDescriptions returns the question with the descriptions of the images,
+// or the question itself if the images can't be described.
+func (a *Application) withImageDescriptions(ctx context.Context, question string, images []base.Image) string {
+	logger := klog.FromContext(ctx)
+	if a.captioner == nil {
+		logger.V(3).Info("the llms of application only accept text and no image caption is set, ignore the images", "images", len(images))
+		return question
+	}
+	descriptions := make([]string, 0, len(images))
+	for i, image := range images {
+		description, err := a.captioner.describe(ctx, image)
+		if err != nil {
+			logger.Error(err, "failed to describe the image, ignore it", "image", i+1)
+			continue
+		}
+		descriptions = append(descriptions, fmt.Sprintf("Image %d: %s", i+1, description))
+	}
+	if len(descriptions) == 0 {
+		return question
+	}
+	return question + "\n\nThe images in the question are described below.\n" + strings.Join(descriptions, "\n")
+}
+
+func (c *imageCaptioner) describe(ctx context.Context, image base.Image) (string, error) {
+	resp, err := c.model.GenerateContent(ctx, []langchainllms.MessageContent{
+		{
+			Role:  langchaingoschema.ChatMessageTypeHuman,
+			Parts: []langchainllms.ContentPart{langchainllms.TextPart(PromptForDescribeImage), llms.ImagePart(c.llmType, image.MIMEType, image.Data)},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Content) == "" {
+		return "", fmt.Errorf("empty description")
+	}
+	return strings.TrimSpace(resp.Choices[0].Content), nil
+}
@@ -28,12 +28,17 @@ import (
 	"github.com/kubeagi/arcadia/api/base/v1alpha1"
 	"github.com/kubeagi/arcadia/pkg/appruntime/base"
 	"github.com/kubeagi/arcadia/pkg/langchainwrap"
+	"github.com/kubeagi/arcadia/pkg/llms"
 )
 
 type LLM struct {
 	base.BaseNode
 	langchainllms.Model
 	Instance *v1alpha1.LLM
+	// LLMGroup is set when the node refers to a LLMGroup instead of a LLM
+	LLMGroup *v1alpha1.LLMGroup
+	// Members are the available members of the group
+	Members []langchainwrap.LLMGroupMember
 }
 
 func NewLLM(baseNode base.BaseNode) *LLM {
@@ -43,6 +48,9 @@ func NewLLM(baseNode base.BaseNode) *LLM {
 }
 
 func (z *LLM) Init(ctx context.Context, cli client.Client, _ map[string]any) error {
+	if z.Kind() == "llmgroup" {
+		return z.initGroup(ctx, cli)
+	}
 	instance := &v1alpha1.LLM{}
 	if err := cli.Get(ctx, types.NamespacedName{Namespace: z.RefNamespace(), Name: z.Ref.Name}, instance); err != nil {
 		return fmt.Errorf("can't find the llm in cluster: %w", err)
@@ -56,6 +64,21 @@ func (z *LLM) Init(ctx context.Context, cli client.Client, _ map[string]any) err
 	return nil
 }
 
+func (z *LLM) initGroup(ctx context.Context, cli client.Client) error {
+	group := &v1alpha1.LLMGroup{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: z.RefNamespace(), Name: z.Ref.Name}, group); err != nil {
+		return fmt.Errorf("can't find the llmgroup in cluster: %w", err)
+	}
+	llm, members, err := langchainwrap.GetLangchainLLMGroup(ctx, group, cli)
+	if err != nil {
+		return fmt.Errorf("can't convert to langchain llm: %w", err)
+	}
+	z.Model = llm
+	z.LLMGroup = group
+	z.Members = members
+	return nil
+}
+
 func (z *LLM) Run(ctx context.Context, _ client.Client, args map[string]any) (map[string]any, error) {
 	args[base.LangchaingoLLMKeyInArg] = z
 	logger := klog.FromContext(ctx)
@@ -63,6 +86,53 @@ func (z *LLM) Run(ctx context.Context, _ client.Client, args map[string]any) (ma
 	return args, nil
 }
 
+// SupportsFunctionCalling returns whether the model used by this node supports native function calling
+func (z *LLM) SupportsFunctionCalling() bool {
+	if z.LLMGroup != nil {
+		// any member may serve the request, so all of them must support it
+		for _, m := range z.Members {
+			if !m.LLM.SupportsFunctionCalling(m.Model) {
+				return false
+			}
+		}
+		return len(z.Members) > 0
+	}
+	return z.Instance.SupportsFunctionCalling("")
+}
+
+// SupportsVision returns whether the model used by this node accepts images as input
+func (z *LLM) SupportsVision() bool {
+	if z.LLMGroup != nil {
+		// any member may serve the request, so all of them must accept the images in the same format
+		for _, m := range z.Members {
+			if !m.LLM.SupportsVision(m.Model) || (m.LLM.Spec.Type == llms.Gemini) != (z.Members[0].LLM.Spec.Type == llms.Gemini) {
+				return false
+			}
+		}
+		return len(z.Members) > 0
+	}
+	return z.Instance.SupportsVision("")
+}
+
+// ImageParts returns the content parts of the images in the format accepted by the model
+func (z *LLM) ImageParts(images []base.Image) []langchainllms.ContentPart {
+	llmType := llms.Unknown
+	switch {
+	case z.LLMGroup != nil && len(z.Members) > 0:
+		llmType = z.Members[0].LLM.Spec.Type
+	case z.Instance != nil:
+		llmType = z.Instance.Spec.Type
+	}
+	parts := make([]langchainllms.ContentPart, 0, len(images))
+	for _, image := range images {
+		parts = append(parts, llms.ImagePart(llmType, image.MIMEType, image.Data))
+	}
+	return parts
+}
+
 func (z *LLM) Ready() (isReady bool, msg string) {
+	if z.LLMGroup != nil {
+		return z.LLMGroup.Status.IsReadyOrGetReadyMessage()
+	}
 	return z.Instance.Status.IsReadyOrGetReadyMessage()
 }
@@ -148,6 +148,18 @@ func ConvertDocuments(ctx context.Context, docs []langchaingoschema.Document, re
 				content = strings.TrimPrefix(strings.TrimSuffix(string(a), "\""), "\"")
 			}
 		}
+		title, ok := doc.Metadata[documentloaders.TitleCol].(string)
+		if !ok {
+			if a, ok := doc.Metadata[documentloaders.TitleCol].([]byte); ok {
+				title = strings.TrimPrefix(strings.TrimSuffix(string(a), "\""), "\"")
+			}
+		}
+		url, ok := doc.Metadata[documentloaders.URLCol].(string)
+		if !ok {
+			if a, ok := doc.Metadata[documentloaders.URLCol].([]byte); ok {
+				url = strings.TrimPrefix(strings.TrimSuffix(string(a), "\""), "\"")
+			}
+		}
 		rerankScore, _ := doc.Metadata[RerankScoreCol].(float32)
 		refs = append(refs, Reference{
 			Question:     pageContent,
@@ -158,6 +170,8 @@ func ConvertDocuments(ctx context.Context, docs []langchaingoschema.Document, re
 			FileName:     filename,
 			PageNumber:   page,
 			Content:      content,
+			Title:        title,
+			URL:          url,
 			Metadata:     doc.Metadata,
 			RerankScore:  rerankScore,
 		})
@@ -161,7 +161,7 @@ func GenerateKnowledgebaseRetriever(ctx context.Context, cli client.Client, know
 		return nil, finish, fmt.Errorf("can't get relevant documents: %w", err)
 	}
 	// pgvector get score means vector distance, similarity = 1 - vector distance
-	// chroma get score means similarity
+	// chroma and the other vectorstores get score means similarity
 	// we want similarity finally.
 	if vectorStore.Spec.Type() == v1alpha1.VectorStoreTypePGVector {
 		for i := range docs {
@@ -0,0 +1,194 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/tools"
+	"github.com/tmc/langchaingo/tools/scraper"
+
+	"github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/tools/bingsearch"
+	"github.com/kubeagi/arcadia/pkg/tools/weather"
+)
+
+// Schema is the subset of json schema used to describe the params and arguments of tools
+type Schema struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+}
+
+// Definition describes the schemas of a tool
+type Definition struct {
+	// Params is the schema of the params of the tool in the Agent CRD
+	Params *Schema
+	// Arguments is the schema of the arguments when the tool is called by native function calling
+	Arguments *Schema
+	// Input is the argument passed to the tool as its input
+	Input string
+}
+
+func objectSchema(required []string, properties map[string]*Schema) *Schema {
+	return &Schema{Type: "object", Properties: properties, Required: required}
+}
+
+var definitions = map[string]Definition{
+	bingsearch.ToolName: {
+		Params: objectSchema([]string{bingsearch.ParamAPIKey}, map[string]*Schema{
+			bingsearch.ParamAPIKey:      {Type: "string", Description: "The api key of bing search"},
+			bingsearch.ParamCount:       {Type: "integer", Description: "The number of search results"},
+			bingsearch.ParamScraperPage: {Type: "boolean", Description: "Whether to scrape the pages of search results"},
+		}),
+		Arguments: objectSchema([]string{"query"}, map[string]*Schema{
+			"query": {Type: "string", Description: "The keywords to search on the internet"},
+		}),
+		Input: "query",
+	},
+	weather.ToolName: {
+		Params: objectSchema([]string{"apiKey"}, map[string]*Schema{
+			"apiKey": {Type: "string", Description: "The api key of the weather api"},
+		}),
+		Arguments: objectSchema([]string{"city"}, map[string]*Schema{
+			"city": {Type: "string", Description: "The city to query the weather of, like Beijing"},
+		}),
+		Input: "city",
+	},
+	tools.Calculator{}.Name(): {
+		Params: objectSchema(nil, nil),
+		Arguments: objectSchema([]string{"expression"}, map[string]*Schema{
+			"expression": {Type: "string", Description: "A valid mathematical expression, like 3 * (4 + 5)"},
+		}),
+		Input: "expression",
+	},
+	scraper.Scraper{}.Name(): {
+		Params: objectSchema(nil, map[string]*Schema{
+			"delay":                {Type: "integer", Description: "The delay between two requests in seconds"},
+			"async":                {Type: "boolean", Description: "Whether to scrape pages asynchronously"},
+			"handleLinks":          {Type: "boolean", Description: "Whether to follow the links in the page"},
+			"blacklist":            {Type: "string", Description: "The urls not to scrape, separated by commas"},
+			"maxScrapedDataLength": {Type: "integer", Description: "The max length of the scraped data"},
+		}),
+		Arguments: objectSchema([]string{"url"}, map[string]*Schema{
+			"url": {Type: "string", Description: "The url of the web page to scrape"},
+		}),
+		Input: "url",
+	},
+}
+
+// GetDefinition returns the definition of the tool
+func GetDefinition(name string) (Definition, bool) {
+	d, ok := definitions[name]
+	return d, ok
+}
+
+// ValidateParams checks the params of the tool in the Agent CRD against its schema
+func ValidateParams(tool v1alpha1.Tool) error {
+	d, ok := GetDefinition(tool.Name)
+	if !ok {
+		return fmt.Errorf("no tool found with name: %s", tool.Name)
+	}
+	for _, required := range d.Params.Required {
+		if tool.Params[required] == "" {
+			return fmt.Errorf("param %s of tool %s is required", required, tool.Name)
+		}
+	}
+	for name, value := range tool.Params {
+		s, ok := d.Params.Properties[name]
+		if !ok || value == "" {
+			continue
+		}
+		var err error
+		switch s.Type {
+		case "integer":
+			_, err = strconv.Atoi(value)
+		case "number":
+			_, err = strconv.ParseFloat(value, 64)
+		case "boolean":
+			_, err = strconv.ParseBool(value)
+		}
+		if err != nil {
+			return fmt.Errorf("param %s of tool %s should be %s, got %q", name, tool.Name, s.Type, value)
+		}
+	}
+	return nil
+}
+
+var invalidFunctionNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// FunctionName converts the tool name to a valid function name, which only contains letters, digits, `_` and `-`
+func FunctionName(toolName string) string {
+	name := invalidFunctionNameChars.ReplaceAllString(toolName, "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// defaultArguments is the schema of tools without a definition, whose input is a string
+func defaultArguments(description string) *Schema {
+	return objectSchema([]string{"input"}, map[string]*Schema{
+		"input": {Type: "string", Description: description},
+	})
+}
+
+// FunctionDefinitions returns the function definitions of tools for native function calling
+func FunctionDefinitions(ts []tools.Tool) []llms.FunctionDefinition {
+	res := make([]llms.FunctionDefinition, 0, len(ts))
+	for _, t := range ts {
+		arguments := defaultArguments(t.Description())
+		if d, ok := GetDefinition(t.Name()); ok {
+			arguments = d.Arguments
+		}
+		res = append(res, llms.FunctionDefinition{
+			Name:        FunctionName(t.Name()),
+			Description: t.Description(),
+			Parameters:  arguments,
+		})
+	}
+	return res
+}
+
+// ToolInput returns the input of the tool from the arguments of a function call
+func ToolInput(toolName, arguments string) string {
+	args := make(map[string]any)
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return arguments
+	}
+	input := "input"
+	if d, ok := GetDefinition(toolName); ok {
+		input = d.Input
+	}
+	if v, ok := args[input].(string); ok {
+		return v
+	}
+	// use the only argument if the model names it in another way
+	if len(args) == 1 {
+		for _, v := range args {
+			if s, ok := v.(string); ok {
+				return s
+			}
+		}
+	}
+	return arguments
+}
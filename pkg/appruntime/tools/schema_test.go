@@ -0,0 +1,81 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/tools/bingsearch"
+	"github.com/kubeagi/arcadia/pkg/tools/weather"
+)
+
+func TestValidateParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		tool    v1alpha1.Tool
+		wantErr bool
+	}{
+		{"valid", v1alpha1.Tool{Name: bingsearch.ToolName, Params: map[string]string{bingsearch.ParamAPIKey: "key", bingsearch.ParamCount: "5"}}, false},
+		{"missing required", v1alpha1.Tool{Name: bingsearch.ToolName, Params: map[string]string{bingsearch.ParamCount: "5"}}, true},
+		{"wrong type", v1alpha1.Tool{Name: bingsearch.ToolName, Params: map[string]string{bingsearch.ParamAPIKey: "key", bingsearch.ParamScraperPage: "yes"}}, true},
+		{"unknown tool", v1alpha1.Tool{Name: "unknown"}, true},
+	}
+	for _, test := range tests {
+		if err := ValidateParams(test.tool); (err != nil) != test.wantErr {
+			t.Errorf("%s: expect error %v, got %v", test.name, test.wantErr, err)
+		}
+	}
+}
+
+func TestToolInput(t *testing.T) {
+	tests := []struct {
+		tool      string
+		arguments string
+		want      string
+	}{
+		{weather.ToolName, `{"city": "Beijing"}`, "Beijing"},
+		{weather.ToolName, `{"location": "Beijing"}`, "Beijing"},
+		{"unknown", `{"input": "hello"}`, "hello"},
+		{"unknown", `not json`, "not json"},
+	}
+	for _, test := range tests {
+		if got := ToolInput(test.tool, test.arguments); got != test.want {
+			t.Errorf("%s %s: expect %q, got %q", test.tool, test.arguments, test.want, got)
+		}
+	}
+}
+
+func TestFunctionName(t *testing.T) {
+	if got := FunctionName("Bing Search.v1"); got != "Bing_Search_v1" {
+		t.Errorf("expect Bing_Search_v1, got %s", got)
+	}
+}
+
+func TestInitTools(t *testing.T) {
+	ts, err := InitTools(context.Background(), []v1alpha1.Tool{{Name: weather.ToolName, Params: map[string]string{"apiKey": "key"}}, {Name: "unknown"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 || ts[0].Name() != weather.ToolName {
+		t.Errorf("expect the weather tool only, got %v", ts)
+	}
+	if _, err = InitTools(context.Background(), []v1alpha1.Tool{{Name: weather.ToolName}}); err == nil {
+		t.Error("expect an error for the missing api key")
+	}
+}
@@ -32,10 +32,16 @@ import (
 	"github.com/kubeagi/arcadia/pkg/tools/weather"
 )
 
-func InitTools(ctx context.Context, specTools []v1alpha1.Tool) []tools.Tool {
+// InitTools creates the tools of the agent, unknown tools are skipped and invalid params of a known tool are returned as an error
+func InitTools(ctx context.Context, specTools []v1alpha1.Tool) ([]tools.Tool, error) {
 	logger := klog.FromContext(ctx)
 	allowedTools := make([]tools.Tool, 0, len(specTools))
 	for _, toolSpec := range specTools {
+		if _, ok := GetDefinition(toolSpec.Name); ok {
+			if err := ValidateParams(toolSpec); err != nil {
+				return nil, err
+			}
+		}
 		switch toolSpec.Name {
 		case bingsearch.ToolName:
 			client, err := bingsearch.New(&toolSpec)
@@ -107,7 +113,7 @@ func InitTools(ctx context.Context, specTools []v1alpha1.Tool) []tools.Tool {
 			klog.Errorf("no tool found with name: %s", toolSpec.Name)
 		}
 	}
-	return allowedTools
+	return allowedTools, nil
 }
 
 // FIXME: should add web reference into chat result
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"fmt"
+
+	"k8s.io/utils/strings/slices"
+
+	chainv1alpha1 "github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	"github.com/kubeagi/arcadia/pkg/appruntime/chain"
+	"github.com/kubeagi/arcadia/pkg/appruntime/llm"
+)
+
+// ValidateModels checks the configs of nodes against the models of the llms they use,
+// like whether the model is provided by the llm and whether the max tokens fit in the context length.
+// Application must be inited before validation.
+func (a *Application) ValidateModels() error {
+	for _, n := range a.Nodes {
+		l, ok := n.(*llm.LLM)
+		if !ok || (l.Instance == nil && l.LLMGroup == nil) {
+			continue
+		}
+		for _, next := range n.GetNextNode() {
+			config := chainConfig(next)
+			if config == nil {
+				continue
+			}
+			if l.LLMGroup == nil {
+				if err := ValidateChainConfig(l.Instance, *config); err != nil {
+					return fmt.Errorf("node %s: %w", next.Name(), err)
+				}
+				continue
+			}
+			// the model of each member overrides the model in the chain config
+			for _, m := range l.Members {
+				memberConfig := *config
+				memberConfig.Model = m.Model
+				if err := ValidateChainConfig(m.LLM, memberConfig); err != nil {
+					return fmt.Errorf("node %s: %w", next.Name(), err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// chainConfig returns the chain config of the node, nil if the node is not a chain
+func chainConfig(n base.Node) *chainv1alpha1.CommonChainConfig {
+	switch c := n.(type) {
+	case *chain.LLMChain:
+		if c.Instance != nil {
+			return &c.Instance.Spec.CommonChainConfig
+		}
+	case *chain.RetrievalQAChain:
+		if c.Instance != nil {
+			return &c.Instance.Spec.CommonChainConfig
+		}
+	case *chain.APIChain:
+		if c.Instance != nil {
+			return &c.Instance.Spec.CommonChainConfig
+		}
+	}
+	return nil
+}
+
+// ValidateChainConfig checks the chain config against the capability of the model it uses
+func ValidateChainConfig(instance *arcadiav1alpha1.LLM, config chainv1alpha1.CommonChainConfig) error {
+	if config.Model != "" {
+		if models := instance.GetModelList(); len(models) != 0 && !slices.Contains(models, config.Model) {
+			return fmt.Errorf("model %s is not provided by llm %s, available models: %v", config.Model, instance.Name, models)
+		}
+	}
+	capability, ok := instance.GetModelCapability(config.Model)
+	if !ok || capability.ContextLength == 0 {
+		return nil
+	}
+	if config.MaxTokens >= capability.ContextLength {
+		return fmt.Errorf("maxTokens %d should be less than the context length %d of model %s", config.MaxTokens, capability.ContextLength, capability.Name)
+	}
+	if config.Memory.MaxTokenLimit > 0 && config.Memory.MaxTokenLimit+config.MaxTokens > capability.ContextLength {
+		return fmt.Errorf("memory maxTokenLimit %d plus maxTokens %d exceeds the context length %d of model %s", config.Memory.MaxTokenLimit, config.MaxTokens, capability.ContextLength, capability.Name)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"testing"
+
+	chainv1alpha1 "github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/llms"
+)
+
+func TestValidateChainConfig(t *testing.T) {
+	llm := &arcadiav1alpha1.LLM{
+		Spec: arcadiav1alpha1.LLMSpec{
+			Type:     llms.OpenAICompatible,
+			Provider: arcadiav1alpha1.Provider{Endpoint: &arcadiav1alpha1.Endpoint{URL: "http://localhost:11434/v1"}},
+			ModelCapabilities: []arcadiav1alpha1.ModelCapability{
+				{Name: "qwen:7b", ContextLength: 8192},
+				{Name: "llama3"},
+			},
+		},
+	}
+	llm.Name = "ollama"
+	tests := []struct {
+		name    string
+		config  chainv1alpha1.CommonChainConfig
+		wantErr bool
+	}{
+		{"default model fits", chainv1alpha1.CommonChainConfig{MaxTokens: 2048}, false},
+		{"unknown model", chainv1alpha1.CommonChainConfig{Model: "gpt-4", MaxTokens: 2048}, true},
+		{"max tokens exceed context length", chainv1alpha1.CommonChainConfig{Model: "qwen:7b", MaxTokens: 8192}, true},
+		{"memory exceeds context length", chainv1alpha1.CommonChainConfig{MaxTokens: 4096, Memory: chainv1alpha1.Memory{MaxTokenLimit: 5000}}, true},
+		{"context length unknown", chainv1alpha1.CommonChainConfig{Model: "llama3", MaxTokens: 100000}, false},
+	}
+	for _, test := range tests {
+		if err := ValidateChainConfig(llm, test.config); (err != nil) != test.wantErr {
+			t.Errorf("%s: expect error %v, got %v", test.name, test.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/minio/minio-go/v7"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+var (
+	ErrGitNoSuchFile  = errors.New("no such file in git repository")
+	ErrGitNoReference = errors.New("no such reference in git repository")
+	ErrGitReadOnly    = errors.New("git datasource is read only")
+)
+
+var (
+	_ Datasource = (*Git)(nil)
+
+	gitReposMutex sync.Mutex
+	gitRepos      = make(map[string]*Git)
+)
+
+// GetGit returns the git repository of the datasource, which is cached until the datasource is updated,
+// so files are only fetched again when the commit of the reference changes.
+func GetGit(ctx context.Context, c client.Client, datasource *v1alpha1.Datasource) (*Git, error) {
+	if datasource.Spec.Type() != v1alpha1.DatasourceTypeGit {
+		return nil, ErrUnknowDatasourceType
+	}
+	gitReposMutex.Lock()
+	defer gitReposMutex.Unlock()
+	g, ok := gitRepos[string(datasource.GetUID())]
+	if ok && g.Ref.GetGeneration() == datasource.GetGeneration() {
+		return g, nil
+	}
+	endpoint := datasource.Spec.Endpoint.DeepCopy()
+	if endpoint.AuthSecret != nil {
+		endpoint.AuthSecret.WithNameSpace(datasource.Namespace)
+	}
+	g, err := NewGit(ctx, c, endpoint, datasource.Spec.Git)
+	if err != nil {
+		return nil, err
+	}
+	g.Ref = datasource.DeepCopy()
+	gitRepos[string(datasource.GetUID())] = g
+	return g, nil
+}
+
+func RemoveGit(datasource v1alpha1.Datasource) {
+	gitReposMutex.Lock()
+	delete(gitRepos, string(datasource.GetUID()))
+	gitReposMutex.Unlock()
+}
+
+// Git reads files of a reference of a git repository.
+// The repository is cloned into memory with depth 1, and cloned again once the reference points to a new commit.
+type Git struct {
+	Ref *v1alpha1.Datasource
+
+	url       string
+	auth      transport.AuthMethod
+	reference string
+	paths     []*regexp.Regexp
+
+	mu       sync.Mutex
+	snapshot *gitSnapshot
+}
+
+// gitSnapshot is the files of a commit
+type gitSnapshot struct {
+	repo   *git.Repository
+	commit *object.Commit
+	// refHash is the hash the reference points to, which is the annotated tag instead of the commit for tags
+	refHash plumbing.Hash
+	files   map[string]*object.File
+}
+
+func NewGit(ctx context.Context, c client.Client, endpoint *v1alpha1.Endpoint, config *v1alpha1.Git) (*Git, error) {
+	if endpoint == nil || endpoint.URL == "" {
+		return nil, errors.New("no url of git repository")
+	}
+	g := &Git{url: endpoint.URL}
+	if config != nil {
+		g.reference = config.Reference
+		for _, p := range config.Paths {
+			re, err := globRegexp(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %s: %w", p, err)
+			}
+			g.paths = append(g.paths, re)
+		}
+	}
+	if endpoint.AuthSecret != nil {
+		if endpoint.AuthSecret.Namespace == nil {
+			return nil, errors.New("no namespace found for endpoint.authsecret")
+		}
+		data, err := endpoint.AuthData(ctx, *endpoint.AuthSecret.Namespace, c)
+		if err != nil {
+			return nil, err
+		}
+		if g.auth, err = gitAuth(data, config); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+func gitAuth(data map[string][]byte, config *v1alpha1.Git) (transport.AuthMethod, error) {
+	if key := data[v1alpha1.GitSSHPrivateKey]; len(key) > 0 {
+		auth, err := gitssh.NewPublicKeys(gitssh.DefaultUsername, key, string(data[v1alpha1.GitPassword]))
+		if err != nil {
+			return nil, err
+		}
+		// the host is verified with known_hosts in the secret, unless it's ignored explicitly
+		switch knownHosts := data[v1alpha1.GitKnownHosts]; {
+		case len(knownHosts) > 0:
+			auth.HostKeyCallback = knownHostsCallback(knownHosts)
+		case config != nil && config.InsecureIgnoreHostKey:
+			auth.HostKeyCallback = ssh.InsecureIgnoreHostKey() //nolint:gosec
+		default:
+			return nil, fmt.Errorf("%s is required in the auth secret to verify the ssh host key", v1alpha1.GitKnownHosts)
+		}
+		return auth, nil
+	}
+	if password := string(data[v1alpha1.GitPassword]); password != "" {
+		username := string(data[v1alpha1.GitUsername])
+		if username == "" {
+			// tokens work with any username
+			username = gitssh.DefaultUsername
+		}
+		return &githttp.BasicAuth{Username: username, Password: password}, nil
+	}
+	return nil, nil
+}
+
+func knownHostsCallback(data []byte) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		host := knownhosts.Normalize(hostname)
+		rest := data
+		for len(rest) > 0 {
+			_, hosts, pubKey, _, next, err := ssh.ParseKnownHosts(rest)
+			if err != nil {
+				break
+			}
+			rest = next
+			if !bytes.Equal(pubKey.Marshal(), key.Marshal()) {
+				continue
+			}
+			for _, h := range hosts {
+				if knownhosts.Normalize(h) == host {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("host key of %s is not in known_hosts", hostname)
+	}
+}
+
+// Stat checks the repository is accessible and the reference exists
+func (g *Git) Stat(ctx context.Context, _ any) error {
+	_, _, err := g.resolve(ctx)
+	return err
+}
+
+// Remove is not supported, as the repository is read only
+func (g *Git) Remove(ctx context.Context, info any) error {
+	return ErrGitReadOnly
+}
+
+func (g *Git) ReadFile(ctx context.Context, info any) (io.ReadCloser, error) {
+	file, _, err := g.file(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	return file.Reader()
+}
+
+// StatFile returns the minio.ObjectInfo of a file in the current commit, the etag is the hash of the blob
+func (g *Git) StatFile(ctx context.Context, info any) (any, error) {
+	file, commit, err := g.file(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	return gitObjectInfo(file, commit), nil
+}
+
+func (g *Git) GetTags(ctx context.Context, info any) (map[string]string, error) {
+	if _, _, err := g.file(ctx, info); err != nil {
+		return nil, err
+	}
+	return map[string]string{}, nil
+}
+
+// ListObjects fetches the latest commit of the reference and lists files matching the paths as []minio.ObjectInfo,
+// info should be of type minio.ListObjectsOptions.
+// As the etag of a file is the hash of its blob, only changed files are embedded again after a new commit.
+func (g *Git) ListObjects(ctx context.Context, _ string, info any) (any, error) {
+	result := make([]minio.ObjectInfo, 0)
+	listOption, ok := info.(minio.ListObjectsOptions)
+	if !ok {
+		return result, fmt.Errorf("info should be of type ListObjectOptions")
+	}
+	snapshot, err := g.update(ctx)
+	if err != nil {
+		return result, err
+	}
+	for name, file := range snapshot.files {
+		if strings.HasPrefix(name, listOption.Prefix) {
+			result = append(result, gitObjectInfo(file, snapshot.commit))
+		}
+	}
+	return result, nil
+}
+
+// Commit returns the hash of the commit which files are read from, empty if the repository is not fetched yet
+func (g *Git) Commit() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.snapshot == nil {
+		return ""
+	}
+	return g.snapshot.commit.Hash.String()
+}
+
+func (g *Git) file(ctx context.Context, info any) (*object.File, *object.Commit, error) {
+	ossInfo, ok := info.(*v1alpha1.OSS)
+	if !ok || ossInfo == nil || ossInfo.Object == "" {
+		return nil, nil, ErrOSSNoConfig
+	}
+	g.mu.Lock()
+	snapshot := g.snapshot
+	g.mu.Unlock()
+	if snapshot == nil {
+		var err error
+		if snapshot, err = g.update(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	file, ok := snapshot.files[ossInfo.Object]
+	if !ok {
+		return nil, nil, ErrGitNoSuchFile
+	}
+	return file, snapshot.commit, nil
+}
+
+// resolve returns the name of the reference and the hash it points to in the remote repository
+func (g *Git) resolve(ctx context.Context) (plumbing.ReferenceName, plumbing.Hash, error) {
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{g.url}})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: g.auth})
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	candidates := []plumbing.ReferenceName{plumbing.HEAD}
+	if g.reference != "" {
+		candidates = []plumbing.ReferenceName{
+			plumbing.ReferenceName(g.reference),
+			plumbing.NewBranchReferenceName(g.reference),
+			plumbing.NewTagReferenceName(g.reference),
+		}
+	}
+	for _, name := range candidates {
+		for _, ref := range refs {
+			if ref.Name() != name {
+				continue
+			}
+			if ref.Type() == plumbing.SymbolicReference {
+				name = ref.Target()
+				for _, target := range refs {
+					if target.Name() == name {
+						return name, target.Hash(), nil
+					}
+				}
+				return "", plumbing.ZeroHash, ErrGitNoReference
+			}
+			return name, ref.Hash(), nil
+		}
+	}
+	return "", plumbing.ZeroHash, ErrGitNoReference
+}
+
+// update clones the repository again if the reference points to a new commit
+func (g *Git) update(ctx context.Context) (*gitSnapshot, error) {
+	name, hash, err := g.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	snapshot := g.snapshot
+	g.mu.Unlock()
+	if snapshot != nil && snapshot.refHash == hash {
+		return snapshot, nil
+	}
+
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{
+		URL:           g.url,
+		Auth:          g.auth,
+		ReferenceName: name,
+		SingleBranch:  true,
+		Depth:         1,
+		Tags:          git.NoTags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		// an annotated tag
+		var tag *object.Tag
+		if tag, err = repo.TagObject(head.Hash()); err == nil {
+			commit, err = tag.Commit()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	snapshot = &gitSnapshot{repo: repo, commit: commit, refHash: hash, files: make(map[string]*object.File)}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if f.Mode == filemode.Regular || f.Mode == filemode.Executable {
+			if g.matches(f.Name) {
+				snapshot.files[f.Name] = f
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	g.snapshot = snapshot
+	g.mu.Unlock()
+	return snapshot, nil
+}
+
+func (g *Git) matches(name string) bool {
+	if len(g.paths) == 0 {
+		return true
+	}
+	for _, re := range g.paths {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func gitObjectInfo(file *object.File, commit *object.Commit) minio.ObjectInfo {
+	return minio.ObjectInfo{
+		Key:          file.Name,
+		ETag:         file.Hash.String(),
+		Size:         file.Size,
+		LastModified: commit.Committer.When,
+		VersionID:    commit.Hash.String(),
+	}
+}
+
+// globRegexp converts a glob pattern to a regular expression matching the whole path.
+// `*` and `?` don't match `/`, `**` matches any number of directories,
+// and a pattern ending with `/` matches all files in the directory.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// `**/` matches zero or more directories
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
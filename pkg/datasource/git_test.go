@@ -0,0 +1,242 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/minio/minio-go/v7"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// commitFiles writes the files to the worktree and commits them
+func commitFiles(t *testing.T, repo *git.Repository, dir string, files map[string]string) {
+	t.Helper()
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = w.Commit("update", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func listGit(t *testing.T, g *Git) map[string]minio.ObjectInfo {
+	t.Helper()
+	res, err := g.ListObjects(context.Background(), "", minio.ListObjectsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := make(map[string]minio.ObjectInfo)
+	for _, o := range res.([]minio.ObjectInfo) {
+		objects[o.Key] = o
+	}
+	return objects
+}
+
+func TestGit(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, dir, map[string]string{
+		"README.md":          "# readme",
+		"docs/intro.md":      "intro",
+		"docs/guide/deep.md": "deep",
+		"docs/image.png":     "png",
+		"main.go":            "package main",
+	})
+
+	g, err := NewGit(context.Background(), nil, &v1alpha1.Endpoint{URL: dir}, &v1alpha1.Git{Paths: []string{"docs/**/*.md", "README.md"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = g.Stat(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	objects := listGit(t, g)
+	names := make([]string, 0, len(objects))
+	for name := range objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) != 3 || names[0] != "README.md" || names[1] != "docs/guide/deep.md" || names[2] != "docs/intro.md" {
+		t.Fatalf("unexpected files %v", names)
+	}
+	file, err := g.ReadFile(context.Background(), &v1alpha1.OSS{Object: "docs/intro.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(file)
+	file.Close()
+	if string(data) != "intro" {
+		t.Errorf("unexpected content %q", data)
+	}
+	if _, err = g.StatFile(context.Background(), &v1alpha1.OSS{Object: "main.go"}); err != ErrGitNoSuchFile {
+		t.Errorf("files not matching the paths should not be read, got %v", err)
+	}
+
+	// only the etag of the changed file changes in a new commit
+	commit := g.Commit()
+	commitFiles(t, repo, dir, map[string]string{"docs/intro.md": "new intro"})
+	updated := listGit(t, g)
+	if g.Commit() == commit {
+		t.Error("the new commit should be fetched")
+	}
+	if updated["docs/intro.md"].ETag == objects["docs/intro.md"].ETag {
+		t.Error("etag of the changed file should change")
+	}
+	if updated["README.md"].ETag != objects["README.md"].ETag {
+		t.Error("etag of the unchanged file should not change")
+	}
+
+	// read a tag
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.CreateTag("v1", head.Hash(), &git.CreateTagOptions{Tagger: &object.Signature{Name: "test", When: time.Now()}, Message: "v1"}); err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, dir, map[string]string{"README.md": "changed after v1"})
+	g, err = NewGit(context.Background(), nil, &v1alpha1.Endpoint{URL: dir}, &v1alpha1.Git{Reference: "v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tagged := listGit(t, g); tagged["README.md"].ETag != objects["README.md"].ETag || len(tagged) != 5 {
+		t.Errorf("files of the tag should be read, got %v", tagged)
+	}
+	if g.Commit() != head.Hash().String() {
+		t.Errorf("commit of the tag should be %s, got %s", head.Hash(), g.Commit())
+	}
+}
+
+func newSSHKey(t *testing.T) (privateKey []byte, publicKey ssh.PublicKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if publicKey, err = ssh.NewPublicKey(pub); err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(block), publicKey
+}
+
+func TestGitAuth(t *testing.T) {
+	privateKey, _ := newSSHKey(t)
+	_, hostKey := newSSHKey(t)
+	_, otherKey := newSSHKey(t)
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+	knownHosts := []byte("github.com " + string(ssh.MarshalAuthorizedKey(hostKey)))
+
+	if _, err := gitAuth(map[string][]byte{v1alpha1.GitSSHPrivateKey: privateKey}, nil); err == nil {
+		t.Error("ssh auth without known_hosts should be rejected")
+	}
+
+	auth, err := gitAuth(map[string][]byte{v1alpha1.GitSSHPrivateKey: privateKey, v1alpha1.GitKnownHosts: knownHosts}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback := auth.(*gitssh.PublicKeys).HostKeyCallback
+	if err = callback("github.com:22", addr, hostKey); err != nil {
+		t.Errorf("host key in known_hosts should be accepted: %s", err)
+	}
+	if err = callback("github.com:22", addr, otherKey); err == nil {
+		t.Error("host key not in known_hosts should be rejected")
+	}
+	if err = callback("gitlab.com:22", addr, hostKey); err == nil {
+		t.Error("host not in known_hosts should be rejected")
+	}
+
+	auth, err = gitAuth(map[string][]byte{v1alpha1.GitSSHPrivateKey: privateKey}, &v1alpha1.Git{InsecureIgnoreHostKey: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = auth.(*gitssh.PublicKeys).HostKeyCallback("gitlab.com:22", addr, otherKey); err != nil {
+		t.Errorf("host key should be ignored in insecure mode: %s", err)
+	}
+
+	auth, err = gitAuth(map[string][]byte{v1alpha1.GitPassword: []byte("token")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if basic, ok := auth.(*githttp.BasicAuth); !ok || basic.Username != gitssh.DefaultUsername || basic.Password != "token" {
+		t.Errorf("unexpected basic auth %v", auth)
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"*.md", "README.md", true},
+		{"*.md", "docs/a.md", false},
+		{"**/*.md", "docs/a.md", true},
+		{"**/*.md", "a.md", true},
+		{"docs/**/*.md", "docs/a.md", true},
+		{"docs/**/*.md", "docs/x/y/a.md", true},
+		{"docs/**/*.md", "other/docs/a.md", false},
+		{"docs/", "docs/x/y.go", true},
+		{"doc?.txt", "docs.txt", true},
+		{"a.md", "a_md", false},
+	}
+	for _, test := range tests {
+		re, err := globRegexp(test.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if re.MatchString(test.name) != test.match {
+			t.Errorf("glob %s matching %s should be %v", test.pattern, test.name, test.match)
+		}
+	}
+}
@@ -16,16 +16,30 @@ limitations under the License.
 package datasource
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"strings"
 	"sync"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/minio/minio-go/v7"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/documentloaders"
+)
+
+var (
+	ErrPGNoSuchRecord = errors.New("no such record in postgresql")
+	ErrPGReadOnly     = errors.New("postgresql datasource is read only")
 )
 
 var (
@@ -201,30 +215,262 @@ func newPostgreSQL(ctx context.Context, c client.Client, config *v1alpha1.Postgr
 }
 
 func (p *PostgreSQL) Stat(ctx context.Context, _ any) error {
-	return p.Ping(ctx)
+	if err := p.Ping(ctx); err != nil {
+		return err
+	}
+	// make sure the tables and columns exist
+	for i := range p.tables() {
+		query, err := recordQuery(&p.tables()[i], true)
+		if err != nil {
+			return err
+		}
+		if _, err = p.Exec(ctx, query+" LIMIT 0"); err != nil {
+			return fmt.Errorf("invalid table %s: %w", p.tables()[i].Name, err)
+		}
+	}
+	return nil
 }
 
+// Remove is not supported, as rows are read only
 func (p *PostgreSQL) Remove(ctx context.Context, info any) error {
-	// TODO implement me
-	panic("implement me")
+	return ErrPGReadOnly
 }
 
+// ReadFile reads a row as a json file of documentloaders.Record
 func (p *PostgreSQL) ReadFile(ctx context.Context, info any) (io.ReadCloser, error) {
-	// TODO implement me
-	panic("implement me")
+	record, _, err := p.getRecord(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
 }
 
+// StatFile returns the minio.ObjectInfo of a row
 func (p *PostgreSQL) StatFile(ctx context.Context, info any) (any, error) {
-	// TODO implement me
-	panic("implement me")
+	record, updatedAt, err := p.getRecord(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	return recordObjectInfo(info.(*v1alpha1.OSS).Object, record, updatedAt)
 }
 
 func (p *PostgreSQL) GetTags(ctx context.Context, info any) (map[string]string, error) {
-	// TODO implement me
-	panic("implement me")
+	if _, _, err := p.parseKey(info); err != nil {
+		return nil, err
+	}
+	return map[string]string{v1alpha1.ObjectTypeTag: v1alpha1.ObjectTypeRecord}, nil
+}
+
+// ListObjects lists rows of all tables as []minio.ObjectInfo, info should be of type minio.ListObjectsOptions.
+// The etag of a row is computed from the updatedAt column if set, so only changed rows are embedded again.
+func (p *PostgreSQL) ListObjects(ctx context.Context, _ string, info any) (any, error) {
+	result := make([]minio.ObjectInfo, 0)
+	listOption, ok := info.(minio.ListObjectsOptions)
+	if !ok {
+		return result, fmt.Errorf("info should be of type ListObjectOptions")
+	}
+	for i := range p.tables() {
+		table := &p.tables()[i]
+		dir := table.Name + "/"
+		if !strings.HasPrefix(dir, listOption.Prefix) && !strings.HasPrefix(listOption.Prefix, dir) {
+			continue
+		}
+		// the content is only needed to compute the etag if there is no updatedAt column
+		full := table.UpdatedAtColumn == ""
+		query, err := recordQuery(table, full)
+		if err != nil {
+			return result, err
+		}
+		rows, err := p.Query(ctx, query)
+		if err != nil {
+			return result, err
+		}
+		for rows.Next() {
+			values, err := rows.Values()
+			if err != nil {
+				rows.Close()
+				return result, err
+			}
+			record, updatedAt := scanRecord(table, values, full)
+			key := recordKey(table.Name, record.ID)
+			if !strings.HasPrefix(key, listOption.Prefix) {
+				continue
+			}
+			object, err := recordObjectInfo(key, record, updatedAt)
+			if err != nil {
+				rows.Close()
+				return result, err
+			}
+			if !full {
+				object.Size = 0
+			}
+			result = append(result, object)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (p *PostgreSQL) tables() []v1alpha1.PostgreSQLTable {
+	if p.Ref == nil || p.Ref.Spec.PostgreSQL == nil {
+		return nil
+	}
+	return p.Ref.Spec.PostgreSQL.Tables
+}
+
+// parseKey returns the table and the row id of a file named <table>/<id>.json
+func (p *PostgreSQL) parseKey(info any) (*v1alpha1.PostgreSQLTable, string, error) {
+	ossInfo, ok := info.(*v1alpha1.OSS)
+	if !ok || ossInfo == nil || ossInfo.Object == "" {
+		return nil, "", ErrOSSNoConfig
+	}
+	name, file, ok := strings.Cut(ossInfo.Object, "/")
+	if !ok || !strings.HasSuffix(file, recordExt) {
+		return nil, "", ErrPGNoSuchRecord
+	}
+	id, err := url.PathUnescape(strings.TrimSuffix(file, recordExt))
+	if err != nil {
+		return nil, "", ErrPGNoSuchRecord
+	}
+	for i := range p.tables() {
+		if p.tables()[i].Name == name {
+			return &p.tables()[i], id, nil
+		}
+	}
+	return nil, "", ErrPGNoSuchRecord
+}
+
+func (p *PostgreSQL) getRecord(ctx context.Context, info any) (*documentloaders.Record, string, error) {
+	table, id, err := p.parseKey(info)
+	if err != nil {
+		return nil, "", err
+	}
+	query, err := recordQuery(table, true)
+	if err != nil {
+		return nil, "", err
+	}
+	rows, err := p.Query(ctx, fmt.Sprintf("%s WHERE %s::text = $1 LIMIT 1", query, quoteIdentifier(table.IDColumn)), id)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, "", err
+		}
+		return nil, "", ErrPGNoSuchRecord
+	}
+	values, err := rows.Values()
+	if err != nil {
+		return nil, "", err
+	}
+	record, updatedAt := scanRecord(table, values, true)
+	return record, updatedAt, nil
 }
 
-func (p *PostgreSQL) ListObjects(ctx context.Context, source string, info any) (any, error) {
-	// TODO implement me
-	panic("implement me")
+const recordExt = ".json"
+
+// recordKey returns the file name of a row
+func recordKey(table, id string) string {
+	return table + "/" + url.PathEscape(id) + recordExt
+}
+
+func quoteIdentifier(name string) string {
+	return pgx.Identifier{name}.Sanitize()
+}
+
+// recordQuery returns the query to read rows of the table, all columns are converted to text.
+// The columns are id, updatedAt if set, and then title, content and metadata columns if full is true.
+func recordQuery(table *v1alpha1.PostgreSQLTable, full bool) (string, error) {
+	var from string
+	switch {
+	case table.Table != "":
+		from = pgx.Identifier(strings.Split(table.Table, ".")).Sanitize()
+	case table.Query != "":
+		from = "(" + strings.TrimSuffix(strings.TrimSpace(table.Query), ";") + ") AS records"
+	default:
+		return "", fmt.Errorf("neither table nor query is set for %s", table.Name)
+	}
+	if table.IDColumn == "" || len(table.ContentColumns) == 0 {
+		return "", fmt.Errorf("idColumn and contentColumns are required for %s", table.Name)
+	}
+	columns := []string{table.IDColumn}
+	if table.UpdatedAtColumn != "" {
+		columns = append(columns, table.UpdatedAtColumn)
+	}
+	if full {
+		if table.TitleColumn != "" {
+			columns = append(columns, table.TitleColumn)
+		}
+		columns = append(columns, table.ContentColumns...)
+		columns = append(columns, table.MetadataColumns...)
+	}
+	for i := range columns {
+		columns[i] = quoteIdentifier(columns[i]) + "::text"
+	}
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), from), nil
+}
+
+// scanRecord converts values read by the query of recordQuery to a record
+func scanRecord(table *v1alpha1.PostgreSQLTable, values []any, full bool) (record *documentloaders.Record, updatedAt string) {
+	next := func() string {
+		if len(values) == 0 {
+			return ""
+		}
+		v := values[0]
+		values = values[1:]
+		if s, ok := v.(string); ok {
+			return s
+		}
+		return ""
+	}
+	record = &documentloaders.Record{ID: next()}
+	if table.UpdatedAtColumn != "" {
+		updatedAt = next()
+	}
+	if !full {
+		return record, updatedAt
+	}
+	if table.TitleColumn != "" {
+		record.Title = next()
+	}
+	contents := make([]string, 0, len(table.ContentColumns))
+	for range table.ContentColumns {
+		if content := next(); content != "" {
+			contents = append(contents, content)
+		}
+	}
+	record.Content = strings.Join(contents, "\n\n")
+	if len(table.MetadataColumns) > 0 {
+		record.Metadata = make(map[string]string, len(table.MetadataColumns))
+		for _, column := range table.MetadataColumns {
+			record.Metadata[column] = next()
+		}
+	}
+	return record, updatedAt
+}
+
+// recordObjectInfo returns the object info of a row, the etag changes once the row is updated
+func recordObjectInfo(key string, record *documentloaders.Record, updatedAt string) (minio.ObjectInfo, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+	version := []byte(updatedAt)
+	if updatedAt == "" {
+		version = data
+	}
+	return minio.ObjectInfo{
+		Key:         key,
+		ETag:        fmt.Sprintf("%x", md5.Sum(version)),
+		Size:        int64(len(data)),
+		ContentType: "application/json",
+	}, nil
 }
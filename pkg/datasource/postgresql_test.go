@@ -0,0 +1,101 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+func TestRecordQuery(t *testing.T) {
+	table := &v1alpha1.PostgreSQLTable{
+		Name:            "docs",
+		Table:           "public.docs",
+		IDColumn:        "id",
+		ContentColumns:  []string{"summary", "body"},
+		TitleColumn:     "title",
+		MetadataColumns: []string{"author"},
+		UpdatedAtColumn: "updated_at",
+	}
+	tests := []struct {
+		table    *v1alpha1.PostgreSQLTable
+		full     bool
+		expected string
+	}{
+		{table, false, `SELECT "id"::text, "updated_at"::text FROM "public"."docs"`},
+		{table, true, `SELECT "id"::text, "updated_at"::text, "title"::text, "summary"::text, "body"::text, "author"::text FROM "public"."docs"`},
+		{
+			&v1alpha1.PostgreSQLTable{Name: "faq", Query: "SELECT * FROM faq WHERE published; ", IDColumn: "id", ContentColumns: []string{"answer"}},
+			true,
+			`SELECT "id"::text, "answer"::text FROM (SELECT * FROM faq WHERE published) AS records`,
+		},
+	}
+	for _, test := range tests {
+		query, err := recordQuery(test.table, test.full)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query != test.expected {
+			t.Errorf("recordQuery = %s, expected %s", query, test.expected)
+		}
+	}
+	if _, err := recordQuery(&v1alpha1.PostgreSQLTable{Name: "empty", IDColumn: "id", ContentColumns: []string{"c"}}, true); err == nil {
+		t.Error("recordQuery should fail without table and query")
+	}
+
+	record, updatedAt := scanRecord(table, []any{"1", "2024-01-01 00:00:00+00", "Hello", "short", nil, "alice"}, true)
+	if updatedAt != "2024-01-01 00:00:00+00" || record.ID != "1" || record.Title != "Hello" || record.Content != "short" || record.Metadata["author"] != "alice" {
+		t.Errorf("unexpected record %+v, updatedAt %s", record, updatedAt)
+	}
+	full, err := recordObjectInfo("docs/1.json", record, updatedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, updatedAt = scanRecord(table, []any{"1", "2024-01-01 00:00:00+00"}, false)
+	listed, err := recordObjectInfo("docs/1.json", record, updatedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if full.ETag != listed.ETag {
+		t.Error("etag of a row should be the same when listed and stated")
+	}
+}
+
+func TestParseRecordKey(t *testing.T) {
+	p := &PostgreSQL{Ref: &v1alpha1.Datasource{Spec: v1alpha1.DatasourceSpec{PostgreSQL: &v1alpha1.PostgreSQL{
+		Tables: []v1alpha1.PostgreSQLTable{{Name: "docs"}},
+	}}}}
+	key := recordKey("docs", "a/b c")
+	if key != "docs/a%2Fb%20c.json" {
+		t.Errorf("unexpected key %s", key)
+	}
+	table, id, err := p.parseKey(&v1alpha1.OSS{Object: key})
+	if err != nil || table.Name != "docs" || id != "a/b c" {
+		t.Errorf("parseKey(%s) = %v, %s, %v", key, table, id, err)
+	}
+	for _, key := range []string{"docs/1.txt", "unknown/1.json", "docs"} {
+		if _, _, err := p.parseKey(&v1alpha1.OSS{Object: key}); err != ErrPGNoSuchRecord {
+			t.Errorf("parseKey(%s) should fail, got %v", key, err)
+		}
+	}
+	tags, err := p.GetTags(context.Background(), &v1alpha1.OSS{Object: key})
+	if err != nil || tags[v1alpha1.ObjectTypeTag] != v1alpha1.ObjectTypeRecord {
+		t.Errorf("unexpected tags %v, %v", tags, err)
+	}
+}
@@ -33,7 +33,7 @@ func NewWeb(ctx context.Context, url string) (*Web, error) {
 }
 
 func (w *Web) Stat(ctx context.Context, info any) error {
-	_, err := url.ParseRequestURI("http://google.com/")
+	_, err := url.ParseRequestURI(w.url)
 	if err != nil {
 		return err
 	}
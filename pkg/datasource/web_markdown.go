@@ -0,0 +1,253 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// boilerplateSelector matches the elements which are not the main content of a page
+const boilerplateSelector = "script, style, noscript, template, iframe, svg, canvas, form, button, nav, aside, footer, " +
+	"[role=navigation], [role=banner], [role=contentinfo], [role=complementary], [aria-hidden=true], " +
+	".nav, .navbar, .menu, .sidebar, .breadcrumb, .breadcrumbs, .advertisement, .ads, .cookie, .cookie-banner"
+
+var (
+	spaces        = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLines    = regexp.MustCompile(`\n{3,}`)
+	trailingSpace = regexp.MustCompile(`[ \t]+\n`)
+)
+
+// HTMLToMarkdown extracts the title and the main content of the page as markdown.
+// Boilerplate such as navigation, sidebars and footers is removed, and links are resolved with base.
+func HTMLToMarkdown(doc *goquery.Document, base *url.URL) (title, markdown string) {
+	title = strings.TrimSpace(spaces.ReplaceAllString(doc.Find("title").First().Text(), " "))
+	if title == "" {
+		title = strings.TrimSpace(spaces.ReplaceAllString(doc.Find("h1").First().Text(), " "))
+	}
+
+	doc.Find(boilerplateSelector).Remove()
+	// headers of articles are kept, as they usually contain the title of the article
+	doc.Find("header").Each(func(_ int, s *goquery.Selection) {
+		if s.ParentsFiltered("main, article").Length() == 0 {
+			s.Remove()
+		}
+	})
+	root := doc.Find("main, article, [role=main]").First()
+	if root.Length() == 0 {
+		root = doc.Find("body")
+	}
+	if root.Length() == 0 {
+		root = doc.Selection
+	}
+
+	c := &markdownConverter{base: base}
+	var b strings.Builder
+	for _, n := range root.Nodes {
+		b.WriteString(c.convert(n))
+	}
+	markdown = trailingSpace.ReplaceAllString(b.String(), "\n")
+	markdown = blankLines.ReplaceAllString(markdown, "\n\n")
+	return title, strings.TrimSpace(markdown)
+}
+
+type markdownConverter struct {
+	base *url.URL
+}
+
+func (c *markdownConverter) children(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(c.convert(child))
+	}
+	return b.String()
+}
+
+func (c *markdownConverter) convert(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return spaces.ReplaceAllString(n.Data, " ")
+	case html.DocumentNode:
+		return c.children(n)
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := strings.TrimSpace(c.children(n))
+		if text == "" {
+			return ""
+		}
+		level := int(n.Data[1] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + text + "\n\n"
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Figure, atom.Dl, atom.Dd, atom.Dt:
+		return "\n\n" + strings.TrimSpace(c.children(n)) + "\n\n"
+	case atom.Br:
+		return "\n"
+	case atom.Hr:
+		return "\n\n---\n\n"
+	case atom.Strong, atom.B:
+		return wrapInline(c.children(n), "**")
+	case atom.Em, atom.I:
+		return wrapInline(c.children(n), "*")
+	case atom.Code:
+		return wrapInline(textContent(n), "`")
+	case atom.Pre:
+		return "\n\n```\n" + strings.Trim(textContent(n), "\n") + "\n```\n\n"
+	case atom.A:
+		text := strings.TrimSpace(c.children(n))
+		href := c.resolve(attr(n, "href"))
+		if text == "" || href == "" {
+			return text
+		}
+		return fmt.Sprintf("[%s](%s)", text, href)
+	case atom.Img:
+		alt := strings.TrimSpace(attr(n, "alt"))
+		src := c.resolve(attr(n, "src"))
+		if alt == "" || src == "" {
+			return ""
+		}
+		return fmt.Sprintf("![%s](%s)", alt, src)
+	case atom.Ul, atom.Ol:
+		return "\n\n" + c.list(n) + "\n\n"
+	case atom.Blockquote:
+		text := strings.TrimSpace(blankLines.ReplaceAllString(c.children(n), "\n\n"))
+		return "\n\n> " + strings.ReplaceAll(text, "\n", "\n> ") + "\n\n"
+	case atom.Table:
+		return "\n\n" + c.table(n) + "\n\n"
+	default:
+		return c.children(n)
+	}
+}
+
+func (c *markdownConverter) list(n *html.Node) string {
+	ordered := n.DataAtom == atom.Ol
+	items := make([]string, 0)
+	index := 1
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = fmt.Sprintf("%d. ", index)
+			index++
+		}
+		text := strings.TrimSpace(blankLines.ReplaceAllString(c.children(child), "\n\n"))
+		text = strings.ReplaceAll(strings.ReplaceAll(text, "\n\n", "\n"), "\n", "\n"+strings.Repeat(" ", len(marker)))
+		items = append(items, marker+text)
+	}
+	return strings.Join(items, "\n")
+}
+
+func (c *markdownConverter) table(n *html.Node) string {
+	rows := make([][]string, 0)
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			if child.DataAtom != atom.Tr {
+				walk(child)
+				continue
+			}
+			row := make([]string, 0)
+			for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+					text := strings.TrimSpace(spaces.ReplaceAllString(c.children(cell), " "))
+					row = append(row, strings.ReplaceAll(text, "|", "\\|"))
+				}
+			}
+			if len(row) > 0 {
+				rows = append(rows, row)
+			}
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return ""
+	}
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *markdownConverter) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(strings.ToLower(ref), "javascript:") {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if c.base != nil {
+		u = c.base.ResolveReference(u)
+	}
+	return u.String()
+}
+
+func wrapInline(text, mark string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	return mark + trimmed + mark
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
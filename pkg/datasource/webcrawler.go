@@ -0,0 +1,464 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/temoto/robotstxt"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/documentloaders"
+)
+
+const (
+	DefaultWebCrawlerUserAgent = "arcadia-webcrawler"
+
+	// maxPageSize is the max size of a page to be read
+	maxPageSize = 10 << 20
+)
+
+// WebCrawler crawls pages from websites, starting from the seed urls
+type WebCrawler struct {
+	seeds     []*url.URL
+	hosts     map[string]bool
+	include   []*regexp.Regexp
+	exclude   []*regexp.Regexp
+	maxDepth  int
+	maxPages  int
+	sitemap   bool
+	robots    bool
+	delay     time.Duration
+	userAgent string
+
+	client *http.Client
+	// robotsTxt caches the parsed robots.txt of each host, nil if not available
+	robotsTxt map[string]*robotstxt.RobotsData
+	lastVisit map[string]time.Time
+}
+
+// CrawlResult is the result of a crawl
+type CrawlResult struct {
+	// Pages is the number of pages handled
+	Pages int
+	// Failed is the number of pages which failed to be fetched
+	Failed int
+}
+
+// NewWebCrawler creates a crawler with the config, defaultSeed is used if no seed urls are configured
+func NewWebCrawler(defaultSeed string, config *v1alpha1.WebCrawler) (*WebCrawler, error) {
+	if config == nil {
+		config = &v1alpha1.WebCrawler{}
+	}
+	c := &WebCrawler{
+		hosts:     make(map[string]bool),
+		maxDepth:  config.MaxDepth,
+		maxPages:  config.MaxPages,
+		sitemap:   config.Sitemap,
+		robots:    !config.IgnoreRobotsTxt,
+		delay:     v1alpha1.DefaultWebCrawlerDelay,
+		userAgent: config.UserAgent,
+		robotsTxt: make(map[string]*robotstxt.RobotsData),
+		lastVisit: make(map[string]time.Time),
+	}
+	if c.maxDepth <= 0 {
+		c.maxDepth = v1alpha1.DefaultWebCrawlerMaxDepth
+	}
+	if c.maxPages <= 0 {
+		c.maxPages = v1alpha1.DefaultWebCrawlerMaxPages
+	}
+	if config.Delay != nil {
+		c.delay = config.Delay.Duration
+	}
+	if c.userAgent == "" {
+		c.userAgent = DefaultWebCrawlerUserAgent
+	}
+	c.client = &http.Client{Timeout: 30 * time.Second, CheckRedirect: c.checkRedirect}
+	seeds := config.SeedURLs
+	if len(seeds) == 0 && defaultSeed != "" {
+		seeds = []string{defaultSeed}
+	}
+	if len(seeds) == 0 {
+		return nil, fmt.Errorf("no seed urls")
+	}
+	for _, s := range seeds {
+		u, err := url.ParseRequestURI(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed url %s: %w", s, err)
+		}
+		u = normalizeURL(u)
+		c.seeds = append(c.seeds, u)
+		c.hosts[u.Host] = true
+	}
+	for _, p := range config.IncludePatterns {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %s: %w", p, err)
+		}
+		c.include = append(c.include, r)
+	}
+	for _, p := range config.ExcludePatterns {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %s: %w", p, err)
+		}
+		c.exclude = append(c.exclude, r)
+	}
+	return c, nil
+}
+
+type crawlItem struct {
+	u     *url.URL
+	depth int
+}
+
+// Crawl visits pages breadth first and calls handle for each page with content.
+// Crawling stops once handle returns an error.
+func (c *WebCrawler) Crawl(ctx context.Context, handle func(ctx context.Context, page *documentloaders.WebPage) error) (result CrawlResult, err error) {
+	visited := make(map[string]bool)
+	contents := make(map[string]bool)
+	queue := make([]crawlItem, 0, len(c.seeds))
+	for _, u := range c.seeds {
+		if !visited[u.String()] {
+			visited[u.String()] = true
+			queue = append(queue, crawlItem{u: u})
+		}
+	}
+	if c.sitemap {
+		// pages in sitemap are crawled without following their links
+		for _, u := range c.sitemapURLs(ctx) {
+			if !visited[u.String()] && c.matches(u) {
+				visited[u.String()] = true
+				queue = append(queue, crawlItem{u: u, depth: c.maxDepth})
+			}
+		}
+	}
+
+	for len(queue) > 0 && result.Pages < c.maxPages {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		item := queue[0]
+		queue = queue[1:]
+		if !c.allowed(ctx, item.u) {
+			klog.V(5).Infof("webcrawler: %s is disallowed by robots.txt", item.u)
+			continue
+		}
+		doc, final, err := c.fetch(ctx, item.u)
+		if err != nil {
+			klog.V(3).Infof("webcrawler: failed to fetch %s: %s", item.u, err)
+			result.Failed++
+			continue
+		}
+		if doc == nil {
+			continue
+		}
+		if final.String() != item.u.String() {
+			// redirected to a visited page
+			if visited[final.String()] {
+				continue
+			}
+			visited[final.String()] = true
+		}
+
+		if item.depth < c.maxDepth {
+			doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+				href, _ := s.Attr("href")
+				ref, err := url.Parse(strings.TrimSpace(href))
+				if err != nil {
+					return
+				}
+				u := normalizeURL(final.ResolveReference(ref))
+				if (u.Scheme != "http" && u.Scheme != "https") || !c.hosts[u.Host] || visited[u.String()] || !c.matches(u) {
+					return
+				}
+				visited[u.String()] = true
+				queue = append(queue, crawlItem{u: u, depth: item.depth + 1})
+			})
+		}
+
+		// seed pages are crawled to discover links, but only kept when they match the patterns
+		if !c.matches(final) {
+			continue
+		}
+		title, markdown := HTMLToMarkdown(doc, final)
+		if markdown == "" {
+			continue
+		}
+		sum := sha1.Sum([]byte(markdown))
+		if checksum := hex.EncodeToString(sum[:]); contents[checksum] {
+			continue
+		} else {
+			contents[checksum] = true
+		}
+		if err := handle(ctx, &documentloaders.WebPage{URL: final.String(), Title: title, Markdown: markdown}); err != nil {
+			return result, err
+		}
+		result.Pages++
+	}
+	return result, nil
+}
+
+// matches checks the url against the include and exclude patterns
+func (c *WebCrawler) matches(u *url.URL) bool {
+	s := u.String()
+	for _, r := range c.exclude {
+		if r.MatchString(s) {
+			return false
+		}
+	}
+	if len(c.include) == 0 {
+		return true
+	}
+	for _, r := range c.include {
+		if r.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetch gets the page, returns nil document if the page is not html
+func (c *WebCrawler) fetch(ctx context.Context, u *url.URL) (*goquery.Document, *url.URL, error) {
+	resp, err := c.get(ctx, u)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	final := normalizeURL(resp.Request.URL)
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "html") {
+		return nil, final, nil
+	}
+	doc, err := goquery.NewDocumentFromReader(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, final, nil
+}
+
+// checkRedirect stops following redirects to hosts other than the ones of seed urls
+func (c *WebCrawler) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if u := normalizeURL(req.URL); !c.hosts[u.Host] {
+		return fmt.Errorf("redirected to %s which is out of the allowed hosts", u.Host)
+	}
+	return nil
+}
+
+// get sends a request after the politeness delay of the host
+func (c *WebCrawler) get(ctx context.Context, u *url.URL) (*http.Response, error) {
+	delay := c.delay
+	if data := c.robotsTxt[u.Host]; data != nil {
+		if group := data.FindGroup(c.userAgent); group.CrawlDelay > delay {
+			delay = group.CrawlDelay
+		}
+	}
+	if last, ok := c.lastVisit[u.Host]; ok {
+		if wait := time.Until(last.Add(delay)); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+	c.lastVisit[u.Host] = time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	return c.client.Do(req)
+}
+
+// allowed checks the url against robots.txt of its host
+func (c *WebCrawler) allowed(ctx context.Context, u *url.URL) bool {
+	if !c.robots {
+		return true
+	}
+	data := c.robotsData(ctx, u)
+	return data == nil || data.TestAgent(u.EscapedPath(), c.userAgent)
+}
+
+// robotsData returns robots.txt of the host of u, which is fetched once per host
+func (c *WebCrawler) robotsData(ctx context.Context, u *url.URL) *robotstxt.RobotsData {
+	if data, ok := c.robotsTxt[u.Host]; ok {
+		return data
+	}
+	var data *robotstxt.RobotsData
+	resp, err := c.get(ctx, &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"})
+	if err == nil {
+		defer resp.Body.Close()
+		if data, err = robotstxt.FromResponse(resp); err != nil {
+			data = nil
+		}
+	}
+	// robots.txt is not available, all pages are allowed
+	if ctx.Err() == nil {
+		c.robotsTxt[u.Host] = data
+	}
+	return data
+}
+
+type sitemapXML struct {
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// sitemapURLs discovers urls from the sitemaps of seed hosts, including the ones declared in robots.txt
+func (c *WebCrawler) sitemapURLs(ctx context.Context) []*url.URL {
+	sitemaps := make([]string, 0)
+	for _, seed := range c.seeds {
+		sitemaps = append(sitemaps, (&url.URL{Scheme: seed.Scheme, Host: seed.Host, Path: "/sitemap.xml"}).String())
+		if c.robots {
+			if data := c.robotsData(ctx, seed); data != nil {
+				sitemaps = append(sitemaps, data.Sitemaps...)
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	res := make([]*url.URL, 0)
+	// sitemap index files are followed one level deep
+	for level := 0; level < 2 && len(sitemaps) > 0; level++ {
+		next := make([]string, 0)
+		for _, s := range sitemaps {
+			u := c.sitemapURL(ctx, s)
+			if u == nil || seen[u.String()] {
+				continue
+			}
+			seen[u.String()] = true
+			sm, err := c.fetchSitemap(ctx, u)
+			if err != nil {
+				klog.V(3).Infof("webcrawler: failed to fetch sitemap %s: %s", u, err)
+				continue
+			}
+			for _, loc := range sm.URLs {
+				u, err := url.Parse(strings.TrimSpace(loc.Loc))
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !c.hosts[u.Host] {
+					continue
+				}
+				res = append(res, normalizeURL(u))
+			}
+			for _, loc := range sm.Sitemaps {
+				next = append(next, strings.TrimSpace(loc.Loc))
+			}
+		}
+		sitemaps = next
+	}
+	return res
+}
+
+// sitemapURL parses the url of a sitemap, returns nil if it is out of the allowed hosts or disallowed by robots.txt
+func (c *WebCrawler) sitemapURL(ctx context.Context, s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil
+	}
+	u = normalizeURL(u)
+	if (u.Scheme != "http" && u.Scheme != "https") || !c.hosts[u.Host] {
+		klog.V(3).Infof("webcrawler: sitemap %s is out of the allowed hosts", s)
+		return nil
+	}
+	if !c.allowed(ctx, u) {
+		klog.V(5).Infof("webcrawler: sitemap %s is disallowed by robots.txt", s)
+		return nil
+	}
+	return u
+}
+
+func (c *WebCrawler) fetchSitemap(ctx context.Context, u *url.URL) (*sitemapXML, error) {
+	resp, err := c.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	sm := &sitemapXML{}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, maxPageSize)).Decode(sm); err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
+
+// normalizeURL removes the fragment, default port and sorts query, so the same page has the same url
+func normalizeURL(u *url.URL) *url.URL {
+	n := *u
+	n.Fragment = ""
+	n.RawFragment = ""
+	n.Scheme = strings.ToLower(n.Scheme)
+	n.Host = strings.ToLower(n.Host)
+	if (n.Scheme == "http" && strings.HasSuffix(n.Host, ":80")) || (n.Scheme == "https" && strings.HasSuffix(n.Host, ":443")) {
+		n.Host = n.Host[:strings.LastIndex(n.Host, ":")]
+	}
+	if n.Path == "" {
+		n.Path = "/"
+	}
+	if n.RawQuery != "" {
+		n.RawQuery = n.Query().Encode()
+	}
+	return &n
+}
+
+var unsafeObjectChars = regexp.MustCompile(`[^a-zA-Z0-9._\-/]+`)
+
+// WebPageObjectName returns the object name of the page relative to the path of the crawler
+func WebPageObjectName(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		sum := sha1.Sum([]byte(pageURL))
+		return hex.EncodeToString(sum[:]) + ".md"
+	}
+	p := u.Path
+	if p == "" || strings.HasSuffix(p, "/") {
+		p += "index"
+	}
+	switch ext := path.Ext(p); ext {
+	case ".html", ".htm", ".php", ".asp", ".aspx", ".jsp":
+		p = strings.TrimSuffix(p, ext)
+	}
+	name := unsafeObjectChars.ReplaceAllString(strings.ReplaceAll(u.Host, ":", "_")+path.Clean("/"+p), "_")
+	if u.RawQuery != "" {
+		sum := sha1.Sum([]byte(u.RawQuery))
+		name += "_" + hex.EncodeToString(sum[:])[:8]
+	}
+	return name + ".md"
+}
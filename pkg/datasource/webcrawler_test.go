@@ -0,0 +1,261 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/documentloaders"
+)
+
+func newTestSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	page := func(title, body string) string {
+		return fmt.Sprintf(`<html><head><title>%s</title></head><body>
+<nav><a href="/">Home</a><a href="/private/secret">Secret</a></nav>
+<main>%s</main>
+<footer>Copyright footer</footer>
+<script>var x = 1;</script>
+</body></html>`, title, body)
+	}
+	home := `<h1>Welcome</h1><p>Read the <a href="/docs#intro">docs</a>, the <a href="/copy">copy</a>
+and the <a href="/private/secret">secret</a>, or leave to <a href="https://example.com/">example</a>.</p>
+<a href="/excluded/page">excluded</a>`
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private/\n")
+	})
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>%s/only-in-sitemap</loc></url></urlset>`, server.URL)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, page("Home", home))
+		case "/docs":
+			fmt.Fprint(w, page("Docs", `<h2>Install</h2><ul><li>step <b>one</b></li><li>step two</li></ul>
+<pre><code>go build ./...</code></pre><a href="/deep">deep</a>`))
+		case "/copy":
+			fmt.Fprint(w, page("Copy", home))
+		case "/deep":
+			fmt.Fprint(w, page("Deep", `<p>too deep</p>`))
+		case "/only-in-sitemap":
+			fmt.Fprint(w, page("Sitemap", `<table><tr><th>a</th><th>b</th></tr><tr><td>1</td><td>2</td></tr></table>`))
+		case "/private/secret", "/excluded/page":
+			fmt.Fprint(w, page("Forbidden", `<p>should not be crawled</p>`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebCrawler(t *testing.T) {
+	server := newTestSite(t)
+	crawler, err := NewWebCrawler(server.URL, &v1alpha1.WebCrawler{
+		MaxDepth:        1,
+		MaxPages:        10,
+		Sitemap:         true,
+		ExcludePatterns: []string{"/excluded/"},
+		Delay:           &metav1.Duration{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := make(map[string]*documentloaders.WebPage)
+	result, err := crawler.Crawl(context.Background(), func(_ context.Context, page *documentloaders.WebPage) error {
+		pages[strings.TrimPrefix(page.URL, server.URL)] = page
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(pages))
+	for p := range pages {
+		got = append(got, p)
+	}
+	sort.Strings(got)
+	// /copy has the same content as /, /deep exceeds the max depth
+	expected := []string{"/", "/docs", "/only-in-sitemap"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") || result.Pages != len(expected) {
+		t.Fatalf("crawled pages %v, expected %v", got, expected)
+	}
+
+	home := pages["/"]
+	if home.Title != "Home" {
+		t.Errorf("unexpected title %q", home.Title)
+	}
+	for _, s := range []string{"Copyright footer", "var x", "[Home]"} {
+		if strings.Contains(home.Markdown, s) {
+			t.Errorf("boilerplate %q should be removed: %s", s, home.Markdown)
+		}
+	}
+	if !strings.Contains(home.Markdown, "# Welcome") || !strings.Contains(home.Markdown, "[docs]("+server.URL+"/docs#intro)") {
+		t.Errorf("unexpected markdown: %s", home.Markdown)
+	}
+	docs := pages["/docs"].Markdown
+	for _, s := range []string{"## Install", "- step **one**", "```\ngo build ./...\n```"} {
+		if !strings.Contains(docs, s) {
+			t.Errorf("markdown should contain %q: %s", s, docs)
+		}
+	}
+	if table := pages["/only-in-sitemap"].Markdown; table != "| a | b |\n| --- | --- |\n| 1 | 2 |" {
+		t.Errorf("unexpected table: %q", table)
+	}
+
+	parsed := documentloaders.ParseWebPage(home.String())
+	if parsed.URL != home.URL || parsed.Title != home.Title || parsed.Markdown != home.Markdown {
+		t.Errorf("web page is changed after formatted and parsed: %+v", parsed)
+	}
+}
+
+func TestWebCrawlerRedirectAndRobots(t *testing.T) {
+	offsite := 0
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offsite++
+		fmt.Fprint(w, `<html><body><p>off site</p></body></html>`)
+	}))
+	t.Cleanup(other.Close)
+
+	robots := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		robots++
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private/\n")
+	})
+	mux.HandleFunc("/away", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/landing", http.StatusFound)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/docs", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><p>docs</p></body></html>`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><p>home</p><a href="/away">away</a><a href="/moved">moved</a></body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	crawler, err := NewWebCrawler(server.URL, &v1alpha1.WebCrawler{Sitemap: true, Delay: &metav1.Duration{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	result, err := crawler.Crawl(context.Background(), func(_ context.Context, page *documentloaders.WebPage) error {
+		got = append(got, strings.TrimPrefix(page.URL, server.URL))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	if strings.Join(got, ",") != "/,/docs" {
+		t.Errorf("crawled pages %v, expected [/ /docs]", got)
+	}
+	if offsite != 0 || result.Failed != 1 {
+		t.Errorf("redirect to other hosts should fail without visiting them, visited %d times, failed %d", offsite, result.Failed)
+	}
+	if robots != 1 {
+		t.Errorf("robots.txt should be fetched once, fetched %d times", robots)
+	}
+}
+
+func TestWebCrawlerSitemapHosts(t *testing.T) {
+	offsite := 0
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offsite++
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><urlset><url><loc>/landing</loc></url></urlset>`)
+	}))
+	t.Cleanup(other.Close)
+
+	var server *httptest.Server
+	disallowed := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "User-agent: *\nDisallow: /private/\nSitemap: %s/robots-sitemap.xml\nSitemap: %s/private/sitemap.xml\nSitemap: file:///etc/passwd\n", other.URL, server.URL)
+	})
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex><sitemap><loc>%s/index-sitemap.xml</loc></sitemap><sitemap><loc>%s/pages.xml</loc></sitemap></sitemapindex>`, other.URL, server.URL)
+	})
+	mux.HandleFunc("/pages.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset><url><loc>%s/page</loc></url><url><loc>%s/page</loc></url></urlset>`, server.URL, other.URL)
+	})
+	mux.HandleFunc("/private/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		disallowed++
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<html><body><p>%s</p></body></html>`, r.URL.Path)
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	crawler, err := NewWebCrawler(server.URL, &v1alpha1.WebCrawler{Sitemap: true, Delay: &metav1.Duration{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	if _, err = crawler.Crawl(context.Background(), func(_ context.Context, page *documentloaders.WebPage) error {
+		got = append(got, page.URL)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	if expected := server.URL + "/," + server.URL + "/page"; strings.Join(got, ",") != expected {
+		t.Errorf("crawled pages %v, expected %s", got, expected)
+	}
+	if offsite != 0 {
+		t.Errorf("sitemaps of other hosts should not be fetched, fetched %d times", offsite)
+	}
+	if disallowed != 0 {
+		t.Errorf("sitemaps disallowed by robots.txt should not be fetched, fetched %d times", disallowed)
+	}
+}
+
+func TestWebPageObjectName(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://example.com/", "example.com/index.md"},
+		{"https://example.com/docs/", "example.com/docs/index.md"},
+		{"https://example.com/a/b.html", "example.com/a/b.md"},
+		{"http://localhost:8080/a b", "localhost_8080/a_b.md"},
+	}
+	for _, test := range tests {
+		if result := WebPageObjectName(test.url); result != test.expected {
+			t.Errorf("WebPageObjectName(%s) = %s, expected %s", test.url, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package documentloaders
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// RecordIDCol is the metadata key of the id of a record
+const RecordIDCol = "record_id"
+
+// Record is a row of a database table stored as a json file
+type Record struct {
+	ID       string            `json:"id"`
+	Title    string            `json:"title,omitempty"`
+	Content  string            `json:"content"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// RecordLoader loads a record file, the id, title and metadata columns are kept in the metadata of documents
+type RecordLoader struct {
+	r io.Reader
+}
+
+func NewRecord(r io.Reader) *RecordLoader {
+	return &RecordLoader{r: r}
+}
+
+func (l *RecordLoader) Load(ctx context.Context) ([]schema.Document, error) {
+	record := &Record{}
+	if err := json.NewDecoder(l.r).Decode(record); err != nil {
+		return nil, err
+	}
+	metadata := make(map[string]any, len(record.Metadata)+2)
+	for k, v := range record.Metadata {
+		metadata[k] = v
+	}
+	metadata[RecordIDCol] = record.ID
+	if record.Title != "" {
+		metadata[TitleCol] = record.Title
+	}
+	return []schema.Document{
+		{
+			PageContent: record.Content,
+			Metadata:    metadata,
+		},
+	}, nil
+}
+
+func (l *RecordLoader) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := l.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
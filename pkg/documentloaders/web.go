@@ -0,0 +1,119 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package documentloaders
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+const (
+	// URLCol is the metadata key of the url of a web page
+	URLCol = "url"
+	// TitleCol is the metadata key of the title of a web page
+	TitleCol = "title"
+
+	frontMatterDelimiter = "---"
+)
+
+// WebPage is a markdown file crawled from a web page, with its url and title in the front matter:
+//
+//	---
+//	url: https://example.com/
+//	title: Example
+//	---
+//
+//	# Example
+type WebPage struct {
+	URL      string
+	Title    string
+	Markdown string
+}
+
+// String returns the content of the file stored in oss
+func (p *WebPage) String() string {
+	return fmt.Sprintf("%s\n%s: %s\n%s: %s\n%s\n\n%s", frontMatterDelimiter,
+		URLCol, strings.ReplaceAll(p.URL, "\n", " "),
+		TitleCol, strings.ReplaceAll(p.Title, "\n", " "),
+		frontMatterDelimiter, p.Markdown)
+}
+
+// ParseWebPage parses the content of a web page file, content without front matter is taken as markdown
+func ParseWebPage(content string) *WebPage {
+	page := &WebPage{Markdown: content}
+	if !strings.HasPrefix(content, frontMatterDelimiter+"\n") {
+		return page
+	}
+	rest := content[len(frontMatterDelimiter)+1:]
+	end := strings.Index(rest, "\n"+frontMatterDelimiter)
+	if end == -1 {
+		return page
+	}
+	for _, line := range strings.Split(rest[:end], "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case URLCol:
+			page.URL = strings.TrimSpace(value)
+		case TitleCol:
+			page.Title = strings.TrimSpace(value)
+		}
+	}
+	page.Markdown = strings.TrimLeft(rest[end+len(frontMatterDelimiter)+1:], "\n")
+	return page
+}
+
+// Web loads a web page file, the url and title are kept in the metadata of documents
+type Web struct {
+	r io.Reader
+}
+
+func NewWeb(r io.Reader) *Web {
+	return &Web{r: r}
+}
+
+func (w *Web) Load(ctx context.Context) ([]schema.Document, error) {
+	data, err := io.ReadAll(w.r)
+	if err != nil {
+		return nil, err
+	}
+	page := ParseWebPage(string(data))
+	return []schema.Document{
+		{
+			PageContent: page.Markdown,
+			Metadata: map[string]any{
+				URLCol:   page.URL,
+				TitleCol: page.Title,
+			},
+		},
+	}, nil
+}
+
+func (w *Web) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := w.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
@@ -16,30 +16,106 @@ limitations under the License.
 package dashscope
 
 import (
+	"bufio"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/tmc/langchaingo/embeddings"
+	"k8s.io/klog/v2"
 
 	"github.com/kubeagi/arcadia/pkg/llms/dashscope"
 )
 
 var _ embeddings.Embedder = (*DashScopeEmbedder)(nil)
 
+// Uploader uploads the input file of an async embedding task, and returns an url which DashScope can download it from.
+// cleanup is called once the task is done.
+type Uploader func(ctx context.Context, data []byte) (url string, cleanup func(), err error)
+
 type DashScopeEmbedder struct {
 	*dashscope.DashScope
+
+	model          dashscope.Model
+	uploader       Uploader
+	asyncThreshold int
+	pollInterval   time.Duration
+	clientOptions  []dashscope.Option
 }
 
 const (
 	MaxTextLength = 25 // https://help.aliyun.com/zh/dashscope/developer-reference/text-embedding-quick-start
+	// DefaultAsyncThreshold is the min number of texts embedded by an async task
+	DefaultAsyncThreshold = 1000
+	// MaxAsyncTextLength is the max number of texts of an async task
+	MaxAsyncTextLength = 100000
 )
 
-func NewDashScopeEmbedder(apiKey string) *DashScopeEmbedder {
-	return &DashScopeEmbedder{
-		DashScope: dashscope.NewDashScope(apiKey, false),
+type Option func(*DashScopeEmbedder)
+
+// WithModel sets the embedding model, text-embedding-v1 by default
+func WithModel(model string) Option {
+	return func(d *DashScopeEmbedder) {
+		d.model = dashscope.Model(model)
+	}
+}
+
+// WithAsync embeds documents by async tasks if there are at least threshold texts, DefaultAsyncThreshold if threshold is 0.
+// The texts are uploaded by uploader, and the sync api is used instead if the upload or the task fails.
+func WithAsync(uploader Uploader, threshold int) Option {
+	return func(d *DashScopeEmbedder) {
+		d.uploader = uploader
+		if threshold > 0 {
+			d.asyncThreshold = threshold
+		}
+	}
+}
+
+// WithClientOptions sets the options of the DashScope client, like WithBaseURL
+func WithClientOptions(opts ...dashscope.Option) Option {
+	return func(d *DashScopeEmbedder) {
+		d.clientOptions = append(d.clientOptions, opts...)
+	}
+}
+
+func NewDashScopeEmbedder(apiKey string, opts ...Option) *DashScopeEmbedder {
+	d := &DashScopeEmbedder{
+		model:          dashscope.EmbeddingV1,
+		asyncThreshold: DefaultAsyncThreshold,
+		pollInterval:   5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(d)
 	}
+	d.DashScope = dashscope.NewDashScope(apiKey, false, d.clientOptions...)
+	return d
 }
+
+// AsyncThreshold returns the min number of texts to be embedded by an async task, 0 if async embedding is disabled.
+// Callers which split texts into batches should send at least this number of texts in one call to use async tasks.
+func (d DashScopeEmbedder) AsyncThreshold() int {
+	if d.uploader == nil {
+		return 0
+	}
+	return d.asyncThreshold
+}
+
 func (d DashScopeEmbedder) EmbedDocuments(ctx context.Context, texts []string) (res [][]float32, err error) {
+	if d.uploader != nil && len(texts) >= d.asyncThreshold && len(texts) <= MaxAsyncTextLength {
+		res, err = d.embedAsync(ctx, texts)
+		if err == nil {
+			return res, nil
+		}
+		klog.Errorf("failed to embed %d texts by async task, fallback to batch api: %s", len(texts), err)
+	}
 	res = make([][]float32, 0, len(texts))
 	for i := 0; i < len(texts); i += MaxTextLength {
 		end := i + MaxTextLength
@@ -47,13 +123,14 @@ func (d DashScopeEmbedder) EmbedDocuments(ctx context.Context, texts []string) (
 			end = len(texts)
 		}
 		data := texts[i:end]
-		embedding, err := d.CreateEmbedding(ctx, data, false)
+		embedding, err := d.CreateEmbeddingWithModel(ctx, d.model, data, false)
 		if err != nil {
 			return res, err
 		}
-		if len(embedding) == 0 {
-			return res, errors.New("embedding is empty")
+		if len(embedding) != len(data) {
+			return res, fmt.Errorf("expect %d embeddings, got %d", len(data), len(embedding))
 		}
+		sort.Slice(embedding, func(a, b int) bool { return embedding[a].TextIndex < embedding[b].TextIndex })
 		for j := 0; j < len(embedding); j++ {
 			res = append(res, embedding[j].Embedding)
 		}
@@ -62,7 +139,7 @@ func (d DashScopeEmbedder) EmbedDocuments(ctx context.Context, texts []string) (
 }
 
 func (d DashScopeEmbedder) EmbedQuery(ctx context.Context, text string) (res []float32, err error) {
-	embedding, err := d.CreateEmbedding(ctx, []string{text}, true)
+	embedding, err := d.CreateEmbeddingWithModel(ctx, d.model, []string{text}, true)
 	if err != nil {
 		return nil, err
 	}
@@ -72,3 +149,130 @@ func (d DashScopeEmbedder) EmbedQuery(ctx context.Context, text string) (res []f
 	res = embedding[0].Embedding
 	return res, nil
 }
+
+// asyncModel returns the async model of the sync model
+func (d DashScopeEmbedder) asyncModel() dashscope.Model {
+	if d.model == dashscope.EmbeddingV2 {
+		return dashscope.EmbeddingAsyncV2
+	}
+	return dashscope.EmbeddingAsyncV1
+}
+
+// embedAsync uploads texts as a file with one text per line, and waits for the async task to finish
+func (d DashScopeEmbedder) embedAsync(ctx context.Context, texts []string) ([][]float32, error) {
+	lines := make([]string, len(texts))
+	for i, text := range texts {
+		lines[i] = strings.Join(strings.Fields(text), " ")
+	}
+	url, cleanup, err := d.uploader(ctx, []byte(strings.Join(lines, "\n")))
+	if err != nil {
+		return nil, err
+	}
+	if cleanup != nil {
+		defer cleanup()
+	}
+	taskID, err := d.CreateEmbeddingAsyncWithModel(ctx, d.asyncModel(), url, false)
+	if err != nil {
+		return nil, err
+	}
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+	for {
+		task, err := d.GetTask(ctx, taskID)
+		if err != nil {
+			return nil, err
+		}
+		switch task.TaskStatus {
+		case dashscope.TaskStatusSucceeded:
+			return downloadAsyncResult(ctx, task.URL, len(texts))
+		case dashscope.TaskStatusFailed, dashscope.TaskStatusUnknown:
+			return nil, fmt.Errorf("async embedding task %s %s, code: %s, message: %s", taskID, task.TaskStatus, task.Code, task.Message)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckPublicURL checks the input url of an async task can be downloaded by DashScope,
+// urls of loopback, private or in-cluster addresses are rejected.
+func CheckPublicURL(u *url.URL) error {
+	host := strings.ToLower(u.Hostname())
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() {
+			return fmt.Errorf("%s is not a public address which DashScope can reach", host)
+		}
+		return nil
+	}
+	if host == "localhost" || !strings.Contains(host, ".") {
+		return fmt.Errorf("%s is not a public address which DashScope can reach", host)
+	}
+	for _, suffix := range []string{".local", ".svc", ".internal", ".localhost"} {
+		if strings.HasSuffix(host, suffix) {
+			return fmt.Errorf("%s is not a public address which DashScope can reach", host)
+		}
+	}
+	return nil
+}
+
+// asyncResultLine is a line of the result file of an async embedding task
+type asyncResultLine struct {
+	Output struct {
+		Code      int       `json:"code"`
+		Message   string    `json:"message"`
+		TextIndex int       `json:"text_index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"output"`
+}
+
+// downloadAsyncResult downloads the gzipped result file, whose lines are embeddings of each text
+func downloadAsyncResult(ctx context.Context, url string, count int) ([][]float32, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download result with status %s", resp.Status)
+	}
+	gzReader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer gzReader.Close()
+
+	res := make([][]float32, count)
+	scanner := bufio.NewScanner(gzReader)
+	scanner.Buffer(make([]byte, 0, 1<<20), 1<<24)
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		line := &asyncResultLine{}
+		if err := json.Unmarshal(scanner.Bytes(), line); err != nil {
+			return nil, err
+		}
+		if line.Output.Code != 0 && line.Output.Code != http.StatusOK {
+			return nil, fmt.Errorf("failed to embed text %d, code: %d, message: %s", line.Output.TextIndex, line.Output.Code, line.Output.Message)
+		}
+		if line.Output.TextIndex < 0 || line.Output.TextIndex >= count {
+			return nil, fmt.Errorf("unexpected text index %d", line.Output.TextIndex)
+		}
+		res[line.Output.TextIndex] = line.Output.Embedding
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for i := range res {
+		if len(res[i]) == 0 {
+			return nil, fmt.Errorf("no embedding of text %d in the result", i)
+		}
+	}
+	return res, nil
+}
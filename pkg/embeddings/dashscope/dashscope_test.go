@@ -0,0 +1,182 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashscope
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubeagi/arcadia/pkg/llms/dashscope"
+)
+
+// fakeDashScope serves the async embedding apis, the embedding of a text is [len(text)]
+type fakeDashScope struct {
+	server   *httptest.Server
+	input    map[string][]byte
+	syncReqs int
+	polls    int
+}
+
+func newFakeDashScope(t *testing.T) *fakeDashScope {
+	t.Helper()
+	f := &fakeDashScope{input: make(map[string][]byte)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/input/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(f.input[r.URL.Path])
+	})
+	mux.HandleFunc("/api/v1/services/embeddings/text-embedding/text-embedding", func(w http.ResponseWriter, r *http.Request) {
+		req := &dashscope.EmbeddingRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+		}
+		if req.Input.EmbeddingInputAsync == nil || req.Input.EmbeddingInputAsync.URL == "" {
+			f.syncReqs++
+			embeddings := make([]dashscope.Embeddings, len(req.Input.Texts))
+			for i, text := range req.Input.Texts {
+				embeddings[i] = dashscope.Embeddings{TextIndex: i, Embedding: []float32{float32(len(text))}}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"output": map[string]any{"embeddings": embeddings}})
+			return
+		}
+		if r.Header.Get("X-DashScope-Async") != "enable" {
+			t.Error("async header is not set")
+		}
+		if req.Model != dashscope.EmbeddingAsyncV2 {
+			t.Errorf("unexpected async model %s", req.Model)
+		}
+		resp, err := http.Get(req.Input.EmbeddingInputAsync.URL)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		var result bytes.Buffer
+		gz := gzip.NewWriter(&result)
+		for i, line := range strings.Split(string(data), "\n") {
+			fmt.Fprintf(gz, `{"output":{"code":200,"text_index":%d,"embedding":[%d]}}`+"\n", i, len(line))
+		}
+		gz.Close()
+		f.input["/input/result.gz"] = result.Bytes()
+		_ = json.NewEncoder(w).Encode(map[string]any{"output": map[string]any{"task_id": "task-1", "task_status": "PENDING"}})
+	})
+	mux.HandleFunc("/api/v1/tasks/task-1", func(w http.ResponseWriter, r *http.Request) {
+		f.polls++
+		status := dashscope.TaskStatusRunning
+		if f.polls > 1 {
+			status = dashscope.TaskStatusSucceeded
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"output": map[string]any{"task_id": "task-1", "task_status": status, "url": f.server.URL + "/input/result.gz"}})
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeDashScope) uploader(ctx context.Context, data []byte) (string, func(), error) {
+	f.input["/input/texts.txt"] = data
+	return f.server.URL + "/input/texts.txt", func() { delete(f.input, "/input/texts.txt") }, nil
+}
+
+func TestEmbedDocumentsAsync(t *testing.T) {
+	f := newFakeDashScope(t)
+	e := NewDashScopeEmbedder("fake", WithModel(string(dashscope.EmbeddingV2)), WithAsync(f.uploader, 3),
+		WithClientOptions(dashscope.WithBaseURL(f.server.URL)))
+	e.pollInterval = time.Millisecond
+	if e.AsyncThreshold() != 3 {
+		t.Fatalf("unexpected async threshold %d", e.AsyncThreshold())
+	}
+
+	texts := []string{"a", "bb", "multi\nline text"}
+	res, err := e.EmbedDocuments(context.Background(), texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// lines are joined as one text, so the embedding of "multi\nline text" is [len("multi line text")]
+	expected := []float32{1, 2, 15}
+	for i := range expected {
+		if len(res[i]) != 1 || res[i][0] != expected[i] {
+			t.Fatalf("unexpected embeddings %v, expected %v", res, expected)
+		}
+	}
+	if f.syncReqs != 0 || f.polls != 2 {
+		t.Errorf("texts should be embedded by the async task, got %d sync requests and %d polls", f.syncReqs, f.polls)
+	}
+	if _, ok := f.input["/input/texts.txt"]; ok {
+		t.Error("uploaded texts should be cleaned up")
+	}
+
+	// texts under the threshold are embedded by the sync api
+	if _, err = e.EmbedDocuments(context.Background(), texts[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if f.syncReqs != 1 || f.polls != 2 {
+		t.Errorf("texts under the threshold should be embedded by the sync api, got %d sync requests and %d polls", f.syncReqs, f.polls)
+	}
+
+	// the sync api is used if the upload fails
+	e.uploader = func(ctx context.Context, data []byte) (string, func(), error) {
+		return "", nil, fmt.Errorf("not reachable")
+	}
+	if res, err = e.EmbedDocuments(context.Background(), texts); err != nil || len(res) != 3 || res[1][0] != 2 {
+		t.Fatalf("unexpected embeddings %v, error: %v", res, err)
+	}
+	if f.syncReqs != 2 {
+		t.Errorf("texts should be embedded by the sync api after the upload failed, got %d sync requests", f.syncReqs)
+	}
+
+	if NewDashScopeEmbedder("fake").AsyncThreshold() != 0 {
+		t.Error("async embedding should be disabled without uploader")
+	}
+}
+
+func TestCheckPublicURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		public bool
+	}{
+		{"https://oss.example.com/bucket/object?X-Amz-Signature=abc", true},
+		{"http://47.100.1.2:9000/bucket/object", true},
+		{"http://127.0.0.1:9000/bucket/object", false},
+		{"http://10.96.0.10:9000/bucket/object", false},
+		{"http://192.168.1.10/bucket/object", false},
+		{"http://[::1]:9000/bucket/object", false},
+		{"http://localhost:9000/bucket/object", false},
+		{"http://arcadia-minio:9000/bucket/object", false},
+		{"http://arcadia-minio.kubeagi-system.svc:9000/bucket/object", false},
+		{"http://arcadia-minio.kubeagi-system.svc.cluster.local:9000/bucket/object", false},
+	}
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = CheckPublicURL(u); (err == nil) != test.public {
+			t.Errorf("CheckPublicURL(%s) = %v, expected public %t", test.url, err, test.public)
+		}
+	}
+}
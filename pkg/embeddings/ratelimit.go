@@ -0,0 +1,219 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	langchaingoembeddings "github.com/tmc/langchaingo/embeddings"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// DefaultBackoff is used to retry embedding requests which are rejected by quota limits or server errors
+var DefaultBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    6,
+	Cap:      time.Minute,
+}
+
+// QueryBackoff is used to retry embedding requests at query time, like searching a knowledgebase during a chat,
+// which should fail fast instead of waiting for the service to recover
+var QueryBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    3,
+	Cap:      2 * time.Second,
+}
+
+// retryableErr matches the messages returned by embedding services when requests are rate limited (429)
+// or failed on server side (5xx). Status codes are only matched right after "status", "status code",
+// "exception" or "HTTP", so numbers like "max input length 512" in other errors are not retried.
+var retryableErr = regexp.MustCompile(`(?i)(\bstatus(\s+code)?|\bexception|\bhttp(/[\d.]+)?)\s*[:=]?\s*(429|5\d\d)\b|too many requests|rate limit|throttl`)
+
+// statusCoder is implemented by errors which carry the HTTP status code of the failed request
+type statusCoder interface {
+	StatusCode() int
+}
+
+// IsRetryableError returns true if the request may succeed after a while
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var sc statusCoder
+	if errors.As(err, &sc) {
+		code := sc.StatusCode()
+		return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+	return retryableErr.MatchString(err.Error())
+}
+
+// Limiter limits the requests sent to an embedding service
+type Limiter struct {
+	requestsPerMinute int
+	maxConcurrency    int
+
+	limiter *rate.Limiter
+	sem     chan struct{}
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*Limiter)
+)
+
+// GetLimiter returns the limiter registered with key, so all callers of the same embedding service share the limits.
+// The registered limiter is replaced when the limits change.
+func GetLimiter(key string, requestsPerMinute, maxConcurrency int) *Limiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	if l, ok := limiters[key]; ok && l.requestsPerMinute == requestsPerMinute && l.maxConcurrency == maxConcurrency {
+		return l
+	}
+	l := NewLimiter(requestsPerMinute, maxConcurrency)
+	limiters[key] = l
+	return l
+}
+
+// NewLimiter creates a limiter. A zero value of requestsPerMinute or maxConcurrency means no limit.
+func NewLimiter(requestsPerMinute, maxConcurrency int) *Limiter {
+	l := &Limiter{
+		requestsPerMinute: requestsPerMinute,
+		maxConcurrency:    maxConcurrency,
+	}
+	if requestsPerMinute > 0 {
+		l.limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(requestsPerMinute)), 1)
+	}
+	if maxConcurrency > 0 {
+		l.sem = make(chan struct{}, maxConcurrency)
+	}
+	return l
+}
+
+// Acquire blocks until a request is allowed to be sent.
+// The returned release function must be called after the request is done.
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	release = func() {}
+	if l == nil {
+		return release, nil
+	}
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+			release = func() { <-l.sem }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if l.limiter != nil {
+		if err = l.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+var _ langchaingoembeddings.Embedder = (*RateLimitedEmbedder)(nil)
+
+// RateLimitedEmbedder splits texts into batches and sends each batch as a request under the limits of Limiter.
+// Batches which failed with 429 or 5xx are retried with exponential backoff.
+type RateLimitedEmbedder struct {
+	Embedder  langchaingoembeddings.Embedder
+	Limiter   *Limiter
+	Backoff   wait.Backoff
+	BatchSize int
+	// UnbatchedThreshold is the min number of texts to be sent in one request without splitting into batches,
+	// for embedders which embed large jobs in their own way, like async tasks of DashScope. 0 means always split.
+	UnbatchedThreshold int
+}
+
+// NewRateLimitedEmbedder creates an embedder which retries failed requests with DefaultBackoff
+func NewRateLimitedEmbedder(embedder langchaingoembeddings.Embedder, limiter *Limiter, batchSize int) *RateLimitedEmbedder {
+	return &RateLimitedEmbedder{
+		Embedder:  embedder,
+		Limiter:   limiter,
+		Backoff:   DefaultBackoff,
+		BatchSize: batchSize,
+	}
+}
+
+// EmbedDocuments creates one vector embedding for each of the texts.
+func (e *RateLimitedEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+	batchSize := e.BatchSize
+	if batchSize <= 0 || (e.UnbatchedThreshold > 0 && len(texts) >= e.UnbatchedThreshold) {
+		batchSize = len(texts)
+	}
+	emb := make([][]float32, 0, len(texts))
+	for _, batch := range langchaingoembeddings.BatchTexts(texts, batchSize) {
+		var res [][]float32
+		err := e.do(ctx, func() (err error) {
+			res, err = e.Embedder.EmbedDocuments(ctx, batch)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		emb = append(emb, res...)
+	}
+	return emb, nil
+}
+
+// EmbedQuery embeds a single text.
+func (e *RateLimitedEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	var res []float32
+	err := e.do(ctx, func() (err error) {
+		res, err = e.Embedder.EmbedQuery(ctx, text)
+		return err
+	})
+	return res, err
+}
+
+func (e *RateLimitedEmbedder) do(ctx context.Context, request func() error) error {
+	backoff := e.Backoff
+	for {
+		release, err := e.Limiter.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+		err = request()
+		release()
+		if err == nil || !IsRetryableError(err) || backoff.Steps <= 1 {
+			return err
+		}
+		delay := backoff.Step()
+		klog.V(3).Infof("embedding request failed, retry after %s: %s", delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
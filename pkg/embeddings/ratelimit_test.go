@@ -0,0 +1,129 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type statusError int
+
+func (e statusError) Error() string   { return "request failed with " + strconv.Itoa(int(e)) }
+func (e statusError) StatusCode() int { return int(e) }
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{errors.New("API returned unexpected status code: 429"), true},
+		{errors.New("API returned unexpected status code: 503"), true},
+		{errors.New("exception: 429 Too Many Requests"), true},
+		{errors.New("dashscope request abc failed with status 429, code: Throttling, message: rate limited"), true},
+		{errors.New("failed to download result with status 502 Bad Gateway"), true},
+		{errors.New("HTTP 500: internal error"), true},
+		{statusError(503), true},
+		{fmt.Errorf("embed: %w", statusError(429)), true},
+		{statusError(400), false},
+		{errors.New("API returned unexpected status code: 401"), false},
+		{errors.New("API returned unexpected status code: 400: max input length 512"), false},
+		{errors.New("input exceeds 512 tokens"), false},
+		{errors.New("invalid input"), false},
+	}
+	for _, test := range tests {
+		if result := IsRetryableError(test.err); result != test.expected {
+			t.Errorf("IsRetryableError(%v) = %t, expected %t", test.err, result, test.expected)
+		}
+	}
+}
+
+type fakeEmbedder struct {
+	failures int
+	calls    int
+	batches  [][]string
+}
+
+func (f *fakeEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	f.calls++
+	if f.failures > 0 {
+		f.failures--
+		return nil, errors.New("API returned unexpected status code: 429")
+	}
+	f.batches = append(f.batches, texts)
+	res := make([][]float32, len(texts))
+	for i := range texts {
+		res[i] = []float32{float32(len(texts[i]))}
+	}
+	return res, nil
+}
+
+func (f *fakeEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	res, err := f.EmbedDocuments(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return res[0], nil
+}
+
+func TestRateLimitedEmbedder(t *testing.T) {
+	fake := &fakeEmbedder{failures: 2}
+	e := NewRateLimitedEmbedder(fake, NewLimiter(0, 1), 2)
+	e.Backoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 5}
+
+	res, err := e.EmbedDocuments(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != 3 || res[2][0] != 3 {
+		t.Fatalf("unexpected embeddings: %v", res)
+	}
+	if len(fake.batches) != 2 {
+		t.Fatalf("texts should be split into 2 batches, got %d", len(fake.batches))
+	}
+	if fake.calls != 4 {
+		t.Fatalf("2 failed requests should be retried, got %d calls", fake.calls)
+	}
+
+	// large jobs are sent in one request
+	fake = &fakeEmbedder{}
+	e = NewRateLimitedEmbedder(fake, nil, 2)
+	e.UnbatchedThreshold = 4
+	if _, err = e.EmbedDocuments(context.Background(), []string{"a", "bb", "ccc"}); err != nil || len(fake.batches) != 2 {
+		t.Fatalf("texts under the unbatched threshold should be split into 2 batches, got %d batches, error: %v", len(fake.batches), err)
+	}
+	if _, err = e.EmbedDocuments(context.Background(), []string{"a", "bb", "ccc", "dddd"}); err != nil || len(fake.batches) != 3 || len(fake.batches[2]) != 4 {
+		t.Fatalf("texts over the unbatched threshold should be sent in one request, got batches %v, error: %v", fake.batches, err)
+	}
+
+	fake = &fakeEmbedder{failures: 10}
+	e = NewRateLimitedEmbedder(fake, nil, 0)
+	e.Backoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 3}
+	if _, err := e.EmbedQuery(context.Background(), "a"); err == nil {
+		t.Fatal("expected error after retries are exhausted")
+	}
+	if fake.calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", fake.calls)
+	}
+}
@@ -17,24 +17,77 @@ limitations under the License.
 package langchainwrap
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
 
 	langchaingoembeddings "github.com/tmc/langchaingo/embeddings"
 	"github.com/tmc/langchaingo/llms/googleai"
 	"github.com/tmc/langchaingo/llms/openai"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kubeagi/arcadia/api/base/v1alpha1"
 	"github.com/kubeagi/arcadia/pkg/config"
 	"github.com/kubeagi/arcadia/pkg/embeddings"
+	dashscopeembeddings "github.com/kubeagi/arcadia/pkg/embeddings/dashscope"
 	zhipuaiembeddings "github.com/kubeagi/arcadia/pkg/embeddings/zhipuai"
+	"github.com/kubeagi/arcadia/pkg/llms/dashscope"
 	"github.com/kubeagi/arcadia/pkg/llms/zhipuai"
+	"github.com/kubeagi/arcadia/pkg/tokenusage"
 )
 
+// GetLangchainEmbedder returns a langchaingo embedder for this Embedder to be used at query time.
+// Requests are limited by Embedder's rate limit and retried on 429/5xx errors for a few times with QueryBackoff,
+// and the token usage is recorded to the recorder in the context.
 func GetLangchainEmbedder(ctx context.Context, e *v1alpha1.Embedder, c client.Client, model string, opts ...langchaingoembeddings.Option) (em langchaingoembeddings.Embedder, err error) {
+	return getRateLimitedEmbedder(ctx, e, c, model, embeddings.QueryBackoff, opts...)
+}
+
+// GetIndexingEmbedder returns a langchaingo embedder for this Embedder to embed files of knowledgebases,
+// which waits longer for the embedding service to recover by retrying 429/5xx errors with DefaultBackoff.
+func GetIndexingEmbedder(ctx context.Context, e *v1alpha1.Embedder, c client.Client, model string, opts ...langchaingoembeddings.Option) (em langchaingoembeddings.Embedder, err error) {
+	return getRateLimitedEmbedder(ctx, e, c, model, embeddings.DefaultBackoff, opts...)
+}
+
+func getRateLimitedEmbedder(ctx context.Context, e *v1alpha1.Embedder, c client.Client, model string, backoff wait.Backoff, opts ...langchaingoembeddings.Option) (em langchaingoembeddings.Embedder, err error) {
+	em, err = getLangchainEmbedder(ctx, e, c, model, opts...)
+	if err != nil {
+		return nil, err
+	}
+	unbatchedThreshold := 0
+	if async, ok := em.(interface{ AsyncThreshold() int }); ok {
+		// large jobs are embedded by one async task instead of batches
+		unbatchedThreshold = async.AsyncThreshold()
+	}
+	if model == "" {
+		if models := e.GetModelList(); len(models) > 0 {
+			model = models[0]
+		}
+	}
+	em = tokenusage.WrapEmbedder(em, e.Namespace+"/"+e.Name, model)
+	// get the batch size from options, the same as how langchaingo embedder does
+	options := &langchaingoembeddings.EmbedderImpl{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	var limiter *embeddings.Limiter
+	if limit := e.Spec.RateLimit; limit != nil {
+		limiter = embeddings.GetLimiter(e.Namespace+"/"+e.Name, limit.RequestsPerMinute, limit.MaxConcurrency)
+	}
+	rateLimited := embeddings.NewRateLimitedEmbedder(em, limiter, options.BatchSize)
+	rateLimited.Backoff = backoff
+	rateLimited.UnbatchedThreshold = unbatchedThreshold
+	return rateLimited, nil
+}
+
+func getLangchainEmbedder(ctx context.Context, e *v1alpha1.Embedder, c client.Client, model string, opts ...langchaingoembeddings.Option) (em langchaingoembeddings.Embedder, err error) {
 	switch e.Spec.Provider.GetType() {
 	case v1alpha1.ProviderType3rdParty:
 		switch e.Spec.Type { // nolint: gocritic
@@ -88,6 +141,49 @@ func GetLangchainEmbedder(ctx context.Context, e *v1alpha1.Embedder, c client.Cl
 				return nil, err
 			}
 			return langchaingoembeddings.NewEmbedder(llm, opts...)
+		case embeddings.DashScope:
+			apiKey, err := e.AuthAPIKey(ctx, c)
+			if err != nil {
+				return nil, err
+			}
+
+			if model == "" {
+				models := e.GetModelList()
+				if len(models) == 0 {
+					return nil, errors.New("no valid models for this Embedder")
+				}
+				model = models[0]
+			}
+
+			dashscopeOpts := []dashscopeembeddings.Option{
+				dashscopeembeddings.WithModel(model),
+			}
+			if e.Spec.DashScope != nil && e.Spec.DashScope.AsyncThreshold > 0 {
+				dashscopeOpts = append(dashscopeOpts, dashscopeembeddings.WithAsync(dashScopeUploader(e.Namespace), e.Spec.DashScope.AsyncThreshold))
+			}
+			if baseURL := e.Get3rdPartyEmbedderBaseURL(); baseURL != "" {
+				dashscopeOpts = append(dashscopeOpts, dashscopeembeddings.WithClientOptions(dashscope.WithBaseURL(baseURL)))
+			}
+			return dashscopeembeddings.NewDashScopeEmbedder(apiKey, dashscopeOpts...), nil
+		case embeddings.OpenAICompatible:
+			apiKey, err := e.AuthAPIKey(ctx, c)
+			if err != nil {
+				return nil, err
+			}
+
+			if model == "" {
+				models := e.GetModelList()
+				if len(models) == 0 {
+					return nil, errors.New("no valid models for this Embedder")
+				}
+				model = models[0]
+			}
+
+			llm, err := openai.New(openAICompatibleOptions(e.Get3rdPartyEmbedderBaseURL(), apiKey, model, e.Spec.OpenAICompatible)...)
+			if err != nil {
+				return nil, err
+			}
+			return langchaingoembeddings.NewEmbedder(llm, opts...)
 		}
 	case v1alpha1.ProviderTypeWorker:
 		gateway, err := config.GetGateway(ctx)
@@ -118,3 +214,30 @@ func GetLangchainEmbedder(ctx context.Context, e *v1alpha1.Embedder, c client.Cl
 	}
 	return nil, fmt.Errorf("unknown provider type")
 }
+
+// dashScopeUploader uploads the input files of DashScope async embedding tasks to the system oss,
+// DashScope downloads them by presigned urls, so the oss must be reachable from DashScope.
+// Nothing is uploaded if the presigned url is not a public address, and the sync api is used instead.
+func dashScopeUploader(namespace string) dashscopeembeddings.Uploader {
+	return func(ctx context.Context, data []byte) (string, func(), error) {
+		oss, err := config.GetSystemDatasourceOSS(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+		object := fmt.Sprintf("embeddings/dashscope/%s.txt", uuid.NewString())
+		u, err := oss.Client.PresignedGetObject(ctx, namespace, object, time.Hour, nil)
+		if err != nil {
+			return "", nil, err
+		}
+		if err = dashscopeembeddings.CheckPublicURL(u); err != nil {
+			return "", nil, fmt.Errorf("system datasource can't be reached by DashScope: %w", err)
+		}
+		if _, err = oss.Client.PutObject(ctx, namespace, object, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{ContentType: "text/plain"}); err != nil {
+			return "", nil, err
+		}
+		cleanup := func() {
+			_ = oss.Client.RemoveObject(context.Background(), namespace, object, minio.RemoveObjectOptions{})
+		}
+		return u.String(), cleanup, nil
+	}
+}
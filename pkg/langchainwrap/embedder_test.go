@@ -0,0 +1,91 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package langchainwrap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	langchaingoembeddings "github.com/tmc/langchaingo/embeddings"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/embeddings"
+)
+
+func TestEmbedderRetries(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"rate limit exceeded","type":"requests"}}`))
+	}))
+	defer server.Close()
+	e := &v1alpha1.Embedder{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "arcadia", Name: "bge"},
+		Spec: v1alpha1.EmbedderSpec{
+			Type:              embeddings.OpenAICompatible,
+			Provider:          v1alpha1.Provider{Endpoint: &v1alpha1.Endpoint{URL: server.URL}},
+			ModelCapabilities: []v1alpha1.ModelCapability{{Name: "bge-large-zh"}},
+		},
+	}
+	defaultBackoff, queryBackoff := embeddings.DefaultBackoff, embeddings.QueryBackoff
+	t.Cleanup(func() {
+		embeddings.DefaultBackoff, embeddings.QueryBackoff = defaultBackoff, queryBackoff
+	})
+	embeddings.DefaultBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 5}
+	embeddings.QueryBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 2}
+
+	ctx := context.Background()
+	tests := []struct {
+		name             string
+		get              func(ctx context.Context, e *v1alpha1.Embedder, c client.Client, model string, opts ...langchaingoembeddings.Option) (langchaingoembeddings.Embedder, error)
+		expectedRequests int32
+	}{
+		{
+			name:             "query",
+			get:              GetLangchainEmbedder,
+			expectedRequests: 2,
+		},
+		{
+			name:             "indexing",
+			get:              GetIndexingEmbedder,
+			expectedRequests: 5,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			em, err := test.get(ctx, e, nil, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = em.EmbedQuery(ctx, "kubeagi"); err == nil {
+				t.Fatal("expect the rate limit error")
+			}
+			if n := atomic.LoadInt32(&requests); n != test.expectedRequests {
+				t.Errorf("expect %d requests, got %d", test.expectedRequests, n)
+			}
+		})
+	}
+}
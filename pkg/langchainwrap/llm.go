@@ -20,7 +20,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
 
 	langchainllms "github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/googleai"
@@ -32,14 +34,32 @@ import (
 	"github.com/kubeagi/arcadia/pkg/appruntime/log"
 	"github.com/kubeagi/arcadia/pkg/config"
 	"github.com/kubeagi/arcadia/pkg/llms"
+	"github.com/kubeagi/arcadia/pkg/llms/dashscope"
+	"github.com/kubeagi/arcadia/pkg/llms/gemini"
+	arcadiaopenai "github.com/kubeagi/arcadia/pkg/llms/openai"
 	"github.com/kubeagi/arcadia/pkg/llms/zhipuai"
+	"github.com/kubeagi/arcadia/pkg/tokenusage"
 )
 
 const (
 	GatewayUseExternalURLEnv = "GATEWAY_USE_EXTERNAL_URL"
 )
 
+// GetLangchainLLM returns a langchaingo llm for this LLM, its token usage is recorded to the recorder in the context.
 func GetLangchainLLM(ctx context.Context, llm *v1alpha1.LLM, c client.Client, model string) (langchainllms.Model, error) {
+	l, err := getLangchainLLM(ctx, llm, c, model)
+	if err != nil {
+		return nil, err
+	}
+	if model == "" {
+		if models := llm.GetModelList(); len(models) > 0 {
+			model = models[0]
+		}
+	}
+	return tokenusage.WrapModel(l, llm.Namespace+"/"+llm.Name, model), nil
+}
+
+func getLangchainLLM(ctx context.Context, llm *v1alpha1.LLM, c client.Client, model string) (langchainllms.Model, error) {
 	switch llm.Spec.Provider.GetType() {
 	case v1alpha1.ProviderType3rdParty:
 		apiKey, err := llm.AuthAPIKey(ctx, c)
@@ -48,7 +68,12 @@ func GetLangchainLLM(ctx context.Context, llm *v1alpha1.LLM, c client.Client, mo
 		}
 		switch llm.Spec.Type {
 		case llms.ZhiPuAI:
-			return zhipuai.NewZhiPuAILLM(apiKey, zhipuai.WithRetryTimes(3), zhipuai.WithCallback(log.KLogHandler{LogLevel: 3})), nil
+			if model == "" {
+				if models := llm.GetModelList(); len(models) > 0 {
+					model = models[0]
+				}
+			}
+			return zhipuai.NewZhiPuAILLM(apiKey, zhipuai.WithRetryTimes(3), zhipuai.WithCallback(log.KLogHandler{LogLevel: 3}), zhipuai.WithModel(model)), nil
 		case llms.OpenAI:
 			// When apitype is OpenAI,there are two possible sources:
 			// 1. From official OpenAI
@@ -61,7 +86,15 @@ func GetLangchainLLM(ctx context.Context, llm *v1alpha1.LLM, c client.Client, mo
 				}
 				model = models[0]
 			}
-			return openai.New(openai.WithToken(apiKey), openai.WithBaseURL(llm.Get3rdPartyLLMBaseURL()), openai.WithModel(model), openai.WithCallback(log.KLogHandler{LogLevel: 3}), openai.WithHTTPClient(DebugHTTPClient))
+			baseURL := llm.Get3rdPartyLLMBaseURL()
+			if baseURL == "" {
+				baseURL = arcadiaopenai.OpenaiModelAPIURL
+			}
+			openaiLLM, err := openai.New(openai.WithToken(apiKey), openai.WithBaseURL(baseURL), openai.WithModel(model), openai.WithCallback(log.KLogHandler{LogLevel: 3}), openai.WithHTTPClient(DebugHTTPClient))
+			if err != nil {
+				return nil, err
+			}
+			return withTools(openaiLLM, baseURL, apiKey, model, DebugHTTPClient), nil
 		case llms.Gemini:
 			if model == "" {
 				models := llm.GetModelList()
@@ -75,7 +108,37 @@ func GetLangchainLLM(ctx context.Context, llm *v1alpha1.LLM, c client.Client, mo
 				return nil, err
 			}
 			googleLLM.CallbacksHandler = log.GeminiKLogHandler{KLogHandler: &log.KLogHandler{LogLevel: 3}}
-			return googleLLM, nil
+			// the googleai llm doesn't support tools, functions are called by the REST api
+			return gemini.NewFunctionCallingLLM(googleLLM, apiKey, model, gemini.WithHTTPClient(DebugHTTPClient)), nil
+		case llms.DashScope:
+			if model == "" {
+				models := llm.GetModelList()
+				if len(models) == 0 {
+					return nil, errors.New("no valid models for this LLM")
+				}
+				model = models[0]
+			}
+			opts := []dashscope.LLMOption{dashscope.WithModel(model), dashscope.WithCallback(log.KLogHandler{LogLevel: 3})}
+			if baseURL := llm.Get3rdPartyLLMBaseURL(); baseURL != "" {
+				opts = append(opts, dashscope.WithClientOptions(dashscope.WithBaseURL(baseURL)))
+			}
+			return dashscope.NewDashScopeLLM(apiKey, opts...), nil
+		case llms.OpenAICompatible:
+			if model == "" {
+				models := llm.GetModelList()
+				if len(models) == 0 {
+					return nil, errors.New("no valid models for this LLM")
+				}
+				model = models[0]
+			}
+			compatibleLLM, err := openai.New(openAICompatibleOptions(llm.Get3rdPartyLLMBaseURL(), apiKey, model, llm.Spec.OpenAICompatible)...)
+			if err != nil {
+				return nil, err
+			}
+			if apiKey == "" {
+				apiKey = openAICompatibleFakeToken
+			}
+			return withTools(compatibleLLM, llm.Get3rdPartyLLMBaseURL(), apiKey, model, openAICompatibleHTTPClient(llm.Spec.OpenAICompatible)), nil
 		}
 	case v1alpha1.ProviderTypeWorker:
 		gateway, err := config.GetGateway(ctx)
@@ -104,7 +167,25 @@ func GetLangchainLLM(ctx context.Context, llm *v1alpha1.LLM, c client.Client, mo
 		if os.Getenv(GatewayUseExternalURLEnv) == "true" {
 			gatewayURL = gateway.ExternalAPIServer
 		}
-		return openai.New(openai.WithModel(modelName), openai.WithBaseURL(gatewayURL), openai.WithToken("fake"), openai.WithCallback(log.KLogHandler{LogLevel: 3}), openai.WithHTTPClient(DebugHTTPClient))
+		workerLLM, err := openai.New(openai.WithModel(modelName), openai.WithBaseURL(gatewayURL), openai.WithToken("fake"), openai.WithCallback(log.KLogHandler{LogLevel: 3}), openai.WithHTTPClient(DebugHTTPClient))
+		if err != nil {
+			return nil, err
+		}
+		return withTools(workerLLM, gatewayURL, "fake", modelName, DebugHTTPClient), nil
 	}
 	return nil, fmt.Errorf("unknown provider type")
 }
+
+// withTools wraps the langchaingo openai llm to call functions by tools,
+// because it only supports the deprecated functions and rejects function messages.
+func withTools(llm langchainllms.Model, baseURL, apiKey, model string, httpClient *http.Client) langchainllms.Model {
+	return &arcadiaopenai.FunctionCallingLLM{
+		LLM: llm,
+		Client: &arcadiaopenai.ToolsClient{
+			URL:        strings.TrimSuffix(baseURL, "/") + openAIChatPath,
+			Token:      func() (string, error) { return apiKey, nil },
+			HTTPClient: httpClient,
+		},
+		ModelName: model,
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package langchainwrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/llms/llmgroup"
+)
+
+// LLMGroupMember is an available member of the group
+type LLMGroupMember struct {
+	LLM   *v1alpha1.LLM
+	Model string
+}
+
+// GetLangchainLLMGroup returns a composite llm which dispatches requests to the members of the group,
+// and the available members. Members which are not ready are skipped.
+func GetLangchainLLMGroup(ctx context.Context, group *v1alpha1.LLMGroup, c client.Client) (langchainllms.Model, []LLMGroupMember, error) {
+	logger := klog.FromContext(ctx)
+	members := make([]llmgroup.Member, 0, len(group.Spec.Members))
+	available := make([]LLMGroupMember, 0, len(group.Spec.Members))
+	for _, m := range group.Spec.Members {
+		name := group.MemberName(m)
+		llm := &v1alpha1.LLM{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: m.LLM.GetNamespace(group.Namespace), Name: m.LLM.Name}, llm); err != nil {
+			logger.Error(err, "failed to get member of llm group, skip it", "group", group.Name, "member", name)
+			continue
+		}
+		if ready, msg := llm.Status.IsReadyOrGetReadyMessage(); !ready {
+			logger.Info("member of llm group is not ready, skip it", "group", group.Name, "member", name, "message", msg)
+			continue
+		}
+		model, err := GetLangchainLLM(ctx, llm, c, m.Model)
+		if err != nil {
+			logger.Error(err, "failed to get langchain llm of member, skip it", "group", group.Name, "member", name)
+			continue
+		}
+		// always set the model, so the model configured in chains for a single llm won't be sent to the members
+		modelName := m.Model
+		if models := llm.GetModelList(); modelName == "" && len(models) > 0 {
+			modelName = models[0]
+		}
+		members = append(members, llmgroup.Member{
+			Name:      name,
+			Model:     model,
+			ModelName: modelName,
+			Priority:  m.Priority,
+			Weight:    m.Weight,
+		})
+		available = append(available, LLMGroupMember{LLM: llm, Model: modelName})
+	}
+	config := llmgroup.Config{Strategy: llmgroup.Strategy(group.GetStrategy())}
+	if group.Spec.Retry != nil {
+		config.MaxAttempts = group.Spec.Retry.MaxAttempts
+		config.ErrorCodes = group.Spec.Retry.ErrorCodes
+	}
+	if cb := group.Spec.CircuitBreaker; cb != nil {
+		config.FailureThreshold = cb.FailureThreshold
+		if config.FailureThreshold == 0 {
+			config.FailureThreshold = v1alpha1.DefaultLLMGroupFailureThreshold
+		}
+		openSeconds := cb.OpenSeconds
+		if openSeconds == 0 {
+			openSeconds = v1alpha1.DefaultLLMGroupOpenSeconds
+		}
+		config.OpenDuration = time.Duration(openSeconds) * time.Second
+	}
+	model, err := llmgroup.New(fmt.Sprintf("%s/%s", group.Namespace, group.Name), config, members...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("llm group %s has no available members: %w", group.Name, err)
+	}
+	return model, available, nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package langchainwrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms/openai"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/log"
+)
+
+const (
+	openAIChatPath       = "/chat/completions"
+	openAIEmbeddingsPath = "/embeddings"
+	// openAICompatibleFakeToken is used when the service needs no api key, because langchaingo requires one
+	openAICompatibleFakeToken = "fake"
+)
+
+// openAICompatibleOptions returns the langchaingo openai options to request an OpenAI compatible service
+func openAICompatibleOptions(baseURL, apiKey, model string, config *v1alpha1.OpenAICompatible) []openai.Option {
+	if apiKey == "" {
+		apiKey = openAICompatibleFakeToken
+	}
+	return []openai.Option{
+		openai.WithToken(apiKey),
+		openai.WithBaseURL(strings.TrimSuffix(baseURL, "/")),
+		openai.WithModel(model),
+		openai.WithEmbeddingModel(model),
+		openai.WithCallback(log.KLogHandler{LogLevel: 3}),
+		openai.WithHTTPClient(openAICompatibleHTTPClient(config)),
+	}
+}
+
+// openAICompatibleHTTPClient returns the http client which applies the config to requests
+func openAICompatibleHTTPClient(config *v1alpha1.OpenAICompatible) *http.Client {
+	if config == nil {
+		return DebugHTTPClient
+	}
+	return &http.Client{Transport: &openAICompatibleTransport{config: config, transport: DebugHTTPClient.Transport}}
+}
+
+// openAICompatibleTransport applies the headers, api version, path overrides and extra body parameters to requests
+type openAICompatibleTransport struct {
+	config    *v1alpha1.OpenAICompatible
+	transport http.RoundTripper
+}
+
+func (t *openAICompatibleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.config.Headers {
+		req.Header.Set(k, v)
+	}
+	switch {
+	case t.config.ChatPath != "" && strings.HasSuffix(req.URL.Path, openAIChatPath):
+		req.URL.Path = strings.TrimSuffix(req.URL.Path, openAIChatPath) + t.config.ChatPath
+	case t.config.EmbeddingsPath != "" && strings.HasSuffix(req.URL.Path, openAIEmbeddingsPath):
+		req.URL.Path = strings.TrimSuffix(req.URL.Path, openAIEmbeddingsPath) + t.config.EmbeddingsPath
+	}
+	if t.config.APIVersion != "" {
+		query := req.URL.Query()
+		query.Set("api-version", t.config.APIVersion)
+		req.URL.RawQuery = query.Encode()
+	}
+	if len(t.config.ExtraBody) != 0 && req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = addExtraBody(body, t.config.ExtraBody)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// addExtraBody adds the extra parameters which are not set to the json body, the body is not changed if it is not a json object
+func addExtraBody(body []byte, extra map[string]string) []byte {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	for k, v := range extra {
+		if _, ok := fields[k]; ok {
+			continue
+		}
+		if json.Valid([]byte(v)) {
+			fields[k] = json.RawMessage(v)
+		} else {
+			fields[k], _ = json.Marshal(v)
+		}
+	}
+	res, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return res
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package langchainwrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/openai"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+func TestOpenAICompatible(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("api-version") != "2024-01-01" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		if r.Header.Get("X-Tenant") != "kubeagi" || r.Header.Get("Authorization") != "Bearer fake" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		body := make(map[string]any)
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body["model"] != "deepseek-chat" || body["enable_search"] != true || body["region"] != "cn" || body["top_k"] != float64(3) {
+			t.Errorf("unexpected body %v", body)
+		}
+		fmt.Fprint(w, `{"id":"1","object":"chat.completion","model":"deepseek-chat","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	config := &v1alpha1.OpenAICompatible{
+		Headers:    map[string]string{"X-Tenant": "kubeagi"},
+		APIVersion: "2024-01-01",
+		ChatPath:   "/chat",
+		// model is set by the request, so it should not be overridden
+		ExtraBody: map[string]string{"enable_search": "true", "region": "cn", "model": `"other"`, "top_k": "3"},
+	}
+	llm, err := openai.New(openAICompatibleOptions(server.URL+"/v1/", "", "deepseek-chat", config)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := langchainllms.GenerateFromSinglePrompt(context.Background(), llm, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "hi" {
+		t.Errorf("unexpected response %q", res)
+	}
+}
+
+func TestAddExtraBody(t *testing.T) {
+	if res := addExtraBody([]byte("not json"), map[string]string{"a": "1"}); string(res) != "not json" {
+		t.Errorf("body which is not json should not be changed, got %s", res)
+	}
+	res := addExtraBody([]byte(`{"a":1}`), map[string]string{"a": "2", "b": "[1,2]", "c": "text"})
+	if string(res) != `{"a":1,"b":[1,2],"c":"text"}` {
+		t.Errorf("unexpected body %s", res)
+	}
+}
@@ -21,21 +21,30 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 
+	"github.com/r3labs/sse/v2"
 	langchainllms "github.com/tmc/langchaingo/llms"
 
 	"github.com/kubeagi/arcadia/pkg/llms"
 )
 
 const (
-	DashScopeChatURL          = "https://dashscope.aliyuncs.com/api/v1/services/aigc/text-generation/generation"
-	DashScopeTextEmbeddingURL = "https://dashscope.aliyuncs.com/api/v1/services/embeddings/text-embedding/text-embedding"
-	DashScopeTaskURL          = "https://dashscope.aliyuncs.com/api/v1/tasks/"
+	DashScopeBaseURL          = "https://dashscope.aliyuncs.com"
+	DashScopeChatURL          = DashScopeBaseURL + "/api/v1/services/aigc/text-generation/generation"
+	DashScopeTextEmbeddingURL = DashScopeBaseURL + "/api/v1/services/embeddings/text-embedding/text-embedding"
+	DashScopeTaskURL          = DashScopeBaseURL + "/api/v1/tasks/"
 )
 
 type Model string
 
 const (
+	// 通义千问商业版模型, 分别对应不同的效果和成本
+	QWENTurbo Model = "qwen-turbo"
+	QWENPlus  Model = "qwen-plus"
+	QWENMax   Model = "qwen-max"
 	// 通义千问对外开源的 14B / 7B 规模参数量的经过人类指令对齐的 chat 模型
 	QWEN14BChat Model = "qwen-14b-chat"
 	QWEN7BChat  Model = "qwen-7b-chat"
@@ -45,21 +54,45 @@ const (
 	BAICHUAN7BV1     Model = "baichuan-7b-v1"          // baichuan-7B 是由百川智能开发的一个开源的大规模预训练模型。基于 Transformer 结构，在大约 1.2 万亿 tokens 上训练的 70 亿参数模型，支持中英双语，上下文窗口长度为 4096。在标准的中文和英文权威 benchmark（C-EVAL/MMLU）上均取得同尺寸最好的效果。
 	CHATGLM6BV2      Model = "chatglm-6b-v2"           // ChatGLM2 模型是由智谱 AI 出品的大规模语言模型，它在灵积平台上的模型名称为 "chatglm-6b-v2".
 	EmbeddingV1      Model = "text-embedding-v1"       // 通用文本向量 同步调用
+	EmbeddingV2      Model = "text-embedding-v2"       // 通用文本向量 v2 同步调用
 	EmbeddingAsyncV1 Model = "text-embedding-async-v1" // 通用文本向量 批处理调用
+	EmbeddingAsyncV2 Model = "text-embedding-async-v2" // 通用文本向量 v2 批处理调用
 )
 
 var _ llms.LLM = (*DashScope)(nil)
 
 type DashScope struct {
-	apiKey string
-	sse    bool
+	apiKey  string
+	sse     bool
+	baseURL string
 }
 
-func NewDashScope(apiKey string, sse bool) *DashScope {
-	return &DashScope{
+type Option func(*DashScope)
+
+// WithBaseURL overrides the default base url https://dashscope.aliyuncs.com
+func WithBaseURL(baseURL string) Option {
+	return func(z *DashScope) {
+		z.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+func NewDashScope(apiKey string, sse bool, opts ...Option) *DashScope {
+	z := &DashScope{
 		apiKey: apiKey,
 		sse:    sse,
 	}
+	for _, opt := range opts {
+		opt(z)
+	}
+	return z
+}
+
+// url replaces the default base url of the api url if a base url is set
+func (z *DashScope) url(apiURL string) string {
+	if z.baseURL == "" {
+		return apiURL
+	}
+	return z.baseURL + strings.TrimPrefix(apiURL, DashScopeBaseURL)
 }
 
 func (z DashScope) Type() llms.LLMType {
@@ -72,20 +105,117 @@ func (z *DashScope) Call(data []byte) (llms.Response, error) {
 	if err := params.Unmarshal(data); err != nil {
 		return nil, err
 	}
-	return do(context.TODO(), DashScopeChatURL, z.apiKey, data, z.sse, false, params.Model)
+	return do(context.TODO(), z.url(DashScopeChatURL), z.apiKey, data, z.sse, false, params.Model)
 }
 
+// Validate checks the api key and the model by a simple chat
 func (z *DashScope) Validate(ctx context.Context, options ...langchainllms.CallOption) (llms.Response, error) {
-	return nil, errors.New("not implemented")
+	opts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	params := DefaultModelParams()
+	if opts.Model != "" {
+		params.Model = Model(opts.Model)
+	}
+	params.Parameters.MaxTokens = 16
+	params.Input.Messages = []Message{{Role: User, Content: "Hello!"}}
+	return z.Chat(ctx, params)
+}
+
+// Chat calls the chat api with messages and returns the whole response
+func (z *DashScope) Chat(ctx context.Context, params ModelParams) (*Response, error) {
+	params.Parameters.ResultFormat = "message"
+	params.Parameters.IncrementalOutput = false
+	resp, err := req(ctx, z.url(DashScopeChatURL), z.apiKey, params.Marshal(), false, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respData := &Response{}
+	if err := json.NewDecoder(resp.Body).Decode(respData); err != nil {
+		return nil, fmt.Errorf("failed to decode response with status %s: %w", resp.Status, err)
+	}
+	if err := respData.Error(resp.StatusCode); err != nil {
+		return nil, err
+	}
+	return respData, nil
+}
+
+// StreamChat calls the chat api with sse, handler is called with the new content of each event.
+// It returns the whole response, whose content is the joined content of all events.
+func (z *DashScope) StreamChat(ctx context.Context, params ModelParams, handler func(ctx context.Context, delta string) error) (*Response, error) {
+	params.Parameters.ResultFormat = "message"
+	params.Parameters.IncrementalOutput = true
+	resp, err := req(ctx, z.url(DashScopeChatURL), z.apiKey, params.Marshal(), true, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respData := &Response{}
+		if err := json.NewDecoder(resp.Body).Decode(respData); err != nil {
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return nil, respData.Error(resp.StatusCode)
+	}
+
+	result := &Response{}
+	content := &strings.Builder{}
+	finishReason := Generating
+	client := NewSSEClient()
+	reader := sse.NewEventStreamReader(resp.Body, client.maxBufferSize)
+	for {
+		msg, err := reader.ReadEvent()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		event, err := client.processEvent(msg)
+		if err != nil || len(event.Data) == 0 {
+			continue
+		}
+		data := &Response{}
+		if err := json.Unmarshal(event.Data, data); err != nil {
+			return nil, fmt.Errorf("failed to decode event %s: %w", event.Data, err)
+		}
+		if string(event.Event) == "error" || data.Code != "" {
+			return nil, data.Error(http.StatusInternalServerError)
+		}
+		result.RequestID = data.RequestID
+		result.Usage = data.Usage
+		delta := data.String()
+		if len(data.Output.Choices) > 0 {
+			finishReason = data.Output.Choices[len(data.Output.Choices)-1].FinishReason
+		}
+		if delta == "" {
+			continue
+		}
+		content.WriteString(delta)
+		if handler != nil {
+			if err := handler(ctx, delta); err != nil {
+				return nil, err
+			}
+		}
+	}
+	result.Output.Choices = []Choice{{FinishReason: finishReason, Message: Message{Role: Assistant, Content: content.String()}}}
+	return result, nil
 }
 
 func (z *DashScope) CreateEmbedding(ctx context.Context, inputTexts []string, query bool) ([]Embeddings, error) {
+	return z.CreateEmbeddingWithModel(ctx, EmbeddingV1, inputTexts, query)
+}
+
+// CreateEmbeddingWithModel embeds the texts with the sync embedding model
+func (z *DashScope) CreateEmbeddingWithModel(ctx context.Context, model Model, inputTexts []string, query bool) ([]Embeddings, error) {
 	textType := TextTypeDocument
 	if query {
 		textType = TextTypeQuery
 	}
 	reqBody := EmbeddingRequest{
-		Model: EmbeddingV1,
+		Model: model,
 		Input: EmbeddingInput{
 			EmbeddingInputSync: &EmbeddingInputSync{
 				Texts: inputTexts,
@@ -99,7 +229,7 @@ func (z *DashScope) CreateEmbedding(ctx context.Context, inputTexts []string, qu
 	if err != nil {
 		return nil, err
 	}
-	resp, err := req(ctx, DashScopeTextEmbeddingURL, z.apiKey, data, false, false)
+	resp, err := req(ctx, z.url(DashScopeTextEmbeddingURL), z.apiKey, data, false, false)
 	if err != nil {
 		return nil, err
 	}
@@ -115,12 +245,17 @@ func (z *DashScope) CreateEmbedding(ctx context.Context, inputTexts []string, qu
 }
 
 func (z *DashScope) CreateEmbeddingAsync(ctx context.Context, inputURL string, query bool) (taskID string, err error) {
+	return z.CreateEmbeddingAsyncWithModel(ctx, EmbeddingAsyncV1, inputURL, query)
+}
+
+// CreateEmbeddingAsyncWithModel creates an async embedding task for the text file at inputURL, one text per line
+func (z *DashScope) CreateEmbeddingAsyncWithModel(ctx context.Context, model Model, inputURL string, query bool) (taskID string, err error) {
 	textType := TextTypeDocument
 	if query {
 		textType = TextTypeQuery
 	}
 	reqBody := EmbeddingRequest{
-		Model: EmbeddingAsyncV1,
+		Model: model,
 		Input: EmbeddingInput{
 			EmbeddingInputAsync: &EmbeddingInputAsync{
 				URL: inputURL,
@@ -134,7 +269,7 @@ func (z *DashScope) CreateEmbeddingAsync(ctx context.Context, inputURL string, q
 	if err != nil {
 		return "", err
 	}
-	resp, err := req(ctx, DashScopeTextEmbeddingURL, z.apiKey, data, false, true)
+	resp, err := req(ctx, z.url(DashScopeTextEmbeddingURL), z.apiKey, data, false, true)
 	if err != nil {
 		return "", err
 	}
@@ -149,8 +284,28 @@ func (z *DashScope) CreateEmbeddingAsync(ctx context.Context, inputURL string, q
 	return respData.Output.TaskID, nil
 }
 
+// GetTask returns the status of an async task
+func (z *DashScope) GetTask(ctx context.Context, taskID string) (*EmbeddingOutputASync, error) {
+	resp, err := req(ctx, z.url(DashScopeTaskURL)+taskID, z.apiKey, nil, false, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respData := &EmbeddingResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(respData); err != nil {
+		return nil, err
+	}
+	if err := respData.Error(resp.StatusCode); err != nil {
+		return nil, err
+	}
+	if respData.Output.EmbeddingOutputASync == nil {
+		return nil, fmt.Errorf("can't find data in resp:%+v", respData)
+	}
+	return respData.Output.EmbeddingOutputASync, nil
+}
+
 func (z *DashScope) GetTaskDetail(ctx context.Context, taskID string) (outURL string, err error) {
-	resp, err := req(ctx, DashScopeTaskURL+taskID, z.apiKey, nil, false, false)
+	resp, err := req(ctx, z.url(DashScopeTaskURL)+taskID, z.apiKey, nil, false, false)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,194 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashscope
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/tmc/langchaingo/callbacks"
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+	"k8s.io/klog/v2"
+)
+
+var (
+	ErrEmptyResponse = errors.New("no response")
+	ErrEmptyPrompt   = errors.New("empty prompt")
+)
+
+var (
+	_ langchainllms.Model = (*DashScopeLLM)(nil)
+)
+
+type LLMOption func(*DashScopeLLM)
+
+// WithModel sets the default model, which can be overridden by langchainllms.WithModel
+func WithModel(model string) LLMOption {
+	return func(l *DashScopeLLM) {
+		l.model = Model(model)
+	}
+}
+
+func WithCallback(callbacksHandler callbacks.Handler) LLMOption {
+	return func(l *DashScopeLLM) {
+		l.callbacksHandler = callbacksHandler
+	}
+}
+
+// WithClientOptions sets the options of the DashScope client, like WithBaseURL
+func WithClientOptions(opts ...Option) LLMOption {
+	return func(l *DashScopeLLM) {
+		l.clientOptions = append(l.clientOptions, opts...)
+	}
+}
+
+// DashScopeLLM is a langchaingo llm of DashScope chat models
+type DashScopeLLM struct {
+	c                *DashScope
+	model            Model
+	callbacksHandler callbacks.Handler
+	clientOptions    []Option
+}
+
+func NewDashScopeLLM(apiKey string, opts ...LLMOption) *DashScopeLLM {
+	l := &DashScopeLLM{model: QWENTurbo}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.c = NewDashScope(apiKey, false, l.clientOptions...)
+	return l
+}
+
+func (l *DashScopeLLM) GetNumTokens(text string) int {
+	return langchainllms.CountTokens("gpt2", text)
+}
+
+func (l *DashScopeLLM) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, l, prompt, options...)
+}
+
+func (l *DashScopeLLM) GenerateContent(ctx context.Context, messages []langchainllms.MessageContent, options ...langchainllms.CallOption) (resp *langchainllms.ContentResponse, err error) {
+	if l.callbacksHandler != nil {
+		l.callbacksHandler.HandleLLMGenerateContentStart(ctx, messages)
+		defer func() {
+			if err != nil {
+				l.callbacksHandler.HandleLLMError(ctx, err)
+			} else {
+				l.callbacksHandler.HandleLLMGenerateContentEnd(ctx, resp)
+			}
+		}()
+	}
+	opts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	params := l.params(opts)
+	params.Input.Messages = convertMessages(messages)
+	if len(params.Input.Messages) == 0 {
+		return nil, ErrEmptyPrompt
+	}
+
+	var res *Response
+	if opts.StreamingFunc != nil {
+		res, err = l.c.StreamChat(ctx, params, func(ctx context.Context, delta string) error {
+			return opts.StreamingFunc(ctx, []byte(delta))
+		})
+	} else {
+		res, err = l.c.Chat(ctx, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Output.Choices) == 0 && res.Output.Text == "" {
+		return nil, ErrEmptyResponse
+	}
+
+	generationInfo := map[string]any{
+		"PromptTokens":     res.Usage.InputTokens,
+		"CompletionTokens": res.Usage.OutputTokens,
+		"TotalTokens":      res.Usage.InputTokens + res.Usage.OutputTokens,
+	}
+	choices := make([]*langchainllms.ContentChoice, 0, len(res.Output.Choices))
+	for _, c := range res.Output.Choices {
+		choices = append(choices, &langchainllms.ContentChoice{
+			Content:        c.Message.Content,
+			StopReason:     string(c.FinishReason),
+			GenerationInfo: generationInfo,
+		})
+	}
+	if len(choices) == 0 {
+		choices = append(choices, &langchainllms.ContentChoice{Content: res.Output.Text, GenerationInfo: generationInfo})
+	}
+	return &langchainllms.ContentResponse{Choices: choices}, nil
+}
+
+func (l *DashScopeLLM) params(opts langchainllms.CallOptions) ModelParams {
+	params := DefaultModelParams()
+	params.Model = l.model
+	if opts.Model != "" {
+		params.Model = Model(opts.Model)
+	}
+	if opts.TopP > 0 && opts.TopP < 1 {
+		params.Parameters.TopP = float32(opts.TopP)
+	}
+	if opts.TopK > 0 {
+		params.Parameters.TopK = opts.TopK
+	}
+	if opts.Temperature > 0 && opts.Temperature < 2 {
+		params.Parameters.Temperature = float32(opts.Temperature)
+	}
+	if opts.MaxTokens > 0 {
+		params.Parameters.MaxTokens = opts.MaxTokens
+	}
+	if opts.Seed > 0 {
+		params.Parameters.Seed = opts.Seed
+	}
+	params.Parameters.Stop = opts.StopWords
+	return params
+}
+
+// convertMessages converts langchaingo messages to DashScope messages, only the text parts are kept
+func convertMessages(messages []langchainllms.MessageContent) []Message {
+	result := make([]Message, 0, len(messages))
+	for _, mc := range messages {
+		msg := Message{}
+		switch mc.Role {
+		case schema.ChatMessageTypeSystem:
+			msg.Role = System
+		case schema.ChatMessageTypeAI:
+			msg.Role = Assistant
+		case schema.ChatMessageTypeHuman, schema.ChatMessageTypeGeneric:
+			msg.Role = User
+		case schema.ChatMessageTypeFunction:
+			fallthrough
+		default:
+			klog.Infof("unsupported role: %s, just skip", mc.Role)
+			continue
+		}
+		texts := make([]string, 0, len(mc.Parts))
+		for _, part := range mc.Parts {
+			if text, ok := part.(langchainllms.TextContent); ok {
+				texts = append(texts, text.Text)
+			}
+		}
+		msg.Content = strings.Join(texts, "\n")
+		result = append(result, msg)
+	}
+	return result
+}
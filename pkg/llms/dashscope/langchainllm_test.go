@@ -0,0 +1,105 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashscope
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/services/aigc/text-generation/generation" {
+			http.NotFound(w, r)
+			return
+		}
+		params := ModelParams{}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Errorf("failed to decode request: %s", err)
+		}
+		if len(params.Input.Messages) != 2 || params.Input.Messages[0].Role != System || params.Input.Messages[1].Role != User {
+			t.Errorf("unexpected messages %v", params.Input.Messages)
+		}
+		if params.Model != QWENPlus {
+			t.Errorf("expect model %s, got %s", QWENPlus, params.Model)
+		}
+		if r.Header.Get("X-DashScope-SSE") != "enable" {
+			fmt.Fprint(w, `{"request_id":"1","output":{"choices":[{"finish_reason":"stop","message":{"role":"assistant","content":"hello world"}}]},"usage":{"input_tokens":5,"output_tokens":2}}`)
+			return
+		}
+		if !params.Parameters.IncrementalOutput {
+			t.Error("incremental output should be enabled when streaming")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i, delta := range []string{"hello", " world"} {
+			finishReason := "null"
+			if i == 1 {
+				finishReason = "stop"
+			}
+			fmt.Fprintf(w, "id:%d\nevent:result\n:HTTP_STATUS/200\ndata:{\"request_id\":\"1\",\"output\":{\"choices\":[{\"finish_reason\":%q,\"message\":{\"role\":\"assistant\",\"content\":%q}}]},\"usage\":{\"input_tokens\":5,\"output_tokens\":%d}}\n\n", i+1, finishReason, delta, i+1)
+		}
+	}))
+}
+
+func TestDashScopeLLM(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	llm := NewDashScopeLLM("fake", WithModel(string(QWENPlus)), WithClientOptions(WithBaseURL(server.URL)))
+	messages := []langchainllms.MessageContent{
+		langchainllms.TextParts(schema.ChatMessageTypeSystem, "You are a helpful assistant."),
+		langchainllms.TextParts(schema.ChatMessageTypeHuman, "hello"),
+	}
+	resp, err := llm.GenerateContent(context.Background(), messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Choices) != 1 || resp.Choices[0].Content != "hello world" || resp.Choices[0].StopReason != "stop" {
+		t.Fatalf("unexpected response %v", resp.Choices)
+	}
+	if resp.Choices[0].GenerationInfo["TotalTokens"] != 7 {
+		t.Errorf("unexpected generation info %v", resp.Choices[0].GenerationInfo)
+	}
+
+	var streamed strings.Builder
+	resp, err = llm.GenerateContent(context.Background(), messages, langchainllms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+		streamed.Write(chunk)
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if streamed.String() != "hello world" || resp.Choices[0].Content != "hello world" {
+		t.Errorf("unexpected streamed content %q, response %q", streamed.String(), resp.Choices[0].Content)
+	}
+	if resp.Choices[0].StopReason != "stop" || resp.Choices[0].GenerationInfo["CompletionTokens"] != 2 {
+		t.Errorf("unexpected streamed response %v", resp.Choices[0])
+	}
+
+	if _, err = llm.GenerateContent(context.Background(), nil); err != ErrEmptyPrompt {
+		t.Errorf("expect %v, got %v", ErrEmptyPrompt, err)
+	}
+}
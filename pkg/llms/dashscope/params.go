@@ -53,11 +53,19 @@ type Input struct {
 	History  *[]string `json:"history,omitempty"`
 }
 
+// +kubebuilder:object:generate=true
+
 type Parameters struct {
 	TopP         float32 `json:"top_p,omitempty"`
 	TopK         int     `json:"top_k,omitempty"`
 	Seed         int     `json:"seed,omitempty"`
 	ResultFormat string  `json:"result_format,omitempty"`
+	Temperature  float32 `json:"temperature,omitempty"`
+	MaxTokens    int     `json:"max_tokens,omitempty"`
+	// Stop words, the generation stops before any of them
+	Stop []string `json:"stop,omitempty"`
+	// IncrementalOutput makes each stream event only contain the new content
+	IncrementalOutput bool `json:"incremental_output,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
@@ -106,6 +114,9 @@ func ValidateModelParams(params ModelParams) error {
 	if params.Parameters.TopP < 0 || params.Parameters.TopP > 1 {
 		return errors.New("top_p must be in (0, 1)")
 	}
+	if params.Parameters.Temperature < 0 || params.Parameters.Temperature > 2 {
+		return errors.New("temperature must be in [0, 2)")
+	}
 
 	return nil
 }
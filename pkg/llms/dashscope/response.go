@@ -19,6 +19,8 @@ package dashscope
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"net/http"
 
 	"github.com/kubeagi/arcadia/pkg/llms"
 )
@@ -61,6 +63,19 @@ type Choice struct {
 type Usage struct {
 	OutputTokens int `json:"output_tokens"`
 	InputTokens  int `json:"input_tokens"`
+	TotalTokens  int `json:"total_tokens,omitempty"`
+}
+
+// Error returns the error of a failed response
+func (response *CommonResponse) Error(httpStatus int) error {
+	if response.Code == "" && (httpStatus == http.StatusOK || httpStatus == 0) {
+		return nil
+	}
+	status := response.StatusCode
+	if status == 0 {
+		status = httpStatus
+	}
+	return fmt.Errorf("dashscope request %s failed with status %d, code: %s, message: %s", response.RequestID, status, response.Code, response.Message)
 }
 
 func (response *Response) Unmarshal(bytes []byte) error {
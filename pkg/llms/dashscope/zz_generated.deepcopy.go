@@ -71,7 +71,7 @@ func (in *Message) DeepCopy() *Message {
 func (in *ModelParams) DeepCopyInto(out *ModelParams) {
 	*out = *in
 	in.Input.DeepCopyInto(&out.Input)
-	out.Parameters = in.Parameters
+	in.Parameters.DeepCopyInto(&out.Parameters)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelParams.
@@ -83,3 +83,23 @@ func (in *ModelParams) DeepCopy() *ModelParams {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Parameters) DeepCopyInto(out *Parameters) {
+	*out = *in
+	if in.Stop != nil {
+		in, out := &in.Stop, &out.Stop
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Parameters.
+func (in *Parameters) DeepCopy() *Parameters {
+	if in == nil {
+		return nil
+	}
+	out := new(Parameters)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package llms
+
+import (
+	"fmt"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// FunctionCallPart is a function call of the ai, sent back to the llm in the ai message before its result.
+// The embedded text describes the call for the llms which don't support function calling.
+type FunctionCallPart struct {
+	langchainllms.TextContent
+	Name      string
+	Arguments string
+}
+
+// FunctionResultPart is the result of a function call, sent to the llm in a function message.
+// The embedded text is the result itself.
+type FunctionResultPart struct {
+	langchainllms.TextContent
+	Name string
+}
+
+// FunctionCallMessage returns the ai message which calls the function with the arguments
+func FunctionCallMessage(name, arguments string) langchainllms.MessageContent {
+	return langchainllms.MessageContent{
+		Role: schema.ChatMessageTypeAI,
+		Parts: []langchainllms.ContentPart{FunctionCallPart{
+			TextContent: langchainllms.TextContent{Text: fmt.Sprintf("Invoking function %s with %s", name, arguments)},
+			Name:        name,
+			Arguments:   arguments,
+		}},
+	}
+}
+
+// FunctionResultMessage returns the function message with the result of the function call
+func FunctionResultMessage(name, result string) langchainllms.MessageContent {
+	return langchainllms.MessageContent{
+		Role: schema.ChatMessageTypeFunction,
+		Parts: []langchainllms.ContentPart{FunctionResultPart{
+			TextContent: langchainllms.TextContent{Text: result},
+			Name:        name,
+		}},
+	}
+}
+
+// MessageText returns the text of the message, joined by new lines if it has multiple text parts
+func MessageText(mc langchainllms.MessageContent) string {
+	text := ""
+	for _, part := range mc.Parts {
+		var s string
+		switch p := part.(type) {
+		case langchainllms.TextContent:
+			s = p.Text
+		case FunctionCallPart:
+			s = p.Text
+		case FunctionResultPart:
+			s = p.Text
+		default:
+			continue
+		}
+		if text != "" {
+			text += "\n"
+		}
+		text += s
+	}
+	return text
+}
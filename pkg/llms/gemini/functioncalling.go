@@ -0,0 +1,328 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/pkg/llms"
+)
+
+// GeminiAPIURL is the base url of the Gemini REST api
+const GeminiAPIURL = "https://generativelanguage.googleapis.com/v1beta"
+
+var _ langchainllms.Model = (*FunctionCallingLLM)(nil)
+
+// FunctionCallingLLM calls functions by the REST api of Gemini, other requests are served by the wrapped llm,
+// because the Go sdk used by langchaingo googleai doesn't support tools.
+type FunctionCallingLLM struct {
+	LLM langchainllms.Model
+
+	apiKey     string
+	model      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+type Option func(*FunctionCallingLLM)
+
+// WithBaseURL sets the base url of the api, GeminiAPIURL by default
+func WithBaseURL(baseURL string) Option {
+	return func(l *FunctionCallingLLM) {
+		l.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sets the http client of requests, http.DefaultClient by default
+func WithHTTPClient(client *http.Client) Option {
+	return func(l *FunctionCallingLLM) {
+		l.httpClient = client
+	}
+}
+
+func NewFunctionCallingLLM(llm langchainllms.Model, apiKey, model string, opts ...Option) *FunctionCallingLLM {
+	l := &FunctionCallingLLM{
+		LLM:        llm,
+		apiKey:     apiKey,
+		model:      model,
+		baseURL:    GeminiAPIURL,
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+type part struct {
+	Text             string            `json:"text,omitempty"`
+	FunctionCall     *functionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *functionResponse `json:"functionResponse,omitempty"`
+}
+
+type functionCall struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+}
+
+type functionResponse struct {
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+type content struct {
+	Role  string `json:"role"`
+	Parts []part `json:"parts"`
+}
+
+type functionDeclaration struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+type generationConfig struct {
+	Temperature     float64  `json:"temperature,omitempty"`
+	TopP            float64  `json:"topP,omitempty"`
+	MaxOutputTokens int      `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
+}
+
+type generateContentRequest struct {
+	Contents []content `json:"contents"`
+	Tools    []struct {
+		FunctionDeclarations []functionDeclaration `json:"functionDeclarations"`
+	} `json:"tools,omitempty"`
+	GenerationConfig generationConfig `json:"generationConfig"`
+}
+
+type generateContentResponse struct {
+	Candidates []struct {
+		Content      content `json:"content"`
+		FinishReason string  `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+func (l *FunctionCallingLLM) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, l, prompt, options...)
+}
+
+func (l *FunctionCallingLLM) GenerateContent(ctx context.Context, messages []langchainllms.MessageContent, options ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	opts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if len(opts.Functions) == 0 {
+		return l.LLM.GenerateContent(ctx, messages, options...)
+	}
+	return l.generateWithTools(ctx, messages, opts)
+}
+
+// contents converts the messages to the contents of the api.
+// Gemini has no system role, so the system messages are prepended to the first user message.
+func contents(messages []langchainllms.MessageContent) ([]content, error) {
+	res := make([]content, 0, len(messages))
+	var system []string
+	for _, mc := range messages {
+		c := content{}
+		switch mc.Role {
+		case schema.ChatMessageTypeSystem:
+			system = append(system, llms.MessageText(mc))
+			continue
+		case schema.ChatMessageTypeHuman, schema.ChatMessageTypeGeneric:
+			c.Role = "user"
+			text := llms.MessageText(mc)
+			if len(system) > 0 {
+				text = strings.Join(append(system, text), "\n\n")
+				system = nil
+			}
+			c.Parts = []part{{Text: text}}
+		case schema.ChatMessageTypeAI:
+			c.Role = "model"
+			for _, p := range mc.Parts {
+				switch p := p.(type) {
+				case llms.FunctionCallPart:
+					args := make(map[string]any)
+					if err := json.Unmarshal([]byte(p.Arguments), &args); err != nil {
+						return nil, fmt.Errorf("invalid arguments of function %s: %w", p.Name, err)
+					}
+					c.Parts = append(c.Parts, part{FunctionCall: &functionCall{Name: p.Name, Args: args}})
+				case langchainllms.TextContent:
+					c.Parts = append(c.Parts, part{Text: p.Text})
+				}
+			}
+		case schema.ChatMessageTypeFunction:
+			c.Role = "function"
+			for _, p := range mc.Parts {
+				if r, ok := p.(llms.FunctionResultPart); ok {
+					c.Parts = append(c.Parts, part{FunctionResponse: &functionResponse{
+						Name:     r.Name,
+						Response: map[string]any{"name": r.Name, "content": r.Text},
+					}})
+				}
+			}
+		default:
+			return nil, fmt.Errorf("role %v not supported", mc.Role)
+		}
+		if len(c.Parts) > 0 {
+			res = append(res, c)
+		}
+	}
+	if len(system) > 0 {
+		res = append(res, content{Role: "user", Parts: []part{{Text: strings.Join(system, "\n\n")}}})
+	}
+	return res, nil
+}
+
+// parameters converts the json schema of function parameters to the schema of Gemini, whose types are upper case
+func parameters(params any) (any, error) {
+	if params == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var res any
+	if err = json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	var upper func(v any)
+	upper = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, field := range v {
+				if s, ok := field.(string); ok && k == "type" {
+					v[k] = strings.ToUpper(s)
+					continue
+				}
+				upper(field)
+			}
+		case []any:
+			for _, item := range v {
+				upper(item)
+			}
+		}
+	}
+	upper(res)
+	return res, nil
+}
+
+// generateWithTools calls the generateContent api with the functions of the options as function declarations
+func (l *FunctionCallingLLM) generateWithTools(ctx context.Context, messages []langchainllms.MessageContent, opts langchainllms.CallOptions) (*langchainllms.ContentResponse, error) {
+	cs, err := contents(messages)
+	if err != nil {
+		return nil, err
+	}
+	req := generateContentRequest{
+		Contents: cs,
+		GenerationConfig: generationConfig{
+			Temperature:     opts.Temperature,
+			TopP:            opts.TopP,
+			MaxOutputTokens: opts.MaxTokens,
+			StopSequences:   opts.StopWords,
+		},
+	}
+	declarations := make([]functionDeclaration, 0, len(opts.Functions))
+	for _, fn := range opts.Functions {
+		params, err := parameters(fn.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		declarations = append(declarations, functionDeclaration{Name: fn.Name, Description: fn.Description, Parameters: params})
+	}
+	req.Tools = append(req.Tools, struct {
+		FunctionDeclarations []functionDeclaration `json:"functionDeclarations"`
+	}{FunctionDeclarations: declarations})
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	model := l.model
+	if opts.Model != "" {
+		model = opts.Model
+	}
+	u := fmt.Sprintf("%s/models/%s:generateContent?key=%s", l.baseURL, url.PathEscape(model), url.QueryEscape(l.apiKey))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := l.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gemini request failed with status %d: %s", httpResp.StatusCode, data)
+	}
+	resp := &generateContentResponse{}
+	if err = json.Unmarshal(data, resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Candidates) == 0 {
+		return nil, errors.New("no candidates in the response")
+	}
+	choices := make([]*langchainllms.ContentChoice, len(resp.Candidates))
+	for i, candidate := range resp.Candidates {
+		choice := &langchainllms.ContentChoice{
+			StopReason: candidate.FinishReason,
+			GenerationInfo: map[string]any{
+				"PromptTokens":     resp.UsageMetadata.PromptTokenCount,
+				"CompletionTokens": resp.UsageMetadata.CandidatesTokenCount,
+				"TotalTokens":      resp.UsageMetadata.TotalTokenCount,
+			},
+		}
+		for _, p := range candidate.Content.Parts {
+			if p.FunctionCall != nil && choice.FuncCall == nil {
+				args, err := json.Marshal(p.FunctionCall.Args)
+				if err != nil {
+					return nil, err
+				}
+				choice.FuncCall = &schema.FunctionCall{Name: p.FunctionCall.Name, Arguments: string(args)}
+			}
+			choice.Content += p.Text
+		}
+		choices[i] = choice
+	}
+	if opts.StreamingFunc != nil && choices[0].FuncCall == nil && choices[0].Content != "" {
+		if err = opts.StreamingFunc(ctx, []byte(choices[0].Content)); err != nil {
+			return nil, err
+		}
+	}
+	return &langchainllms.ContentResponse{Choices: choices}, nil
+}
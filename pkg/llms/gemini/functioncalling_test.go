@@ -0,0 +1,131 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/pkg/llms"
+)
+
+type fakeLLM struct {
+	called bool
+}
+
+func (f *fakeLLM) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+func (f *fakeLLM) GenerateContent(ctx context.Context, messages []langchainllms.MessageContent, options ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	f.called = true
+	return &langchainllms.ContentResponse{Choices: []*langchainllms.ContentChoice{{Content: "from sdk"}}}, nil
+}
+
+func TestFunctionCallingLLM(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-pro:generateContent" || r.URL.Query().Get("key") != "fake" {
+			http.NotFound(w, r)
+			return
+		}
+		req := generateContentRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %s", err)
+		}
+		if len(req.Tools) != 1 || len(req.Tools[0].FunctionDeclarations) != 1 {
+			t.Fatalf("unexpected tools %+v", req.Tools)
+		}
+		params, _ := json.Marshal(req.Tools[0].FunctionDeclarations[0].Parameters)
+		if string(params) != `{"properties":{"city":{"type":"STRING"}},"type":"OBJECT"}` {
+			t.Errorf("unexpected parameters %s", params)
+		}
+		roles := make([]string, 0, len(req.Contents))
+		for _, c := range req.Contents {
+			roles = append(roles, c.Role)
+		}
+		if text := req.Contents[0].Parts[0].Text; text != "You are a helpful assistant.\n\nHow is the weather in Beijing?" {
+			t.Errorf("system message should be prepended to the user message, got %q", text)
+		}
+		switch strings.Join(roles, ",") {
+		case "user":
+			fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"weather","args":{"city":"Beijing"}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5,"totalTokenCount":15}}`)
+		case "user,model,function":
+			call, result := req.Contents[1].Parts[0], req.Contents[2].Parts[0]
+			if call.FunctionCall == nil || call.FunctionCall.Name != "weather" || call.FunctionCall.Args["city"] != "Beijing" {
+				t.Errorf("unexpected function call %+v", call)
+			}
+			if result.FunctionResponse == nil || result.FunctionResponse.Name != "weather" || result.FunctionResponse.Response["content"] != "sunny" {
+				t.Errorf("unexpected function response %+v", result)
+			}
+			fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"It is sunny in Beijing."}]},"finishReason":"STOP"}]}`)
+		default:
+			t.Errorf("unexpected roles %v", roles)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	sdk := &fakeLLM{}
+	llm := NewFunctionCallingLLM(sdk, "fake", "gemini-pro", WithBaseURL(server.URL))
+	messages := []langchainllms.MessageContent{
+		langchainllms.TextParts(schema.ChatMessageTypeSystem, "You are a helpful assistant."),
+		langchainllms.TextParts(schema.ChatMessageTypeHuman, "How is the weather in Beijing?"),
+	}
+	resp, err := llm.GenerateContent(context.Background(), messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sdk.called || resp.Choices[0].Content != "from sdk" {
+		t.Errorf("requests without functions should be served by the wrapped llm")
+	}
+
+	options := []langchainllms.CallOption{
+		langchainllms.WithFunctions([]langchainllms.FunctionDefinition{{
+			Name:        "weather",
+			Description: "query the weather of a city",
+			Parameters:  map[string]any{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}},
+		}}),
+	}
+	resp, err = llm.GenerateContent(context.Background(), messages, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := resp.Choices[0].FuncCall
+	if call == nil || call.Name != "weather" || call.Arguments != `{"city":"Beijing"}` {
+		t.Fatalf("unexpected function call %+v", call)
+	}
+	if resp.Choices[0].GenerationInfo["TotalTokens"] != 15 {
+		t.Errorf("unexpected generation info %v", resp.Choices[0].GenerationInfo)
+	}
+
+	messages = append(messages, llms.FunctionCallMessage(call.Name, call.Arguments), llms.FunctionResultMessage(call.Name, "sunny"))
+	resp, err = llm.GenerateContent(context.Background(), messages, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Choices[0].FuncCall != nil || resp.Choices[0].Content != "It is sunny in Beijing." {
+		t.Errorf("unexpected response %+v", resp.Choices[0])
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package llmgroup provides a langchaingo llms.Model which dispatches requests across several llms,
+// with failover, load balancing and circuit breaking.
+package llmgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"k8s.io/klog/v2"
+)
+
+type Strategy string
+
+const (
+	StrategyPriority           Strategy = "priority"
+	StrategyWeightedRoundRobin Strategy = "weightedRoundRobin"
+	StrategyLeastLatency       Strategy = "leastLatency"
+)
+
+var (
+	ErrNoMembers        = errors.New("no members in llm group")
+	ErrAllCircuitsOpen  = errors.New("circuits of all members are open")
+	ErrAllMembersFailed = errors.New("all attempted members failed")
+)
+
+// Member is a model in the group
+type Member struct {
+	// Name identifies the member in the group, it is also the key of the member's circuit breaker and statistics
+	Name  string
+	Model langchainllms.Model
+	// ModelName overrides the model in the call options if set
+	ModelName string
+	Priority  int
+	Weight    int
+}
+
+// Config of the group
+type Config struct {
+	Strategy Strategy
+	// MaxAttempts is the max number of members to try for a request, all members are tried if it is 0
+	MaxAttempts int
+	// ErrorCodes only fails over on errors which contain one of these codes, all errors are failed over if empty
+	ErrorCodes []string
+	// FailureThreshold is the number of consecutive failures to open the circuit of a member, disabled if it is 0
+	FailureThreshold int
+	// OpenDuration is how long a member is skipped after its circuit is opened
+	OpenDuration time.Duration
+}
+
+// Group is a llms.Model which dispatches requests to its members
+type Group struct {
+	name    string
+	config  Config
+	members []Member
+	state   *state
+	// retryable matches the errors to fail over on
+	retryable *regexp.Regexp
+}
+
+var _ langchainllms.Model = (*Group)(nil)
+
+// New creates a group, the groups with the same name share the circuit breakers and statistics of members,
+// so the group can be created for each request.
+func New(name string, config Config, members ...Member) (*Group, error) {
+	if len(members) == 0 {
+		return nil, ErrNoMembers
+	}
+	g := &Group{
+		name:    name,
+		config:  config,
+		members: members,
+		state:   getState(name),
+	}
+	if len(config.ErrorCodes) > 0 {
+		codes := make([]string, 0, len(config.ErrorCodes))
+		for _, code := range config.ErrorCodes {
+			codes = append(codes, regexp.QuoteMeta(code))
+		}
+		g.retryable = regexp.MustCompile(fmt.Sprintf(`\b(%s)\b`, strings.Join(codes, "|")))
+	}
+	return g, nil
+}
+
+func (g *Group) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, g, prompt, options...)
+}
+
+func (g *Group) GenerateContent(ctx context.Context, messages []langchainllms.MessageContent, options ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	candidates := g.candidates(time.Now())
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("llm group %s: %w", g.name, ErrAllCircuitsOpen)
+	}
+	if g.config.MaxAttempts > 0 && len(candidates) > g.config.MaxAttempts {
+		candidates = candidates[:g.config.MaxAttempts]
+	}
+	logger := klog.FromContext(ctx)
+	errs := make([]error, 0, len(candidates))
+	for _, m := range candidates {
+		opts, streamed := g.callOptions(m, options)
+		start := time.Now()
+		resp, err := m.Model.GenerateContent(ctx, messages, opts...)
+		if err == nil {
+			g.state.success(m.Name, time.Since(start))
+			return resp, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", m.Name, err))
+		if ctx.Err() != nil || !g.isRetryable(err) {
+			return nil, err
+		}
+		g.state.failure(m.Name, time.Now(), g.config.FailureThreshold, g.config.OpenDuration)
+		// the partial answer has been sent to the user, can't fail over anymore
+		if *streamed {
+			return nil, err
+		}
+		logger.Info("llm group member failed, try next one", "group", g.name, "member", m.Name, "error", err)
+	}
+	return nil, fmt.Errorf("llm group %s: %w: %w", g.name, ErrAllMembersFailed, errors.Join(errs...))
+}
+
+// callOptions overrides the model of the options, and records whether any chunk is streamed
+func (g *Group) callOptions(m Member, options []langchainllms.CallOption) ([]langchainllms.CallOption, *bool) {
+	streamed := new(bool)
+	opts := make([]langchainllms.CallOption, 0, len(options)+2)
+	opts = append(opts, options...)
+	if m.ModelName != "" {
+		opts = append(opts, langchainllms.WithModel(m.ModelName))
+	}
+	callOpts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&callOpts)
+	}
+	if streamingFunc := callOpts.StreamingFunc; streamingFunc != nil {
+		opts = append(opts, langchainllms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+			*streamed = true
+			return streamingFunc(ctx, chunk)
+		}))
+	}
+	return opts, streamed
+}
+
+func (g *Group) isRetryable(err error) bool {
+	if g.retryable == nil {
+		return true
+	}
+	return g.retryable.MatchString(err.Error())
+}
+
+// candidates returns the members to try in order, members with open circuits are skipped
+func (g *Group) candidates(now time.Time) []Member {
+	members := make([]Member, 0, len(g.members))
+	for _, m := range g.members {
+		if g.state.available(m.Name, now) {
+			members = append(members, m)
+		}
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	// failover in priority order, whichever member is chosen first
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].Priority < members[j].Priority
+	})
+	switch g.config.Strategy {
+	case StrategyWeightedRoundRobin:
+		first := g.state.nextWeighted(members)
+		members = append([]Member{members[first]}, append(members[:first:first], members[first+1:]...)...)
+	case StrategyLeastLatency:
+		latencies := g.state.latencies(members)
+		sort.SliceStable(members, func(i, j int) bool {
+			return latencies[members[i].Name] < latencies[members[j].Name]
+		})
+	}
+	return members
+}
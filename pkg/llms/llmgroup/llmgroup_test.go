@@ -0,0 +1,163 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package llmgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+// fakeModel answers with its name, or fails with err
+type fakeModel struct {
+	name  string
+	err   error
+	calls int
+	model string
+}
+
+func (f *fakeModel) GenerateContent(_ context.Context, _ []langchainllms.MessageContent, options ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	f.calls++
+	opts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	f.model = opts.Model
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &langchainllms.ContentResponse{Choices: []*langchainllms.ContentChoice{{Content: f.name}}}, nil
+}
+
+func (f *fakeModel) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+func TestPriorityFailover(t *testing.T) {
+	primary := &fakeModel{name: "primary", err: errors.New("API returned unexpected status code: 429")}
+	backup := &fakeModel{name: "backup"}
+	g, err := New(t.Name(), Config{Strategy: StrategyPriority, ErrorCodes: []string{"429"}},
+		Member{Name: "backup", Model: backup, ModelName: "glm-4", Priority: 2},
+		Member{Name: "primary", Model: primary, Priority: 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := g.Call(context.Background(), "hi", langchainllms.WithModel("gpt-4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "backup" || primary.calls != 1 {
+		t.Errorf("expect failover to backup after primary, got %s with %d calls of primary", out, primary.calls)
+	}
+	if primary.model != "gpt-4" || backup.model != "glm-4" {
+		t.Errorf("expect model of member to override, got %s and %s", primary.model, backup.model)
+	}
+
+	// errors without the configured codes are returned directly
+	primary.err = errors.New("API returned unexpected status code: 400")
+	if _, err := g.Call(context.Background(), "hi"); err == nil || backup.calls != 1 {
+		t.Errorf("expect no failover on 400, got %v with %d calls of backup", err, backup.calls)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	primary := &fakeModel{name: "primary", err: errors.New("service unavailable")}
+	backup := &fakeModel{name: "backup"}
+	g, err := New(t.Name(), Config{FailureThreshold: 2, OpenDuration: time.Hour},
+		Member{Name: "primary", Model: primary, Priority: 1},
+		Member{Name: "backup", Model: backup, Priority: 2},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := g.Call(context.Background(), "hi"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if primary.calls != 2 {
+		t.Errorf("expect primary skipped after its circuit opens, got %d calls", primary.calls)
+	}
+
+	backup.err = errors.New("service unavailable")
+	for i := 0; i < 2; i++ {
+		_, _ = g.Call(context.Background(), "hi")
+	}
+	if _, err := g.Call(context.Background(), "hi"); !errors.Is(err, ErrAllCircuitsOpen) {
+		t.Errorf("expect %v, got %v", ErrAllCircuitsOpen, err)
+	}
+}
+
+func TestWeightedRoundRobin(t *testing.T) {
+	a, b := &fakeModel{name: "a"}, &fakeModel{name: "b"}
+	g, err := New(t.Name(), Config{Strategy: StrategyWeightedRoundRobin},
+		Member{Name: "a", Model: a, Weight: 3},
+		Member{Name: "b", Model: b, Weight: 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 8; i++ {
+		if _, err := g.Call(context.Background(), "hi"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.calls != 6 || b.calls != 2 {
+		t.Errorf("expect calls split by weights 6:2, got %d:%d", a.calls, b.calls)
+	}
+}
+
+func TestStreamedNoFailover(t *testing.T) {
+	streamErr := errors.New("connection reset")
+	primary := &streamingModel{err: streamErr}
+	backup := &fakeModel{name: "backup"}
+	g, err := New(t.Name(), Config{},
+		Member{Name: "primary", Model: primary, Priority: 1},
+		Member{Name: "backup", Model: backup, Priority: 2},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.Call(context.Background(), "hi", langchainllms.WithStreamingFunc(func(context.Context, []byte) error { return nil }))
+	if !errors.Is(err, streamErr) || backup.calls != 0 {
+		t.Errorf("expect no failover after streaming started, got %v with %d calls of backup", err, backup.calls)
+	}
+}
+
+// streamingModel streams a chunk and then fails
+type streamingModel struct {
+	err error
+}
+
+func (s *streamingModel) GenerateContent(ctx context.Context, _ []langchainllms.MessageContent, options ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	opts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if opts.StreamingFunc != nil {
+		_ = opts.StreamingFunc(ctx, []byte("partial"))
+	}
+	return nil, s.err
+}
+
+func (s *streamingModel) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, s, prompt, options...)
+}
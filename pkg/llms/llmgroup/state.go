@@ -0,0 +1,122 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package llmgroup
+
+import (
+	"sync"
+	"time"
+)
+
+// latencyDecay is the weight of the newest latency in the moving average
+const latencyDecay = 0.3
+
+// states keeps the state of groups by name, so it survives the groups created for each request
+var states sync.Map
+
+func getState(name string) *state {
+	s, _ := states.LoadOrStore(name, &state{members: make(map[string]*memberState)})
+	return s.(*state)
+}
+
+type state struct {
+	mu      sync.Mutex
+	members map[string]*memberState
+}
+
+type memberState struct {
+	// consecutiveFailures is reset once the member succeeds
+	consecutiveFailures int
+	openUntil           time.Time
+	// latency is the exponential moving average of the latency of successful calls
+	latency time.Duration
+	// currentWeight is used by the smooth weighted round robin
+	currentWeight int
+}
+
+func (s *state) member(name string) *memberState {
+	m, ok := s.members[name]
+	if !ok {
+		m = &memberState{}
+		s.members[name] = m
+	}
+	return m
+}
+
+// available returns false if the circuit of the member is open,
+// the member is allowed to be tried again once the open duration passed.
+func (s *state) available(name string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !now.Before(s.member(name).openUntil)
+}
+
+func (s *state) success(name string, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.member(name)
+	m.consecutiveFailures = 0
+	m.openUntil = time.Time{}
+	if m.latency == 0 {
+		m.latency = latency
+	} else {
+		m.latency = time.Duration(latencyDecay*float64(latency) + (1-latencyDecay)*float64(m.latency))
+	}
+}
+
+func (s *state) failure(name string, now time.Time, threshold int, openDuration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.member(name)
+	m.consecutiveFailures++
+	if threshold > 0 && m.consecutiveFailures >= threshold {
+		m.openUntil = now.Add(openDuration)
+		// a single failure opens the circuit again after it is half-open
+		m.consecutiveFailures = threshold - 1
+	}
+}
+
+func (s *state) latencies(members []Member) map[string]time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make(map[string]time.Duration, len(members))
+	for _, m := range members {
+		res[m.Name] = s.member(m.Name).latency
+	}
+	return res
+}
+
+// nextWeighted picks a member by the smooth weighted round robin, returns its index
+func (s *state) nextWeighted(members []Member) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	best, total := 0, 0
+	var bestState *memberState
+	for i, m := range members {
+		weight := m.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		total += weight
+		ms := s.member(m.Name)
+		ms.currentWeight += weight
+		if bestState == nil || ms.currentWeight > bestState.currentWeight {
+			best, bestState = i, ms
+		}
+	}
+	bestState.currentWeight -= total
+	return best
+}
@@ -18,7 +18,9 @@ package llms
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"strings"
 
 	langchainllms "github.com/tmc/langchaingo/llms"
 )
@@ -30,12 +32,16 @@ const (
 	ZhiPuAI   LLMType = "zhipuai"
 	DashScope LLMType = "dashscope"
 	Gemini    LLMType = "gemini"
-	Unknown   LLMType = "unknown"
+	// OpenAICompatible is a service which speaks the OpenAI protocol, like DeepSeek, Moonshot, vLLM or Ollama
+	OpenAICompatible LLMType = "openai-compatible"
+	Unknown          LLMType = "unknown"
 )
 
 var (
 	OpenAIModels = []string{"gpt-3.5", "gpt-3.5-turbo"}
 	GeminiModels = []string{"gemini-pro"}
+	// DashScopeModels are the chat models of DashScope(Qwen)
+	DashScopeModels = []string{"qwen-turbo", "qwen-plus", "qwen-max", "qwen-14b-chat", "qwen-7b-chat"}
 )
 
 var (
@@ -52,6 +58,51 @@ var (
 )
 var ZhiPuAIModels = []string{ZhiPuAILite, ZhiPuAIStd, ZhiPuAIPro, ZhiPuAITurbo, ZhiPuAIGLM3Turbo, ZhiPuAIGLM4}
 
+// functionCallingModelPrefixes are prefixes of the models which support native function calling.
+// ZhiPuAI models call functions by the tools of the v4 api, and Gemini models by the REST api.
+var functionCallingModelPrefixes = map[LLMType][]string{
+	OpenAI:  {"gpt-3.5-turbo", "gpt-4"},
+	ZhiPuAI: {ZhiPuAIGLM4, ZhiPuAIGLM3Turbo},
+	Gemini:  {"gemini-pro", "gemini-1.0-pro", "gemini-1.5"},
+}
+
+// SupportsFunctionCalling returns whether the well-known model supports native function calling
+func SupportsFunctionCalling(llmType LLMType, model string) bool {
+	for _, prefix := range functionCallingModelPrefixes[llmType] {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// visionModelPrefixes are prefixes of the models which accept images as input.
+// ZhiPuAI vision models are only served by the OpenAI compatible api.
+var visionModelPrefixes = map[LLMType][]string{
+	OpenAI:           {"gpt-4-vision", "gpt-4-turbo", "gpt-4o"},
+	Gemini:           {"gemini-pro-vision", "gemini-1.0-pro-vision", "gemini-1.5"},
+	OpenAICompatible: {"glm-4v", "qwen-vl", "gpt-4-vision", "gpt-4-turbo", "gpt-4o", "llava"},
+}
+
+// SupportsVision returns whether the well-known model accepts images as input
+func SupportsVision(llmType LLMType, model string) bool {
+	for _, prefix := range visionModelPrefixes[llmType] {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ImagePart returns the content part of an image in the format accepted by the llm type.
+// Gemini takes the image data, the OpenAI protocol takes a data url.
+func ImagePart(llmType LLMType, mimeType string, data []byte) langchainllms.ContentPart {
+	if llmType == Gemini {
+		return langchainllms.BinaryPart(mimeType, data)
+	}
+	return langchainllms.ImageURLPart("data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data))
+}
+
 type LLM interface {
 	Type() LLMType
 	Call([]byte) (Response, error)
@@ -0,0 +1,240 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/pkg/llms"
+)
+
+var _ langchainllms.Model = (*FunctionCallingLLM)(nil)
+
+// ToolsClient generates content with functions by the tools of the OpenAI chat completions api,
+// which is also served by ZhiPuAI v4 and most OpenAI compatible services.
+// Function calls and their results are sent as assistant messages with tool calls and tool messages.
+type ToolsClient struct {
+	// URL is the url of the chat completions api
+	URL string
+	// Token returns the bearer token of a request
+	Token func() (string, error)
+	// HTTPClient is http.DefaultClient if not set
+	HTTPClient *http.Client
+}
+
+type toolChatRequest struct {
+	Model       string            `json:"model"`
+	Messages    []toolChatMessage `json:"messages"`
+	Tools       []tool            `json:"tools,omitempty"`
+	ToolChoice  string            `json:"tool_choice,omitempty"`
+	Temperature float64           `json:"temperature,omitempty"`
+	TopP        float64           `json:"top_p,omitempty"`
+	MaxTokens   int               `json:"max_tokens,omitempty"`
+	Stop        []string          `json:"stop,omitempty"`
+}
+
+type toolChatMessage struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []toolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+type tool struct {
+	Type     string                           `json:"type"`
+	Function langchainllms.FunctionDefinition `json:"function"`
+}
+
+type toolCall struct {
+	ID       string              `json:"id"`
+	Type     string              `json:"type"`
+	Function schema.FunctionCall `json:"function"`
+}
+
+type toolChatResponse struct {
+	Choices []struct {
+		FinishReason string          `json:"finish_reason"`
+		Message      toolChatMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// toolChatMessages converts the messages to the messages of the chat completions api.
+// A function result answers the last function call, so they share the same tool call id.
+func toolChatMessages(messages []langchainllms.MessageContent) ([]toolChatMessage, error) {
+	res := make([]toolChatMessage, 0, len(messages))
+	callID := ""
+	for _, mc := range messages {
+		msg := toolChatMessage{Content: llms.MessageText(mc)}
+		switch mc.Role {
+		case schema.ChatMessageTypeSystem:
+			msg.Role = "system"
+		case schema.ChatMessageTypeHuman, schema.ChatMessageTypeGeneric:
+			msg.Role = "user"
+		case schema.ChatMessageTypeAI:
+			msg.Role = "assistant"
+			for _, part := range mc.Parts {
+				if call, ok := part.(llms.FunctionCallPart); ok {
+					callID = fmt.Sprintf("call_%d", len(res))
+					msg.Content = ""
+					msg.ToolCalls = append(msg.ToolCalls, toolCall{
+						ID:       callID,
+						Type:     "function",
+						Function: schema.FunctionCall{Name: call.Name, Arguments: call.Arguments},
+					})
+				}
+			}
+		case schema.ChatMessageTypeFunction:
+			if callID == "" {
+				return nil, errors.New("function result without a function call")
+			}
+			msg.Role = "tool"
+			msg.ToolCallID = callID
+		default:
+			return nil, fmt.Errorf("role %v not supported", mc.Role)
+		}
+		res = append(res, msg)
+	}
+	return res, nil
+}
+
+// GenerateContent requests the chat completions api with the functions of the options as tools,
+// the function call of the response is returned as the FuncCall of the choice.
+func (c *ToolsClient) GenerateContent(ctx context.Context, model string, messages []langchainllms.MessageContent, opts langchainllms.CallOptions) (*langchainllms.ContentResponse, error) {
+	chatMsgs, err := toolChatMessages(messages)
+	if err != nil {
+		return nil, err
+	}
+	req := toolChatRequest{
+		Model:       model,
+		Messages:    chatMsgs,
+		ToolChoice:  string(opts.FunctionCallBehavior),
+		Temperature: opts.Temperature,
+		TopP:        opts.TopP,
+		MaxTokens:   opts.MaxTokens,
+		Stop:        opts.StopWords,
+	}
+	if opts.Model != "" {
+		req.Model = opts.Model
+	}
+	for _, fn := range opts.Functions {
+		req.Tools = append(req.Tools, tool{Type: "function", Function: fn})
+	}
+	if len(req.Tools) == 0 {
+		req.ToolChoice = ""
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.Token != nil {
+		token, err := c.Token()
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("chat completions request failed with status %d: %s", httpResp.StatusCode, data)
+	}
+	resp := &toolChatResponse{}
+	if err = json.Unmarshal(data, resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("no choices in the response")
+	}
+	choices := make([]*langchainllms.ContentChoice, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		choices[i] = &langchainllms.ContentChoice{
+			Content:    choice.Message.Content,
+			StopReason: choice.FinishReason,
+			GenerationInfo: map[string]any{
+				"PromptTokens":     resp.Usage.PromptTokens,
+				"CompletionTokens": resp.Usage.CompletionTokens,
+				"TotalTokens":      resp.Usage.TotalTokens,
+			},
+		}
+		for _, call := range choice.Message.ToolCalls {
+			if call.Type == "function" || call.Type == "" {
+				choices[i].FuncCall = &schema.FunctionCall{Name: call.Function.Name, Arguments: call.Function.Arguments}
+				break
+			}
+		}
+	}
+	if opts.StreamingFunc != nil && choices[0].FuncCall == nil && choices[0].Content != "" {
+		if err = opts.StreamingFunc(ctx, []byte(choices[0].Content)); err != nil {
+			return nil, err
+		}
+	}
+	return &langchainllms.ContentResponse{Choices: choices}, nil
+}
+
+// FunctionCallingLLM generates content with functions by a ToolsClient,
+// other requests are served by the wrapped llm.
+type FunctionCallingLLM struct {
+	LLM    langchainllms.Model
+	Client *ToolsClient
+	// ModelName is used if the model is not set by the call options
+	ModelName string
+}
+
+func (l *FunctionCallingLLM) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, l, prompt, options...)
+}
+
+func (l *FunctionCallingLLM) GenerateContent(ctx context.Context, messages []langchainllms.MessageContent, options ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	opts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if len(opts.Functions) == 0 {
+		return l.LLM.GenerateContent(ctx, messages, options...)
+	}
+	return l.Client.GenerateContent(ctx, l.ModelName, messages, opts)
+}
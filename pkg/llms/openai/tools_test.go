@@ -0,0 +1,93 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/pkg/llms"
+)
+
+type fakeLLM struct {
+	called bool
+}
+
+func (f *fakeLLM) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+func (f *fakeLLM) GenerateContent(ctx context.Context, messages []langchainllms.MessageContent, options ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	f.called = true
+	return &langchainllms.ContentResponse{Choices: []*langchainllms.ContentChoice{{Content: "from llm"}}}, nil
+}
+
+func TestFunctionCallingLLM(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"model":"gpt-4"`) || !strings.Contains(string(body), `"tools":[{"type":"function"`) {
+			t.Errorf("unexpected request %s", body)
+		}
+		fmt.Fprint(w, `{"choices":[{"finish_reason":"stop","message":{"role":"assistant","content":"hello"}}]}`)
+	}))
+	defer server.Close()
+
+	wrapped := &fakeLLM{}
+	llm := &FunctionCallingLLM{
+		LLM:       wrapped,
+		Client:    &ToolsClient{URL: server.URL, Token: func() (string, error) { return "key", nil }},
+		ModelName: "gpt-4",
+	}
+	messages := []langchainllms.MessageContent{langchainllms.TextParts(schema.ChatMessageTypeHuman, "hello")}
+	resp, err := llm.GenerateContent(context.Background(), messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wrapped.called || resp.Choices[0].Content != "from llm" {
+		t.Errorf("requests without functions should be served by the wrapped llm")
+	}
+
+	var streamed string
+	withFunctions := langchainllms.WithFunctions([]langchainllms.FunctionDefinition{{Name: "weather"}})
+	resp, err = llm.GenerateContent(context.Background(), messages, withFunctions, langchainllms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+		streamed += string(chunk)
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Choices[0].Content != "hello" || streamed != "hello" {
+		t.Errorf("unexpected response %+v, streamed %q", resp.Choices[0], streamed)
+	}
+
+	// a function result must answer a function call
+	messages = append(messages, llms.FunctionResultMessage("weather", "sunny"))
+	if _, err = llm.GenerateContent(context.Background(), messages, withFunctions); err == nil {
+		t.Error("expect an error for the function result without a function call")
+	}
+}
@@ -31,8 +31,14 @@ import (
 	"github.com/tmc/langchaingo/llms/openai"
 	"github.com/tmc/langchaingo/schema"
 	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/pkg/llms"
+	arcadiaopenai "github.com/kubeagi/arcadia/pkg/llms/openai"
 )
 
+// ZhipuaiChatCompletionsURL is the OpenAI compatible chat completions api of ZhiPuAI v4, which supports tools
+const ZhipuaiChatCompletionsURL = "https://open.bigmodel.cn/api/paas/v4/chat/completions"
+
 var (
 	ErrEmptyResponse = errors.New("no response")
 	ErrEmptyPrompt   = errors.New("empty prompt")
@@ -45,6 +51,8 @@ var (
 type options struct {
 	retryTimes       int
 	callbacksHandler callbacks.Handler
+	model            string
+	toolsURL         string
 }
 
 type Option func(*options)
@@ -61,6 +69,13 @@ func WithCallback(callbacksHandler callbacks.Handler) Option {
 	}
 }
 
+// WithModel sets the model used if the model is not set by the call options
+func WithModel(model string) Option {
+	return func(o *options) {
+		o.model = model
+	}
+}
+
 type ZhiPuAILLM struct {
 	c       *ZhiPuAI
 	options *options
@@ -72,6 +87,7 @@ func NewZhiPuAILLM(apiKey string, opts ...Option) *ZhiPuAILLM {
 		options: &options{
 			// 2 times by default
 			retryTimes: 2,
+			toolsURL:   ZhipuaiChatCompletionsURL,
 		},
 	}
 	for _, opt := range opts {
@@ -96,6 +112,10 @@ func (z *ZhiPuAILLM) GenerateContent(ctx context.Context, messages []langchainll
 	for _, opt := range options {
 		opt(&opts)
 	}
+	if len(opts.Functions) > 0 {
+		// only the v4 api supports tools
+		return z.generateWithTools(ctx, messages, opts)
+	}
 	chatMsgs := make([]*openai.ChatMessage, 0, len(messages))
 	for _, mc := range messages {
 		msg := &openai.ChatMessage{MultiContent: mc.Parts}
@@ -124,6 +144,9 @@ func (z *ZhiPuAILLM) GenerateContent(ctx context.Context, messages []langchainll
 	if opts.Temperature > 0 && opts.Temperature < 1 {
 		params.Temperature = float32(opts.Temperature)
 	}
+	if z.options.model != "" {
+		params.Model = z.options.model
+	}
 	if opts.Model != "" {
 		params.Model = opts.Model
 	}
@@ -203,3 +226,28 @@ func (z *ZhiPuAILLM) GenerateContent(ctx context.Context, messages []langchainll
 	}
 	return response, nil
 }
+
+// generateWithTools calls the functions by the tools of the v4 chat completions api
+func (z *ZhiPuAILLM) generateWithTools(ctx context.Context, messages []langchainllm.MessageContent, opts langchainllm.CallOptions) (*langchainllm.ContentResponse, error) {
+	if len(messages) == 0 {
+		return nil, ErrEmptyPrompt
+	}
+	model := z.options.model
+	if model == "" {
+		model = llms.ZhiPuAIGLM4
+	}
+	client := &arcadiaopenai.ToolsClient{
+		URL: z.options.toolsURL,
+		Token: func() (string, error) {
+			return GenerateToken(z.c.apiKey, APITokenTTLSeconds)
+		},
+	}
+	response, err := client.GenerateContent(ctx, model, messages, opts)
+	if err != nil {
+		return nil, err
+	}
+	if z.options.callbacksHandler != nil {
+		z.options.callbacksHandler.HandleLLMGenerateContentEnd(ctx, response)
+	}
+	return response, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zhipuai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/pkg/llms"
+)
+
+func TestZhiPuAILLMWithTools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			t.Errorf("expect a bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		req := struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Role       string `json:"role"`
+				Content    string `json:"content"`
+				ToolCallID string `json:"tool_call_id"`
+				ToolCalls  []struct {
+					ID       string `json:"id"`
+					Function struct {
+						Name      string `json:"name"`
+						Arguments string `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"messages"`
+			Tools []struct {
+				Type     string `json:"type"`
+				Function struct {
+					Name string `json:"name"`
+				} `json:"function"`
+			} `json:"tools"`
+			ToolChoice string `json:"tool_choice"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %s", err)
+		}
+		if req.Model != llms.ZhiPuAIGLM4 {
+			t.Errorf("expect model %s, got %s", llms.ZhiPuAIGLM4, req.Model)
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Type != "function" || req.Tools[0].Function.Name != "weather" || req.ToolChoice != "auto" {
+			t.Errorf("unexpected tools %+v, tool choice %q", req.Tools, req.ToolChoice)
+		}
+		roles := make([]string, 0, len(req.Messages))
+		for _, m := range req.Messages {
+			roles = append(roles, m.Role)
+		}
+		switch strings.Join(roles, ",") {
+		case "system,user":
+			fmt.Fprint(w, `{"choices":[{"finish_reason":"tool_calls","message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"weather","arguments":"{\"city\":\"Beijing\"}"}}]}}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`)
+		case "system,user,assistant,tool":
+			call, result := req.Messages[2], req.Messages[3]
+			if len(call.ToolCalls) != 1 || call.ToolCalls[0].Function.Name != "weather" || call.ToolCalls[0].Function.Arguments != `{"city":"Beijing"}` {
+				t.Errorf("unexpected function call message %+v", call)
+			}
+			if result.ToolCallID == "" || result.ToolCallID != call.ToolCalls[0].ID || result.Content != "sunny" {
+				t.Errorf("unexpected function result message %+v", result)
+			}
+			fmt.Fprint(w, `{"choices":[{"finish_reason":"stop","message":{"role":"assistant","content":"It is sunny in Beijing."}}],"usage":{"total_tokens":20}}`)
+		default:
+			t.Errorf("unexpected roles %v", roles)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	llm := NewZhiPuAILLM("id.secret", WithModel(llms.ZhiPuAIGLM4))
+	llm.options.toolsURL = server.URL
+	messages := []langchainllms.MessageContent{
+		langchainllms.TextParts(schema.ChatMessageTypeSystem, "You are a helpful assistant."),
+		langchainllms.TextParts(schema.ChatMessageTypeHuman, "How is the weather in Beijing?"),
+	}
+	options := []langchainllms.CallOption{
+		langchainllms.WithFunctions([]langchainllms.FunctionDefinition{{Name: "weather", Description: "query the weather of a city"}}),
+		langchainllms.WithFunctionCallBehavior(langchainllms.FunctionCallBehaviorAuto),
+	}
+	resp, err := llm.GenerateContent(context.Background(), messages, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := resp.Choices[0].FuncCall
+	if call == nil || call.Name != "weather" || call.Arguments != `{"city":"Beijing"}` {
+		t.Fatalf("unexpected function call %+v", call)
+	}
+	if resp.Choices[0].GenerationInfo["TotalTokens"] != 15 {
+		t.Errorf("unexpected generation info %v", resp.Choices[0].GenerationInfo)
+	}
+
+	messages = append(messages, llms.FunctionCallMessage(call.Name, call.Arguments), llms.FunctionResultMessage(call.Name, "sunny"))
+	resp, err = llm.GenerateContent(context.Background(), messages, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Choices[0].FuncCall != nil || resp.Choices[0].Content != "It is sunny in Beijing." {
+		t.Errorf("unexpected response %+v", resp.Choices[0])
+	}
+}
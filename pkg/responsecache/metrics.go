@@ -0,0 +1,40 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsecache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var lookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "arcadia",
+	Subsystem: "response_cache",
+	Name:      "lookups_total",
+	Help:      "The number of response cache lookups by application, layer and result(hit or miss)",
+}, []string{"namespace", "application", "layer", "result"})
+
+func init() {
+	prometheus.MustRegister(lookups)
+}
+
+func (c *Cache) record(layer Layer, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	lookups.WithLabelValues(c.namespace, c.app, string(layer), result).Inc()
+}
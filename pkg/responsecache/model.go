@@ -0,0 +1,109 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsecache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	"k8s.io/klog/v2"
+)
+
+// Model caches the responses of the llm by the exact messages and call options
+type Model struct {
+	langchainllms.Model
+	cache *Cache
+}
+
+var _ langchainllms.Model = (*Model)(nil)
+
+// WrapModel returns a llm whose responses are cached in the cache
+func WrapModel(model langchainllms.Model, cache *Cache) *Model {
+	return &Model{Model: model, cache: cache}
+}
+
+type skipCacheKey struct{}
+
+// WithoutCache returns a context in which the responses are neither read from nor written to the cache,
+// e.g. to regenerate an answer which would be the same as the cached one
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipCacheKey{}, true)
+}
+
+// skipped returns true if the cache is skipped in the context
+func skipped(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipCacheKey{}).(bool)
+	return skip
+}
+
+func (m *Model) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func (m *Model) GenerateContent(ctx context.Context, messages []langchainllms.MessageContent, options ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	if skipped(ctx) {
+		return m.Model.GenerateContent(ctx, messages, options...)
+	}
+	opts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	key, err := requestKey(messages, opts)
+	if err != nil {
+		klog.FromContext(ctx).Error(err, "failed to get the cache key of llm request, skip cache")
+		return m.Model.GenerateContent(ctx, messages, options...)
+	}
+	if v, ok := m.cache.get(LayerLLM, key); ok {
+		resp := v.(*langchainllms.ContentResponse)
+		// replay the cached content as a single chunk
+		if opts.StreamingFunc != nil && len(resp.Choices) > 0 {
+			if err := opts.StreamingFunc(ctx, []byte(resp.Choices[0].Content)); err != nil {
+				return nil, err
+			}
+		}
+		return resp, nil
+	}
+	resp, err := m.Model.GenerateContent(ctx, messages, options...)
+	if err != nil {
+		return nil, err
+	}
+	m.cache.set(LayerLLM, key, resp)
+	return resp, nil
+}
+
+// keyOptions are the call options used in the cache key
+type keyOptions struct {
+	langchainllms.CallOptions
+	// StreamingFunc shadows the func in CallOptions which can't be marshaled and doesn't affect the response
+	StreamingFunc bool `json:",omitempty"`
+}
+
+// requestKey returns the hash of the messages and the options which affect the response
+func requestKey(messages []langchainllms.MessageContent, opts langchainllms.CallOptions) (string, error) {
+	data, err := json.Marshal(struct {
+		Messages []langchainllms.MessageContent `json:"messages"`
+		Options  keyOptions                     `json:"options"`
+	}{messages, keyOptions{CallOptions: opts}})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
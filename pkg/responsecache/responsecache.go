@@ -0,0 +1,277 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package responsecache caches the answers of applications and the responses of llms,
+// in exact mode by the normalized question, or in semantic mode by the similarity of question embeddings.
+package responsecache
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	langchaingoembeddings "github.com/tmc/langchaingo/embeddings"
+	"k8s.io/klog/v2"
+)
+
+type Mode string
+
+const (
+	ModeExact    Mode = "exact"
+	ModeSemantic Mode = "semantic"
+)
+
+const (
+	DefaultTTL                 = time.Hour
+	DefaultMaxEntries          = 1000
+	DefaultSimilarityThreshold = 0.95
+)
+
+// Layer is where the cache is used
+type Layer string
+
+const (
+	LayerApplication Layer = "application"
+	LayerLLM         Layer = "llm"
+)
+
+// Config of the cache
+type Config struct {
+	Mode       Mode
+	TTL        time.Duration
+	MaxEntries int
+	// Embedder and SimilarityThreshold are only used in semantic mode
+	Embedder            langchaingoembeddings.Embedder
+	SimilarityThreshold float64
+}
+
+// Cache is the cache of an application. Entries are kept in a process wide store,
+// so the cache can be created for each request, and they are dropped once the version changes.
+type Cache struct {
+	namespace string
+	app       string
+	config    Config
+	store     *scopeStore
+
+	// vectors keeps the embeddings of questions looked up in semantic mode to be reused when they are set
+	mu      sync.Mutex
+	vectors map[string][]float32
+}
+
+// New returns the cache of the application, version should change when the answers may change,
+// like the application or its knowledgebases are updated.
+func New(namespace, app, version string, config Config) *Cache {
+	if config.Mode == "" {
+		config.Mode = ModeExact
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultMaxEntries
+	}
+	if config.SimilarityThreshold <= 0 {
+		config.SimilarityThreshold = DefaultSimilarityThreshold
+	}
+	return &Cache{
+		namespace: namespace,
+		app:       app,
+		config:    config,
+		store:     getScope(namespace+"/"+app, version),
+		vectors:   make(map[string][]float32),
+	}
+}
+
+// NormalizeQuestion trims the question, collapses whitespaces and lowers the case
+func NormalizeQuestion(question string) string {
+	return strings.ToLower(strings.Join(strings.Fields(question), " "))
+}
+
+// GetAnswer returns the cached answer of the question
+func (c *Cache) GetAnswer(ctx context.Context, question string) (any, bool) {
+	question = NormalizeQuestion(question)
+	value, ok := c.store.get(string(LayerApplication)+":"+question, time.Now())
+	if !ok && c.config.Mode == ModeSemantic && c.config.Embedder != nil {
+		vector, err := c.config.Embedder.EmbedQuery(ctx, question)
+		if err != nil {
+			klog.FromContext(ctx).Error(err, "failed to embed question for semantic cache")
+		} else {
+			c.mu.Lock()
+			c.vectors[question] = vector
+			c.mu.Unlock()
+			value, ok = c.store.getSimilar(vector, c.config.SimilarityThreshold, time.Now())
+		}
+	}
+	c.record(LayerApplication, ok)
+	return value, ok
+}
+
+// SetAnswer caches the answer of the question
+func (c *Cache) SetAnswer(ctx context.Context, question string, value any) {
+	question = NormalizeQuestion(question)
+	var vector []float32
+	if c.config.Mode == ModeSemantic && c.config.Embedder != nil {
+		c.mu.Lock()
+		vector = c.vectors[question]
+		c.mu.Unlock()
+		if vector == nil {
+			var err error
+			if vector, err = c.config.Embedder.EmbedQuery(ctx, question); err != nil {
+				klog.FromContext(ctx).Error(err, "failed to embed question for semantic cache")
+			}
+		}
+	}
+	c.store.set(string(LayerApplication)+":"+question, value, vector, time.Now().Add(c.config.TTL), c.config.MaxEntries)
+}
+
+// get returns the cached value of the exact key in the layer
+func (c *Cache) get(layer Layer, key string) (any, bool) {
+	value, ok := c.store.get(string(layer)+":"+key, time.Now())
+	c.record(layer, ok)
+	return value, ok
+}
+
+func (c *Cache) set(layer Layer, key string, value any) {
+	c.store.set(string(layer)+":"+key, value, nil, time.Now().Add(c.config.TTL), c.config.MaxEntries)
+}
+
+// scopes keeps the entries of each application
+var scopes sync.Map
+
+func getScope(name, version string) *scopeStore {
+	v, _ := scopes.LoadOrStore(name, &scopeStore{version: version, entries: make(map[string]*entry)})
+	s := v.(*scopeStore)
+	s.reset(version)
+	return s
+}
+
+type entry struct {
+	value    any
+	vector   []float32
+	expireAt time.Time
+	seq      uint64
+}
+
+// orderItem is a set of the key, it is stale if the entry has been set again or deleted
+type orderItem struct {
+	key string
+	seq uint64
+}
+
+type scopeStore struct {
+	mu      sync.Mutex
+	version string
+	entries map[string]*entry
+	// order is the sets in order, the oldest entries are evicted first
+	order []orderItem
+	seq   uint64
+}
+
+// reset drops all the entries if the version changed
+func (s *scopeStore) reset(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.version == version {
+		return
+	}
+	s.version = version
+	s.entries = make(map[string]*entry)
+	s.order = nil
+}
+
+func (s *scopeStore) get(key string, now time.Time) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if now.After(e.expireAt) {
+		delete(s.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+// getSimilar returns the value of the most similar entry whose similarity is not less than the threshold
+func (s *scopeStore) getSimilar(vector []float32, threshold float64, now time.Time) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var best *entry
+	bestScore := threshold
+	for key, e := range s.entries {
+		if e.vector == nil {
+			continue
+		}
+		if now.After(e.expireAt) {
+			delete(s.entries, key)
+			continue
+		}
+		if score := cosineSimilarity(vector, e.vector); score >= bestScore {
+			best, bestScore = e, score
+		}
+	}
+	if best == nil {
+		return nil, false
+	}
+	return best.value, true
+}
+
+func (s *scopeStore) set(key string, value any, vector []float32, expireAt time.Time, maxEntries int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	s.entries[key] = &entry{value: value, vector: vector, expireAt: expireAt, seq: s.seq}
+	s.order = append(s.order, orderItem{key: key, seq: s.seq})
+	for len(s.order) > 0 {
+		head := s.order[0]
+		if e, ok := s.entries[head.key]; ok && e.seq == head.seq {
+			if len(s.entries) <= maxEntries {
+				break
+			}
+			delete(s.entries, head.key)
+		}
+		s.order = s.order[1:]
+	}
+	// drop the stale items if the same keys are set again and again
+	if len(s.order) > 2*maxEntries {
+		order := make([]orderItem, 0, len(s.entries))
+		for _, item := range s.order {
+			if e, ok := s.entries[item.key]; ok && e.seq == item.seq {
+				order = append(order, item)
+			}
+		}
+		s.order = order
+	}
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
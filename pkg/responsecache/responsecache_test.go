@@ -0,0 +1,204 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsecache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+)
+
+func TestNormalizeQuestion(t *testing.T) {
+	tests := []struct {
+		question string
+		want     string
+	}{
+		{question: "What is KubeAGI?", want: "what is kubeagi?"},
+		{question: "  what   is\tkubeagi? \n", want: "what is kubeagi?"},
+		{question: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeQuestion(tt.question); got != tt.want {
+			t.Errorf("%q: expect %q, got %q", tt.question, tt.want, got)
+		}
+	}
+}
+
+func TestExactCache(t *testing.T) {
+	ctx := context.Background()
+	c := New("ns", "exact", "1", Config{})
+	if _, ok := c.GetAnswer(ctx, "hello"); ok {
+		t.Errorf("expect miss before set")
+	}
+	c.SetAnswer(ctx, "Hello ", "world")
+	if got, ok := c.GetAnswer(ctx, "  hello"); !ok || got != "world" {
+		t.Errorf("expect hit world, got %v %v", got, ok)
+	}
+	// the cache of the same version is shared
+	if got, ok := New("ns", "exact", "1", Config{}).GetAnswer(ctx, "hello"); !ok || got != "world" {
+		t.Errorf("expect hit world with the same version, got %v %v", got, ok)
+	}
+	// other apps don't share the entries
+	if _, ok := New("ns", "other", "1", Config{}).GetAnswer(ctx, "hello"); ok {
+		t.Errorf("expect miss in other app")
+	}
+	// entries are dropped once the version changes
+	if _, ok := New("ns", "exact", "2", Config{}).GetAnswer(ctx, "hello"); ok {
+		t.Errorf("expect miss after version changed")
+	}
+}
+
+func TestTTLAndEviction(t *testing.T) {
+	s := &scopeStore{entries: make(map[string]*entry)}
+	now := time.Now()
+	s.set("a", 1, nil, now.Add(time.Minute), 2)
+	if _, ok := s.get("a", now.Add(2*time.Minute)); ok {
+		t.Errorf("expect expired entry to miss")
+	}
+	if _, ok := s.entries["a"]; ok {
+		t.Errorf("expect expired entry to be deleted")
+	}
+
+	s.set("a", 1, nil, now.Add(time.Minute), 2)
+	s.set("b", 2, nil, now.Add(time.Minute), 2)
+	// set a again, so b is the oldest one
+	s.set("a", 3, nil, now.Add(time.Minute), 2)
+	s.set("c", 4, nil, now.Add(time.Minute), 2)
+	if _, ok := s.get("b", now); ok {
+		t.Errorf("expect the oldest entry b to be evicted")
+	}
+	for key, want := range map[string]int{"a": 3, "c": 4} {
+		if got, ok := s.get(key, now); !ok || got != want {
+			t.Errorf("%s: expect %d, got %v %v", key, want, got, ok)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		s.set("a", i, nil, now.Add(time.Minute), 2)
+	}
+	if len(s.order) > 4 {
+		t.Errorf("expect stale order items to be dropped, got %d", len(s.order))
+	}
+}
+
+// fakeEmbedder embeds the questions to the vectors in the map
+type fakeEmbedder struct {
+	vectors map[string][]float32
+}
+
+func (e *fakeEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	res := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		v, err := e.EmbedQuery(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+func (e *fakeEmbedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	v, ok := e.vectors[text]
+	if !ok {
+		return nil, fmt.Errorf("no vector for %s", text)
+	}
+	return v, nil
+}
+
+func TestSemanticCache(t *testing.T) {
+	ctx := context.Background()
+	embedder := &fakeEmbedder{vectors: map[string][]float32{
+		"what is kubeagi?":       {1, 0, 0},
+		"what's kubeagi?":        {0.99, 0.1, 0},
+		"how to deploy kubeagi?": {0, 1, 0},
+	}}
+	c := New("ns", "semantic", "1", Config{Mode: ModeSemantic, Embedder: embedder, SimilarityThreshold: 0.9})
+	c.SetAnswer(ctx, "What is KubeAGI?", "a platform")
+	tests := []struct {
+		question string
+		hit      bool
+	}{
+		{question: "what is kubeagi?", hit: true},
+		{question: "What's KubeAGI?", hit: true},
+		{question: "how to deploy kubeagi?", hit: false},
+		// failed to embed, fallback to miss
+		{question: "unknown", hit: false},
+	}
+	for _, tt := range tests {
+		got, ok := c.GetAnswer(ctx, tt.question)
+		if ok != tt.hit {
+			t.Errorf("%s: expect hit %v, got %v", tt.question, tt.hit, ok)
+		}
+		if ok && got != "a platform" {
+			t.Errorf("%s: expect a platform, got %v", tt.question, got)
+		}
+	}
+}
+
+// fakeModel returns the number of calls as the content
+type fakeModel struct {
+	calls int
+}
+
+func (m *fakeModel) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func (m *fakeModel) GenerateContent(_ context.Context, _ []langchainllms.MessageContent, _ ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	m.calls++
+	return &langchainllms.ContentResponse{Choices: []*langchainllms.ContentChoice{{Content: fmt.Sprintf("call %d", m.calls)}}}, nil
+}
+
+func TestModel(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeModel{}
+	m := WrapModel(fake, New("ns", "model", "1", Config{}))
+	messages := []langchainllms.MessageContent{langchainllms.TextParts(langchaingoschema.ChatMessageTypeHuman, "hello")}
+
+	resp, err := m.GenerateContent(ctx, messages, langchainllms.WithTemperature(0.1))
+	if err != nil || resp.Choices[0].Content != "call 1" {
+		t.Fatalf("expect call 1, got %v %v", resp, err)
+	}
+	var streamed string
+	resp, err = m.GenerateContent(ctx, messages, langchainllms.WithTemperature(0.1), langchainllms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+		streamed += string(chunk)
+		return nil
+	}))
+	if err != nil || resp.Choices[0].Content != "call 1" {
+		t.Errorf("expect cached call 1, got %v %v", resp, err)
+	}
+	if streamed != "call 1" {
+		t.Errorf("expect cached content to be streamed, got %q", streamed)
+	}
+	resp, err = m.GenerateContent(ctx, messages, langchainllms.WithTemperature(0.2))
+	if err != nil || resp.Choices[0].Content != "call 2" {
+		t.Errorf("expect call 2 with other options, got %v %v", resp, err)
+	}
+	// the cache is neither read nor written without cache
+	resp, err = m.GenerateContent(WithoutCache(ctx), messages, langchainllms.WithTemperature(0.1))
+	if err != nil || resp.Choices[0].Content != "call 3" {
+		t.Errorf("expect call 3 without cache, got %v %v", resp, err)
+	}
+	resp, err = m.GenerateContent(ctx, messages, langchainllms.WithTemperature(0.1))
+	if err != nil || resp.Choices[0].Content != "call 1" {
+		t.Errorf("expect cached call 1, got %v %v", resp, err)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenusage
+
+import (
+	"context"
+
+	langchaingoembeddings "github.com/tmc/langchaingo/embeddings"
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+// Model records the usage of each call to the llm
+type Model struct {
+	langchainllms.Model
+	llm   string
+	model string
+}
+
+var _ langchainllms.Model = (*Model)(nil)
+
+// WrapModel returns a llm recording its usage, llm is the namespace/name of the LLM
+// and model is the default model used if the call doesn't specify one.
+func WrapModel(model langchainllms.Model, llm, modelName string) *Model {
+	return &Model{Model: model, llm: llm, model: modelName}
+}
+
+func (m *Model) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func (m *Model) GenerateContent(ctx context.Context, messages []langchainllms.MessageContent, options ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	resp, err := m.Model.GenerateContent(ctx, messages, options...)
+	if err != nil {
+		return nil, err
+	}
+	opts := langchainllms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	modelName := m.model
+	if opts.Model != "" {
+		modelName = opts.Model
+	}
+	usage, ok := FromResponse(resp)
+	if !ok {
+		// streaming responses usually have no usage
+		usage.PromptTokens = estimateMessages(messages)
+		if len(resp.Choices) > 0 {
+			usage.CompletionTokens = EstimateTokens(resp.Choices[0].Content)
+		}
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+		usage.Estimated = true
+	}
+	usage.LLM, usage.Model, usage.Calls = m.llm, modelName, 1
+	Record(ctx, usage)
+	return resp, nil
+}
+
+// Embedder records the usage of each call to the embedder.
+// Embedding apis of langchaingo return no usage, so the tokens are always estimated.
+type Embedder struct {
+	langchaingoembeddings.Embedder
+	embedder string
+	model    string
+}
+
+var _ langchaingoembeddings.Embedder = (*Embedder)(nil)
+
+// WrapEmbedder returns an embedder recording its usage, embedder is the namespace/name of the Embedder
+func WrapEmbedder(embedder langchaingoembeddings.Embedder, name, model string) *Embedder {
+	return &Embedder{Embedder: embedder, embedder: name, model: model}
+}
+
+func (e *Embedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	res, err := e.Embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	var tokens int64
+	for _, text := range texts {
+		tokens += EstimateTokens(text)
+	}
+	e.record(ctx, tokens)
+	return res, nil
+}
+
+func (e *Embedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	res, err := e.Embedder.EmbedQuery(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	e.record(ctx, EstimateTokens(text))
+	return res, nil
+}
+
+func (e *Embedder) record(ctx context.Context, tokens int64) {
+	Record(ctx, Usage{LLM: e.embedder, Model: e.model, PromptTokens: tokens, TotalTokens: tokens, Calls: 1, Estimated: true})
+}
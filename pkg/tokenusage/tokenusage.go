@@ -0,0 +1,176 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tokenusage records the tokens used by llms and embedders during a request.
+// The Recorder is passed in the context, and the wrapped models and embedders add their usage to it.
+package tokenusage
+
+import (
+	"context"
+	"sync"
+	"unicode"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+)
+
+// Usage is the tokens used by a model
+type Usage struct {
+	// LLM is the namespace/name of the LLM or Embedder
+	LLM string `json:"llm"`
+	// Model is the model name used in the calls
+	Model            string `json:"model,omitempty"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	TotalTokens      int64  `json:"total_tokens"`
+	// Calls is the number of calls to the model
+	Calls int64 `json:"calls"`
+	// Estimated is true if some tokens are estimated from the text, because the provider returns no usage
+	Estimated bool `json:"estimated,omitempty"`
+}
+
+// Add adds the tokens and calls of another usage
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.Calls += other.Calls
+	u.Estimated = u.Estimated || other.Estimated
+}
+
+// Recorder collects the usage of each model
+type Recorder struct {
+	mu     sync.Mutex
+	usages []Usage
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Add adds the usage to the usage of the same llm and model
+func (r *Recorder) Add(usage Usage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.usages {
+		if r.usages[i].LLM == usage.LLM && r.usages[i].Model == usage.Model {
+			r.usages[i].Add(usage)
+			return
+		}
+	}
+	r.usages = append(r.usages, usage)
+}
+
+// Usages returns the usage of each model in the order they are first used
+func (r *Recorder) Usages() []Usage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]Usage, len(r.usages))
+	copy(res, r.usages)
+	return res
+}
+
+// Total returns the sum of all models
+func (r *Recorder) Total() Usage {
+	total := Usage{}
+	for _, u := range r.Usages() {
+		total.Add(u)
+	}
+	return total
+}
+
+type recorderKey struct{}
+
+// NewContext returns a context carrying the recorder
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// FromContext returns the recorder in the context, nil if there is none
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// Record adds the usage to the recorder in the context, does nothing if there is none
+func Record(ctx context.Context, usage Usage) {
+	if r := FromContext(ctx); r != nil {
+		r.Add(usage)
+	}
+}
+
+// FromResponse reads the usage from the generation info of the response,
+// all the llms in arcadia use the same keys as openai does.
+func FromResponse(resp *langchainllms.ContentResponse) (usage Usage, ok bool) {
+	if resp == nil || len(resp.Choices) == 0 {
+		return usage, false
+	}
+	// all the choices share the same usage
+	info := resp.Choices[0].GenerationInfo
+	var hasPrompt, hasCompletion, hasTotal bool
+	usage.PromptTokens, hasPrompt = toInt64(info["PromptTokens"])
+	usage.CompletionTokens, hasCompletion = toInt64(info["CompletionTokens"])
+	usage.TotalTokens, hasTotal = toInt64(info["TotalTokens"])
+	if !hasTotal {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	return usage, hasPrompt || hasCompletion || hasTotal
+}
+
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	case float32:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// EstimateTokens estimates the tokens of the text when the provider returns no usage.
+// Each CJK character is counted as a token, and every 4 other non-space characters are counted as a token.
+func EstimateTokens(text string) int64 {
+	var cjk, others int64
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			cjk++
+		default:
+			others++
+		}
+	}
+	return cjk + (others+3)/4
+}
+
+// estimateMessages estimates the tokens of the text parts in the messages
+func estimateMessages(messages []langchainllms.MessageContent) int64 {
+	var tokens int64
+	for _, m := range messages {
+		for _, p := range m.Parts {
+			if t, ok := p.(langchainllms.TextContent); ok {
+				tokens += EstimateTokens(t.Text)
+			}
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenusage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	langchainllms "github.com/tmc/langchaingo/llms"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int64
+	}{
+		{text: "", want: 0},
+		{text: "hello", want: 2},
+		{text: "hi all", want: 2},
+		{text: "旷工最小计算单位", want: 8},
+		{text: "KubeAGI 平台", want: 4},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("%q: expect %d, got %d", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestFromResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		info map[string]any
+		want Usage
+		ok   bool
+	}{
+		{
+			name: "openai",
+			info: map[string]any{"PromptTokens": 10, "CompletionTokens": 5, "TotalTokens": 15},
+			want: Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+			ok:   true,
+		},
+		{
+			name: "only total",
+			info: map[string]any{"TotalTokens": int64(7)},
+			want: Usage{TotalTokens: 7},
+			ok:   true,
+		},
+		{
+			name: "no total",
+			info: map[string]any{"PromptTokens": 3.0, "CompletionTokens": 2.0},
+			want: Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+			ok:   true,
+		},
+		{
+			name: "no usage",
+			info: nil,
+			want: Usage{},
+			ok:   false,
+		},
+	}
+	for _, tt := range tests {
+		got, ok := FromResponse(&langchainllms.ContentResponse{Choices: []*langchainllms.ContentChoice{{GenerationInfo: tt.info}}})
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expect %+v %v, got %+v %v", tt.name, tt.want, tt.ok, got, ok)
+		}
+	}
+}
+
+type fakeModel struct {
+	info map[string]any
+}
+
+func (m *fakeModel) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func (m *fakeModel) GenerateContent(_ context.Context, _ []langchainllms.MessageContent, _ ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	return &langchainllms.ContentResponse{Choices: []*langchainllms.ContentChoice{{Content: "hello world", GenerationInfo: m.info}}}, nil
+}
+
+type fakeEmbedder struct{}
+
+func (e fakeEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	return make([][]float32, len(texts)), nil
+}
+
+func (e fakeEmbedder) EmbedQuery(_ context.Context, _ string) ([]float32, error) {
+	return []float32{}, nil
+}
+
+func TestRecord(t *testing.T) {
+	r := NewRecorder()
+	ctx := NewContext(context.Background(), r)
+	messages := []langchainllms.MessageContent{langchainllms.TextParts(langchaingoschema.ChatMessageTypeHuman, "how are you")}
+
+	withUsage := WrapModel(&fakeModel{info: map[string]any{"PromptTokens": 10, "CompletionTokens": 5, "TotalTokens": 15}}, "arcadia/openai", "gpt-4")
+	for i := 0; i < 2; i++ {
+		if _, err := withUsage.GenerateContent(ctx, messages); err != nil {
+			t.Fatal(err)
+		}
+	}
+	withoutUsage := WrapModel(&fakeModel{}, "arcadia/zhipuai", "")
+	if _, err := withoutUsage.GenerateContent(ctx, messages, langchainllms.WithModel("glm-4")); err != nil {
+		t.Fatal(err)
+	}
+	embedder := WrapEmbedder(fakeEmbedder{}, "arcadia/embedder", "bge")
+	if _, err := embedder.EmbedDocuments(ctx, []string{"hello", "world"}); err != nil {
+		t.Fatal(err)
+	}
+	// no recorder in the context
+	if _, err := withUsage.GenerateContent(context.Background(), messages); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Usage{
+		{LLM: "arcadia/openai", Model: "gpt-4", PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30, Calls: 2},
+		{LLM: "arcadia/zhipuai", Model: "glm-4", PromptTokens: 3, CompletionTokens: 3, TotalTokens: 6, Calls: 1, Estimated: true},
+		{LLM: "arcadia/embedder", Model: "bge", PromptTokens: 4, TotalTokens: 4, Calls: 1, Estimated: true},
+	}
+	if got := r.Usages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expect %+v, got %+v", want, got)
+	}
+	wantTotal := Usage{PromptTokens: 27, CompletionTokens: 13, TotalTokens: 40, Calls: 4, Estimated: true}
+	if got := r.Total(); !reflect.DeepEqual(got, wantTotal) {
+		t.Errorf("expect total %+v, got %+v", wantTotal, got)
+	}
+}
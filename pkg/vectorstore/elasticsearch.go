@@ -0,0 +1,290 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectorstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/tmc/langchaingo/embeddings"
+	lanchaingoschema "github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+const (
+	elasticsearchDefaultSimilarity = "cosine"
+	// elasticsearchNumCandidatesFactor is the default factor of num_candidates to k in knn search
+	elasticsearchNumCandidatesFactor = 10
+)
+
+var _ managedStore = (*ElasticsearchStore)(nil)
+
+// ElasticsearchStore is the vectorstore of Elasticsearch, each collection is an index
+type ElasticsearchStore struct {
+	client        *restClient
+	embedder      embeddings.Embedder
+	index         string
+	similarity    string
+	numCandidates int
+}
+
+func NewElasticsearchStore(endpoint string, auth map[string][]byte, spec *arcadiav1alpha1.Elasticsearch, embedder embeddings.Embedder, collectionName string) *ElasticsearchStore {
+	header := http.Header{}
+	if apiKey := auth["apiKey"]; len(apiKey) != 0 {
+		header.Set("Authorization", "ApiKey "+string(apiKey))
+	} else if user := auth["user"]; len(user) != 0 {
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(string(user)+":"+string(auth["password"]))))
+	}
+	s := &ElasticsearchStore{
+		client:     newRESTClient(endpoint, header),
+		embedder:   embedder,
+		index:      elasticsearchIndexName(collectionName),
+		similarity: elasticsearchDefaultSimilarity,
+	}
+	if spec != nil {
+		if spec.Similarity != "" {
+			s.similarity = spec.Similarity
+		}
+		s.numCandidates = spec.NumCandidates
+	}
+	return s
+}
+
+// elasticsearchIndexName returns a valid index name, which must be lowercase and can't start with _, - or +
+func elasticsearchIndexName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`\/*?"<>| ,#:`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	return strings.TrimLeft(name, "_-+")
+}
+
+func (s *ElasticsearchStore) path(elem ...string) string {
+	p := "/" + url.PathEscape(s.index)
+	for _, e := range elem {
+		p += "/" + e
+	}
+	return p
+}
+
+func (s *ElasticsearchStore) Ping(ctx context.Context) error {
+	return s.client.do(ctx, http.MethodGet, "/", nil, nil)
+}
+
+// ensureIndex creates the index if not exist, metadata is flattened so that any key can be filtered by term queries
+func (s *ElasticsearchStore) ensureIndex(ctx context.Context, dimension int) error {
+	err := s.client.do(ctx, http.MethodHead, s.path(), nil, nil)
+	if !isNotFound(err) {
+		return err
+	}
+	err = s.client.do(ctx, http.MethodPut, s.path(), map[string]any{
+		"mappings": map[string]any{
+			"properties": map[string]any{
+				pageContentField: map[string]any{"type": "text"},
+				metadataField:    map[string]any{"type": "flattened"},
+				vectorField:      map[string]any{"type": "dense_vector", "dims": dimension, "index": true, "similarity": s.similarity},
+			},
+		},
+	}, nil)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && strings.Contains(httpErr.Body, "resource_already_exists_exception") {
+		return nil
+	}
+	return err
+}
+
+func (s *ElasticsearchStore) AddDocuments(ctx context.Context, docs []lanchaingoschema.Document, _ ...vectorstores.Option) ([]string, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	vectors, err := embedDocuments(ctx, s.embedder, docs)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.ensureIndex(ctx, len(vectors[0])); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(docs))
+	body := &bytes.Buffer{}
+	encoder := json.NewEncoder(body)
+	for i, doc := range docs {
+		ids[i] = documentID(doc)
+		if err = encoder.Encode(map[string]any{"index": map[string]any{"_id": ids[i]}}); err != nil {
+			return nil, err
+		}
+		if err = encoder.Encode(map[string]any{pageContentField: doc.PageContent, metadataField: doc.Metadata, vectorField: vectors[i]}); err != nil {
+			return nil, err
+		}
+	}
+	resp := struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}{}
+	if err = s.client.doRaw(ctx, http.MethodPost, s.path("_bulk?refresh=true"), "application/x-ndjson", body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Errors {
+		for _, item := range resp.Items {
+			for _, result := range item {
+				if len(result.Error) != 0 {
+					return nil, fmt.Errorf("failed to index document %s: %s", result.ID, result.Error)
+				}
+			}
+		}
+	}
+	return ids, nil
+}
+
+// elasticsearchFilter converts the metadata filters to the query of elasticsearch
+func elasticsearchFilter(filters any) any {
+	m, ok := equalFilters(filters)
+	if !ok {
+		return filters
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	terms := make([]any, 0, len(m))
+	for _, k := range keys {
+		terms = append(terms, map[string]any{"term": map[string]any{metadataField + "." + k: m[k]}})
+	}
+	return map[string]any{"bool": map[string]any{"filter": terms}}
+}
+
+// SimilaritySearch returns the similar documents, for cosine similarity the score is converted from
+// the _score of elasticsearch, which is (1 + cosine) / 2, to cosine.
+func (s *ElasticsearchStore) SimilaritySearch(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]lanchaingoschema.Document, error) {
+	opts, vector, err := searchOptions(ctx, s.embedder, query, options)
+	if err != nil {
+		return nil, err
+	}
+	numCandidates := s.numCandidates
+	if numCandidates < numDocuments {
+		numCandidates = numDocuments * elasticsearchNumCandidatesFactor
+	}
+	knn := map[string]any{
+		"field":          vectorField,
+		"query_vector":   vector,
+		"k":              numDocuments,
+		"num_candidates": numCandidates,
+	}
+	if opts.Filters != nil {
+		knn["filter"] = elasticsearchFilter(opts.Filters)
+	}
+	resp := struct {
+		Hits struct {
+			Hits []struct {
+				Score  float32 `json:"_score"`
+				Source struct {
+					PageContent string         `json:"page_content"`
+					Metadata    map[string]any `json:"metadata"`
+				} `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}{}
+	err = s.client.do(ctx, http.MethodPost, s.path("_search"), map[string]any{
+		"knn":     knn,
+		"size":    numDocuments,
+		"_source": []string{pageContentField, metadataField},
+	}, &resp)
+	if isNotFound(err) {
+		// no documents added yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]lanchaingoschema.Document, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		score := hit.Score
+		if s.similarity == elasticsearchDefaultSimilarity {
+			score = 2*score - 1
+		}
+		if opts.ScoreThreshold > 0 && score < opts.ScoreThreshold {
+			continue
+		}
+		docs = append(docs, lanchaingoschema.Document{PageContent: hit.Source.PageContent, Metadata: hit.Source.Metadata, Score: score})
+	}
+	return docs, nil
+}
+
+func (s *ElasticsearchStore) RemoveExist(ctx context.Context, log logr.Logger, documents []lanchaingoschema.Document) ([]lanchaingoschema.Document, error) {
+	if len(documents) == 0 {
+		return documents, nil
+	}
+	ids := make([]string, len(documents))
+	for i, doc := range documents {
+		ids[i] = documentID(doc)
+	}
+	resp := struct {
+		Docs []struct {
+			ID    string `json:"_id"`
+			Found bool   `json:"found"`
+		} `json:"docs"`
+	}{}
+	err := s.client.do(ctx, http.MethodPost, s.path("_mget?_source=false"), map[string]any{"ids": ids}, &resp)
+	if isNotFound(err) {
+		return documents, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	exist := make(map[string]bool, len(resp.Docs))
+	for _, doc := range resp.Docs {
+		if doc.Found {
+			exist[doc.ID] = true
+		}
+	}
+	return filterExist(log, documents, ids, exist), nil
+}
+
+func (s *ElasticsearchStore) RemoveCollection(ctx context.Context) error {
+	err := s.client.do(ctx, http.MethodDelete, s.path(), nil, nil)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *ElasticsearchStore) RemoveFileDocuments(ctx context.Context, fileName string) error {
+	err := s.client.do(ctx, http.MethodPost, s.path("_delete_by_query?refresh=true"), map[string]any{
+		"query": elasticsearchFilter(map[string]any{FileMetadataKey: fileName}),
+	}, nil)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
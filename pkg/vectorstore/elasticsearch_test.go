@@ -0,0 +1,170 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectorstore
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/tmc/langchaingo/vectorstores"
+)
+
+// fakeElasticsearch is an elasticsearch server with a single index, the search scores are fixed
+type fakeElasticsearch struct {
+	mappings map[string]any
+	docs     map[string]map[string]any
+	scores   map[string]float64
+	// lastSearch is the body of the last search request
+	lastSearch map[string]any
+}
+
+func (f *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "ApiKey key" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	reply := func(v any) {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	if r.URL.Path == "/" {
+		reply(map[string]any{"version": map[string]any{"number": "8.13.0"}})
+		return
+	}
+	if r.URL.Path == "/kb/_bulk" {
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			action := map[string]map[string]any{}
+			_ = json.Unmarshal(scanner.Bytes(), &action)
+			scanner.Scan()
+			doc := map[string]any{}
+			_ = json.Unmarshal(scanner.Bytes(), &doc)
+			f.docs[action["index"]["_id"].(string)] = doc
+		}
+		reply(map[string]any{"errors": false})
+		return
+	}
+	body := map[string]any{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	if r.URL.Path == "/kb" && r.Method == http.MethodPut {
+		f.mappings, f.docs = body, map[string]map[string]any{}
+		reply(map[string]any{"acknowledged": true})
+		return
+	}
+	if f.mappings == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch r.Method + " " + r.URL.Path {
+	case "HEAD /kb":
+	case "DELETE /kb":
+		f.mappings, f.docs = nil, nil
+		reply(map[string]any{"acknowledged": true})
+	case "POST /kb/_mget":
+		res := make([]any, 0)
+		for _, id := range body["ids"].([]any) {
+			_, ok := f.docs[id.(string)]
+			res = append(res, map[string]any{"_id": id, "found": ok})
+		}
+		reply(map[string]any{"docs": res})
+	case "POST /kb/_search":
+		f.lastSearch = body
+		hits := make([]any, 0)
+		for _, doc := range f.docs {
+			hits = append(hits, map[string]any{"_score": f.scores[doc[pageContentField].(string)], "_source": doc})
+		}
+		reply(map[string]any{"hits": map[string]any{"hits": hits}})
+	case "POST /kb/_delete_by_query":
+		term := body["query"].(map[string]any)["bool"].(map[string]any)["filter"].([]any)[0].(map[string]any)["term"].(map[string]any)
+		for id, doc := range f.docs {
+			if term[metadataField+"."+FileMetadataKey] == doc[metadataField].(map[string]any)[FileMetadataKey] {
+				delete(f.docs, id)
+			}
+		}
+		reply(map[string]any{})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestElasticsearchStore(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeElasticsearch{scores: map[string]float64{"kubeagi": 0.95, "arcadia": 0.6}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	if err := NewElasticsearchStore(server.URL, nil, nil, fakeEmbedder{}, "KB").Ping(ctx); err == nil {
+		t.Errorf("expect ping error without api key")
+	}
+	s := NewElasticsearchStore(server.URL, map[string][]byte{"apiKey": []byte("key")}, nil, fakeEmbedder{}, "KB")
+	if err := s.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if docs, err := s.SimilaritySearch(ctx, "kubeagi", 2); err != nil || len(docs) != 0 {
+		t.Errorf("expect no documents before adding, got %v %v", docs, err)
+	}
+
+	if _, err := s.AddDocuments(ctx, testDocuments[:2]); err != nil {
+		t.Fatal(err)
+	}
+	wantVector := map[string]any{"type": "dense_vector", "dims": float64(2), "index": true, "similarity": "cosine"}
+	if got := fake.mappings["mappings"].(map[string]any)["properties"].(map[string]any)[vectorField]; !reflect.DeepEqual(got, wantVector) {
+		t.Errorf("expect vector mapping %v, got %v", wantVector, got)
+	}
+	docs, err := s.RemoveExist(ctx, logr.Discard(), testDocuments)
+	if err != nil || !reflect.DeepEqual(docs, testDocuments[2:]) {
+		t.Fatalf("expect only the new document, got %v %v", docs, err)
+	}
+	if _, err = s.AddDocuments(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.docs) != 3 {
+		t.Errorf("expect 3 documents, got %d", len(fake.docs))
+	}
+
+	docs, err = s.SimilaritySearch(ctx, "kubeagi", 3, vectorstores.WithScoreThreshold(0.5), vectorstores.WithFilters(map[string]any{FileMetadataKey: "a.txt"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// _score 0.95 is cosine 0.9, and _score 0.6 is cosine 0.2
+	if len(docs) != 1 || docs[0].PageContent != "kubeagi" || docs[0].Score < 0.89 || docs[0].Score > 0.91 {
+		t.Errorf("expect only the document above the score threshold, got %v", docs)
+	}
+	knn := fake.lastSearch["knn"].(map[string]any)
+	wantFilter := map[string]any{"bool": map[string]any{"filter": []any{map[string]any{"term": map[string]any{metadataField + "." + FileMetadataKey: "a.txt"}}}}}
+	if knn["num_candidates"] != float64(30) || !reflect.DeepEqual(knn["filter"], wantFilter) {
+		t.Errorf("expect num_candidates and filter in knn search, got %v", knn)
+	}
+
+	if err = s.RemoveFileDocuments(ctx, "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.docs) != 1 {
+		t.Errorf("expect 1 document after removing file documents, got %d", len(fake.docs))
+	}
+	if err = s.RemoveCollection(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if fake.mappings != nil {
+		t.Errorf("expect index removed")
+	}
+}
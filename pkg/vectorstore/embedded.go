@@ -0,0 +1,499 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectorstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/minio-go/v7"
+	"github.com/tmc/langchaingo/embeddings"
+	lanchaingoschema "github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/config"
+)
+
+const (
+	embeddedDistanceCosine = "cosine"
+	embeddedDistanceL2     = "l2"
+	embeddedDistanceIP     = "ip"
+)
+
+var _ managedStore = (*EmbeddedStore)(nil)
+
+// embeddedStorage persists the collections of the embedded vectorstore, each collection is a json file
+type embeddedStorage interface {
+	// Ping checks whether the storage is available
+	Ping(ctx context.Context) error
+	// Location returns the unique location of the collection, used as the key of the cache
+	Location(name string) string
+	// Version returns the version of the collection, which changes when the collection is saved, empty if not exist
+	Version(ctx context.Context, name string) (string, error)
+	// Load returns the data of the collection, nil if not exist
+	Load(ctx context.Context, name string) ([]byte, error)
+	Save(ctx context.Context, name string, data []byte) error
+	Remove(ctx context.Context, name string) error
+}
+
+type embeddedDocument struct {
+	ID          string         `json:"id"`
+	PageContent string         `json:"page_content"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	Vector      []float32      `json:"vector"`
+}
+
+// embeddedCollection is never modified after loaded or saved, so it can be shared without locks
+type embeddedCollection struct {
+	Documents []embeddedDocument `json:"documents"`
+}
+
+type embeddedCacheEntry struct {
+	version    string
+	collection *embeddedCollection
+}
+
+var (
+	// embeddedCache caches the loaded collections by their locations, and reloads them when their versions change
+	embeddedCache   = make(map[string]embeddedCacheEntry)
+	embeddedCacheMu sync.Mutex
+	// embeddedLocks serializes the updates of a collection in this process
+	embeddedLocks   = make(map[string]*sync.Mutex)
+	embeddedLocksMu sync.Mutex
+)
+
+// EmbeddedStore is a vectorstore running inside arcadia with a brute-force index, for single-node and test setups
+type EmbeddedStore struct {
+	storage          embeddedStorage
+	embedder         embeddings.Embedder
+	collectionName   string
+	distanceFunction string
+}
+
+func NewEmbeddedStore(ctx context.Context, vs *arcadiav1alpha1.VectorStore, embedder embeddings.Embedder, collectionName string) (*EmbeddedStore, error) {
+	spec := vs.Spec.Embedded
+	if spec == nil {
+		return nil, ErrUnsupportedVectorStoreType
+	}
+	var storage embeddedStorage
+	switch spec.Storage {
+	case arcadiav1alpha1.EmbeddedStorageOSS, "":
+		oss, err := config.GetSystemDatasourceOSS(ctx)
+		if err != nil {
+			return nil, err
+		}
+		prefix := spec.Path
+		if prefix == "" {
+			prefix = path.Join("vectorstore", vs.Name)
+		}
+		storage = &embeddedOSSStorage{client: oss.Client, bucket: vs.Namespace, prefix: prefix}
+	case arcadiav1alpha1.EmbeddedStorageLocal:
+		// a default directory in the pod is neither persistent nor shared by the controller and the apiserver
+		if spec.Path == "" {
+			return nil, errors.New("the path of the local storage of the embedded vectorstore is required")
+		}
+		storage = &embeddedLocalStorage{dir: spec.Path}
+	default:
+		return nil, fmt.Errorf("unsupported storage %s of the embedded vectorstore", spec.Storage)
+	}
+	return newEmbeddedStore(storage, spec.DistanceFunction, embedder, collectionName), nil
+}
+
+func newEmbeddedStore(storage embeddedStorage, distanceFunction string, embedder embeddings.Embedder, collectionName string) *EmbeddedStore {
+	if distanceFunction == "" {
+		distanceFunction = embeddedDistanceCosine
+	}
+	return &EmbeddedStore{
+		storage:          storage,
+		embedder:         embedder,
+		collectionName:   strings.ReplaceAll(collectionName, "/", "_"),
+		distanceFunction: distanceFunction,
+	}
+}
+
+func (s *EmbeddedStore) Ping(ctx context.Context) error {
+	return s.storage.Ping(ctx)
+}
+
+// lock locks the collection for updates, and returns the unlock function
+func (s *EmbeddedStore) lock() func() {
+	location := s.storage.Location(s.collectionName)
+	embeddedLocksMu.Lock()
+	mu, ok := embeddedLocks[location]
+	if !ok {
+		mu = &sync.Mutex{}
+		embeddedLocks[location] = mu
+	}
+	embeddedLocksMu.Unlock()
+	mu.Lock()
+	return mu.Unlock
+}
+
+// load returns the collection from the cache if it is not changed, an empty collection if not exist
+func (s *EmbeddedStore) load(ctx context.Context) (*embeddedCollection, error) {
+	location := s.storage.Location(s.collectionName)
+	version, err := s.storage.Version(ctx, s.collectionName)
+	if err != nil {
+		return nil, err
+	}
+	if version == "" {
+		return &embeddedCollection{}, nil
+	}
+	embeddedCacheMu.Lock()
+	entry, ok := embeddedCache[location]
+	embeddedCacheMu.Unlock()
+	if ok && entry.version == version {
+		return entry.collection, nil
+	}
+	data, err := s.storage.Load(ctx, s.collectionName)
+	if err != nil {
+		return nil, err
+	}
+	collection := &embeddedCollection{}
+	if data != nil {
+		if err = json.Unmarshal(data, collection); err != nil {
+			return nil, fmt.Errorf("failed to load collection %s: %w", s.collectionName, err)
+		}
+	}
+	embeddedCacheMu.Lock()
+	embeddedCache[location] = embeddedCacheEntry{version: version, collection: collection}
+	embeddedCacheMu.Unlock()
+	return collection, nil
+}
+
+func (s *EmbeddedStore) save(ctx context.Context, collection *embeddedCollection) error {
+	data, err := json.Marshal(collection)
+	if err != nil {
+		return err
+	}
+	if err = s.storage.Save(ctx, s.collectionName, data); err != nil {
+		return err
+	}
+	version, err := s.storage.Version(ctx, s.collectionName)
+	if err != nil {
+		return err
+	}
+	embeddedCacheMu.Lock()
+	embeddedCache[s.storage.Location(s.collectionName)] = embeddedCacheEntry{version: version, collection: collection}
+	embeddedCacheMu.Unlock()
+	return nil
+}
+
+func (s *EmbeddedStore) AddDocuments(ctx context.Context, docs []lanchaingoschema.Document, _ ...vectorstores.Option) ([]string, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	vectors, err := embedDocuments(ctx, s.embedder, docs)
+	if err != nil {
+		return nil, err
+	}
+	unlock := s.lock()
+	defer unlock()
+	old, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(old.Documents))
+	collection := &embeddedCollection{Documents: make([]embeddedDocument, len(old.Documents), len(old.Documents)+len(docs))}
+	for i, doc := range old.Documents {
+		index[doc.ID] = i
+		collection.Documents[i] = doc
+	}
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = documentID(doc)
+		if len(old.Documents) > 0 && len(old.Documents[0].Vector) != len(vectors[i]) {
+			return nil, fmt.Errorf("the dimension of the embedding is %d, but %d in the collection", len(vectors[i]), len(old.Documents[0].Vector))
+		}
+		d := embeddedDocument{ID: ids[i], PageContent: doc.PageContent, Metadata: doc.Metadata, Vector: vectors[i]}
+		if j, ok := index[d.ID]; ok {
+			collection.Documents[j] = d
+			continue
+		}
+		index[d.ID] = len(collection.Documents)
+		collection.Documents = append(collection.Documents, d)
+	}
+	if err = s.save(ctx, collection); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// score returns the similarity of vectors, the l2 distance is converted to a similarity
+func (s *EmbeddedStore) score(a, b []float32) float32 {
+	var dot, normA, normB, l2 float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+		l2 += (float64(a[i]) - float64(b[i])) * (float64(a[i]) - float64(b[i]))
+	}
+	switch s.distanceFunction {
+	case embeddedDistanceL2:
+		return distanceToSimilarity(float32(math.Sqrt(l2)))
+	case embeddedDistanceIP:
+		return float32(dot)
+	default:
+		if normA == 0 || normB == 0 {
+			return 0
+		}
+		return float32(dot / math.Sqrt(normA*normB))
+	}
+}
+
+// matchFilters returns whether the metadata has all the keys and values of the filters
+func matchFilters(metadata, filters map[string]any) bool {
+	for k, v := range filters {
+		got, ok := metadata[k]
+		if !ok || fmt.Sprint(got) != fmt.Sprint(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// SimilaritySearch returns the similar documents, the score is the similarity,
+// which is converted from the distance for l2, so the score threshold is always the minimum similarity.
+func (s *EmbeddedStore) SimilaritySearch(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]lanchaingoschema.Document, error) {
+	opts, vector, err := searchOptions(ctx, s.embedder, query, options)
+	if err != nil {
+		return nil, err
+	}
+	var filters map[string]any
+	if opts.Filters != nil {
+		var ok bool
+		if filters, ok = equalFilters(opts.Filters); !ok {
+			return nil, fmt.Errorf("unsupported filters %T for the embedded vectorstore", opts.Filters)
+		}
+	}
+	collection, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]lanchaingoschema.Document, 0)
+	for _, d := range collection.Documents {
+		if len(d.Vector) != len(vector) {
+			return nil, fmt.Errorf("the dimension of the query embedding is %d, but %d in the collection", len(vector), len(d.Vector))
+		}
+		if !matchFilters(d.Metadata, filters) {
+			continue
+		}
+		score := s.score(vector, d.Vector)
+		if opts.ScoreThreshold > 0 && score < opts.ScoreThreshold {
+			continue
+		}
+		docs = append(docs, lanchaingoschema.Document{PageContent: d.PageContent, Metadata: d.Metadata, Score: score})
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Score > docs[j].Score
+	})
+	if len(docs) > numDocuments {
+		docs = docs[:numDocuments]
+	}
+	return docs, nil
+}
+
+func (s *EmbeddedStore) RemoveExist(ctx context.Context, log logr.Logger, documents []lanchaingoschema.Document) ([]lanchaingoschema.Document, error) {
+	collection, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exist := make(map[string]bool, len(collection.Documents))
+	for _, d := range collection.Documents {
+		exist[d.ID] = true
+	}
+	ids := make([]string, len(documents))
+	for i, doc := range documents {
+		ids[i] = documentID(doc)
+	}
+	return filterExist(log, documents, ids, exist), nil
+}
+
+func (s *EmbeddedStore) RemoveCollection(ctx context.Context) error {
+	unlock := s.lock()
+	defer unlock()
+	if err := s.storage.Remove(ctx, s.collectionName); err != nil {
+		return err
+	}
+	embeddedCacheMu.Lock()
+	delete(embeddedCache, s.storage.Location(s.collectionName))
+	embeddedCacheMu.Unlock()
+	return nil
+}
+
+func (s *EmbeddedStore) RemoveFileDocuments(ctx context.Context, fileName string) error {
+	unlock := s.lock()
+	defer unlock()
+	old, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	collection := &embeddedCollection{Documents: make([]embeddedDocument, 0, len(old.Documents))}
+	for _, d := range old.Documents {
+		if !matchFilters(d.Metadata, map[string]any{FileMetadataKey: fileName}) {
+			collection.Documents = append(collection.Documents, d)
+		}
+	}
+	if len(collection.Documents) == len(old.Documents) {
+		return nil
+	}
+	return s.save(ctx, collection)
+}
+
+// embeddedLocalStorage stores the collections in a local directory
+type embeddedLocalStorage struct {
+	dir string
+}
+
+func (l *embeddedLocalStorage) file(name string) string {
+	return filepath.Join(l.dir, name+".json")
+}
+
+func (l *embeddedLocalStorage) Ping(_ context.Context) error {
+	return os.MkdirAll(l.dir, 0o755)
+}
+
+func (l *embeddedLocalStorage) Location(name string) string {
+	return l.file(name)
+}
+
+func (l *embeddedLocalStorage) Version(_ context.Context, name string) (string, error) {
+	info, err := os.Stat(l.file(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
+func (l *embeddedLocalStorage) Load(_ context.Context, name string) ([]byte, error) {
+	data, err := os.ReadFile(l.file(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Save writes to a temporary file first, so readers never see a partial collection
+func (l *embeddedLocalStorage) Save(_ context.Context, name string, data []byte) error {
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(l.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), l.file(name))
+}
+
+func (l *embeddedLocalStorage) Remove(_ context.Context, name string) error {
+	err := os.Remove(l.file(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// embeddedOSSStorage stores the collections in the bucket of the system datasource
+type embeddedOSSStorage struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func (o *embeddedOSSStorage) object(name string) string {
+	return path.Join(o.prefix, name+".json")
+}
+
+func isNoSuchKey(err error) bool {
+	return minio.ToErrorResponse(err).Code == "NoSuchKey"
+}
+
+func (o *embeddedOSSStorage) Ping(ctx context.Context) error {
+	exist, err := o.client.BucketExists(ctx, o.bucket)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return fmt.Errorf("bucket %s not found in the system datasource", o.bucket)
+	}
+	return nil
+}
+
+func (o *embeddedOSSStorage) Location(name string) string {
+	return o.client.EndpointURL().Host + "/" + o.bucket + "/" + o.object(name)
+}
+
+func (o *embeddedOSSStorage) Version(ctx context.Context, name string) (string, error) {
+	info, err := o.client.StatObject(ctx, o.bucket, o.object(name), minio.StatObjectOptions{})
+	if isNoSuchKey(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return info.ETag, nil
+}
+
+func (o *embeddedOSSStorage) Load(ctx context.Context, name string) ([]byte, error) {
+	object, err := o.client.GetObject(ctx, o.bucket, o.object(name), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if isNoSuchKey(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (o *embeddedOSSStorage) Save(ctx context.Context, name string, data []byte) error {
+	_, err := o.client.PutObject(ctx, o.bucket, o.object(name), bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{ContentType: "application/json"})
+	return err
+}
+
+func (o *embeddedOSSStorage) Remove(ctx context.Context, name string) error {
+	err := o.client.RemoveObject(ctx, o.bucket, o.object(name), minio.RemoveObjectOptions{})
+	if isNoSuchKey(err) {
+		return nil
+	}
+	return err
+}
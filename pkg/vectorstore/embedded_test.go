@@ -0,0 +1,131 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectorstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/tmc/langchaingo/vectorstores"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+func TestEmbeddedStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	vs := &arcadiav1alpha1.VectorStore{
+		ObjectMeta: metav1.ObjectMeta{Name: "embedded", Namespace: "arcadia"},
+		Spec:       arcadiav1alpha1.VectorStoreSpec{Embedded: &arcadiav1alpha1.Embedded{Storage: arcadiav1alpha1.EmbeddedStorageLocal}},
+	}
+	if _, _, err := NewVectorStore(ctx, vs, nil, "", nil); err == nil {
+		t.Error("expect an error for the local storage without a path")
+	}
+	vs.Spec.Embedded.Path = dir
+	// health check of the controller
+	if _, _, err := NewVectorStore(ctx, vs, nil, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	v, _, err := NewVectorStore(ctx, vs, fakeEmbedder{}, "arcadia_kb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := v.(*EmbeddedStore)
+	if docs, err := s.SimilaritySearch(ctx, "kubeagi", 2); err != nil || len(docs) != 0 {
+		t.Errorf("expect no documents before adding, got %v %v", docs, err)
+	}
+	if err = AddDocuments(ctx, logr.Discard(), vs, fakeEmbedder{}, "arcadia_kb", nil, testDocuments[:2]); err != nil {
+		t.Fatal(err)
+	}
+	docs, err := s.RemoveExist(ctx, logr.Discard(), testDocuments)
+	if err != nil || !reflect.DeepEqual(docs, testDocuments[2:]) {
+		t.Fatalf("expect only the new document, got %v %v", docs, err)
+	}
+	if _, err = s.AddDocuments(ctx, testDocuments); err != nil {
+		t.Fatal(err)
+	}
+
+	// vectors of "kubeagi" and "arcadia" are the same by the fake embedder, use a different query for scores
+	docs, err = s.SimilaritySearch(ctx, "kube", 3, vectorstores.WithFilters(map[string]any{FileMetadataKey: "a.txt"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].Metadata[FileMetadataKey] != "a.txt" || docs[0].Score < 0.9 {
+		t.Errorf("expect 2 documents of a.txt, got %v", docs)
+	}
+	if docs, err = s.SimilaritySearch(ctx, "kube", 3, vectorstores.WithScoreThreshold(0.999)); err != nil || len(docs) != 0 {
+		t.Errorf("expect no documents above the score threshold, got %v %v", docs, err)
+	}
+
+	// another store reads the persisted collection
+	other := newEmbeddedStore(&embeddedLocalStorage{dir: dir}, "l2", fakeEmbedder{}, "arcadia_kb")
+	docs, err = other.SimilaritySearch(ctx, "kubeagi", 1)
+	// the l2 distance 0 is converted to the similarity 1
+	if err != nil || len(docs) != 1 || docs[0].Score != 1 {
+		t.Errorf("expect the nearest document with similarity 1, got %v %v", docs, err)
+	}
+	if docs, err = other.SimilaritySearch(ctx, "kube", 3, vectorstores.WithScoreThreshold(0.999)); err != nil || len(docs) != 0 {
+		t.Errorf("expect no documents above the similarity threshold, got %v %v", docs, err)
+	}
+	if err = RemoveFileDocuments(ctx, logr.Discard(), vs, "arcadia_kb", nil, "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if docs, err = other.SimilaritySearch(ctx, "kubeagi", 3); err != nil || len(docs) != 1 || docs[0].Metadata[FileMetadataKey] != "b.txt" {
+		t.Errorf("expect only the document of b.txt, got %v %v", docs, err)
+	}
+	if err = RemoveCollection(ctx, logr.Discard(), vs, "arcadia_kb", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "arcadia_kb.json")); !os.IsNotExist(err) {
+		t.Errorf("expect collection file removed, got %v", err)
+	}
+}
+
+func TestEmbeddedStoreDimension(t *testing.T) {
+	ctx := context.Background()
+	s := newEmbeddedStore(&embeddedLocalStorage{dir: t.TempDir()}, "", fakeEmbedder{}, "kb")
+	if _, err := s.AddDocuments(ctx, testDocuments); err != nil {
+		t.Fatal(err)
+	}
+	s.embedder = dimensionEmbedder{}
+	if _, err := s.AddDocuments(ctx, testDocuments[:1]); err == nil {
+		t.Errorf("expect error when adding documents of a different dimension")
+	}
+	if _, err := s.SimilaritySearch(ctx, "kubeagi", 1); err == nil {
+		t.Errorf("expect error when searching with a different dimension")
+	}
+}
+
+// dimensionEmbedder returns 3-dimensional vectors
+type dimensionEmbedder struct{}
+
+func (e dimensionEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	res := make([][]float32, len(texts))
+	for i := range texts {
+		res[i] = []float32{1, 1, 1}
+	}
+	return res, nil
+}
+
+func (e dimensionEmbedder) EmbedQuery(_ context.Context, _ string) ([]float32, error) {
+	return []float32{1, 1, 1}, nil
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectorstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/tmc/langchaingo/embeddings"
+	lanchaingoschema "github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+const (
+	milvusDefaultDatabase   = "default"
+	milvusDefaultMetricType = "COSINE"
+	milvusMetricTypeL2      = "L2"
+	milvusIDField           = "id"
+)
+
+var _ managedStore = (*MilvusStore)(nil)
+
+// MilvusStore is the vectorstore of Milvus, using its RESTful api(v2)
+type MilvusStore struct {
+	client         *restClient
+	embedder       embeddings.Embedder
+	databaseName   string
+	collectionName string
+	metricType     string
+}
+
+func NewMilvusStore(endpoint string, auth map[string][]byte, spec *arcadiav1alpha1.Milvus, embedder embeddings.Embedder, collectionName string) *MilvusStore {
+	header := http.Header{}
+	if apiKey := auth["apiKey"]; len(apiKey) != 0 {
+		header.Set("Authorization", "Bearer "+string(apiKey))
+	} else if user := auth["user"]; len(user) != 0 {
+		header.Set("Authorization", "Bearer "+string(user)+":"+string(auth["password"]))
+	}
+	s := &MilvusStore{
+		client:         newRESTClient(endpoint, header),
+		embedder:       embedder,
+		databaseName:   milvusDefaultDatabase,
+		collectionName: milvusCollectionName(collectionName),
+		metricType:     milvusDefaultMetricType,
+	}
+	if spec != nil {
+		if spec.DatabaseName != "" {
+			s.databaseName = spec.DatabaseName
+		}
+		if spec.MetricType != "" {
+			s.metricType = spec.MetricType
+		}
+	}
+	return s
+}
+
+// milvusCollectionName returns a valid collection name, which can only contain letters, numbers and underscores,
+// and must start with a letter or an underscore.
+func milvusCollectionName(name string) string {
+	if name == "" {
+		return ""
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// call calls the api of milvus, which responds 200 with a non-zero code when fails
+func (s *MilvusStore) call(ctx context.Context, path string, req map[string]any, data any) error {
+	req["dbName"] = s.databaseName
+	resp := struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}{}
+	if err := s.client.do(ctx, http.MethodPost, "/v2/vectordb/"+path, req, &resp); err != nil {
+		return err
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("milvus error %d: %s", resp.Code, resp.Message)
+	}
+	if data == nil || len(resp.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Data, data)
+}
+
+func (s *MilvusStore) Ping(ctx context.Context) error {
+	return s.call(ctx, "collections/list", map[string]any{}, nil)
+}
+
+func (s *MilvusStore) hasCollection(ctx context.Context) (bool, error) {
+	data := struct {
+		Has bool `json:"has"`
+	}{}
+	err := s.call(ctx, "collections/has", map[string]any{"collectionName": s.collectionName}, &data)
+	return data.Has, err
+}
+
+// ensureCollection creates the collection if not exist, the collection is loaded after creation
+func (s *MilvusStore) ensureCollection(ctx context.Context, dimension int) error {
+	has, err := s.hasCollection(ctx)
+	if err != nil || has {
+		return err
+	}
+	return s.call(ctx, "collections/create", map[string]any{
+		"collectionName": s.collectionName,
+		"schema": map[string]any{
+			"autoId":             false,
+			"enableDynamicField": false,
+			"fields": []map[string]any{
+				{"fieldName": milvusIDField, "dataType": "VarChar", "isPrimary": true, "elementTypeParams": map[string]any{"max_length": "64"}},
+				{"fieldName": vectorField, "dataType": "FloatVector", "elementTypeParams": map[string]any{"dim": strconv.Itoa(dimension)}},
+				{"fieldName": pageContentField, "dataType": "VarChar", "elementTypeParams": map[string]any{"max_length": "65535"}},
+				{"fieldName": metadataField, "dataType": "JSON"},
+			},
+		},
+		"indexParams": []map[string]any{
+			{"fieldName": vectorField, "indexName": vectorField, "metricType": s.metricType, "params": map[string]any{"index_type": "AUTOINDEX"}},
+		},
+	}, nil)
+}
+
+func (s *MilvusStore) AddDocuments(ctx context.Context, docs []lanchaingoschema.Document, _ ...vectorstores.Option) ([]string, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	vectors, err := embedDocuments(ctx, s.embedder, docs)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.ensureCollection(ctx, len(vectors[0])); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(docs))
+	data := make([]map[string]any, len(docs))
+	for i, doc := range docs {
+		ids[i] = documentID(doc)
+		metadata := doc.Metadata
+		if metadata == nil {
+			metadata = map[string]any{}
+		}
+		data[i] = map[string]any{
+			milvusIDField:    ids[i],
+			vectorField:      vectors[i],
+			pageContentField: doc.PageContent,
+			metadataField:    metadata,
+		}
+	}
+	if err = s.call(ctx, "entities/upsert", map[string]any{"collectionName": s.collectionName, "data": data}, nil); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// milvusFilter converts the metadata filters to the boolean expression of milvus
+func milvusFilter(filters any) (string, error) {
+	if expr, ok := filters.(string); ok {
+		return expr, nil
+	}
+	m, ok := equalFilters(filters)
+	if !ok {
+		return "", fmt.Errorf("unsupported filters %T for milvus", filters)
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	exprs := make([]string, 0, len(m))
+	for _, k := range keys {
+		value, err := json.Marshal(m[k])
+		if err != nil {
+			return "", err
+		}
+		exprs = append(exprs, fmt.Sprintf("%s[%s] == %s", metadataField, strconv.Quote(k), value))
+	}
+	return strings.Join(exprs, " and "), nil
+}
+
+// SimilaritySearch returns the similar documents, the score is the distance of milvus for COSINE and IP,
+// and the L2 distance is converted to a similarity, so the score threshold is always the minimum similarity.
+func (s *MilvusStore) SimilaritySearch(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]lanchaingoschema.Document, error) {
+	opts, vector, err := searchOptions(ctx, s.embedder, query, options)
+	if err != nil {
+		return nil, err
+	}
+	has, err := s.hasCollection(ctx)
+	if err != nil || !has {
+		// no documents added yet
+		return nil, err
+	}
+	req := map[string]any{
+		"collectionName": s.collectionName,
+		"data":           [][]float32{vector},
+		"annsField":      vectorField,
+		"limit":          numDocuments,
+		"outputFields":   []string{pageContentField, metadataField},
+	}
+	if opts.Filters != nil {
+		filter, err := milvusFilter(opts.Filters)
+		if err != nil {
+			return nil, err
+		}
+		req["filter"] = filter
+	}
+	var data []map[string]any
+	if err = s.call(ctx, "entities/search", req, &data); err != nil {
+		return nil, err
+	}
+	docs := make([]lanchaingoschema.Document, 0, len(data))
+	for _, d := range data {
+		distance, _ := d["distance"].(float64)
+		doc := lanchaingoschema.Document{Score: float32(distance)}
+		if s.metricType == milvusMetricTypeL2 {
+			doc.Score = distanceToSimilarity(doc.Score)
+		}
+		if opts.ScoreThreshold > 0 && doc.Score < opts.ScoreThreshold {
+			continue
+		}
+		doc.PageContent, _ = d[pageContentField].(string)
+		doc.Metadata = milvusMetadata(d[metadataField])
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// milvusMetadata returns the metadata of the search result, json fields may be returned as strings
+func milvusMetadata(v any) map[string]any {
+	switch metadata := v.(type) {
+	case map[string]any:
+		return metadata
+	case string:
+		res := make(map[string]any)
+		if err := json.Unmarshal([]byte(metadata), &res); err == nil {
+			return res
+		}
+	}
+	return nil
+}
+
+func (s *MilvusStore) RemoveExist(ctx context.Context, log logr.Logger, documents []lanchaingoschema.Document) ([]lanchaingoschema.Document, error) {
+	if len(documents) == 0 {
+		return documents, nil
+	}
+	has, err := s.hasCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return documents, nil
+	}
+	ids := make([]string, len(documents))
+	for i, doc := range documents {
+		ids[i] = documentID(doc)
+	}
+	var data []map[string]any
+	if err = s.call(ctx, "entities/get", map[string]any{"collectionName": s.collectionName, "id": ids, "outputFields": []string{milvusIDField}}, &data); err != nil {
+		return nil, err
+	}
+	exist := make(map[string]bool, len(data))
+	for _, d := range data {
+		if id, ok := d[milvusIDField].(string); ok {
+			exist[id] = true
+		}
+	}
+	return filterExist(log, documents, ids, exist), nil
+}
+
+func (s *MilvusStore) RemoveCollection(ctx context.Context) error {
+	has, err := s.hasCollection(ctx)
+	if err != nil || !has {
+		return err
+	}
+	return s.call(ctx, "collections/drop", map[string]any{"collectionName": s.collectionName}, nil)
+}
+
+func (s *MilvusStore) RemoveFileDocuments(ctx context.Context, fileName string) error {
+	has, err := s.hasCollection(ctx)
+	if err != nil || !has {
+		return err
+	}
+	filter, err := milvusFilter(map[string]any{FileMetadataKey: fileName})
+	if err != nil {
+		return err
+	}
+	return s.call(ctx, "entities/delete", map[string]any{"collectionName": s.collectionName, "filter": filter}, nil)
+}
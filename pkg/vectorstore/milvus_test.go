@@ -0,0 +1,205 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectorstore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/tmc/langchaingo/vectorstores"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// fakeMilvus is a milvus server with a single collection, the search distances are fixed
+type fakeMilvus struct {
+	collection map[string]any
+	entities   map[string]map[string]any
+	distances  map[string]float64
+	// lastRequest is the body of the last request
+	lastRequest map[string]any
+}
+
+func (f *fakeMilvus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reply := func(data any) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": data})
+	}
+	if r.Header.Get("Authorization") != "Bearer root:Milvus" {
+		_ = json.NewEncoder(w).Encode(map[string]any{"code": 1800, "message": "user hasn't authenticated"})
+		return
+	}
+	body := map[string]any{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	f.lastRequest = body
+	switch strings.TrimPrefix(r.URL.Path, "/v2/vectordb/") {
+	case "collections/list":
+		reply([]string{})
+	case "collections/has":
+		reply(map[string]any{"has": f.collection != nil})
+	case "collections/create":
+		f.collection, f.entities = body, map[string]map[string]any{}
+		reply(map[string]any{})
+	case "collections/drop":
+		f.collection, f.entities = nil, nil
+		reply(map[string]any{})
+	case "entities/upsert":
+		for _, d := range body["data"].([]any) {
+			entity := d.(map[string]any)
+			f.entities[entity[milvusIDField].(string)] = entity
+		}
+		reply(map[string]any{"upsertCount": len(body["data"].([]any))})
+	case "entities/get":
+		res := make([]any, 0)
+		for _, id := range body["id"].([]any) {
+			if _, ok := f.entities[id.(string)]; ok {
+				res = append(res, map[string]any{milvusIDField: id})
+			}
+		}
+		reply(res)
+	case "entities/search":
+		res := make([]any, 0)
+		for id, e := range f.entities {
+			// json fields may be returned as strings
+			metadata, _ := json.Marshal(e[metadataField])
+			res = append(res, map[string]any{milvusIDField: id, "distance": f.distances[e[pageContentField].(string)], pageContentField: e[pageContentField], metadataField: string(metadata)})
+		}
+		reply(res)
+	case "entities/delete":
+		for id, e := range f.entities {
+			if body["filter"] == `metadata["`+FileMetadataKey+`"] == "`+e[metadataField].(map[string]any)[FileMetadataKey].(string)+`"` {
+				delete(f.entities, id)
+			}
+		}
+		reply(map[string]any{})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestMilvusFilter(t *testing.T) {
+	tests := []struct {
+		filters any
+		want    string
+	}{
+		{filters: map[string]any{"b": 1, "a": "x"}, want: `metadata["a"] == "x" and metadata["b"] == 1`},
+		{filters: `metadata["a"] in ["x", "y"]`, want: `metadata["a"] in ["x", "y"]`},
+	}
+	for _, tt := range tests {
+		if got, err := milvusFilter(tt.filters); err != nil || got != tt.want {
+			t.Errorf("%v: expect %s, got %s %v", tt.filters, tt.want, got, err)
+		}
+	}
+	if _, err := milvusFilter(1); err == nil {
+		t.Errorf("expect error for unsupported filters")
+	}
+}
+
+func TestMilvusStore(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeMilvus{distances: map[string]float64{"kubeagi": 0.9, "arcadia": 0.3}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	if err := NewMilvusStore(server.URL, nil, nil, fakeEmbedder{}, "arcadia_kb-1").Ping(ctx); err == nil {
+		t.Errorf("expect ping error without authentication")
+	}
+	s := NewMilvusStore(server.URL, map[string][]byte{"user": []byte("root"), "password": []byte("Milvus")}, &arcadiav1alpha1.Milvus{DatabaseName: "arcadia"}, fakeEmbedder{}, "arcadia_kb-1")
+	if err := s.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if fake.lastRequest["dbName"] != "arcadia" {
+		t.Errorf("expect database arcadia, got %v", fake.lastRequest["dbName"])
+	}
+	if docs, err := s.SimilaritySearch(ctx, "kubeagi", 2); err != nil || len(docs) != 0 {
+		t.Errorf("expect no documents before adding, got %v %v", docs, err)
+	}
+
+	if _, err := s.AddDocuments(ctx, testDocuments[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if fake.collection["collectionName"] != "arcadia_kb_1" {
+		t.Errorf("expect collection arcadia_kb_1, got %v", fake.collection["collectionName"])
+	}
+	docs, err := s.RemoveExist(ctx, logr.Discard(), testDocuments)
+	if err != nil || !reflect.DeepEqual(docs, testDocuments[2:]) {
+		t.Fatalf("expect only the new document, got %v %v", docs, err)
+	}
+	if _, err = s.AddDocuments(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.entities) != 3 {
+		t.Errorf("expect 3 entities, got %d", len(fake.entities))
+	}
+
+	docs, err = s.SimilaritySearch(ctx, "kubeagi", 3, vectorstores.WithScoreThreshold(0.5), vectorstores.WithFilters(map[string]any{FileMetadataKey: "a.txt"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].PageContent != "kubeagi" || docs[0].Metadata[FileMetadataKey] != "a.txt" {
+		t.Errorf("expect only the document above the score threshold, got %v", docs)
+	}
+	if want := `metadata["` + FileMetadataKey + `"] == "a.txt"`; fake.lastRequest["filter"] != want {
+		t.Errorf("expect filter %s, got %v", want, fake.lastRequest["filter"])
+	}
+
+	if err = s.RemoveFileDocuments(ctx, "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.entities) != 1 {
+		t.Errorf("expect 1 entity after removing file documents, got %d", len(fake.entities))
+	}
+	if err = s.RemoveCollection(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if fake.collection != nil {
+		t.Errorf("expect collection removed")
+	}
+}
+
+func TestMilvusStoreL2(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeMilvus{distances: map[string]float64{"kubeagi": 0.25, "arcadia": 3}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s := NewMilvusStore(server.URL, map[string][]byte{"user": []byte("root"), "password": []byte("Milvus")}, &arcadiav1alpha1.Milvus{MetricType: milvusMetricTypeL2}, fakeEmbedder{}, "kb")
+	if _, err := s.AddDocuments(ctx, testDocuments[:2]); err != nil {
+		t.Fatal(err)
+	}
+	// the distances are converted to similarities, and the threshold is the minimum similarity
+	docs, err := s.SimilaritySearch(ctx, "kubeagi", 2, vectorstores.WithScoreThreshold(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].PageContent != "kubeagi" || docs[0].Score != 0.8 {
+		t.Errorf("expect only kubeagi with similarity 0.8, got %v", docs)
+	}
+	if docs, err = s.SimilaritySearch(ctx, "kubeagi", 2); err != nil || len(docs) != 2 {
+		t.Fatalf("expect 2 documents without threshold, got %v %v", docs, err)
+	}
+	for _, doc := range docs {
+		if doc.PageContent == "arcadia" && doc.Score != 0.25 {
+			t.Errorf("expect arcadia with similarity 0.25, got %v", doc.Score)
+		}
+	}
+}
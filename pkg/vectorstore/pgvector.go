@@ -179,3 +179,11 @@ func (s *PGVectorStore) RemoveExist(ctx context.Context, log logr.Logger, docume
 	}
 	return doc, nil
 }
+
+// RemoveFileDocuments deletes the documents split from the file
+func (s *PGVectorStore) RemoveFileDocuments(ctx context.Context, fileName string) error {
+	sql := fmt.Sprintf(`DELETE FROM %s WHERE collection_id = (SELECT uuid FROM %s WHERE name = $1) AND cmetadata ->> $2 = $3`,
+		s.PGVector.EmbeddingTableName, s.PGVector.CollectionTableName)
+	_, err := s.Conn.Exec(ctx, sql, s.PGVector.CollectionName, FileMetadataKey, fileName)
+	return err
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"github.com/tmc/langchaingo/embeddings"
+	lanchaingoschema "github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+const qdrantDefaultDistance = "Cosine"
+
+// qdrantDistances are the distances whose scores are distances instead of similarities
+var qdrantDistances = map[string]bool{"Euclid": true, "Manhattan": true}
+
+var _ managedStore = (*QdrantStore)(nil)
+
+// QdrantStore is the vectorstore of Qdrant, using its RESTful api
+type QdrantStore struct {
+	client         *restClient
+	embedder       embeddings.Embedder
+	collectionName string
+	distance       string
+}
+
+func NewQdrantStore(endpoint string, auth map[string][]byte, spec *arcadiav1alpha1.Qdrant, embedder embeddings.Embedder, collectionName string) *QdrantStore {
+	header := http.Header{}
+	if apiKey := auth["apiKey"]; len(apiKey) != 0 {
+		header.Set("api-key", string(apiKey))
+	}
+	s := &QdrantStore{
+		client:         newRESTClient(endpoint, header),
+		embedder:       embedder,
+		collectionName: collectionName,
+		distance:       qdrantDefaultDistance,
+	}
+	if spec != nil && spec.Distance != "" {
+		s.distance = spec.Distance
+	}
+	return s
+}
+
+type qdrantPoint struct {
+	ID      string         `json:"id"`
+	Vector  []float32      `json:"vector,omitempty"`
+	Payload map[string]any `json:"payload,omitempty"`
+	Score   float32        `json:"score,omitempty"`
+}
+
+// qdrantPointID returns the id of the document, qdrant only accepts uuids and integers as point ids
+func qdrantPointID(doc lanchaingoschema.Document) string {
+	id := documentID(doc)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:32])
+}
+
+func (s *QdrantStore) path(elem ...string) string {
+	p := "/collections/" + url.PathEscape(s.collectionName)
+	for _, e := range elem {
+		p += "/" + e
+	}
+	return p
+}
+
+func (s *QdrantStore) Ping(ctx context.Context) error {
+	return s.client.do(ctx, http.MethodGet, "/collections", nil, nil)
+}
+
+// ensureCollection creates the collection if not exist
+func (s *QdrantStore) ensureCollection(ctx context.Context, dimension int) error {
+	err := s.client.do(ctx, http.MethodGet, s.path(), nil, nil)
+	if !isNotFound(err) {
+		return err
+	}
+	return s.client.do(ctx, http.MethodPut, s.path(), map[string]any{
+		"vectors": map[string]any{"size": dimension, "distance": s.distance},
+	}, nil)
+}
+
+func (s *QdrantStore) AddDocuments(ctx context.Context, docs []lanchaingoschema.Document, _ ...vectorstores.Option) ([]string, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	vectors, err := embedDocuments(ctx, s.embedder, docs)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.ensureCollection(ctx, len(vectors[0])); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(docs))
+	points := make([]qdrantPoint, len(docs))
+	for i, doc := range docs {
+		ids[i] = qdrantPointID(doc)
+		points[i] = qdrantPoint{
+			ID:      ids[i],
+			Vector:  vectors[i],
+			Payload: map[string]any{pageContentField: doc.PageContent, metadataField: doc.Metadata},
+		}
+	}
+	if err = s.client.do(ctx, http.MethodPut, s.path("points?wait=true"), map[string]any{"points": points}, nil); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// qdrantFilter converts the metadata filters to the filter of qdrant
+func qdrantFilter(filters any) any {
+	m, ok := equalFilters(filters)
+	if !ok {
+		return filters
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	must := make([]any, 0, len(m))
+	for _, k := range keys {
+		must = append(must, map[string]any{"key": metadataField + "." + k, "match": map[string]any{"value": m[k]}})
+	}
+	return map[string]any{"must": must}
+}
+
+// SimilaritySearch returns the similar documents, the score is the similarity for Cosine and Dot,
+// and Euclid and Manhattan distances are converted to similarities, so the score threshold is always the minimum similarity.
+func (s *QdrantStore) SimilaritySearch(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]lanchaingoschema.Document, error) {
+	opts, vector, err := searchOptions(ctx, s.embedder, query, options)
+	if err != nil {
+		return nil, err
+	}
+	req := map[string]any{
+		"vector":       vector,
+		"limit":        numDocuments,
+		"with_payload": true,
+	}
+	isDistance := qdrantDistances[s.distance]
+	if opts.ScoreThreshold > 0 && !isDistance {
+		// the score threshold of qdrant is the maximum distance for distances, so they are filtered after conversion
+		req["score_threshold"] = opts.ScoreThreshold
+	}
+	if opts.Filters != nil {
+		req["filter"] = qdrantFilter(opts.Filters)
+	}
+	resp := struct {
+		Result []qdrantPoint `json:"result"`
+	}{}
+	if err = s.client.do(ctx, http.MethodPost, s.path("points", "search"), req, &resp); err != nil {
+		if isNotFound(err) {
+			// no documents added yet
+			return nil, nil
+		}
+		return nil, err
+	}
+	docs := make([]lanchaingoschema.Document, 0, len(resp.Result))
+	for _, p := range resp.Result {
+		doc := lanchaingoschema.Document{Score: p.Score}
+		if isDistance {
+			doc.Score = distanceToSimilarity(p.Score)
+			if opts.ScoreThreshold > 0 && doc.Score < opts.ScoreThreshold {
+				continue
+			}
+		}
+		doc.PageContent, _ = p.Payload[pageContentField].(string)
+		doc.Metadata, _ = p.Payload[metadataField].(map[string]any)
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func (s *QdrantStore) RemoveExist(ctx context.Context, log logr.Logger, documents []lanchaingoschema.Document) ([]lanchaingoschema.Document, error) {
+	if len(documents) == 0 {
+		return documents, nil
+	}
+	ids := make([]string, len(documents))
+	for i, doc := range documents {
+		ids[i] = qdrantPointID(doc)
+	}
+	resp := struct {
+		Result []qdrantPoint `json:"result"`
+	}{}
+	err := s.client.do(ctx, http.MethodPost, s.path("points"), map[string]any{"ids": ids, "with_payload": false, "with_vector": false}, &resp)
+	if isNotFound(err) {
+		return documents, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	exist := make(map[string]bool, len(resp.Result))
+	for _, p := range resp.Result {
+		exist[p.ID] = true
+	}
+	return filterExist(log, documents, ids, exist), nil
+}
+
+func (s *QdrantStore) RemoveCollection(ctx context.Context) error {
+	err := s.client.do(ctx, http.MethodDelete, s.path(), nil, nil)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *QdrantStore) RemoveFileDocuments(ctx context.Context, fileName string) error {
+	err := s.client.do(ctx, http.MethodPost, s.path("points", "delete?wait=true"), map[string]any{
+		"filter": qdrantFilter(map[string]any{FileMetadataKey: fileName}),
+	}, nil)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectorstore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/tmc/langchaingo/vectorstores"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// fakeQdrant is a qdrant server with a single collection
+type fakeQdrant struct {
+	collection map[string]any
+	points     map[string]qdrantPoint
+	// lastSearch is the body of the last search request
+	lastSearch map[string]any
+}
+
+func (f *fakeQdrant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("api-key") != "key" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	body := map[string]any{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	path := strings.TrimPrefix(r.URL.Path, "/collections")
+	reply := func(result any) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": result, "status": "ok"})
+	}
+	if path == "" {
+		reply(map[string]any{"collections": []any{}})
+		return
+	}
+	if path != "/kb" && f.collection == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch r.Method + " " + path {
+	case "GET /kb":
+		if f.collection == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		reply(f.collection)
+	case "PUT /kb":
+		f.collection, f.points = body, map[string]qdrantPoint{}
+		reply(true)
+	case "DELETE /kb":
+		f.collection, f.points = nil, nil
+		reply(true)
+	case "PUT /kb/points":
+		data, _ := json.Marshal(body["points"])
+		var points []qdrantPoint
+		_ = json.Unmarshal(data, &points)
+		for _, p := range points {
+			f.points[p.ID] = p
+		}
+		reply(map[string]any{"status": "completed"})
+	case "POST /kb/points":
+		res := make([]qdrantPoint, 0)
+		for _, id := range body["ids"].([]any) {
+			if p, ok := f.points[id.(string)]; ok {
+				res = append(res, qdrantPoint{ID: p.ID})
+			}
+		}
+		reply(res)
+	case "POST /kb/points/search":
+		f.lastSearch = body
+		res := make([]qdrantPoint, 0)
+		for _, p := range f.points {
+			res = append(res, qdrantPoint{ID: p.ID, Payload: p.Payload, Score: 0.9})
+		}
+		reply(res)
+	case "POST /kb/points/delete":
+		must := body["filter"].(map[string]any)["must"].([]any)[0].(map[string]any)
+		value := must["match"].(map[string]any)["value"]
+		for id, p := range f.points {
+			if p.Payload[metadataField].(map[string]any)[FileMetadataKey] == value {
+				delete(f.points, id)
+			}
+		}
+		reply(map[string]any{"status": "completed"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestQdrantStore(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeQdrant{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	if err := NewQdrantStore(server.URL, nil, nil, fakeEmbedder{}, "kb").Ping(ctx); err == nil {
+		t.Errorf("expect ping error without api key")
+	}
+	s := NewQdrantStore(server.URL, map[string][]byte{"apiKey": []byte("key")}, nil, fakeEmbedder{}, "kb")
+	if err := s.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// search before any documents added
+	if docs, err := s.SimilaritySearch(ctx, "kubeagi", 2); err != nil || len(docs) != 0 {
+		t.Errorf("expect no documents before adding, got %v %v", docs, err)
+	}
+
+	docs, err := s.RemoveExist(ctx, logr.Discard(), testDocuments[:2])
+	if err != nil || len(docs) != 2 {
+		t.Fatalf("expect 2 documents to add, got %d %v", len(docs), err)
+	}
+	if _, err = s.AddDocuments(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"vectors": map[string]any{"size": float64(2), "distance": "Cosine"}}; !reflect.DeepEqual(fake.collection, want) {
+		t.Errorf("expect collection %v, got %v", want, fake.collection)
+	}
+	docs, err = s.RemoveExist(ctx, logr.Discard(), testDocuments)
+	if err != nil || !reflect.DeepEqual(docs, testDocuments[2:]) {
+		t.Fatalf("expect only the new document, got %v %v", docs, err)
+	}
+	if _, err = s.AddDocuments(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.points) != 3 {
+		t.Errorf("expect 3 points, got %d", len(fake.points))
+	}
+
+	docs, err = s.SimilaritySearch(ctx, "kubeagi", 2, vectorstores.WithScoreThreshold(0.5), vectorstores.WithFilters(map[string]any{FileMetadataKey: "a.txt"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 3 || docs[0].Score != 0.9 || docs[0].Metadata == nil {
+		t.Errorf("expect documents with score and metadata, got %v", docs)
+	}
+	wantFilter := map[string]any{"must": []any{map[string]any{"key": "metadata." + FileMetadataKey, "match": map[string]any{"value": "a.txt"}}}}
+	if fake.lastSearch["score_threshold"] != 0.5 || !reflect.DeepEqual(fake.lastSearch["filter"], wantFilter) {
+		t.Errorf("expect score threshold and filter in search, got %v", fake.lastSearch)
+	}
+
+	if err = s.RemoveFileDocuments(ctx, "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.points) != 1 {
+		t.Errorf("expect 1 point after removing file documents, got %d", len(fake.points))
+	}
+	if err = s.RemoveCollection(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if fake.collection != nil {
+		t.Errorf("expect collection removed")
+	}
+}
+
+func TestQdrantStoreEuclid(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeQdrant{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s := NewQdrantStore(server.URL, map[string][]byte{"apiKey": []byte("key")}, &arcadiav1alpha1.Qdrant{Distance: "Euclid"}, fakeEmbedder{}, "kb")
+	if _, err := s.AddDocuments(ctx, testDocuments[:1]); err != nil {
+		t.Fatal(err)
+	}
+	// the fake distance 0.9 is the similarity 1 / 1.9
+	docs, err := s.SimilaritySearch(ctx, "kubeagi", 2, vectorstores.WithScoreThreshold(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].Score != distanceToSimilarity(0.9) {
+		t.Errorf("expect the document with similarity %v, got %v", distanceToSimilarity(0.9), docs)
+	}
+	if _, ok := fake.lastSearch["score_threshold"]; ok {
+		t.Errorf("the similarity threshold should not be sent as the maximum distance, got %v", fake.lastSearch)
+	}
+	if docs, err = s.SimilaritySearch(ctx, "kubeagi", 2, vectorstores.WithScoreThreshold(0.6)); err != nil || len(docs) != 0 {
+		t.Errorf("expect no documents above the similarity threshold, got %v %v", docs, err)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectorstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/tmc/langchaingo/embeddings"
+	lanchaingoschema "github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores"
+)
+
+var (
+	ErrNoEmbedder = errors.New("no embedder for the vectorstore")
+)
+
+// The fields of documents in the vectorstores accessed by RESTful apis
+const (
+	pageContentField = "page_content"
+	metadataField    = "metadata"
+	vectorField      = "vector"
+)
+
+// HTTPError is returned when the server responds with a non-2xx status
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// isNotFound returns whether the error is a 404 response
+func isNotFound(err error) bool {
+	var e *HTTPError
+	return errors.As(err, &e) && e.StatusCode == http.StatusNotFound
+}
+
+type restClient struct {
+	baseURL string
+	header  http.Header
+	client  *http.Client
+}
+
+func newRESTClient(baseURL string, header http.Header) *restClient {
+	return &restClient{baseURL: strings.TrimSuffix(baseURL, "/"), header: header, client: http.DefaultClient}
+}
+
+// do sends the request with in as the json body if not nil, and decodes the json response to out if not nil
+func (c *restClient) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	return c.doRaw(ctx, method, path, "application/json", body, out)
+}
+
+func (c *restClient) doRaw(ctx context.Context, method, path, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &HTTPError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// documentID returns the hash of the content and metadata of the document,
+// so the same document always has the same id and won't be added twice.
+func documentID(doc lanchaingoschema.Document) string {
+	metadata, _ := json.Marshal(doc.Metadata)
+	sum := sha256.Sum256(append([]byte(doc.PageContent+"\x00"), metadata...))
+	return hex.EncodeToString(sum[:])
+}
+
+// embedDocuments returns the vectors of the documents
+func embedDocuments(ctx context.Context, embedder embeddings.Embedder, documents []lanchaingoschema.Document) ([][]float32, error) {
+	if embedder == nil {
+		return nil, ErrNoEmbedder
+	}
+	texts := make([]string, len(documents))
+	for i, doc := range documents {
+		texts[i] = doc.PageContent
+	}
+	vectors, err := embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(documents) {
+		return nil, fmt.Errorf("got %d vectors for %d documents", len(vectors), len(documents))
+	}
+	return vectors, nil
+}
+
+// searchOptions returns the options of similarity search, and the embedding of the query
+func searchOptions(ctx context.Context, embedder embeddings.Embedder, query string, options []vectorstores.Option) (vectorstores.Options, []float32, error) {
+	opts := vectorstores.Options{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if opts.Embedder != nil {
+		embedder = opts.Embedder
+	}
+	if embedder == nil {
+		return opts, nil, ErrNoEmbedder
+	}
+	vector, err := embedder.EmbedQuery(ctx, query)
+	return opts, vector, err
+}
+
+// distanceToSimilarity converts a distance to a similarity in (0, 1], the smaller the distance, the higher the similarity.
+// The stores return similarities as the scores of documents, so the score threshold is always the minimum similarity.
+func distanceToSimilarity(distance float32) float32 {
+	if distance < 0 {
+		distance = 0
+	}
+	return 1 / (1 + distance)
+}
+
+// equalFilters returns the metadata filters if they are the map of keys and values to match,
+// other filters are in the native format of the vectorstore and should be used as they are.
+func equalFilters(filters any) (map[string]any, bool) {
+	m, ok := filters.(map[string]any)
+	return m, ok
+}
+
+// filterExist filters out the documents whose ids exist
+func filterExist(log logr.Logger, documents []lanchaingoschema.Document, ids []string, exist map[string]bool) []lanchaingoschema.Document {
+	res := make([]lanchaingoschema.Document, 0, len(documents))
+	for i, doc := range documents {
+		if exist[ids[i]] {
+			log.V(5).Info(fmt.Sprintf("filter out exist documents[%s]", doc.PageContent))
+			continue
+		}
+		res = append(res, doc)
+	}
+	return res
+}
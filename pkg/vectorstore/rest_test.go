@@ -0,0 +1,81 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectorstore
+
+import (
+	"context"
+	"testing"
+
+	lanchaingoschema "github.com/tmc/langchaingo/schema"
+)
+
+// fakeEmbedder returns a 2-dimensional vector of the text length
+type fakeEmbedder struct{}
+
+func (e fakeEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	res := make([][]float32, len(texts))
+	for i, text := range texts {
+		res[i], _ = e.EmbedQuery(ctx, text)
+	}
+	return res, nil
+}
+
+func (e fakeEmbedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	return []float32{float32(len(text)), 1}, nil
+}
+
+// testDocuments are the documents for tests, the first two are from the same file
+var testDocuments = []lanchaingoschema.Document{
+	{PageContent: "kubeagi", Metadata: map[string]any{FileMetadataKey: "a.txt"}},
+	{PageContent: "arcadia", Metadata: map[string]any{FileMetadataKey: "a.txt"}},
+	{PageContent: "arcadia", Metadata: map[string]any{FileMetadataKey: "b.txt"}},
+}
+
+func TestDocumentID(t *testing.T) {
+	ids := make(map[string]bool)
+	for _, doc := range testDocuments {
+		id := documentID(doc)
+		if id != documentID(lanchaingoschema.Document{PageContent: doc.PageContent, Metadata: doc.Metadata}) {
+			t.Errorf("%s: expect the same id for the same document", doc.PageContent)
+		}
+		ids[id] = true
+	}
+	if len(ids) != len(testDocuments) {
+		t.Errorf("expect %d different ids, got %d", len(testDocuments), len(ids))
+	}
+}
+
+func TestCollectionNames(t *testing.T) {
+	tests := []struct {
+		name          string
+		milvus        string
+		elasticsearch string
+	}{
+		{name: "arcadia_kb-1", milvus: "arcadia_kb_1", elasticsearch: "arcadia_kb-1"},
+		{name: "KubeAGI_Docs", milvus: "KubeAGI_Docs", elasticsearch: "kubeagi_docs"},
+		{name: "1-kb", milvus: "_1_kb", elasticsearch: "1-kb"},
+		{name: "_kb", milvus: "_kb", elasticsearch: "kb"},
+	}
+	for _, tt := range tests {
+		if got := milvusCollectionName(tt.name); got != tt.milvus {
+			t.Errorf("%s: expect milvus collection %s, got %s", tt.name, tt.milvus, got)
+		}
+		if got := elasticsearchIndexName(tt.name); got != tt.elasticsearch {
+			t.Errorf("%s: expect elasticsearch index %s, got %s", tt.name, tt.elasticsearch, got)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 
+	chromaopenapi "github.com/amikos-tech/chroma-go/swagger"
 	"github.com/go-logr/logr"
 	"github.com/tmc/langchaingo/embeddings"
 	lanchaingoschema "github.com/tmc/langchaingo/schema"
@@ -35,6 +36,9 @@ var (
 	ErrUnsupportedVectorStoreType = errors.New("unsupported vectorstore type")
 )
 
+// FileMetadataKey is the metadata key of the source file which a document is split from
+const FileMetadataKey = "arcadia_file"
+
 func NewVectorStore(ctx context.Context, vs *arcadiav1alpha1.VectorStore, embedder embeddings.Embedder, collectionName string, c client.Client) (v vectorstores.VectorStore, finish func(), err error) {
 	switch vs.Spec.Type() {
 	case arcadiav1alpha1.VectorStoreTypeChroma:
@@ -53,6 +57,9 @@ func NewVectorStore(ctx context.Context, vs *arcadiav1alpha1.VectorStore, embedd
 		v, err = chroma.New(ops...)
 	case arcadiav1alpha1.VectorStoreTypePGVector:
 		v, finish, err = NewPGVectorStore(ctx, vs, c, embedder, collectionName)
+	case arcadiav1alpha1.VectorStoreTypeMilvus, arcadiav1alpha1.VectorStoreTypeQdrant, arcadiav1alpha1.VectorStoreTypeElasticsearch,
+		arcadiav1alpha1.VectorStoreTypeEmbedded:
+		v, err = newManagedStore(ctx, vs, c, embedder, collectionName)
 	case arcadiav1alpha1.VectorStoreTypeUnknown:
 		fallthrough
 	default:
@@ -101,7 +108,17 @@ func RemoveCollection(ctx context.Context, log logr.Logger, vs *arcadiav1alpha1.
 			log.Error(err, "reconcile delete: remove vector store error, may leave garbage data")
 			return err
 		}
-
+	case arcadiav1alpha1.VectorStoreTypeMilvus, arcadiav1alpha1.VectorStoreTypeQdrant, arcadiav1alpha1.VectorStoreTypeElasticsearch,
+		arcadiav1alpha1.VectorStoreTypeEmbedded:
+		v, err := newManagedStore(ctx, vs, c, nil, collectionName)
+		if err != nil {
+			log.Error(err, "reconcile delete: init vector store error, may leave garbage data")
+			return err
+		}
+		if err = v.RemoveCollection(ctx); err != nil {
+			log.Error(err, "reconcile delete: remove vector store error, may leave garbage data")
+			return err
+		}
 	case arcadiav1alpha1.VectorStoreTypeUnknown:
 		fallthrough
 	default:
@@ -110,19 +127,71 @@ func RemoveCollection(ctx context.Context, log logr.Logger, vs *arcadiav1alpha1.
 	return err
 }
 
+// managedStore is a vectorstore implemented in this package, it supports Row-level updates
+// by using the hash of the document as its id.
+type managedStore interface {
+	vectorstores.VectorStore
+	// Ping checks the connection and the authentication
+	Ping(ctx context.Context) error
+	// RemoveExist filters out the documents already in the collection
+	RemoveExist(ctx context.Context, log logr.Logger, documents []lanchaingoschema.Document) ([]lanchaingoschema.Document, error)
+	// RemoveCollection removes the collection and all its documents
+	RemoveCollection(ctx context.Context) error
+	// RemoveFileDocuments removes the documents split from the file
+	RemoveFileDocuments(ctx context.Context, fileName string) error
+}
+
+// newManagedStore returns the store of the vectorstore type, and checks the connection
+func newManagedStore(ctx context.Context, vs *arcadiav1alpha1.VectorStore, c client.Client, embedder embeddings.Embedder, collectionName string) (s managedStore, err error) {
+	if vs.Spec.Type() == arcadiav1alpha1.VectorStoreTypeEmbedded {
+		if s, err = NewEmbeddedStore(ctx, vs, embedder, collectionName); err != nil {
+			return nil, err
+		}
+	} else {
+		if vs.Spec.Endpoint == nil {
+			return nil, errors.New("no endpoint for the vectorstore")
+		}
+		var auth map[string][]byte
+		if c != nil {
+			if auth, err = vs.Spec.Endpoint.AuthData(ctx, vs.Namespace, c); err != nil {
+				return nil, err
+			}
+		}
+		switch vs.Spec.Type() {
+		case arcadiav1alpha1.VectorStoreTypeMilvus:
+			s = NewMilvusStore(vs.Spec.Endpoint.URL, auth, vs.Spec.Milvus, embedder, collectionName)
+		case arcadiav1alpha1.VectorStoreTypeQdrant:
+			s = NewQdrantStore(vs.Spec.Endpoint.URL, auth, vs.Spec.Qdrant, embedder, collectionName)
+		case arcadiav1alpha1.VectorStoreTypeElasticsearch:
+			s = NewElasticsearchStore(vs.Spec.Endpoint.URL, auth, vs.Spec.Elasticsearch, embedder, collectionName)
+		default:
+			return nil, ErrUnsupportedVectorStoreType
+		}
+	}
+	if err = s.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", vs.Spec.Type(), err)
+	}
+	return s, nil
+}
+
+// existRemover is implemented by the vectorstores supporting Row-level updates
+type existRemover interface {
+	RemoveExist(ctx context.Context, log logr.Logger, documents []lanchaingoschema.Document) ([]lanchaingoschema.Document, error)
+}
+
 func AddDocuments(ctx context.Context, log logr.Logger, vs *arcadiav1alpha1.VectorStore, embedder embeddings.Embedder, collectionName string, c client.Client, documents []lanchaingoschema.Document) (err error) {
 	s, finish, err := NewVectorStore(ctx, vs, embedder, collectionName, c)
 	if err != nil {
 		return err
 	}
 	log.Info("handle file: add documents to embedder")
-	if store, ok := s.(*PGVectorStore); ok {
-		// now only pgvector support Row-level updates
-		log.V(3).Info("handle file: use pgvector, filter out exist documents...")
+	if store, ok := s.(existRemover); ok {
+		// chroma doesn't support Row-level updates
+		log.V(3).Info("handle file: filter out exist documents...")
 		if documents, err = store.RemoveExist(ctx, log, documents); err != nil {
 			return err
 		}
-		log.V(3).Info("handle file: use pgvector, filter out exist documents done")
+		log.V(3).Info("handle file: filter out exist documents done")
 	}
 	for i, doc := range documents {
 		log.V(5).Info(fmt.Sprintf("add doc to vectorstore, document[%d]: embedding:%s, metadata:%v", i, doc.PageContent, doc.Metadata))
@@ -138,3 +207,57 @@ func AddDocuments(ctx context.Context, log logr.Logger, vs *arcadiav1alpha1.Vect
 	log.V(3).Info("handle file succeeded")
 	return nil
 }
+
+// RemoveFileDocuments removes the documents split from the file in the collection
+func RemoveFileDocuments(ctx context.Context, log logr.Logger, vs *arcadiav1alpha1.VectorStore, collectionName string, c client.Client, fileName string) (err error) {
+	log = log.WithValues("fileName", fileName)
+	switch vs.Spec.Type() {
+	case arcadiav1alpha1.VectorStoreTypeChroma:
+		// chroma-go Collection.Delete exits the process when fails, use the api client directly
+		configuration := chromaopenapi.NewConfiguration()
+		configuration.Servers = chromaopenapi.ServerConfigurations{{URL: vs.Spec.Endpoint.URL}}
+		api := chromaopenapi.NewAPIClient(configuration).DefaultApi
+		col, _, err := api.GetCollection(ctx, collectionName).Execute()
+		if err != nil {
+			log.Error(err, "remove file documents: get collection error")
+			return err
+		}
+		if _, _, err = api.Delete(ctx, col.Id).DeleteEmbedding(chromaopenapi.DeleteEmbedding{
+			Where: map[string]interface{}{FileMetadataKey: fileName},
+		}).Execute(); err != nil {
+			log.Error(err, "remove file documents: delete embeddings error")
+			return err
+		}
+	case arcadiav1alpha1.VectorStoreTypePGVector:
+		v, finish, err := NewPGVectorStore(ctx, vs, c, nil, collectionName)
+		defer func() {
+			if finish != nil {
+				finish()
+			}
+		}()
+		if err != nil {
+			log.Error(err, "remove file documents: init pgvector error")
+			return err
+		}
+		if err = v.RemoveFileDocuments(ctx, fileName); err != nil {
+			log.Error(err, "remove file documents: delete embeddings error")
+			return err
+		}
+	case arcadiav1alpha1.VectorStoreTypeMilvus, arcadiav1alpha1.VectorStoreTypeQdrant, arcadiav1alpha1.VectorStoreTypeElasticsearch,
+		arcadiav1alpha1.VectorStoreTypeEmbedded:
+		v, err := newManagedStore(ctx, vs, c, nil, collectionName)
+		if err != nil {
+			log.Error(err, "remove file documents: init vector store error")
+			return err
+		}
+		if err = v.RemoveFileDocuments(ctx, fileName); err != nil {
+			log.Error(err, "remove file documents: delete embeddings error")
+			return err
+		}
+	case arcadiav1alpha1.VectorStoreTypeUnknown:
+		fallthrough
+	default:
+		err = ErrUnsupportedVectorStoreType
+	}
+	return err
+}